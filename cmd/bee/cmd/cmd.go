@@ -13,9 +13,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction/private"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -55,11 +61,81 @@ const (
 	optionNameClefSignerEthereumAddress  = "clef-signer-ethereum-address"
 	optionNameSwapEndpoint               = "swap-endpoint" // deprecated: use rpc endpoint instead
 	optionNameBlockchainRpcEndpoint      = "blockchain-rpc-endpoint"
+	optionNameBlockchainRpcHeaders       = "blockchain-rpc-headers"
+	optionNameBlockchainRpcBasicAuth     = "blockchain-rpc-basic-auth"
+	optionNameBlockchainRpcJWTSecret     = "blockchain-rpc-jwt-secret"
+	optionNameBlockchainRpcRetries       = "blockchain-rpc-retries"
+	optionNameBlockchainRpcRetryBackoff  = "blockchain-rpc-retry-backoff"
+	optionNameBlockchainRpcMaxBackoff    = "blockchain-rpc-retry-max-backoff"
+	optionNameBlockchainRpcCallRetries   = "blockchain-rpc-call-retries"
+	optionNameBlockchainRpcTimeoutMargin = "blockchain-rpc-timeout-margin"
+	optionNameBlockchainRpcMinTimeout    = "blockchain-rpc-min-timeout"
+	optionNameBlockchainRpcMaxTimeout    = "blockchain-rpc-max-timeout"
+	optionNameBlockchainPrivateRelay     = "blockchain-rpc-private-relay"
+	optionNameBlockchainPrivateDeadline  = "blockchain-rpc-private-deadline"
 	optionNameSwapFactoryAddress         = "swap-factory-address"
 	optionNameSwapLegacyFactoryAddresses = "swap-legacy-factory-addresses"
 	optionNameSwapInitialDeposit         = "swap-initial-deposit"
+	optionNameSwapMulticallAddress       = "swap-multicall-address"
+	optionNameSwapMPCSignerEndpoint      = "swap-mpc-signer-endpoint"
+	optionNameSwapMPCSignerParticipants  = "swap-mpc-signer-participants"
+	optionNameSwapMPCSignerThreshold     = "swap-mpc-signer-threshold"
+	optionNameSwapMPCSignerTimeout       = "swap-mpc-signer-timeout"
+	optionNameSwapMPCSignerFallback      = "swap-mpc-signer-fallback"
+	optionNameSwapChequeSignerKeystore   = "swap-cheque-signer-keystore"
+	optionNameSwapChequeSignerPassword   = "swap-cheque-signer-password-file"
+	optionNameSwapChequeSignerSeed       = "swap-cheque-signer-seed-file"
+	optionNameSwapChequeSignerPath       = "swap-cheque-signer-derivation-path"
+	optionNameSwapChequeVerifier         = "swap-cheque-verifier-endpoint"
+	optionNameSwapUserOpBundler          = "swap-user-operation-bundler"
+	optionNameSwapUserOpEntryPoint       = "swap-user-operation-entry-point"
+	optionNameSwapUserOpAccount          = "swap-user-operation-account"
+	optionNameSwapUserOpPaymaster        = "swap-user-operation-paymaster"
+	optionNameSwapGasPriceCaps           = "swap-gas-price-caps"
+	optionNameSwapGasPriceCapExpiry      = "swap-gas-price-cap-expiry"
+	optionNameSwapChequeRateInterval     = "swap-cheque-rate-interval"
+	optionNameSwapChequeRateBurst        = "swap-cheque-rate-burst"
+	optionNameSwapChequeGranularity      = "swap-cheque-granularity"
+	optionNameSwapTotalIssuedTolerance   = "swap-total-issued-tolerance"
+	optionNameSwapBalanceDeclineMax      = "swap-balance-decline-max"
+	optionNameSwapBalanceDeclineWindow   = "swap-balance-decline-window"
+	optionNameSwapBalanceDeclinePause    = "swap-balance-decline-pause"
+	optionNameSwapPaymentBatchWindow     = "swap-payment-batch-window"
 	optionNameSwapEnable                 = "swap-enable"
+	optionNameSwapBlocklistDuration      = "swap-blocklist-duration"
+	optionNameSwapWorkers                = "swap-workers"
+	optionNameSwapWorkerQueueSize        = "swap-worker-queue-size"
+	optionNameSwapDrainTimeout           = "swap-drain-timeout"
+	optionNameSwapDrainMinDebt           = "swap-drain-min-debt"
+	optionNameSwapMinChequebookAge       = "swap-min-chequebook-age"
+	optionNameSwapTrustedChequeScore     = "swap-trusted-cheque-score"
+	optionNameSwapTrustedChequeReverify  = "swap-trusted-cheque-reverify"
+	optionNameSwapCallTimeout            = "swap-call-timeout"
+	optionNameSwapSendTimeout            = "swap-send-timeout"
+	optionNameSwapReceiptTimeout         = "swap-receipt-timeout"
+	optionNameSwapStatementInterval      = "swap-statement-interval"
+	optionNameSwapEscalationLadder       = "swap-escalation-ladder"
+	optionNameSwapWithdrawWatchPeers     = "swap-withdraw-watch-peers"
+	optionNameSwapWithdrawWatchCashout   = "swap-withdraw-watch-cashout"
+	optionNameSwapStalePeerPeriod        = "swap-stale-peer-period"
+	optionNameSwapAutoCashoutThreshold   = "swap-auto-cashout-threshold"
+	optionNameSwapAutoCashoutMinInterval = "swap-auto-cashout-min-interval"
+	optionNameSwapRegistryAddress        = "swap-registry-address"
+	optionNameSwapRegistryPublish        = "swap-registry-publish"
+	optionNameSwapCashoutMaxDelay        = "swap-cashout-max-delay"
+	optionNameSwapCashoutParallelism     = "swap-cashout-parallelism"
+	optionNameSwapCashoutMaxInFlight     = "swap-cashout-max-in-flight"
+	optionNameSwapCashoutCost            = "swap-cashout-cost"
+	optionNameSwapConfirmations          = "swap-confirmations"
+	optionNameSwapSequencerUptimeFeed    = "swap-sequencer-uptime-feed"
+	optionNameSwapL1FeeOracle            = "swap-l1-fee-oracle"
+	optionNameSwapFactoryDeposit         = "swap-factory-deposit"
 	optionNameChequebookEnable           = "chequebook-enable"
+	optionNameSettlementEncryption       = "settlement-encryption"
+	optionNameSettlementEncryptionSecret = "settlement-encryption-secret"
+	optionNameSettlementNamespace        = "settlement-namespace"
+	optionNameSettlementDataDir          = "settlement-data-dir"
+	optionNameSettlementReplica          = "settlement-replica"
 	optionNameSwapDeploymentGasPrice     = "swap-deployment-gas-price"
 	optionNameFullNode                   = "full-node"
 	optionNamePostageContractAddress     = "postage-stamp-address"
@@ -154,6 +230,7 @@ func newCommand(opts ...option) (c *command, err error) {
 
 	c.initVersionCmd()
 	c.initDBCmd()
+	c.initFleetCmd()
 
 	if err := c.initConfigurateOptionsCmd(); err != nil {
 		return nil, err
@@ -270,11 +347,81 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameClefSignerEthereumAddress, "", "ethereum address to use from clef signer")
 	cmd.Flags().String(optionNameSwapEndpoint, "", "swap blockchain endpoint") // deprecated: use rpc endpoint instead
 	cmd.Flags().String(optionNameBlockchainRpcEndpoint, "", "rpc blockchain endpoint")
+	cmd.Flags().StringSlice(optionNameBlockchainRpcHeaders, nil, "http headers sent to the rpc blockchain endpoint as name: value, e.g. to pass an api key")
+	cmd.Flags().String(optionNameBlockchainRpcBasicAuth, "", "basic auth credentials for the rpc blockchain endpoint as username:password")
+	cmd.Flags().String(optionNameBlockchainRpcJWTSecret, "", "path to a file with the hex encoded secret signing jwt tokens for the rpc blockchain endpoint")
+	cmd.Flags().Int(optionNameBlockchainRpcRetries, retry.DefaultMaxAttempts, "attempts of rpc blockchain calls failing with transient errors like timeouts, rate limiting or temporary server errors")
+	cmd.Flags().Duration(optionNameBlockchainRpcRetryBackoff, retry.DefaultInitialBackoff, "upper bound of the randomized wait before the first retry of a rpc blockchain call, doubled with every retry")
+	cmd.Flags().Duration(optionNameBlockchainRpcMaxBackoff, retry.DefaultMaxBackoff, "upper bound of the randomized wait between two attempts of a rpc blockchain call")
+	cmd.Flags().StringSlice(optionNameBlockchainRpcCallRetries, nil, "attempts per rpc blockchain call type as method=attempts, e.g. SendTransaction=1 or CallContract=5")
+	cmd.Flags().Duration(optionNameBlockchainRpcTimeoutMargin, retry.DefaultTimeoutMargin, "time added to the p99 latency of a rpc blockchain call type to get the timeout after which hanging calls are cancelled and retried")
+	cmd.Flags().Duration(optionNameBlockchainRpcMinTimeout, retry.DefaultMinTimeout, "lower bound of the adaptive timeout of rpc blockchain calls")
+	cmd.Flags().Duration(optionNameBlockchainRpcMaxTimeout, retry.DefaultMaxTimeout, "upper bound of the adaptive timeout of rpc blockchain calls, 0 disables adaptive timeouts")
+	cmd.Flags().String(optionNameBlockchainPrivateRelay, "", "rpc endpoint of a private relay serving eth_sendPrivateTransaction, transactions of api requests with the Private-Submission header are sent through it instead of the public mempool")
+	cmd.Flags().Duration(optionNameBlockchainPrivateDeadline, private.DefaultDeadline, "time after which transactions sent through the private relay which were not mined are broadcast publicly")
 	cmd.Flags().String(optionNameSwapFactoryAddress, "", "swap factory addresses")
 	cmd.Flags().StringSlice(optionNameSwapLegacyFactoryAddresses, nil, "legacy swap factory addresses")
 	cmd.Flags().String(optionNameSwapInitialDeposit, "0", "initial deposit if deploying a new chequebook")
+	cmd.Flags().String(optionNameSwapMulticallAddress, "", "multicall contract address used to bundle cashouts")
+	cmd.Flags().String(optionNameSwapMPCSignerEndpoint, "", "threshold signing service used to sign cheques instead of the node key")
+	cmd.Flags().StringSlice(optionNameSwapMPCSignerParticipants, nil, "participants of the threshold signing service")
+	cmd.Flags().Int(optionNameSwapMPCSignerThreshold, 0, "number of participants needed to sign a cheque")
+	cmd.Flags().Duration(optionNameSwapMPCSignerTimeout, 30*time.Second, "maximum duration of a threshold signing round")
+	cmd.Flags().String(optionNameSwapMPCSignerFallback, "reject", "what to do if no threshold signature can be obtained: reject or local")
+	cmd.Flags().String(optionNameSwapChequeSignerKeystore, "", "encrypted key file of the chequebook issuer used to sign cheques instead of the node key")
+	cmd.Flags().String(optionNameSwapChequeSignerPassword, "", "path to a file that contains the passphrase of the cheque signer key file")
+	cmd.Flags().String(optionNameSwapChequeSignerSeed, "", "path to a file that contains the hex encoded HD wallet seed the cheque signer key is derived from instead of using the node key, the derived key is the issuer of new chequebooks and has to send their withdrawals")
+	cmd.Flags().String(optionNameSwapChequeSignerPath, accounts.DefaultBaseDerivationPath.String(), "derivation path of the cheque signer key in the HD wallet")
+	cmd.Flags().String(optionNameSwapChequeVerifier, "", "API endpoint of a trusted node which verifies the received cheques instead of this node")
+	cmd.Flags().String(optionNameSwapUserOpBundler, "", "ERC-4337 bundler endpoint used to send chequebook deposits and cashouts as user operations")
+	cmd.Flags().String(optionNameSwapUserOpEntryPoint, "", "ERC-4337 entry point contract address")
+	cmd.Flags().String(optionNameSwapUserOpAccount, "", "smart contract account owned by the node key sending the user operations")
+	cmd.Flags().Bool(optionNameSwapUserOpPaymaster, false, "request gas sponsorship for user operations from the bundler's paymaster")
+	cmd.Flags().StringSlice(optionNameSwapGasPriceCaps, nil, "maximum gas price in wei per chequebook operation as operation=wei, operations are deployment, deposit, withdraw and cashout")
+	cmd.Flags().Duration(optionNameSwapGasPriceCapExpiry, time.Hour, "how long an operation waits for the gas price to fall below its cap")
+	cmd.Flags().Duration(optionNameSwapChequeRateInterval, 0, "interval in which one more cheque may be issued to the same peer, 0 disables the limit")
+	cmd.Flags().Int(optionNameSwapChequeRateBurst, 10, "maximum number of cheques issued to the same peer at once, 0 disables the limit")
+	cmd.Flags().String(optionNameSwapChequeGranularity, "", "round cheque amounts up to a multiple of this amount in PLUR, the excess is used for later payments")
+	cmd.Flags().String(optionNameSwapTotalIssuedTolerance, "0", "largest difference in PLUR between the total issued counter and the issued cheques accepted on startup before issuing is stopped")
+	cmd.Flags().String(optionNameSwapBalanceDeclineMax, "", "largest decline in PLUR of the available chequebook balance within the decline window before an alarm is raised, empty disables the alarm")
+	cmd.Flags().Duration(optionNameSwapBalanceDeclineWindow, time.Hour, "window over which the decline of the available chequebook balance is measured")
+	cmd.Flags().Bool(optionNameSwapBalanceDeclinePause, false, "stop issuing cheques while the available chequebook balance declines faster than allowed")
+	cmd.Flags().Duration(optionNameSwapPaymentBatchWindow, 0, "period over which the debt to a peer is accumulated once a payment is due to pay it with one cheque, 0 pays right away")
 	cmd.Flags().Bool(optionNameSwapEnable, true, "enable swap")
+	cmd.Flags().Duration(optionNameSwapBlocklistDuration, time.Hour, "duration for which peers not settling their debt are blocklisted, 0 only disconnects them")
+	cmd.Flags().Int(optionNameSwapWorkers, 16, "number of cheque issuances and cashouts run concurrently")
+	cmd.Flags().Int(optionNameSwapWorkerQueueSize, 1000, "maximum number of settlement tasks of each priority waiting for a worker")
+	cmd.Flags().Duration(optionNameSwapDrainTimeout, 10*time.Second, "time given on shutdown to send queued and deferred cheques and finish the ones in flight, 0 drops them")
+	cmd.Flags().String(optionNameSwapDrainMinDebt, "", "minimum debt in PLUR towards a connected peer for which a final cheque is issued on shutdown, empty issues no final cheques")
+	cmd.Flags().Uint64(optionNameSwapMinChequebookAge, 0, "minimum age in blocks of chequebooks cheques are accepted from, 0 disables the check")
+	cmd.Flags().Uint64(optionNameSwapTrustedChequeScore, 0, "number of cheques in a row passing the full verification after which the liquidity of their chequebook is not queried for every cheque, 0 verifies all cheques fully")
+	cmd.Flags().Duration(optionNameSwapTrustedChequeReverify, chequebook.DefaultReverifyInterval, "interval after which cheques of a trusted chequebook are fully verified again")
+	cmd.Flags().Duration(optionNameSwapCallTimeout, chequebook.DefaultCallTimeout, "timeout of settlement contract reads, 0 disables the timeout")
+	cmd.Flags().Duration(optionNameSwapSendTimeout, chequebook.DefaultSendTimeout, "timeout of sending settlement transactions, 0 disables the timeout")
+	cmd.Flags().Duration(optionNameSwapReceiptTimeout, chequebook.DefaultReceiptTimeout, "timeout of waiting for settlement transactions to be mined, 0 disables the timeout")
+	cmd.Flags().Duration(optionNameSwapStatementInterval, time.Hour, "interval in which signed settlement statements are made for peers we sent cheques to, 0 disables statements")
+	cmd.Flags().String(optionNameSwapEscalationLadder, swap.DefaultEscalationLadder, "actions taken once the debt of a peer stayed above the payment threshold for the given duration, empty disables escalation")
+	cmd.Flags().Int(optionNameSwapWithdrawWatchPeers, swap.DefaultWithdrawWatchPeers, "number of peers with the most uncashed cheques whose chequebooks are watched for withdrawals, 0 disables watching")
+	cmd.Flags().Bool(optionNameSwapWithdrawWatchCashout, false, "cash the last cheque of a watched peer as soon as it withdraws from its chequebook")
+	cmd.Flags().Duration(optionNameSwapStalePeerPeriod, 0, "period after which the settlement records of a disconnected and settled peer are archived to the audit log, 0 disables archiving")
+	cmd.Flags().String(optionNameSwapAutoCashoutThreshold, "0", "uncashed amount in PLUR of a peer above which its cheques are cashed automatically, 0 disables automatic cashouts")
+	cmd.Flags().Duration(optionNameSwapAutoCashoutMinInterval, swap.DefaultAutoCashoutMinInterval, "minimum time between two automatic cashouts of a peer")
+	cmd.Flags().String(optionNameSwapRegistryAddress, "", "registry contract announced chequebooks and beneficiaries of peers are verified against, empty disables the verification")
+	cmd.Flags().Bool(optionNameSwapRegistryPublish, false, "publish the chequebook and beneficiary of this node in the registry on startup")
+	cmd.Flags().Duration(optionNameSwapCashoutMaxDelay, 0, "maximum delay of cashouts scheduled for a low base fee, 0 disables scheduled cashouts")
+	cmd.Flags().Int(optionNameSwapCashoutParallelism, cashouttiming.DefaultParallelism, "number of scheduled cashouts sent at the same time")
+	cmd.Flags().Int(optionNameSwapCashoutMaxInFlight, cashouttiming.DefaultMaxInFlight, "number of scheduled cashout transactions waiting to be mined after which further cashouts are postponed")
+	cmd.Flags().String(optionNameSwapCashoutCost, "0", "PLUR expected to be deducted from the payout of a cashout, reserved from the uncashed earnings")
+	cmd.Flags().Int64(optionNameSwapConfirmations, -1, "blocks after which settlement transactions and events are final, -1 uses the default of the chain")
+	cmd.Flags().String(optionNameSwapSequencerUptimeFeed, "", "sequencer uptime feed address on L2 rollups, transactions wait while the sequencer is down")
+	cmd.Flags().String(optionNameSwapL1FeeOracle, "", "L1 data fee oracle address on L2 rollups, defaults to the known oracle of the chain")
+	cmd.Flags().Bool(optionNameSwapFactoryDeposit, false, "fund new chequebooks with the initial deposit in the deployment transaction, the factory must support it")
 	cmd.Flags().Bool(optionNameChequebookEnable, true, "enable chequebook")
+	cmd.Flags().Bool(optionNameSettlementEncryption, false, "encrypt cheques and settlement records in the statestore")
+	cmd.Flags().String(optionNameSettlementEncryptionSecret, "", "secret to derive the settlement encryption key from, the node key is used if empty")
+	cmd.Flags().String(optionNameSettlementNamespace, "", "namespace of the settlement records in the statestore, the chain ID is used if empty")
+	cmd.Flags().String(optionNameSettlementDataDir, "", "directory of a separate statestore for the settlement records and transactions, they are kept in the node statestore if empty")
+	cmd.Flags().String(optionNameSettlementReplica, "", "warm standby to replicate the settlement state to before it is written, a journal file as journal:<path> or a statestore as statestore:<dir>")
 	cmd.Flags().Bool(optionNameFullNode, false, "cause the node to start in full mode")
 	cmd.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address")
 	cmd.Flags().Uint64(optionNamePostageContractStartBlock, 0, "postage stamp contract start block number")
@@ -5,6 +5,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,7 +15,10 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/statestore/replicated"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +37,10 @@ func (c *command) initDBCmd() {
 	dbImportCmd(cmd)
 	dbNukeCmd(cmd)
 	dbIndicesCmd(cmd)
+	dbSettlementSnapshotCmd(cmd)
+	dbSettlementRestoreCmd(cmd)
+	dbSettlementReplayCmd(cmd)
+	dbSettlementKeysCmd(cmd)
 
 	c.root.AddCommand(cmd)
 }
@@ -293,6 +301,237 @@ func dbNukeCmd(cmd *cobra.Command) {
 	cmd.AddCommand(c)
 }
 
+func dbSettlementSnapshotCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "settlement-snapshot <filename>",
+		Short: "Write a snapshot of the settlement state to a file. Use \"-\" as filename in order to write to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			path, err := settlementStatePath(cmd)
+			if err != nil {
+				return err
+			}
+
+			stateStore, err := leveldb.NewStateStore(path, logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			snap, err := node.SettlementSnapshots(stateStore).Snapshot(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("settlement snapshot: %w", err)
+			}
+
+			var out io.Writer
+			if args[0] == "-" {
+				out = os.Stdout
+			} else {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if err := json.NewEncoder(out).Encode(snap); err != nil {
+				return fmt.Errorf("error writing snapshot: %w", err)
+			}
+
+			logger.Info("settlement snapshot written successfully", "total_records", len(snap.Records))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameSettlementDataDir, "", "directory of the separate settlement statestore, if the node is configured with one")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbSettlementKeysCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "settlement-keys",
+		Short: "Print the number of settlement records by key layout and the keys of unknown layout",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			path, err := settlementStatePath(cmd)
+			if err != nil {
+				return err
+			}
+
+			stateStore, err := leveldb.NewStateStore(path, logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			layout, err := node.SettlementKeyLayout()
+			if err != nil {
+				return fmt.Errorf("settlement key layout: %w", err)
+			}
+
+			counts, unknown, err := node.CountSettlementKeys(stateStore, layout)
+			if err != nil {
+				return fmt.Errorf("count settlement keys: %w", err)
+			}
+
+			for _, k := range layout.Keys() {
+				fmt.Printf("%-70s v%d %d\n", k.Pattern, k.Version, counts[k.Pattern])
+			}
+			for _, key := range unknown {
+				fmt.Printf("unknown key %s\n", key)
+			}
+
+			logger.Info("settlement keys checked", "unknown_keys", len(unknown))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameSettlementDataDir, "", "directory of the separate settlement statestore, if the node is configured with one")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbSettlementRestoreCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "settlement-restore <filename>",
+		Short: "Replace the settlement state with a snapshot from a file while the node is stopped. Use \"-\" as filename in order to feed from STDIN",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			path, err := settlementStatePath(cmd)
+			if err != nil {
+				return err
+			}
+
+			var in io.Reader
+			if args[0] == "-" {
+				in = os.Stdin
+			} else {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening input file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+			var snap snapshot.Snapshot
+			if err := json.NewDecoder(in).Decode(&snap); err != nil {
+				return fmt.Errorf("error reading snapshot: %w", err)
+			}
+
+			stateStore, err := leveldb.NewStateStore(path, logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			if err := node.SettlementSnapshots(stateStore).Restore(cmd.Context(), &snap); err != nil {
+				return fmt.Errorf("settlement restore: %w", err)
+			}
+
+			logger.Info("settlement state restored successfully", "total_records", len(snap.Records), "snapshot_time", time.Unix(snap.Time, 0))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameSettlementDataDir, "", "directory of the separate settlement statestore, if the node is configured with one")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbSettlementReplayCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "settlement-replay <filename>",
+		Short: "Apply a settlement replication journal to the statestore of a stopped standby node. Use \"-\" as filename in order to feed from STDIN",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			path, err := settlementStatePath(cmd)
+			if err != nil {
+				return err
+			}
+
+			var in io.Reader
+			if args[0] == "-" {
+				in = os.Stdin
+			} else {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening input file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			stateStore, err := leveldb.NewStateStore(path, logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			n, err := replicated.Replay(in, stateStore)
+			if err != nil {
+				return fmt.Errorf("settlement replay: %w", err)
+			}
+
+			logger.Info("settlement journal replayed successfully", "total_changes", n)
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameSettlementDataDir, "", "directory of the separate settlement statestore, if the node is configured with one")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
 func removeContent(path string) error {
 	dir, err := os.Open(path)
 	if err != nil {
@@ -313,3 +552,25 @@ func removeContent(path string) error {
 	}
 	return nil
 }
+
+// settlementStatePath returns the path of the statestore holding the
+// settlement state, which is the node statestore unless the node keeps it in a
+// separate one.
+func settlementStatePath(cmd *cobra.Command) (string, error) {
+	settlementDataDir, err := cmd.Flags().GetString(optionNameSettlementDataDir)
+	if err != nil {
+		return "", fmt.Errorf("get settlement-data-dir: %w", err)
+	}
+	if settlementDataDir != "" {
+		return settlementDataDir, nil
+	}
+
+	dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+	if err != nil {
+		return "", fmt.Errorf("get data-dir: %w", err)
+	}
+	if dataDir == "" {
+		return "", errors.New("no data-dir provided")
+	}
+	return filepath.Join(dataDir, "statestore"), nil
+}
@@ -7,7 +7,10 @@ package cmd
 import (
 	"fmt"
 	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/settlement/swap/erc20"
+	"github.com/ethersphere/bee/pkg/transaction/private"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
 	"github.com/spf13/cobra"
 	"strings"
 )
@@ -46,6 +49,15 @@ func (c *command) initDeployCmd() error {
 
 			defer stateStore.Close()
 
+			settlementDataDir := c.config.GetString(optionNameSettlementDataDir)
+			settlementStore, err := node.InitSettlementStateStore(cmd.Context(), logger, stateStore, settlementDataDir)
+			if err != nil {
+				return err
+			}
+			if settlementDataDir != "" {
+				defer settlementStore.Close()
+			}
+
 			signerConfig, err := c.configureSigner(cmd, logger)
 			if err != nil {
 				return err
@@ -54,11 +66,38 @@ func (c *command) initDeployCmd() error {
 
 			ctx := cmd.Context()
 
-			swapBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err := node.InitChain(
+			rpcAuth, err := node.RPCAuthOptions(
+				c.config.GetStringSlice(optionNameBlockchainRpcHeaders),
+				c.config.GetString(optionNameBlockchainRpcBasicAuth),
+				c.config.GetString(optionNameBlockchainRpcJWTSecret),
+			)
+			if err != nil {
+				return err
+			}
+
+			rpcRetry, err := node.RPCRetryOptions(
+				c.config.GetInt(optionNameBlockchainRpcRetries),
+				c.config.GetDuration(optionNameBlockchainRpcRetryBackoff),
+				c.config.GetDuration(optionNameBlockchainRpcMaxBackoff),
+				c.config.GetStringSlice(optionNameBlockchainRpcCallRetries),
+				retry.TimeoutOptions{
+					Margin: c.config.GetDuration(optionNameBlockchainRpcTimeoutMargin),
+					Min:    c.config.GetDuration(optionNameBlockchainRpcMinTimeout),
+					Max:    c.config.GetDuration(optionNameBlockchainRpcMaxTimeout),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			chainRuntime, overlayEthAddress, transactionService, err := node.InitChain(
 				ctx,
 				logger,
-				stateStore,
+				settlementStore,
 				blockchainRpcEndpoint,
+				rpcAuth,
+				rpcRetry,
+				private.Options{},
 				0,
 				signer,
 				blocktime,
@@ -67,8 +106,21 @@ func (c *command) initDeployCmd() error {
 			if err != nil {
 				return err
 			}
+			swapBackend := chainRuntime.Backend()
+			chainID := chainRuntime.ChainID().Int64()
 			defer swapBackend.Close()
-			defer transactionMonitor.Close()
+			defer chainRuntime.Close()
+
+			settlementStore, err = node.InitSettlementNamespace(logger, settlementStore, c.config.GetString(optionNameSettlementNamespace), chainID)
+			if err != nil {
+				return err
+			}
+
+			transactionService = chequebook.NewTimeoutTransactionService(transactionService, chequebook.Timeouts{
+				Call:    c.config.GetDuration(optionNameSwapCallTimeout),
+				Send:    c.config.GetDuration(optionNameSwapSendTimeout),
+				Receipt: c.config.GetDuration(optionNameSwapReceiptTimeout),
+			})
 
 			chequebookFactory, err := node.InitChequebookFactory(
 				logger,
@@ -77,6 +129,7 @@ func (c *command) initDeployCmd() error {
 				transactionService,
 				factoryAddress,
 				nil,
+				c.config.GetBool(optionNameSwapFactoryDeposit),
 			)
 			if err != nil {
 				return err
@@ -89,14 +142,26 @@ func (c *command) initDeployCmd() error {
 
 			erc20Service := erc20.New(transactionService, erc20Address)
 
+			// the chequebook is issued by the derived cheque signer key if one is configured
+			chequeSigner := chequebook.NewChequeSigner(signer, chainID)
+			if seedFile := c.config.GetString(optionNameSwapChequeSignerSeed); seedFile != "" {
+				hdSigner, err := node.InitHDChequeSigner(seedFile, c.config.GetString(optionNameSwapChequeSignerPath), chainID)
+				if err != nil {
+					return fmt.Errorf("cheque signer seed: %w", err)
+				}
+				defer hdSigner.Close()
+				chequeSigner = hdSigner
+			}
+
 			_, err = node.InitChequebookService(
 				ctx,
 				logger,
-				stateStore,
-				signer,
+				settlementStore,
+				chequeSigner,
 				chainID,
 				swapBackend,
 				overlayEthAddress,
+				overlayEthAddress,
 				transactionService,
 				chequebookFactory,
 				swapInitialDeposit,
@@ -0,0 +1,241 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/fleet"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameFleetNodes     = "node"
+	optionNameFleetAuthToken = "auth-token"
+	optionNameFleetTimeout   = "timeout"
+	optionNameFleetRetain    = "retain"
+	optionNameFleetTarget    = "target"
+	optionNameFleetBudget    = "budget"
+)
+
+func (c *command) initFleetCmd() {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Manage the chequebooks of several nodes",
+	}
+
+	cmd.PersistentFlags().StringSlice(optionNameFleetNodes, nil, "node to manage as name=api-endpoint, can be repeated")
+	cmd.PersistentFlags().String(optionNameFleetAuthToken, "", "bearer token for nodes running in restricted mode")
+	cmd.PersistentFlags().Duration(optionNameFleetTimeout, time.Minute, "timeout of the whole operation")
+
+	fleetBalancesCmd(cmd)
+	fleetSweepCmd(cmd)
+	fleetTopUpCmd(cmd)
+	fleetReconcileCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+// newFleetManager connects to all nodes given on the command line.
+func newFleetManager(ctx context.Context, cmd *cobra.Command) (*fleet.Manager, error) {
+	nodes, err := cmd.Flags().GetStringSlice(optionNameFleetNodes)
+	if err != nil {
+		return nil, fmt.Errorf("get nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes provided")
+	}
+	token, err := cmd.Flags().GetString(optionNameFleetAuthToken)
+	if err != nil {
+		return nil, fmt.Errorf("get auth token: %w", err)
+	}
+
+	fleetNodes := make([]fleet.Node, 0, len(nodes))
+	for _, node := range nodes {
+		name, endpoint, ok := strings.Cut(node, "=")
+		if !ok || name == "" || endpoint == "" {
+			return nil, fmt.Errorf("invalid node %q, expected name=api-endpoint", node)
+		}
+		remote, err := fleet.NewRemote(ctx, endpoint, fleet.WithAuthToken(token))
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", name, err)
+		}
+		fleetNodes = append(fleetNodes, fleet.Node{Name: name, Chequebook: remote})
+	}
+
+	return fleet.New(fleetNodes...), nil
+}
+
+// runFleetCmd runs f with a manager for the nodes given on the command line.
+func runFleetCmd(cmd *cobra.Command, f func(ctx context.Context, manager *fleet.Manager) error) error {
+	timeout, err := cmd.Flags().GetDuration(optionNameFleetTimeout)
+	if err != nil {
+		return fmt.Errorf("get timeout: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	manager, err := newFleetManager(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return f(ctx, manager)
+}
+
+// getAmountFlag parses a token amount flag, returning nil if it is empty.
+func getAmountFlag(cmd *cobra.Command, name string) (*big.Int, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", name, err)
+	}
+	if value == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return amount, nil
+}
+
+func printFleetResults(cmd *cobra.Command, results []fleet.Result) {
+	for _, result := range results {
+		if result.Err != nil {
+			cmd.Printf("%s\t%s\terror: %v\n", result.Name, result.Chequebook, result.Err)
+			continue
+		}
+		cmd.Printf("%s\t%s\t%s\t%s\n", result.Name, result.Chequebook, result.Amount, result.TransactionHash)
+	}
+}
+
+func fleetBalancesCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "balances",
+		Short: "Prints the chequebook balances of all nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFleetCmd(cmd, func(ctx context.Context, manager *fleet.Manager) error {
+				balances, err := manager.Balances(ctx)
+				if err != nil {
+					return err
+				}
+				for _, balance := range balances.Nodes {
+					if balance.Err != nil {
+						cmd.Printf("%s\t%s\terror: %v\n", balance.Name, balance.Chequebook, balance.Err)
+						continue
+					}
+					cmd.Printf("%s\t%s\t%s\t%s\n", balance.Name, balance.Chequebook, balance.TotalBalance, balance.AvailableBalance)
+				}
+				cmd.Printf("total\t\t%s\t%s\n", balances.TotalBalance, balances.AvailableBalance)
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(c)
+}
+
+func fleetSweepCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "sweep",
+		Short: "Withdraws the available chequebook balance exceeding the retained amount from all nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			retain, err := getAmountFlag(cmd, optionNameFleetRetain)
+			if err != nil {
+				return err
+			}
+			if retain == nil {
+				retain = big.NewInt(0)
+			}
+			return runFleetCmd(cmd, func(ctx context.Context, manager *fleet.Manager) error {
+				results, err := manager.Sweep(ctx, retain)
+				if err != nil {
+					return err
+				}
+				printFleetResults(cmd, results)
+				return nil
+			})
+		},
+	}
+
+	c.Flags().String(optionNameFleetRetain, "0", "available balance to keep in every chequebook")
+	cmd.AddCommand(c)
+}
+
+func fleetTopUpCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "topup",
+		Short: "Deposits into all chequebooks whose available balance is below the target",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := getAmountFlag(cmd, optionNameFleetTarget)
+			if err != nil {
+				return err
+			}
+			if target == nil {
+				return errors.New("no target provided")
+			}
+			budget, err := getAmountFlag(cmd, optionNameFleetBudget)
+			if err != nil {
+				return err
+			}
+			return runFleetCmd(cmd, func(ctx context.Context, manager *fleet.Manager) error {
+				results, err := manager.TopUp(ctx, target, budget)
+				if err != nil {
+					return err
+				}
+				printFleetResults(cmd, results)
+				return nil
+			})
+		},
+	}
+
+	c.Flags().String(optionNameFleetTarget, "", "available balance every chequebook is topped up to")
+	c.Flags().String(optionNameFleetBudget, "", "maximum amount deposited in total, unlimited if empty")
+	cmd.AddCommand(c)
+}
+
+func fleetReconcileCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Compares the cheques the nodes issued to each other with the cheques they received",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFleetCmd(cmd, func(ctx context.Context, manager *fleet.Manager) error {
+				report, err := manager.Reconcile(ctx)
+				if err != nil {
+					return err
+				}
+				for _, failed := range report.Errors {
+					cmd.Printf("%s\terror: %v\n", failed.Name, failed.Err)
+				}
+				for _, pair := range report.Pairs {
+					status := "ok"
+					if pair.Inconsistency != "" {
+						status = string(pair.Inconsistency)
+					}
+					cmd.Printf("%s\t%s\t%s\t%s\t%s\n", pair.Issuer, pair.Receiver, chequePayout(pair.Issued), chequePayout(pair.Received), status)
+				}
+				cmd.Printf("%d of %d pairs inconsistent\n", report.Inconsistent, len(report.Pairs))
+				if report.Inconsistent > 0 {
+					return errors.New("inconsistent cheques")
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(c)
+}
+
+// chequePayout returns the cumulative payout of the cheque, - if there is none.
+func chequePayout(cheque *fleet.ChequeRecord) string {
+	if cheque == nil {
+		return "-"
+	}
+	return cheque.CumulativePayout.String()
+}
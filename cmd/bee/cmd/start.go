@@ -317,11 +317,81 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		ResolverConnectionCfgs:        resolverCfgs,
 		BootnodeMode:                  bootNode,
 		BlockchainRpcEndpoint:         blockchainRpcEndpoint,
+		BlockchainRpcHeaders:          c.config.GetStringSlice(optionNameBlockchainRpcHeaders),
+		BlockchainRpcBasicAuth:        c.config.GetString(optionNameBlockchainRpcBasicAuth),
+		BlockchainRpcJWTSecret:        c.config.GetString(optionNameBlockchainRpcJWTSecret),
+		BlockchainRpcRetries:          c.config.GetInt(optionNameBlockchainRpcRetries),
+		BlockchainRpcRetryBackoff:     c.config.GetDuration(optionNameBlockchainRpcRetryBackoff),
+		BlockchainRpcRetryMaxBackoff:  c.config.GetDuration(optionNameBlockchainRpcMaxBackoff),
+		BlockchainRpcCallRetries:      c.config.GetStringSlice(optionNameBlockchainRpcCallRetries),
+		BlockchainRpcTimeoutMargin:    c.config.GetDuration(optionNameBlockchainRpcTimeoutMargin),
+		BlockchainRpcMinTimeout:       c.config.GetDuration(optionNameBlockchainRpcMinTimeout),
+		BlockchainRpcMaxTimeout:       c.config.GetDuration(optionNameBlockchainRpcMaxTimeout),
+		BlockchainRpcPrivateRelay:     c.config.GetString(optionNameBlockchainPrivateRelay),
+		BlockchainRpcPrivateDeadline:  c.config.GetDuration(optionNameBlockchainPrivateDeadline),
 		SwapFactoryAddress:            c.config.GetString(optionNameSwapFactoryAddress),
 		SwapLegacyFactoryAddresses:    c.config.GetStringSlice(optionNameSwapLegacyFactoryAddresses),
 		SwapInitialDeposit:            c.config.GetString(optionNameSwapInitialDeposit),
+		SwapMulticallAddress:          c.config.GetString(optionNameSwapMulticallAddress),
+		SwapMPCSignerEndpoint:         c.config.GetString(optionNameSwapMPCSignerEndpoint),
+		SwapMPCSignerParticipants:     c.config.GetStringSlice(optionNameSwapMPCSignerParticipants),
+		SwapMPCSignerThreshold:        c.config.GetInt(optionNameSwapMPCSignerThreshold),
+		SwapMPCSignerTimeout:          c.config.GetDuration(optionNameSwapMPCSignerTimeout),
+		SwapMPCSignerFallback:         c.config.GetString(optionNameSwapMPCSignerFallback),
+		SwapChequeSignerKeystore:      c.config.GetString(optionNameSwapChequeSignerKeystore),
+		SwapChequeSignerPasswordFile:  c.config.GetString(optionNameSwapChequeSignerPassword),
+		SwapChequeSignerSeedFile:      c.config.GetString(optionNameSwapChequeSignerSeed),
+		SwapChequeSignerPath:          c.config.GetString(optionNameSwapChequeSignerPath),
+		SwapChequeVerifierEndpoint:    c.config.GetString(optionNameSwapChequeVerifier),
+		SwapUserOperationBundler:      c.config.GetString(optionNameSwapUserOpBundler),
+		SwapUserOperationEntryPoint:   c.config.GetString(optionNameSwapUserOpEntryPoint),
+		SwapUserOperationAccount:      c.config.GetString(optionNameSwapUserOpAccount),
+		SwapUserOperationPaymaster:    c.config.GetBool(optionNameSwapUserOpPaymaster),
+		SwapGasPriceCaps:              c.config.GetStringSlice(optionNameSwapGasPriceCaps),
+		SwapGasPriceCapExpiry:         c.config.GetDuration(optionNameSwapGasPriceCapExpiry),
+		SwapChequeRateInterval:        c.config.GetDuration(optionNameSwapChequeRateInterval),
+		SwapChequeRateBurst:           c.config.GetInt(optionNameSwapChequeRateBurst),
+		SwapChequeGranularity:         c.config.GetString(optionNameSwapChequeGranularity),
+		SwapTotalIssuedTolerance:      c.config.GetString(optionNameSwapTotalIssuedTolerance),
+		SwapBalanceDeclineMax:         c.config.GetString(optionNameSwapBalanceDeclineMax),
+		SwapBalanceDeclineWindow:      c.config.GetDuration(optionNameSwapBalanceDeclineWindow),
+		SwapBalanceDeclinePause:       c.config.GetBool(optionNameSwapBalanceDeclinePause),
+		SwapPaymentBatchWindow:        c.config.GetDuration(optionNameSwapPaymentBatchWindow),
 		SwapEnable:                    c.config.GetBool(optionNameSwapEnable),
+		SwapBlocklistDuration:         c.config.GetDuration(optionNameSwapBlocklistDuration),
+		SwapWorkers:                   c.config.GetInt(optionNameSwapWorkers),
+		SwapWorkerQueueSize:           c.config.GetInt(optionNameSwapWorkerQueueSize),
+		SwapDrainTimeout:              c.config.GetDuration(optionNameSwapDrainTimeout),
+		SwapDrainMinDebt:              c.config.GetString(optionNameSwapDrainMinDebt),
+		SwapMinChequebookAge:          c.config.GetUint64(optionNameSwapMinChequebookAge),
+		SwapTrustedChequeScore:        c.config.GetUint64(optionNameSwapTrustedChequeScore),
+		SwapTrustedChequeReverify:     c.config.GetDuration(optionNameSwapTrustedChequeReverify),
+		SwapCallTimeout:               c.config.GetDuration(optionNameSwapCallTimeout),
+		SwapSendTimeout:               c.config.GetDuration(optionNameSwapSendTimeout),
+		SwapReceiptTimeout:            c.config.GetDuration(optionNameSwapReceiptTimeout),
+		SwapStatementInterval:         c.config.GetDuration(optionNameSwapStatementInterval),
+		SwapEscalationLadder:          c.config.GetString(optionNameSwapEscalationLadder),
+		SwapWithdrawWatchPeers:        c.config.GetInt(optionNameSwapWithdrawWatchPeers),
+		SwapWithdrawWatchCashout:      c.config.GetBool(optionNameSwapWithdrawWatchCashout),
+		SwapStalePeerPeriod:           c.config.GetDuration(optionNameSwapStalePeerPeriod),
+		SwapAutoCashoutThreshold:      c.config.GetString(optionNameSwapAutoCashoutThreshold),
+		SwapAutoCashoutMinInterval:    c.config.GetDuration(optionNameSwapAutoCashoutMinInterval),
+		SwapRegistryAddress:           c.config.GetString(optionNameSwapRegistryAddress),
+		SwapRegistryPublish:           c.config.GetBool(optionNameSwapRegistryPublish),
+		SwapCashoutMaxDelay:           c.config.GetDuration(optionNameSwapCashoutMaxDelay),
+		SwapCashoutParallelism:        c.config.GetInt(optionNameSwapCashoutParallelism),
+		SwapCashoutMaxInFlight:        c.config.GetInt(optionNameSwapCashoutMaxInFlight),
+		SwapCashoutCost:               c.config.GetString(optionNameSwapCashoutCost),
+		SwapConfirmations:             c.config.GetInt64(optionNameSwapConfirmations),
+		SwapSequencerUptimeFeed:       c.config.GetString(optionNameSwapSequencerUptimeFeed),
+		SwapL1FeeOracle:               c.config.GetString(optionNameSwapL1FeeOracle),
+		SwapFactoryDeposit:            c.config.GetBool(optionNameSwapFactoryDeposit),
 		ChequebookEnable:              c.config.GetBool(optionNameChequebookEnable),
+		SettlementEncryption:          c.config.GetBool(optionNameSettlementEncryption),
+		SettlementEncryptionSecret:    c.config.GetString(optionNameSettlementEncryptionSecret),
+		SettlementNamespace:           c.config.GetString(optionNameSettlementNamespace),
+		SettlementDataDir:             c.config.GetString(optionNameSettlementDataDir),
+		SettlementReplica:             c.config.GetString(optionNameSettlementReplica),
 		FullNodeMode:                  fullNode,
 		PostageContractAddress:        c.config.GetString(optionNamePostageContractAddress),
 		PostageContractStartBlock:     c.config.GetUint64(optionNamePostageContractStartBlock),
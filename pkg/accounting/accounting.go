@@ -13,11 +13,13 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pricing"
+	"github.com/ethersphere/bee/pkg/settlement/events"
 	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -60,6 +62,8 @@ type Interface interface {
 	CompensatedBalances() (map[string]*big.Int, error)
 	// PeerAccounting returns the associated values for all known peers
 	PeerAccounting() (map[string]PeerInfo, error)
+	// ImportBalance sets the starting balance of a peer without accounting history.
+	ImportBalance(peer swarm.Address, balance *big.Int) error
 }
 
 // Action represents an accounting action that can be applied
@@ -92,6 +96,14 @@ type creditAction struct {
 // PayFunc is the function used for async monetary settlement
 type PayFunc func(context.Context, swarm.Address, *big.Int)
 
+// PreviewPayFunc is the function used to check whether a monetary settlement
+// would be issued before it is sent.
+type PreviewPayFunc func(context.Context, swarm.Address, *big.Int) error
+
+// DisconnectThresholdFunc is the function informed about a peer which exceeded
+// the disconnect threshold with its debt.
+type DisconnectThresholdFunc func(peer swarm.Address, debt *big.Int)
+
 // RefreshFunc is the function used for sync time-based settlement
 type RefreshFunc func(context.Context, swarm.Address, *big.Int)
 
@@ -146,6 +158,8 @@ type accountingPeer struct {
 	fullNode                       bool     // the peer connected as full node or light node
 	totalDebtRepay                 *big.Int // since being connected, amount of cumulative debt settled by the peer
 	thresholdGrowAt                *big.Int // cumulative debt to be settled by the peer in order to give threshold upgrade
+	paymentBatch                   paymentBatchState
+	paymentBatchTimer              *time.Timer // settles at the end of the payment batch window
 }
 
 // Accounting is the main implementation of the accounting interface.
@@ -166,8 +180,12 @@ type Accounting struct {
 	disconnectLimit *big.Int
 	// function used for monetary settlement
 	payFunction PayFunc
+	// function used to check a monetary settlement before sending it
+	previewPayFunction PreviewPayFunc
 	// function used for time settlement
 	refreshFunction RefreshFunc
+	// function informed about peers exceeding the disconnect threshold
+	disconnectThresholdFunction DisconnectThresholdFunc
 	// allowance based on time used in pseudo settle
 	refreshRate      *big.Int
 	lightRefreshRate *big.Int
@@ -185,6 +203,12 @@ type Accounting struct {
 	lightDisconnectLimit     *big.Int
 	lightThresholdGrowStep   *big.Int
 	lightThresholdGrowChange *big.Int
+	// informed about balance changes caused by settlements
+	eventPublisher events.Publisher
+	// period over which debt is accumulated before paying it with one cheque
+	paymentBatchWindow time.Duration
+	// payments are no longer deferred once the node drains its settlements
+	draining atomic.Bool
 }
 
 var (
@@ -200,6 +224,8 @@ var (
 	ErrOverRelease = errors.New("attempting to release more balance than was reserved for peer")
 	// ErrEnforceRefresh
 	ErrEnforceRefresh = errors.New("allowance expectation refused")
+	// ErrBalanceExists denotes that a balance can not be imported for a peer with accounting history.
+	ErrBalanceExists = errors.New("peer already has a balance")
 )
 
 // NewAccounting creates a new Accounting instance with the provided options.
@@ -469,7 +495,7 @@ func (a *Accounting) settle(peer swarm.Address, balance *accountingPeer) error {
 			}
 		}
 
-		if a.payFunction != nil && !balance.paymentOngoing {
+		if a.payFunction != nil && !balance.paymentOngoing && !a.batchPayment(peer, balance, paymentAmount) {
 			// if a settlement failed recently, wait until failedSettlementInterval before trying again
 			differenceInSeconds := now.Unix() - balance.lastSettlementFailureTimestamp
 			if differenceInSeconds > failedSettlementInterval {
@@ -510,7 +536,7 @@ func (a *Accounting) settle(peer swarm.Address, balance *accountingPeer) error {
 							balance.refreshReservedBalance = new(big.Int).Add(balance.refreshReservedBalance, paymentAmount)
 						}
 						a.wg.Add(1)
-						go a.payFunction(context.Background(), peer, paymentAmount)
+						go a.pay(context.Background(), peer, paymentAmount)
 					}
 				}
 			}
@@ -589,6 +615,38 @@ func (a *Accounting) CompensatedBalance(peer swarm.Address) (compensated *big.In
 	return compensated, nil
 }
 
+// ImportBalance sets the starting balance of the peer carried over from
+// another node, e.g. after data loss or when migrating from another
+// implementation. A positive balance is debt of the peer, a negative one debt
+// to the peer. It fails with ErrBalanceExists if the peer already has a
+// different non-zero balance or reserved balance, so that no amount
+// accumulated since the node started is overwritten.
+func (a *Accounting) ImportBalance(peer swarm.Address, balance *big.Int) error {
+	accountingPeer := a.getAccountingPeer(peer)
+
+	accountingPeer.lock.Lock()
+	defer accountingPeer.lock.Unlock()
+
+	currentBalance, err := a.Balance(peer)
+	if err != nil && !errors.Is(err, ErrPeerNoBalance) {
+		return err
+	}
+	if currentBalance.Cmp(balance) == 0 {
+		return nil
+	}
+	if currentBalance.Sign() != 0 || accountingPeer.reservedBalance.Sign() != 0 || accountingPeer.shadowReservedBalance.Sign() != 0 {
+		return ErrBalanceExists
+	}
+
+	a.logger.Info("importing peer balance", "peer_address", peer, "balance", balance)
+
+	if err := a.store.Put(peerBalanceKey(peer), balance); err != nil {
+		return fmt.Errorf("failed to persist balance: %w", err)
+	}
+	a.publishBalanceChange(peer, balance, balance)
+	return nil
+}
+
 // peerBalanceKey returns the balance storage key for the given peer.
 func peerBalanceKey(peer swarm.Address) string {
 	return fmt.Sprintf("%s%s", balancesPrefix, peer.String())
@@ -869,6 +927,22 @@ func surplusBalanceKeyPeer(key []byte) (swarm.Address, error) {
 	return addr, nil
 }
 
+// PaymentThresholdForPeer returns the payment threshold at which the peer is
+// expected to pay us.
+func (a *Accounting) PaymentThresholdForPeer(peer swarm.Address) *big.Int {
+	accountingPeer := a.getAccountingPeer(peer)
+
+	accountingPeer.lock.Lock()
+	defer accountingPeer.lock.Unlock()
+
+	return new(big.Int).Set(accountingPeer.paymentThresholdForPeer)
+}
+
+// MinimumPayment returns the smallest debt which is paid with a cheque.
+func (a *Accounting) MinimumPayment() *big.Int {
+	return new(big.Int).Set(a.minimumPayment)
+}
+
 // PeerDebt returns the positive part of the sum of the outstanding balance and the shadow reserve
 func (a *Accounting) PeerDebt(peer swarm.Address) (*big.Int, error) {
 	accountingPeer := a.getAccountingPeer(peer)
@@ -1001,6 +1075,7 @@ func (a *Accounting) NotifyPaymentSent(peer swarm.Address, amount *big.Int, rece
 		a.logger.Error(err, "notify payment sent; failed to persist balance")
 		return
 	}
+	a.publishBalanceChange(peer, amount, nextBalance)
 
 	err = a.decreaseOriginatedBalanceBy(peer, amount)
 	if err != nil {
@@ -1078,6 +1153,7 @@ func (a *Accounting) NotifyPaymentReceived(peer swarm.Address, amount *big.Int)
 	if err != nil {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
+	a.publishBalanceChange(peer, new(big.Int).Neg(amount), nextBalance)
 
 	// If payment would have put us into debt, rather, let's add to surplusBalance,
 	// so as that an oversettlement attempt creates balance for future forwarding services
@@ -1180,6 +1256,7 @@ func (a *Accounting) NotifyRefreshmentSent(peer swarm.Address, attemptedAmount,
 		a.logger.Error(err, "notifyrefreshmentsent failed to persist balance")
 		return
 	}
+	a.publishBalanceChange(peer, amount, newBalance)
 
 	// update originated balance
 	err = a.decreaseOriginatedBalanceTo(peer, newBalance)
@@ -1220,6 +1297,7 @@ func (a *Accounting) NotifyRefreshmentReceived(peer swarm.Address, amount *big.I
 	if err != nil {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
+	a.publishBalanceChange(peer, new(big.Int).Neg(amount), nextBalance)
 
 	accountingPeer.refreshReceivedTimestamp = timestamp
 
@@ -1375,6 +1453,7 @@ func (d *debitAction) Apply() error {
 		if err != nil {
 			disconnectFor = 10
 		}
+		a.notifyDisconnectThreshold(d.peer, nextBalance)
 		return p2p.NewBlockPeerError(time.Duration(disconnectFor)*time.Second, ErrDisconnectThresholdExceeded)
 
 	}
@@ -1544,8 +1623,71 @@ func (a *Accounting) SetPayFunc(f PayFunc) {
 	a.payFunction = f
 }
 
+// SetPreviewPayFunc sets the function checking monetary settlements before
+// they are sent. Settlements it rejects fail without a cheque being signed.
+func (a *Accounting) SetPreviewPayFunc(f PreviewPayFunc) {
+	a.previewPayFunction = f
+}
+
+// pay sends the monetary settlement unless its preview rejects it.
+func (a *Accounting) pay(ctx context.Context, peer swarm.Address, amount *big.Int) {
+	if a.previewPayFunction != nil {
+		if err := a.previewPayFunction(ctx, peer, amount); err != nil {
+			a.NotifyPaymentSent(peer, amount, fmt.Errorf("preview payment: %w", err))
+			return
+		}
+	}
+	a.payFunction(ctx, peer, amount)
+}
+
+// SetDisconnectThresholdFunc sets the function informed about peers which
+// exceeded the disconnect threshold.
+func (a *Accounting) SetDisconnectThresholdFunc(f DisconnectThresholdFunc) {
+	a.disconnectThresholdFunction = f
+}
+
+// notifyDisconnectThreshold informs the disconnect threshold function about
+// the debt of the peer. It is called with the lock of the peer held and the
+// function may disconnect the peer, so it runs asynchronously.
+func (a *Accounting) notifyDisconnectThreshold(peer swarm.Address, debt *big.Int) {
+	if a.disconnectThresholdFunction == nil {
+		return
+	}
+	debt = new(big.Int).Set(debt)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.disconnectThresholdFunction(peer, debt)
+	}()
+}
+
+// SetPaymentBatchWindow sets the period over which the debt towards a peer is
+// accumulated once a payment is due before it is paid with a single cheque.
+// Zero disables batching.
+func (a *Accounting) SetPaymentBatchWindow(window time.Duration) {
+	a.paymentBatchWindow = window
+}
+
+// SetEventPublisher sets the publisher informed about balance changes caused by settlements.
+func (a *Accounting) SetEventPublisher(p events.Publisher) {
+	a.eventPublisher = p
+}
+
+func (a *Accounting) publishBalanceChange(peer swarm.Address, amount, balance *big.Int) {
+	if a.eventPublisher == nil {
+		return
+	}
+	a.eventPublisher.Publish(events.Event{
+		Type:    events.TypeBalanceChanged,
+		Peer:    peer,
+		Amount:  amount,
+		Balance: new(big.Int).Set(balance),
+	})
+}
+
 // Close hangs up running websockets on shutdown.
 func (a *Accounting) Close() error {
+	a.stopPaymentBatches()
 	a.wg.Wait()
 	return nil
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/settlement/events"
 	"github.com/ethersphere/bee/pkg/statestore/mock"
 
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -583,10 +584,20 @@ func TestAccountingDisconnect(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	type disconnectCall struct {
+		peer swarm.Address
+		debt *big.Int
+	}
+	disconnectC := make(chan disconnectCall, 1)
+	acc.SetDisconnectThresholdFunc(func(peer swarm.Address, debt *big.Int) {
+		disconnectC <- disconnectCall{peer: peer, debt: debt}
+	})
+
 	acc.Connect(peer1Addr, true)
 
 	// put the peer 1 unit away from disconnect
-	debitAction, err := acc.PrepareDebit(context.Background(), peer1Addr, uint64(testRefreshRate)+(testPaymentThreshold.Uint64()*(100+uint64(testPaymentTolerance))/100)-1)
+	disconnectDebt := uint64(testRefreshRate) + (testPaymentThreshold.Uint64() * (100 + uint64(testPaymentTolerance)) / 100)
+	debitAction, err := acc.PrepareDebit(context.Background(), peer1Addr, disconnectDebt-1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -611,6 +622,18 @@ func TestAccountingDisconnect(t *testing.T) {
 	if !errors.As(err, &e) {
 		t.Fatalf("expected BlockPeerError, got %v", err)
 	}
+
+	select {
+	case call := <-disconnectC:
+		if !call.peer.Equal(peer1Addr) {
+			t.Fatalf("disconnect threshold exceeded by %v, want %v", call.peer, peer1Addr)
+		}
+		if call.debt.Uint64() != disconnectDebt {
+			t.Fatalf("got debt %d, want %d", call.debt, disconnectDebt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("disconnect threshold function not called")
+	}
 }
 
 // TestAccountingCallSettlement tests that settlement is called correctly if the payment threshold is hit
@@ -871,6 +894,169 @@ func TestAccountingCallSettlementMonetary(t *testing.T) {
 
 }
 
+func TestAccountingCallSettlementBatched(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+
+	ts := int64(1000)
+	acc.SetTime(ts)
+	acc.SetPaymentBatchWindow(100 * time.Millisecond)
+
+	// refreshments do not settle anything so the whole debt is paid
+	acc.SetRefreshFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		acc.NotifyRefreshmentSent(peer, amount, big.NewInt(0), ts*1000, 0, nil)
+	})
+
+	paychan := make(chan paymentCall, 2)
+	acc.SetPayFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		acc.NotifyPaymentSent(peer, amount, nil)
+		paychan <- paymentCall{peer: peer, amount: amount}
+	})
+
+	peer1Addr, err := swarm.ParseHexAddress("00112233")
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.Connect(peer1Addr, true)
+
+	credit := func(price uint64) {
+		t.Helper()
+		creditAction, err := acc.PrepareCredit(context.Background(), peer1Addr, price, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := creditAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		creditAction.Cleanup()
+	}
+
+	// both credits exceed the early payment threshold but are paid together
+	credit(9200)
+	credit(200)
+
+	select {
+	case call := <-paychan:
+		t.Fatalf("payment of %d sent before the end of the batch window", call.amount)
+	default:
+	}
+
+	select {
+	case call := <-paychan:
+		if call.amount.Cmp(big.NewInt(9400)) != 0 {
+			t.Fatalf("paid wrong amount. got %d wanted %d", call.amount, 9400)
+		}
+		if !call.peer.Equal(peer1Addr) {
+			t.Fatalf("wrong peer address got %v wanted %v", call.peer, peer1Addr)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timeout waiting for payment")
+	}
+
+	// debt close to the payment threshold is paid without waiting
+	credit(9600)
+
+	select {
+	case call := <-paychan:
+		if call.amount.Cmp(big.NewInt(9600)) != 0 {
+			t.Fatalf("paid wrong amount. got %d wanted %d", call.amount, 9600)
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("timeout waiting for payment")
+	}
+}
+
+func TestAccountingCallSettlementPreviewRejected(t *testing.T) {
+	t.Parallel()
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, log.Noop, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+
+	ts := int64(1000)
+	acc.SetTime(ts)
+
+	// refreshments do not settle anything so the whole debt is due
+	refreshchan := make(chan struct{}, 1)
+	acc.SetRefreshFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		acc.NotifyRefreshmentSent(peer, amount, big.NewInt(0), ts*1000, 0, nil)
+		refreshchan <- struct{}{}
+	})
+	acc.SetPayFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		t.Errorf("payment of %d sent despite the rejected preview", amount)
+		acc.NotifyPaymentSent(peer, amount, nil)
+	})
+	previewchan := make(chan paymentCall, 1)
+	acc.SetPreviewPayFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) error {
+		previewchan <- paymentCall{peer: peer, amount: amount}
+		return errors.New("rejected")
+	})
+
+	peer1Addr, err := swarm.ParseHexAddress("00112233")
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.Connect(peer1Addr, true)
+
+	credit := func(price uint64) {
+		t.Helper()
+		creditAction, err := acc.PrepareCredit(context.Background(), peer1Addr, price, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := creditAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		creditAction.Cleanup()
+	}
+
+	credit(9200)
+	select {
+	case <-refreshchan:
+	case <-time.After(1 * time.Second):
+		t.Fatal("timeout waiting for refreshment")
+	}
+	credit(200)
+
+	select {
+	case call := <-previewchan:
+		if call.amount.Cmp(big.NewInt(9200)) != 0 {
+			t.Fatalf("previewed wrong amount. got %d wanted %d", call.amount, 9200)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timeout waiting for payment preview")
+	}
+
+	for start := time.Now(); acc.IsPaymentOngoing(peer1Addr); time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("payment still ongoing after rejected preview")
+		}
+	}
+
+	balance, err := acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(-9400); balance.Cmp(want) != 0 {
+		t.Fatalf("got balance %d, want %d", balance, want)
+	}
+}
+
 func TestAccountingCallSettlementTooSoon(t *testing.T) {
 	t.Parallel()
 
@@ -1251,6 +1437,55 @@ func TestAccountingNotifyPaymentReceived(t *testing.T) {
 	}
 }
 
+func TestAccountingBalanceChangeEvents(t *testing.T) {
+	t.Parallel()
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, log.Noop, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := events.NewFeed()
+	defer feed.Close()
+	acc.SetEventPublisher(feed)
+	c, cancel := feed.Subscribe()
+	defer cancel()
+
+	peer := swarm.MustParseHexAddress("00112233")
+	acc.Connect(peer, true)
+
+	debitAction, err := acc.PrepareDebit(context.Background(), peer, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := debitAction.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	debitAction.Cleanup()
+
+	// debits are not settlements and do not emit events
+	select {
+	case e := <-c:
+		t.Fatalf("unexpected event %+v", e)
+	default:
+	}
+
+	if err := acc.NotifyPaymentReceived(peer, big.NewInt(60)); err != nil {
+		t.Fatal(err)
+	}
+
+	e := <-c
+	if e.Type != events.TypeBalanceChanged || !e.Peer.Equal(peer) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if e.Amount.Int64() != -60 || e.Balance.Int64() != 40 {
+		t.Fatalf("got amount %d and balance %d, want -60 and 40", e.Amount, e.Balance)
+	}
+}
+
 type pricingMock struct {
 	called           bool
 	peer             swarm.Address
@@ -2018,3 +2253,117 @@ func debitAndReceivePayment(t *testing.T, acc *accounting.Accounting, peer1Addr
 	}
 
 }
+
+func TestAccountingImportBalance(t *testing.T) {
+	t.Parallel()
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, log.Noop, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+	peer2Addr := swarm.MustParseHexAddress("00112244")
+
+	if err := acc.ImportBalance(peer1Addr, big.NewInt(-500)); err != nil {
+		t.Fatal(err)
+	}
+	balance, err := acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Int64() != -500 {
+		t.Fatalf("got balance %d, want %d", balance, -500)
+	}
+
+	// importing the same balance again has no effect
+	if err := acc.ImportBalance(peer1Addr, big.NewInt(-500)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acc.ImportBalance(peer1Addr, big.NewInt(100)); !errors.Is(err, accounting.ErrBalanceExists) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrBalanceExists)
+	}
+
+	acc.Connect(peer2Addr, true)
+	debitAction, err := acc.PrepareDebit(context.Background(), peer2Addr, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := debitAction.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	debitAction.Cleanup()
+
+	if err := acc.ImportBalance(peer2Addr, big.NewInt(300)); !errors.Is(err, accounting.ErrBalanceExists) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrBalanceExists)
+	}
+}
+
+func TestAccountingDrain(t *testing.T) {
+	t.Parallel()
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, log.Noop, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+
+	ts := int64(1000)
+	acc.SetTime(ts)
+	acc.SetPaymentBatchWindow(time.Hour)
+
+	// refreshments do not settle anything so the whole debt is paid
+	acc.SetRefreshFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		acc.NotifyRefreshmentSent(peer, amount, big.NewInt(0), ts*1000, 0, nil)
+	})
+
+	var (
+		mu       sync.Mutex
+		payments = make(map[string]*big.Int)
+	)
+	acc.SetPayFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) {
+		mu.Lock()
+		payments[peer.String()] = amount
+		mu.Unlock()
+		acc.NotifyPaymentSent(peer, amount, nil)
+	})
+
+	batched := swarm.MustParseHexAddress("00112233")
+	indebted := swarm.MustParseHexAddress("00112244")
+	small := swarm.MustParseHexAddress("00112255")
+	for _, peer := range []swarm.Address{batched, indebted, small} {
+		acc.Connect(peer, true)
+	}
+
+	credit := func(peer swarm.Address, price uint64) {
+		t.Helper()
+		creditAction, err := acc.PrepareCredit(context.Background(), peer, price, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := creditAction.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		creditAction.Cleanup()
+	}
+	credit(batched, 9200)
+	credit(indebted, 3000)
+	credit(small, 500)
+
+	if err := acc.Drain(context.Background(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payments) != 2 || payments[batched.String()].Cmp(big.NewInt(9200)) != 0 || payments[indebted.String()].Cmp(big.NewInt(3000)) != 0 {
+		t.Fatalf("unexpected payments %v", payments)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// paymentBatchState is the state of the payment batch window of a peer.
+type paymentBatchState int
+
+const (
+	paymentBatchNone    paymentBatchState = iota // no payment is due
+	paymentBatchOpen                             // a payment is due and debt is accumulated
+	paymentBatchExpired                          // the window passed, the payment is sent with the next settlement
+)
+
+// batchPayment reports whether the payment to the peer is deferred. The first
+// payment which becomes due opens a batch window at the end of which the debt
+// accumulated in the meantime is paid with a single cheque. Payments are not
+// deferred once the debt comes close to the payment threshold of the peer, as
+// reserving more would fail. The lock on the accountingPeer must be held when
+// called.
+func (a *Accounting) batchPayment(peer swarm.Address, balance *accountingPeer, debt *big.Int) bool {
+	if a.paymentBatchWindow <= 0 || a.draining.Load() {
+		return false
+	}
+
+	limit := new(big.Int).Add(balance.earlyPayment, balance.paymentThreshold)
+	limit.Rsh(limit, 1)
+	if debt.Cmp(limit) >= 0 {
+		a.stopPaymentBatch(balance)
+		return false
+	}
+
+	switch balance.paymentBatch {
+	case paymentBatchNone:
+		balance.paymentBatch = paymentBatchOpen
+		a.metrics.BatchedPaymentsCount.Inc()
+		a.wg.Add(1)
+		balance.paymentBatchTimer = time.AfterFunc(a.paymentBatchWindow, func() {
+			defer a.wg.Done()
+			a.settleBatch(peer)
+		})
+		return true
+	case paymentBatchOpen:
+		return true
+	default:
+		balance.paymentBatch = paymentBatchNone
+		return false
+	}
+}
+
+// settleBatch settles with the peer at the end of its payment batch window.
+func (a *Accounting) settleBatch(peer swarm.Address) {
+	accountingPeer := a.getAccountingPeer(peer)
+
+	accountingPeer.lock.Lock()
+	defer accountingPeer.lock.Unlock()
+
+	accountingPeer.paymentBatchTimer = nil
+	if accountingPeer.paymentBatch != paymentBatchOpen {
+		return
+	}
+	accountingPeer.paymentBatch = paymentBatchExpired
+	if err := a.settle(peer, accountingPeer); err != nil {
+		a.logger.Error(err, "failed to settle batched payment", "peer_address", peer)
+	}
+}
+
+// stopPaymentBatches stops the timers of the open payment batch windows.
+func (a *Accounting) stopPaymentBatches() {
+	a.accountingPeersMu.Lock()
+	peers := make([]*accountingPeer, 0, len(a.accountingPeers))
+	for _, peer := range a.accountingPeers {
+		peers = append(peers, peer)
+	}
+	a.accountingPeersMu.Unlock()
+
+	for _, peer := range peers {
+		peer.lock.Lock()
+		a.stopPaymentBatch(peer)
+		peer.lock.Unlock()
+	}
+}
+
+// stopPaymentBatch closes the payment batch window of the peer. The lock on
+// the accountingPeer must be held when called.
+func (a *Accounting) stopPaymentBatch(balance *accountingPeer) {
+	if balance.paymentBatchTimer != nil && balance.paymentBatchTimer.Stop() {
+		a.wg.Done()
+	}
+	balance.paymentBatchTimer = nil
+	balance.paymentBatch = paymentBatchNone
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Drain settles the debts which would otherwise stay unsettled over a
+// restart. The payments deferred in open payment batch windows are sent right
+// away and no further payments are deferred. If minDebt is not nil, connected
+// peers whose debt is at least minDebt are paid a final cheque. Drain then
+// waits until the payments in flight are done or ctx is done.
+func (a *Accounting) Drain(ctx context.Context, minDebt *big.Int) error {
+	a.draining.Store(true)
+
+	a.accountingPeersMu.Lock()
+	peers := make(map[string]*accountingPeer, len(a.accountingPeers))
+	for key, peer := range a.accountingPeers {
+		peers[key] = peer
+	}
+	a.accountingPeersMu.Unlock()
+
+	for key, balance := range peers {
+		peer, err := swarm.ParseHexAddress(key)
+		if err != nil {
+			return err
+		}
+		if err := balance.lock.TryLock(ctx); err != nil {
+			return err
+		}
+		err = a.drainPeer(peer, balance, minDebt)
+		balance.lock.Unlock()
+		if err != nil {
+			a.logger.Error(err, "failed to settle with peer before shutdown", "peer_address", peer)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drainPeer sends the payment deferred in the open payment batch window of the
+// peer and a final cheque if the debt is at least minDebt. The lock on the
+// accountingPeer must be held when called.
+func (a *Accounting) drainPeer(peer swarm.Address, balance *accountingPeer, minDebt *big.Int) error {
+	if balance.paymentBatch == paymentBatchOpen {
+		a.stopPaymentBatch(balance)
+		balance.paymentBatch = paymentBatchExpired
+		if err := a.settle(peer, balance); err != nil {
+			return err
+		}
+	}
+
+	if minDebt == nil || a.payFunction == nil || !balance.connected || balance.paymentOngoing {
+		return nil
+	}
+
+	// only the debt originated by the node is paid, like in regular settlements
+	originatedBalance, err := a.OriginatedBalance(peer)
+	if err != nil {
+		if errors.Is(err, ErrPeerNoBalance) {
+			return nil
+		}
+		return err
+	}
+	currentBalance, err := a.Balance(peer)
+	if err != nil {
+		if errors.Is(err, ErrPeerNoBalance) {
+			return nil
+		}
+		return err
+	}
+	paymentAmount := new(big.Int).Neg(originatedBalance)
+	if debt := new(big.Int).Neg(currentBalance); paymentAmount.Cmp(debt) > 0 {
+		paymentAmount = debt
+	}
+	paymentAmount.Sub(paymentAmount, balance.shadowReservedBalance)
+	if paymentAmount.Cmp(minDebt) < 0 || paymentAmount.Cmp(a.minimumPayment) < 0 {
+		return nil
+	}
+
+	a.logger.Debug("paying final cheque before shutdown", "peer_address", peer, "amount", paymentAmount)
+	balance.paymentOngoing = true
+	balance.shadowReservedBalance.Add(balance.shadowReservedBalance, paymentAmount)
+	if balance.refreshOngoing {
+		balance.refreshReservedBalance = new(big.Int).Add(balance.refreshReservedBalance, paymentAmount)
+	}
+	a.wg.Add(1)
+	go a.pay(context.Background(), peer, paymentAmount)
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the accounting.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     balancesPrefix + "<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "balance with the peer, positive if the peer owes us",
+	},
+	{
+		Pattern:     balancesSurplusPrefix + "<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount the peer paid beyond its debt",
+	},
+	{
+		Pattern:     balancesOriginatedPrefix + "<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "part of the debt to the peer originating from own requests",
+	},
+}
@@ -30,6 +30,7 @@ type metrics struct {
 	SettleErrorCount                         prometheus.Counter
 	PaymentAttemptCount                      prometheus.Counter
 	PaymentErrorCount                        prometheus.Counter
+	BatchedPaymentsCount                     prometheus.Counter
 	ErrTimeOutOfSyncAlleged                  prometheus.Counter
 	ErrTimeOutOfSyncRecent                   prometheus.Counter
 	ErrTimeOutOfSyncInterval                 prometheus.Counter
@@ -138,6 +139,12 @@ func newMetrics() metrics {
 			Name:      "payment_error_count",
 			Help:      "Number of errors occurring during payment op",
 		}),
+		BatchedPaymentsCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "batched_payments_count",
+			Help:      "Number of payments deferred to accumulate debt over the payment batch window",
+		}),
 		PaymentAttemptCount: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -28,6 +28,7 @@ type Service struct {
 	compensatedBalancesFunc func() (map[string]*big.Int, error)
 	peerAccountingFunc      func() (map[string]accounting.PeerInfo, error)
 	balanceSurplusFunc      func(swarm.Address) (*big.Int, error)
+	importBalanceFunc       func(swarm.Address, *big.Int) error
 }
 
 type debitAction struct {
@@ -99,6 +100,13 @@ func WithPeerAccountingFunc(f func() (map[string]accounting.PeerInfo, error)) Op
 	})
 }
 
+// WithImportBalanceFunc sets the mock ImportBalance function
+func WithImportBalanceFunc(f func(swarm.Address, *big.Int) error) Option {
+	return optionFunc(func(s *Service) {
+		s.importBalanceFunc = f
+	})
+}
+
 // NewAccounting creates the mock accounting implementation
 func NewAccounting(opts ...Option) *Service {
 	mock := new(Service)
@@ -239,6 +247,17 @@ func (s *Service) Disconnect(peer swarm.Address) {
 
 }
 
+// ImportBalance is the mock function wrapper that calls the set implementation
+func (s *Service) ImportBalance(peer swarm.Address, balance *big.Int) error {
+	if s.importBalanceFunc != nil {
+		return s.importBalanceFunc(peer, balance)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.balances[peer.String()] = new(big.Int).Set(balance)
+	return nil
+}
+
 func (s *Service) SurplusBalance(peer swarm.Address) (*big.Int, error) {
 	if s.balanceFunc != nil {
 		return s.balanceSurplusFunc(peer)
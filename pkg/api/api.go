@@ -46,9 +46,17 @@ import (
 	"github.com/ethersphere/bee/pkg/resolver/client/ens"
 	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
 	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/settlement/swap/erc20"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
 	"github.com/ethersphere/bee/pkg/status"
 	"github.com/ethersphere/bee/pkg/steward"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -84,10 +92,11 @@ const (
 	SwarmPostageBatchIdHeader = "Swarm-Postage-Batch-Id"
 	SwarmDeferredUploadHeader = "Swarm-Deferred-Upload"
 
-	ImmutableHeader = "Immutable"
-	GasPriceHeader  = "Gas-Price"
-	GasLimitHeader  = "Gas-Limit"
-	ETagHeader      = "ETag"
+	ImmutableHeader         = "Immutable"
+	GasPriceHeader          = "Gas-Price"
+	GasLimitHeader          = "Gas-Limit"
+	PrivateSubmissionHeader = "Private-Submission"
+	ETagHeader              = "ETag"
 
 	AuthorizationHeader      = "Authorization"
 	AcceptEncodingHeader     = "Accept-Encoding"
@@ -174,8 +183,31 @@ type Service struct {
 	p2p            p2p.DebugService
 	accounting     accounting.Interface
 	chequebook     chequebook.Service
-	pseudosettle   settlement.Interface
-	pingpong       pingpong.Interface
+	factories      chequebook.TrustedFactories
+	contracts      chequebook.ContractInspector
+	chequeVerifier chequebook.ChequeVerifier
+	chequeSigner   chequebook.PassphraseRotator
+	issuedGuard    chequebook.TotalIssuedGuard
+	spend          *analytics.Spend
+	earnings       *analytics.Earnings
+	ledger         *analytics.Ledger
+	purposes       *analytics.Purposes
+	auditLog       *auditlog.Log
+	snapshots      *snapshot.Service
+	stateUsage     *usage.Store
+	keyLayout      *keylayout.Registry
+	summaryCache   settlementsSummaryCache
+
+	settlementEvents      *events.Feed
+	cashoutOptimizer      *cashouttiming.Optimizer
+	cashoutDataFee        func(context.Context) (*big.Int, error)
+	settlementRates       func() (exchangeRate, deduction *big.Int, err error)
+	deductedBy            func(peer swarm.Address) (bool, error)
+	minimumPayment        *big.Int
+	runtimeConfig         *runtimeconfig.Registry
+	settlementMiddlewares []SettlementMiddleware
+	pseudosettle          settlement.Interface
+	pingpong              pingpong.Interface
 
 	batchStore postage.Storer
 	syncStatus func() (bool, error)
@@ -228,28 +260,49 @@ type Options struct {
 }
 
 type ExtraOptions struct {
-	Pingpong         pingpong.Interface
-	TopologyDriver   topology.Driver
-	LightNodes       *lightnode.Container
-	Accounting       accounting.Interface
-	Pseudosettle     settlement.Interface
-	Swap             swap.Interface
-	Chequebook       chequebook.Service
-	BlockTime        time.Duration
-	Tags             *tags.Tags
-	Storer           storage.Storer
-	Resolver         resolver.Interface
-	Pss              pss.Interface
-	TraversalService traversal.Traverser
-	Pinning          pinning.Interface
-	FeedFactory      feeds.Factory
-	Post             postage.Service
-	PostageContract  postagecontract.Interface
-	Staking          staking.Contract
-	Steward          steward.Interface
-	SyncStatus       func() (bool, error)
-	IndexDebugger    StorageIndexDebugger
-	NodeStatus       *status.Service
+	Pingpong              pingpong.Interface
+	TopologyDriver        topology.Driver
+	LightNodes            *lightnode.Container
+	Accounting            accounting.Interface
+	Pseudosettle          settlement.Interface
+	Swap                  swap.Interface
+	Chequebook            chequebook.Service
+	TrustedFactories      chequebook.TrustedFactories
+	Contracts             chequebook.ContractInspector
+	ChequeVerifier        chequebook.ChequeVerifier
+	ChequeSigner          chequebook.PassphraseRotator
+	TotalIssuedGuard      chequebook.TotalIssuedGuard
+	SpendAnalytics        *analytics.Spend
+	Earnings              *analytics.Earnings
+	Ledger                *analytics.Ledger
+	SpendPurposes         *analytics.Purposes
+	AuditLog              *auditlog.Log
+	Snapshots             *snapshot.Service
+	StateStoreUsage       *usage.Store
+	KeyLayout             *keylayout.Registry
+	SettlementEvents      *events.Feed
+	CashoutOptimizer      *cashouttiming.Optimizer
+	CashoutDataFee        func(context.Context) (*big.Int, error)
+	SettlementRates       func() (exchangeRate, deduction *big.Int, err error)
+	DeductedBy            func(peer swarm.Address) (bool, error)
+	MinimumPayment        *big.Int
+	RuntimeConfig         *runtimeconfig.Registry
+	SettlementMiddlewares []SettlementMiddleware
+	BlockTime             time.Duration
+	Tags                  *tags.Tags
+	Storer                storage.Storer
+	Resolver              resolver.Interface
+	Pss                   pss.Interface
+	TraversalService      traversal.Traverser
+	Pinning               pinning.Interface
+	FeedFactory           feeds.Factory
+	Post                  postage.Service
+	PostageContract       postagecontract.Interface
+	Staking               staking.Contract
+	Steward               steward.Interface
+	SyncStatus            func() (bool, error)
+	IndexDebugger         StorageIndexDebugger
+	NodeStatus            *status.Service
 }
 
 func New(publicKey, pssPublicKey ecdsa.PublicKey, ethereumAddress common.Address, logger log.Logger, transaction transaction.Service, batchStore postage.Storer, beeMode BeeNodeMode, chequebookEnabled, swapEnabled bool, chainBackend transaction.Backend, cors []string) *Service {
@@ -317,6 +370,27 @@ func (s *Service) Configure(signer crypto.Signer, auth auth.Authenticator, trace
 	s.topologyDriver = e.TopologyDriver
 	s.accounting = e.Accounting
 	s.chequebook = e.Chequebook
+	s.factories = e.TrustedFactories
+	s.contracts = e.Contracts
+	s.chequeVerifier = e.ChequeVerifier
+	s.chequeSigner = e.ChequeSigner
+	s.issuedGuard = e.TotalIssuedGuard
+	s.spend = e.SpendAnalytics
+	s.earnings = e.Earnings
+	s.ledger = e.Ledger
+	s.purposes = e.SpendPurposes
+	s.auditLog = e.AuditLog
+	s.snapshots = e.Snapshots
+	s.stateUsage = e.StateStoreUsage
+	s.keyLayout = e.KeyLayout
+	s.settlementEvents = e.SettlementEvents
+	s.cashoutOptimizer = e.CashoutOptimizer
+	s.cashoutDataFee = e.CashoutDataFee
+	s.settlementRates = e.SettlementRates
+	s.deductedBy = e.DeductedBy
+	s.minimumPayment = e.MinimumPayment
+	s.runtimeConfig = e.RuntimeConfig
+	s.settlementMiddlewares = e.SettlementMiddlewares
 	s.swap = e.Swap
 	s.lightNodes = e.LightNodes
 	s.pseudosettle = e.Pseudosettle
@@ -630,8 +704,9 @@ func (s *Service) gasConfigMiddleware(handlerName string) func(h http.Handler) h
 			logger := s.logger.WithName(handlerName).Build()
 
 			headers := struct {
-				GasPrice *big.Int `map:"Gas-Price"`
-				GasLimit uint64   `map:"Gas-Limit"`
+				GasPrice          *big.Int `map:"Gas-Price"`
+				GasLimit          uint64   `map:"Gas-Limit"`
+				PrivateSubmission bool     `map:"Private-Submission"`
 			}{}
 			if response := s.mapStructure(r.Header, &headers); response != nil {
 				response("invalid header params", logger, w)
@@ -640,6 +715,7 @@ func (s *Service) gasConfigMiddleware(handlerName string) func(h http.Handler) h
 			ctx := r.Context()
 			ctx = sctx.SetGasPrice(ctx, headers.GasPrice)
 			ctx = sctx.SetGasLimit(ctx, headers.GasLimit)
+			ctx = sctx.SetPrivateSubmission(ctx, headers.PrivateSubmission)
 
 			h.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -659,7 +735,7 @@ func (s *Service) corsHandler(h http.Handler) http.Handler {
 		"User-Agent", "Accept", "X-Requested-With", "Access-Control-Request-Headers", "Access-Control-Request-Method", "Accept-Ranges", "Content-Encoding",
 		AuthorizationHeader, AcceptEncodingHeader, ContentTypeHeader, ContentDispositionHeader, RangeHeader, OriginHeader,
 		SwarmTagHeader, SwarmPinHeader, SwarmEncryptHeader, SwarmIndexDocumentHeader, SwarmErrorDocumentHeader, SwarmCollectionHeader, SwarmPostageBatchIdHeader, SwarmDeferredUploadHeader,
-		GasPriceHeader, GasLimitHeader, ImmutableHeader,
+		GasPriceHeader, GasLimitHeader, PrivateSubmissionHeader, ImmutableHeader,
 	}
 	allowedHeadersStr := strings.Join(allowedHeaders, ", ")
 
@@ -52,11 +52,20 @@ import (
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/resolver"
 	resolverMock "github.com/ethersphere/bee/pkg/resolver/mock"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
 	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
 	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
 	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
 	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
 	"github.com/ethersphere/bee/pkg/steward"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
@@ -114,19 +123,40 @@ type testServerOptions struct {
 	Probe              *api.Probe
 	IndexDebugger      api.StorageIndexDebugger
 
-	Overlay         swarm.Address
-	PublicKey       ecdsa.PublicKey
-	PSSPublicKey    ecdsa.PublicKey
-	EthereumAddress common.Address
-	BlockTime       time.Duration
-	P2P             *p2pmock.Service
-	Pingpong        pingpong.Interface
-	TopologyOpts    []topologymock.Option
-	AccountingOpts  []accountingmock.Option
-	ChequebookOpts  []chequebookmock.Option
-	SwapOpts        []swapmock.Option
-	TransactionOpts []transactionmock.Option
-	Traverser       traversal.Traverser
+	Overlay               swarm.Address
+	PublicKey             ecdsa.PublicKey
+	PSSPublicKey          ecdsa.PublicKey
+	EthereumAddress       common.Address
+	BlockTime             time.Duration
+	P2P                   *p2pmock.Service
+	Pingpong              pingpong.Interface
+	TopologyOpts          []topologymock.Option
+	AccountingOpts        []accountingmock.Option
+	ChequebookOpts        []chequebookmock.Option
+	SwapOpts              []swapmock.Option
+	Factories             chequebook.TrustedFactories
+	Contracts             chequebook.ContractInspector
+	ChequeVerifier        chequebook.ChequeVerifier
+	ChequeSigner          chequebook.PassphraseRotator
+	IssuedGuard           chequebook.TotalIssuedGuard
+	SpendAnalytics        *analytics.Spend
+	Earnings              *analytics.Earnings
+	Ledger                *analytics.Ledger
+	SpendPurposes         *analytics.Purposes
+	AuditLog              *auditlog.Log
+	Snapshots             *snapshot.Service
+	StateStoreUsage       *usage.Store
+	KeyLayout             *keylayout.Registry
+	Events                *events.Feed
+	CashoutTiming         *cashouttiming.Optimizer
+	CashoutDataFee        func(context.Context) (*big.Int, error)
+	SettlementRates       func() (*big.Int, *big.Int, error)
+	DeductedBy            func(swarm.Address) (bool, error)
+	MinimumPayment        *big.Int
+	RuntimeConfig         *runtimeconfig.Registry
+	SettlementMiddlewares []api.SettlementMiddleware
+	TransactionOpts       []transactionmock.Option
+	Traverser             traversal.Traverser
 
 	BatchStore postage.Storer
 	SyncStatus func() (bool, error)
@@ -188,28 +218,49 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 	backend := backendmock.New(o.BackendOpts...)
 
 	var extraOpts = api.ExtraOptions{
-		TopologyDriver:   topologyDriver,
-		Accounting:       acc,
-		Pseudosettle:     recipient,
-		LightNodes:       ln,
-		Swap:             settlement,
-		Chequebook:       chequebook,
-		Pingpong:         o.Pingpong,
-		BlockTime:        o.BlockTime,
-		Tags:             o.Tags,
-		Storer:           o.Storer,
-		Resolver:         o.Resolver,
-		Pss:              o.Pss,
-		TraversalService: o.Traversal,
-		Pinning:          o.Pinning,
-		FeedFactory:      o.Feeds,
-		Post:             o.Post,
-		PostageContract:  o.PostageContract,
-		Steward:          o.Steward,
-		SyncStatus:       o.SyncStatus,
-		Staking:          o.StakingContract,
-		IndexDebugger:    o.IndexDebugger,
-		NodeStatus:       o.NodeStatus,
+		TopologyDriver:        topologyDriver,
+		Accounting:            acc,
+		Pseudosettle:          recipient,
+		LightNodes:            ln,
+		Swap:                  settlement,
+		Chequebook:            chequebook,
+		TrustedFactories:      o.Factories,
+		Contracts:             o.Contracts,
+		ChequeVerifier:        o.ChequeVerifier,
+		ChequeSigner:          o.ChequeSigner,
+		TotalIssuedGuard:      o.IssuedGuard,
+		SpendAnalytics:        o.SpendAnalytics,
+		Earnings:              o.Earnings,
+		Ledger:                o.Ledger,
+		SpendPurposes:         o.SpendPurposes,
+		AuditLog:              o.AuditLog,
+		Snapshots:             o.Snapshots,
+		StateStoreUsage:       o.StateStoreUsage,
+		KeyLayout:             o.KeyLayout,
+		SettlementEvents:      o.Events,
+		CashoutOptimizer:      o.CashoutTiming,
+		CashoutDataFee:        o.CashoutDataFee,
+		SettlementRates:       o.SettlementRates,
+		DeductedBy:            o.DeductedBy,
+		MinimumPayment:        o.MinimumPayment,
+		RuntimeConfig:         o.RuntimeConfig,
+		SettlementMiddlewares: o.SettlementMiddlewares,
+		Pingpong:              o.Pingpong,
+		BlockTime:             o.BlockTime,
+		Tags:                  o.Tags,
+		Storer:                o.Storer,
+		Resolver:              o.Resolver,
+		Pss:                   o.Pss,
+		TraversalService:      o.Traversal,
+		Pinning:               o.Pinning,
+		FeedFactory:           o.Feeds,
+		Post:                  o.Post,
+		PostageContract:       o.PostageContract,
+		Steward:               o.Steward,
+		SyncStatus:            o.SyncStatus,
+		Staking:               o.StakingContract,
+		IndexDebugger:         o.IndexDebugger,
+		NodeStatus:            o.NodeStatus,
 	}
 
 	// By default bee mode is set to full mode.
@@ -244,7 +295,7 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 
 	if o.DebugAPI {
 		s.MountTechnicalDebug()
-		s.MountDebug(false)
+		s.MountDebug(o.Restricted)
 	} else {
 		s.MountAPI()
 	}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+)
+
+const (
+	errAuditLogUnavailable = "audit log unavailable"
+	errCantAuditLog        = "can not get audit log"
+)
+
+type auditLogEntryResponse struct {
+	Index           uint64          `json:"index"`
+	Timestamp       int64           `json:"timestamp"`
+	Action          auditlog.Action `json:"action"`
+	Chequebook      common.Address  `json:"chequebook"`
+	Counterparty    common.Address  `json:"counterparty"`
+	Amount          *bigint.BigInt  `json:"amount,omitempty"`
+	TransactionHash common.Hash     `json:"transactionHash"`
+	Note            string          `json:"note,omitempty"`
+	PrevHash        common.Hash     `json:"prevHash"`
+	Hash            common.Hash     `json:"hash"`
+}
+
+type auditLogResponse struct {
+	Total   uint64                  `json:"total"`
+	Head    common.Hash             `json:"head"`
+	Entries []auditLogEntryResponse `json:"entries"`
+}
+
+type auditLogVerifyResponse struct {
+	Valid bool        `json:"valid"`
+	Total uint64      `json:"total"`
+	Head  common.Hash `json:"head"`
+	Error string      `json:"error,omitempty"`
+}
+
+func newAuditLogEntryResponse(entry auditlog.Entry) auditLogEntryResponse {
+	response := auditLogEntryResponse{
+		Index:           entry.Index,
+		Timestamp:       entry.Timestamp,
+		Action:          entry.Action,
+		Chequebook:      entry.Chequebook,
+		Counterparty:    entry.Counterparty,
+		TransactionHash: entry.TxHash,
+		Note:            entry.Note,
+		PrevHash:        entry.PrevHash,
+		Hash:            entry.Hash,
+	}
+	if entry.Amount != nil {
+		response.Amount = bigint.Wrap(entry.Amount)
+	}
+	return response
+}
+
+func (s *Service) auditLogHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_audit").Build()
+
+	if s.auditLog == nil {
+		jsonhttp.MethodNotAllowed(w, errAuditLogUnavailable)
+		return
+	}
+
+	queries := struct {
+		Offset uint64 `map:"offset"`
+		Limit  uint64 `map:"limit"`
+	}{
+		Limit: 100, // Default limit.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	entries, err := s.auditLog.Entries(queries.Offset, queries.Limit)
+	if err != nil {
+		logger.Debug("get audit log failed", "offset", queries.Offset, "limit", queries.Limit, "error", err)
+		logger.Error(nil, "get audit log failed")
+		jsonhttp.InternalServerError(w, errCantAuditLog)
+		return
+	}
+
+	response := auditLogResponse{
+		Total:   s.auditLog.Len(),
+		Head:    s.auditLog.Head(),
+		Entries: make([]auditLogEntryResponse, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, newAuditLogEntryResponse(entry))
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// auditLogExportHandler streams the whole audit log as newline delimited json.
+func (s *Service) auditLogExportHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_settlements_audit_export").Build()
+
+	if s.auditLog == nil {
+		jsonhttp.MethodNotAllowed(w, errAuditLogUnavailable)
+		return
+	}
+
+	w.Header().Set(ContentTypeHeader, "application/x-ndjson")
+	w.Header().Set(ContentDispositionHeader, `attachment; filename="settlement-audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	err := s.auditLog.Iterate(0, func(entry auditlog.Entry) (bool, error) {
+		return false, encoder.Encode(newAuditLogEntryResponse(entry))
+	})
+	if err != nil {
+		// the status was already sent, the export ends prematurely
+		logger.Debug("export audit log failed", "error", err)
+		logger.Error(nil, "export audit log failed")
+	}
+}
+
+func (s *Service) auditLogVerifyHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_settlements_audit_verify").Build()
+
+	if s.auditLog == nil {
+		jsonhttp.MethodNotAllowed(w, errAuditLogUnavailable)
+		return
+	}
+
+	response := auditLogVerifyResponse{
+		Valid: true,
+		Total: s.auditLog.Len(),
+		Head:  s.auditLog.Head(),
+	}
+
+	err := s.auditLog.Verify()
+	if errors.Is(err, auditlog.ErrTampered) {
+		logger.Warning("audit log verification failed", "error", err)
+		response.Valid = false
+		response.Error = err.Error()
+	} else if err != nil {
+		logger.Debug("verify audit log failed", "error", err)
+		logger.Error(nil, "verify audit log failed")
+		jsonhttp.InternalServerError(w, errCantAuditLog)
+		return
+	}
+
+	jsonhttp.OK(w, response)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func newTestAuditLog(t *testing.T, n int) *auditlog.Log {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog, err := auditlog.New(statestore.NewStateStore(), crypto.NewDefaultSigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		_, err := auditLog.Append(auditlog.Entry{
+			Action:     auditlog.ActionIssue,
+			Chequebook: common.HexToAddress("0xaaaa"),
+			Amount:     big.NewInt(int64(i + 1)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return auditLog
+}
+
+func TestAuditLog(t *testing.T) {
+	t.Parallel()
+
+	auditLog := newTestAuditLog(t, 3)
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		AuditLog: auditLog,
+	})
+
+	var got api.AuditLogResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/audit?offset=1&limit=1", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if got.Total != 3 || got.Head != auditLog.Head() {
+		t.Fatalf("got total %d and head %x, want %d and %x", got.Total, got.Head, 3, auditLog.Head())
+	}
+	if len(got.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(got.Entries))
+	}
+	if entry := got.Entries[0]; entry.Index != 1 || entry.Action != auditlog.ActionIssue || entry.Amount.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("got entry %+v", entry)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/audit/verify", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.AuditLogVerifyResponse{
+			Valid: true,
+			Total: 3,
+			Head:  auditLog.Head(),
+		}),
+	)
+}
+
+func TestAuditLogExport(t *testing.T) {
+	t.Parallel()
+
+	auditLog := newTestAuditLog(t, 2)
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		AuditLog: auditLog,
+	})
+
+	var body []byte
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/audit/export", http.StatusOK,
+		jsonhttptest.WithPutResponseBody(&body),
+		jsonhttptest.WithExpectedResponseHeader(api.ContentTypeHeader, "application/x-ndjson"),
+	)
+
+	var entries []api.AuditLogEntryResponse
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var entry api.AuditLogEntryResponse
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[1].PrevHash != entries[0].Hash || entries[1].Hash != auditLog.Head() {
+		t.Fatal("exported entries do not form a chain")
+	}
+	if entries[0].Amount.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("got amount %v, want 1", entries[0].Amount)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/auth/mock"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
@@ -114,3 +115,75 @@ func TestAuth(t *testing.T) {
 		)
 	})
 }
+
+// TestFundsScope checks that the handlers moving chequebook funds require the
+// funds scope even if the policy of their path grants access.
+func TestFundsScope(t *testing.T) {
+	t.Parallel()
+
+	authenticator := &mock.Auth{
+		EnforceFunc: func(apiKey, obj, _ string) (bool, error) {
+			if obj == auth.FundsScope {
+				return apiKey == "treasurer", nil
+			}
+			return true, nil
+		},
+	}
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		Restricted:    true,
+		Authenticator: authenticator,
+	})
+
+	peer := "1000000000000000000000000000000000000000000000000000000000000000"
+	routes := []struct {
+		method, path string
+	}{
+		{http.MethodPost, "/chequebook/deposit?amount=1"},
+		{http.MethodPost, "/chequebook/deposit/split?amount=1"},
+		{http.MethodPost, "/chequebook/withdraw?amount=1"},
+		{http.MethodPost, "/chequebook/selftest"},
+		{http.MethodPost, "/chequebook/cashout"},
+		{http.MethodPost, "/chequebook/cashout/" + peer},
+		{http.MethodPost, "/chequebook/cashout/" + peer + "/chequebooks"},
+		{http.MethodPost, "/chequebook/cashout/" + peer + "/retry"},
+		{http.MethodPost, "/chequebook/assignment/" + peer},
+		{http.MethodDelete, "/chequebook/assignment/" + peer},
+		{http.MethodPut, "/chequebook/beneficiary"},
+		{http.MethodPost, "/chequebook/totalissued/override"},
+		{http.MethodPost, "/settlements/import"},
+		{http.MethodPost, "/settlements/disputes"},
+		{http.MethodPost, "/settlements/disputes/1/resolve"},
+		{http.MethodPatch, "/settlements/config"},
+	}
+
+	for _, role := range []string{"consumer", "creator", "accountant", "maintainer"} {
+		for _, r := range routes {
+			jsonhttptest.Request(t, client, r.method, r.path, http.StatusForbidden,
+				jsonhttptest.WithRequestHeader(api.AuthorizationHeader, "Bearer "+role),
+				jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+					Message: "Provided security token does not grant access to the resource",
+					Code:    http.StatusForbidden,
+				}),
+			)
+		}
+	}
+
+	t.Run("treasurer", func(t *testing.T) {
+		t.Parallel()
+
+		// The runtime settings are not configured in the test server, so
+		// the request passes the scope check and is refused by the handler.
+		jsonhttptest.Request(t, client, http.MethodPatch, "/settlements/config", http.StatusMethodNotAllowed,
+			jsonhttptest.WithRequestHeader(api.AuthorizationHeader, "Bearer treasurer"),
+		)
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/settlements/config", http.StatusMethodNotAllowed,
+			jsonhttptest.WithRequestHeader(api.AuthorizationHeader, "Bearer maintainer"),
+		)
+	})
+}
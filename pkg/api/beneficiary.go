@@ -0,0 +1,131 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errBeneficiaries     = "cannot get beneficiaries"
+	errRotateBeneficiary = "cannot rotate beneficiary"
+)
+
+type previousBeneficiaryResponse struct {
+	Beneficiary common.Address `json:"beneficiary"`
+	AcceptUntil time.Time      `json:"acceptUntil"`
+}
+
+type beneficiaryAnnouncementResponse struct {
+	Peer  swarm.Address `json:"peer"`
+	Error string        `json:"error,omitempty"`
+}
+
+type beneficiariesResponse struct {
+	Beneficiary   common.Address                    `json:"beneficiary"`
+	Previous      []previousBeneficiaryResponse     `json:"previous"`
+	Rotated       *time.Time                        `json:"rotated,omitempty"`
+	Announcements []beneficiaryAnnouncementResponse `json:"announcements,omitempty"` // set after a rotation
+}
+
+type rotateBeneficiaryRequest struct {
+	Beneficiary common.Address `json:"beneficiary"`
+	GracePeriod *uint64        `json:"gracePeriod"` // in seconds, the default grace period if not set
+}
+
+func newBeneficiariesResponse(rotation *chequebook.BeneficiaryRotation) beneficiariesResponse {
+	previous := make([]previousBeneficiaryResponse, 0, len(rotation.Previous))
+	for _, p := range rotation.Previous {
+		previous = append(previous, previousBeneficiaryResponse{
+			Beneficiary: p.Beneficiary,
+			AcceptUntil: p.AcceptUntil,
+		})
+	}
+	response := beneficiariesResponse{
+		Beneficiary: rotation.Current,
+		Previous:    previous,
+	}
+	if !rotation.Rotated.IsZero() {
+		response.Rotated = &rotation.Rotated
+	}
+	return response
+}
+
+func (s *Service) beneficiariesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_beneficiary").Build()
+
+	rotation, err := s.swap.Beneficiaries()
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("get beneficiaries failed", "error", err)
+		logger.Error(nil, "get beneficiaries failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get beneficiaries failed", "error", err)
+		logger.Error(nil, "get beneficiaries failed")
+		jsonhttp.InternalServerError(w, errBeneficiaries)
+		return
+	}
+
+	jsonhttp.OK(w, newBeneficiariesResponse(rotation))
+}
+
+// rotateBeneficiaryHandler changes the beneficiary cheques to us have to be
+// addressed to and announces it to the connected peers.
+func (s *Service) rotateBeneficiaryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("put_chequebook_beneficiary").Build()
+
+	var data rotateBeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	grace := swap.DefaultBeneficiaryGracePeriod
+	if data.GracePeriod != nil {
+		grace = time.Duration(*data.GracePeriod) * time.Second
+	}
+
+	rotation, announcements, err := s.swap.RotateBeneficiary(r.Context(), data.Beneficiary, grace)
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("rotate beneficiary failed", "error", err)
+		logger.Error(nil, "rotate beneficiary failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if errors.Is(err, chequebook.ErrInvalidBeneficiary) || errors.Is(err, chequebook.ErrBeneficiaryUnchanged) {
+		logger.Debug("rotate beneficiary failed", "beneficiary", data.Beneficiary, "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("rotate beneficiary failed", "beneficiary", data.Beneficiary, "error", err)
+		logger.Error(nil, "rotate beneficiary failed")
+		jsonhttp.InternalServerError(w, errRotateBeneficiary)
+		return
+	}
+
+	response := newBeneficiariesResponse(rotation)
+	for _, a := range announcements {
+		announcement := beneficiaryAnnouncementResponse{Peer: a.Peer}
+		if a.Err != nil {
+			announcement.Error = a.Err.Error()
+		}
+		response.Announcements = append(response.Announcements, announcement)
+	}
+
+	jsonhttp.OK(w, response)
+}
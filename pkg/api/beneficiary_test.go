@@ -0,0 +1,99 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBeneficiary(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xfff5")
+	newBeneficiary := common.HexToAddress("0xfff6")
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	failedPeer := swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")
+	rotated := time.Unix(1000, 0).UTC()
+
+	var gotGrace time.Duration
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithBeneficiariesFunc(func() (*chequebook.BeneficiaryRotation, error) {
+				return &chequebook.BeneficiaryRotation{Current: beneficiary}, nil
+			}),
+			swapmock.WithRotateBeneficiaryFunc(func(_ context.Context, b common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, []swap.BeneficiaryAnnouncement, error) {
+				if b == beneficiary {
+					return nil, nil, chequebook.ErrBeneficiaryUnchanged
+				}
+				gotGrace = grace
+				return &chequebook.BeneficiaryRotation{
+					Current: b,
+					Previous: []chequebook.PreviousBeneficiary{
+						{Beneficiary: beneficiary, AcceptUntil: rotated.Add(grace)},
+					},
+					Rotated: rotated,
+				}, []swap.BeneficiaryAnnouncement{
+					{Peer: peer},
+					{Peer: failedPeer, Err: errors.New("stream reset")},
+				}, nil
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/beneficiary", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.BeneficiariesResponse{
+			Beneficiary: beneficiary,
+			Previous:    []api.PreviousBeneficiaryResponse{},
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/beneficiary", http.StatusBadRequest,
+		jsonhttptest.WithJSONRequestBody(api.RotateBeneficiaryRequest{Beneficiary: beneficiary}),
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Code:    http.StatusBadRequest,
+			Message: chequebook.ErrBeneficiaryUnchanged.Error(),
+		}),
+	)
+
+	grace := uint64(3600)
+	jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/beneficiary", http.StatusOK,
+		jsonhttptest.WithJSONRequestBody(api.RotateBeneficiaryRequest{Beneficiary: newBeneficiary, GracePeriod: &grace}),
+		jsonhttptest.WithExpectedJSONResponse(api.BeneficiariesResponse{
+			Beneficiary: newBeneficiary,
+			Previous: []api.PreviousBeneficiaryResponse{
+				{Beneficiary: beneficiary, AcceptUntil: rotated.Add(time.Hour)},
+			},
+			Rotated: &rotated,
+			Announcements: []api.BeneficiaryAnnouncementResponse{
+				{Peer: peer},
+				{Peer: failedPeer, Error: "stream reset"},
+			},
+		}),
+	)
+	if gotGrace != time.Hour {
+		t.Fatalf("got grace period %v, want %v", gotGrace, time.Hour)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/beneficiary", http.StatusOK,
+		jsonhttptest.WithJSONRequestBody(api.RotateBeneficiaryRequest{Beneficiary: newBeneficiary}),
+	)
+	if gotGrace != swap.DefaultBeneficiaryGracePeriod {
+		t.Fatalf("got grace period %v, want %v", gotGrace, swap.DefaultBeneficiaryGracePeriod)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errCashoutAttempts        = "cannot get cashout attempts"
+	errCashoutNotRetryable    = "last cashout attempt did not fail"
+	errCannotRetryCashoutPeer = "cannot retry cashout"
+)
+
+type cashoutAttemptResponse struct {
+	TransactionHash  *common.Hash   `json:"transactionHash,omitempty"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Time             int64          `json:"time"`
+	Status           string         `json:"status"`
+	Reason           string         `json:"reason,omitempty"`
+}
+
+type cashoutAttemptsResponse struct {
+	Peer     swarm.Address            `json:"peer"`
+	Attempts []cashoutAttemptResponse `json:"attempts"`
+}
+
+func newCashoutAttemptResponse(attempt chequebook.CashoutAttempt) cashoutAttemptResponse {
+	response := cashoutAttemptResponse{
+		CumulativePayout: bigint.Wrap(attempt.CumulativePayout),
+		Time:             attempt.Time,
+		Status:           string(attempt.Status),
+		Reason:           attempt.Reason,
+	}
+	if attempt.TxHash != (common.Hash{}) {
+		txHash := attempt.TxHash
+		response.TransactionHash = &txHash
+	}
+	return response
+}
+
+func (s *Service) cashoutAttemptsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cashout_attempts").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	attempts, err := s.swap.CashoutAttempts(r.Context(), paths.Peer)
+	if err != nil {
+		logger.Debug("get cashout attempts failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "get cashout attempts failed", "peer_address", paths.Peer)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		default:
+			jsonhttp.InternalServerError(w, errCashoutAttempts)
+		}
+		return
+	}
+
+	response := cashoutAttemptsResponse{
+		Peer:     paths.Peer,
+		Attempts: make([]cashoutAttemptResponse, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		response.Attempts = append(response.Attempts, newCashoutAttemptResponse(attempt))
+	}
+	jsonhttp.OK(w, response)
+}
+
+func (s *Service) retryCashoutHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_cashout_retry").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	if !s.cashOutChequeSem.TryAcquire(1) {
+		logger.Debug("simultaneous on-chain operations not supported")
+		logger.Error(nil, "simultaneous on-chain operations not supported")
+		jsonhttp.TooManyRequests(w, "simultaneous on-chain operations not supported")
+		return
+	}
+	defer s.cashOutChequeSem.Release(1)
+
+	txHash, err := s.swap.RetryCashout(r.Context(), paths.Peer)
+	if err != nil {
+		logger.Debug("retry cashout failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "retry cashout failed", "peer_address", paths.Peer)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		case errors.Is(err, chequebook.ErrNoCashout):
+			jsonhttp.NotFound(w, errNoCashout)
+		case errors.Is(err, chequebook.ErrCashoutNotRetryable):
+			jsonhttp.Conflict(w, errCashoutNotRetryable)
+		default:
+			jsonhttp.InternalServerError(w, errCannotRetryCashoutPeer)
+		}
+		return
+	}
+
+	jsonhttp.OK(w, swapCashoutResponse{TransactionHash: txHash.String()})
+}
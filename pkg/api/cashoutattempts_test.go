@@ -0,0 +1,99 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestCashoutAttempts(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	pendingPeer := swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")
+	revertedTx := common.HexToHash("0xaa")
+	retryTx := common.HexToHash("0xbb")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithCashoutAttemptsFunc(func(_ context.Context, p swarm.Address) ([]chequebook.CashoutAttempt, error) {
+				if !p.Equal(peer) {
+					return nil, chequebook.ErrNoCheque
+				}
+				return []chequebook.CashoutAttempt{
+					{CumulativePayout: big.NewInt(500), Time: 10, Status: chequebook.CashoutAttemptFailed, Reason: "nonce too low"},
+					{TxHash: revertedTx, CumulativePayout: big.NewInt(500), Time: 20, Status: chequebook.CashoutAttemptReverted},
+				}, nil
+			}),
+			swapmock.WithRetryCashoutFunc(func(_ context.Context, p swarm.Address) (common.Hash, error) {
+				switch {
+				case p.Equal(peer):
+					return retryTx, nil
+				case p.Equal(pendingPeer):
+					return common.Hash{}, chequebook.ErrCashoutNotRetryable
+				}
+				return common.Hash{}, chequebook.ErrNoCashout
+			}),
+		},
+	})
+
+	t.Run("attempts", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashout/"+peer.String()+"/attempts", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.CashoutAttemptsResponse{
+				Peer: peer,
+				Attempts: []api.CashoutAttemptResponse{
+					{CumulativePayout: bigint.Wrap(big.NewInt(500)), Time: 10, Status: "failed", Reason: "nonce too low"},
+					{TransactionHash: &revertedTx, CumulativePayout: bigint.Wrap(big.NewInt(500)), Time: 20, Status: "reverted"},
+				},
+			}),
+		)
+	})
+
+	t.Run("attempts unknown peer", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashout/"+pendingPeer.String()+"/attempts", http.StatusNotFound)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+peer.String()+"/retry", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.SwapCashoutResponse{TransactionHash: retryTx.String()}),
+		)
+	})
+
+	t.Run("retry not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+pendingPeer.String()+"/retry", http.StatusConflict,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusConflict,
+				Message: "last cashout attempt did not fail",
+			}),
+		)
+	})
+
+	t.Run("retry without attempt", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+swarm.MustParseHexAddress("ab").String()+"/retry", http.StatusNotFound)
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errCashoutSchedulingDisabled = "scheduled cashouts are disabled"
+	errCannotScheduleCashout     = "cannot schedule cashout"
+)
+
+type scheduledCashoutResponse struct {
+	Peer      swarm.Address  `json:"peer"`
+	Scheduled time.Time      `json:"scheduled"`
+	Deadline  time.Time      `json:"deadline"`
+	BaseFee   *bigint.BigInt `json:"baseFee,omitempty"`
+}
+
+type scheduledCashoutsResponse struct {
+	Cashouts []scheduledCashoutResponse `json:"cashouts"`
+}
+
+func newScheduledCashoutResponse(c cashouttiming.ScheduledCashout) scheduledCashoutResponse {
+	response := scheduledCashoutResponse{
+		Peer:      c.Peer,
+		Scheduled: c.Scheduled,
+		Deadline:  c.Deadline,
+	}
+	if c.BaseFee != nil {
+		response.BaseFee = bigint.Wrap(c.BaseFee)
+	}
+	return response
+}
+
+// scheduleCashout schedules the cashout of the last cheque of the peer for a
+// low base fee within delay.
+func (s *Service) scheduleCashout(w http.ResponseWriter, r *http.Request, logger log.Logger, peer swarm.Address, delay time.Duration) {
+	if s.cashoutOptimizer == nil {
+		logger.Debug("schedule cashout failed", "peer_address", peer, "error", errCashoutSchedulingDisabled)
+		jsonhttp.MethodNotAllowed(w, errCashoutSchedulingDisabled)
+		return
+	}
+
+	scheduled, err := s.cashoutOptimizer.Schedule(r.Context(), peer, delay)
+	if err != nil {
+		logger.Debug("schedule cashout failed", "peer_address", peer, "error", err)
+		if errors.Is(err, cashouttiming.ErrInvalidDeadline) {
+			jsonhttp.BadRequest(w, err.Error())
+			return
+		}
+		logger.Error(nil, "schedule cashout failed", "peer_address", peer)
+		jsonhttp.InternalServerError(w, errCannotScheduleCashout)
+		return
+	}
+
+	jsonhttp.Accepted(w, newScheduledCashoutResponse(scheduled))
+}
+
+// scheduledCashoutsHandler lists the cashouts waiting for a low base fee.
+func (s *Service) scheduledCashoutsHandler(w http.ResponseWriter, _ *http.Request) {
+	if s.cashoutOptimizer == nil {
+		jsonhttp.MethodNotAllowed(w, errCashoutSchedulingDisabled)
+		return
+	}
+
+	scheduled := s.cashoutOptimizer.Scheduled()
+	response := scheduledCashoutsResponse{Cashouts: make([]scheduledCashoutResponse, 0, len(scheduled))}
+	for _, c := range scheduled {
+		response.Cashouts = append(response.Cashouts, newScheduledCashoutResponse(c))
+	}
+
+	jsonhttp.OK(w, response)
+}
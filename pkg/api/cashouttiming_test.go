@@ -0,0 +1,90 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+)
+
+func TestScheduledCashout(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("a1")
+
+	// the base fee stays high so that the cashout remains scheduled
+	optimizer, err := cashouttiming.New(
+		log.Noop,
+		mockstore.NewStateStore(),
+		backendmock.New(backendmock.WithFeeHistoryFunc(func(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+			return &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(10), big.NewInt(10), big.NewInt(50)}}, nil
+		})),
+		func(context.Context, swarm.Address) (common.Hash, error) {
+			t.Error("unexpected cashout")
+			return common.Hash{}, nil
+		},
+		cashouttiming.Options{MaxDelay: 2 * time.Hour},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = optimizer.Close() })
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		CashoutTiming: optimizer,
+	})
+
+	var scheduled api.ScheduledCashoutResponse
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+peer.String()+"?deadline=3600", http.StatusAccepted,
+		jsonhttptest.WithUnmarshalJSONResponse(&scheduled),
+	)
+	if !scheduled.Peer.Equal(peer) || scheduled.BaseFee.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got scheduled cashout %+v", scheduled)
+	}
+	if d := scheduled.Deadline.Sub(scheduled.Scheduled); d != time.Hour {
+		t.Fatalf("got delay %s, want %s", d, time.Hour)
+	}
+
+	var list api.ScheduledCashoutsResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashouts/scheduled", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&list),
+	)
+	if len(list.Cashouts) != 1 || !list.Cashouts[0].Peer.Equal(peer) {
+		t.Fatalf("got scheduled cashouts %+v", list)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+peer.String()+"?deadline=86400", http.StatusBadRequest)
+}
+
+func TestScheduledCashoutDisabled(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/a1?deadline=3600", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrCashoutSchedulingDisabled,
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashouts/scheduled", http.StatusMethodNotAllowed)
+}
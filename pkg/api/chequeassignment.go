@@ -0,0 +1,222 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errNoAssignment             = "no cheque assignment"
+	errChequeAssigned           = "cheque assigned to a third party"
+	errCantAssignCheque         = "cannot assign cheque"
+	errCantChequeAssignment     = "cannot get cheque assignment"
+	errCantRemoveAssignment     = "cannot remove cheque assignment"
+	errAssignmentUnsupported    = "chequebook does not support cheque assignments"
+	errInvalidAssignmentRequest = "invalid cheque assignment"
+)
+
+type chequeAssignmentRequest struct {
+	Cashier      common.Address `json:"cashier"`
+	Recipient    common.Address `json:"recipient"`
+	CallerPayout *bigint.BigInt `json:"callerPayout"`
+}
+
+type chequeAssignmentResponse struct {
+	Peer                 swarm.Address  `json:"peer"`
+	Chequebook           common.Address `json:"chequebook"`
+	Beneficiary          common.Address `json:"beneficiary"`
+	CumulativePayout     *bigint.BigInt `json:"cumulativePayout"`
+	ChequeSignature      string         `json:"chequeSignature"`
+	Cashier              common.Address `json:"cashier"`
+	Recipient            common.Address `json:"recipient"`
+	CallerPayout         *bigint.BigInt `json:"callerPayout"`
+	BeneficiarySignature string         `json:"beneficiarySignature"`
+	CallData             string         `json:"callData"`
+	Time                 int64          `json:"time"`
+}
+
+type chequeAssignmentStatusResponse struct {
+	chequeAssignmentResponse
+	PaidOut *bigint.BigInt `json:"paidOut"`
+	Cashed  bool           `json:"cashed"`
+}
+
+func newChequeAssignmentResponse(peer swarm.Address, assignment *chequebook.ChequeAssignment) (chequeAssignmentResponse, error) {
+	callData, err := assignment.CallData()
+	if err != nil {
+		return chequeAssignmentResponse{}, err
+	}
+	return chequeAssignmentResponse{
+		Peer:                 peer,
+		Chequebook:           assignment.Cheque.Chequebook,
+		Beneficiary:          assignment.Cheque.Beneficiary,
+		CumulativePayout:     bigint.Wrap(assignment.Cheque.CumulativePayout),
+		ChequeSignature:      hexutil.Encode(assignment.Cheque.Signature),
+		Cashier:              assignment.Cashier,
+		Recipient:            assignment.Recipient,
+		CallerPayout:         bigint.Wrap(assignment.CallerPayout),
+		BeneficiarySignature: hexutil.Encode(assignment.Signature),
+		CallData:             hexutil.Encode(callData),
+		Time:                 assignment.Time,
+	}, nil
+}
+
+// assignChequeHandler assigns the proceeds of the last cheque of the peer to
+// a recipient. The response carries the call the cashier has to send to the
+// chequebook to cash it.
+func (s *Service) assignChequeHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_assignment").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	var data chequeAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if data.Cashier == (common.Address{}) || data.Recipient == (common.Address{}) {
+		jsonhttp.BadRequest(w, errInvalidAssignmentRequest)
+		return
+	}
+	callerPayout := big.NewInt(0)
+	if data.CallerPayout != nil {
+		callerPayout = data.CallerPayout.Int
+	}
+
+	if !s.cashOutChequeSem.TryAcquire(1) {
+		logger.Debug("simultaneous on-chain operations not supported")
+		logger.Error(nil, "simultaneous on-chain operations not supported")
+		jsonhttp.TooManyRequests(w, "simultaneous on-chain operations not supported")
+		return
+	}
+	defer s.cashOutChequeSem.Release(1)
+
+	assignment, err := s.swap.AssignCheque(r.Context(), paths.Peer, data.Cashier, data.Recipient, callerPayout)
+	if err != nil {
+		logger.Debug("assign cheque failed", "peer_address", paths.Peer, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled),
+			errors.Is(err, chequebook.ErrNoCashoutSigner):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		case errors.Is(err, chequebook.ErrInvalidCallerPayout):
+			jsonhttp.BadRequest(w, err)
+		case errors.Is(err, chequebook.ErrChequeAssigned):
+			jsonhttp.Conflict(w, errChequeAssigned)
+		case errors.Is(err, chequebook.ErrAssignmentUnsupported):
+			jsonhttp.Conflict(w, errAssignmentUnsupported)
+		default:
+			logger.Error(nil, "assign cheque failed", "peer_address", paths.Peer)
+			jsonhttp.InternalServerError(w, errCantAssignCheque)
+		}
+		return
+	}
+
+	response, err := newChequeAssignmentResponse(paths.Peer, assignment)
+	if err != nil {
+		logger.Debug("encode cheque assignment failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "encode cheque assignment failed", "peer_address", paths.Peer)
+		jsonhttp.InternalServerError(w, errCantAssignCheque)
+		return
+	}
+	jsonhttp.Created(w, response)
+}
+
+// chequeAssignmentHandler returns the last cheque assignment of the peer and
+// whether the assigned cheque was cashed.
+func (s *Service) chequeAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_assignment").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	status, err := s.swap.ChequeAssignment(r.Context(), paths.Peer)
+	if err != nil {
+		logger.Debug("get cheque assignment failed", "peer_address", paths.Peer, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		case errors.Is(err, chequebook.ErrNoAssignment):
+			jsonhttp.NotFound(w, errNoAssignment)
+		default:
+			logger.Error(nil, "get cheque assignment failed", "peer_address", paths.Peer)
+			jsonhttp.InternalServerError(w, errCantChequeAssignment)
+		}
+		return
+	}
+
+	assignment, err := newChequeAssignmentResponse(paths.Peer, &status.Assignment)
+	if err != nil {
+		logger.Debug("encode cheque assignment failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "encode cheque assignment failed", "peer_address", paths.Peer)
+		jsonhttp.InternalServerError(w, errCantChequeAssignment)
+		return
+	}
+	jsonhttp.OK(w, chequeAssignmentStatusResponse{
+		chequeAssignmentResponse: assignment,
+		PaidOut:                  bigint.Wrap(status.PaidOut),
+		Cashed:                   status.Cashed,
+	})
+}
+
+// removeChequeAssignmentHandler forgets the cheque assignment of the peer so
+// that the node cashes its cheques again.
+func (s *Service) removeChequeAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("delete_chequebook_assignment").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	if err := s.swap.RemoveChequeAssignment(paths.Peer); err != nil {
+		logger.Debug("remove cheque assignment failed", "peer_address", paths.Peer, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		case errors.Is(err, chequebook.ErrNoAssignment):
+			jsonhttp.NotFound(w, errNoAssignment)
+		default:
+			logger.Error(nil, "remove cheque assignment failed", "peer_address", paths.Peer)
+			jsonhttp.InternalServerError(w, errCantRemoveAssignment)
+		}
+		return
+	}
+
+	jsonhttp.NoContent(w)
+}
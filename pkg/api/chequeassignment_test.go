@@ -0,0 +1,148 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChequeAssignment(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	assignedPeer := swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")
+	cashier := common.HexToAddress("0xcccc")
+	recipient := common.HexToAddress("0xefff")
+
+	assignment := &chequebook.ChequeAssignment{
+		Cheque: chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       common.HexToAddress("0x01"),
+				Beneficiary:      common.HexToAddress("0xaaaa"),
+				CumulativePayout: big.NewInt(500),
+			},
+			Signature: []byte{1},
+		},
+		Cashier:      cashier,
+		Recipient:    recipient,
+		CallerPayout: big.NewInt(10),
+		Signature:    []byte{2},
+		Time:         100,
+	}
+	callData, err := assignment.CallData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := api.ChequeAssignmentResponse{
+		Peer:                 peer,
+		Chequebook:           assignment.Cheque.Chequebook,
+		Beneficiary:          assignment.Cheque.Beneficiary,
+		CumulativePayout:     bigint.Wrap(big.NewInt(500)),
+		ChequeSignature:      "0x01",
+		Cashier:              cashier,
+		Recipient:            recipient,
+		CallerPayout:         bigint.Wrap(big.NewInt(10)),
+		BeneficiarySignature: "0x02",
+		CallData:             hexutil.Encode(callData),
+		Time:                 100,
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithAssignChequeFunc(func(_ context.Context, p swarm.Address, c, r common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error) {
+				if p.Equal(assignedPeer) {
+					return nil, chequebook.ErrChequeAssigned
+				}
+				if c != cashier || r != recipient || callerPayout.Cmp(big.NewInt(10)) != 0 {
+					t.Fatalf("wrong assignment to cashier %v recipient %v caller payout %d", c, r, callerPayout)
+				}
+				return assignment, nil
+			}),
+			swapmock.WithChequeAssignmentFunc(func(_ context.Context, p swarm.Address) (*chequebook.ChequeAssignmentStatus, error) {
+				if !p.Equal(peer) {
+					return nil, chequebook.ErrNoAssignment
+				}
+				return &chequebook.ChequeAssignmentStatus{Assignment: *assignment, PaidOut: big.NewInt(500), Cashed: true}, nil
+			}),
+			swapmock.WithRemoveChequeAssignmentFunc(func(p swarm.Address) error {
+				if !p.Equal(peer) {
+					return chequebook.ErrNoAssignment
+				}
+				return nil
+			}),
+		},
+	})
+
+	t.Run("assign", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/assignment/"+peer.String(), http.StatusCreated,
+			jsonhttptest.WithJSONRequestBody(api.ChequeAssignmentRequest{
+				Cashier:      cashier,
+				Recipient:    recipient,
+				CallerPayout: bigint.Wrap(big.NewInt(10)),
+			}),
+			jsonhttptest.WithExpectedJSONResponse(want),
+		)
+	})
+
+	t.Run("assign without recipient", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/assignment/"+peer.String(), http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.ChequeAssignmentRequest{Cashier: cashier}),
+		)
+	})
+
+	t.Run("assign assigned", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/assignment/"+assignedPeer.String(), http.StatusConflict,
+			jsonhttptest.WithJSONRequestBody(api.ChequeAssignmentRequest{
+				Cashier:      cashier,
+				Recipient:    recipient,
+				CallerPayout: bigint.Wrap(big.NewInt(10)),
+			}),
+		)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		t.Parallel()
+
+		var got api.ChequeAssignmentStatusResponse
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/assignment/"+peer.String(), http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&got),
+		)
+		if got.CallData != want.CallData || got.Recipient != recipient || got.PaidOut.Cmp(big.NewInt(500)) != 0 || !got.Cashed {
+			t.Fatalf("got assignment status %+v, want cashed %+v", got, want)
+		}
+	})
+
+	t.Run("status not assigned", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/assignment/"+assignedPeer.String(), http.StatusNotFound)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodDelete, "/chequebook/assignment/"+peer.String(), http.StatusNoContent)
+		jsonhttptest.Request(t, testServer, http.MethodDelete, "/chequebook/assignment/"+assignedPeer.String(), http.StatusNotFound)
+	})
+}
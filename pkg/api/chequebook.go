@@ -5,18 +5,24 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/keystore"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/settlement/swap"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/gorilla/mux"
 )
 
@@ -25,13 +31,23 @@ const (
 	errChequebookNoAmount          = "did not specify amount"
 	errChequebookNoWithdraw        = "cannot withdraw"
 	errChequebookNoDeposit         = "cannot deposit"
+	errChequebookInvalidMax        = "invalid maximum transfer amount"
 	errChequebookInsufficientFunds = "insufficient funds"
 	errCantLastChequePeer          = "cannot get last cheque for peer"
 	errCantLastCheque              = "cannot get last cheque for all peers"
 	errCannotCash                  = "cannot cash cheque"
+	errChequeCashed                = "cheque already cashed"
 	errCannotCashStatus            = "cannot get cashout status"
 	errNoCashout                   = "no prior cashout"
+	errNoCashoutPeers              = "no peers to cash out"
 	errNoCheque                    = "no prior cheque"
+	errChequebookToken             = "cannot get chequebook token"
+	errChequebookDepositHistory    = "cannot get chequebook deposit history"
+	errChequebookSetFactories      = "cannot set trusted factories"
+	errChequeSignerPassphrase      = "cannot rotate cheque signer passphrase"
+	errCashoutTransactions         = "cannot get cashout transactions"
+	errUnknownCashoutTransaction   = "unknown cashout transaction"
+	errCashoutReconciliation       = "cannot reconcile cashouts"
 )
 
 type chequebookBalanceResponse struct {
@@ -43,6 +59,45 @@ type chequebookAddressResponse struct {
 	Address string `json:"chequebookAddress"`
 }
 
+type chequebookTokenResponse struct {
+	Address  common.Address `json:"address"`
+	Symbol   string         `json:"symbol"`
+	Name     string         `json:"name"`
+	Decimals uint8          `json:"decimals"`
+}
+
+type chequebookDepositResponse struct {
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockNumber     uint64         `json:"blockNumber"`
+	From            common.Address `json:"from"`
+	Amount          *bigint.BigInt `json:"amount"`
+}
+
+type chequebookSplitDepositResponse struct {
+	TransactionHashes []common.Hash  `json:"transactionHashes"`
+	Amount            *bigint.BigInt `json:"amount"`
+	Received          *bigint.BigInt `json:"received"`
+	Fee               *bigint.BigInt `json:"fee"`
+}
+
+type chequebookDepositHistoryResponse struct {
+	Deposits []chequebookDepositResponse `json:"deposits"`
+}
+
+type chequebookFactoriesResponse struct {
+	Factory         common.Address   `json:"factory"`
+	LegacyFactories []common.Address `json:"legacyFactories"`
+}
+
+type chequebookFactoriesRequest struct {
+	LegacyFactories []common.Address `json:"legacyFactories"`
+}
+
+type chequeSignerPassphraseRequest struct {
+	OldPassphrase string `json:"oldPassphrase"`
+	NewPassphrase string `json:"newPassphrase"`
+}
+
 type chequebookLastChequePeerResponse struct {
 	Beneficiary string         `json:"beneficiary"`
 	Chequebook  string         `json:"chequebook"`
@@ -57,6 +112,7 @@ type chequebookLastChequesPeerResponse struct {
 
 type chequebookLastChequesResponse struct {
 	LastCheques []chequebookLastChequesPeerResponse `json:"lastcheques"`
+	Next        string                              `json:"next,omitempty"`
 }
 
 func (s *Service) chequebookBalanceHandler(w http.ResponseWriter, r *http.Request) {
@@ -91,6 +147,153 @@ func (s *Service) chequebookAddressHandler(w http.ResponseWriter, _ *http.Reques
 	jsonhttp.OK(w, chequebookAddressResponse{Address: s.chequebook.Address().String()})
 }
 
+func (s *Service) chequebookTokenHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_token").Build()
+
+	token, err := s.chequebook.Token(r.Context())
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("get token failed", "error", err)
+		logger.Error(nil, "get token failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get token failed", "error", err)
+		logger.Error(nil, "get token failed")
+		jsonhttp.InternalServerError(w, errChequebookToken)
+		return
+	}
+
+	jsonhttp.OK(w, chequebookTokenResponse{
+		Address:  token.Address,
+		Symbol:   token.Symbol,
+		Name:     token.Name,
+		Decimals: token.Decimals,
+	})
+}
+
+func (s *Service) chequebookDepositHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_deposits").Build()
+
+	deposits, err := s.chequebook.DepositHistory(r.Context())
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("get deposit history failed", "error", err)
+		logger.Error(nil, "get deposit history failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get deposit history failed", "error", err)
+		logger.Error(nil, "get deposit history failed")
+		jsonhttp.InternalServerError(w, errChequebookDepositHistory)
+		return
+	}
+
+	response := chequebookDepositHistoryResponse{
+		Deposits: make([]chequebookDepositResponse, 0, len(deposits)),
+	}
+	for _, deposit := range deposits {
+		response.Deposits = append(response.Deposits, chequebookDepositResponse{
+			TransactionHash: deposit.TxHash,
+			BlockNumber:     deposit.BlockNumber,
+			From:            deposit.From,
+			Amount:          bigint.Wrap(deposit.Amount),
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+func (s *Service) chequebookFactoriesResponse() chequebookFactoriesResponse {
+	factory, legacyFactories := s.factories.TrustedFactories()
+	if legacyFactories == nil {
+		legacyFactories = make([]common.Address, 0)
+	}
+	return chequebookFactoriesResponse{
+		Factory:         factory,
+		LegacyFactories: legacyFactories,
+	}
+}
+
+func (s *Service) chequebookFactoriesHandler(w http.ResponseWriter, _ *http.Request) {
+	if s.factories == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrTrustedFactoriesUnsupported)
+		return
+	}
+
+	jsonhttp.OK(w, s.chequebookFactoriesResponse())
+}
+
+func (s *Service) chequebookSetFactoriesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("put_chequebook_factories").Build()
+
+	if s.factories == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrTrustedFactoriesUnsupported)
+		return
+	}
+
+	var data chequebookFactoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	err := s.factories.SetLegacyFactories(r.Context(), data.LegacyFactories)
+	if errors.Is(err, chequebook.ErrInvalidFactory) {
+		logger.Debug("set trusted factories failed", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("set trusted factories failed", "error", err)
+		logger.Error(nil, "set trusted factories failed")
+		jsonhttp.InternalServerError(w, errChequebookSetFactories)
+		return
+	}
+
+	logger.Info("trusted factories updated", "legacy_factories", data.LegacyFactories)
+
+	jsonhttp.OK(w, s.chequebookFactoriesResponse())
+}
+
+func (s *Service) chequeSignerPassphraseHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("put_chequebook_signer_passphrase").Build()
+
+	if s.chequeSigner == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrPassphraseRotationUnsupported)
+		return
+	}
+
+	var data chequeSignerPassphraseRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if data.NewPassphrase == "" {
+		jsonhttp.BadRequest(w, "new passphrase not set")
+		return
+	}
+
+	err := s.chequeSigner.RotatePassphrase(data.OldPassphrase, data.NewPassphrase)
+	if errors.Is(err, keystore.ErrInvalidPassword) {
+		logger.Debug("rotate cheque signer passphrase failed", "error", err)
+		jsonhttp.Forbidden(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("rotate cheque signer passphrase failed", "error", err)
+		logger.Error(nil, "rotate cheque signer passphrase failed")
+		jsonhttp.InternalServerError(w, errChequeSignerPassphrase)
+		return
+	}
+
+	logger.Info("cheque signer passphrase rotated")
+
+	jsonhttp.OK(w, nil)
+}
+
 func (s *Service) chequebookLastPeerHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("get_chequebook_cheque_by_peer").Build()
 
@@ -147,75 +350,80 @@ func (s *Service) chequebookLastPeerHandler(w http.ResponseWriter, r *http.Reque
 	})
 }
 
-func (s *Service) chequebookAllLastHandler(w http.ResponseWriter, _ *http.Request) {
+type chequebookLastChequesCountResponse struct {
+	Count int `json:"count"`
+}
+
+func (s *Service) chequebookAllLastHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("get_chequebook_cheques").Build()
 
-	lastchequessent, err := s.swap.LastSentCheques()
-	if errors.Is(err, postagecontract.ErrChainDisabled) {
-		logger.Debug("get all last sent cheque failed", "error", err)
-		logger.Error(nil, "get all last sent cheque failed")
-		jsonhttp.MethodNotAllowed(w, err)
+	queries := struct {
+		Count  bool   `map:"count"`
+		Order  string `map:"order"`
+		Cursor string `map:"cursor"`
+		Limit  int    `map:"limit" validate:"min=0"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
 		return
 	}
-	if err != nil {
-		if !errors.Is(err, swap.ErrNoChequebook) {
-			logger.Debug("get all last sent cheque failed", "error", err)
-			logger.Error(nil, "get all last sent cheque failed")
+
+	// only the number of beneficiaries is needed, the cheques are not decoded
+	if queries.Count {
+		count, err := s.chequebook.LastChequesCount(r.Context())
+		if errors.Is(err, postagecontract.ErrChainDisabled) {
+			logger.Debug("count last sent cheques failed", "error", err)
+			logger.Error(nil, "count last sent cheques failed")
+			jsonhttp.MethodNotAllowed(w, err)
+			return
+		}
+		if err != nil {
+			logger.Debug("count last sent cheques failed", "error", err)
+			logger.Error(nil, "count last sent cheques failed")
 			jsonhttp.InternalServerError(w, errCantLastCheque)
 			return
 		}
-		lastchequessent = map[string]*chequebook.SignedCheque{}
+		jsonhttp.OK(w, chequebookLastChequesCountResponse{Count: count})
+		return
 	}
-	lastchequesreceived, err := s.swap.LastReceivedCheques()
+
+	page, err := s.swap.LastCheques(r.Context(), swap.ChequeOrder(queries.Order), queries.Cursor, queries.Limit)
 	if err != nil {
-		logger.Debug("get all last received cheque failed", "error", err)
-		logger.Error(nil, "get all last received cheque failed")
-		jsonhttp.InternalServerError(w, errCantLastCheque)
-		return
-	}
-
-	lcr := make(map[string]chequebookLastChequesPeerResponse)
-	for i, j := range lastchequessent {
-		lcr[i] = chequebookLastChequesPeerResponse{
-			Peer: i,
-			LastSent: &chequebookLastChequePeerResponse{
-				Beneficiary: j.Cheque.Beneficiary.String(),
-				Chequebook:  j.Cheque.Chequebook.String(),
-				Payout:      bigint.Wrap(j.Cheque.CumulativePayout),
-			},
-			LastReceived: nil,
-		}
-	}
-	for i, j := range lastchequesreceived {
-		if _, ok := lcr[i]; ok {
-			t := lcr[i]
-			t.LastReceived = &chequebookLastChequePeerResponse{
-				Beneficiary: j.Cheque.Beneficiary.String(),
-				Chequebook:  j.Cheque.Chequebook.String(),
-				Payout:      bigint.Wrap(j.Cheque.CumulativePayout),
-			}
-			lcr[i] = t
-		} else {
-			lcr[i] = chequebookLastChequesPeerResponse{
-				Peer:     i,
-				LastSent: nil,
-				LastReceived: &chequebookLastChequePeerResponse{
-					Beneficiary: j.Cheque.Beneficiary.String(),
-					Chequebook:  j.Cheque.Chequebook.String(),
-					Payout:      bigint.Wrap(j.Cheque.CumulativePayout),
-				},
-			}
+		logger.Debug("get all last cheques failed", "error", err)
+		logger.Error(nil, "get all last cheques failed")
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, swap.ErrInvalidChequeOrder), errors.Is(err, swap.ErrInvalidCursor):
+			jsonhttp.BadRequest(w, err)
+		default:
+			jsonhttp.InternalServerError(w, errCantLastCheque)
 		}
+		return
 	}
 
-	lcresponses := make([]chequebookLastChequesPeerResponse, len(lcr))
-	i := 0
-	for k := range lcr {
-		lcresponses[i] = lcr[k]
-		i++
+	lcresponses := make([]chequebookLastChequesPeerResponse, 0, len(page.Cheques))
+	for _, c := range page.Cheques {
+		lcresponses = append(lcresponses, chequebookLastChequesPeerResponse{
+			Peer:         c.Peer,
+			LastSent:     lastChequePeerResponse(c.LastSent),
+			LastReceived: lastChequePeerResponse(c.LastReceived),
+		})
 	}
 
-	jsonhttp.OK(w, chequebookLastChequesResponse{LastCheques: lcresponses})
+	jsonhttp.OK(w, chequebookLastChequesResponse{LastCheques: lcresponses, Next: page.Next})
+}
+
+// lastChequePeerResponse returns the response of the cheque, nil if there is none.
+func lastChequePeerResponse(cheque *chequebook.SignedCheque) *chequebookLastChequePeerResponse {
+	if cheque == nil {
+		return nil
+	}
+	return &chequebookLastChequePeerResponse{
+		Beneficiary: cheque.Cheque.Beneficiary.String(),
+		Chequebook:  cheque.Cheque.Chequebook.String(),
+		Payout:      bigint.Wrap(cheque.Cheque.CumulativePayout),
+	}
 }
 
 type swapCashoutResponse struct {
@@ -233,6 +441,18 @@ func (s *Service) swapCashoutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	queries := struct {
+		Deadline uint64 `map:"deadline"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+	if queries.Deadline > 0 {
+		s.scheduleCashout(w, r, logger, paths.Peer, time.Duration(queries.Deadline)*time.Second)
+		return
+	}
+
 	if !s.cashOutChequeSem.TryAcquire(1) {
 		logger.Debug("simultaneous on-chain operations not supported")
 		logger.Error(nil, "simultaneous on-chain operations not supported")
@@ -248,6 +468,16 @@ func (s *Service) swapCashoutHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.MethodNotAllowed(w, err)
 		return
 	}
+	if errors.Is(err, chequebook.ErrChequeAssigned) {
+		logger.Debug("cash cheque failed", "peer_address", paths.Peer, "error", err)
+		jsonhttp.Conflict(w, errChequeAssigned)
+		return
+	}
+	if errors.Is(err, chequebook.ErrChequeCashed) {
+		logger.Debug("cash cheque failed", "peer_address", paths.Peer, "error", err)
+		jsonhttp.Conflict(w, errChequeCashed)
+		return
+	}
 	if err != nil {
 		logger.Debug("cash cheque failed", "peer_address", paths.Peer, "error", err)
 		logger.Error(nil, "cash cheque failed", "peer_address", paths.Peer)
@@ -258,6 +488,78 @@ func (s *Service) swapCashoutHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, swapCashoutResponse{TransactionHash: txHash.String()})
 }
 
+type swapCashoutBatchRequest struct {
+	Peers []swarm.Address `json:"peers"`
+}
+
+type swapCashoutBatchResult struct {
+	Peer            swarm.Address  `json:"peer"`
+	Chequebook      common.Address `json:"chequebook"`
+	TransactionHash *common.Hash   `json:"transactionHash,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type swapCashoutBatchResponse struct {
+	Results []swapCashoutBatchResult `json:"results"`
+}
+
+func (s *Service) swapCashoutBatchHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_cashout_batch").Build()
+
+	var data swapCashoutBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if len(data.Peers) == 0 {
+		jsonhttp.BadRequest(w, errNoCashoutPeers)
+		return
+	}
+
+	if !s.cashOutChequeSem.TryAcquire(1) {
+		logger.Debug("simultaneous on-chain operations not supported")
+		logger.Error(nil, "simultaneous on-chain operations not supported")
+		jsonhttp.TooManyRequests(w, "simultaneous on-chain operations not supported")
+		return
+	}
+	defer s.cashOutChequeSem.Release(1)
+
+	results, err := s.swap.CashChequeBatch(r.Context(), data.Peers)
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("cash cheque batch failed", "error", err)
+		logger.Error(nil, "cash cheque batch failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("cash cheque batch failed", "error", err)
+		logger.Error(nil, "cash cheque batch failed")
+		// the cashouts sent before the failure are reported with their results
+		if results == nil {
+			jsonhttp.InternalServerError(w, errCannotCash)
+			return
+		}
+	}
+
+	response := swapCashoutBatchResponse{Results: make([]swapCashoutBatchResult, 0, len(results))}
+	for i, result := range results {
+		item := swapCashoutBatchResult{
+			Peer:       data.Peers[i],
+			Chequebook: result.Chequebook,
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		} else {
+			txHash := result.TxHash
+			item.TransactionHash = &txHash
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	jsonhttp.OK(w, response)
+}
+
 type swapCashoutStatusResult struct {
 	Recipient  common.Address `json:"recipient"`
 	LastPayout *bigint.BigInt `json:"lastPayout"`
@@ -337,10 +639,157 @@ func (s *Service) swapCashoutStatusHandler(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+type chequeCashoutResponse struct {
+	TransactionHash common.Hash                      `json:"transactionHash"`
+	Cheque          chequebookLastChequePeerResponse `json:"cheque"`
+	Time            int64                            `json:"time"`
+}
+
+type chequeCashoutsResponse struct {
+	Peer     swarm.Address           `json:"peer"`
+	Cashouts []chequeCashoutResponse `json:"cashouts"`
+}
+
+func newChequeCashoutResponse(cashout chequebook.ChequeCashout) chequeCashoutResponse {
+	return chequeCashoutResponse{
+		TransactionHash: cashout.TxHash,
+		Cheque:          newChequeResponse(&cashout.Cheque),
+		Time:            cashout.Time,
+	}
+}
+
+func newChequeResponse(cheque *chequebook.SignedCheque) chequebookLastChequePeerResponse {
+	return chequebookLastChequePeerResponse{
+		Chequebook:  cheque.Chequebook.String(),
+		Payout:      bigint.Wrap(cheque.CumulativePayout),
+		Beneficiary: cheque.Beneficiary.String(),
+	}
+}
+
+func (s *Service) chequeCashoutsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cashout_transactions").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	cashouts, err := s.swap.ChequeCashouts(paths.Peer)
+	if err != nil {
+		logger.Debug("get cashout transactions failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "get cashout transactions failed", "peer_address", paths.Peer)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrNoCheque):
+			jsonhttp.NotFound(w, errNoCheque)
+		default:
+			jsonhttp.InternalServerError(w, errCashoutTransactions)
+		}
+		return
+	}
+
+	response := chequeCashoutsResponse{
+		Peer:     paths.Peer,
+		Cashouts: make([]chequeCashoutResponse, 0, len(cashouts)),
+	}
+	for _, cashout := range cashouts {
+		response.Cashouts = append(response.Cashouts, newChequeCashoutResponse(cashout))
+	}
+	jsonhttp.OK(w, response)
+}
+
+type cashoutTransactionChequesResponse struct {
+	TransactionHash common.Hash                        `json:"transactionHash"`
+	Cheques         []chequebookLastChequePeerResponse `json:"cheques"`
+}
+
+func (s *Service) cashoutTransactionChequesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cashouts_by_hash").Build()
+
+	paths := struct {
+		Hash common.Hash `map:"hash"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	cheques, err := s.swap.CashoutTransactionCheques(paths.Hash)
+	if err != nil {
+		logger.Debug("get cashout transaction cheques failed", "tx", paths.Hash, "error", err)
+		logger.Error(nil, "get cashout transaction cheques failed", "tx", paths.Hash)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, chequebook.ErrUnknownCashoutTransaction):
+			jsonhttp.NotFound(w, errUnknownCashoutTransaction)
+		default:
+			jsonhttp.InternalServerError(w, errCashoutTransactions)
+		}
+		return
+	}
+
+	response := cashoutTransactionChequesResponse{
+		TransactionHash: paths.Hash,
+		Cheques:         make([]chequebookLastChequePeerResponse, 0, len(cheques)),
+	}
+	for i := range cheques {
+		response.Cheques = append(response.Cheques, newChequeResponse(&cheques[i]))
+	}
+	jsonhttp.OK(w, response)
+}
+
+type cashoutReconciliationResponse struct {
+	NeverCashed []chequebookLastChequePeerResponse `json:"neverCashed"`
+	CashedTwice [][]chequeCashoutResponse          `json:"cashedTwice"`
+}
+
+func (s *Service) cashoutReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_reconciliation").Build()
+
+	reconciliation, err := s.swap.ReconcileCashouts(r.Context())
+	if err != nil {
+		logger.Debug("reconcile cashouts failed", "error", err)
+		logger.Error(nil, "reconcile cashouts failed")
+		if errors.Is(err, postagecontract.ErrChainDisabled) {
+			jsonhttp.MethodNotAllowed(w, err)
+			return
+		}
+		jsonhttp.InternalServerError(w, errCashoutReconciliation)
+		return
+	}
+
+	response := cashoutReconciliationResponse{
+		NeverCashed: make([]chequebookLastChequePeerResponse, 0, len(reconciliation.NeverCashed)),
+		CashedTwice: make([][]chequeCashoutResponse, 0, len(reconciliation.CashedTwice)),
+	}
+	for i := range reconciliation.NeverCashed {
+		response.NeverCashed = append(response.NeverCashed, newChequeResponse(&reconciliation.NeverCashed[i]))
+	}
+	for _, cashouts := range reconciliation.CashedTwice {
+		entry := make([]chequeCashoutResponse, 0, len(cashouts))
+		for _, cashout := range cashouts {
+			entry = append(entry, newChequeCashoutResponse(cashout))
+		}
+		response.CashedTwice = append(response.CashedTwice, entry)
+	}
+	jsonhttp.OK(w, response)
+}
+
 type chequebookTxResponse struct {
 	TransactionHash common.Hash `json:"transactionHash"`
 }
 
+// insufficientGasFundsMessage names the native token of the chain the node
+// lacks to pay for gas.
+func (s *Service) insufficientGasFundsMessage() string {
+	return fmt.Sprintf("insufficient %s for gas", config.NativeTokenSymbol(s.chainID))
+}
+
 func (s *Service) chequebookWithdrawHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("post_chequebook_withdraw").Build()
 
@@ -352,13 +801,26 @@ func (s *Service) chequebookWithdrawHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	txHash, err := s.chequebook.Withdraw(r.Context(), queries.Amount)
+	amount, err := chequebook.NewTokens(queries.Amount)
+	if err != nil {
+		logger.Debug("withdraw failed", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	txHash, err := s.chequebook.Withdraw(r.Context(), amount)
 	if errors.Is(err, chequebook.ErrInsufficientFunds) {
 		logger.Debug("withdraw failed", "error", err)
 		logger.Error(nil, "withdraw failed")
 		jsonhttp.BadRequest(w, errChequebookInsufficientFunds)
 		return
 	}
+	if errors.Is(err, transaction.ErrInsufficientGasFunds) {
+		logger.Debug("withdraw failed", "error", err)
+		logger.Error(nil, "withdraw failed")
+		jsonhttp.BadRequest(w, s.insufficientGasFundsMessage())
+		return
+	}
 	if err != nil {
 		logger.Debug("withdraw failed", "error", err)
 		logger.Error(nil, "withdraw failed")
@@ -380,13 +842,26 @@ func (s *Service) chequebookDepositHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	txHash, err := s.chequebook.Deposit(r.Context(), queries.Amount)
+	amount, err := chequebook.NewTokens(queries.Amount)
+	if err != nil {
+		logger.Debug("chequebook deposit: deposit failed", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	txHash, err := s.chequebook.Deposit(r.Context(), amount)
 	if errors.Is(err, chequebook.ErrInsufficientFunds) {
 		logger.Debug("chequebook deposit: deposit failed", "error", err)
 		logger.Error(nil, "chequebook deposit: deposit failed")
 		jsonhttp.BadRequest(w, errChequebookInsufficientFunds)
 		return
 	}
+	if errors.Is(err, transaction.ErrInsufficientGasFunds) {
+		logger.Debug("chequebook deposit: deposit failed", "error", err)
+		logger.Error(nil, "chequebook deposit: deposit failed")
+		jsonhttp.BadRequest(w, s.insufficientGasFundsMessage())
+		return
+	}
 	if err != nil {
 		logger.Debug("chequebook deposit: deposit failed", "error", err)
 		logger.Error(nil, "chequebook deposit: deposit failed")
@@ -396,3 +871,60 @@ func (s *Service) chequebookDepositHandler(w http.ResponseWriter, r *http.Reques
 
 	jsonhttp.OK(w, chequebookTxResponse{TransactionHash: txHash})
 }
+
+func (s *Service) chequebookSplitDepositHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_deposit_split").Build()
+
+	queries := struct {
+		Amount      *big.Int `map:"amount" validate:"required"`
+		MaxTransfer *big.Int `map:"maxTransfer" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	amount, err := chequebook.NewTokens(queries.Amount)
+	if err != nil {
+		logger.Debug("chequebook split deposit: deposit failed", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	maxTransfer, err := chequebook.NewTokens(queries.MaxTransfer)
+	if err != nil {
+		logger.Debug("chequebook split deposit: deposit failed", "error", err)
+		jsonhttp.BadRequest(w, errChequebookInvalidMax)
+		return
+	}
+
+	result, err := s.chequebook.SplitDeposit(r.Context(), amount, maxTransfer)
+	if errors.Is(err, chequebook.ErrInsufficientFunds) {
+		logger.Debug("chequebook split deposit: deposit failed", "error", err)
+		logger.Error(nil, "chequebook split deposit: deposit failed")
+		jsonhttp.BadRequest(w, errChequebookInsufficientFunds)
+		return
+	}
+	if errors.Is(err, chequebook.ErrInvalidMaxTransfer) {
+		logger.Debug("chequebook split deposit: deposit failed", "error", err)
+		logger.Error(nil, "chequebook split deposit: deposit failed")
+		jsonhttp.BadRequest(w, errChequebookInvalidMax)
+		return
+	}
+	if err != nil {
+		if result != nil {
+			logger.Debug("chequebook split deposit: deposit failed", "error", err, "confirmed_transfers", result.TxHashes, "received", result.Received)
+		} else {
+			logger.Debug("chequebook split deposit: deposit failed", "error", err)
+		}
+		logger.Error(nil, "chequebook split deposit: deposit failed")
+		jsonhttp.InternalServerError(w, errChequebookNoDeposit)
+		return
+	}
+
+	jsonhttp.OK(w, chequebookSplitDepositResponse{
+		TransactionHashes: result.TxHashes,
+		Amount:            bigint.Wrap(result.Amount),
+		Received:          bigint.Wrap(result.Received),
+		Fee:               bigint.Wrap(result.Fee()),
+	})
+}
@@ -7,6 +7,7 @@ package api_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
 	"reflect"
@@ -17,12 +18,15 @@ import (
 	"github.com/ethersphere/bee/pkg/bigint"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/keystore"
 	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
 	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
 
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
 )
 
 func TestChequebookBalance(t *testing.T) {
@@ -138,6 +142,295 @@ func TestChequebookAddress(t *testing.T) {
 	}
 }
 
+func TestChequebookToken(t *testing.T) {
+	t.Parallel()
+
+	token := &chequebook.Token{
+		Address:  common.HexToAddress("0xfffff"),
+		Symbol:   "BZZ",
+		Name:     "Swarm Token",
+		Decimals: 16,
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{mock.WithTokenFunc(func(context.Context) (*chequebook.Token, error) {
+			return token, nil
+		})},
+	})
+
+	expected := &api.ChequebookTokenResponse{
+		Address:  token.Address,
+		Symbol:   token.Symbol,
+		Name:     token.Name,
+		Decimals: token.Decimals,
+	}
+
+	var got *api.ChequebookTokenResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/token", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got token: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestChequebookTokenError(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{mock.WithTokenFunc(func(context.Context) (*chequebook.Token, error) {
+			return nil, errors.New("New errors")
+		})},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/token", http.StatusInternalServerError,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrChequebookToken,
+			Code:    http.StatusInternalServerError,
+		}),
+	)
+}
+
+func TestChequebookDepositHistory(t *testing.T) {
+	t.Parallel()
+
+	deposits := []chequebook.Deposit{
+		{
+			TxHash:      common.HexToHash("0xaaaa"),
+			BlockNumber: 10,
+			From:        common.HexToAddress("0xbbbb"),
+			Amount:      big.NewInt(500),
+		},
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{mock.WithDepositHistoryFunc(func(context.Context) ([]chequebook.Deposit, error) {
+			return deposits, nil
+		})},
+	})
+
+	expected := &api.ChequebookDepositHistoryResponse{
+		Deposits: []api.ChequebookDepositResponse{
+			{
+				TransactionHash: deposits[0].TxHash,
+				BlockNumber:     deposits[0].BlockNumber,
+				From:            deposits[0].From,
+				Amount:          bigint.Wrap(deposits[0].Amount),
+			},
+		},
+	}
+
+	var got *api.ChequebookDepositHistoryResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/deposits", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got deposits: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestChequebookDepositHistoryError(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{mock.WithDepositHistoryFunc(func(context.Context) ([]chequebook.Deposit, error) {
+			return nil, errors.New("New errors")
+		})},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/deposits", http.StatusInternalServerError,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrChequebookDepositHistory,
+			Code:    http.StatusInternalServerError,
+		}),
+	)
+}
+
+type trustedFactoriesMock struct {
+	factory common.Address
+	legacy  []common.Address
+	err     error
+}
+
+func (m *trustedFactoriesMock) TrustedFactories() (common.Address, []common.Address) {
+	return m.factory, m.legacy
+}
+
+func (m *trustedFactoriesMock) SetLegacyFactories(_ context.Context, addresses []common.Address) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.legacy = addresses
+	return nil
+}
+
+func TestChequebookFactories(t *testing.T) {
+	t.Parallel()
+
+	factory := common.HexToAddress("0xaaaa")
+	legacyFactory := common.HexToAddress("0xbbbb")
+
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:  true,
+			Factories: &trustedFactoriesMock{factory: factory},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/factories", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookFactoriesResponse{
+				Factory:         factory,
+				LegacyFactories: []common.Address{},
+			}),
+		)
+	})
+
+	t.Run("put", func(t *testing.T) {
+		t.Parallel()
+
+		factories := &trustedFactoriesMock{factory: factory}
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:  true,
+			Factories: factories,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/factories", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(api.ChequebookFactoriesRequest{
+				LegacyFactories: []common.Address{legacyFactory},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookFactoriesResponse{
+				Factory:         factory,
+				LegacyFactories: []common.Address{legacyFactory},
+			}),
+		)
+
+		if len(factories.legacy) != 1 || factories.legacy[0] != legacyFactory {
+			t.Fatalf("got legacy factories %v, want %v", factories.legacy, []common.Address{legacyFactory})
+		}
+	})
+
+	t.Run("put invalid factory", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:  true,
+			Factories: &trustedFactoriesMock{factory: factory, err: chequebook.ErrInvalidFactory},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/factories", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.ChequebookFactoriesRequest{
+				LegacyFactories: []common.Address{legacyFactory},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: chequebook.ErrInvalidFactory.Error(),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("put error", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:  true,
+			Factories: &trustedFactoriesMock{factory: factory, err: errors.New("rpc error")},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/factories", http.StatusInternalServerError,
+			jsonhttptest.WithJSONRequestBody(api.ChequebookFactoriesRequest{
+				LegacyFactories: []common.Address{legacyFactory},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: api.ErrChequebookSetFactories,
+				Code:    http.StatusInternalServerError,
+			}),
+		)
+	})
+}
+
+type passphraseRotatorMock struct {
+	passphrase string
+}
+
+func (m *passphraseRotatorMock) RotatePassphrase(oldPassphrase, newPassphrase string) error {
+	if oldPassphrase != m.passphrase {
+		return keystore.ErrInvalidPassword
+	}
+	m.passphrase = newPassphrase
+	return nil
+}
+
+func TestChequeSignerPassphrase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		rotator := &passphraseRotatorMock{passphrase: "old"}
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:     true,
+			ChequeSigner: rotator,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/signer/passphrase", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(api.ChequeSignerPassphraseRequest{
+				OldPassphrase: "old",
+				NewPassphrase: "new",
+			}),
+		)
+
+		if rotator.passphrase != "new" {
+			t.Fatalf("got passphrase %q, want %q", rotator.passphrase, "new")
+		}
+	})
+
+	t.Run("invalid passphrase", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:     true,
+			ChequeSigner: &passphraseRotatorMock{passphrase: "old"},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/signer/passphrase", http.StatusForbidden,
+			jsonhttptest.WithJSONRequestBody(api.ChequeSignerPassphraseRequest{
+				OldPassphrase: "wrong",
+				NewPassphrase: "new",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: keystore.ErrInvalidPassword.Error(),
+				Code:    http.StatusForbidden,
+			}),
+		)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPut, "/chequebook/signer/passphrase", http.StatusMethodNotAllowed,
+			jsonhttptest.WithJSONRequestBody(api.ChequeSignerPassphraseRequest{
+				OldPassphrase: "old",
+				NewPassphrase: "new",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: chequebook.ErrPassphraseRotationUnsupported.Error(),
+				Code:    http.StatusMethodNotAllowed,
+			}),
+		)
+	})
+}
+
 func TestChequebookWithdraw(t *testing.T) {
 	t.Parallel()
 
@@ -200,6 +493,26 @@ func TestChequebookWithdraw(t *testing.T) {
 			t.Errorf("got address: %+v, expected: %+v", got, expected)
 		}
 	})
+
+	t.Run("insufficient gas funds", func(t *testing.T) {
+		t.Parallel()
+
+		chequebookWithdrawFunc := func(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
+			return common.Hash{}, fmt.Errorf("%w: not enough ETH", transaction.ErrInsufficientGasFunds)
+		}
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:       true,
+			ChequebookOpts: []mock.Option{mock.WithChequebookWithdrawFunc(chequebookWithdrawFunc)},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/withdraw?amount=500", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "insufficient ETH for gas",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
 }
 
 func TestChequebookDeposit(t *testing.T) {
@@ -265,6 +578,75 @@ func TestChequebookDeposit(t *testing.T) {
 			t.Errorf("got address: %+v, expected: %+v", got, expected)
 		}
 	})
+
+	t.Run("negative amount", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			ChequebookOpts: []mock.Option{mock.WithChequebookDepositFunc(func(context.Context, *big.Int) (common.Hash, error) {
+				t.Fatal("negative amount deposited")
+				return common.Hash{}, nil
+			})},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/deposit?amount=-700", http.StatusBadRequest)
+	})
+}
+
+func TestChequebookSplitDeposit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		result := &chequebook.SplitDepositResult{
+			TxHashes: []common.Hash{common.HexToHash("0xaa"), common.HexToHash("0xbb")},
+			Amount:   big.NewInt(700),
+			Received: big.NewInt(693),
+		}
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			ChequebookOpts: []mock.Option{mock.WithSplitDepositFunc(func(ctx context.Context, amount, maxTransfer *big.Int) (*chequebook.SplitDepositResult, error) {
+				if amount.Cmp(big.NewInt(700)) != 0 || maxTransfer.Cmp(big.NewInt(400)) != 0 {
+					return nil, errors.New("wrong amounts")
+				}
+				return result, nil
+			})},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/deposit/split?amount=700&maxTransfer=400", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookSplitDepositResponse{
+				TransactionHashes: result.TxHashes,
+				Amount:            bigint.Wrap(big.NewInt(700)),
+				Received:          bigint.Wrap(big.NewInt(693)),
+				Fee:               bigint.Wrap(big.NewInt(7)),
+			}),
+		)
+	})
+
+	t.Run("invalid max transfer", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			ChequebookOpts: []mock.Option{mock.WithSplitDepositFunc(func(ctx context.Context, amount, maxTransfer *big.Int) (*chequebook.SplitDepositResult, error) {
+				return nil, chequebook.ErrInvalidMaxTransfer
+			})},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/deposit/split?amount=700&maxTransfer=0", http.StatusBadRequest)
+	})
+
+	t.Run("missing max transfer", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/deposit/split?amount=700", http.StatusBadRequest)
+	})
 }
 
 func TestChequebookLastCheques(t *testing.T) {
@@ -418,16 +800,82 @@ func TestChequebookLastCheques(t *testing.T) {
 		LastCheques: lastchequesexpected,
 	}
 
-	// We expect a list of items unordered by peer:
-	var got *api.ChequebookLastChequesResponse
+	// We expect a list of items ordered by peer:
 	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque", http.StatusOK,
-		jsonhttptest.WithUnmarshalJSONResponse(&got),
+		jsonhttptest.WithExpectedJSONResponse(expected),
 	)
 
-	if !LastChequesEqual(got, expected) {
-		t.Fatalf("Got: \n %+v \n\n Expected: \n %+v \n\n", got, expected)
-	}
+	t.Run("pages by payout", func(t *testing.T) {
+		t.Parallel()
+
+		// sent payouts first, then received payouts, highest first
+		order := []api.ChequebookLastChequesPeerResponse{
+			lastchequesexpected[1], lastchequesexpected[0], lastchequesexpected[2], lastchequesexpected[4], lastchequesexpected[3],
+		}
+
+		var got []api.ChequebookLastChequesPeerResponse
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(order) {
+				t.Fatal("pagination does not end")
+			}
+			var page *api.ChequebookLastChequesResponse
+			jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque?order=payout&limit=2&cursor="+cursor, http.StatusOK,
+				jsonhttptest.WithUnmarshalJSONResponse(&page),
+			)
+			if len(page.LastCheques) > 2 {
+				t.Fatalf("got %d cheques, want at most 2", len(page.LastCheques))
+			}
+			got = append(got, page.LastCheques...)
+			if page.Next == "" {
+				break
+			}
+			cursor = page.Next
+		}
+
+		if !reflect.DeepEqual(got, order) {
+			t.Fatalf("Got: \n %+v \n\n Expected: \n %+v \n\n", got, order)
+		}
+	})
+
+	t.Run("invalid order", func(t *testing.T) {
+		t.Parallel()
 
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque?order=age", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: swap.ErrInvalidChequeOrder.Error(),
+			}),
+		)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque?cursor=invalid", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: swap.ErrInvalidCursor.Error(),
+			}),
+		)
+	})
+}
+
+func TestChequebookLastChequesCount(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{
+			mock.WithLastChequesCountFunc(func(context.Context) (int, error) {
+				return 3, nil
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque?count=true", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.ChequebookLastChequesCountResponse{Count: 3}),
+	)
 }
 
 func TestChequebookLastChequesPeer(t *testing.T) {
@@ -528,6 +976,76 @@ func TestChequebookCashout(t *testing.T) {
 	}
 }
 
+func TestChequebookCashoutAlreadyCashed(t *testing.T) {
+	t.Parallel()
+
+	addr := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{swapmock.WithCashChequeFunc(func(ctx context.Context, peer swarm.Address) (common.Hash, error) {
+			return common.Hash{}, fmt.Errorf("paid out 10: %w", chequebook.ErrChequeCashed)
+		})},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+addr.String(), http.StatusConflict,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "cheque already cashed",
+			Code:    http.StatusConflict,
+		}),
+	)
+}
+
+func TestChequebookCashoutBatch(t *testing.T) {
+	t.Parallel()
+
+	peer1 := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	peer2 := swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")
+	chequebookAddress := common.HexToAddress("0xabcd")
+	txHash := common.HexToHash("0xffff")
+
+	cashBatchFunc := func(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+		if len(peers) != 2 || !peers[0].Equal(peer1) || !peers[1].Equal(peer2) {
+			t.Fatalf("got peers %v", peers)
+		}
+		return []chequebook.BatchCashoutResult{
+			{Chequebook: chequebookAddress, TxHash: txHash},
+			{Err: chequebook.ErrNoCheque},
+		}, nil
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{swapmock.WithCashChequeBatchFunc(cashBatchFunc)},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(api.SwapCashoutBatchRequest{Peers: []swarm.Address{peer1, peer2}}),
+			jsonhttptest.WithExpectedJSONResponse(api.SwapCashoutBatchResponse{
+				Results: []api.SwapCashoutBatchResult{
+					{Peer: peer1, Chequebook: chequebookAddress, TransactionHash: &txHash},
+					{Peer: peer2, Error: chequebook.ErrNoCheque.Error()},
+				},
+			}),
+		)
+	})
+
+	t.Run("no peers", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.SwapCashoutBatchRequest{}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: api.ErrNoCashoutPeers,
+			}),
+		)
+	})
+}
+
 func TestChequebookCashout_CustomGas(t *testing.T) {
 	t.Parallel()
 
@@ -779,35 +1297,92 @@ func Test_chequebookLastPeerHandler_invalidInputs(t *testing.T) {
 	}
 }
 
-func LastChequesEqual(a, b *api.ChequebookLastChequesResponse) bool {
-
-	var state bool
+func TestChequeCashouts(t *testing.T) {
+	t.Parallel()
 
-	for akeys := range a.LastCheques {
-		state = false
-		for bkeys := range b.LastCheques {
-			if reflect.DeepEqual(a.LastCheques[akeys], b.LastCheques[bkeys]) {
-				state = true
-				break
-			}
-		}
-		if !state {
-			return false
-		}
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	txHash := common.HexToHash("0xaa")
+	cheque := chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       common.HexToAddress("0xcc"),
+			Beneficiary:      common.HexToAddress("0xbb"),
+			CumulativePayout: big.NewInt(500),
+		},
 	}
-
-	for bkeys := range b.LastCheques {
-		state = false
-		for akeys := range a.LastCheques {
-			if reflect.DeepEqual(a.LastCheques[akeys], b.LastCheques[bkeys]) {
-				state = true
-				break
-			}
-		}
-		if !state {
-			return false
-		}
+	chequeResponse := api.ChequebookLastChequePeerResponse{
+		Chequebook:  cheque.Chequebook.String(),
+		Payout:      bigint.Wrap(cheque.CumulativePayout),
+		Beneficiary: cheque.Beneficiary.String(),
 	}
 
-	return true
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithChequeCashoutsFunc(func(p swarm.Address) ([]chequebook.ChequeCashout, error) {
+				if !p.Equal(peer) {
+					return nil, chequebook.ErrNoCheque
+				}
+				return []chequebook.ChequeCashout{{TxHash: txHash, Cheque: cheque, Time: 10}}, nil
+			}),
+			swapmock.WithCashoutTransactionChequesFunc(func(hash common.Hash) ([]chequebook.SignedCheque, error) {
+				if hash != txHash {
+					return nil, chequebook.ErrUnknownCashoutTransaction
+				}
+				return []chequebook.SignedCheque{cheque}, nil
+			}),
+			swapmock.WithReconcileCashoutsFunc(func(context.Context) (*chequebook.CashoutReconciliation, error) {
+				return &chequebook.CashoutReconciliation{
+					NeverCashed: []chequebook.SignedCheque{cheque},
+					CashedTwice: [][]chequebook.ChequeCashout{},
+				}, nil
+			}),
+		},
+	})
+
+	t.Run("by peer", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashout/"+peer.String()+"/transactions", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequeCashoutsResponse{
+				Peer: peer,
+				Cashouts: []api.ChequeCashoutResponse{
+					{TransactionHash: txHash, Cheque: chequeResponse, Time: 10},
+				},
+			}),
+		)
+	})
+
+	t.Run("unknown peer", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashout/"+swarm.MustParseHexAddress("ab").String()+"/transactions", http.StatusNotFound)
+	})
+
+	t.Run("by transaction", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashouts/"+txHash.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.CashoutTransactionChequesResponse{
+				TransactionHash: txHash,
+				Cheques:         []api.ChequebookLastChequePeerResponse{chequeResponse},
+			}),
+		)
+	})
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashouts/"+common.HexToHash("0xff").String(), http.StatusNotFound)
+	})
+
+	t.Run("reconciliation", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/reconciliation", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.CashoutReconciliationResponse{
+				NeverCashed: []api.ChequebookLastChequePeerResponse{chequeResponse},
+				CashedTwice: [][]api.ChequeCashoutResponse{},
+			}),
+		)
+	})
 }
@@ -0,0 +1,94 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/gorilla/mux"
+)
+
+const errChequebookContractInfo = "cannot get chequebook contract info"
+
+type chequebookContractFeaturesResponse struct {
+	HardDeposits    bool `json:"hardDeposits"`
+	PartialCashouts bool `json:"partialCashouts"`
+	MinimalProxy    bool `json:"minimalProxy"`
+	Assignments     bool `json:"assignments"`
+}
+
+type chequebookContractResponse struct {
+	Address         common.Address                     `json:"address"`
+	Version         string                             `json:"version"`
+	Features        chequebookContractFeaturesResponse `json:"features"`
+	Factory         common.Address                     `json:"factory"`
+	DeploymentBlock uint64                             `json:"deploymentBlock"`
+}
+
+// chequebookContractHandler reports the contract version of the chequebook of the node.
+func (s *Service) chequebookContractHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_contract").Build()
+
+	s.writeChequebookContract(w, r, logger, s.chequebook.Address())
+}
+
+// chequebookContractAddressHandler reports the contract version of any
+// chequebook, like the one of a peer.
+func (s *Service) chequebookContractAddressHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_contract_by_address").Build()
+
+	paths := struct {
+		Address common.Address `map:"address" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	s.writeChequebookContract(w, r, logger, paths.Address)
+}
+
+func (s *Service) writeChequebookContract(w http.ResponseWriter, r *http.Request, logger log.Logger, address common.Address) {
+	if s.contracts == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrContractInfoUnsupported)
+		return
+	}
+
+	info, err := s.contracts.ContractInfo(r.Context(), address)
+	if errors.Is(err, chequebook.ErrNotDeployedByFactory) {
+		logger.Debug("get contract info failed", "chequebook_address", address, "error", err)
+		jsonhttp.NotFound(w, err)
+		return
+	}
+	if errors.Is(err, chequebook.ErrUnknownChequebookBytecode) {
+		logger.Debug("get contract info failed", "chequebook_address", address, "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get contract info failed", "chequebook_address", address, "error", err)
+		logger.Error(nil, "get contract info failed")
+		jsonhttp.InternalServerError(w, errChequebookContractInfo)
+		return
+	}
+
+	jsonhttp.OK(w, chequebookContractResponse{
+		Address: info.Address,
+		Version: info.Version,
+		Features: chequebookContractFeaturesResponse{
+			HardDeposits:    info.Features.HardDeposits,
+			PartialCashouts: info.Features.PartialCashouts,
+			MinimalProxy:    info.Features.MinimalProxy,
+			Assignments:     info.Features.Assignments,
+		},
+		Factory:         info.Factory,
+		DeploymentBlock: info.DeploymentBlock,
+	})
+}
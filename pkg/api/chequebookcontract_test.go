@@ -0,0 +1,110 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+)
+
+type contractInspectorMock map[common.Address]*chequebook.ContractInfo
+
+func (m contractInspectorMock) ContractInfo(_ context.Context, address common.Address) (*chequebook.ContractInfo, error) {
+	info, ok := m[address]
+	if !ok {
+		return nil, chequebook.ErrNotDeployedByFactory
+	}
+	return info, nil
+}
+
+func TestChequebookContract(t *testing.T) {
+	t.Parallel()
+
+	own := common.HexToAddress("0xaaaa")
+	peer := common.HexToAddress("0xbbbb")
+	factory := common.HexToAddress("0xffff")
+	contracts := contractInspectorMock{
+		own: {
+			Address:         own,
+			Version:         chequebook.ContractVersionv0_4_0,
+			Features:        chequebook.ContractFeatures{HardDeposits: true, PartialCashouts: true, MinimalProxy: true},
+			Factory:         factory,
+			DeploymentBlock: 100,
+		},
+		peer: {
+			Address:  peer,
+			Version:  chequebook.ContractVersionv0_3_1,
+			Features: chequebook.ContractFeatures{HardDeposits: true, PartialCashouts: true},
+			Factory:  factory,
+		},
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:       true,
+		Contracts:      contracts,
+		ChequebookOpts: []mock.Option{mock.WithChequebookAddressFunc(func() common.Address { return own })},
+	})
+
+	t.Run("own", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/contract", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookContractResponse{
+				Address:         own,
+				Version:         chequebook.ContractVersionv0_4_0,
+				Features:        api.ChequebookContractFeatures{HardDeposits: true, PartialCashouts: true, MinimalProxy: true},
+				Factory:         factory,
+				DeploymentBlock: 100,
+			}),
+		)
+	})
+
+	t.Run("peer", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/contract/"+peer.Hex(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookContractResponse{
+				Address:  peer,
+				Version:  chequebook.ContractVersionv0_3_1,
+				Features: api.ChequebookContractFeatures{HardDeposits: true, PartialCashouts: true},
+				Factory:  factory,
+			}),
+		)
+	})
+
+	t.Run("not deployed by factory", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/contract/"+common.HexToAddress("0xcccc").Hex(), http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: chequebook.ErrNotDeployedByFactory.Error(),
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/contract", http.StatusMethodNotAllowed,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: chequebook.ErrContractInfoUnsupported.Error(),
+				Code:    http.StatusMethodNotAllowed,
+			}),
+		)
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+const errChequebookSelfTest = "cannot run chequebook self-test"
+
+type chequebookSelfTestStepResponse struct {
+	Name            string         `json:"name"`
+	DurationSeconds float64        `json:"durationSeconds"`
+	TransactionHash *common.Hash   `json:"transactionHash,omitempty"`
+	GasUsed         uint64         `json:"gasUsed"`
+	Cost            *bigint.BigInt `json:"cost"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type chequebookSelfTestResponse struct {
+	Passed          bool                             `json:"passed"`
+	Amount          *bigint.BigInt                   `json:"amount"`
+	Beneficiary     common.Address                   `json:"beneficiary"`
+	Steps           []chequebookSelfTestStepResponse `json:"steps"`
+	TotalCost       *bigint.BigInt                   `json:"totalCost"`
+	DurationSeconds float64                          `json:"durationSeconds"`
+}
+
+// chequebookSelfTestHandler runs the chequebook burn-in self-test: a deposit,
+// a cheque issued to the node itself, its cashout and a withdrawal, all
+// against the chain. A failed step is reported in the response, not as an
+// error status.
+func (s *Service) chequebookSelfTestHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_selftest").Build()
+
+	queries := struct {
+		Amount *big.Int `map:"amount"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+	amount := chequebook.DefaultSelfTestAmount
+	if queries.Amount != nil {
+		amount = queries.Amount
+	}
+
+	if !s.cashOutChequeSem.TryAcquire(1) {
+		logger.Debug("simultaneous on-chain operations not supported")
+		logger.Error(nil, "simultaneous on-chain operations not supported")
+		jsonhttp.TooManyRequests(w, "simultaneous on-chain operations not supported")
+		return
+	}
+	defer s.cashOutChequeSem.Release(1)
+
+	report, err := chequebook.SelfTest(r.Context(), s.chequebook, s.transaction, s.chainBackend, s.ethereumAddress, amount)
+	if err != nil {
+		logger.Debug("chequebook self-test failed", "error", err)
+		if errors.Is(err, chequebook.ErrInvalidSelfTestAmount) {
+			jsonhttp.BadRequest(w, err)
+			return
+		}
+		logger.Error(nil, "chequebook self-test failed")
+		jsonhttp.InternalServerError(w, errChequebookSelfTest)
+		return
+	}
+	if !report.Passed {
+		logger.Warning("chequebook self-test failed", "step", report.Steps[len(report.Steps)-1].Error)
+	}
+
+	steps := make([]chequebookSelfTestStepResponse, 0, len(report.Steps))
+	for _, step := range report.Steps {
+		response := chequebookSelfTestStepResponse{
+			Name:            step.Name,
+			DurationSeconds: step.Duration.Seconds(),
+			GasUsed:         step.GasUsed,
+			Cost:            bigint.Wrap(step.Cost),
+			Error:           step.Error,
+		}
+		if step.TxHash != (common.Hash{}) {
+			txHash := step.TxHash
+			response.TransactionHash = &txHash
+		}
+		steps = append(steps, response)
+	}
+
+	jsonhttp.OK(w, chequebookSelfTestResponse{
+		Passed:          report.Passed,
+		Amount:          bigint.Wrap(report.Amount),
+		Beneficiary:     report.Beneficiary,
+		Steps:           steps,
+		TotalCost:       bigint.Wrap(report.TotalCost),
+		DurationSeconds: report.Duration.Seconds(),
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+)
+
+func TestChequebookSelfTest(t *testing.T) {
+	t.Parallel()
+
+	var deposited *big.Int
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+				deposited = amount
+				return common.Hash{}, errors.New("insufficient funds")
+			}),
+		},
+	})
+
+	var response api.ChequebookSelfTestResponse
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/selftest?amount=10", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&response),
+	)
+	if deposited.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("got deposit of %d, want 20", deposited)
+	}
+	if response.Passed || len(response.Steps) != 1 || response.Steps[0].Name != chequebook.SelfTestStepDeposit || response.Steps[0].Error == "" {
+		t.Fatalf("got response %+v, want failed deposit", response)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/selftest?amount=0", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: chequebook.ErrInvalidSelfTestAmount.Error(),
+			Code:    http.StatusBadRequest,
+		}),
+	)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/gorilla/mux"
+)
+
+const errCantChequeHistory = "cannot get cheque history"
+
+type issuedChequeResponse struct {
+	Chequebook       common.Address `json:"chequebook"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Amount           *bigint.BigInt `json:"amount"`
+	Signature        string         `json:"signature"`
+	Timestamp        int64          `json:"timestamp"`
+}
+
+type chequeHistoryResponse struct {
+	Beneficiary common.Address         `json:"beneficiary"`
+	Total       uint64                 `json:"total"`
+	Cheques     []issuedChequeResponse `json:"cheques"`
+}
+
+// chequeHistoryHandler returns the cheques issued to the beneficiary in the
+// order they were issued.
+func (s *Service) chequeHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_history").Build()
+
+	paths := struct {
+		Beneficiary common.Address `map:"beneficiary" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		Offset uint64 `map:"offset"`
+		Limit  uint64 `map:"limit"`
+	}{
+		Limit: 100, // Default limit.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	cheques, total, err := s.chequebook.ChequeHistory(r.Context(), paths.Beneficiary, queries.Offset, queries.Limit)
+	if err != nil {
+		logger.Debug("get cheque history failed", "beneficiary", paths.Beneficiary, "offset", queries.Offset, "limit", queries.Limit, "error", err)
+		logger.Error(nil, "get cheque history failed", "beneficiary", paths.Beneficiary)
+		jsonhttp.InternalServerError(w, errCantChequeHistory)
+		return
+	}
+
+	response := chequeHistoryResponse{
+		Beneficiary: paths.Beneficiary,
+		Total:       total,
+		Cheques:     make([]issuedChequeResponse, 0, len(cheques)),
+	}
+	for _, cheque := range cheques {
+		response.Cheques = append(response.Cheques, issuedChequeResponse{
+			Chequebook:       cheque.Cheque.Chequebook,
+			CumulativePayout: bigint.Wrap(cheque.Cheque.CumulativePayout),
+			Amount:           bigint.Wrap(cheque.Amount),
+			Signature:        hexutil.Encode(cheque.Cheque.Signature),
+			Timestamp:        cheque.Time,
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
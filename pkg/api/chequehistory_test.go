@@ -0,0 +1,85 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+)
+
+func TestChequeHistory(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xbbbb")
+	chequebookAddress := common.HexToAddress("0xcccc")
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			ChequebookOpts: []mock.Option{
+				mock.WithChequeHistoryFunc(func(ctx context.Context, b common.Address, offset, limit uint64) ([]chequebook.IssuedCheque, uint64, error) {
+					if b != beneficiary || offset != 1 || limit != 2 {
+						t.Fatalf("got history of %v offset %d limit %d", b, offset, limit)
+					}
+					return []chequebook.IssuedCheque{{
+						Cheque: chequebook.SignedCheque{
+							Cheque:    chequebook.Cheque{Chequebook: chequebookAddress, Beneficiary: beneficiary, CumulativePayout: big.NewInt(300)},
+							Signature: []byte{1, 2},
+						},
+						Amount: big.NewInt(200),
+						Time:   1000,
+					}}, 2, nil
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/history/"+beneficiary.String()+"?offset=1&limit=2", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequeHistoryResponse{
+				Beneficiary: beneficiary,
+				Total:       2,
+				Cheques: []api.IssuedChequeResponse{{
+					Chequebook:       chequebookAddress,
+					CumulativePayout: bigint.Wrap(big.NewInt(300)),
+					Amount:           bigint.Wrap(big.NewInt(200)),
+					Signature:        "0x0102",
+					Timestamp:        1000,
+				}},
+			}),
+		)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			ChequebookOpts: []mock.Option{
+				mock.WithChequeHistoryFunc(func(context.Context, common.Address, uint64, uint64) ([]chequebook.IssuedCheque, uint64, error) {
+					return nil, 0, errors.New("failed")
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/history/"+beneficiary.String(), http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "cannot get cheque history",
+				Code:    http.StatusInternalServerError,
+			}),
+		)
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errCantPreviewCheque = "cannot preview cheque"
+	errNoBeneficiary     = "unknown beneficiary for peer"
+)
+
+type chequePolicyResponse struct {
+	Policy  string `json:"policy"`
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type chequePreviewResponse struct {
+	Peer             swarm.Address          `json:"peer"`
+	Beneficiary      common.Address         `json:"beneficiary"`
+	Amount           *bigint.BigInt         `json:"amount"`
+	CumulativePayout *bigint.BigInt         `json:"cumulativePayout"`
+	AvailableBalance *bigint.BigInt         `json:"availableBalance"`
+	Allowed          bool                   `json:"allowed"`
+	Policies         []chequePolicyResponse `json:"policies"`
+}
+
+// chequePreviewHandler evaluates paying the amount in accounting units to the
+// peer without signing or sending a cheque.
+func (s *Service) chequePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cheque_preview").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		Amount *big.Int `map:"amount" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	preview, err := s.swap.PreviewPay(r.Context(), paths.Peer, queries.Amount)
+	if err != nil {
+		logger.Debug("preview cheque failed", "peer_address", paths.Peer, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled),
+			errors.Is(err, swap.ErrNoChequebook):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, swap.ErrUnknownBeneficary):
+			jsonhttp.NotFound(w, errNoBeneficiary)
+		default:
+			logger.Error(nil, "preview cheque failed", "peer_address", paths.Peer)
+			jsonhttp.InternalServerError(w, errCantPreviewCheque)
+		}
+		return
+	}
+
+	response := chequePreviewResponse{
+		Peer:             paths.Peer,
+		Beneficiary:      preview.Beneficiary,
+		Amount:           bigint.Wrap(preview.Amount),
+		CumulativePayout: bigint.Wrap(preview.CumulativePayout),
+		AvailableBalance: bigint.Wrap(preview.AvailableBalance),
+		Allowed:          preview.Err() == nil,
+		Policies:         make([]chequePolicyResponse, 0, len(preview.Policies)),
+	}
+	for _, check := range preview.Policies {
+		policy := chequePolicyResponse{Policy: check.Policy, Allowed: check.Allowed()}
+		if check.Err != nil {
+			policy.Reason = check.Err.Error()
+		}
+		response.Policies = append(response.Policies, policy)
+	}
+	jsonhttp.OK(w, response)
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChequePreview(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	beneficiary := common.HexToAddress("0xfffe")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithPreviewPayFunc(func(_ context.Context, p swarm.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+				if !p.Equal(peer) {
+					return nil, swap.ErrUnknownBeneficary
+				}
+				return &chequebook.IssuePreview{
+					Beneficiary:      beneficiary,
+					Amount:           amount,
+					CumulativePayout: big.NewInt(500),
+					AvailableBalance: big.NewInt(100),
+					Policies: []chequebook.PolicyCheck{
+						{Policy: chequebook.PolicyFunds},
+						{Policy: chequebook.PolicyRateLimit, Err: chequebook.ErrIssueRateLimited},
+					},
+				}, nil
+			}),
+		},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque/"+peer.String()+"/preview?amount=200", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequePreviewResponse{
+				Peer:             peer,
+				Beneficiary:      beneficiary,
+				Amount:           bigint.Wrap(big.NewInt(200)),
+				CumulativePayout: bigint.Wrap(big.NewInt(500)),
+				AvailableBalance: bigint.Wrap(big.NewInt(100)),
+				Allowed:          false,
+				Policies: []api.ChequePolicyResponse{
+					{Policy: "funds", Allowed: true},
+					{Policy: "rate_limit", Allowed: false, Reason: chequebook.ErrIssueRateLimited.Error()},
+				},
+			}),
+		)
+	})
+
+	t.Run("unknown beneficiary", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque/"+swarm.MustParseHexAddress("ab").String()+"/preview?amount=200", http.StatusNotFound)
+	})
+
+	t.Run("missing amount", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque/"+peer.String()+"/preview", http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/remoteverifier"
+)
+
+const errCantVerifyCheque = "cannot verify cheque"
+
+// chequeVerificationHandler verifies a cheque received by another node which
+// delegates its cheque verification to this one. Rejected cheques are
+// answered with the reason, failures to verify with an error status.
+func (s *Service) chequeVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_verification").Build()
+
+	if s.chequeVerifier == nil {
+		jsonhttp.MethodNotAllowed(w, errCantVerifyCheque)
+		return
+	}
+
+	var data remoteverifier.Request
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if data.Cheque == nil {
+		jsonhttp.BadRequest(w, "cheque not set")
+		return
+	}
+
+	var lastCumulativePayout *big.Int
+	if data.LastCumulativePayout != nil {
+		lastCumulativePayout = data.LastCumulativePayout.Int
+	}
+
+	err := s.chequeVerifier.VerifyCheque(r.Context(), data.Cheque, lastCumulativePayout)
+	if err != nil {
+		reason, ok := remoteverifier.Reason(err)
+		if !ok {
+			logger.Debug("verify cheque failed", "chequebook", data.Cheque.Chequebook, "error", err)
+			logger.Error(nil, "verify cheque failed", "chequebook", data.Cheque.Chequebook)
+			jsonhttp.InternalServerError(w, errCantVerifyCheque)
+			return
+		}
+		logger.Debug("cheque rejected", "chequebook", data.Cheque.Chequebook, "error", err)
+		jsonhttp.OK(w, remoteverifier.Response{Reason: reason})
+		return
+	}
+
+	jsonhttp.OK(w, remoteverifier.Response{})
+}
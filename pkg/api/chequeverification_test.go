@@ -0,0 +1,80 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/remoteverifier"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+)
+
+func TestChequeVerification(t *testing.T) {
+	t.Parallel()
+
+	trustedChequebook := common.HexToAddress("0xfff5")
+	untrustedChequebook := common.HexToAddress("0xfff6")
+	unreachableChequebook := common.HexToAddress("0xfff7")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequeVerifier: chequestoremock.NewChequeStore(chequestoremock.WithVerifyChequeFunc(func(_ context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error {
+			switch cheque.Chequebook {
+			case untrustedChequebook:
+				return chequebook.ErrNotDeployedByFactory
+			case unreachableChequebook:
+				return errors.New("chain unreachable")
+			}
+			if lastCumulativePayout != nil && cheque.CumulativePayout.Cmp(lastCumulativePayout) <= 0 {
+				return chequebook.ErrChequeNotIncreasing
+			}
+			return nil
+		})),
+	})
+	verifier := remoteverifier.NewHTTPVerifier("", testServer)
+
+	cheque := func(chequebookAddress common.Address, cumulativePayout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      common.HexToAddress("0xbeee"),
+				CumulativePayout: big.NewInt(cumulativePayout),
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	if err := verifier.VerifyCheque(context.Background(), cheque(trustedChequebook, 10), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.VerifyCheque(context.Background(), cheque(trustedChequebook, 10), big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.VerifyCheque(context.Background(), cheque(trustedChequebook, 10), big.NewInt(10)); !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeNotIncreasing)
+	}
+	if err := verifier.VerifyCheque(context.Background(), cheque(untrustedChequebook, 10), nil); !errors.Is(err, chequebook.ErrNotDeployedByFactory) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNotDeployedByFactory)
+	}
+	if err := verifier.VerifyCheque(context.Background(), cheque(unreachableChequebook, 10), nil); !errors.Is(err, remoteverifier.ErrVerifier) {
+		t.Fatalf("got error %v, want %v", err, remoteverifier.ErrVerifier)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, remoteverifier.Path, http.StatusBadRequest,
+		jsonhttptest.WithJSONRequestBody(remoteverifier.Request{}),
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Code:    http.StatusBadRequest,
+			Message: "cheque not set",
+		}),
+	)
+}
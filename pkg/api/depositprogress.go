@@ -0,0 +1,136 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/gorilla/mux"
+)
+
+const errDepositProgressUnavailable = "deposit progress unavailable"
+
+type depositProgressResponse struct {
+	TransactionHash common.Hash `json:"transactionHash"`
+	Mined           bool        `json:"mined"`
+	BlockNumber     uint64      `json:"blockNumber,omitempty"`
+	Confirmations   uint64      `json:"confirmations"`
+	Required        uint64      `json:"required"`
+	Final           bool        `json:"final"`
+}
+
+func newDepositProgressResponse(p chequebook.DepositProgress) depositProgressResponse {
+	return depositProgressResponse{
+		TransactionHash: p.TxHash,
+		Mined:           p.Mined,
+		BlockNumber:     p.BlockNumber,
+		Confirmations:   p.Confirmations,
+		Required:        p.Required,
+		Final:           p.Final,
+	}
+}
+
+type depositResultResponse struct {
+	TransactionHash common.Hash `json:"transactionHash"`
+	Message         string      `json:"message,omitempty"`
+}
+
+// chequebookDepositProgressHandler waits for the deposit transaction and
+// streams the progress of the wait as server-sent events. The stream ends
+// with a confirmed or a failed event.
+func (s *Service) chequebookDepositProgressHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_deposit_progress").Build()
+
+	paths := struct {
+		Hash common.Hash `map:"hash" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.Error(nil, "response writer does not support streaming")
+		jsonhttp.InternalServerError(w, errDepositProgressUnavailable)
+		return
+	}
+
+	progressC := make(chan chequebook.DepositProgress, 16)
+	resultC := make(chan error, 1)
+	go func() {
+		resultC <- s.chequebook.WaitForDepositWithProgress(r.Context(), paths.Hash, func(p chequebook.DepositProgress) {
+			select {
+			case progressC <- p:
+			case <-r.Context().Done():
+			}
+		})
+	}()
+
+	w.Header().Set(ContentTypeHeader, "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	write := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			logger.Debug("marshal deposit progress failed", "error", err)
+			logger.Error(nil, "marshal deposit progress failed")
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			logger.Debug("write deposit progress failed", "error", err)
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for {
+		select {
+		case p := <-progressC:
+			if !write("progress", newDepositProgressResponse(p)) {
+				return
+			}
+		case err := <-resultC:
+			// report the progress which was sent before the wait returned
+			for drained := false; !drained; {
+				select {
+				case p := <-progressC:
+					if !write("progress", newDepositProgressResponse(p)) {
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			if err == nil {
+				write("confirmed", depositResultResponse{TransactionHash: paths.Hash})
+				return
+			}
+			logger.Debug("wait for deposit failed", "tx", paths.Hash, "error", err)
+			message := err.Error()
+			if !errors.Is(err, transaction.ErrTransactionReverted) && !errors.Is(err, postagecontract.ErrChainDisabled) {
+				logger.Error(nil, "wait for deposit failed", "tx", paths.Hash)
+				message = errDepositProgressUnavailable
+			}
+			write("failed", depositResultResponse{TransactionHash: paths.Hash, Message: message})
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+func TestChequebookDepositProgress(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0xdddd")
+	reverted := common.HexToHash("0xeeee")
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []mock.Option{
+			mock.WithWaitForDepositWithProgressFunc(func(ctx context.Context, hash common.Hash, progressFn chequebook.DepositProgressFunc) error {
+				if hash == reverted {
+					return transaction.ErrTransactionReverted
+				}
+				progressFn(chequebook.DepositProgress{TxHash: hash})
+				progressFn(chequebook.DepositProgress{TxHash: hash, Mined: true, BlockNumber: 10, Confirmations: 1, Required: 2})
+				progressFn(chequebook.DepositProgress{TxHash: hash, Mined: true, BlockNumber: 10, Confirmations: 2, Required: 2, Final: true})
+				return nil
+			}),
+		},
+	})
+
+	read := func(t *testing.T, hash common.Hash) []string {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/chequebook/deposit/"+hash.String()+"/progress", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var events []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if v, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				events = append(events, v)
+			}
+			if v, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events[len(events)-1] += " " + v
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	t.Run("confirmed", func(t *testing.T) {
+		t.Parallel()
+
+		got := read(t, txHash)
+		want := []string{
+			`progress {"transactionHash":"` + txHash.String() + `","mined":false,"confirmations":0,"required":0,"final":false}`,
+			`progress {"transactionHash":"` + txHash.String() + `","mined":true,"blockNumber":10,"confirmations":1,"required":2,"final":false}`,
+			`progress {"transactionHash":"` + txHash.String() + `","mined":true,"blockNumber":10,"confirmations":2,"required":2,"final":true}`,
+			`confirmed {"transactionHash":"` + txHash.String() + `"}`,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got events %q, want %q", got, want)
+		}
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		t.Parallel()
+
+		got := read(t, reverted)
+		want := []string{
+			`failed {"transactionHash":"` + reverted.String() + `","message":"` + transaction.ErrTransactionReverted.Error() + `"}`,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got events %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errNoDisputeSettlement = "no settlement with peer"
+	errInvalidDisputePeer  = "invalid evidence from peer"
+	errCantOpenDispute     = "can not open dispute"
+	errCantDisputes        = "can not get disputes"
+	errCantResolveDispute  = "can not resolve dispute"
+)
+
+type disputeEntryResponse struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Note   string    `json:"note,omitempty"`
+}
+
+type disputeReceiptResponse struct {
+	Chequebook       string         `json:"chequebook"`
+	Beneficiary      string         `json:"beneficiary"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Signature        string         `json:"signature"`
+}
+
+type disputeResponse struct {
+	ID          uint64                            `json:"id"`
+	Peer        swarm.Address                     `json:"peer"`
+	Direction   swap.DisputeDirection             `json:"direction"`
+	Status      swap.DisputeStatus                `json:"status"`
+	Resolution  swap.DisputeResolution            `json:"resolution,omitempty"`
+	LocalPayout *bigint.BigInt                    `json:"localPayout"`
+	PeerPayout  *bigint.BigInt                    `json:"peerPayout"`
+	Matches     bool                              `json:"matches"`
+	Cheque      *chequebookLastChequePeerResponse `json:"cheque,omitempty"`
+	Receipt     *disputeReceiptResponse           `json:"receipt,omitempty"`
+	Statement   *settlementStatementResponse      `json:"statement,omitempty"`
+	Trail       []disputeEntryResponse            `json:"trail"`
+}
+
+type disputesResponse struct {
+	Disputes []disputeResponse `json:"disputes"`
+}
+
+type openDisputeRequest struct {
+	Peer      swarm.Address         `json:"peer"`
+	Direction swap.DisputeDirection `json:"direction"`
+	Note      string                `json:"note"`
+}
+
+type resolveDisputeRequest struct {
+	Resolution swap.DisputeResolution `json:"resolution"`
+	Note       string                 `json:"note"`
+}
+
+func newDisputeResponse(d *swap.Dispute) disputeResponse {
+	response := disputeResponse{
+		ID:          d.ID,
+		Peer:        d.Peer,
+		Direction:   d.Direction,
+		Status:      d.Status,
+		Resolution:  d.Resolution,
+		LocalPayout: bigint.Wrap(d.LocalPayout),
+		PeerPayout:  bigint.Wrap(d.PeerPayout),
+		Matches:     d.Matches(),
+		Trail:       make([]disputeEntryResponse, 0, len(d.Trail)),
+	}
+	if d.Cheque != nil {
+		response.Cheque = &chequebookLastChequePeerResponse{
+			Beneficiary: d.Cheque.Beneficiary.String(),
+			Chequebook:  d.Cheque.Chequebook.String(),
+			Payout:      bigint.Wrap(d.Cheque.CumulativePayout),
+		}
+	}
+	if d.Receipt != nil {
+		response.Receipt = &disputeReceiptResponse{
+			Chequebook:       d.Receipt.Chequebook.String(),
+			Beneficiary:      d.Receipt.Beneficiary.String(),
+			CumulativePayout: bigint.Wrap(d.Receipt.CumulativePayout),
+			Signature:        hexutil.Encode(d.Receipt.Signature),
+		}
+	}
+	if d.Statement != nil {
+		response.Statement = &settlementStatementResponse{
+			Chequebook:       d.Statement.Chequebook.String(),
+			Beneficiary:      d.Statement.Beneficiary.String(),
+			CumulativePayout: bigint.Wrap(d.Statement.CumulativePayout),
+			Timestamp:        d.Statement.Timestamp,
+			Signature:        hexutil.Encode(d.Statement.Signature),
+		}
+	}
+	for _, e := range d.Trail {
+		response.Trail = append(response.Trail, disputeEntryResponse{
+			Time:   e.Time,
+			Action: e.Action,
+			Note:   e.Note,
+		})
+	}
+	return response
+}
+
+// openDisputeHandler opens a dispute about the cheques sent to or received
+// from a peer and compares the cumulative payouts of both sides.
+func (s *Service) openDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_settlements_disputes").Build()
+
+	var data openDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	dispute, err := s.swap.OpenDispute(r.Context(), data.Peer, data.Direction, data.Note)
+	if err != nil {
+		logger.Debug("open dispute failed", "peer_address", data.Peer, "direction", data.Direction, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled),
+			errors.Is(err, swap.ErrNoChequebook):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, swap.ErrInvalidDisputeDirection):
+			jsonhttp.BadRequest(w, err)
+		case errors.Is(err, swap.ErrUnknownBeneficary),
+			errors.Is(err, settlement.ErrPeerNoSettlements):
+			jsonhttp.NotFound(w, errNoDisputeSettlement)
+		case errors.Is(err, chequebook.ErrStatementInvalid),
+			errors.Is(err, chequebook.ErrReceiptInvalid),
+			errors.Is(err, chequebook.ErrWrongBeneficiary),
+			errors.Is(err, swap.ErrWrongChequebook):
+			jsonhttp.BadGateway(w, errInvalidDisputePeer)
+		default:
+			logger.Error(nil, "open dispute failed", "peer_address", data.Peer)
+			jsonhttp.InternalServerError(w, errCantOpenDispute)
+		}
+		return
+	}
+
+	jsonhttp.Created(w, newDisputeResponse(dispute))
+}
+
+func (s *Service) disputesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_disputes").Build()
+
+	disputes, err := s.swap.Disputes()
+	if err != nil {
+		logger.Debug("get disputes failed", "error", err)
+		logger.Error(nil, "get disputes failed")
+		jsonhttp.InternalServerError(w, errCantDisputes)
+		return
+	}
+
+	response := disputesResponse{Disputes: make([]disputeResponse, 0, len(disputes))}
+	for i := range disputes {
+		response.Disputes = append(response.Disputes, newDisputeResponse(&disputes[i]))
+	}
+	jsonhttp.OK(w, response)
+}
+
+func (s *Service) disputeHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_dispute").Build()
+
+	paths := struct {
+		ID uint64 `map:"id" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	dispute, err := s.swap.Dispute(paths.ID)
+	if errors.Is(err, swap.ErrDisputeNotFound) {
+		jsonhttp.NotFound(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get dispute failed", "id", paths.ID, "error", err)
+		logger.Error(nil, "get dispute failed", "id", paths.ID)
+		jsonhttp.InternalServerError(w, errCantDisputes)
+		return
+	}
+
+	jsonhttp.OK(w, newDisputeResponse(dispute))
+}
+
+// resolveDisputeHandler resolves an open dispute by resending our last
+// cheque, adjusting it to the payout confirmed by the peer or dismissing it.
+func (s *Service) resolveDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_settlements_dispute_resolve").Build()
+
+	paths := struct {
+		ID uint64 `map:"id" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	var data resolveDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	dispute, err := s.swap.ResolveDispute(r.Context(), paths.ID, data.Resolution, data.Note)
+	if err != nil {
+		logger.Debug("resolve dispute failed", "id", paths.ID, "resolution", data.Resolution, "error", err)
+		switch {
+		case errors.Is(err, swap.ErrDisputeNotFound):
+			jsonhttp.NotFound(w, err)
+		case errors.Is(err, swap.ErrDisputeResolved), errors.Is(err, swap.ErrChequeNotSigned):
+			jsonhttp.Conflict(w, err)
+		case errors.Is(err, swap.ErrInvalidResolution):
+			jsonhttp.BadRequest(w, err)
+		case errors.Is(err, chequebook.ErrReceiptInvalid):
+			jsonhttp.BadGateway(w, errInvalidDisputePeer)
+		default:
+			logger.Error(nil, "resolve dispute failed", "id", paths.ID)
+			jsonhttp.InternalServerError(w, errCantResolveDispute)
+		}
+		return
+	}
+
+	jsonhttp.OK(w, newDisputeResponse(dispute))
+}
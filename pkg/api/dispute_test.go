@@ -0,0 +1,180 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlementDisputes(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("a1")
+	chequebookAddress := common.HexToAddress("0xcb")
+	beneficiary := common.HexToAddress("0xbe")
+	opened := time.Unix(1000, 0).UTC()
+
+	dispute := swap.Dispute{
+		ID:          1,
+		Peer:        peer,
+		Direction:   swap.DisputeSent,
+		Status:      swap.DisputeOpen,
+		LocalPayout: big.NewInt(500),
+		PeerPayout:  big.NewInt(300),
+		Cheque: &chequebook.SignedCheque{Cheque: chequebook.Cheque{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(500),
+		}},
+		Receipt: &chequebook.Receipt{
+			Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(300),
+			},
+			Signature: []byte{1, 2},
+		},
+		Trail: []swap.DisputeEntry{{Time: opened, Action: "opened", Note: "cheque lost"}},
+	}
+	expected := api.DisputeResponse{
+		ID:          1,
+		Peer:        peer,
+		Direction:   swap.DisputeSent,
+		Status:      swap.DisputeOpen,
+		LocalPayout: bigint.Wrap(big.NewInt(500)),
+		PeerPayout:  bigint.Wrap(big.NewInt(300)),
+		Cheque: &api.ChequebookLastChequePeerResponse{
+			Beneficiary: beneficiary.String(),
+			Chequebook:  chequebookAddress.String(),
+			Payout:      bigint.Wrap(big.NewInt(500)),
+		},
+		Receipt: &api.DisputeReceiptResponse{
+			Chequebook:       chequebookAddress.String(),
+			Beneficiary:      beneficiary.String(),
+			CumulativePayout: bigint.Wrap(big.NewInt(300)),
+			Signature:        "0x0102",
+		},
+		Trail: []api.DisputeEntryResponse{{Time: opened, Action: "opened", Note: "cheque lost"}},
+	}
+
+	var openedWith string
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithOpenDisputeFunc(func(_ context.Context, p swarm.Address, direction swap.DisputeDirection, note string) (*swap.Dispute, error) {
+				if direction != swap.DisputeSent && direction != swap.DisputeReceived {
+					return nil, swap.ErrInvalidDisputeDirection
+				}
+				openedWith = note
+				d := dispute
+				return &d, nil
+			}),
+			swapmock.WithDisputesFunc(func() ([]swap.Dispute, error) {
+				return []swap.Dispute{dispute}, nil
+			}),
+			swapmock.WithDisputeFunc(func(id uint64) (*swap.Dispute, error) {
+				if id != 1 {
+					return nil, swap.ErrDisputeNotFound
+				}
+				d := dispute
+				return &d, nil
+			}),
+			swapmock.WithResolveDisputeFunc(func(_ context.Context, id uint64, resolution swap.DisputeResolution, note string) (*swap.Dispute, error) {
+				if id != 1 {
+					return nil, swap.ErrDisputeNotFound
+				}
+				if resolution != swap.ResolutionDismiss {
+					return nil, swap.ErrInvalidResolution
+				}
+				d := dispute
+				d.Status = swap.DisputeResolved
+				d.Resolution = resolution
+				return &d, nil
+			}),
+		},
+	})
+
+	t.Run("open", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/disputes", http.StatusCreated,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"peer":      peer.String(),
+				"direction": "sent",
+				"note":      "cheque lost",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(expected),
+		)
+		if openedWith != "cheque lost" {
+			t.Fatalf("got note %q, want %q", openedWith, "cheque lost")
+		}
+	})
+
+	t.Run("open invalid direction", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/disputes", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"peer":      peer.String(),
+				"direction": "sideways",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: swap.ErrInvalidDisputeDirection.Error(),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/disputes", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.DisputesResponse{
+				Disputes: []api.DisputeResponse{expected},
+			}),
+		)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/disputes/1", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(expected),
+		)
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/disputes/2", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: swap.ErrDisputeNotFound.Error(),
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		resolved := expected
+		resolved.Status = swap.DisputeResolved
+		resolved.Resolution = swap.ResolutionDismiss
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/disputes/1/resolve", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"resolution": "dismiss",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(resolved),
+		)
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/disputes/1/resolve", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"resolution": "adjust",
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: swap.ErrInvalidResolution.Error(),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errEarningsUnavailable = "earnings analytics unavailable"
+	errCantEarnings        = "cannot get earnings analytics"
+)
+
+type chequebookEarningsPeer struct {
+	Peer      swarm.Address  `json:"peer"`
+	Earned    *bigint.BigInt `json:"earned"`
+	Cashed    *bigint.BigInt `json:"cashed"`
+	Deducted  *bigint.BigInt `json:"deducted"`
+	Uncashed  *bigint.BigInt `json:"uncashed,omitempty"`
+	Reserved  *bigint.BigInt `json:"reserved"`
+	Gas       *bigint.BigInt `json:"gas"`
+	Effective *bigint.BigInt `json:"effective"`
+}
+
+type chequebookEarningsDay struct {
+	Day    time.Time      `json:"day"`
+	Earned *bigint.BigInt `json:"earned"`
+	Cashed *bigint.BigInt `json:"cashed"`
+	Gas    *bigint.BigInt `json:"gas"`
+}
+
+type chequebookEarningsResponse struct {
+	Window    int                      `json:"window"` // seconds
+	Earned    *bigint.BigInt           `json:"earned"`
+	Cashed    *bigint.BigInt           `json:"cashed"`
+	Deducted  *bigint.BigInt           `json:"deducted"`
+	Uncashed  *bigint.BigInt           `json:"uncashed"`
+	Reserved  *bigint.BigInt           `json:"reserved"`
+	Gas       *bigint.BigInt           `json:"gas"`
+	Effective *bigint.BigInt           `json:"effective"`
+	Peers     []chequebookEarningsPeer `json:"peers"`
+	Daily     []chequebookEarningsDay  `json:"daily"`
+}
+
+// chequebookEarningsHandler returns the tokens received in cheques within the
+// analytics window per peer, how much of it was cashed out and the gas spent
+// on the cashouts. The effective earnings are net of the payouts to cashout
+// callers, including those expected for the uncashed cheques.
+func (s *Service) chequebookEarningsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_earnings").Build()
+
+	if s.earnings == nil {
+		jsonhttp.MethodNotAllowed(w, errEarningsUnavailable)
+		return
+	}
+
+	queries := struct {
+		Top int `map:"top" validate:"min=0"`
+	}{
+		Top: 10, // Default number of peers.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	report, err := s.earnings.Report(r.Context(), queries.Top)
+	if err != nil {
+		logger.Debug("get earnings analytics failed", "error", err)
+		logger.Error(nil, "get earnings analytics failed")
+		jsonhttp.InternalServerError(w, errCantEarnings)
+		return
+	}
+
+	response := chequebookEarningsResponse{
+		Window:    int(report.Window.Seconds()),
+		Earned:    bigint.Wrap(report.Earned),
+		Cashed:    bigint.Wrap(report.Cashed),
+		Deducted:  bigint.Wrap(report.Deducted),
+		Uncashed:  bigint.Wrap(report.Uncashed),
+		Reserved:  bigint.Wrap(report.Reserved),
+		Gas:       bigint.Wrap(report.Gas),
+		Effective: bigint.Wrap(report.Effective),
+		Peers:     make([]chequebookEarningsPeer, 0, len(report.Peers)),
+		Daily:     make([]chequebookEarningsDay, 0, len(report.Daily)),
+	}
+	for _, p := range report.Peers {
+		peer := chequebookEarningsPeer{
+			Peer:      p.Peer,
+			Earned:    bigint.Wrap(p.Earned),
+			Cashed:    bigint.Wrap(p.Cashed),
+			Deducted:  bigint.Wrap(p.Deducted),
+			Reserved:  bigint.Wrap(p.Reserved),
+			Gas:       bigint.Wrap(p.Gas),
+			Effective: bigint.Wrap(p.Effective),
+		}
+		if p.Uncashed != nil {
+			peer.Uncashed = bigint.Wrap(p.Uncashed)
+		}
+		response.Peers = append(response.Peers, peer)
+	}
+	for _, d := range report.Daily {
+		response.Daily = append(response.Daily, chequebookEarningsDay{
+			Day:    d.Day,
+			Earned: bigint.Wrap(d.Earned),
+			Cashed: bigint.Wrap(d.Cashed),
+			Gas:    bigint.Wrap(d.Gas),
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChequebookEarnings(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("aaaa")
+	txHash := common.HexToHash("eeee")
+	earnings, err := analytics.NewEarnings(log.Noop, statestore.NewStateStore(), analytics.DefaultWindow,
+		func(context.Context, swarm.Address) (*chequebook.CashoutStatus, error) {
+			return &chequebook.CashoutStatus{
+				Last: &chequebook.LastCashout{
+					TxHash: txHash,
+					Result: &chequebook.CashChequeResult{TotalPayout: big.NewInt(80)},
+				},
+				UncashedAmount: big.NewInt(20),
+			}, nil
+		},
+		func(context.Context, common.Hash) (*big.Int, error) {
+			return big.NewInt(5), nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	earnings.HandleEvent(events.Event{Type: events.TypeChequeReceived, Peer: peer, Time: time.Now(), Payout: big.NewInt(100)})
+	earnings.HandleEvent(events.Event{Type: events.TypeCashout, Peer: peer, Time: time.Now(), TxHash: txHash})
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		Earnings: earnings,
+	})
+
+	var response api.ChequebookEarningsResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/earnings", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&response),
+	)
+
+	if response.Earned.Int64() != 100 || response.Cashed.Int64() != 80 || response.Uncashed.Int64() != 20 ||
+		response.Gas.Int64() != 5 || response.Effective.Int64() != 95 || response.Reserved.Int64() != 0 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	if len(response.Peers) != 1 || !response.Peers[0].Peer.Equal(peer) || response.Peers[0].Effective.Int64() != 95 {
+		t.Fatalf("unexpected peers %+v", response.Peers)
+	}
+	if len(response.Daily) != 7 || response.Daily[6].Earned.Int64() != 100 {
+		t.Fatalf("unexpected daily earnings %+v", response.Daily)
+	}
+}
+
+func TestChequebookEarningsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/earnings", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "earnings analytics unavailable",
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
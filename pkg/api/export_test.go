@@ -39,6 +39,12 @@ var (
 	ErrInvalidNameOrAddress             = errInvalidNameOrAddress
 	ErrUnsupportedDevNodeOperation      = errUnsupportedDevNodeOperation
 	ErrOperationSupportedOnlyInFullMode = errOperationSupportedOnlyInFullMode
+	ErrSnapshotUnavailable              = errSnapshotUnavailable
+	ErrNoImportPeers                    = errNoImportPeers
+	ErrCantImport                       = errCantImport
+	ErrNoPeerStatement                  = errNoPeerStatement
+	ErrInvalidPeerStatement             = errInvalidPeerStatement
+	ErrCashoutSchedulingDisabled        = errCashoutSchedulingDisabled
 )
 
 var (
@@ -63,62 +69,136 @@ func CalculateNumberOfChunks(contentLength int64, isEncrypted bool) int64 {
 }
 
 type (
-	HealthStatusResponse              = healthStatusResponse
-	NodeResponse                      = nodeResponse
-	PingpongResponse                  = pingpongResponse
-	PeerConnectResponse               = peerConnectResponse
-	PeersResponse                     = peersResponse
-	BlockedListedPeersResponse        = blockListedPeersResponse
-	AddressesResponse                 = addressesResponse
-	WelcomeMessageRequest             = welcomeMessageRequest
-	WelcomeMessageResponse            = welcomeMessageResponse
-	BalancesResponse                  = balancesResponse
-	PeerDataResponse                  = peerDataResponse
-	PeerData                          = peerData
-	BalanceResponse                   = balanceResponse
-	SettlementResponse                = settlementResponse
-	SettlementsResponse               = settlementsResponse
-	ChequebookBalanceResponse         = chequebookBalanceResponse
-	ChequebookAddressResponse         = chequebookAddressResponse
-	ChequebookLastChequePeerResponse  = chequebookLastChequePeerResponse
-	ChequebookLastChequesResponse     = chequebookLastChequesResponse
-	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
-	ChequebookTxResponse              = chequebookTxResponse
-	SwapCashoutResponse               = swapCashoutResponse
-	SwapCashoutStatusResponse         = swapCashoutStatusResponse
-	SwapCashoutStatusResult           = swapCashoutStatusResult
-	TransactionInfo                   = transactionInfo
-	TransactionPendingList            = transactionPendingList
-	TransactionHashResponse           = transactionHashResponse
-	TagResponse                       = tagResponse
-	ReserveStateResponse              = reserveStateResponse
-	ChainStateResponse                = chainStateResponse
-	PostageCreateResponse             = postageCreateResponse
-	PostageStampResponse              = postageStampResponse
-	PostageStampsResponse             = postageStampsResponse
-	PostageBatchResponse              = postageBatchResponse
-	PostageStampBucketsResponse       = postageStampBucketsResponse
-	BucketData                        = bucketData
-	WalletResponse                    = walletResponse
-	GetStakeResponse                  = getStakeResponse
-	WithdrawAllStakeResponse          = withdrawAllStakeResponse
-	StatusSnapshotResponse            = statusSnapshotResponse
-	StatusResponse                    = statusResponse
+	HealthStatusResponse               = healthStatusResponse
+	NodeResponse                       = nodeResponse
+	PingpongResponse                   = pingpongResponse
+	PeerConnectResponse                = peerConnectResponse
+	PeersResponse                      = peersResponse
+	BlockedListedPeersResponse         = blockListedPeersResponse
+	AddressesResponse                  = addressesResponse
+	WelcomeMessageRequest              = welcomeMessageRequest
+	WelcomeMessageResponse             = welcomeMessageResponse
+	BalancesResponse                   = balancesResponse
+	PeerDataResponse                   = peerDataResponse
+	PeerData                           = peerData
+	BalanceResponse                    = balanceResponse
+	SettlementResponse                 = settlementResponse
+	SettlementsResponse                = settlementsResponse
+	SettlementSimulationResponse       = settlementSimulationResponse
+	SettlementSimulationPeerResponse   = settlementSimulationPeerResponse
+	SettlementsSummaryResponse         = settlementsSummaryResponse
+	SettlementsSummaryPeerResponse     = settlementsSummaryPeerResponse
+	SettlementStatementResponse        = settlementStatementResponse
+	SettlementStatementCheckResponse   = settlementStatementCheckResponse
+	DisputeResponse                    = disputeResponse
+	DisputesResponse                   = disputesResponse
+	DisputeEntryResponse               = disputeEntryResponse
+	DisputeReceiptResponse             = disputeReceiptResponse
+	ChequebookBalanceResponse          = chequebookBalanceResponse
+	ChequebookAddressResponse          = chequebookAddressResponse
+	ChequebookContractResponse         = chequebookContractResponse
+	ChequebookContractFeatures         = chequebookContractFeaturesResponse
+	ChequebookTokenResponse            = chequebookTokenResponse
+	ChequebookDepositHistoryResponse   = chequebookDepositHistoryResponse
+	ChequebookDepositResponse          = chequebookDepositResponse
+	ChequebookSplitDepositResponse     = chequebookSplitDepositResponse
+	ChequebookSelfTestResponse         = chequebookSelfTestResponse
+	ChequebookSelfTestStepResponse     = chequebookSelfTestStepResponse
+	ChequeCashoutResponse              = chequeCashoutResponse
+	ChequeCashoutsResponse             = chequeCashoutsResponse
+	CashoutAttemptResponse             = cashoutAttemptResponse
+	CashoutAttemptsResponse            = cashoutAttemptsResponse
+	ChequeAssignmentRequest            = chequeAssignmentRequest
+	ChequeAssignmentResponse           = chequeAssignmentResponse
+	ChequeAssignmentStatusResponse     = chequeAssignmentStatusResponse
+	ChequePreviewResponse              = chequePreviewResponse
+	ChequePolicyResponse               = chequePolicyResponse
+	CashoutTransactionChequesResponse  = cashoutTransactionChequesResponse
+	ScheduledCashoutResponse           = scheduledCashoutResponse
+	ScheduledCashoutsResponse          = scheduledCashoutsResponse
+	CashoutReconciliationResponse      = cashoutReconciliationResponse
+	ChequebookFactoriesResponse        = chequebookFactoriesResponse
+	ChequebookFactoriesRequest         = chequebookFactoriesRequest
+	ChequeSignerPassphraseRequest      = chequeSignerPassphraseRequest
+	AuditLogResponse                   = auditLogResponse
+	AuditLogEntryResponse              = auditLogEntryResponse
+	AuditLogVerifyResponse             = auditLogVerifyResponse
+	RuntimeConfigResponse              = runtimeConfigResponse
+	RuntimeConfigSettingResponse       = runtimeConfigSettingResponse
+	RuntimeConfigUpdateRequest         = runtimeConfigUpdateRequest
+	RuntimeConfigUpdateResponse        = runtimeConfigUpdateResponse
+	RuntimeConfigChangeResponse        = runtimeConfigChangeResponse
+	ChequebookLastChequePeerResponse   = chequebookLastChequePeerResponse
+	ChequebookLastChequesResponse      = chequebookLastChequesResponse
+	ChequebookLastChequesPeerResponse  = chequebookLastChequesPeerResponse
+	ChequebookLastChequesCountResponse = chequebookLastChequesCountResponse
+	ChequebookTxResponse               = chequebookTxResponse
+	ReceivedChequebookResponse         = receivedChequebookResponse
+	ReceivedChequebooksResponse        = receivedChequebooksResponse
+	BeneficiariesResponse              = beneficiariesResponse
+	StateStoreUsageResponse            = stateStoreUsageResponse
+	SettlementKeysResponse             = settlementKeysResponse
+	SettlementKeyResponse              = settlementKeyResponse
+	TotalIssuedCheckResponse           = totalIssuedCheckResponse
+	ChequebookSpendResponse            = chequebookSpendResponse
+	ChequebookSpendBeneficiary         = chequebookSpendBeneficiary
+	ChequebookSpendPurposesResponse    = chequebookSpendPurposesResponse
+	ChequebookSpendPurpose             = chequebookSpendPurpose
+	ChequebookEarningsResponse         = chequebookEarningsResponse
+	ChequebookEarningsPeer             = chequebookEarningsPeer
+	ChequebookEarningsDay              = chequebookEarningsDay
+	LedgerResponse                     = ledgerResponse
+	LedgerEntryResponse                = ledgerEntryResponse
+	ChequeHistoryResponse              = chequeHistoryResponse
+	IssuedChequeResponse               = issuedChequeResponse
+	PreviousBeneficiaryResponse        = previousBeneficiaryResponse
+	BeneficiaryAnnouncementResponse    = beneficiaryAnnouncementResponse
+	RotateBeneficiaryRequest           = rotateBeneficiaryRequest
+	SwapCashoutResponse                = swapCashoutResponse
+	SwapCashoutBatchRequest            = swapCashoutBatchRequest
+	SwapCashoutBatchResponse           = swapCashoutBatchResponse
+	SwapCashoutBatchResult             = swapCashoutBatchResult
+	SwapCashoutStatusResponse          = swapCashoutStatusResponse
+	SwapCashoutStatusResult            = swapCashoutStatusResult
+	TransactionInfo                    = transactionInfo
+	TransactionPendingList             = transactionPendingList
+	TransactionHashResponse            = transactionHashResponse
+	TagResponse                        = tagResponse
+	ReserveStateResponse               = reserveStateResponse
+	ChainStateResponse                 = chainStateResponse
+	PostageCreateResponse              = postageCreateResponse
+	PostageStampResponse               = postageStampResponse
+	PostageStampsResponse              = postageStampsResponse
+	PostageBatchResponse               = postageBatchResponse
+	PostageStampBucketsResponse        = postageStampBucketsResponse
+	BucketData                         = bucketData
+	WalletResponse                     = walletResponse
+	GetStakeResponse                   = getStakeResponse
+	WithdrawAllStakeResponse           = withdrawAllStakeResponse
+	StatusSnapshotResponse             = statusSnapshotResponse
+	StatusResponse                     = statusResponse
 )
 
 var (
-	ErrCantBalance           = errCantBalance
-	ErrCantBalances          = errCantBalances
-	HttpErrGetAccountingInfo = httpErrGetAccountingInfo
-	ErrNoBalance             = errNoBalance
-	ErrCantSettlementsPeer   = errCantSettlementsPeer
-	ErrCantSettlements       = errCantSettlements
-	ErrChequebookBalance     = errChequebookBalance
-	ErrInvalidAddress        = errInvalidAddress
-	ErrUnknownTransaction    = errUnknownTransaction
-	ErrCantGetTransaction    = errCantGetTransaction
-	ErrCantResendTransaction = errCantResendTransaction
-	ErrAlreadyImported       = errAlreadyImported
+	ErrCantBalance                 = errCantBalance
+	ErrCantBalances                = errCantBalances
+	HttpErrGetAccountingInfo       = httpErrGetAccountingInfo
+	ErrNoBalance                   = errNoBalance
+	ErrCantSettlementsPeer         = errCantSettlementsPeer
+	ErrCantSettlements             = errCantSettlements
+	ErrCantSettlementsSummary      = errCantSettlementsSummary
+	ErrSettlementEventsUnavailable = errSettlementEventsUnavailable
+	ErrChequebookBalance           = errChequebookBalance
+	ErrChequebookToken             = errChequebookToken
+	ErrChequebookDepositHistory    = errChequebookDepositHistory
+	ErrChequebookSetFactories      = errChequebookSetFactories
+	ErrNoCashoutPeers              = errNoCashoutPeers
+	ErrNoCheque                    = errNoCheque
+	ErrInvalidAddress              = errInvalidAddress
+	ErrUnknownTransaction          = errUnknownTransaction
+	ErrCantGetTransaction          = errCantGetTransaction
+	ErrCantResendTransaction       = errCantResendTransaction
+	ErrAlreadyImported             = errAlreadyImported
 )
 
 type (
@@ -0,0 +1,209 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errLedgerUnavailable = "settlement ledger unavailable"
+	errCantLedger        = "can not get settlement ledger"
+)
+
+type ledgerEntryResponse struct {
+	Index           uint64            `json:"index"`
+	Time            time.Time         `json:"time"`
+	Event           events.Type       `json:"event"`
+	Debit           analytics.Account `json:"debit"`
+	Credit          analytics.Account `json:"credit"`
+	Amount          *bigint.BigInt    `json:"amount"`
+	Counterparty    swarm.Address     `json:"counterparty"`
+	Chequebook      common.Address    `json:"chequebook"`
+	TransactionHash common.Hash       `json:"transactionHash"`
+	ExchangeRate    *bigint.BigInt    `json:"exchangeRate,omitempty"`
+	Deduction       *bigint.BigInt    `json:"deduction,omitempty"`
+}
+
+type ledgerResponse struct {
+	Total   uint64                `json:"total"`
+	Entries []ledgerEntryResponse `json:"entries"`
+}
+
+// ledgerCSVHeader are the columns of the csv export of the ledger.
+var ledgerCSVHeader = []string{"index", "time", "event", "debit", "credit", "amount", "counterparty", "chequebook", "transactionHash", "exchangeRate", "deduction"}
+
+func newLedgerEntryResponse(entry analytics.LedgerEntry) ledgerEntryResponse {
+	response := ledgerEntryResponse{
+		Index:           entry.Index,
+		Time:            entry.Time,
+		Event:           entry.Event,
+		Debit:           entry.Debit,
+		Credit:          entry.Credit,
+		Amount:          bigint.Wrap(entry.Amount),
+		Counterparty:    entry.Counterparty,
+		Chequebook:      entry.Chequebook,
+		TransactionHash: entry.TxHash,
+	}
+	if entry.ExchangeRate != nil {
+		response.ExchangeRate = bigint.Wrap(entry.ExchangeRate)
+	}
+	if entry.Deduction != nil {
+		response.Deduction = bigint.Wrap(entry.Deduction)
+	}
+	return response
+}
+
+func ledgerCSVRecord(entry analytics.LedgerEntry) []string {
+	var exchangeRate, deduction string
+	if entry.ExchangeRate != nil {
+		exchangeRate = entry.ExchangeRate.String()
+	}
+	if entry.Deduction != nil {
+		deduction = entry.Deduction.String()
+	}
+	var counterparty string
+	if !entry.Counterparty.IsZero() {
+		counterparty = entry.Counterparty.String()
+	}
+	var txHash string
+	if entry.TxHash != (common.Hash{}) {
+		txHash = entry.TxHash.Hex()
+	}
+	return []string{
+		strconv.FormatUint(entry.Index, 10),
+		entry.Time.UTC().Format(time.RFC3339),
+		string(entry.Event),
+		string(entry.Debit),
+		string(entry.Credit),
+		entry.Amount.String(),
+		counterparty,
+		entry.Chequebook.Hex(),
+		txHash,
+		exchangeRate,
+		deduction,
+	}
+}
+
+func (s *Service) ledgerHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_ledger").Build()
+
+	if s.ledger == nil {
+		jsonhttp.MethodNotAllowed(w, errLedgerUnavailable)
+		return
+	}
+
+	queries := struct {
+		Offset uint64 `map:"offset"`
+		Limit  uint64 `map:"limit"`
+	}{
+		Limit: 100, // Default limit.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	entries, err := s.ledger.Entries(r.Context(), queries.Offset, queries.Limit)
+	if err != nil {
+		logger.Debug("get ledger failed", "offset", queries.Offset, "limit", queries.Limit, "error", err)
+		logger.Error(nil, "get ledger failed")
+		jsonhttp.InternalServerError(w, errCantLedger)
+		return
+	}
+
+	response := ledgerResponse{
+		Total:   s.ledger.Len(),
+		Entries: make([]ledgerEntryResponse, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, newLedgerEntryResponse(entry))
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// ledgerExportHandler streams the ledger entries of the events within the
+// requested period as csv or as newline delimited json.
+func (s *Service) ledgerExportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_ledger_export").Build()
+
+	if s.ledger == nil {
+		jsonhttp.MethodNotAllowed(w, errLedgerUnavailable)
+		return
+	}
+
+	queries := struct {
+		Format string `map:"format" validate:"omitempty,oneof=csv ndjson"`
+		From   int64  `map:"from" validate:"min=0"`
+		To     int64  `map:"to" validate:"min=0"`
+	}{
+		Format: "csv",
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+	inPeriod := func(entry analytics.LedgerEntry) bool {
+		if entry.Time.Unix() < queries.From {
+			return false
+		}
+		return queries.To == 0 || entry.Time.Unix() < queries.To
+	}
+
+	var write func(entry analytics.LedgerEntry) error
+	flush := func() error { return nil }
+	switch queries.Format {
+	case "ndjson":
+		w.Header().Set(ContentTypeHeader, "application/x-ndjson")
+		w.Header().Set(ContentDispositionHeader, `attachment; filename="settlement-ledger.ndjson"`)
+		encoder := json.NewEncoder(w)
+		write = func(entry analytics.LedgerEntry) error {
+			return encoder.Encode(newLedgerEntryResponse(entry))
+		}
+	default:
+		w.Header().Set(ContentTypeHeader, "text/csv")
+		w.Header().Set(ContentDispositionHeader, `attachment; filename="settlement-ledger.csv"`)
+		writer := csv.NewWriter(w)
+		write = func(entry analytics.LedgerEntry) error {
+			return writer.Write(ledgerCSVRecord(entry))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		if err := writer.Write(ledgerCSVHeader); err != nil {
+			logger.Debug("export ledger failed", "error", err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	err := s.ledger.Iterate(r.Context(), 0, func(entry analytics.LedgerEntry) (bool, error) {
+		if !inPeriod(entry) {
+			return false, nil
+		}
+		return false, write(entry)
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// the status was already sent, the export ends prematurely
+		logger.Debug("export ledger failed", "error", err)
+		logger.Error(nil, "export ledger failed")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlementLedger(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("aaaa")
+	ledger, err := analytics.NewLedger(log.Noop, statestore.NewStateStore(),
+		func(context.Context, swarm.Address) (*chequebook.CashoutStatus, error) {
+			return &chequebook.CashoutStatus{}, nil
+		},
+		func() (*big.Int, *big.Int, error) {
+			return big.NewInt(24000), big.NewInt(0), nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger.HandleEvent(events.Event{Type: events.TypeChequeReceived, Peer: peer, Time: time.Unix(1000, 0), Payout: big.NewInt(100)})
+	ledger.HandleEvent(events.Event{Type: events.TypeChequeReceived, Peer: peer, Time: time.Unix(2000, 0), Payout: big.NewInt(50)})
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		Ledger:   ledger,
+	})
+
+	var response api.LedgerResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/ledger?offset=1", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&response),
+	)
+	if response.Total != 2 || len(response.Entries) != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	entry := response.Entries[0]
+	if entry.Index != 1 || entry.Debit != analytics.AccountReceivable || entry.Credit != analytics.AccountIncome ||
+		entry.Amount.Int64() != 50 || entry.ExchangeRate.Int64() != 24000 || !entry.Counterparty.Equal(peer) {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	var body []byte
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/ledger/export?from=1500", http.StatusOK,
+		jsonhttptest.WithPutResponseBody(&body),
+		jsonhttptest.WithExpectedResponseHeader(api.ContentTypeHeader, "text/csv"),
+	)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "index,time,event") ||
+		!strings.HasPrefix(lines[1], "1,1970-01-01T00:33:20Z,cheque_received,cheques_receivable,income,50,"+peer.String()) {
+		t.Fatalf("unexpected csv export %q", body)
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/ledger/export?format=xml", http.StatusBadRequest)
+}
+
+func TestSettlementLedgerUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/ledger", http.StatusMethodNotAllowed)
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/ledger/export", http.StatusMethodNotAllowed)
+}
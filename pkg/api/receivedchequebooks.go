@@ -0,0 +1,149 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const errCantReceivedChequebooks = "cannot get received chequebooks"
+
+type receivedChequebookResponse struct {
+	Chequebook   common.Address                    `json:"chequebook"`
+	Current      bool                              `json:"current"`
+	LastReceived *chequebookLastChequePeerResponse `json:"lastreceived"`
+	Uncashed     *bigint.BigInt                    `json:"uncashed"`
+}
+
+type receivedChequebooksResponse struct {
+	Peer          swarm.Address                `json:"peer"`
+	TotalUncashed *bigint.BigInt               `json:"totalUncashed"`
+	Chequebooks   []receivedChequebookResponse `json:"chequebooks"`
+}
+
+// receivedChequebooksHandler lists every chequebook the peer sent cheques
+// from with the amount it still owes us.
+func (s *Service) receivedChequebooksHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cheque_chequebooks").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	received, err := s.swap.ReceivedChequebooks(r.Context(), paths.Peer)
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("get received chequebooks failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "get received chequebooks failed", "peer_address", paths.Peer)
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if errors.Is(err, chequebook.ErrNoCheque) {
+		logger.Debug("get received chequebooks failed", "peer_address", paths.Peer, "error", err)
+		jsonhttp.NotFound(w, errNoCheque)
+		return
+	}
+	if err != nil {
+		logger.Debug("get received chequebooks failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "get received chequebooks failed", "peer_address", paths.Peer)
+		jsonhttp.InternalServerError(w, errCantReceivedChequebooks)
+		return
+	}
+
+	response := receivedChequebooksResponse{
+		Peer:          paths.Peer,
+		TotalUncashed: bigint.Wrap(big.NewInt(0)),
+		Chequebooks:   make([]receivedChequebookResponse, 0, len(received)),
+	}
+	for _, c := range received {
+		response.TotalUncashed.Add(response.TotalUncashed.Int, c.Uncashed)
+		response.Chequebooks = append(response.Chequebooks, receivedChequebookResponse{
+			Chequebook: c.Chequebook,
+			Current:    c.Current,
+			LastReceived: &chequebookLastChequePeerResponse{
+				Beneficiary: c.LastCheque.Cheque.Beneficiary.String(),
+				Chequebook:  c.LastCheque.Cheque.Chequebook.String(),
+				Payout:      bigint.Wrap(c.LastCheque.Cheque.CumulativePayout),
+			},
+			Uncashed: bigint.Wrap(c.Uncashed),
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// swapCashoutChequebooksHandler cashes out every chequebook of the peer which
+// still owes us, also the ones the peer no longer pays from.
+func (s *Service) swapCashoutChequebooksHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_cashout_chequebooks").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	if !s.cashOutChequeSem.TryAcquire(1) {
+		logger.Debug("simultaneous on-chain operations not supported")
+		logger.Error(nil, "simultaneous on-chain operations not supported")
+		jsonhttp.TooManyRequests(w, "simultaneous on-chain operations not supported")
+		return
+	}
+	defer s.cashOutChequeSem.Release(1)
+
+	results, err := s.swap.CashChequebooks(r.Context(), paths.Peer)
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("cash chequebooks failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "cash chequebooks failed", "peer_address", paths.Peer)
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if errors.Is(err, chequebook.ErrNoCheque) {
+		logger.Debug("cash chequebooks failed", "peer_address", paths.Peer, "error", err)
+		jsonhttp.NotFound(w, errNoCheque)
+		return
+	}
+	if err != nil {
+		logger.Debug("cash chequebooks failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "cash chequebooks failed", "peer_address", paths.Peer)
+		// the cashouts sent before the failure are reported with their results
+		if results == nil {
+			jsonhttp.InternalServerError(w, errCannotCash)
+			return
+		}
+	}
+
+	response := swapCashoutBatchResponse{Results: make([]swapCashoutBatchResult, 0, len(results))}
+	for _, result := range results {
+		item := swapCashoutBatchResult{
+			Peer:       paths.Peer,
+			Chequebook: result.Chequebook,
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		} else {
+			txHash := result.TxHash
+			item.TransactionHash = &txHash
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	jsonhttp.OK(w, response)
+}
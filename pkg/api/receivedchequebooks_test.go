@@ -0,0 +1,163 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestReceivedChequebooks(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	unknownPeer := swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")
+	beneficiary := common.HexToAddress("0xfff5")
+	currentChequebook := common.HexToAddress("0xfff6")
+	previousChequebook := common.HexToAddress("0xfff7")
+
+	receivedFunc := func(_ context.Context, p swarm.Address) ([]swap.ReceivedChequebook, error) {
+		if !p.Equal(peer) {
+			return nil, chequebook.ErrNoCheque
+		}
+		return []swap.ReceivedChequebook{
+			{
+				Chequebook: currentChequebook,
+				Current:    true,
+				LastCheque: &chequebook.SignedCheque{Cheque: chequebook.Cheque{
+					Beneficiary:      beneficiary,
+					Chequebook:       currentChequebook,
+					CumulativePayout: big.NewInt(30),
+				}},
+				Uncashed: big.NewInt(10),
+			},
+			{
+				Chequebook: previousChequebook,
+				LastCheque: &chequebook.SignedCheque{Cheque: chequebook.Cheque{
+					Beneficiary:      beneficiary,
+					Chequebook:       previousChequebook,
+					CumulativePayout: big.NewInt(50),
+				}},
+				Uncashed: big.NewInt(5),
+			},
+		}, nil
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{swapmock.WithReceivedChequebooksFunc(receivedFunc)},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque/"+peer.String()+"/chequebooks", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ReceivedChequebooksResponse{
+				Peer:          peer,
+				TotalUncashed: bigint.Wrap(big.NewInt(15)),
+				Chequebooks: []api.ReceivedChequebookResponse{
+					{
+						Chequebook: currentChequebook,
+						Current:    true,
+						LastReceived: &api.ChequebookLastChequePeerResponse{
+							Beneficiary: beneficiary.String(),
+							Chequebook:  currentChequebook.String(),
+							Payout:      bigint.Wrap(big.NewInt(30)),
+						},
+						Uncashed: bigint.Wrap(big.NewInt(10)),
+					},
+					{
+						Chequebook: previousChequebook,
+						LastReceived: &api.ChequebookLastChequePeerResponse{
+							Beneficiary: beneficiary.String(),
+							Chequebook:  previousChequebook.String(),
+							Payout:      bigint.Wrap(big.NewInt(50)),
+						},
+						Uncashed: bigint.Wrap(big.NewInt(5)),
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("no cheques", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cheque/"+unknownPeer.String()+"/chequebooks", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: api.ErrNoCheque,
+			}),
+		)
+	})
+}
+
+func TestCashoutChequebooks(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	currentChequebook := common.HexToAddress("0xfff6")
+	previousChequebook := common.HexToAddress("0xfff7")
+	txHash := common.HexToHash("0xffff")
+
+	cashFunc := func(_ context.Context, p swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+		if !p.Equal(peer) {
+			t.Fatalf("got peer %v, want %v", p, peer)
+		}
+		return []chequebook.BatchCashoutResult{
+			{Chequebook: currentChequebook, TxHash: txHash},
+			{Chequebook: previousChequebook, Err: chequebook.ErrNoCheque},
+		}, nil
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{swapmock.WithCashChequebooksFunc(cashFunc)},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+peer.String()+"/chequebooks", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.SwapCashoutBatchResponse{
+			Results: []api.SwapCashoutBatchResult{
+				{Peer: peer, Chequebook: currentChequebook, TransactionHash: &txHash},
+				{Peer: peer, Chequebook: previousChequebook, Error: chequebook.ErrNoCheque.Error()},
+			},
+		}),
+	)
+}
+
+func TestCashoutChequebooksChainDisabled(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+
+	cashFunc := func(context.Context, swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+		return nil, postagecontract.ErrChainDisabled
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{swapmock.WithCashChequebooksFunc(cashFunc)},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+peer.String()+"/chequebooks", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Code:    http.StatusMethodNotAllowed,
+			Message: postagecontract.ErrChainDisabled.Error(),
+		}),
+	)
+}
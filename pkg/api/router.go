@@ -372,6 +372,17 @@ func (s *Service) mountBusinessDebug(restricted bool) {
 		s.router.Handle(path, handler)
 		s.router.Handle(rootPath+path, handler)
 	}
+	handleSettlement := func(path string, handler http.Handler) {
+		handle(path, s.settlementHandler(handler))
+	}
+	// fundsScope guards the handlers moving chequebook funds. In addition to
+	// the policy of the path, the token must grant the funds scope.
+	fundsScope := func(handler http.Handler) http.Handler {
+		if restricted {
+			return auth.ScopeCheckHandler(s.auth, auth.FundsScope)(handler)
+		}
+		return handler
+	}
 
 	if s.transaction != nil {
 		handle("/transactions", jsonhttp.MethodHandler{
@@ -441,61 +452,274 @@ func (s *Service) mountBusinessDebug(restricted bool) {
 		"GET": http.HandlerFunc(s.peerBalanceHandler),
 	})
 
-	handle("/timesettlements", jsonhttp.MethodHandler{
+	handleSettlement("/timesettlements", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.settlementsHandlerPseudosettle),
 	})
 
 	if s.swapEnabled {
-		handle("/settlements", jsonhttp.MethodHandler{
+		handleSettlement("/settlements", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.settlementsHandler),
 		})
 
-		handle("/settlements/{peer}", jsonhttp.MethodHandler{
+		handleSettlement("/settlements/simulation", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementsSimulationHandler),
+		})
+
+		handleSettlement("/settlements/audit", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.auditLogHandler),
+		})
+
+		handleSettlement("/settlements/audit/export", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.auditLogExportHandler),
+		})
+
+		handleSettlement("/settlements/audit/verify", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.auditLogVerifyHandler),
+		})
+
+		handleSettlement("/settlements/ledger", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.ledgerHandler),
+		})
+
+		handleSettlement("/settlements/ledger/export", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.ledgerExportHandler),
+		})
+
+		handleSettlement("/settlements/config", jsonhttp.MethodHandler{
+			"GET":   http.HandlerFunc(s.runtimeConfigHandler),
+			"PATCH": fundsScope(http.HandlerFunc(s.updateRuntimeConfigHandler)),
+		})
+
+		handleSettlement("/settlements/snapshot", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementSnapshotHandler),
+		})
+
+		handleSettlement("/settlements/import", jsonhttp.MethodHandler{
+			"POST": fundsScope(http.HandlerFunc(s.settlementImportHandler)),
+		})
+
+		handleSettlement("/settlements/statement/{peer}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementStatementHandler),
+		})
+
+		handleSettlement("/settlements/disputes", jsonhttp.MethodHandler{
+			"GET":  http.HandlerFunc(s.disputesHandler),
+			"POST": fundsScope(http.HandlerFunc(s.openDisputeHandler)),
+		})
+
+		handleSettlement("/settlements/disputes/{id}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.disputeHandler),
+		})
+
+		handleSettlement("/settlements/disputes/{id}/resolve", jsonhttp.MethodHandler{
+			"POST": fundsScope(http.HandlerFunc(s.resolveDisputeHandler)),
+		})
+
+		handleSettlement("/settlements/events", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementEventsHandler),
+		})
+
+		handleSettlement("/settlements/usage", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.stateStoreUsageHandler),
+		})
+
+		handleSettlement("/settlements/keys", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementKeysHandler),
+		})
+
+		handleSettlement("/settlements/summary", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.settlementsSummaryHandler),
+		})
+
+		handleSettlement("/settlements/{peer}", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.peerSettlementsHandler),
 		})
 
-		handle("/chequebook/cheque/{peer}", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/cheque/{peer}", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.chequebookLastPeerHandler),
 		})
 
-		handle("/chequebook/cheque", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/cheque/{peer}/preview", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequePreviewHandler),
+		})
+
+		handleSettlement("/chequebook/cheque/{peer}/chequebooks", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.receivedChequebooksHandler),
+		})
+
+		handleSettlement("/chequebook/cheque", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.chequebookAllLastHandler),
 		})
 
-		handle("/chequebook/cashout/{peer}", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/verification", jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.chequeVerificationHandler),
+		})
+
+		handleSettlement("/chequebook/factories", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookFactoriesHandler),
+			"PUT": http.HandlerFunc(s.chequebookSetFactoriesHandler),
+		})
+
+		handleSettlement("/chequebook/history/{beneficiary}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequeHistoryHandler),
+		})
+
+		handleSettlement("/chequebook/contract/{address}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookContractAddressHandler),
+		})
+
+		handleSettlement("/chequebook/beneficiary", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.beneficiariesHandler),
+			"PUT": fundsScope(http.HandlerFunc(s.rotateBeneficiaryHandler)),
+		})
+
+		handleSettlement("/chequebook/signer/passphrase", jsonhttp.MethodHandler{
+			"PUT": http.HandlerFunc(s.chequeSignerPassphraseHandler),
+		})
+
+		handleSettlement("/chequebook/totalissued", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.totalIssuedCheckHandler),
+		})
+
+		handleSettlement("/chequebook/totalissued/reconcile", jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.totalIssuedReconcileHandler),
+		})
+
+		handleSettlement("/chequebook/totalissued/override", jsonhttp.MethodHandler{
+			"POST": fundsScope(http.HandlerFunc(s.totalIssuedOverrideHandler)),
+		})
+
+		handleSettlement("/chequebook/spend", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookSpendHandler),
+		})
+
+		handleSettlement("/chequebook/spend/purposes", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookSpendPurposesHandler),
+		})
+
+		handleSettlement("/chequebook/earnings", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookEarningsHandler),
+		})
+
+		handleSettlement("/chequebook/cashout", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				fundsScope,
+				s.gasConfigMiddleware("swap cashout batch"),
+				web.FinalHandlerFunc(s.swapCashoutBatchHandler),
+			),
+		})
+
+		handleSettlement("/chequebook/cashout/{peer}", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.swapCashoutStatusHandler),
 			"POST": web.ChainHandlers(
+				fundsScope,
 				s.gasConfigMiddleware("swap cashout"),
 				web.FinalHandlerFunc(s.swapCashoutHandler),
 			),
 		})
+
+		handleSettlement("/chequebook/cashout/{peer}/chequebooks", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				fundsScope,
+				s.gasConfigMiddleware("swap cashout chequebooks"),
+				web.FinalHandlerFunc(s.swapCashoutChequebooksHandler),
+			),
+		})
+
+		handleSettlement("/chequebook/cashout/{peer}/transactions", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequeCashoutsHandler),
+		})
+
+		handleSettlement("/chequebook/cashout/{peer}/attempts", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.cashoutAttemptsHandler),
+		})
+
+		handleSettlement("/chequebook/cashout/{peer}/retry", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				fundsScope,
+				s.gasConfigMiddleware("swap cashout retry"),
+				web.FinalHandlerFunc(s.retryCashoutHandler),
+			),
+		})
+
+		handleSettlement("/chequebook/assignment/{peer}", jsonhttp.MethodHandler{
+			"GET":    http.HandlerFunc(s.chequeAssignmentHandler),
+			"POST":   fundsScope(http.HandlerFunc(s.assignChequeHandler)),
+			"DELETE": fundsScope(http.HandlerFunc(s.removeChequeAssignmentHandler)),
+		})
+
+		handleSettlement("/chequebook/cashouts/scheduled", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.scheduledCashoutsHandler),
+		})
+
+		handleSettlement("/chequebook/cashouts/{hash}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.cashoutTransactionChequesHandler),
+		})
+
+		handleSettlement("/chequebook/reconciliation", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.cashoutReconciliationHandler),
+		})
 	}
 
 	if s.chequebookEnabled {
-		handle("/chequebook/balance", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/balance", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.chequebookBalanceHandler),
 		})
 
-		handle("/chequebook/address", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/address", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.chequebookAddressHandler),
 		})
 
-		handle("/chequebook/deposit", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/contract", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookContractHandler),
+		})
+
+		handleSettlement("/chequebook/token", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookTokenHandler),
+		})
+
+		handleSettlement("/chequebook/deposits", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookDepositHistoryHandler),
+		})
+
+		handleSettlement("/chequebook/deposit", jsonhttp.MethodHandler{
 			"POST": web.ChainHandlers(
+				fundsScope,
 				s.gasConfigMiddleware("chequebook deposit"),
 				web.FinalHandlerFunc(s.chequebookDepositHandler),
 			),
 		})
 
-		handle("/chequebook/withdraw", jsonhttp.MethodHandler{
+		handleSettlement("/chequebook/deposit/{hash}/progress", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookDepositProgressHandler),
+		})
+
+		handleSettlement("/chequebook/deposit/split", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				fundsScope,
+				s.gasConfigMiddleware("chequebook split deposit"),
+				web.FinalHandlerFunc(s.chequebookSplitDepositHandler),
+			),
+		})
+
+		handleSettlement("/chequebook/selftest", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				fundsScope,
+				s.gasConfigMiddleware("chequebook self-test"),
+				web.FinalHandlerFunc(s.chequebookSelfTestHandler),
+			),
+		})
+
+		handleSettlement("/chequebook/withdraw", jsonhttp.MethodHandler{
 			"POST": web.ChainHandlers(
+				fundsScope,
 				s.gasConfigMiddleware("chequebook withdraw"),
 				web.FinalHandlerFunc(s.chequebookWithdrawHandler),
 			),
 		})
 
 		if s.swapEnabled {
-			handle("/wallet", jsonhttp.MethodHandler{
+			handleSettlement("/wallet", jsonhttp.MethodHandler{
 				"GET": http.HandlerFunc(s.walletHandler),
 			})
 		}
@@ -0,0 +1,112 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+)
+
+const (
+	errRuntimeConfigUnavailable = "runtime config unavailable"
+	errCantUpdateRuntimeConfig  = "can not update runtime config"
+)
+
+type runtimeConfigSettingResponse struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+type runtimeConfigResponse struct {
+	Settings []runtimeConfigSettingResponse `json:"settings"`
+}
+
+type runtimeConfigChangeResponse struct {
+	Name     string `json:"name"`
+	Previous string `json:"previous"`
+	Value    string `json:"value"`
+}
+
+type runtimeConfigUpdateResponse struct {
+	Changes []runtimeConfigChangeResponse `json:"changes"`
+}
+
+type runtimeConfigUpdateRequest struct {
+	Settings map[string]string `json:"settings"`
+	Reason   string            `json:"reason"`
+}
+
+func (s *Service) runtimeConfigHandler(w http.ResponseWriter, _ *http.Request) {
+	if s.runtimeConfig == nil {
+		jsonhttp.MethodNotAllowed(w, errRuntimeConfigUnavailable)
+		return
+	}
+
+	values := s.runtimeConfig.Values()
+	response := runtimeConfigResponse{Settings: make([]runtimeConfigSettingResponse, 0, len(values))}
+	for _, v := range values {
+		response.Settings = append(response.Settings, runtimeConfigSettingResponse{
+			Name:        v.Name,
+			Value:       v.Value,
+			Description: v.Description,
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// updateRuntimeConfigHandler applies the settings of the request if all of
+// them are valid. The changes are recorded in the audit log together with the
+// remote address of the request and the given reason.
+func (s *Service) updateRuntimeConfigHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("patch_settlements_config").Build()
+
+	if s.runtimeConfig == nil {
+		jsonhttp.MethodNotAllowed(w, errRuntimeConfigUnavailable)
+		return
+	}
+
+	var data runtimeConfigUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	actor := r.RemoteAddr
+	if data.Reason != "" {
+		actor += " (" + data.Reason + ")"
+	}
+
+	changes, err := s.runtimeConfig.Update(data.Settings, actor)
+	if errors.Is(err, runtimeconfig.ErrUnknownSetting) || errors.Is(err, runtimeconfig.ErrInvalidValue) {
+		logger.Debug("update runtime config failed", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("update runtime config failed", "error", err)
+		logger.Error(nil, "update runtime config failed")
+		jsonhttp.InternalServerError(w, errCantUpdateRuntimeConfig)
+		return
+	}
+
+	response := runtimeConfigUpdateResponse{Changes: make([]runtimeConfigChangeResponse, 0, len(changes))}
+	for _, c := range changes {
+		logger.Info("settlement setting changed", "name", c.Name, "previous", c.Previous, "value", c.Value, "actor", actor)
+		response.Changes = append(response.Changes, runtimeConfigChangeResponse{
+			Name:     c.Name,
+			Previous: c.Previous,
+			Value:    c.Value,
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+)
+
+func TestRuntimeConfig(t *testing.T) {
+	t.Parallel()
+
+	pause := false
+	registry := runtimeconfig.New()
+	registry.Register("swap-balance-decline-pause", runtimeconfig.Bool("pause", func() bool { return pause }, func(v bool) { pause = v }))
+
+	auditLog := newTestAuditLog(t, 0)
+	registry.OnChange(func(change runtimeconfig.Change, actor string) {
+		if _, err := auditLog.Append(auditlog.Entry{Action: auditlog.ActionConfig, Note: change.Name + " by " + actor}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		RuntimeConfig: registry,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/config", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.RuntimeConfigResponse{
+			Settings: []api.RuntimeConfigSettingResponse{{Name: "swap-balance-decline-pause", Value: "false", Description: "pause"}},
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer, http.MethodPatch, "/settlements/config", http.StatusBadRequest,
+		jsonhttptest.WithJSONRequestBody(api.RuntimeConfigUpdateRequest{
+			Settings: map[string]string{"swap-balance-decline-pause": "maybe"},
+		}),
+	)
+	jsonhttptest.Request(t, testServer, http.MethodPatch, "/settlements/config", http.StatusBadRequest,
+		jsonhttptest.WithJSONRequestBody(api.RuntimeConfigUpdateRequest{
+			Settings: map[string]string{"unknown": "1"},
+		}),
+	)
+	if pause || auditLog.Len() != 0 {
+		t.Fatal("rejected update applied")
+	}
+
+	jsonhttptest.Request(t, testServer, http.MethodPatch, "/settlements/config", http.StatusOK,
+		jsonhttptest.WithJSONRequestBody(api.RuntimeConfigUpdateRequest{
+			Settings: map[string]string{"swap-balance-decline-pause": "true"},
+			Reason:   "incident",
+		}),
+		jsonhttptest.WithExpectedJSONResponse(api.RuntimeConfigUpdateResponse{
+			Changes: []api.RuntimeConfigChangeResponse{{Name: "swap-balance-decline-pause", Previous: "false", Value: "true"}},
+		}),
+	)
+	if !pause {
+		t.Fatal("update not applied")
+	}
+	if auditLog.Len() != 1 {
+		t.Fatalf("got %d audit log entries, want 1", auditLog.Len())
+	}
+}
+
+func TestRuntimeConfigUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/config", http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+)
+
+const errSettlementEventsUnavailable = "settlement events unavailable"
+
+// settlementEventsKeepAlive is the interval in which a comment is sent on an
+// idle event stream so that intermediaries do not close the connection.
+var settlementEventsKeepAlive = 30 * time.Second
+
+type settlementEventResponse struct {
+	Type       events.Type     `json:"type"`
+	Time       time.Time       `json:"time"`
+	Peer       string          `json:"peer"`
+	Chequebook *common.Address `json:"chequebook,omitempty"`
+	Amount     *bigint.BigInt  `json:"amount,omitempty"`
+	Payout     *bigint.BigInt  `json:"payout,omitempty"`
+	Balance    *bigint.BigInt  `json:"balance,omitempty"`
+	TxHash     *common.Hash    `json:"transactionHash,omitempty"`
+}
+
+func newSettlementEventResponse(e events.Event) settlementEventResponse {
+	response := settlementEventResponse{
+		Type: e.Type,
+		Time: e.Time,
+		Peer: e.Peer.String(),
+	}
+	if e.Chequebook != (common.Address{}) {
+		response.Chequebook = &e.Chequebook
+	}
+	if e.Amount != nil {
+		response.Amount = bigint.Wrap(e.Amount)
+	}
+	if e.Payout != nil {
+		response.Payout = bigint.Wrap(e.Payout)
+	}
+	if e.Balance != nil {
+		response.Balance = bigint.Wrap(e.Balance)
+	}
+	if e.TxHash != (common.Hash{}) {
+		response.TxHash = &e.TxHash
+	}
+	return response
+}
+
+// settlementEventsHandler streams settlement events as server-sent events
+// until the client disconnects or the node shuts down.
+func (s *Service) settlementEventsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_events").Build()
+
+	if s.settlementEvents == nil {
+		jsonhttp.MethodNotAllowed(w, errSettlementEventsUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.Error(nil, "response writer does not support streaming")
+		jsonhttp.InternalServerError(w, errSettlementEventsUnavailable)
+		return
+	}
+
+	c, cancel := s.settlementEvents.Subscribe()
+	defer cancel()
+
+	w.Header().Set(ContentTypeHeader, "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(settlementEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(newSettlementEventResponse(e))
+			if err != nil {
+				logger.Debug("marshal settlement event failed", "error", err)
+				logger.Error(nil, "marshal settlement event failed")
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				logger.Debug("write settlement event failed", "error", err)
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				logger.Debug("write keep-alive failed", "error", err)
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.quit:
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlementEvents(t *testing.T) {
+	t.Parallel()
+
+	feed := events.NewFeed()
+	t.Cleanup(func() { _ = feed.Close() })
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		Events:   feed,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/settlements/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get(api.ContentTypeHeader); ct != "text/event-stream" {
+		t.Fatalf("got content type %q", ct)
+	}
+
+	peer := swarm.MustParseHexAddress("abcd")
+	chequebook := common.HexToAddress("0xffff")
+	feed.Publish(events.Event{
+		Type:       events.TypeChequeReceived,
+		Peer:       peer,
+		Chequebook: chequebook,
+		Amount:     big.NewInt(42),
+	})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var eventType, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" && data != "" {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			eventType = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if eventType != string(events.TypeChequeReceived) {
+		t.Fatalf("got event type %q", eventType)
+	}
+	var got struct {
+		Type       string         `json:"type"`
+		Peer       string         `json:"peer"`
+		Chequebook common.Address `json:"chequebook"`
+		Amount     string         `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Peer != peer.String() || got.Chequebook != chequebook || got.Amount != "42" {
+		t.Fatalf("unexpected event %s", data)
+	}
+}
+
+func TestSettlementEventsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/settlements/events", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrSettlementEventsUnavailable,
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
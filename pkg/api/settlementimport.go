@@ -0,0 +1,132 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errNoImportPeers = "no peers to import"
+	errCantImport    = "can not import peer"
+)
+
+type settlementImportPeer struct {
+	Peer                 swarm.Address            `json:"peer"`
+	Balance              *bigint.BigInt           `json:"balance,omitempty"`
+	Beneficiary          *common.Address          `json:"beneficiary,omitempty"`
+	SentCumulativePayout *bigint.BigInt           `json:"sentCumulativePayout,omitempty"`
+	ReceivedCheque       *chequebook.SignedCheque `json:"receivedCheque,omitempty"`
+}
+
+type settlementImportRequest struct {
+	Peers []settlementImportPeer `json:"peers"`
+}
+
+type settlementImportResponse struct {
+	Imported []swarm.Address `json:"imported"`
+}
+
+// settlementImportHandler imports the balances and cumulative payouts of
+// peers carried over from another node. The swap state of all peers is
+// imported first so that the received cheques are verified in one batch, then
+// the balances are imported in order and the import stops at the first peer
+// that fails validation. Since importing the same state again has no effect,
+// the request can be repeated after fixing the failing entry.
+func (s *Service) settlementImportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_settlements_import").Build()
+
+	var data settlementImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		logger.Debug("failed to read body", "error", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if len(data.Peers) == 0 {
+		jsonhttp.BadRequest(w, errNoImportPeers)
+		return
+	}
+
+	var (
+		entries   []swap.PeerImportEntry
+		swapIndex = make([]int, len(data.Peers)) // index of the swap state of every peer, -1 if it has none
+	)
+	for i, p := range data.Peers {
+		swapIndex[i] = -1
+		if state, ok := swapImportState(p); ok {
+			swapIndex[i] = len(entries)
+			entries = append(entries, swap.PeerImportEntry{Peer: p.Peer, State: state})
+		}
+	}
+	var swapErrs []error
+	if len(entries) > 0 {
+		swapErrs = s.swap.ImportPeers(r.Context(), entries)
+	}
+
+	imported := make([]swarm.Address, 0, len(data.Peers))
+	for i, p := range data.Peers {
+		var err error
+		if p.Balance != nil {
+			if err = s.accounting.ImportBalance(p.Peer, p.Balance.Int); err != nil {
+				err = fmt.Errorf("balance: %w", err)
+			}
+		}
+		if err == nil && swapIndex[i] >= 0 {
+			err = swapErrs[swapIndex[i]]
+		}
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			logger.Debug("import peer failed", "peer_address", p.Peer, "error", err)
+			logger.Error(nil, "import peer failed", "peer_address", p.Peer)
+			jsonhttp.MethodNotAllowed(w, err)
+			return
+		case errors.Is(err, accounting.ErrBalanceExists),
+			errors.Is(err, chequebook.ErrImportBelowPaidOut),
+			errors.Is(err, chequebook.ErrChequeNotIncreasing),
+			errors.Is(err, chequebook.ErrChequeInvalid),
+			errors.Is(err, chequebook.ErrWrongBeneficiary),
+			errors.Is(err, chequebook.ErrNotDeployedByFactory),
+			errors.Is(err, swap.ErrWrongChequebook),
+			errors.Is(err, swap.ErrUnknownBeneficary),
+			errors.Is(err, swap.ErrNoChequebook):
+			logger.Debug("import peer failed", "peer_address", p.Peer, "error", err)
+			jsonhttp.BadRequest(w, fmt.Sprintf("peer %s: %v", p.Peer, err))
+			return
+		case err != nil:
+			logger.Debug("import peer failed", "peer_address", p.Peer, "error", err)
+			logger.Error(nil, "import peer failed", "peer_address", p.Peer)
+			jsonhttp.InternalServerError(w, errCantImport)
+			return
+		}
+		imported = append(imported, p.Peer)
+	}
+
+	jsonhttp.OK(w, settlementImportResponse{Imported: imported})
+}
+
+// swapImportState returns the swap state to import for the peer. It returns
+// false if there is none.
+func swapImportState(p settlementImportPeer) (swap.PeerImport, bool) {
+	state := swap.PeerImport{
+		Beneficiary:    p.Beneficiary,
+		ReceivedCheque: p.ReceivedCheque,
+	}
+	if p.SentCumulativePayout != nil {
+		state.SentCumulativePayout = p.SentCumulativePayout.Int
+	}
+	return state, state.SentCumulativePayout != nil || state.ReceivedCheque != nil
+}
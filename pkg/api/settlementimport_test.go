@@ -0,0 +1,167 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/accounting"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlementImport(t *testing.T) {
+	t.Parallel()
+
+	peer1 := swarm.MustParseHexAddress("a1")
+	peer2 := swarm.MustParseHexAddress("b2")
+	beneficiary := common.HexToAddress("0xfa")
+
+	balances := make(map[string]*big.Int)
+	imports := make(map[string]swap.PeerImport)
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		AccountingOpts: []accountingmock.Option{
+			accountingmock.WithImportBalanceFunc(func(peer swarm.Address, balance *big.Int) error {
+				balances[peer.String()] = balance
+				return nil
+			}),
+		},
+		SwapOpts: []swapmock.Option{
+			swapmock.WithImportPeerFunc(func(_ context.Context, peer swarm.Address, state swap.PeerImport) error {
+				imports[peer.String()] = state
+				return nil
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/import", http.StatusOK,
+		jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+			"peers": []map[string]interface{}{
+				{"peer": peer1.String(), "balance": "-100", "beneficiary": beneficiary.Hex(), "sentCumulativePayout": "500"},
+				{"peer": peer2.String(), "balance": "30"},
+			},
+		}),
+		jsonhttptest.WithExpectedJSONResponse(map[string]interface{}{
+			"imported": []string{peer1.String(), peer2.String()},
+		}),
+	)
+
+	if balances[peer1.String()].Int64() != -100 || balances[peer2.String()].Int64() != 30 {
+		t.Fatalf("got balances %v", balances)
+	}
+	state, ok := imports[peer1.String()]
+	if !ok || *state.Beneficiary != beneficiary || state.SentCumulativePayout.Int64() != 500 || state.ReceivedCheque != nil {
+		t.Fatalf("got import %+v", state)
+	}
+	if _, ok := imports[peer2.String()]; ok {
+		t.Fatal("swap import for peer without swap state")
+	}
+}
+
+func TestSettlementImportErrors(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("a1")
+
+	t.Run("no peers", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/import", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{"peers": []interface{}{}}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: api.ErrNoImportPeers,
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("sent cumulative payout: %w", chequebook.ErrImportBelowPaidOut)
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			SwapOpts: []swapmock.Option{
+				swapmock.WithImportPeerFunc(func(context.Context, swarm.Address, swap.PeerImport) error {
+					return err
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/import", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"peers": []map[string]interface{}{{"peer": peer.String(), "sentCumulativePayout": "5"}},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: fmt.Sprintf("peer %s: %v", peer, err),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("balance exists", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			AccountingOpts: []accountingmock.Option{
+				accountingmock.WithImportBalanceFunc(func(swarm.Address, *big.Int) error {
+					return accounting.ErrBalanceExists
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/import", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"peers": []map[string]interface{}{{"peer": peer.String(), "balance": "5"}},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: fmt.Sprintf("peer %s: balance: %v", peer, accounting.ErrBalanceExists),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			SwapOpts: []swapmock.Option{
+				swapmock.WithImportPeerFunc(func(context.Context, swarm.Address, swap.PeerImport) error {
+					return errors.New("rpc down")
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodPost, "/settlements/import", http.StatusInternalServerError,
+			jsonhttptest.WithJSONRequestBody(map[string]interface{}{
+				"peers": []map[string]interface{}{{"peer": peer.String(), "sentCumulativePayout": "5"}},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: api.ErrCantImport,
+				Code:    http.StatusInternalServerError,
+			}),
+		)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+const errSettlementKeysUnavailable = "settlement key layout not available"
+
+type settlementKeyResponse struct {
+	Pattern     string `json:"pattern"`
+	Prefix      string `json:"prefix"`
+	Value       string `json:"value"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+type settlementKeysResponse struct {
+	Keys []settlementKeyResponse `json:"keys"`
+}
+
+// settlementKeysHandler returns the layout of the settlement records in the
+// statestore.
+func (s *Service) settlementKeysHandler(w http.ResponseWriter, _ *http.Request) {
+	if s.keyLayout == nil {
+		jsonhttp.MethodNotAllowed(w, errSettlementKeysUnavailable)
+		return
+	}
+
+	keys := s.keyLayout.Keys()
+	resp := settlementKeysResponse{Keys: make([]settlementKeyResponse, 0, len(keys))}
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, settlementKeyResponse{
+			Pattern:     k.Pattern,
+			Prefix:      k.Prefix(),
+			Value:       k.Value,
+			Version:     k.Version,
+			Description: k.Description,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
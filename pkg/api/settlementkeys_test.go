@@ -0,0 +1,55 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
+)
+
+func TestSettlementKeys(t *testing.T) {
+	t.Parallel()
+
+	layout, err := keylayout.New(
+		keylayout.Key{Pattern: "swap_receipt_<beneficiary>", Value: "swap.Receipt", Version: 1, Description: "receipt"},
+		keylayout.Key{Pattern: "audit_head", Value: "uint64", Version: 1, Description: "head"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:  true,
+		KeyLayout: layout,
+	})
+
+	var got api.SettlementKeysResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/keys", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	want := []api.SettlementKeyResponse{
+		{Pattern: "audit_head", Prefix: "audit_head", Value: "uint64", Version: 1, Description: "head"},
+		{Pattern: "swap_receipt_<beneficiary>", Prefix: "swap_receipt_", Value: "swap.Receipt", Version: 1, Description: "receipt"},
+	}
+	if !reflect.DeepEqual(got.Keys, want) {
+		t.Fatalf("got keys %+v, want %+v", got.Keys, want)
+	}
+
+	t.Run("not available", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/keys", http.StatusMethodNotAllowed)
+	})
+}
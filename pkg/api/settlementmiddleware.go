@@ -0,0 +1,29 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"resenje.org/web"
+)
+
+// SettlementMiddleware wraps the handlers of the settlement endpoints of the
+// debug API, the time settlement, settlement, chequebook and wallet
+// endpoints. Deployments register middlewares in code to enforce their own
+// controls on fund movement, like auditing, rate limiting or additional
+// authorization. They run after the permission check of restricted nodes.
+type SettlementMiddleware = func(http.Handler) http.Handler
+
+// settlementHandler wraps the handler with the settlement middlewares, the
+// first one being the outermost.
+func (s *Service) settlementHandler(handler http.Handler) http.Handler {
+	if len(s.settlementMiddlewares) == 0 {
+		return handler
+	}
+	chain := make([]func(http.Handler) http.Handler, 0, len(s.settlementMiddlewares)+1)
+	chain = append(chain, s.settlementMiddlewares...)
+	return web.ChainHandlers(append(chain, web.FinalHandler(handler))...)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/mock"
+)
+
+func TestSettlementMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(name string) api.SettlementMiddleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls = append(calls, name+" "+r.Method+" "+r.URL.Path)
+				mu.Unlock()
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+	denyWithdrawals := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && r.URL.Path == "/chequebook/withdraw" {
+				jsonhttp.Forbidden(w, "withdrawals disabled")
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []mock.Option{
+			mock.WithSettlementsSentFunc(func() (map[string]*big.Int, error) { return nil, nil }),
+			mock.WithSettlementsRecvFunc(func() (map[string]*big.Int, error) { return nil, nil }),
+		},
+		SettlementMiddlewares: []api.SettlementMiddleware{record("first"), record("second"), denyWithdrawals},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements", http.StatusOK)
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/withdraw?amount=1", http.StatusForbidden,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "withdrawals disabled",
+			Code:    http.StatusForbidden,
+		}),
+	)
+	// endpoints other than the settlement ones are not wrapped
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/health", http.StatusOK)
+
+	want := []string{
+		"first GET /settlements",
+		"second GET /settlements",
+		"first POST /chequebook/withdraw",
+		"second POST /chequebook/withdraw",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("got calls %v, want %v", calls, want)
+		}
+	}
+}
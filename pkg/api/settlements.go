@@ -13,6 +13,7 @@ import (
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/mux"
 )
@@ -20,6 +21,7 @@ import (
 const (
 	errCantSettlements     = "can not get settlements"
 	errCantSettlementsPeer = "can not get settlements for peer"
+	errCantSimulate        = "can not simulate settlements"
 )
 
 type settlementResponse struct {
@@ -34,10 +36,10 @@ type settlementsResponse struct {
 	Settlements             []settlementResponse `json:"settlements"`
 }
 
-func (s *Service) settlementsHandler(w http.ResponseWriter, _ *http.Request) {
+func (s *Service) settlementsHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("get_settlements").Build()
 
-	settlementsSent, err := s.swap.SettlementsSent()
+	settlementsSent, err := s.swap.SettlementsSent(r.Context())
 	if errors.Is(err, postagecontract.ErrChainDisabled) {
 		logger.Debug("sent settlements failed", "error", err)
 		logger.Error(nil, "sent settlements failed")
@@ -160,10 +162,10 @@ func (s *Service) peerSettlementsHandler(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-func (s *Service) settlementsHandlerPseudosettle(w http.ResponseWriter, _ *http.Request) {
+func (s *Service) settlementsHandlerPseudosettle(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("get_timesettlements").Build()
 
-	settlementsSent, err := s.pseudosettle.SettlementsSent()
+	settlementsSent, err := s.pseudosettle.SettlementsSent(r.Context())
 	if err != nil {
 		jsonhttp.InternalServerError(w, errCantSettlements)
 		logger.Debug("sent settlements failed", "error", err)
@@ -216,3 +218,147 @@ func (s *Service) settlementsHandlerPseudosettle(w http.ResponseWriter, _ *http.
 
 	jsonhttp.OK(w, settlementsResponse{TotalSettlementReceived: bigint.Wrap(totalReceived), TotalSettlementSent: bigint.Wrap(totalSent), Settlements: settlementResponsesArray})
 }
+
+type settlementSimulationPeerResponse struct {
+	Peer         string         `json:"peer"`
+	Debt         *bigint.BigInt `json:"debt"`
+	ChequeCount  int            `json:"chequeCount"`
+	ChequeAmount *bigint.BigInt `json:"chequeAmount"`
+	Remainder    *bigint.BigInt `json:"remainder"`
+}
+
+type settlementSimulationResponse struct {
+	PaymentThreshold *bigint.BigInt                     `json:"paymentThreshold"`
+	ChequeCount      int                                `json:"chequeCount"`
+	TotalAmount      *bigint.BigInt                     `json:"totalAmount"`
+	GasPrice         *bigint.BigInt                     `json:"gasPrice"`
+	DataFee          *bigint.BigInt                     `json:"dataFee,omitempty"`
+	ProjectedGasCost *bigint.BigInt                     `json:"projectedGasCost"`
+	Peers            []settlementSimulationPeerResponse `json:"peers"`
+}
+
+func (s *Service) settlementsSimulationHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_simulation").Build()
+
+	queries := struct {
+		Threshold *big.Int `map:"threshold" validate:"required"`
+		GasPrice  *big.Int `map:"gasPrice"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	balances, err := s.accounting.Balances()
+	if err != nil {
+		logger.Debug("get balances failed", "error", err)
+		logger.Error(nil, "get balances failed")
+		jsonhttp.InternalServerError(w, errCantSimulate)
+		return
+	}
+
+	// a negative balance is a debt towards the peer
+	debts := make(map[string]*big.Int, len(balances))
+	for peer, balance := range balances {
+		debts[peer] = new(big.Int).Neg(balance)
+	}
+
+	gasPrice := queries.GasPrice
+	if gasPrice == nil {
+		gasPrice, err = s.chainBackend.SuggestGasPrice(r.Context())
+		if err != nil {
+			logger.Debug("suggest gas price failed", "error", err)
+			logger.Error(nil, "suggest gas price failed")
+			jsonhttp.InternalServerError(w, errCantSimulate)
+			return
+		}
+	}
+
+	// on rollups every cashout is also charged the fee for publishing it on L1
+	var dataFee *big.Int
+	if s.cashoutDataFee != nil {
+		dataFee, err = s.cashoutDataFee(r.Context())
+		if err != nil {
+			logger.Debug("estimate cashout data fee failed", "error", err)
+			logger.Error(nil, "estimate cashout data fee failed")
+			jsonhttp.InternalServerError(w, errCantSimulate)
+			return
+		}
+	}
+
+	// cheques are issued at the current rates and include the deduction
+	// for peers which have not received it yet
+	o := swap.SimulationOptions{
+		PaymentThreshold: queries.Threshold,
+		MinimumPayment:   s.minimumPayment,
+		Deducted:         make(map[string]bool),
+		GasPrice:         gasPrice,
+		DataFee:          dataFee,
+	}
+	if s.settlementRates != nil {
+		o.ExchangeRate, o.Deduction, err = s.settlementRates()
+		if err != nil {
+			logger.Debug("get settlement rates failed", "error", err)
+			logger.Error(nil, "get settlement rates failed")
+			jsonhttp.InternalServerError(w, errCantSimulate)
+			return
+		}
+	}
+	if s.deductedBy != nil {
+		for peer, debt := range debts {
+			if debt.Sign() <= 0 {
+				continue
+			}
+			address, err := swarm.ParseHexAddress(peer)
+			if err != nil {
+				continue
+			}
+			deducted, err := s.deductedBy(address)
+			if err != nil {
+				logger.Debug("get deduction failed", "peer_address", address, "error", err)
+				logger.Error(nil, "get deduction failed")
+				jsonhttp.InternalServerError(w, errCantSimulate)
+				return
+			}
+			o.Deducted[peer] = deducted
+		}
+	}
+
+	simulation, err := swap.Simulate(debts, o)
+	if errors.Is(err, swap.ErrInvalidPaymentThreshold) {
+		logger.Debug("simulate settlements failed", "error", err)
+		logger.Error(nil, "simulate settlements failed")
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("simulate settlements failed", "error", err)
+		logger.Error(nil, "simulate settlements failed")
+		jsonhttp.InternalServerError(w, errCantSimulate)
+		return
+	}
+
+	peers := make([]settlementSimulationPeerResponse, 0, len(simulation.Peers))
+	for _, p := range simulation.Peers {
+		peers = append(peers, settlementSimulationPeerResponse{
+			Peer:         p.Peer,
+			Debt:         bigint.Wrap(p.Debt),
+			ChequeCount:  p.ChequeCount,
+			ChequeAmount: bigint.Wrap(p.ChequeAmount),
+			Remainder:    bigint.Wrap(p.Remainder),
+		})
+	}
+
+	response := settlementSimulationResponse{
+		PaymentThreshold: bigint.Wrap(simulation.PaymentThreshold),
+		ChequeCount:      simulation.ChequeCount,
+		TotalAmount:      bigint.Wrap(simulation.TotalAmount),
+		GasPrice:         bigint.Wrap(gasPrice),
+		ProjectedGasCost: bigint.Wrap(simulation.ProjectedGasCost),
+		Peers:            peers,
+	}
+	if dataFee != nil {
+		response.DataFee = bigint.Wrap(dataFee)
+	}
+	jsonhttp.OK(w, response)
+}
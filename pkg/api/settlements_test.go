@@ -5,19 +5,26 @@
 package api_test
 
 import (
+	"context"
 	"errors"
 	"math/big"
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/bigint"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
 	"github.com/ethersphere/bee/pkg/settlement/swap/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
 )
 
 func TestSettlements(t *testing.T) {
@@ -284,3 +291,218 @@ func equalSettlements(a, b *api.SettlementsResponse) bool {
 
 	return true
 }
+
+func TestSettlementsSimulation(t *testing.T) {
+	t.Parallel()
+
+	balancesFunc := func() (map[string]*big.Int, error) {
+		return map[string]*big.Int{
+			"DEAD": big.NewInt(-250),
+			"BEEF": big.NewInt(100),
+		}, nil
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:       true,
+		AccountingOpts: []accountingmock.Option{accountingmock.WithBalancesFunc(balancesFunc)},
+		SettlementRates: func() (*big.Int, *big.Int, error) {
+			return big.NewInt(10), big.NewInt(7), nil
+		},
+		DeductedBy: func(peer swarm.Address) (bool, error) {
+			return false, nil
+		},
+		MinimumPayment: big.NewInt(50),
+	})
+
+	// the whole debt is paid with one cheque at the exchange rate, including
+	// the deduction
+	expected := &api.SettlementSimulationResponse{
+		PaymentThreshold: bigint.Wrap(big.NewInt(100)),
+		ChequeCount:      1,
+		TotalAmount:      bigint.Wrap(big.NewInt(2507)),
+		GasPrice:         bigint.Wrap(big.NewInt(3)),
+		ProjectedGasCost: bigint.Wrap(big.NewInt(900_000)),
+		Peers: []api.SettlementSimulationPeerResponse{
+			{
+				Peer:         "DEAD",
+				Debt:         bigint.Wrap(big.NewInt(250)),
+				ChequeCount:  1,
+				ChequeAmount: bigint.Wrap(big.NewInt(2507)),
+				Remainder:    bigint.Wrap(big.NewInt(0)),
+			},
+		},
+	}
+
+	var got *api.SettlementSimulationResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/simulation?threshold=100&gasPrice=3", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got simulation: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestSettlementsSimulationInvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		AccountingOpts: []accountingmock.Option{accountingmock.WithBalancesFunc(func() (map[string]*big.Int, error) {
+			return map[string]*big.Int{}, nil
+		})},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/simulation?threshold=0&gasPrice=3", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: swap.ErrInvalidPaymentThreshold.Error(),
+			Code:    http.StatusBadRequest,
+		}),
+	)
+}
+
+func TestSettlementsSummary(t *testing.T) {
+	t.Parallel()
+
+	var balanceCalls int
+	pendingTx := common.HexToHash("0xabcd")
+	bounceTx := common.HexToHash("0xeeee")
+	bouncePeer := swarm.MustParseHexAddress("dead")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithChequebookBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+				balanceCalls++
+				return big.NewInt(1000), nil
+			}),
+			chequebookmock.WithChequebookAvailableBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(800), nil
+			}),
+		},
+		SwapOpts: []mock.Option{
+			mock.WithSettlementsSentFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{"DEAD": big.NewInt(100), "BEEF": big.NewInt(50)}, nil
+			}),
+			mock.WithSettlementsRecvFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{"BEEF": big.NewInt(70)}, nil
+			}),
+			mock.WithRecentBouncesFunc(func() []swap.Bounce {
+				return []swap.Bounce{{Peer: bouncePeer, TxHash: bounceTx, Amount: big.NewInt(30), Time: time.Unix(1, 0)}}
+			}),
+		},
+		AccountingOpts: []accountingmock.Option{
+			accountingmock.WithBalancesFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{
+					"A1": big.NewInt(-10),
+					"A2": big.NewInt(-30),
+					"A3": big.NewInt(-20),
+					"B1": big.NewInt(5),
+					"B2": big.NewInt(15),
+					"C1": big.NewInt(0),
+				}, nil
+			}),
+		},
+		TransactionOpts: []transactionmock.Option{
+			transactionmock.WithPendingTransactionsFunc(func() ([]common.Hash, error) {
+				return []common.Hash{pendingTx}, nil
+			}),
+		},
+	})
+
+	var got api.SettlementsSummaryResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/summary?limit=2", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if got.ChequebookBalance.Int64() != 1000 || got.AvailableBalance.Int64() != 800 {
+		t.Fatalf("got balances %v/%v, want 1000/800", got.ChequebookBalance, got.AvailableBalance)
+	}
+	if got.TotalSent.Int64() != 150 || got.TotalReceived.Int64() != 70 {
+		t.Fatalf("got totals %v/%v, want 150/70", got.TotalSent, got.TotalReceived)
+	}
+
+	expectedCreditors := []api.SettlementsSummaryPeerResponse{
+		{Peer: "A2", Balance: bigint.Wrap(big.NewInt(-30))},
+		{Peer: "A3", Balance: bigint.Wrap(big.NewInt(-20))},
+	}
+	if !reflect.DeepEqual(got.TopCreditors, expectedCreditors) {
+		t.Fatalf("got creditors %+v, want %+v", got.TopCreditors, expectedCreditors)
+	}
+	expectedDebtors := []api.SettlementsSummaryPeerResponse{
+		{Peer: "B2", Balance: bigint.Wrap(big.NewInt(15))},
+		{Peer: "B1", Balance: bigint.Wrap(big.NewInt(5))},
+	}
+	if !reflect.DeepEqual(got.TopDebtors, expectedDebtors) {
+		t.Fatalf("got debtors %+v, want %+v", got.TopDebtors, expectedDebtors)
+	}
+
+	if len(got.PendingTransactions) != 1 || got.PendingTransactions[0] != pendingTx {
+		t.Fatalf("got pending transactions %v", got.PendingTransactions)
+	}
+	if len(got.RecentBounces) != 1 || got.RecentBounces[0].TxHash != bounceTx || got.RecentBounces[0].Peer != bouncePeer.String() {
+		t.Fatalf("got bounces %+v", got.RecentBounces)
+	}
+
+	// the summary is cached, a second request does not query the chequebook again
+	got = api.SettlementsSummaryResponse{}
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/summary", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+	if balanceCalls != 1 {
+		t.Fatalf("got %d balance calls, want 1", balanceCalls)
+	}
+	if len(got.TopCreditors) != 3 {
+		t.Fatalf("got %d creditors, want 3", len(got.TopCreditors))
+	}
+}
+
+func TestSettlementsSummaryError(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithChequebookBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+				return nil, errors.New("chain unavailable")
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/summary", http.StatusInternalServerError,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrCantSettlementsSummary,
+			Code:    http.StatusInternalServerError,
+		}),
+	)
+}
+
+func TestSettlementsSimulationDataFee(t *testing.T) {
+	t.Parallel()
+
+	balancesFunc := func() (map[string]*big.Int, error) {
+		return map[string]*big.Int{
+			"DEAD": big.NewInt(-250),
+		}, nil
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:       true,
+		AccountingOpts: []accountingmock.Option{accountingmock.WithBalancesFunc(balancesFunc)},
+		CashoutDataFee: func(context.Context) (*big.Int, error) {
+			return big.NewInt(5000), nil
+		},
+	})
+
+	var got *api.SettlementSimulationResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/simulation?threshold=100&gasPrice=3", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if got.DataFee == nil || got.DataFee.Cmp(big.NewInt(5000)) != 0 {
+		t.Fatalf("got data fee %v, want 5000", got.DataFee)
+	}
+	if want := big.NewInt(900_000 + 5000); got.ProjectedGasCost.Cmp(want) != 0 {
+		t.Fatalf("got projected gas cost %v, want %v", got.ProjectedGasCost, want)
+	}
+}
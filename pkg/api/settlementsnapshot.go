@@ -0,0 +1,38 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+const (
+	errSnapshotUnavailable = "settlement snapshots unavailable"
+	errCantSnapshot        = "can not take settlement snapshot"
+)
+
+// settlementSnapshotHandler returns a consistent snapshot of the settlement
+// state for backups. Snapshots are restored offline with the bee db command.
+func (s *Service) settlementSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_snapshot").Build()
+
+	if s.snapshots == nil {
+		jsonhttp.MethodNotAllowed(w, errSnapshotUnavailable)
+		return
+	}
+
+	snapshot, err := s.snapshots.Snapshot(r.Context())
+	if err != nil {
+		logger.Debug("take settlement snapshot failed", "error", err)
+		logger.Error(nil, "take settlement snapshot failed")
+		jsonhttp.InternalServerError(w, errCantSnapshot)
+		return
+	}
+
+	w.Header().Set(ContentDispositionHeader, `attachment; filename="settlement-snapshot.json"`)
+	jsonhttp.OK(w, snapshot)
+}
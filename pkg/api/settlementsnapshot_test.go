@@ -0,0 +1,63 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+)
+
+func TestSettlementSnapshot(t *testing.T) {
+	t.Parallel()
+
+	store, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	if err := store.Put("swap_cheque", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("other", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:  true,
+		Snapshots: snapshot.New(store, "swap_"),
+	})
+
+	var got snapshot.Snapshot
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/snapshot", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+		jsonhttptest.WithExpectedResponseHeader(api.ContentDispositionHeader, `attachment; filename="settlement-snapshot.json"`),
+	)
+
+	if got.Version != snapshot.Version || len(got.Records) != 1 || got.Records[0].Key != "swap_cheque" || string(got.Records[0].Value) != "1" {
+		t.Fatalf("got snapshot %+v", got)
+	}
+}
+
+func TestSettlementSnapshotUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/snapshot", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrSnapshotUnavailable,
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
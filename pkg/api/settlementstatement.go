@@ -0,0 +1,88 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errNoPeerStatement      = "no settlement with peer"
+	errInvalidPeerStatement = "invalid statement from peer"
+	errCantPeerStatement    = "can not get statement from peer"
+)
+
+type settlementStatementResponse struct {
+	Chequebook       string         `json:"chequebook"`
+	Beneficiary      string         `json:"beneficiary"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Timestamp        int64          `json:"timestamp"`
+	Signature        string         `json:"signature"`
+}
+
+type settlementStatementCheckResponse struct {
+	Peer             string                      `json:"peer"`
+	Statement        settlementStatementResponse `json:"statement"`
+	CumulativePayout *bigint.BigInt              `json:"cumulativePayout"`
+	Matches          bool                        `json:"matches"`
+}
+
+// settlementStatementHandler requests the signed statement of the peer about
+// the cheques it sent to us and compares it with the cheques we received.
+func (s *Service) settlementStatementHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_statement_by_peer").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	check, err := s.swap.PeerStatement(r.Context(), paths.Peer)
+	if err != nil {
+		logger.Debug("get peer statement failed", "peer_address", paths.Peer, "error", err)
+		switch {
+		case errors.Is(err, postagecontract.ErrChainDisabled):
+			jsonhttp.MethodNotAllowed(w, err)
+		case errors.Is(err, swap.ErrUnknownBeneficary),
+			errors.Is(err, settlement.ErrPeerNoSettlements):
+			jsonhttp.NotFound(w, errNoPeerStatement)
+		case errors.Is(err, chequebook.ErrStatementInvalid),
+			errors.Is(err, chequebook.ErrWrongBeneficiary),
+			errors.Is(err, swap.ErrWrongChequebook):
+			jsonhttp.BadGateway(w, errInvalidPeerStatement)
+		default:
+			logger.Error(nil, "get peer statement failed", "peer_address", paths.Peer)
+			jsonhttp.InternalServerError(w, errCantPeerStatement)
+		}
+		return
+	}
+
+	jsonhttp.OK(w, settlementStatementCheckResponse{
+		Peer: paths.Peer.String(),
+		Statement: settlementStatementResponse{
+			Chequebook:       check.Statement.Chequebook.String(),
+			Beneficiary:      check.Statement.Beneficiary.String(),
+			CumulativePayout: bigint.Wrap(check.Statement.CumulativePayout),
+			Timestamp:        check.Statement.Timestamp,
+			Signature:        hexutil.Encode(check.Statement.Signature),
+		},
+		CumulativePayout: bigint.Wrap(check.CumulativePayout),
+		Matches:          check.Matches,
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlementStatement(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("a1")
+	chequebookAddress := common.HexToAddress("0xcb")
+	beneficiary := common.HexToAddress("0xbe")
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithPeerStatementFunc(func(_ context.Context, p swarm.Address) (*swap.StatementCheck, error) {
+				if !p.Equal(peer) {
+					return nil, swap.ErrUnknownBeneficary
+				}
+				return &swap.StatementCheck{
+					Statement: &chequebook.Statement{
+						Chequebook:       chequebookAddress,
+						Beneficiary:      beneficiary,
+						CumulativePayout: big.NewInt(400),
+						Timestamp:        1000,
+						Signature:        []byte{1, 2},
+					},
+					CumulativePayout: big.NewInt(300),
+					Matches:          false,
+				}, nil
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/statement/"+peer.String(), http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.SettlementStatementCheckResponse{
+			Peer: peer.String(),
+			Statement: api.SettlementStatementResponse{
+				Chequebook:       chequebookAddress.String(),
+				Beneficiary:      beneficiary.String(),
+				CumulativePayout: bigint.Wrap(big.NewInt(400)),
+				Timestamp:        1000,
+				Signature:        "0x0102",
+			},
+			CumulativePayout: bigint.Wrap(big.NewInt(300)),
+			Matches:          false,
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/statement/b2", http.StatusNotFound,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrNoPeerStatement,
+			Code:    http.StatusNotFound,
+		}),
+	)
+}
+
+func TestSettlementStatementInvalid(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		SwapOpts: []swapmock.Option{
+			swapmock.WithPeerStatementFunc(func(context.Context, swarm.Address) (*swap.StatementCheck, error) {
+				return nil, chequebook.ErrStatementInvalid
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/statement/a1", http.StatusBadGateway,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: api.ErrInvalidPeerStatement,
+			Code:    http.StatusBadGateway,
+		}),
+	)
+}
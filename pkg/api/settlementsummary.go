@@ -0,0 +1,201 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+)
+
+const errCantSettlementsSummary = "can not get settlements summary"
+
+// settlementsSummaryTTL is how long a computed summary is served from the cache.
+const settlementsSummaryTTL = 5 * time.Second
+
+type settlementsSummaryPeerResponse struct {
+	Peer    string         `json:"peer"`
+	Balance *bigint.BigInt `json:"balance"`
+}
+
+type settlementsSummaryBounceResponse struct {
+	Peer       string         `json:"peer"`
+	Chequebook common.Address `json:"chequebook"`
+	TxHash     common.Hash    `json:"transactionHash"`
+	Amount     *bigint.BigInt `json:"amount,omitempty"`
+	Time       time.Time      `json:"time"`
+}
+
+type settlementsSummaryResponse struct {
+	ChequebookBalance   *bigint.BigInt                     `json:"chequebookBalance"`
+	AvailableBalance    *bigint.BigInt                     `json:"availableBalance"`
+	TotalSent           *bigint.BigInt                     `json:"totalSent"`
+	TotalReceived       *bigint.BigInt                     `json:"totalReceived"`
+	TopCreditors        []settlementsSummaryPeerResponse   `json:"topCreditors"`
+	TopDebtors          []settlementsSummaryPeerResponse   `json:"topDebtors"`
+	PendingTransactions []common.Hash                      `json:"pendingTransactions"`
+	RecentBounces       []settlementsSummaryBounceResponse `json:"recentBounces"`
+	Timestamp           time.Time                          `json:"timestamp"`
+}
+
+// settlementsSummaryCache holds the last computed summary so that frequently
+// polling dashboards do not query the chain and the state store every time.
+type settlementsSummaryCache struct {
+	mu      sync.Mutex
+	summary *settlementsSummaryResponse
+	expires time.Time
+}
+
+func (s *Service) settlementsSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_settlements_summary").Build()
+
+	queries := struct {
+		Limit int `map:"limit" validate:"min=0"`
+	}{
+		Limit: 10, // Default limit.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	summary, err := s.settlementsSummary(r.Context())
+	if errors.Is(err, postagecontract.ErrChainDisabled) {
+		logger.Debug("get settlements summary failed", "error", err)
+		logger.Error(nil, "get settlements summary failed")
+		jsonhttp.MethodNotAllowed(w, err)
+		return
+	}
+	if err != nil {
+		logger.Debug("get settlements summary failed", "error", err)
+		logger.Error(nil, "get settlements summary failed")
+		jsonhttp.InternalServerError(w, errCantSettlementsSummary)
+		return
+	}
+
+	response := *summary
+	if len(response.TopCreditors) > queries.Limit {
+		response.TopCreditors = response.TopCreditors[:queries.Limit]
+	}
+	if len(response.TopDebtors) > queries.Limit {
+		response.TopDebtors = response.TopDebtors[:queries.Limit]
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// settlementsSummary returns the cached summary or computes a new one if the
+// cached one expired. The creditors and debtors are not truncated.
+func (s *Service) settlementsSummary(ctx context.Context) (*settlementsSummaryResponse, error) {
+	s.summaryCache.mu.Lock()
+	defer s.summaryCache.mu.Unlock()
+
+	now := time.Now()
+	if s.summaryCache.summary != nil && now.Before(s.summaryCache.expires) {
+		return s.summaryCache.summary, nil
+	}
+
+	balance, err := s.chequebook.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	availableBalance, err := s.chequebook.AvailableBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := s.swap.SettlementsSent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	received, err := s.swap.SettlementsReceived()
+	if err != nil {
+		return nil, err
+	}
+	totalSent, totalReceived := sumSettlements(sent), sumSettlements(received)
+
+	balances, err := s.accounting.Balances()
+	if err != nil {
+		return nil, err
+	}
+	// a negative balance is a debt towards the peer
+	creditors := make([]settlementsSummaryPeerResponse, 0)
+	debtors := make([]settlementsSummaryPeerResponse, 0)
+	for peer, b := range balances {
+		switch b.Sign() {
+		case -1:
+			creditors = append(creditors, settlementsSummaryPeerResponse{Peer: peer, Balance: bigint.Wrap(b)})
+		case 1:
+			debtors = append(debtors, settlementsSummaryPeerResponse{Peer: peer, Balance: bigint.Wrap(b)})
+		}
+	}
+	sort.Slice(creditors, func(i, j int) bool {
+		if c := creditors[i].Balance.Cmp(creditors[j].Balance.Int); c != 0 {
+			return c < 0
+		}
+		return creditors[i].Peer < creditors[j].Peer
+	})
+	sort.Slice(debtors, func(i, j int) bool {
+		if c := debtors[i].Balance.Cmp(debtors[j].Balance.Int); c != 0 {
+			return c > 0
+		}
+		return debtors[i].Peer < debtors[j].Peer
+	})
+
+	pending, err := s.transaction.PendingTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		pending = make([]common.Hash, 0)
+	}
+
+	bounces := s.swap.RecentBounces()
+	recentBounces := make([]settlementsSummaryBounceResponse, 0, len(bounces))
+	for _, b := range bounces {
+		bounce := settlementsSummaryBounceResponse{
+			Peer:       b.Peer.String(),
+			Chequebook: b.Chequebook,
+			TxHash:     b.TxHash,
+			Time:       b.Time,
+		}
+		if b.Amount != nil {
+			bounce.Amount = bigint.Wrap(b.Amount)
+		}
+		recentBounces = append(recentBounces, bounce)
+	}
+
+	s.summaryCache.summary = &settlementsSummaryResponse{
+		ChequebookBalance:   bigint.Wrap(balance),
+		AvailableBalance:    bigint.Wrap(availableBalance),
+		TotalSent:           bigint.Wrap(totalSent),
+		TotalReceived:       bigint.Wrap(totalReceived),
+		TopCreditors:        creditors,
+		TopDebtors:          debtors,
+		PendingTransactions: pending,
+		RecentBounces:       recentBounces,
+		Timestamp:           now,
+	}
+	s.summaryCache.expires = now.Add(settlementsSummaryTTL)
+
+	return s.summaryCache.summary, nil
+}
+
+func sumSettlements(values map[string]*big.Int) *big.Int {
+	total := big.NewInt(0)
+	for _, v := range values {
+		total.Add(total, v)
+	}
+	return total
+}
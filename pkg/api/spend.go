@@ -0,0 +1,139 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	errSpendUnavailable         = "spend analytics unavailable"
+	errCantSpend                = "cannot get spend analytics"
+	errSpendPurposesUnavailable = "spend by purpose unavailable"
+	errCantSpendPurposes        = "cannot get spend by purpose"
+)
+
+type chequebookSpendBeneficiary struct {
+	Peer   swarm.Address  `json:"peer"`
+	Amount *bigint.BigInt `json:"amount"`
+}
+
+type chequebookSpendResponse struct {
+	Window           int                          `json:"window"` // seconds
+	Total            *bigint.BigInt               `json:"total"`
+	DailyBurnRate    *bigint.BigInt               `json:"dailyBurnRate"`
+	AvailableBalance *bigint.BigInt               `json:"availableBalance"`
+	TimeToEmpty      int                          `json:"timeToEmpty"` // seconds, zero if nothing was issued
+	Beneficiaries    []chequebookSpendBeneficiary `json:"beneficiaries"`
+}
+
+// chequebookSpendHandler returns the tokens issued in cheques within the
+// analytics window, the top beneficiaries and the projected time until the
+// chequebook is empty.
+func (s *Service) chequebookSpendHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_spend").Build()
+
+	if s.spend == nil {
+		jsonhttp.MethodNotAllowed(w, errSpendUnavailable)
+		return
+	}
+
+	queries := struct {
+		Top int `map:"top" validate:"min=0"`
+	}{
+		Top: 10, // Default number of beneficiaries.
+	}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	report, err := s.spend.Report(r.Context(), queries.Top)
+	if err != nil {
+		logger.Debug("get spend analytics failed", "error", err)
+		logger.Error(nil, "get spend analytics failed")
+		jsonhttp.InternalServerError(w, errCantSpend)
+		return
+	}
+
+	response := chequebookSpendResponse{
+		Window:           int(report.Window.Seconds()),
+		Total:            bigint.Wrap(report.Total),
+		DailyBurnRate:    bigint.Wrap(report.DailyBurnRate),
+		AvailableBalance: bigint.Wrap(report.AvailableBalance),
+		TimeToEmpty:      int(report.TimeToEmpty.Seconds()),
+		Beneficiaries:    make([]chequebookSpendBeneficiary, 0, len(report.Beneficiaries)),
+	}
+	for _, b := range report.Beneficiaries {
+		response.Beneficiaries = append(response.Beneficiaries, chequebookSpendBeneficiary{
+			Peer:   b.Peer,
+			Amount: bigint.Wrap(b.Amount),
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+type chequebookSpendPurpose struct {
+	Purpose string         `json:"purpose"`
+	Gas     *bigint.BigInt `json:"gas"`
+	Tokens  *bigint.BigInt `json:"tokens"`
+}
+
+type chequebookSpendPurposesResponse struct {
+	Window   int                      `json:"window"` // seconds
+	Gas      *bigint.BigInt           `json:"gas"`
+	Tokens   *bigint.BigInt           `json:"tokens"`
+	Purposes []chequebookSpendPurpose `json:"purposes"`
+}
+
+// chequebookSpendPurposesHandler returns the gas and tokens spent by the
+// transactions of the node within the requested window grouped by their
+// purpose.
+func (s *Service) chequebookSpendPurposesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_spend_purposes").Build()
+
+	if s.purposes == nil {
+		jsonhttp.MethodNotAllowed(w, errSpendPurposesUnavailable)
+		return
+	}
+
+	queries := struct {
+		Window int64 `map:"window" validate:"min=0"` // seconds, zero for the whole analytics window
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	report, err := s.purposes.Report(r.Context(), time.Duration(queries.Window)*time.Second)
+	if err != nil {
+		logger.Debug("get spend by purpose failed", "error", err)
+		logger.Error(nil, "get spend by purpose failed")
+		jsonhttp.InternalServerError(w, errCantSpendPurposes)
+		return
+	}
+
+	response := chequebookSpendPurposesResponse{
+		Window:   int(report.Window.Seconds()),
+		Gas:      bigint.Wrap(report.Gas),
+		Tokens:   bigint.Wrap(report.Tokens),
+		Purposes: make([]chequebookSpendPurpose, 0, len(report.Purposes)),
+	}
+	for _, p := range report.Purposes {
+		response.Purposes = append(response.Purposes, chequebookSpendPurpose{
+			Purpose: string(p.Purpose),
+			Gas:     bigint.Wrap(p.Gas),
+			Tokens:  bigint.Wrap(p.Tokens),
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
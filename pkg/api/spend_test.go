@@ -0,0 +1,129 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequebookSpend(t *testing.T) {
+	t.Parallel()
+
+	spend, err := analytics.NewSpend(log.Noop, statestore.NewStateStore(), analytics.DefaultWindow, func(context.Context) (*big.Int, error) {
+		return big.NewInt(1000), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer1 := swarm.MustParseHexAddress("aaaa")
+	peer2 := swarm.MustParseHexAddress("bbbb")
+	spend.HandleEvent(events.Event{Type: events.TypeChequeIssued, Peer: peer1, Time: time.Now(), Payout: big.NewInt(100)})
+	spend.HandleEvent(events.Event{Type: events.TypeChequeIssued, Peer: peer2, Time: time.Now(), Payout: big.NewInt(50)})
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:       true,
+		SpendAnalytics: spend,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/spend?top=1", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.ChequebookSpendResponse{
+			Window:           int(analytics.DefaultWindow.Seconds()),
+			Total:            bigint.Wrap(big.NewInt(150)),
+			DailyBurnRate:    bigint.Wrap(big.NewInt(150)),
+			AvailableBalance: bigint.Wrap(big.NewInt(1000)),
+			TimeToEmpty:      int((1000 * 24 * time.Hour / 150).Seconds()),
+			Beneficiaries: []api.ChequebookSpendBeneficiary{
+				{Peer: peer1, Amount: bigint.Wrap(big.NewInt(100))},
+			},
+		}),
+	)
+}
+
+func TestChequebookSpendUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/spend", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "spend analytics unavailable",
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
+
+func TestChequebookSpendPurposes(t *testing.T) {
+	t.Parallel()
+
+	purposes, err := analytics.NewPurposes(log.Noop, statestore.NewStateStore(), analytics.DefaultWindow,
+		transactionmock.New(), backendmock.New(),
+		func(*transaction.TxRequest) analytics.Purpose { return analytics.PurposeDeposit },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	purposes.HandleEvent(events.Event{Type: events.TypeDeposited, Time: time.Now(), Payout: big.NewInt(100)})
+	purposes.HandleEvent(events.Event{Type: events.TypeWithdrawn, Time: time.Now().Add(-2 * 24 * time.Hour), Payout: big.NewInt(50)})
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		SpendPurposes: purposes,
+	})
+
+	t.Run("window", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/spend/purposes?window=3600", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ChequebookSpendPurposesResponse{
+				Window: 3600,
+				Gas:    bigint.Wrap(big.NewInt(0)),
+				Tokens: bigint.Wrap(big.NewInt(100)),
+				Purposes: []api.ChequebookSpendPurpose{
+					{Purpose: "deposit", Gas: bigint.Wrap(big.NewInt(0)), Tokens: bigint.Wrap(big.NewInt(100))},
+				},
+			}),
+		)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/spend/purposes?window=-1", http.StatusBadRequest)
+	})
+}
+
+func TestChequebookSpendPurposesUnavailable(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/spend/purposes", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "spend by purpose unavailable",
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
+)
+
+const errStateStoreUsageUnavailable = "statestore usage not tracked"
+
+type stateStoreUsageResponse struct {
+	Categories []usage.Usage `json:"categories"`
+}
+
+// stateStoreUsageHandler returns the number and size of the settlement
+// records in the statestore by category.
+func (s *Service) stateStoreUsageHandler(w http.ResponseWriter, _ *http.Request) {
+	if s.stateUsage == nil {
+		jsonhttp.MethodNotAllowed(w, errStateStoreUsageUnavailable)
+		return
+	}
+
+	jsonhttp.OK(w, stateStoreUsageResponse{Categories: s.stateUsage.Usage()})
+}
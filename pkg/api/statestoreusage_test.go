@@ -0,0 +1,54 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
+)
+
+func TestStateStoreUsage(t *testing.T) {
+	t.Parallel()
+
+	store, err := usage.New(mock.NewStateStore(),
+		usage.Category{Name: "received_cheques", Prefixes: []string{"swap_chequebook_last_received_cheque_"}},
+		usage.Category{Name: "other", Prefixes: []string{"swap_"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("swap_chequebook_last_received_cheque_a", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:        true,
+		StateStoreUsage: store,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/usage", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.StateStoreUsageResponse{
+			Categories: []usage.Usage{
+				{Category: "received_cheques", Records: 1, Bytes: 41},
+				{Category: "other"},
+			},
+		}),
+	)
+
+	t.Run("not tracked", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/settlements/usage", http.StatusMethodNotAllowed)
+	})
+}
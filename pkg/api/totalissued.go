@@ -0,0 +1,99 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+const (
+	errTotalIssuedCheck     = "cannot check total issued"
+	errTotalIssuedReconcile = "cannot reconcile total issued"
+)
+
+type totalIssuedCheckResponse struct {
+	Stored     *bigint.BigInt `json:"stored"`
+	Computed   *bigint.BigInt `json:"computed"`
+	Difference *bigint.BigInt `json:"difference"`
+	Tolerance  *bigint.BigInt `json:"tolerance"`
+	Consistent bool           `json:"consistent"`
+	Overridden bool           `json:"overridden"`
+}
+
+func newTotalIssuedCheckResponse(check *chequebook.TotalIssuedCheck) totalIssuedCheckResponse {
+	return totalIssuedCheckResponse{
+		Stored:     bigint.Wrap(check.Stored),
+		Computed:   bigint.Wrap(check.Computed),
+		Difference: bigint.Wrap(check.Difference),
+		Tolerance:  bigint.Wrap(check.Tolerance),
+		Consistent: check.Consistent,
+		Overridden: check.Overridden,
+	}
+}
+
+// totalIssuedCheckHandler compares the total issued counter of the chequebook
+// with the sum of the last cheques issued to every beneficiary.
+func (s *Service) totalIssuedCheckHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_chequebook_totalissued").Build()
+
+	if s.issuedGuard == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrConsistencyUnsupported)
+		return
+	}
+
+	check, err := s.issuedGuard.Check(r.Context())
+	if err != nil {
+		logger.Debug("check total issued failed", "error", err)
+		logger.Error(nil, "check total issued failed")
+		jsonhttp.InternalServerError(w, errTotalIssuedCheck)
+		return
+	}
+
+	jsonhttp.OK(w, newTotalIssuedCheckResponse(check))
+}
+
+// totalIssuedReconcileHandler replaces the total issued counter with the sum
+// of the issued cheques, which allows issuing cheques again.
+func (s *Service) totalIssuedReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_totalissued_reconcile").Build()
+
+	if s.issuedGuard == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrConsistencyUnsupported)
+		return
+	}
+
+	check, err := s.issuedGuard.Reconcile(r.Context())
+	if err != nil {
+		logger.Debug("reconcile total issued failed", "error", err)
+		logger.Error(nil, "reconcile total issued failed")
+		jsonhttp.InternalServerError(w, errTotalIssuedReconcile)
+		return
+	}
+
+	logger.Info("total issued reconciled", "total_issued", check.Stored)
+
+	jsonhttp.OK(w, newTotalIssuedCheckResponse(check))
+}
+
+// totalIssuedOverrideHandler allows issuing cheques despite an inconsistent
+// total issued counter.
+func (s *Service) totalIssuedOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_chequebook_totalissued_override").Build()
+
+	if s.issuedGuard == nil {
+		jsonhttp.MethodNotAllowed(w, chequebook.ErrConsistencyUnsupported)
+		return
+	}
+
+	check := s.issuedGuard.Override()
+
+	logger.Warning("total issued consistency check overridden", "stored", check.Stored, "computed", check.Computed)
+
+	jsonhttp.OK(w, newTotalIssuedCheckResponse(check))
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+type totalIssuedGuardMock struct {
+	check *chequebook.TotalIssuedCheck
+}
+
+func (m *totalIssuedGuardMock) Check(context.Context) (*chequebook.TotalIssuedCheck, error) {
+	return m.check, nil
+}
+
+func (m *totalIssuedGuardMock) Reconcile(context.Context) (*chequebook.TotalIssuedCheck, error) {
+	m.check.Stored = m.check.Computed
+	m.check.Difference = big.NewInt(0)
+	m.check.Consistent = true
+	return m.check, nil
+}
+
+func (m *totalIssuedGuardMock) Override() *chequebook.TotalIssuedCheck {
+	m.check.Overridden = true
+	return m.check
+}
+
+func TestTotalIssued(t *testing.T) {
+	t.Parallel()
+
+	guard := &totalIssuedGuardMock{
+		check: &chequebook.TotalIssuedCheck{
+			Stored:     big.NewInt(80),
+			Computed:   big.NewInt(100),
+			Difference: big.NewInt(-20),
+			Tolerance:  big.NewInt(10),
+		},
+	}
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:    true,
+		IssuedGuard: guard,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/totalissued", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.TotalIssuedCheckResponse{
+			Stored:     bigint.Wrap(big.NewInt(80)),
+			Computed:   bigint.Wrap(big.NewInt(100)),
+			Difference: bigint.Wrap(big.NewInt(-20)),
+			Tolerance:  bigint.Wrap(big.NewInt(10)),
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/totalissued/override", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.TotalIssuedCheckResponse{
+			Stored:     bigint.Wrap(big.NewInt(80)),
+			Computed:   bigint.Wrap(big.NewInt(100)),
+			Difference: bigint.Wrap(big.NewInt(-20)),
+			Tolerance:  bigint.Wrap(big.NewInt(10)),
+			Overridden: true,
+		}),
+	)
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/totalissued/reconcile", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.TotalIssuedCheckResponse{
+			Stored:     bigint.Wrap(big.NewInt(100)),
+			Computed:   bigint.Wrap(big.NewInt(100)),
+			Difference: bigint.Wrap(big.NewInt(0)),
+			Tolerance:  bigint.Wrap(big.NewInt(10)),
+			Consistent: true,
+			Overridden: true,
+		}),
+	)
+}
+
+func TestTotalIssuedUnsupported(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/totalissued", http.StatusMethodNotAllowed,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: chequebook.ErrConsistencyUnsupported.Error(),
+			Code:    http.StatusMethodNotAllowed,
+		}),
+	)
+}
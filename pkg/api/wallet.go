@@ -9,12 +9,14 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
 
 type walletResponse struct {
 	BZZ                       *bigint.BigInt `json:"bzzBalance"`                // the BZZ balance of the wallet associated with the eth address of the node
 	NativeToken               *bigint.BigInt `json:"nativeTokenBalance"`        // the native token balance of the wallet associated with the eth address of the node
+	NativeTokenSymbol         string         `json:"nativeTokenSymbol"`         // the symbol of the native token gas is paid in
 	ChainID                   int64          `json:"chainID"`                   // the id of the blockchain
 	ChequebookContractAddress common.Address `json:"chequebookContractAddress"` // the address of the chequebook contract
 	WalletAddress             common.Address `json:"walletAddress"`             // the address of the bee wallet
@@ -42,6 +44,7 @@ func (s *Service) walletHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, walletResponse{
 		BZZ:                       bigint.Wrap(bzz),
 		NativeToken:               bigint.Wrap(nativeToken),
+		NativeTokenSymbol:         config.NativeTokenSymbol(s.chainID),
 		ChainID:                   s.chainID,
 		ChequebookContractAddress: s.chequebook.Address(),
 		WalletAddress:             s.ethereumAddress,
@@ -32,6 +32,17 @@ type Authenticator interface {
 	Enforce(string, string, string) (bool, error)
 }
 
+// FundsScope is the scope of the endpoints which move chequebook funds or
+// change how they are moved: deposits, withdrawals, cashouts, beneficiary
+// rotations, cheque imports, disputes, the total issued override and the
+// runtime settings which pause the issuance of cheques in an emergency.
+// The handlers of these endpoints check it in addition to the policy of
+// their path, so that a path policy granted by mistake does not expose them.
+const FundsScope = "/scopes/funds"
+
+// scopeAction is the action under which scopes are enforced.
+const scopeAction = "USE"
+
 type authRecord struct {
 	Role   string    `json:"r"`
 	Expiry time.Time `json:"e"`
@@ -280,22 +291,58 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"maintainer", "/balances", "GET"},
 		{"maintainer", "/balances/*", "GET"},
 		{"maintainer", "/accounting", "GET"},
+		{"treasurer", "/chequebook/cashout", "POST"},
 		{"maintainer", "/chequebook/cashout/*", "GET"},
-		{"accountant", "/chequebook/cashout/*", "POST"},
-		{"accountant", "/chequebook/withdraw", "POST"},
-		{"accountant", "/chequebook/withdraw?*", "POST"},
-		{"accountant", "/chequebook/deposit", "POST"},
-		{"accountant", "/chequebook/deposit?*", "POST"},
+		{"treasurer", "/chequebook/cashout/*", "POST"},
+		{"maintainer", "/chequebook/cashouts/*", "GET"},
+		{"maintainer", "/chequebook/assignment/*", "GET"},
+		{"treasurer", "/chequebook/assignment/*", "POST"},
+		{"treasurer", "/chequebook/assignment/*", "DELETE"},
+		{"maintainer", "/chequebook/reconciliation", "GET"},
+		{"treasurer", "/chequebook/withdraw", "POST"},
+		{"treasurer", "/chequebook/withdraw?*", "POST"},
+		{"treasurer", "/chequebook/deposit", "POST"},
+		{"treasurer", "/chequebook/deposit?*", "POST"},
+		{"treasurer", "/chequebook/deposit/split", "POST"},
+		{"treasurer", "/chequebook/deposit/split?*", "POST"},
+		{"maintainer", "/chequebook/deposit/*/progress", "GET"},
+		{"treasurer", "/chequebook/selftest", "POST"},
+		{"treasurer", "/chequebook/selftest?*", "POST"},
 		{"maintainer", "/chequebook/cheque/*", "GET"},
 		{"maintainer", "/chequebook/cheque", "GET"},
+		{"maintainer", "/chequebook/cheque?*", "GET"},
+		{"maintainer", "/chequebook/factories", "GET"},
+		{"maintainer", "/chequebook/verification", "POST"},
+		{"accountant", "/chequebook/factories", "PUT"},
+		{"accountant", "/chequebook/signer/passphrase", "PUT"},
+		{"maintainer", "/chequebook/totalissued", "GET"},
+		{"accountant", "/chequebook/totalissued/reconcile", "POST"},
+		{"treasurer", "/chequebook/totalissued/override", "POST"},
+		{"maintainer", "/chequebook/spend", "GET"},
+		{"maintainer", "/chequebook/spend/purposes", "GET"},
+		{"maintainer", "/chequebook/earnings", "GET"},
+		{"maintainer", "/chequebook/beneficiary", "GET"},
+		{"treasurer", "/chequebook/beneficiary", "PUT"},
 		{"maintainer", "/chequebook/address", "GET"},
+		{"maintainer", "/chequebook/contract", "GET"},
+		{"maintainer", "/chequebook/contract/*", "GET"},
+		{"maintainer", "/chequebook/history/*", "GET"},
+		{"maintainer", "/chequebook/token", "GET"},
+		{"maintainer", "/chequebook/deposits", "GET"},
 		{"maintainer", "/chequebook/balance", "GET"},
 		{"maintainer", "/wallet", "GET"},
 		{"maintainer", "/chunks/*", "(GET)|(DELETE)"},
 		{"maintainer", "/reservestate", "GET"},
 		{"maintainer", "/chainstate", "GET"},
 		{"maintainer", "/settlements/*", "GET"},
+		{"treasurer", "/settlements/import", "POST"},
+		{"treasurer", "/settlements/disputes", "POST"},
+		{"treasurer", "/settlements/disputes/*", "POST"},
+		{"treasurer", "/settlements/config", "PATCH"},
 		{"maintainer", "/settlements", "GET"},
+		{"maintainer", "/settlements/audit?*", "GET"},
+		{"maintainer", "/settlements/ledger?*", "GET"},
+		{"maintainer", "/settlements/summary?*", "GET"},
 		{"maintainer", "/transactions", "GET"},
 		{"consumer", "/transactions/*", "GET"},
 		{"accountant", "/transactions/*", "(POST)|(DELETE)"},
@@ -305,17 +352,21 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"creator", "/stewardship/*", "GET"},
 		{"consumer", "/stewardship/*", "PUT"},
 		{"maintainer", "/redistributionstate", "GET"},
+		{"treasurer", FundsScope, scopeAction},
 	})
 
 	if err != nil {
 		return err
 	}
 
-	// consumer > creator > accountant > maintainer
+	// consumer > creator > accountant > maintainer > treasurer
+	// Only the treasurer moves funds out of or into the chequebook, so tokens
+	// of the other roles can be handed to dashboards.
 	_, err = e.AddGroupingPolicies([][]string{
 		{"creator", "consumer"},
 		{"accountant", "creator"},
 		{"maintainer", "accountant"},
+		{"treasurer", "maintainer"},
 	})
 
 	return err
@@ -127,6 +127,46 @@ func TestEnforce(t *testing.T) {
 			resource: "/pingpong/someone",
 			action:   "DELETE",
 		},
+		{
+			desc:     "maintainer reads chequebook balance",
+			role:     "maintainer",
+			resource: "/chequebook/balance",
+			action:   "GET",
+			expected: true,
+		},
+		{
+			desc:     "maintainer cannot withdraw",
+			role:     "maintainer",
+			resource: "/chequebook/withdraw?amount=1",
+			action:   "POST",
+		},
+		{
+			desc:     "accountant cannot cash out",
+			role:     "accountant",
+			resource: "/chequebook/cashout/someone",
+			action:   "POST",
+		},
+		{
+			desc:     "treasurer deposits",
+			role:     "treasurer",
+			resource: "/v1/chequebook/deposit?amount=1",
+			action:   "POST",
+			expected: true,
+		},
+		{
+			desc:     "maintainer simulates settlements",
+			role:     "maintainer",
+			resource: "/settlements/simulation?threshold=1",
+			action:   "GET",
+			expected: true,
+		},
+		{
+			desc:     "treasurer inherits maintainer",
+			role:     "treasurer",
+			resource: "/balances",
+			action:   "GET",
+			expected: true,
+		},
 	}
 
 	for _, tC := range tt {
@@ -152,3 +192,58 @@ func TestEnforce(t *testing.T) {
 		})
 	}
 }
+
+func TestEnforceFunds(t *testing.T) {
+	t.Parallel()
+
+	a, err := auth.New(encryptionKey, passwordHash, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources := []struct {
+		resource, action string
+	}{
+		{"/chequebook/deposit?amount=1", "POST"},
+		{"/chequebook/deposit/split?amount=1", "POST"},
+		{"/chequebook/withdraw?amount=1", "POST"},
+		{"/chequebook/selftest", "POST"},
+		{"/chequebook/cashout", "POST"},
+		{"/chequebook/cashout/someone", "POST"},
+		{"/chequebook/cashout/someone/chequebooks", "POST"},
+		{"/chequebook/cashout/someone/retry", "POST"},
+		{"/chequebook/assignment/someone", "POST"},
+		{"/chequebook/assignment/someone", "DELETE"},
+		{"/chequebook/beneficiary", "PUT"},
+		{"/chequebook/totalissued/override", "POST"},
+		{"/settlements/import", "POST"},
+		{"/settlements/disputes", "POST"},
+		{"/settlements/disputes/1/resolve", "POST"},
+		{"/settlements/config", "PATCH"},
+		{auth.FundsScope, "USE"},
+	}
+
+	roles := map[string]bool{
+		"consumer":   false,
+		"creator":    false,
+		"accountant": false,
+		"maintainer": false,
+		"treasurer":  true,
+	}
+
+	for role, expected := range roles {
+		apiKey, err := a.GenerateKey(role, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range resources {
+			result, err := a.Enforce(apiKey, r.resource, r.action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != expected {
+				t.Errorf("request from user with %s on object %s %s: expected %v, got %v", role, r.action, r.resource, expected, result)
+			}
+		}
+	}
+}
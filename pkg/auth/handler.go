@@ -19,38 +19,57 @@ type auth interface {
 func PermissionCheckHandler(auth auth) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			reqToken := r.Header.Get("Authorization")
-			if !strings.HasPrefix(reqToken, "Bearer ") {
-				jsonhttp.Forbidden(w, "Missing bearer token")
-				return
-			}
-
-			keys := strings.Split(reqToken, "Bearer ")
-
-			if len(keys) != 2 || strings.Trim(keys[1], " ") == "" {
-				jsonhttp.Unauthorized(w, "Missing security token")
-				return
-			}
-
-			apiKey := keys[1]
-
-			allowed, err := auth.Enforce(apiKey, r.URL.Path, r.Method)
-			if errors.Is(err, ErrTokenExpired) {
-				jsonhttp.Unauthorized(w, "Token expired")
-				return
-			}
-
-			if err != nil {
-				jsonhttp.InternalServerError(w, "Error occurred while validating the security token")
-				return
-			}
-
-			if !allowed {
-				jsonhttp.Forbidden(w, "Provided security token does not grant access to the resource")
-				return
-			}
-
-			h.ServeHTTP(w, r)
+			enforce(w, r, h, func(apiKey string) (bool, error) {
+				return auth.Enforce(apiKey, r.URL.Path, r.Method)
+			})
 		})
 	}
 }
+
+// ScopeCheckHandler only passes requests whose security token grants the
+// scope, independently of the path of the request.
+func ScopeCheckHandler(auth auth, scope string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enforce(w, r, h, func(apiKey string) (bool, error) {
+				return auth.Enforce(apiKey, scope, scopeAction)
+			})
+		})
+	}
+}
+
+// enforce passes the request to h if the check of its bearer token succeeds.
+func enforce(w http.ResponseWriter, r *http.Request, h http.Handler, check func(apiKey string) (bool, error)) {
+	reqToken := r.Header.Get("Authorization")
+	if !strings.HasPrefix(reqToken, "Bearer ") {
+		jsonhttp.Forbidden(w, "Missing bearer token")
+		return
+	}
+
+	keys := strings.Split(reqToken, "Bearer ")
+
+	if len(keys) != 2 || strings.Trim(keys[1], " ") == "" {
+		jsonhttp.Unauthorized(w, "Missing security token")
+		return
+	}
+
+	apiKey := keys[1]
+
+	allowed, err := check(apiKey)
+	if errors.Is(err, ErrTokenExpired) {
+		jsonhttp.Unauthorized(w, "Token expired")
+		return
+	}
+
+	if err != nil {
+		jsonhttp.InternalServerError(w, "Error occurred while validating the security token")
+		return
+	}
+
+	if !allowed {
+		jsonhttp.Forbidden(w, "Provided security token does not grant access to the resource")
+		return
+	}
+
+	h.ServeHTTP(w, r)
+}
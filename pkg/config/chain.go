@@ -20,6 +20,9 @@ type ChainConfig struct {
 	NativeTokenSymbol      string
 	SwarmTokenSymbol       string
 
+	// Finality.
+	Confirmations uint64 // blocks after which transactions are final, 0 for chains with instant finality
+
 	// Addresses.
 	StakingAddress         common.Address
 	PostageStampAddress    common.Address
@@ -27,6 +30,7 @@ type ChainConfig struct {
 	SwapPriceOracleAddress common.Address
 	CurrentFactoryAddress  common.Address
 	LegacyFactoryAddresses []common.Address
+	MulticallAddress       common.Address
 
 	// ABIs.
 	StakingABI        string
@@ -34,6 +38,81 @@ type ChainConfig struct {
 	RedistributionABI string
 }
 
+// multicall3Address is the address of the Multicall3 contract, which is
+// deployed at the same address on most chains.
+var multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// DefaultConfirmations is the number of blocks after which transactions are
+// considered final on chains with probabilistic finality.
+const DefaultConfirmations = 12
+
+// chainConfirmations are the blocks after which transactions are final on
+// known chains other than the ones with a ChainConfig. Rollups are final once
+// the sequencer included the transaction, reorgs of their blocks are not expected.
+var chainConfirmations = map[int64]uint64{
+	1:        DefaultConfirmations, // Ethereum
+	11155111: DefaultConfirmations, // Sepolia
+	137:      128,                  // Polygon PoS
+	80001:    128,                  // Polygon Mumbai
+	10:       0,                    // Optimism
+	420:      0,                    // Optimism Goerli
+	42161:    0,                    // Arbitrum One
+	421613:   0,                    // Arbitrum Goerli
+	8453:     0,                    // Base
+	84531:    0,                    // Base Goerli
+	1337:     0,                    // local development chains
+	31337:    0,                    // local development chains
+}
+
+// Confirmations returns the number of blocks after which transactions on the
+// chain are final, DefaultConfirmations for unknown chains.
+func Confirmations(chainID int64) uint64 {
+	switch chainID {
+	case Testnet.ChainID:
+		return Testnet.Confirmations
+	case Mainnet.ChainID:
+		return Mainnet.Confirmations
+	}
+	if confirmations, ok := chainConfirmations[chainID]; ok {
+		return confirmations
+	}
+	return DefaultConfirmations
+}
+
+// DefaultNativeTokenSymbol is the symbol of the native token of unknown chains.
+const DefaultNativeTokenSymbol = "ETH"
+
+// nativeTokenSymbols are the symbols of the native token gas is paid in on
+// known chains other than the ones with a ChainConfig.
+var nativeTokenSymbols = map[int64]string{
+	1:        "ETH",   // Ethereum
+	11155111: "ETH",   // Sepolia
+	10200:    "xDAI",  // Gnosis Chiado
+	137:      "MATIC", // Polygon PoS
+	80001:    "MATIC", // Polygon Mumbai
+	10:       "ETH",   // Optimism
+	420:      "ETH",   // Optimism Goerli
+	42161:    "ETH",   // Arbitrum One
+	421613:   "ETH",   // Arbitrum Goerli
+	8453:     "ETH",   // Base
+	84531:    "ETH",   // Base Goerli
+}
+
+// NativeTokenSymbol returns the symbol of the native token gas is paid in on
+// the chain, DefaultNativeTokenSymbol for unknown chains.
+func NativeTokenSymbol(chainID int64) string {
+	switch chainID {
+	case Testnet.ChainID:
+		return Testnet.NativeTokenSymbol
+	case Mainnet.ChainID:
+		return Mainnet.NativeTokenSymbol
+	}
+	if symbol, ok := nativeTokenSymbols[chainID]; ok {
+		return symbol
+	}
+	return DefaultNativeTokenSymbol
+}
+
 var (
 	Testnet = ChainConfig{
 		ChainID:                abi.TestnetChainID,
@@ -41,6 +120,8 @@ var (
 		NativeTokenSymbol:      "ETH",
 		SwarmTokenSymbol:       "gBZZ",
 
+		Confirmations: DefaultConfirmations,
+
 		StakingAddress:         common.HexToAddress(abi.TestnetStakingAddress),
 		PostageStampAddress:    common.HexToAddress(abi.TestnetPostageStampStampAddress),
 		RedistributionAddress:  common.HexToAddress(abi.TestnetRedistributionAddress),
@@ -49,6 +130,7 @@ var (
 		LegacyFactoryAddresses: []common.Address{
 			common.HexToAddress("0xf0277caffea72734853b834afc9892461ea18474"),
 		},
+		MulticallAddress: multicall3Address,
 
 		StakingABI:        abi.TestnetStakingABI,
 		PostageStampABI:   abi.TestnetPostageStampStampABI,
@@ -61,11 +143,14 @@ var (
 		NativeTokenSymbol:      "xDAI",
 		SwarmTokenSymbol:       "xBZZ",
 
+		Confirmations: DefaultConfirmations,
+
 		StakingAddress:         common.HexToAddress(abi.MainnetStakingAddress),
 		PostageStampAddress:    common.HexToAddress(abi.MainnetPostageStampStampAddress),
 		RedistributionAddress:  common.HexToAddress(abi.MainnetRedistributionAddress),
 		SwapPriceOracleAddress: common.HexToAddress("0x0FDc5429C50e2a39066D8A94F3e2D2476fcc3b85"),
 		CurrentFactoryAddress:  common.HexToAddress("0xc2d5a532cf69aa9a1378737d8ccdef884b6e7420"),
+		MulticallAddress:       multicall3Address,
 
 		StakingABI:        abi.MainnetStakingABI,
 		PostageStampABI:   abi.MainnetPostageStampStampABI,
@@ -81,8 +166,9 @@ func GetByChainID(chainID int64) (ChainConfig, bool) {
 		return Mainnet, true
 	default:
 		return ChainConfig{
-			NativeTokenSymbol: Testnet.NativeTokenSymbol,
+			NativeTokenSymbol: NativeTokenSymbol(chainID),
 			SwarmTokenSymbol:  Testnet.SwarmTokenSymbol,
+			Confirmations:     Confirmations(chainID),
 			StakingABI:        abi.TestnetStakingABI,
 			PostageStampABI:   abi.TestnetPostageStampStampABI,
 			RedistributionABI: abi.TestnetRedistributionABI,
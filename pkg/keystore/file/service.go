@@ -83,6 +83,42 @@ func (s *Service) Key(name, password string, edg keystore.EDG) (pk *ecdsa.Privat
 	return pk, false, nil
 }
 
+// ChangePassword re-encrypts the key file with the new password. The new file
+// is written next to the old one and renamed over it, so that the key is not
+// lost if the node stops in between.
+func (s *Service) ChangePassword(name, oldPassword, newPassword string, edg keystore.EDG) error {
+	filename := s.keyFilename(name)
+
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read private key: %w", err)
+	}
+	if len(data) == 0 {
+		return keystore.ErrKeyNotFound
+	}
+
+	pk, err := decryptKey(data, oldPassword, edg)
+	if err != nil {
+		return err
+	}
+
+	d, err := encryptKey(pk, newPassword, edg)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, d, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
 func (s *Service) keyFilename(name string) string {
 	return filepath.Join(s.dir, fmt.Sprintf("%s.key", name))
 }
@@ -13,6 +13,10 @@ import (
 // private key is stored is not valid.
 var ErrInvalidPassword = errors.New("invalid password")
 
+// ErrKeyNotFound is returned when the private key with the specified name does
+// not exist.
+var ErrKeyNotFound = errors.New("key not found")
+
 // EDG represents and encoder/decoder/generator for ECDSA private keys
 type EDG interface {
 	Generate() (*ecdsa.PrivateKey, error)
@@ -31,4 +35,8 @@ type Service interface {
 	Exists(name string) (bool, error)
 	// SetKey generates and persists a new private key
 	SetKey(name, password string, edg EDG) (*ecdsa.PrivateKey, error)
+	// ChangePassword re-encrypts the private key with the specified name with
+	// the new password. It returns ErrInvalidPassword if the old password
+	// does not decrypt the key.
+	ChangePassword(name, oldPassword, newPassword string, edg EDG) error
 }
@@ -81,6 +81,24 @@ func (s *Service) Key(name, password string, edg keystore.EDG) (pk *ecdsa.Privat
 	return k.pk, created, nil
 }
 
+func (s *Service) ChangePassword(name, oldPassword, newPassword string, edg keystore.EDG) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.m[name]
+	if !ok {
+		return keystore.ErrKeyNotFound
+	}
+	if k.password != oldPassword {
+		return keystore.ErrInvalidPassword
+	}
+
+	k.password = newPassword
+	s.m[name] = k
+
+	return nil
+}
+
 type key struct {
 	pk       *ecdsa.PrivateKey
 	password string
@@ -87,4 +87,32 @@ func Service(t *testing.T, s keystore.Service) {
 	if !bytes.Equal(k3.D.Bytes(), k4.D.Bytes()) {
 		t.Fatal("two keys are not equal")
 	}
+
+	// change the password of the libp2p key
+	err = s.ChangePassword("libp2p", "invalid password", "new p2p pass", edg)
+	if !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatal(err)
+	}
+	if err := s.ChangePassword("libp2p", "p2p pass", "new p2p pass", edg); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = s.Key("libp2p", "p2p pass", edg)
+	if !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatal(err)
+	}
+	k5, created, err := s.Key("libp2p", "new p2p pass", edg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("key is created, but should not be")
+	}
+	if !bytes.Equal(k3.D.Bytes(), k5.D.Bytes()) {
+		t.Fatal("key changed with the password")
+	}
+
+	err = s.ChangePassword("unknown", "pass", "new pass", edg)
+	if !errors.Is(err, keystore.ErrKeyNotFound) {
+		t.Fatal(err)
+	}
 }
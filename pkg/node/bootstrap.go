@@ -77,6 +77,7 @@ func bootstrapNode(
 	cashoutService chequebook.CashoutService,
 	transactionService transaction.Service,
 	stateStore storage.StateStorer,
+	settlementStore storage.StateStorer,
 	signer crypto.Signer,
 	networkID uint64,
 	logger log.Logger,
@@ -158,7 +159,7 @@ func bootstrapNode(
 		o.PaymentTolerance,
 		o.PaymentEarly,
 		logger,
-		stateStore,
+		settlementStore,
 		pricing,
 		big.NewInt(refreshRate),
 		lightFactor,
@@ -172,7 +173,7 @@ func bootstrapNode(
 	// bootstraper mode uses the light node refresh rate
 	enforcedRefreshRate := big.NewInt(lightRefreshRate)
 
-	pseudosettleService := pseudosettle.New(p2ps, logger, stateStore, acc, enforcedRefreshRate, enforcedRefreshRate, p2ps)
+	pseudosettleService := pseudosettle.New(p2ps, logger, settlementStore, acc, enforcedRefreshRate, enforcedRefreshRate, p2ps)
 	if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
 		return nil, fmt.Errorf("pseudosettle service: %w", err)
 	}
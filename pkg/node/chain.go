@@ -5,11 +5,15 @@
 package node
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -17,21 +21,32 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/settlement"
 	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mpcsigner"
 	"github.com/ethersphere/bee/pkg/settlement/swap/erc20"
 	"github.com/ethersphere/bee/pkg/settlement/swap/priceoracle"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
 	"github.com/ethersphere/bee/pkg/settlement/swap/swapprotocol"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/chaos"
+	"github.com/ethersphere/bee/pkg/transaction/gascap"
+	"github.com/ethersphere/bee/pkg/transaction/private"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
+	"github.com/ethersphere/bee/pkg/transaction/rollup"
+	"github.com/ethersphere/bee/pkg/transaction/rpcauth"
+	"github.com/ethersphere/bee/pkg/transaction/userop"
 	"github.com/ethersphere/bee/pkg/transaction/wrapped"
 	"github.com/ethersphere/go-sw3-abi/sw3abi"
 	"github.com/prometheus/client_golang/prometheus"
@@ -45,57 +60,125 @@ const (
 
 // InitChain will initialize the Ethereum backend at the given endpoint and
 // set up the Transaction Service to interact with it using the provided signer.
+// RPCAuthOptions returns the authentication of the blockchain endpoint from
+// headers given as "name: value", basic auth credentials given as
+// "username:password" and the path of a file holding a hex encoded JWT secret.
+func RPCAuthOptions(headers []string, basicAuth, jwtSecretPath string) (rpcauth.Options, error) {
+	var (
+		o   rpcauth.Options
+		err error
+	)
+	if len(headers) > 0 {
+		if o.Headers, err = rpcauth.ParseHeaders(headers); err != nil {
+			return rpcauth.Options{}, err
+		}
+	}
+	if basicAuth != "" {
+		if o.Username, o.Password, err = rpcauth.ParseBasicAuth(basicAuth); err != nil {
+			return rpcauth.Options{}, err
+		}
+	}
+	if jwtSecretPath != "" {
+		if o.JWTSecret, err = rpcauth.LoadJWTSecret(jwtSecretPath); err != nil {
+			return rpcauth.Options{}, fmt.Errorf("jwt secret: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// RPCRetryOptions builds the retry options of the blockchain backend from
+// the default number of attempts, the backoff bounds, per call attempts
+// given as method=attempts and the adaptive timeouts.
+func RPCRetryOptions(attempts int, backoff, maxBackoff time.Duration, calls []string, timeouts retry.TimeoutOptions) (retry.Options, error) {
+	if attempts < 1 {
+		return retry.Options{}, fmt.Errorf("invalid rpc retries %d", attempts)
+	}
+	if timeouts.Max > 0 && timeouts.Min > timeouts.Max {
+		return retry.Options{}, fmt.Errorf("rpc min timeout %s exceeds max timeout %s", timeouts.Min, timeouts.Max)
+	}
+	o := retry.DefaultOptions()
+	o.Default.MaxAttempts = attempts
+	o.Default.InitialBackoff = backoff
+	o.Default.MaxBackoff = maxBackoff
+	o.Timeouts = timeouts
+	if err := o.ParseCallAttempts(calls); err != nil {
+		return retry.Options{}, err
+	}
+	return o, nil
+}
+
+// InitChain connects to the chain backend and creates the runtime shared by
+// all services sending transactions through it, together with the transaction
+// service of the signer.
 func InitChain(
 	ctx context.Context,
 	logger log.Logger,
 	stateStore storage.StateStorer,
 	endpoint string,
+	rpcAuth rpcauth.Options,
+	rpcRetry retry.Options,
+	privateRelay private.Options,
 	oChainID int64,
 	signer crypto.Signer,
 	pollingInterval time.Duration,
 	chainEnabled bool,
-) (transaction.Backend, common.Address, int64, transaction.Monitor, transaction.Service, error) {
+) (*transaction.Runtime, common.Address, transaction.Service, error) {
 	var backend transaction.Backend = &noOpChainBackend{
 		chainID: oChainID,
 	}
 
 	if chainEnabled {
 		// connect to the real one
-		rpcClient, err := rpc.DialContext(ctx, endpoint)
+		rpcClient, err := rpcauth.Dial(ctx, endpoint, rpcAuth)
 		if err != nil {
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("dial eth client: %w", err)
+			return nil, common.Address{}, nil, fmt.Errorf("dial eth client: %w", err)
 		}
 
 		var versionString string
 		err = rpcClient.CallContext(ctx, &versionString, "web3_clientVersion")
 		if err != nil {
 			logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, goerli in testnet) is required; check your node or specify another node using --swap-endpoint.", "backend_endpoint", endpoint)
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("eth client get version: %w", err)
+			return nil, common.Address{}, nil, fmt.Errorf("eth client get version: %w", err)
 		}
 
 		logger.Info("connected to ethereum backend", "version", versionString)
 
-		backend = wrapped.NewBackend(ethclient.NewClient(rpcClient))
+		rpcBackend, err := chaos.Wrap(logger, wrapped.NewBackend(ethclient.NewClient(rpcClient)))
+		if err != nil {
+			return nil, common.Address{}, nil, fmt.Errorf("fault injection: %w", err)
+		}
+
+		backend = retry.NewBackend(rpcBackend, rpcRetry)
+
+		if privateRelay.Endpoint != "" {
+			relay, err := private.DialRelay(ctx, privateRelay.Endpoint)
+			if err != nil {
+				return nil, common.Address{}, nil, fmt.Errorf("dial private relay: %w", err)
+			}
+			backend = private.NewBackend(logger, backend, relay, privateRelay.Deadline, pollingInterval)
+			logger.Info("transactions requesting a private submission are sent through the private relay", "deadline", privateRelay.Deadline)
+		}
 	}
 
 	chainID, err := backend.ChainID(ctx)
 	if err != nil {
-		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("get chain id: %w", err)
+		return nil, common.Address{}, nil, fmt.Errorf("get chain id: %w", err)
 	}
 
 	overlayEthAddress, err := signer.EthereumAddress()
 	if err != nil {
-		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("eth address: %w", err)
+		return nil, common.Address{}, nil, fmt.Errorf("eth address: %w", err)
 	}
 
-	transactionMonitor := transaction.NewMonitor(logger, backend, overlayEthAddress, pollingInterval, cancellationDepth)
+	runtime := transaction.NewRuntime(logger, backend, stateStore, chainID, pollingInterval, cancellationDepth)
 
-	transactionService, err := transaction.NewService(logger, backend, signer, stateStore, chainID, transactionMonitor)
+	transactionService, _, err := runtime.Service(signer)
 	if err != nil {
-		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("new transaction service: %w", err)
+		_ = runtime.Close()
+		return nil, common.Address{}, nil, fmt.Errorf("new transaction service: %w", err)
 	}
 
-	return backend, overlayEthAddress, chainID.Int64(), transactionMonitor, transactionService, nil
+	return runtime, overlayEthAddress, transactionService, nil
 }
 
 // InitChequebookFactory will initialize the chequebook factory with the given
@@ -107,6 +190,7 @@ func InitChequebookFactory(
 	transactionService transaction.Service,
 	factoryAddress string,
 	legacyFactoryAddresses []string,
+	depositOnDeploy bool,
 ) (chequebook.Factory, error) {
 	var currentFactory common.Address
 	var legacyFactories []common.Address
@@ -140,6 +224,16 @@ func InitChequebookFactory(
 		}
 	}
 
+	if depositOnDeploy {
+		logger.Info("funding new chequebooks at deployment", "factory_address", currentFactory)
+		return chequebook.NewDepositFactory(
+			backend,
+			transactionService,
+			currentFactory,
+			legacyFactories,
+		), nil
+	}
+
 	return chequebook.NewFactory(
 		backend,
 		transactionService,
@@ -148,24 +242,339 @@ func InitChequebookFactory(
 	), nil
 }
 
+// initMulticallAddress determines the address of the Multicall3 contract used
+// to bundle cashouts and chequebook verifications. If there is none, they are
+// done one by one.
+func initMulticallAddress(logger log.Logger, chainID int64, multicallAddress string) (common.Address, error) {
+	if multicallAddress == "" {
+		chainCfg, found := config.GetByChainID(chainID)
+		if !found || chainCfg.MulticallAddress == (common.Address{}) {
+			logger.Info("no multicall contract for this network, batch cashouts and chequebook verifications are done one by one", "chain_id", chainID)
+			return common.Address{}, nil
+		}
+		return chainCfg.MulticallAddress, nil
+	}
+	if !common.IsHexAddress(multicallAddress) {
+		return common.Address{}, errors.New("malformed multicall address")
+	}
+	logger.Info("using custom multicall address", "multicall_address", multicallAddress)
+	return common.HexToAddress(multicallAddress), nil
+}
+
+// initChequeSigner creates the signer for issued cheques. If a keystore is
+// configured, cheques are signed locally with its key instead of the node key.
+// If a seed file is configured, cheques are signed with the key derived from
+// it, which is then the issuer of the chequebook.
+// If a threshold signing service is configured, cheques are signed by it and
+// the local signer is only used as fallback. The returned rotator is nil if
+// the local signer does not use a keystore and the returned closer is nil for
+// the node key signer.
+func initChequeSigner(logger log.Logger, signer crypto.Signer, chainID int64, issuer common.Address, o *Options) (chequebook.ChequeSigner, chequebook.PassphraseRotator, io.Closer, error) {
+	var (
+		local   = chequebook.NewChequeSigner(signer, chainID)
+		rotator chequebook.PassphraseRotator
+		closers multiCloser
+	)
+	if o.SwapChequeSignerSeedFile != "" {
+		if o.SwapChequeSignerKeystore != "" {
+			return nil, nil, nil, errors.New("cheque signer keystore and seed file cannot be used together")
+		}
+		hdSigner, err := InitHDChequeSigner(o.SwapChequeSignerSeedFile, o.SwapChequeSignerPath, chainID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cheque signer seed: %w", err)
+		}
+		logger.Info("signing cheques with derived key", "derivation_path", hdSigner.Path(), "issuer", hdSigner.Issuer())
+		local, issuer = hdSigner, hdSigner.Issuer()
+		closers = append(closers, hdSigner)
+	}
+	if o.SwapChequeSignerKeystore != "" {
+		keystoreSigner, err := initKeystoreChequeSigner(o.SwapChequeSignerKeystore, o.SwapChequeSignerPasswordFile, issuer, chainID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cheque signer keystore: %w", err)
+		}
+		logger.Info("signing cheques with keystore key", "keystore", o.SwapChequeSignerKeystore)
+		local, rotator = keystoreSigner, keystoreSigner
+		closers = append(closers, keystoreSigner)
+	}
+	if o.SwapMPCSignerEndpoint == "" {
+		return local, rotator, closers.closer(), nil
+	}
+
+	fallback, err := mpcsigner.ParseFallbackPolicy(o.SwapMPCSignerFallback)
+	if err != nil {
+		_ = closers.Close()
+		return nil, nil, nil, fmt.Errorf("mpc signer: %w", err)
+	}
+
+	mpcSigner, err := mpcsigner.New(
+		logger,
+		mpcsigner.NewHTTPCoordinator(o.SwapMPCSignerEndpoint, nil),
+		issuer,
+		chainID,
+		mpcsigner.Options{
+			Participants: o.SwapMPCSignerParticipants,
+			Threshold:    o.SwapMPCSignerThreshold,
+			Timeout:      o.SwapMPCSignerTimeout,
+			Fallback:     fallback,
+			Local:        local,
+		},
+	)
+	if err != nil {
+		_ = closers.Close()
+		return nil, nil, nil, fmt.Errorf("mpc signer: %w", err)
+	}
+	logger.Info("signing cheques with threshold signing service", "endpoint", o.SwapMPCSignerEndpoint, "threshold", o.SwapMPCSignerThreshold, "participants", len(o.SwapMPCSignerParticipants))
+
+	// the threshold signer is closed first as it may still fall back to the local signer
+	closers = append(multiCloser{mpcSigner}, closers...)
+	return mpcSigner, rotator, closers.closer(), nil
+}
+
+// InitHDChequeSigner derives the cheque signing key along path from the
+// hex encoded HD wallet seed stored in seedFile.
+func InitHDChequeSigner(seedFile, path string, chainID int64) (*chequebook.HDChequeSigner, error) {
+	data, err := os.ReadFile(seedFile)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimPrefix(string(bytes.TrimSpace(data)), "0x"))
+	for i := range data {
+		data[i] = 0
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode seed: %w", err)
+	}
+	defer func() {
+		for i := range seed {
+			seed[i] = 0
+		}
+	}()
+	return chequebook.NewHDChequeSigner(seed, path, chainID)
+}
+
+// initKeystoreChequeSigner loads the cheque signing key from the key file at
+// path, decrypted with the passphrase stored in passwordFile.
+func initKeystoreChequeSigner(path, passwordFile string, issuer common.Address, chainID int64) (*chequebook.KeystoreChequeSigner, error) {
+	if passwordFile == "" {
+		return nil, errors.New("password file not set")
+	}
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".key")
+	return chequebook.NewKeystoreChequeSigner(filekeystore.New(filepath.Dir(path)), name, string(bytes.Trim(password, "\n")), issuer, chainID)
+}
+
+// multiCloser closes all of its elements in order.
+type multiCloser []io.Closer
+
+func (c multiCloser) Close() error {
+	var errs []error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closer returns nil if there is nothing to close.
+func (c multiCloser) closer() io.Closer {
+	if len(c) == 0 {
+		return nil
+	}
+	return c
+}
+
+// Operations of the chequebook whose gas price can be capped.
+const (
+	swapOperationDeployment = "deployment"
+	swapOperationDeposit    = "deposit"
+	swapOperationWithdraw   = "withdraw"
+	swapOperationCashout    = "cashout"
+)
+
+// swapOperation determines the chequebook operation of a transaction request
+// from its description.
+func swapOperation(request *transaction.TxRequest) string {
+	switch {
+	case request.Description == "chequebook deployment":
+		return swapOperationDeployment
+	case request.Description == "token transfer":
+		return swapOperationDeposit
+	case strings.HasPrefix(request.Description, "chequebook withdrawal"):
+		return swapOperationWithdraw
+	case request.Description == "cheque cashout", request.Description == "batch cheque cashout":
+		return swapOperationCashout
+	}
+	return ""
+}
+
+// spendPurpose determines the purpose a transaction request is accounted for
+// in the spending report.
+func spendPurpose(request *transaction.TxRequest) analytics.Purpose {
+	switch swapOperation(request) {
+	case swapOperationDeployment:
+		return analytics.PurposeDeploy
+	case swapOperationDeposit:
+		return analytics.PurposeDeposit
+	case swapOperationWithdraw:
+		return analytics.PurposeWithdraw
+	case swapOperationCashout:
+		return analytics.PurposeCashout
+	}
+	if request.Description == "chequebook deployment with deposit" {
+		return analytics.PurposeDeploy
+	}
+	return analytics.PurposeOther
+}
+
+// initGasPriceCaps wraps the transaction service so that chequebook operations
+// wait while the gas price is above their configured cap. The returned service
+// is nil if no caps are configured.
+func initGasPriceCaps(logger log.Logger, transactionService transaction.Service, backend transaction.Backend, o *Options) (transaction.Service, *gascap.Service, error) {
+	if len(o.SwapGasPriceCaps) == 0 {
+		return transactionService, nil, nil
+	}
+
+	caps, err := parseGasPriceCaps(strings.Join(o.SwapGasPriceCaps, ","))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	service := gascap.New(logger, transactionService, backend, gascap.Options{
+		Caps:      caps,
+		Operation: swapOperation,
+		Expiry:    o.SwapGasPriceCapExpiry,
+	})
+	logger.Info("capping gas prices of chequebook operations", "caps", o.SwapGasPriceCaps, "expiry", o.SwapGasPriceCapExpiry)
+
+	return service, service, nil
+}
+
+// initRollup wraps the transaction service so that transactions wait while the
+// sequencer of the rollup is down, if a sequencer uptime feed is configured.
+// L1 data fees are estimated with the configured oracle or the one known for
+// the chain. The returned service is nil on chains without either.
+func initRollup(logger log.Logger, transactionService transaction.Service, chainID int64, o *Options) (transaction.Service, *rollup.Service, error) {
+	var options rollup.Options
+	if o.SwapSequencerUptimeFeed != "" {
+		if !common.IsHexAddress(o.SwapSequencerUptimeFeed) {
+			return nil, nil, fmt.Errorf("invalid sequencer uptime feed address %q", o.SwapSequencerUptimeFeed)
+		}
+		options.UptimeFeed = common.HexToAddress(o.SwapSequencerUptimeFeed)
+		options.GracePeriod = rollup.DefaultGracePeriod
+	}
+	if o.SwapL1FeeOracle != "" {
+		if !common.IsHexAddress(o.SwapL1FeeOracle) {
+			return nil, nil, fmt.Errorf("invalid L1 fee oracle address %q", o.SwapL1FeeOracle)
+		}
+		options.L1FeeOracle = common.HexToAddress(o.SwapL1FeeOracle)
+	} else if oracle, ok := rollup.L1FeeOracle(chainID); ok {
+		options.L1FeeOracle = oracle
+	}
+
+	if options.UptimeFeed == (common.Address{}) && options.L1FeeOracle == (common.Address{}) {
+		return transactionService, nil, nil
+	}
+
+	service := rollup.New(logger, transactionService, options)
+	logger.Info("rollup support enabled", "sequencer_uptime_feed", options.UptimeFeed, "l1_fee_oracle", options.L1FeeOracle)
+
+	return service, service, nil
+}
+
+// cashoutDataFee returns the estimate of the L1 data fee of a cashout used in
+// settlement simulations, nil if the chain is not a rollup with an L1 fee oracle.
+func cashoutDataFee(rollupService *rollup.Service) func(context.Context) (*big.Int, error) {
+	if rollupService == nil {
+		return nil
+	}
+	return func(ctx context.Context) (*big.Int, error) {
+		data, err := chequebook.CashoutCallDataSample()
+		if err != nil {
+			return nil, err
+		}
+		fee, err := rollupService.L1Fee(ctx, data)
+		if errors.Is(err, rollup.ErrNoL1FeeOracle) {
+			return nil, nil
+		}
+		return fee, err
+	}
+}
+
+// initUserOperations returns the transaction service used for chequebook
+// deposits and cashouts and the account holding the deposited tokens. If a
+// bundler is configured, these transactions are sent as user operations of the
+// configured smart contract account. Withdrawals are restricted to the
+// chequebook issuer and are always sent by the node wallet.
+func initUserOperations(
+	ctx context.Context,
+	logger log.Logger,
+	transactionService transaction.Service,
+	backend transaction.Backend,
+	signer crypto.Signer,
+	chainID int64,
+	overlayEthAddress common.Address,
+	o *Options,
+) (transaction.Service, common.Address, io.Closer, error) {
+	if o.SwapUserOperationBundler == "" {
+		return transactionService, overlayEthAddress, nil, nil
+	}
+
+	if !common.IsHexAddress(o.SwapUserOperationAccount) {
+		return nil, common.Address{}, nil, errors.New("malformed user operation account address")
+	}
+	account := common.HexToAddress(o.SwapUserOperationAccount)
+
+	entryPoint := userop.DefaultEntryPoint
+	if o.SwapUserOperationEntryPoint != "" {
+		if !common.IsHexAddress(o.SwapUserOperationEntryPoint) {
+			return nil, common.Address{}, nil, errors.New("malformed user operation entry point address")
+		}
+		entryPoint = common.HexToAddress(o.SwapUserOperationEntryPoint)
+	}
+
+	client, err := userop.Dial(ctx, o.SwapUserOperationBundler)
+	if err != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("dial bundler: %w", err)
+	}
+
+	var paymaster userop.Paymaster
+	if o.SwapUserOperationPaymaster {
+		paymaster = client
+	}
+
+	service := userop.NewService(logger, transactionService, backend, client, signer, big.NewInt(chainID), userop.Options{
+		EntryPoint: entryPoint,
+		Account:    account,
+		Paymaster:  paymaster,
+		// descriptions of the chequebook deposit and the cashout transactions
+		Route: userop.RouteDescriptions("token transfer", "cheque cashout", "batch cheque cashout"),
+	})
+	logger.Info("sending chequebook deposits and cashouts as user operations", "account", account, "entry_point", entryPoint, "sponsored", o.SwapUserOperationPaymaster)
+
+	return service, account, client, nil
+}
+
 // InitChequebookService will initialize the chequebook service with the given
 // chequebook factory and chain backend.
 func InitChequebookService(
 	ctx context.Context,
 	logger log.Logger,
 	stateStore storage.StateStorer,
-	signer crypto.Signer,
+	chequeSigner chequebook.ChequeSigner,
 	chainID int64,
 	backend transaction.Backend,
 	overlayEthAddress common.Address,
+	tokenOwner common.Address,
 	transactionService transaction.Service,
 	chequebookFactory chequebook.Factory,
 	initialDeposit string,
 	deployGasPrice string,
 	erc20Service erc20.Service,
 ) (chequebook.Service, error) {
-	chequeSigner := chequebook.NewChequeSigner(signer, chainID)
-
 	deposit, ok := new(big.Int).SetString(initialDeposit, 10)
 	if !ok {
 		return nil, fmt.Errorf("initial swap deposit \"%s\" cannot be parsed", initialDeposit)
@@ -189,6 +598,7 @@ func InitChequebookService(
 		backend,
 		chainID,
 		overlayEthAddress,
+		tokenOwner,
 		chequeSigner,
 		erc20Service,
 	)
@@ -206,6 +616,9 @@ func initChequeStoreCashout(
 	chainID int64,
 	overlayEthAddress common.Address,
 	transactionService transaction.Service,
+	cashoutSigner chequebook.CashoutSigner,
+	multicallAddress common.Address,
+	validators ...chequebook.ChequeValidator,
 ) (chequebook.ChequeStore, chequebook.CashoutService) {
 	chequeStore := chequebook.NewChequeStore(
 		stateStore,
@@ -214,6 +627,7 @@ func initChequeStoreCashout(
 		overlayEthAddress,
 		transactionService,
 		chequebook.RecoverCheque,
+		validators...,
 	)
 
 	cashout := chequebook.NewCashoutService(
@@ -221,11 +635,23 @@ func initChequeStoreCashout(
 		swapBackend,
 		transactionService,
 		chequeStore,
+		cashoutSigner,
+		multicallAddress,
 	)
 
 	return chequeStore, cashout
 }
 
+// chequeValidators returns the builtin validators received cheques have to
+// pass according to the configuration.
+func chequeValidators(backend transaction.Backend, minChequebookAge uint64) []chequebook.ChequeValidator {
+	var validators []chequebook.ChequeValidator
+	if minChequebookAge > 0 {
+		validators = append(validators, chequebook.NewMinChequebookAgeValidator(backend, minChequebookAge))
+	}
+	return validators
+}
+
 // InitSwap will initialize and register the swap service.
 func InitSwap(
 	p2ps *libp2p.Service,
@@ -240,6 +666,7 @@ func InitSwap(
 	priceOracleAddress string,
 	chainID int64,
 	transactionService transaction.Service,
+	signer crypto.Signer,
 ) (*swap.Service, priceoracle.Service, error) {
 
 	var currentPriceOracleAddress common.Address
@@ -255,7 +682,7 @@ func InitSwap(
 
 	priceOracle := priceoracle.New(logger, currentPriceOracleAddress, transactionService, 300)
 	priceOracle.Start()
-	swapProtocol := swapprotocol.New(p2ps, logger, overlayEthAddress, priceOracle)
+	swapProtocol := swapprotocol.New(p2ps, logger, overlayEthAddress, priceOracle, signer, chainID)
 	swapAddressBook := swap.NewAddressbook(stateStore)
 
 	cashoutAddress := overlayEthAddress
@@ -345,15 +772,21 @@ func GetTxNextBlock(ctx context.Context, logger log.Logger, backend transaction.
 // noOpChequebookService is a noOp implementation for chequebook.Service interface.
 type noOpChequebookService struct{}
 
-func (m *noOpChequebookService) Deposit(context.Context, *big.Int) (hash common.Hash, err error) {
+func (m *noOpChequebookService) Deposit(context.Context, chequebook.Tokens) (hash common.Hash, err error) {
 	return hash, postagecontract.ErrChainDisabled
 }
-func (m *noOpChequebookService) Withdraw(context.Context, *big.Int) (hash common.Hash, err error) {
+func (m *noOpChequebookService) Withdraw(context.Context, chequebook.Tokens) (hash common.Hash, err error) {
 	return hash, postagecontract.ErrChainDisabled
 }
+func (m *noOpChequebookService) SplitDeposit(context.Context, chequebook.Tokens, chequebook.Tokens) (*chequebook.SplitDepositResult, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
 func (m *noOpChequebookService) WaitForDeposit(context.Context, common.Hash) error {
 	return postagecontract.ErrChainDisabled
 }
+func (m *noOpChequebookService) WaitForDepositWithProgress(context.Context, common.Hash, chequebook.DepositProgressFunc) error {
+	return postagecontract.ErrChainDisabled
+}
 func (m *noOpChequebookService) Balance(context.Context) (*big.Int, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
@@ -363,13 +796,41 @@ func (m *noOpChequebookService) AvailableBalance(context.Context) (*big.Int, err
 func (m *noOpChequebookService) Address() common.Address {
 	return common.Address{}
 }
-func (m *noOpChequebookService) Issue(context.Context, common.Address, *big.Int, chequebook.SendChequeFunc) (*big.Int, error) {
+func (m *noOpChequebookService) Issue(context.Context, common.Address, chequebook.Tokens, chequebook.SendChequeFunc) (*big.Int, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) PreviewIssue(context.Context, common.Address, chequebook.Tokens) (*chequebook.IssuePreview, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
 func (m *noOpChequebookService) LastCheque(common.Address) (*chequebook.SignedCheque, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
-func (m *noOpChequebookService) LastCheques() (map[common.Address]*chequebook.SignedCheque, error) {
+func (m *noOpChequebookService) LastCheques(context.Context) (map[common.Address]*chequebook.SignedCheque, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) LastChequesCount(context.Context) (int, error) {
+	return 0, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) LastChequesSnapshot(context.Context) (*chequebook.ChequesSnapshot, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) ChequeHistory(context.Context, common.Address, uint64, uint64) ([]chequebook.IssuedCheque, uint64, error) {
+	return nil, 0, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) ImportLastCheque(context.Context, common.Address, chequebook.Tokens) (*chequebook.SignedCheque, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) Approve(context.Context, common.Address, chequebook.Tokens) (hash common.Hash, err error) {
+	return hash, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) Allowance(context.Context, common.Address) (*big.Int, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+func (m *noOpChequebookService) Token(context.Context) (*chequebook.Token, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (m *noOpChequebookService) DepositHistory(context.Context) ([]chequebook.Deposit, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
 
@@ -402,6 +863,9 @@ func (m noOpChainBackend) SuggestGasPrice(context.Context) (*big.Int, error) {
 func (m noOpChainBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
 	panic("chain no op: SuggestGasPrice")
 }
+func (m noOpChainBackend) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	panic("chain no op: FeeHistory")
+}
 func (m noOpChainBackend) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
 	panic("chain no op: EstimateGas")
 }
@@ -432,3 +896,39 @@ func (m noOpChainBackend) ChainID(context.Context) (*big.Int, error) {
 	return big.NewInt(m.chainID), nil
 }
 func (m noOpChainBackend) Close() {}
+
+// initRegistry sets the registry announced payment details of peers are
+// verified against if a registry address is configured and publishes the
+// payment details of this node in it if requested.
+func initRegistry(ctx context.Context, logger log.Logger, transactionService transaction.Service, swapService *swap.Service, chequebookService chequebook.Service, overlay swarm.Address, overlayEthAddress common.Address, o *Options) error {
+	if o.SwapRegistryAddress == "" {
+		return nil
+	}
+	if !common.IsHexAddress(o.SwapRegistryAddress) {
+		return fmt.Errorf("invalid registry address %q", o.SwapRegistryAddress)
+	}
+
+	r := registry.New(transactionService, common.HexToAddress(o.SwapRegistryAddress), overlayEthAddress)
+	swapService.SetRegistry(r)
+	if !o.SwapRegistryPublish {
+		return nil
+	}
+
+	beneficiaries, err := swapService.Beneficiaries()
+	if err != nil {
+		return err
+	}
+	record := registry.Record{Beneficiary: beneficiaries.Current}
+	if chequebookService != nil {
+		record.Chequebook = chequebookService.Address()
+	}
+
+	txHash, err := r.Publish(ctx, overlay, record)
+	if err != nil {
+		return fmt.Errorf("publish payment details: %w", err)
+	}
+	if txHash != (common.Hash{}) {
+		logger.Info("published payment details in the registry", "chequebook", record.Chequebook, "beneficiary", record.Beneficiary, "tx", txHash)
+	}
+	return nil
+}
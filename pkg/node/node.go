@@ -64,11 +64,18 @@ import (
 	"github.com/ethersphere/bee/pkg/resolver/multiresolver"
 	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/salud"
+	"github.com/ethersphere/bee/pkg/settlement/events"
 	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/remoteverifier"
 	"github.com/ethersphere/bee/pkg/settlement/swap/erc20"
+	"github.com/ethersphere/bee/pkg/settlement/swap/logcursor"
 	"github.com/ethersphere/bee/pkg/settlement/swap/priceoracle"
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/steward"
 	"github.com/ethersphere/bee/pkg/storageincentives"
@@ -80,6 +87,8 @@ import (
 	"github.com/ethersphere/bee/pkg/topology/lightnode"
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/private"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
 	"github.com/ethersphere/bee/pkg/traversal"
 	"github.com/ethersphere/bee/pkg/util"
 	"github.com/ethersphere/bee/pkg/util/ioutil"
@@ -94,42 +103,59 @@ import (
 const LoggerName = "node"
 
 type Bee struct {
-	p2pService               io.Closer
-	p2pHalter                p2p.Halter
-	ctxCancel                context.CancelFunc
-	apiCloser                io.Closer
-	apiServer                *http.Server
-	debugAPIServer           *http.Server
-	resolverCloser           io.Closer
-	errorLogWriter           io.Writer
-	tracerCloser             io.Closer
-	tagsCloser               io.Closer
-	stateStoreCloser         io.Closer
-	localstoreCloser         io.Closer
-	nsCloser                 io.Closer
-	topologyCloser           io.Closer
-	topologyHalter           topology.Halter
-	pusherCloser             io.Closer
-	pullerCloser             io.Closer
-	accountingCloser         io.Closer
-	pullSyncCloser           io.Closer
-	pushSyncCloser           io.Closer
-	retrievalCloser          io.Closer
-	pssCloser                io.Closer
-	closers                  []func()
-	transactionMonitorCloser io.Closer
-	transactionCloser        io.Closer
-	listenerCloser           io.Closer
-	postageServiceCloser     io.Closer
-	priceOracleCloser        io.Closer
-	hiveCloser               io.Closer
-	chainSyncerCloser        io.Closer
-	depthMonitorCloser       io.Closer
-	saludCloser              io.Closer
-	storageIncetivesCloser   io.Closer
-	shutdownInProgress       bool
-	shutdownMutex            sync.Mutex
-	syncingStopped           *util.Signaler
+	p2pService              io.Closer
+	p2pHalter               p2p.Halter
+	ctxCancel               context.CancelFunc
+	apiCloser               io.Closer
+	apiServer               *http.Server
+	debugAPIServer          *http.Server
+	resolverCloser          io.Closer
+	errorLogWriter          io.Writer
+	tracerCloser            io.Closer
+	tagsCloser              io.Closer
+	stateStoreCloser        io.Closer
+	settlementStoreCloser   io.Closer
+	settlementReplicaCloser io.Closer
+	localstoreCloser        io.Closer
+	nsCloser                io.Closer
+	topologyCloser          io.Closer
+	topologyHalter          topology.Halter
+	pusherCloser            io.Closer
+	pullerCloser            io.Closer
+	accountingCloser        io.Closer
+	pullSyncCloser          io.Closer
+	pushSyncCloser          io.Closer
+	retrievalCloser         io.Closer
+	pssCloser               io.Closer
+	closers                 []func()
+	chainRuntimeCloser      io.Closer
+	transactionCloser       io.Closer
+	listenerCloser          io.Closer
+	postageServiceCloser    io.Closer
+	priceOracleCloser       io.Closer
+	chequeSignerCloser      io.Closer
+	userOperationCloser     io.Closer
+	gasPriceCapCloser       io.Closer
+	rollupCloser            io.Closer
+	settlementEventsCloser  io.Closer
+	settlementWorkersCloser io.Closer
+	settlementDrain         func(context.Context) error
+	settlementDrainTimeout  time.Duration
+	statementsCloser        io.Closer
+	graceCloser             io.Closer
+	withdrawWatchCloser     io.Closer
+	bounceWatchCloser       io.Closer
+	staleCleanupCloser      io.Closer
+	autoCashoutCloser       io.Closer
+	cashoutOptimizerCloser  io.Closer
+	hiveCloser              io.Closer
+	chainSyncerCloser       io.Closer
+	depthMonitorCloser      io.Closer
+	saludCloser             io.Closer
+	storageIncetivesCloser  io.Closer
+	shutdownInProgress      bool
+	shutdownMutex           sync.Mutex
+	syncingStopped          *util.Signaler
 }
 
 type Options struct {
@@ -158,11 +184,81 @@ type Options struct {
 	RetrievalCaching              bool
 	BootnodeMode                  bool
 	BlockchainRpcEndpoint         string
+	BlockchainRpcHeaders          []string
+	BlockchainRpcBasicAuth        string
+	BlockchainRpcJWTSecret        string
+	BlockchainRpcRetries          int
+	BlockchainRpcRetryBackoff     time.Duration
+	BlockchainRpcRetryMaxBackoff  time.Duration
+	BlockchainRpcCallRetries      []string
+	BlockchainRpcTimeoutMargin    time.Duration
+	BlockchainRpcMinTimeout       time.Duration
+	BlockchainRpcMaxTimeout       time.Duration
+	BlockchainRpcPrivateRelay     string
+	BlockchainRpcPrivateDeadline  time.Duration
 	SwapFactoryAddress            string
 	SwapLegacyFactoryAddresses    []string
 	SwapInitialDeposit            string
+	SwapMulticallAddress          string
+	SwapMPCSignerEndpoint         string
+	SwapMPCSignerParticipants     []string
+	SwapMPCSignerThreshold        int
+	SwapMPCSignerTimeout          time.Duration
+	SwapMPCSignerFallback         string
+	SwapChequeSignerKeystore      string
+	SwapChequeSignerPasswordFile  string
+	SwapChequeSignerSeedFile      string
+	SwapChequeSignerPath          string
+	SwapChequeVerifierEndpoint    string
+	SwapUserOperationBundler      string
+	SwapUserOperationEntryPoint   string
+	SwapUserOperationAccount      string
+	SwapUserOperationPaymaster    bool
+	SwapGasPriceCaps              []string
+	SwapGasPriceCapExpiry         time.Duration
+	SwapChequeRateInterval        time.Duration
+	SwapChequeRateBurst           int
+	SwapChequeGranularity         string
+	SwapTotalIssuedTolerance      string
+	SwapBalanceDeclineMax         string
+	SwapBalanceDeclineWindow      time.Duration
+	SwapBalanceDeclinePause       bool
+	SwapPaymentBatchWindow        time.Duration
 	SwapEnable                    bool
+	SwapBlocklistDuration         time.Duration
+	SwapWorkers                   int
+	SwapWorkerQueueSize           int
+	SwapDrainTimeout              time.Duration
+	SwapDrainMinDebt              string
+	SwapMinChequebookAge          uint64
+	SwapTrustedChequeScore        uint64
+	SwapTrustedChequeReverify     time.Duration
+	SwapCallTimeout               time.Duration
+	SwapSendTimeout               time.Duration
+	SwapReceiptTimeout            time.Duration
+	SwapStatementInterval         time.Duration
+	SwapEscalationLadder          string
+	SwapWithdrawWatchPeers        int
+	SwapWithdrawWatchCashout      bool
+	SwapStalePeerPeriod           time.Duration
+	SwapAutoCashoutThreshold      string
+	SwapAutoCashoutMinInterval    time.Duration
+	SwapRegistryAddress           string
+	SwapRegistryPublish           bool
+	SwapCashoutMaxDelay           time.Duration
+	SwapCashoutParallelism        int
+	SwapCashoutMaxInFlight        int
+	SwapCashoutCost               string
+	SwapConfirmations             int64
+	SwapSequencerUptimeFeed       string
+	SwapL1FeeOracle               string
+	SwapFactoryDeposit            bool
 	ChequebookEnable              bool
+	SettlementEncryption          bool
+	SettlementEncryptionSecret    string
+	SettlementNamespace           string
+	SettlementDataDir             string
+	SettlementReplica             string
 	FullNodeMode                  bool
 	PostageContractAddress        string
 	PostageContractStartBlock     uint64
@@ -183,6 +279,13 @@ type Options struct {
 	AdminPasswordHash             string
 	UsePostageSnapshot            bool
 	EnableStorageIncentives       bool
+	// SettlementMiddlewares wrap the settlement endpoints of the debug API.
+	SettlementMiddlewares []api.SettlementMiddleware
+	// ChainRuntime is shared with the other nodes of the process instead of
+	// connecting to the blockchain endpoint. The transactions of the node are
+	// kept in the store of the runtime, and the runtime is not closed with
+	// the node.
+	ChainRuntime *transaction.Runtime
 }
 
 const (
@@ -250,6 +353,46 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 	}
 	b.stateStoreCloser = stateStore
 
+	// settlement records and transactions are kept in the settlementStore,
+	// which is the stateStore unless a separate directory is configured
+	settlementStore, err := InitSettlementStateStore(ctx, logger, stateStore, o.SettlementDataDir)
+	if err != nil {
+		return nil, fmt.Errorf("settlement statestore: %w", err)
+	}
+	if o.SettlementDataDir != "" {
+		b.settlementStoreCloser = settlementStore
+	}
+
+	if o.SettlementReplica != "" {
+		var replicaCloser io.Closer
+		settlementStore, replicaCloser, err = InitSettlementReplication(logger, settlementStore, o.SettlementReplica)
+		if err != nil {
+			return nil, fmt.Errorf("settlement replication: %w", err)
+		}
+		b.settlementReplicaCloser = replicaCloser
+	}
+
+	keyLayout, err := SettlementKeyLayout()
+	if err != nil {
+		return nil, fmt.Errorf("settlement key layout: %w", err)
+	}
+
+	stateStoreUsage, err := InitStateStoreUsage(settlementStore)
+	if err != nil {
+		return nil, fmt.Errorf("statestore usage: %w", err)
+	}
+	settlementStore = stateStoreUsage
+
+	if o.SettlementEncryption {
+		settlementStore, err = initSettlementEncryption(logger, settlementStore, signer, o.SettlementEncryptionSecret)
+		if err != nil {
+			return nil, fmt.Errorf("settlement encryption: %w", err)
+		}
+	}
+	if o.SettlementDataDir == "" {
+		stateStore = settlementStore
+	}
+
 	// Check if the the batchstore exists. If not, we can assume it's missing
 	// due to a migration or it's a fresh install.
 	batchStoreExists, err := batchStoreExists(stateStore)
@@ -295,16 +438,23 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 	}
 
 	var (
-		chainBackend       transaction.Backend
-		overlayEthAddress  common.Address
-		chainID            int64
-		transactionService transaction.Service
-		transactionMonitor transaction.Monitor
-		chequebookFactory  chequebook.Factory
-		chequebookService  chequebook.Service = new(noOpChequebookService)
-		chequeStore        chequebook.ChequeStore
-		cashoutService     chequebook.CashoutService
-		erc20Service       erc20.Service
+		chainBackend        transaction.Backend
+		overlayEthAddress   common.Address
+		chainID             int64
+		transactionService  transaction.Service
+		chainRuntime        *transaction.Runtime
+		chequebookFactory   chequebook.Factory
+		trustedFactories    chequebook.TrustedFactories
+		contractInspector   chequebook.ContractInspector
+		chequeSignerRotator chequebook.PassphraseRotator
+		totalIssuedGuard    chequebook.TotalIssuedGuard
+		balanceAlarm        *chequebook.BalanceAlarm
+		auditLog            *auditlog.Log
+		chequebookService   chequebook.Service = new(noOpChequebookService)
+		chequeStore         chequebook.ChequeStore
+		chequeReputation    *chequebook.Reputation
+		cashoutService      chequebook.CashoutService
+		erc20Service        erc20.Service
 	)
 
 	chainEnabled := isChainEnabled(o, o.BlockchainRpcEndpoint, logger)
@@ -326,19 +476,52 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		}
 	}
 
-	chainBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err = InitChain(
-		ctx,
-		logger,
-		stateStore,
-		o.BlockchainRpcEndpoint,
-		o.ChainID,
-		signer,
-		o.BlockTime,
-		chainEnabled)
+	rpcAuth, err := RPCAuthOptions(o.BlockchainRpcHeaders, o.BlockchainRpcBasicAuth, o.BlockchainRpcJWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("blockchain rpc auth: %w", err)
+	}
+	rpcRetry, err := RPCRetryOptions(o.BlockchainRpcRetries, o.BlockchainRpcRetryBackoff, o.BlockchainRpcRetryMaxBackoff, o.BlockchainRpcCallRetries, retry.TimeoutOptions{
+		Margin: o.BlockchainRpcTimeoutMargin,
+		Min:    o.BlockchainRpcMinTimeout,
+		Max:    o.BlockchainRpcMaxTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blockchain rpc retries: %w", err)
+	}
+
+	if o.ChainRuntime != nil {
+		chainRuntime = o.ChainRuntime
+		overlayEthAddress, err = signer.EthereumAddress()
+		if err != nil {
+			return nil, fmt.Errorf("eth address: %w", err)
+		}
+		transactionService, _, err = chainRuntime.Service(signer)
+	} else {
+		chainRuntime, overlayEthAddress, transactionService, err = InitChain(
+			ctx,
+			logger,
+			settlementStore,
+			o.BlockchainRpcEndpoint,
+			rpcAuth,
+			rpcRetry,
+			private.Options{
+				Endpoint: o.BlockchainRpcPrivateRelay,
+				Deadline: o.BlockchainRpcPrivateDeadline,
+			},
+			o.ChainID,
+			signer,
+			o.BlockTime,
+			chainEnabled)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("init chain: %w", err)
 	}
-	b.closers = append(b.closers, chainBackend.Close)
+	chainBackend = chainRuntime.Backend()
+	chainID = chainRuntime.ChainID().Int64()
+	if o.ChainRuntime == nil {
+		b.closers = append(b.closers, chainBackend.Close)
+		b.chainRuntimeCloser = chainRuntime
+	}
 
 	logger.Info("using chain with network network", "chain_id", chainID, "network_id", networkID)
 
@@ -346,8 +529,41 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		return nil, fmt.Errorf("connected to wrong ethereum network; network chainID %d; configured chainID %d", chainID, o.ChainID)
 	}
 
+	settlementStore, err = InitSettlementNamespace(logger, settlementStore, o.SettlementNamespace, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("settlement namespace: %w", err)
+	}
+	if o.SettlementDataDir == "" {
+		stateStore = settlementStore
+	}
+
 	b.transactionCloser = tracerCloser
-	b.transactionMonitorCloser = transactionMonitor
+	transactionMetrics, _ := transactionService.(metrics.Collector) // registered without the wrapping services
+
+	transactionService, gasPriceCaps, err := initGasPriceCaps(logger, transactionService, chainBackend, o)
+	if err != nil {
+		return nil, fmt.Errorf("gas price caps: %w", err)
+	}
+	if gasPriceCaps != nil {
+		b.gasPriceCapCloser = gasPriceCaps
+	}
+
+	transactionService, rollupService, err := initRollup(logger, transactionService, chainID, o)
+	if err != nil {
+		return nil, fmt.Errorf("rollup: %w", err)
+	}
+	if rollupService != nil {
+		b.rollupCloser = rollupService
+	}
+
+	var spendPurposes *analytics.Purposes
+	if chainEnabled {
+		spendPurposes, err = analytics.NewPurposes(logger, settlementStore, analytics.DefaultWindow, transactionService, chainBackend, spendPurpose)
+		if err != nil {
+			return nil, fmt.Errorf("spend purposes: %w", err)
+		}
+		transactionService = spendPurposes
+	}
 
 	var authenticator auth.Authenticator
 
@@ -463,13 +679,32 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 	}
 
 	if o.SwapEnable {
+		// no settlement operation may hang forever, whatever context it is called with
+		settlementTimeouts := chequebook.Timeouts{
+			Call:    o.SwapCallTimeout,
+			Send:    o.SwapSendTimeout,
+			Receipt: o.SwapReceiptTimeout,
+		}
+		// settlement transactions and events are only acted upon once they are final
+		confirmations := config.Confirmations(chainID)
+		if o.SwapConfirmations >= 0 {
+			confirmations = uint64(o.SwapConfirmations)
+		}
+		logger.Debug("settlement finality", "chain_id", chainID, "confirmations", confirmations)
+		settlementBackend := transaction.NewFinalityBackend(chainBackend, confirmations)
+		settlementTransactionService := chequebook.NewTimeoutTransactionService(
+			transaction.NewFinalityService(transactionService, chainBackend, confirmations, o.BlockTime),
+			settlementTimeouts,
+		)
+
 		chequebookFactory, err = InitChequebookFactory(
 			logger,
 			chainBackend,
 			chainID,
-			transactionService,
+			settlementTransactionService,
 			o.SwapFactoryAddress,
 			o.SwapLegacyFactoryAddresses,
+			o.SwapFactoryDeposit,
 		)
 		if err != nil {
 			return nil, err
@@ -484,36 +719,127 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 			return nil, fmt.Errorf("factory fail: %w", err)
 		}
 
-		erc20Service = erc20.New(transactionService, erc20Address)
+		erc20Service = erc20.New(settlementTransactionService, erc20Address)
+
+		swapTransactionService, tokenOwner, userOperationCloser, err := initUserOperations(ctx, logger, transactionService, chainBackend, signer, chainID, overlayEthAddress, o)
+		if err != nil {
+			return nil, err
+		}
+		swapTransactionService = chequebook.NewTimeoutTransactionService(
+			transaction.NewFinalityService(swapTransactionService, chainBackend, confirmations, o.BlockTime),
+			settlementTimeouts,
+		)
+		b.userOperationCloser = userOperationCloser
 
 		if o.ChequebookEnable && chainEnabled {
+			chequeSigner, rotator, chequeSignerCloser, err := initChequeSigner(logger, signer, chainID, overlayEthAddress, o)
+			if err != nil {
+				return nil, err
+			}
+			b.chequeSignerCloser = chequeSignerCloser
+			chequeSignerRotator = rotator
+
 			chequebookService, err = InitChequebookService(
 				ctx,
 				logger,
-				stateStore,
-				signer,
+				settlementStore,
+				chequeSigner,
 				chainID,
-				chainBackend,
+				settlementBackend,
 				overlayEthAddress,
-				transactionService,
+				tokenOwner,
+				swapTransactionService,
 				chequebookFactory,
 				o.SwapInitialDeposit,
 				o.DeployGasPrice,
-				erc20Service,
+				erc20.New(swapTransactionService, erc20Address),
 			)
 			if err != nil {
 				return nil, err
 			}
+
+			tolerance, ok := new(big.Int).SetString(o.SwapTotalIssuedTolerance, 10)
+			if !ok || tolerance.Sign() < 0 {
+				return nil, fmt.Errorf("invalid total issued tolerance %q", o.SwapTotalIssuedTolerance)
+			}
+			guard, err := chequebook.NewConsistencyGuard(ctx, chequebookService, tolerance)
+			if err != nil {
+				return nil, fmt.Errorf("total issued consistency check: %w", err)
+			}
+			if check := guard.LastCheck(); !check.Consistent {
+				logger.Warning("total issued diverges from the issued cheques, no cheques are issued until it is reconciled or the check is overridden", "stored", check.Stored, "computed", check.Computed, "tolerance", check.Tolerance)
+			}
+			chequebookService = guard
+			totalIssuedGuard = guard
+
+			if o.SwapChequeRateInterval > 0 && o.SwapChequeRateBurst > 0 {
+				chequebookService = chequebook.NewIssueRateLimiter(chequebookService, o.SwapChequeRateInterval, o.SwapChequeRateBurst)
+			}
+
+			if o.SwapBalanceDeclineMax != "" {
+				maxDecline, ok := new(big.Int).SetString(o.SwapBalanceDeclineMax, 10)
+				if !ok || maxDecline.Sign() <= 0 || o.SwapBalanceDeclineWindow <= 0 {
+					return nil, fmt.Errorf("invalid balance decline limit %q over %s", o.SwapBalanceDeclineMax, o.SwapBalanceDeclineWindow)
+				}
+				balanceAlarm = chequebook.NewBalanceAlarm(chequebookService, maxDecline, o.SwapBalanceDeclineWindow, o.SwapBalanceDeclinePause)
+				chequebookService = balanceAlarm
+			}
+		}
+
+		// verification results are cached and revalidated when the trusted factories change at runtime
+		cachingFactory := chequebook.NewCachingFactory(chequebookFactory, settlementStore, chequebook.DefaultVerificationTTL, chequebook.DefaultNegativeVerificationTTL)
+		trustedFactories, _ = cachingFactory.(chequebook.TrustedFactories)
+		contractInspector, _ = cachingFactory.(chequebook.ContractInspector)
+
+		multicallAddress, err := initMulticallAddress(logger, chainID, o.SwapMulticallAddress)
+		if err != nil {
+			return nil, err
+		}
+		// chequebooks of imported cheques are verified in batches through the multicall contract
+		if f, ok := cachingFactory.(chequebook.MulticallFactory); ok {
+			f.SetMulticall(multicallAddress)
 		}
 
 		chequeStore, cashoutService = initChequeStoreCashout(
-			stateStore,
-			chainBackend,
-			chequebookFactory,
+			settlementStore,
+			settlementBackend,
+			cachingFactory,
 			chainID,
 			overlayEthAddress,
-			transactionService,
+			swapTransactionService,
+			chequebook.NewCashoutSigner(signer, chainID),
+			multicallAddress,
+			chequeValidators(chainBackend, o.SwapMinChequebookAge)...,
 		)
+
+		if o.SwapChequeVerifierEndpoint != "" {
+			chequeStore.DelegateVerification(remoteverifier.NewHTTPVerifier(o.SwapChequeVerifierEndpoint, nil))
+			logger.Info("received cheques are verified by a trusted node", "endpoint", o.SwapChequeVerifierEndpoint)
+		}
+
+		if o.SwapTrustedChequeScore > 0 {
+			chequeReputation = chequebook.NewReputation(settlementStore, o.SwapTrustedChequeScore, o.SwapTrustedChequeReverify)
+			chequeStore.SetReputation(chequeReputation)
+		}
+
+		// all settlement affecting actions are recorded in the audit log
+		auditLog, err = auditlog.New(settlementStore, signer)
+		if err != nil {
+			return nil, fmt.Errorf("audit log: %w", err)
+		}
+		chequeStore = auditlog.WrapChequeStore(chequeStore, auditLog, logger)
+		cashoutService = auditlog.WrapCashout(cashoutService, auditLog, logger)
+		if o.ChequebookEnable && chainEnabled {
+			chequebookService = auditlog.WrapChequebook(chequebookService, auditLog, logger)
+
+			if o.SwapChequeGranularity != "" {
+				granularity, ok := new(big.Int).SetString(o.SwapChequeGranularity, 10)
+				if !ok || granularity.Sign() <= 0 {
+					return nil, fmt.Errorf("invalid cheque granularity %q", o.SwapChequeGranularity)
+				}
+				chequebookService = chequebook.NewGranularService(chequebookService, settlementStore, granularity)
+			}
+		}
 	}
 
 	apiService.SetSwarmAddress(&swarmAddress)
@@ -577,6 +903,7 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 			cashoutService,
 			transactionService,
 			stateStore,
+			settlementStore,
 			signer,
 			networkID,
 			log.Noop,
@@ -736,7 +1063,16 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 	}
 	b.hiveCloser = hive
 
-	var swapService *swap.Service
+	var (
+		swapService       *swap.Service
+		settlementWorkers *workerpool.Pool
+		cashoutOptimizer  *cashouttiming.Optimizer
+		spendAnalytics    *analytics.Spend
+		earnings          *analytics.Earnings
+		ledger            *analytics.Ledger
+		settlementRates   func() (*big.Int, *big.Int, error)
+		deductedBy        func(swarm.Address) (bool, error)
+	)
 
 	metricsDB, err := shed.NewDBWrap(stateStore.DB())
 	if err != nil {
@@ -842,12 +1178,28 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		enforcedRefreshRate = big.NewInt(lightRefreshRate)
 	}
 
+	settlementEvents := events.NewFeed()
+	b.settlementEventsCloser = settlementEvents
+
+	if balanceAlarm != nil {
+		chequebookAddress := chequebookService.Address()
+		balanceAlarm.OnDecline(func(decline chequebook.BalanceDecline) {
+			logger.Warning("available chequebook balance declining faster than allowed", "decline", decline.Amount, "max_decline", decline.MaxDecline, "window", decline.Window, "available_balance", decline.AvailableBalance, "issuance_paused", balanceAlarm.Pauses())
+			settlementEvents.Publish(events.Event{
+				Type:       events.TypeBalanceDecline,
+				Chequebook: chequebookAddress,
+				Amount:     decline.Amount,
+				Balance:    decline.AvailableBalance,
+			})
+		})
+	}
+
 	acc, err := accounting.NewAccounting(
 		paymentThreshold,
 		o.PaymentTolerance,
 		o.PaymentEarly,
 		logger,
-		stateStore,
+		settlementStore,
 		pricing,
 		new(big.Int).Set(enforcedRefreshRate),
 		lightFactor,
@@ -857,8 +1209,9 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		return nil, fmt.Errorf("accounting: %w", err)
 	}
 	b.accountingCloser = acc
+	acc.SetEventPublisher(settlementEvents)
 
-	pseudosettleService := pseudosettle.New(p2ps, logger, stateStore, acc, new(big.Int).Set(enforcedRefreshRate), big.NewInt(lightRefreshRate), p2ps)
+	pseudosettleService := pseudosettle.New(p2ps, logger, settlementStore, acc, new(big.Int).Set(enforcedRefreshRate), big.NewInt(lightRefreshRate), p2ps)
 	if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
 		return nil, fmt.Errorf("pseudosettle service: %w", err)
 	}
@@ -870,7 +1223,7 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		swapService, priceOracle, err = InitSwap(
 			p2ps,
 			logger,
-			stateStore,
+			settlementStore,
 			networkID,
 			overlayEthAddress,
 			chequebookService,
@@ -880,14 +1233,153 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 			o.PriceOracleAddress,
 			chainID,
 			transactionService,
+			signer,
 		)
 		if err != nil {
 			return nil, err
 		}
 		b.priceOracleCloser = priceOracle
+		settlementRates = priceOracle.CurrentRates
+		deductedBy = swapService.GetDeductionByPeer
+
+		swapService.SetDisconnectNotifier(swap.NewBlocklistNotifier(p2ps), o.SwapBlocklistDuration)
+		acc.SetDisconnectThresholdFunc(func(peer swarm.Address, debt *big.Int) {
+			if err := swapService.NotifyDisconnectThresholdExceeded(peer, debt); err != nil {
+				logger.Debug("disconnect threshold notification failed", "peer_address", peer, "error", err)
+			}
+		})
+		swapService.SetEventPublisher(settlementEvents)
+		swapService.SetPeerLister(p2ps)
+		if contractInspector != nil {
+			swapService.SetContractInspector(contractInspector)
+		}
+		if err := initRegistry(ctx, logger, transactionService, swapService, chequebookService, swarmAddress, overlayEthAddress, o); err != nil {
+			return nil, fmt.Errorf("registry: %w", err)
+		}
+		chequebookService = swapService.ChequebookWithEvents(chequebookService)
+
+		if o.ChequebookEnable {
+			spendAnalytics, err = analytics.NewSpend(logger, settlementStore, analytics.DefaultWindow, chequebookService.AvailableBalance)
+			if err != nil {
+				return nil, fmt.Errorf("spend analytics: %w", err)
+			}
+			swapService.SubscribeEvents(spendAnalytics.HandleEvent)
+		}
+
+		earnings, err = analytics.NewEarnings(logger, settlementStore, analytics.DefaultWindow, swapService.CashoutStatus, transactionService.TransactionFee)
+		if err != nil {
+			return nil, fmt.Errorf("earnings analytics: %w", err)
+		}
+		if o.SwapCashoutCost != "" {
+			cashoutCost, ok := new(big.Int).SetString(o.SwapCashoutCost, 10)
+			if !ok || cashoutCost.Sign() < 0 {
+				return nil, fmt.Errorf("invalid cashout cost %q", o.SwapCashoutCost)
+			}
+			earnings.SetCashoutCost(cashoutCost)
+		}
+		swapService.SubscribeEvents(earnings.HandleEvent)
+
+		ledger, err = analytics.NewLedger(logger, settlementStore, swapService.CashoutStatus, priceOracle.CurrentRates)
+		if err != nil {
+			return nil, fmt.Errorf("settlement ledger: %w", err)
+		}
+		swapService.SubscribeEvents(ledger.HandleEvent)
+		if chequeReputation != nil {
+			swapService.SubscribeEvents(chequeReputation.HandleEvent)
+		}
+		if spendPurposes != nil {
+			swapService.SubscribeEvents(spendPurposes.HandleEvent)
+		}
+
+		settlementWorkers = workerpool.New(o.SwapWorkers, o.SwapWorkerQueueSize)
+		b.settlementWorkersCloser = settlementWorkers
+		swapService.SetWorkerPool(settlementWorkers)
+
+		if o.SwapDrainTimeout > 0 {
+			var drainMinDebt *big.Int
+			if o.SwapDrainMinDebt != "" {
+				minDebt, ok := new(big.Int).SetString(o.SwapDrainMinDebt, 10)
+				if !ok || minDebt.Sign() <= 0 {
+					return nil, fmt.Errorf("invalid minimum debt %q of final cheques", o.SwapDrainMinDebt)
+				}
+				drainMinDebt = minDebt
+			}
+			// queued and deferred cheques are sent before the peers are disconnected on shutdown
+			b.settlementDrainTimeout = o.SwapDrainTimeout
+			b.settlementDrain = func(ctx context.Context) error {
+				if err := acc.Drain(ctx, drainMinDebt); err != nil {
+					return err
+				}
+				return settlementWorkers.Drain(ctx)
+			}
+		}
+		b.statementsCloser = swapService.StartStatements(signer, chainID, o.SwapStatementInterval)
+
+		escalationLadder, err := swap.ParseEscalationLadder(o.SwapEscalationLadder)
+		if err != nil {
+			return nil, fmt.Errorf("escalation ladder: %w", err)
+		}
+		b.graceCloser = swapService.StartGraceTracking(escalationLadder, acc.PaymentThresholdForPeer, swap.DefaultGraceCheckInterval)
+		b.bounceWatchCloser = swapService.StartBounceWatch(swap.DefaultBounceCheckInterval)
+		b.withdrawWatchCloser = swapService.StartWithdrawWatch(chainBackend, swap.WithdrawWatchOptions{
+			Peers:    o.SwapWithdrawWatchPeers,
+			Interval: o.BlockTime,
+			CashOut:  o.SwapWithdrawWatchCashout,
+		})
+		b.staleCleanupCloser = swapService.StartStaleCleanup(swap.StaleCleanupOptions{
+			Period:   o.SwapStalePeerPeriod,
+			Interval: swap.DefaultStaleCleanupInterval,
+			Balance: func(peer swarm.Address) (*big.Int, error) {
+				balance, err := acc.Balance(peer)
+				if errors.Is(err, accounting.ErrPeerNoBalance) {
+					return balance, nil
+				}
+				return balance, err
+			},
+			Export: func(archive *swap.PeerArchive) error {
+				var chequebookAddress common.Address
+				if len(archive.Chequebooks) > 0 {
+					chequebookAddress = archive.Chequebooks[0]
+				}
+				return auditLog.Archive(chequebookAddress, archive.Beneficiary, archive)
+			},
+		})
+
+		if o.SwapAutoCashoutThreshold != "" {
+			threshold, ok := new(big.Int).SetString(o.SwapAutoCashoutThreshold, 10)
+			if !ok || threshold.Sign() < 0 {
+				return nil, fmt.Errorf("invalid auto cashout threshold %q", o.SwapAutoCashoutThreshold)
+			}
+			b.autoCashoutCloser = swapService.StartAutoCashout(swap.AutoCashoutOptions{
+				Threshold:   threshold,
+				MinInterval: o.SwapAutoCashoutMinInterval,
+				Interval:    swap.DefaultAutoCashoutInterval,
+			})
+		}
+
+		if o.SwapCashoutMaxDelay > 0 {
+			cashoutOptimizer, err = cashouttiming.New(logger, settlementStore, chainBackend, swapService.CashCheque, cashouttiming.Options{
+				MaxDelay:      o.SwapCashoutMaxDelay,
+				CheckInterval: o.BlockTime,
+				Parallelism:   o.SwapCashoutParallelism,
+				MaxInFlight:   o.SwapCashoutMaxInFlight,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("cashout timing: %w", err)
+			}
+			b.cashoutOptimizerCloser = cashoutOptimizer
+		}
 
 		if o.ChequebookEnable {
+			acc.SetPaymentBatchWindow(o.SwapPaymentBatchWindow)
 			acc.SetPayFunc(swapService.Pay)
+			acc.SetPreviewPayFunc(func(ctx context.Context, peer swarm.Address, amount *big.Int) error {
+				preview, err := swapService.PreviewPay(ctx, peer, amount)
+				if err != nil {
+					return err
+				}
+				return preview.Err()
+			})
 		}
 	}
 
@@ -1038,29 +1530,52 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 	feedFactory := factory.New(ns)
 	steward := steward.New(storer, traversalService, retrieve, pushSyncProtocol)
 
+	runtimeConfig := initRuntimeConfig(logger, gasPriceCaps, cashoutOptimizer, balanceAlarm, earnings, auditLog)
+
 	extraOpts := api.ExtraOptions{
-		Pingpong:         pingPong,
-		TopologyDriver:   kad,
-		LightNodes:       lightNodes,
-		Accounting:       acc,
-		Pseudosettle:     pseudosettleService,
-		Swap:             swapService,
-		Chequebook:       chequebookService,
-		BlockTime:        o.BlockTime,
-		Tags:             tagService,
-		Storer:           ns,
-		Resolver:         multiResolver,
-		Pss:              pssService,
-		TraversalService: traversalService,
-		Pinning:          pinningService,
-		FeedFactory:      feedFactory,
-		Post:             post,
-		PostageContract:  postageStampContractService,
-		Staking:          stakingContract,
-		Steward:          steward,
-		SyncStatus:       syncStatusFn,
-		IndexDebugger:    storer,
-		NodeStatus:       nodeStatus,
+		Pingpong:              pingPong,
+		TopologyDriver:        kad,
+		LightNodes:            lightNodes,
+		Accounting:            acc,
+		Pseudosettle:          pseudosettleService,
+		Swap:                  swapService,
+		Chequebook:            chequebookService,
+		TrustedFactories:      trustedFactories,
+		Contracts:             contractInspector,
+		ChequeVerifier:        chequeStore,
+		ChequeSigner:          chequeSignerRotator,
+		TotalIssuedGuard:      totalIssuedGuard,
+		AuditLog:              auditLog,
+		Snapshots:             SettlementSnapshots(settlementStore),
+		StateStoreUsage:       stateStoreUsage,
+		KeyLayout:             keyLayout,
+		SettlementEvents:      settlementEvents,
+		CashoutOptimizer:      cashoutOptimizer,
+		RuntimeConfig:         runtimeConfig,
+		SettlementMiddlewares: o.SettlementMiddlewares,
+		SpendAnalytics:        spendAnalytics,
+		Earnings:              earnings,
+		Ledger:                ledger,
+		SpendPurposes:         spendPurposes,
+		CashoutDataFee:        cashoutDataFee(rollupService),
+		SettlementRates:       settlementRates,
+		DeductedBy:            deductedBy,
+		MinimumPayment:        acc.MinimumPayment(),
+		BlockTime:             o.BlockTime,
+		Tags:                  tagService,
+		Storer:                ns,
+		Resolver:              multiResolver,
+		Pss:                   pssService,
+		TraversalService:      traversalService,
+		Pinning:               pinningService,
+		FeedFactory:           feedFactory,
+		Post:                  post,
+		PostageContract:       postageStampContractService,
+		Staking:               stakingContract,
+		Steward:               steward,
+		SyncStatus:            syncStatusFn,
+		IndexDebugger:         storer,
+		NodeStatus:            nodeStatus,
 	}
 
 	if o.APIAddr != "" {
@@ -1115,6 +1630,7 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		debugService.MustRegisterMetrics(pingPong.Metrics()...)
 		debugService.MustRegisterMetrics(acc.Metrics()...)
 		debugService.MustRegisterMetrics(storer.Metrics()...)
+		debugService.MustRegisterMetrics(stateStoreUsage.Metrics()...)
 		debugService.MustRegisterMetrics(kad.Metrics()...)
 		debugService.MustRegisterMetrics(saludService.Metrics()...)
 
@@ -1149,6 +1665,15 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		if swapBackendMetrics, ok := chainBackend.(metrics.Collector); ok {
 			debugService.MustRegisterMetrics(swapBackendMetrics.Metrics()...)
 		}
+		if transactionMetrics != nil {
+			debugService.MustRegisterMetrics(transactionMetrics.Metrics()...)
+		}
+		if gasPriceCaps != nil {
+			debugService.MustRegisterMetrics(gasPriceCaps.Metrics()...)
+		}
+		if rollupService != nil {
+			debugService.MustRegisterMetrics(rollupService.Metrics()...)
+		}
 		if apiService != nil {
 			debugService.MustRegisterMetrics(apiService.Metrics()...)
 		}
@@ -1162,6 +1687,19 @@ func NewBee(ctx context.Context, addr string, publicKey *ecdsa.PublicKey, signer
 		if swapService != nil {
 			debugService.MustRegisterMetrics(swapService.Metrics()...)
 		}
+		debugService.MustRegisterMetrics(logcursor.Metrics()...)
+		if settlementWorkers != nil {
+			debugService.MustRegisterMetrics(settlementWorkers.Metrics()...)
+		}
+		if cashoutOptimizer != nil {
+			debugService.MustRegisterMetrics(cashoutOptimizer.Metrics()...)
+		}
+		if spendAnalytics != nil {
+			debugService.MustRegisterMetrics(spendAnalytics.Metrics()...)
+		}
+		if earnings != nil {
+			debugService.MustRegisterMetrics(earnings.Metrics()...)
+		}
 
 		debugService.Configure(signer, authenticator, tracer, api.Options{
 			CORSAllowedOrigins: o.CORSAllowedOrigins,
@@ -1251,6 +1789,14 @@ func (b *Bee) Shutdown() error {
 		mErr = multierror.Append(mErr, err)
 	}
 
+	if b.settlementDrain != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), b.settlementDrainTimeout)
+		if err := b.settlementDrain(ctx); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("settlement drain: %w", err))
+		}
+		cancel()
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(10)
 	go func() {
@@ -1300,11 +1846,24 @@ func (b *Bee) Shutdown() error {
 
 	tryClose(b.p2pService, "p2p server")
 	tryClose(b.priceOracleCloser, "price oracle service")
+	tryClose(b.chequeSignerCloser, "cheque signer")
+	tryClose(b.userOperationCloser, "user operation bundler client")
+	tryClose(b.rollupCloser, "rollup")
+	tryClose(b.gasPriceCapCloser, "gas price caps")
+	tryClose(b.cashoutOptimizerCloser, "cashout timing")
+	tryClose(b.statementsCloser, "settlement statements")
+	tryClose(b.graceCloser, "payment grace tracking")
+	tryClose(b.withdrawWatchCloser, "peer withdrawal watch")
+	tryClose(b.bounceWatchCloser, "bounced cheque watch")
+	tryClose(b.staleCleanupCloser, "stale peer cleanup")
+	tryClose(b.autoCashoutCloser, "automatic cashout")
+	tryClose(b.settlementWorkersCloser, "settlement workers")
+	tryClose(b.settlementEventsCloser, "settlement events")
 
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		tryClose(b.transactionMonitorCloser, "transaction monitor")
+		tryClose(b.chainRuntimeCloser, "chain runtime")
 		tryClose(b.transactionCloser, "transaction")
 	}()
 	go func() {
@@ -1329,6 +1888,8 @@ func (b *Bee) Shutdown() error {
 	tryClose(b.depthMonitorCloser, "depthmonitor service")
 	tryClose(b.storageIncetivesCloser, "storage incentives agent")
 	tryClose(b.stateStoreCloser, "statestore")
+	tryClose(b.settlementStoreCloser, "settlement statestore")
+	tryClose(b.settlementReplicaCloser, "settlement replica")
 	tryClose(b.localstoreCloser, "localstore")
 	tryClose(b.resolverCloser, "resolver service")
 
@@ -0,0 +1,119 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/transaction/gascap"
+)
+
+// initRuntimeConfig registers the settlement settings which can be changed
+// through the API for the components which are enabled. The settings are
+// named after the command line flags they override. Changes are recorded in
+// the audit log, if there is one.
+func initRuntimeConfig(logger log.Logger, gasPriceCaps *gascap.Service, cashoutOptimizer *cashouttiming.Optimizer, balanceAlarm *chequebook.BalanceAlarm, earnings *analytics.Earnings, auditLog *auditlog.Log) *runtimeconfig.Registry {
+	registry := runtimeconfig.New()
+
+	if gasPriceCaps != nil {
+		registry.Register("swap-gas-price-caps", runtimeconfig.Setting{
+			Description: "maximum gas price in wei per chequebook operation as comma separated operation=wei",
+			Get: func() string {
+				return strings.Join(gascap.FormatCaps(gasPriceCaps.Caps()), ",")
+			},
+			Validate: func(value string) error {
+				_, err := parseGasPriceCaps(value)
+				return err
+			},
+			Set: func(value string) error {
+				caps, err := parseGasPriceCaps(value)
+				if err != nil {
+					return err
+				}
+				gasPriceCaps.SetCaps(caps)
+				return nil
+			},
+		})
+	}
+
+	if cashoutOptimizer != nil {
+		registry.Register("swap-cashout-fee-percentile", runtimeconfig.Float(
+			"percentile of the past base fees at or below which scheduled cashouts are sent",
+			cashoutOptimizer.FeePercentile,
+			cashoutOptimizer.SetFeePercentile,
+			cashouttiming.ValidateFeePercentile,
+		))
+		registry.Register("swap-cashout-max-in-flight", runtimeconfig.Int(
+			"number of scheduled cashout transactions waiting to be mined after which further cashouts are postponed",
+			cashoutOptimizer.MaxInFlight,
+			cashoutOptimizer.SetMaxInFlight,
+			cashouttiming.ValidateMaxInFlight,
+		))
+	}
+
+	if balanceAlarm != nil {
+		registry.Register("swap-balance-decline-max", runtimeconfig.BigInt(
+			"largest decline in PLUR of the available chequebook balance within the decline window before an alarm is raised",
+			balanceAlarm.MaxDecline,
+			balanceAlarm.SetMaxDecline,
+		))
+		registry.Register("swap-balance-decline-pause", runtimeconfig.Bool(
+			"stop issuing cheques while the available chequebook balance declines faster than allowed",
+			balanceAlarm.Pauses,
+			balanceAlarm.SetPause,
+		))
+	}
+
+	if earnings != nil {
+		registry.Register("swap-cashout-cost", runtimeconfig.BigInt(
+			"PLUR expected to be deducted from the payout of a cashout, reserved from the uncashed earnings",
+			earnings.CashoutCost,
+			earnings.SetCashoutCost,
+		))
+	}
+
+	if auditLog != nil {
+		registry.OnChange(func(change runtimeconfig.Change, actor string) {
+			_, err := auditLog.Append(auditlog.Entry{
+				Action: auditlog.ActionConfig,
+				Note:   fmt.Sprintf("%s changed from %q to %q by %s", change.Name, change.Previous, change.Value, actor),
+			})
+			if err != nil {
+				logger.Error(err, "audit log: failed to record setting change", "name", change.Name)
+			}
+		})
+	}
+
+	return registry
+}
+
+// parseGasPriceCaps parses comma separated gas price caps of the known
+// chequebook operations. An empty value removes all caps.
+func parseGasPriceCaps(value string) (map[string]*big.Int, error) {
+	var caps []string
+	if value != "" {
+		caps = strings.Split(value, ",")
+	}
+	parsed, err := gascap.ParseCaps(caps)
+	if err != nil {
+		return nil, err
+	}
+	for operation := range parsed {
+		switch operation {
+		case swapOperationDeployment, swapOperationDeposit, swapOperationWithdraw, swapOperationCashout:
+		default:
+			return nil, fmt.Errorf("gas price cap for unknown operation %q", operation)
+		}
+	}
+	return parsed, nil
+}
@@ -5,12 +5,30 @@
 package node
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/statestore/encrypted"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/statestore/namespaced"
+	"github.com/ethersphere/bee/pkg/statestore/replicated"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -26,6 +44,250 @@ func InitStateStore(logger log.Logger, dataDir string) (storage.StateStorer, err
 	return leveldb.NewStateStore(filepath.Join(dataDir, "statestore"), logger)
 }
 
+// InitSettlementStateStore returns the statestore holding the settlement
+// records and transactions of the node. If dir is empty, they are kept in the
+// stateStore of the node. Otherwise they are kept in a separate statestore in
+// dir, so that their frequent writes are not compacted together with the rest
+// of the node state and they can be backed up on their own. Records found in
+// the stateStore are moved to the separate statestore when it is first used.
+func InitSettlementStateStore(ctx context.Context, logger log.Logger, stateStore storage.StateStorer, dir string) (storage.StateStorer, error) {
+	if dir == "" {
+		return stateStore, nil
+	}
+
+	settlementStore, err := leveldb.NewStateStore(dir, logger)
+	if err != nil {
+		return nil, err
+	}
+	migrated, err := moveSettlementRecords(ctx, stateStore, settlementStore)
+	if err != nil {
+		_ = settlementStore.Close()
+		return nil, fmt.Errorf("move settlement records: %w", err)
+	}
+	if migrated > 0 {
+		logger.Info("moved settlement records to separate statestore", "path", dir, "keys", migrated)
+	}
+	return settlementStore, nil
+}
+
+// moveSettlementRecords moves the settlement records and transactions from one
+// statestore to an empty other one.
+func moveSettlementRecords(ctx context.Context, from, to storage.StateStorer) (int, error) {
+	snap, err := SettlementSnapshots(from).Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(snap.Records) == 0 {
+		return 0, nil
+	}
+
+	existing, err := SettlementSnapshots(to).Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing.Records) > 0 {
+		return 0, errors.New("settlement records found in both statestores")
+	}
+
+	if err := SettlementSnapshots(to).Restore(ctx, snap); err != nil {
+		return 0, err
+	}
+	empty := &snapshot.Snapshot{Version: snapshot.Version}
+	if err := SettlementSnapshots(from).Restore(ctx, empty); err != nil {
+		return 0, err
+	}
+	return len(snap.Records), nil
+}
+
+// settlementKeyPrefixes are the prefixes of the statestore keys holding
+// cheques, balances and other settlement records.
+var settlementKeyPrefixes = []string{"swap_", "accounting_", "pseudosettle_", "settlement_audit_"}
+
+// settlementStatePrefixes are the prefixes of the settlement records and of the
+// nonces, stored and pending transactions and the transaction intents of the
+// transaction service.
+var settlementStatePrefixes = append([]string{"transaction_"}, settlementKeyPrefixes...)
+
+// settlementUsageCategories are the categories of settlement records whose
+// number and size are tracked. Other settlement records are counted as other.
+var settlementUsageCategories = []usage.Category{
+	{Name: "issued_cheques", Prefixes: []string{"swap_chequebook_last_issued_cheque_", "swap_chequebook_total_issued_"}},
+	{Name: "received_cheques", Prefixes: []string{"swap_chequebook_last_received_cheque_"}},
+	{Name: "history", Prefixes: []string{
+		"swap_cashout_transaction_",
+		"swap_cheque_cashout_",
+		"swap_chequebook_deposit_",
+		"swap_receipt_",
+		"swap_statement_",
+		"swap_peer_statement_",
+		"settlement_audit_",
+	}},
+	{Name: "pending_transactions", Prefixes: []string{"transaction_pending_", "transaction_intent_"}},
+	{Name: "other", Prefixes: settlementKeyPrefixes},
+}
+
+// SettlementKeyLayout returns the layout of the statestore keys of all
+// settlement records.
+func SettlementKeyLayout() (*keylayout.Registry, error) {
+	var keys []keylayout.Key
+	for _, layout := range [][]keylayout.Key{
+		accounting.KeyLayout,
+		pseudosettle.KeyLayout,
+		swap.KeyLayout,
+		chequebook.KeyLayout,
+		cashouttiming.KeyLayout,
+		analytics.KeyLayout,
+		auditlog.KeyLayout,
+	} {
+		keys = append(keys, layout...)
+	}
+	return keylayout.New(keys...)
+}
+
+// CountSettlementKeys returns the number of settlement records in the
+// stateStore by key pattern of the layout and the keys of the settlement
+// records which match no pattern. The keys are matched without their
+// namespace, so the records of all namespaces are counted together.
+func CountSettlementKeys(stateStore storage.StateStorer, layout *keylayout.Registry) (counts map[string]int, unknown []string, err error) {
+	counts = make(map[string]int)
+	for _, prefix := range settlementKeyPrefixes {
+		err := stateStore.Iterate(prefix, func(key, _ []byte) (bool, error) {
+			if k, ok := layout.Match(settlementKey(string(key))); ok {
+				counts[k.Pattern]++
+			} else {
+				unknown = append(unknown, string(key))
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("iterate %s: %w", prefix, err)
+		}
+	}
+	return counts, unknown, nil
+}
+
+// InitStateStoreUsage wraps the stateStore so that the number and size of
+// the settlement records are tracked. It has to wrap the store below all other
+// wrappers so that every write is seen. The records are categorized by their
+// keys without the settlement namespace.
+func InitStateStoreUsage(stateStore storage.StateStorer) (*usage.Store, error) {
+	return usage.NewWithOptions(stateStore, usage.Options{
+		Key:      settlementKey,
+		Prefixes: settlementStatePrefixes,
+	}, settlementUsageCategories...)
+}
+
+// settlementKey returns the key of a settlement record as written to the
+// namespaced store, without the namespace of the stored key.
+func settlementKey(key string) string {
+	key, _ = namespaced.Strip(key, settlementKeyPrefixes...)
+	return key
+}
+
+// SettlementSnapshots returns the service taking and restoring snapshots of
+// the settlement records and the transactions of the node in the stateStore.
+func SettlementSnapshots(stateStore storage.StateStorer) *snapshot.Service {
+	return snapshot.New(stateStore, settlementStatePrefixes...)
+}
+
+// InitSettlementReplication wraps the stateStore so that the settlement records
+// and transactions are replicated to a warm standby before they are written.
+// The replica is either a journal file given as "journal:<path>" or a
+// statestore given as "statestore:<dir>". The returned closer closes the
+// replica.
+func InitSettlementReplication(logger log.Logger, stateStore storage.StateStorer, replica string) (storage.StateStorer, io.Closer, error) {
+	kind, location, ok := strings.Cut(replica, ":")
+	if !ok || location == "" {
+		return nil, nil, fmt.Errorf("invalid replica %q", replica)
+	}
+
+	var (
+		r      replicated.Replica
+		closer io.Closer
+	)
+	switch kind {
+	case "journal":
+		journal, err := replicated.NewJournal(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, closer = journal, journal
+	case "statestore":
+		store, err := leveldb.NewStateStore(location, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, closer = replicated.NewStoreReplica(store), store
+	default:
+		return nil, nil, fmt.Errorf("unknown replica kind %q", kind)
+	}
+
+	store, err := replicated.New(stateStore, r, settlementStatePrefixes...)
+	if err != nil {
+		_ = closer.Close()
+		return nil, nil, err
+	}
+	logger.Info("settlement state is replicated", "replica", replica, "changes", store.Seq())
+	return store, closer, nil
+}
+
+// initSettlementEncryption wraps the stateStore so that settlement records are
+// encrypted at rest. The key is derived from the secret if given, otherwise
+// from the node key. Records written before encryption was enabled are
+// encrypted.
+func initSettlementEncryption(logger log.Logger, stateStore storage.StateStorer, signer crypto.Signer, secret string) (storage.StateStorer, error) {
+	var (
+		key []byte
+		err error
+	)
+	if secret != "" {
+		key, err = encrypted.KeyFromSecret(secret)
+	} else {
+		key, err = encrypted.KeyFromSigner(signer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	store, err := encrypted.New(stateStore, key, settlementKeyPrefixes...)
+	if err != nil {
+		return nil, err
+	}
+	migrated, err := store.Migrate()
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if migrated > 0 {
+		logger.Info("encrypted settlement records", "keys", migrated)
+	}
+	return store, nil
+}
+
+// InitSettlementNamespace wraps the stateStore so that settlement records are
+// kept in the given namespace, by default the chain ID. A single statestore can
+// then hold the records of several networks. Records written before the
+// namespace was introduced are moved into it. The chain ID is only known once
+// the chain is initialized, so the namespace wraps the usage and encryption
+// wrappers, which see the keys with the namespace and strip it with
+// namespaced.Strip.
+func InitSettlementNamespace(logger log.Logger, stateStore storage.StateStorer, namespace string, chainID int64) (storage.StateStorer, error) {
+	if namespace == "" {
+		namespace = strconv.FormatInt(chainID, 10)
+	}
+	if strings.Contains(namespace, namespaced.Separator) {
+		return nil, fmt.Errorf("invalid namespace %q", namespace)
+	}
+
+	store := namespaced.New(stateStore, namespace, settlementKeyPrefixes...)
+	migrated, err := store.Migrate()
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if migrated > 0 {
+		logger.Info("migrated settlement records into namespace", "namespace", namespace, "keys", migrated)
+	}
+	return store, nil
+}
+
 const secureOverlayKey = "non-mineable-overlay"
 const noncedOverlayKey = "nonce-overlay"
 
@@ -0,0 +1,267 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package node_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestInitSettlementStateStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	stateStore, err := node.InitStateStore(log.Noop, dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = stateStore.Close() })
+
+	for key, value := range map[string]string{
+		"swap_chequebook":       "chequebook",
+		"accounting_balance_1":  "balance",
+		"transaction_nonce_1":   "nonce",
+		"non_settlement_record": "other",
+	} {
+		if err := stateStore.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shared, err := node.InitSettlementStateStore(ctx, log.Noop, stateStore, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared != stateStore {
+		t.Fatal("expected settlement records in the node statestore")
+	}
+
+	settlementDir := filepath.Join(dataDir, "settlement")
+	settlementStore, err := node.InitSettlementStateStore(ctx, log.Noop, stateStore, settlementDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"swap_chequebook", "accounting_balance_1", "transaction_nonce_1"} {
+		var value string
+		if err := settlementStore.Get(key, &value); err != nil {
+			t.Fatalf("get %s from settlement statestore: %v", key, err)
+		}
+		if err := stateStore.Get(key, &value); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v for %s in node statestore, want %v", err, key, storage.ErrNotFound)
+		}
+	}
+
+	var value string
+	if err := stateStore.Get("non_settlement_record", &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := settlementStore.Get("non_settlement_record", &value); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	// records written to the node statestore while the separate one exists are
+	// not merged into it
+	if err := stateStore.Put("swap_chequebook", "other chequebook"); err != nil {
+		t.Fatal(err)
+	}
+	if err := settlementStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.InitSettlementStateStore(ctx, log.Noop, stateStore, settlementDir); err == nil {
+		t.Fatal("expected error for settlement records in both statestores")
+	}
+}
+
+func TestInitSettlementReplication(t *testing.T) {
+	t.Parallel()
+
+	stateStore := mock.NewStateStore()
+	if err := stateStore.Put("swap_chequebook", "chequebook"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := node.InitSettlementReplication(log.Noop, stateStore, "s3:bucket"); err == nil {
+		t.Fatal("expected error for unknown replica")
+	}
+
+	replicaDir := filepath.Join(t.TempDir(), "replica")
+	store, closer, err := node.InitSettlementReplication(log.Noop, stateStore, "statestore:"+replicaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("accounting_balance_1", "balance"); err != nil {
+		t.Fatal(err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replica, err := leveldb.NewStateStore(replicaDir, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = replica.Close() })
+
+	for _, key := range []string{"swap_chequebook", "accounting_balance_1"} {
+		var value string
+		if err := replica.Get(key, &value); err != nil {
+			t.Fatalf("get %s from replica: %v", key, err)
+		}
+	}
+}
+
+func TestInitStateStoreUsage(t *testing.T) {
+	t.Parallel()
+
+	stateStore, err := node.InitStateStore(log.Noop, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = stateStore.Close() })
+
+	// the records are written through the wrappers in the order of the node
+	usageStore, err := node.InitStateStoreUsage(stateStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settlementStore, err := node.InitSettlementNamespace(log.Noop, usageStore, "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chequebook := "00000000000000000000000000000000000000aa"
+	for _, key := range []string{
+		"swap_chequebook_last_received_cheque__" + chequebook + "_" + chequebook,
+		"swap_chequebook_total_issued_" + chequebook + ":",
+		"swap_cheque_cashout_" + chequebook,
+		"transaction_pending_1",
+		"accounting_balance_1",
+	} {
+		if err := settlementStore.Put(key, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := func(store *usage.Store) map[string]uint64 {
+		records := make(map[string]uint64)
+		for _, u := range store.Usage() {
+			records[u.Category] = u.Records
+		}
+		return records
+	}
+	want := map[string]uint64{
+		"issued_cheques":       1,
+		"received_cheques":     1,
+		"history":              1,
+		"pending_transactions": 1,
+		"other":                1,
+	}
+	if got := records(usageStore); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got records %v, want %v", got, want)
+	}
+
+	// existing records are counted by the same categories
+	usageStore, err = node.InitStateStoreUsage(stateStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := records(usageStore); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got existing records %v, want %v", got, want)
+	}
+}
+
+func TestSettlementKeyLayout(t *testing.T) {
+	t.Parallel()
+
+	layout, err := node.SettlementKeyLayout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every registered key is part of the settlement snapshots
+	store, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	keys := layout.Keys()
+	for _, k := range keys {
+		if err := store.Put(k.Pattern, k.Version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap, err := node.SettlementSnapshots(store).Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Records) != len(keys) {
+		t.Fatalf("got %d records in the snapshot, want %d", len(snap.Records), len(keys))
+	}
+
+	chequebook := "00000000000000000000000000000000000000aa"
+	overlay := "000000000000000000000000000000000000000000000000000000000000bbbb"
+	for key, want := range map[string]string{
+		"swap_chequebook_last_received_cheque__" + chequebook + "_" + chequebook:                    "swap_chequebook_last_received_cheque__<chequebook>_<beneficiary>",
+		"swap_chequebook_cheque_history_" + chequebook + ":len_" + chequebook:                       "swap_chequebook_cheque_history_<namespace>:len_<beneficiary>",
+		"swap_chequebook_cheque_history_" + chequebook + ":" + chequebook + "_00000000000000000003": "swap_chequebook_cheque_history_<namespace>:<beneficiary>_<index>",
+		"swap_cashout_" + chequebook:              "swap_cashout_<chequebook>",
+		"swap_cashout_scheduled_" + overlay:       "swap_cashout_scheduled_<peer>",
+		"swap_analytics_purpose_gas_19500_stamps": "swap_analytics_purpose_gas_<day>_<purpose>",
+		"accounting_balance_" + overlay:           "accounting_balance_<peer>",
+	} {
+		k, ok := layout.Match(key)
+		if !ok || k.Pattern != want {
+			t.Fatalf("key %s matched %q %t, want %q", key, k.Pattern, ok, want)
+		}
+	}
+	if k, ok := layout.Match("swap_unknown_record"); ok {
+		t.Fatalf("unknown key matched %q", k.Pattern)
+	}
+
+	// records written through the wrappers of the node are counted by pattern
+	// of their keys without the namespace
+	records, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = records.Close() })
+	usageStore, err := node.InitStateStoreUsage(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settlementStore, err := node.InitSettlementNamespace(log.Noop, usageStore, "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := settlementStore.Put("swap_unknown_record", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := settlementStore.Put("accounting_balance_"+overlay, 1); err != nil {
+		t.Fatal(err)
+	}
+	counts, unknown, err := node.CountSettlementKeys(records, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := counts["accounting_balance_<peer>"]; got != 1 {
+		t.Fatalf("got %d balance records, want 1", got)
+	}
+	if len(unknown) != 1 || unknown[0] != "swap_100:unknown_record" {
+		t.Fatalf("got unknown keys %v, want [swap_100:unknown_record]", unknown)
+	}
+}
@@ -47,6 +47,22 @@ func (l *Limiter) Allow(key string, count int) bool {
 	return limiter.AllowN(time.Now(), count)
 }
 
+// Peek checks if Allow would succeed for 'key' without taking from its limit.
+func (l *Limiter) Peek(key string, count int) bool {
+	l.mtx.Lock()
+	limiter, ok := l.limiter[key]
+	l.mtx.Unlock()
+
+	if !ok {
+		return count <= l.burst
+	}
+
+	now := time.Now()
+	r := limiter.ReserveN(now, count)
+	defer r.CancelAt(now)
+	return r.OK() && r.DelayFrom(now) == 0
+}
+
 // Clear deletes the limiter that belongs to 'key'
 func (l *Limiter) Clear(key string) {
 
@@ -41,3 +41,41 @@ func TestRateLimit(t *testing.T) {
 		t.Fatal("want allowed")
 	}
 }
+
+func TestRateLimitPeek(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key   = "test"
+		burst = 2
+	)
+
+	limiter := ratelimit.New(time.Hour, burst)
+
+	if !limiter.Peek(key, burst) {
+		t.Fatal("want allowed")
+	}
+	if limiter.Peek(key, burst+1) {
+		t.Fatal("want not allowed")
+	}
+
+	if !limiter.Allow(key, 1) {
+		t.Fatal("want allowed")
+	}
+	// peeking does not take from the limit
+	for i := 0; i < 3; i++ {
+		if !limiter.Peek(key, 1) {
+			t.Fatal("want allowed")
+		}
+	}
+	if limiter.Peek(key, burst) {
+		t.Fatal("want not allowed")
+	}
+
+	if !limiter.Allow(key, 1) {
+		t.Fatal("want allowed")
+	}
+	if limiter.Peek(key, 1) {
+		t.Fatal("want not allowed")
+	}
+}
@@ -25,6 +25,7 @@ type (
 	tagKey           struct{}
 	gasPriceKey      struct{}
 	gasLimitKey      struct{}
+	privateSubmitKey struct{}
 )
 
 // SetHost sets the http request host in the context
@@ -87,3 +88,16 @@ func GetGasPrice(ctx context.Context) *big.Int {
 	}
 	return nil
 }
+
+// SetPrivateSubmission requests that the transactions sent with the context
+// are submitted through the private relay if one is configured.
+func SetPrivateSubmission(ctx context.Context, private bool) context.Context {
+	return context.WithValue(ctx, privateSubmitKey{}, private)
+}
+
+// GetPrivateSubmission reports whether the transactions sent with the context
+// are to be submitted through the private relay.
+func GetPrivateSubmission(ctx context.Context) bool {
+	v, _ := ctx.Value(privateSubmitKey{}).(bool)
+	return v
+}
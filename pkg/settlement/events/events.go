@@ -0,0 +1,130 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package events distributes settlement events such as issued, received and
+// bounced cheques, cashouts, deposits, withdrawals, settlement related
+// balance changes and settlement reminders to subscribers.
+package events
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// subscriptionBuffer is the number of events buffered for every subscriber.
+const subscriptionBuffer = 64
+
+// Type is the type of a settlement event.
+type Type string
+
+const (
+	TypeChequeIssued   Type = "cheque_issued"
+	TypeChequeReceived Type = "cheque_received"
+	TypeChequeBounced  Type = "cheque_bounced"
+	TypeCashout        Type = "cashout"
+	TypeDeposited      Type = "deposited"
+	TypeWithdrawn      Type = "withdrawn"
+	TypeBalanceChanged Type = "balance_changed"
+	TypePeerWithdrawn  Type = "peer_withdrawn"
+	TypeBalanceDecline Type = "balance_decline"
+
+	TypeReminderSent     Type = "settlement_reminder_sent"
+	TypeReminderReceived Type = "settlement_reminder_received"
+)
+
+// Event is a single settlement event. Fields not applicable to the type of
+// the event are left empty.
+type Event struct {
+	Type       Type
+	Time       time.Time
+	Peer       swarm.Address
+	Chequebook common.Address
+	Amount     *big.Int    // amount of the cheque, settlement, deposit or withdrawal or the debt of a reminder
+	Payout     *big.Int    // tokens paid by the cheque, deposit or withdrawal
+	Balance    *big.Int    // balance with the peer after the change
+	TxHash     common.Hash // transaction of the cashout, deposit or withdrawal
+}
+
+// Publisher is implemented by components which distribute settlement events.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Feed is a Publisher which delivers the events to all of its subscribers.
+type Feed struct {
+	mu      sync.Mutex
+	subs    map[chan Event]struct{}
+	closed  bool
+	timeNow func() time.Time
+}
+
+var _ Publisher = (*Feed)(nil)
+
+// NewFeed creates a new event feed.
+func NewFeed() *Feed {
+	return &Feed{
+		subs:    make(map[chan Event]struct{}),
+		timeNow: time.Now,
+	}
+}
+
+// Publish delivers the event to all subscribers. Publishing never blocks,
+// events are dropped for subscribers which do not keep up. The time of the
+// event is set if it is zero.
+func (f *Feed) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = f.timeNow()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for c := range f.subs {
+		select {
+		case c <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel on which all published events are delivered
+// and a function which cancels the subscription. The channel is closed once
+// the subscription is cancelled or the feed is closed.
+func (f *Feed) Subscribe() (<-chan Event, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := make(chan Event, subscriptionBuffer)
+	if f.closed {
+		close(c)
+		return c, func() {}
+	}
+	f.subs[c] = struct{}{}
+
+	return c, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subs[c]; ok {
+			delete(f.subs, c)
+			close(c)
+		}
+	}
+}
+
+// Close cancels all subscriptions.
+func (f *Feed) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for c := range f.subs {
+		delete(f.subs, c)
+		close(c)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestFeed(t *testing.T) {
+	t.Parallel()
+
+	feed := events.NewFeed()
+
+	c1, cancel1 := feed.Subscribe()
+	c2, cancel2 := feed.Subscribe()
+	defer cancel2()
+
+	peer := swarm.MustParseHexAddress("abcd")
+	feed.Publish(events.Event{Type: events.TypeChequeIssued, Peer: peer, Amount: big.NewInt(10)})
+
+	for _, c := range []<-chan events.Event{c1, c2} {
+		e := <-c
+		if e.Type != events.TypeChequeIssued || !e.Peer.Equal(peer) || e.Amount.Int64() != 10 {
+			t.Fatalf("unexpected event %+v", e)
+		}
+		if e.Time.IsZero() {
+			t.Fatal("event time not set")
+		}
+	}
+
+	cancel1()
+	if _, ok := <-c1; ok {
+		t.Fatal("channel not closed after cancel")
+	}
+	// cancelling twice is harmless
+	cancel1()
+
+	if err := feed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-c2; ok {
+		t.Fatal("channel not closed after close")
+	}
+
+	c3, _ := feed.Subscribe()
+	if _, ok := <-c3; ok {
+		t.Fatal("subscription on closed feed not closed")
+	}
+}
+
+func TestFeedSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	feed := events.NewFeed()
+	c, cancel := feed.Subscribe()
+	defer cancel()
+
+	// publishing must not block even if the subscriber does not read
+	for i := 0; i < 1000; i++ {
+		feed.Publish(events.Event{Type: events.TypeBalanceChanged})
+	}
+	if len(c) == 0 {
+		t.Fatal("no events buffered")
+	}
+}
@@ -5,6 +5,7 @@
 package settlement
 
 import (
+	"context"
 	"errors"
 	"math/big"
 
@@ -22,7 +23,7 @@ type Interface interface {
 	// TotalReceived returns the total amount received from a peer
 	TotalReceived(peer swarm.Address) (totalSent *big.Int, err error)
 	// SettlementsSent returns sent settlements for each individual known peer
-	SettlementsSent() (map[string]*big.Int, error)
+	SettlementsSent(ctx context.Context) (map[string]*big.Int, error)
 	// SettlementsReceived returns received settlements for each individual known peer
 	SettlementsReceived() (map[string]*big.Int, error)
 }
@@ -0,0 +1,157 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package keylayout documents the layout of the settlement records in the
+// statestore. Every package writing settlement records describes the keys it
+// uses, the type of their values and the schema version of their layout, so
+// that the debug API and the migration and repair tools never have to guess
+// key formats.
+package keylayout
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidLayout is the error returned if a key layout is malformed or
+// registered twice.
+var ErrInvalidLayout = errors.New("invalid key layout")
+
+// Key describes the statestore keys of one kind of settlement record.
+type Key struct {
+	// Pattern is the key with its variable parts as placeholders in angle
+	// brackets, like "swap_receipt_<beneficiary>". See Placeholders for the
+	// format of the variable parts.
+	Pattern string
+	// Value is the Go type of the JSON encoded value.
+	Value string
+	// Version is the schema version of the layout of the key and its value.
+	// It is increased whenever existing records have to be migrated.
+	Version int
+	// Description explains what the records hold.
+	Description string
+}
+
+// Prefix returns the constant part all keys of the pattern start with.
+func (k Key) Prefix() string {
+	prefix, _, _ := strings.Cut(k.Pattern, "<")
+	return prefix
+}
+
+// Placeholders are the formats of the variable parts of key patterns.
+// Placeholders of other names match any non-empty string.
+var Placeholders = map[string]string{
+	"beneficiary": "hex encoded ethereum address",
+	"chequebook":  "hex encoded ethereum address",
+	"namespace":   "hex encoded address of the own chequebook",
+	"peer":        "hex encoded overlay address",
+	"tx":          "hex encoded transaction hash",
+	"index":       "zero padded decimal or hex sequence number",
+	"time":        "zero padded decimal unix timestamp",
+	"block":       "zero padded hex block number",
+	"log":         "zero padded hex log index",
+	"day":         "decimal number of days since the unix epoch",
+}
+
+var placeholderExprs = map[string]string{
+	"beneficiary": `[0-9a-f]{40}`,
+	"chequebook":  `[0-9a-f]{40}`,
+	"namespace":   `[0-9a-f]{40}`,
+	"peer":        `[0-9a-f]+`,
+	"tx":          `[0-9a-f]{64}`,
+	"index":       `[0-9a-f]+`,
+	"time":        `[0-9]+`,
+	"block":       `[0-9a-f]{16}`,
+	"log":         `[0-9a-f]{8}`,
+	"day":         `-?[0-9]+`,
+}
+
+var placeholderRe = regexp.MustCompile(`<([a-z]+)>`)
+
+// compile returns the expression matching the keys of the pattern.
+func compile(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	rest := pattern
+	for {
+		loc := placeholderRe.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:loc[0]]))
+		expr, ok := placeholderExprs[rest[loc[2]:loc[3]]]
+		if !ok {
+			expr = `.+`
+		}
+		b.WriteString("(" + expr + ")")
+		rest = rest[loc[1]:]
+	}
+	if strings.ContainsAny(rest, "<>") {
+		return nil, fmt.Errorf("pattern %q: unterminated placeholder: %w", pattern, ErrInvalidLayout)
+	}
+	b.WriteString(regexp.QuoteMeta(rest))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Registry holds the layout of all settlement records.
+type Registry struct {
+	keys []Key
+	exps []*regexp.Regexp
+}
+
+// New creates a registry of the given keys. It fails with ErrInvalidLayout if
+// a pattern is malformed or registered twice, or a version is not positive.
+func New(keys ...Key) (*Registry, error) {
+	r := &Registry{
+		keys: append([]Key(nil), keys...),
+	}
+	sort.Slice(r.keys, func(i, j int) bool {
+		return r.keys[i].Pattern < r.keys[j].Pattern
+	})
+	r.exps = make([]*regexp.Regexp, len(r.keys))
+	for i, k := range r.keys {
+		if k.Pattern == "" || k.Value == "" {
+			return nil, fmt.Errorf("pattern %q: missing pattern or value: %w", k.Pattern, ErrInvalidLayout)
+		}
+		if k.Version < 1 {
+			return nil, fmt.Errorf("pattern %q: version %d: %w", k.Pattern, k.Version, ErrInvalidLayout)
+		}
+		if i > 0 && r.keys[i-1].Pattern == k.Pattern {
+			return nil, fmt.Errorf("pattern %q: registered twice: %w", k.Pattern, ErrInvalidLayout)
+		}
+		exp, err := compile(k.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.exps[i] = exp
+	}
+	return r, nil
+}
+
+// Keys returns the registered keys ordered by pattern.
+func (r *Registry) Keys() []Key {
+	return append([]Key(nil), r.keys...)
+}
+
+// Match returns the layout of the key. If several patterns match, the one
+// with the longest prefix is returned. It reports false for unknown keys.
+func (r *Registry) Match(key string) (Key, bool) {
+	var (
+		match Key
+		found bool
+	)
+	for i, k := range r.keys {
+		if !strings.HasPrefix(key, k.Prefix()) || !r.exps[i].MatchString(key) {
+			continue
+		}
+		if !found || len(k.Prefix()) > len(match.Prefix()) {
+			match, found = k, true
+		}
+	}
+	return match, found
+}
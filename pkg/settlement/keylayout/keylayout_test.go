@@ -0,0 +1,75 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keylayout_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	layout, err := keylayout.New(
+		keylayout.Key{Pattern: "swap_cashout_<chequebook>", Value: "action", Version: 1},
+		keylayout.Key{Pattern: "swap_cashout_attempt_<chequebook>_<time>", Value: "attempt", Version: 2},
+		keylayout.Key{Pattern: "swap_history_<beneficiary>_<index>", Value: "entry", Version: 1},
+		keylayout.Key{Pattern: "swap_history_len_<beneficiary>", Value: "uint64", Version: 1},
+		keylayout.Key{Pattern: "swap_seq", Value: "uint64", Version: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := layout.Keys()
+	if len(keys) != 5 || keys[0].Pattern != "swap_cashout_<chequebook>" || keys[0].Prefix() != "swap_cashout_" {
+		t.Fatalf("got keys %v", keys)
+	}
+
+	address := "00000000000000000000000000000000000000aa"
+	for key, want := range map[string]string{
+		"swap_cashout_" + address:                                   "swap_cashout_<chequebook>",
+		"swap_cashout_attempt_" + address + "_00000000001700000000": "swap_cashout_attempt_<chequebook>_<time>",
+		"swap_history_" + address + "_00000000000000000001":         "swap_history_<beneficiary>_<index>",
+		"swap_history_len_" + address:                               "swap_history_len_<beneficiary>",
+		"swap_seq":                                                  "swap_seq",
+	} {
+		k, ok := layout.Match(key)
+		if !ok || k.Pattern != want {
+			t.Fatalf("key %s matched %q %t, want %q", key, k.Pattern, ok, want)
+		}
+	}
+
+	for _, key := range []string{
+		"swap_cashout_aa",
+		"swap_seq_1",
+		"swap_history_len_",
+		"accounting_balance_aa",
+	} {
+		if k, ok := layout.Match(key); ok {
+			t.Fatalf("key %s matched %q", key, k.Pattern)
+		}
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		keys []keylayout.Key
+	}{
+		{"duplicate", []keylayout.Key{{Pattern: "a_<peer>", Value: "v", Version: 1}, {Pattern: "a_<peer>", Value: "w", Version: 2}}},
+		{"no version", []keylayout.Key{{Pattern: "a_<peer>", Value: "v"}}},
+		{"no value", []keylayout.Key{{Pattern: "a_<peer>", Version: 1}}},
+		{"unterminated", []keylayout.Key{{Pattern: "a_<peer", Value: "v", Version: 1}}},
+	} {
+		if _, err := keylayout.New(tc.keys...); !errors.Is(err, keylayout.ErrInvalidLayout) {
+			t.Fatalf("%s: got error %v, want %v", tc.name, err, keylayout.ErrInvalidLayout)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pseudosettle
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the time based settlement.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     SettlementReceivedPrefix + "<peer>",
+		Value:       "pseudosettle.lastPayment",
+		Version:     1,
+		Description: "total amount and time of the last payment received from the peer",
+	},
+	{
+		Pattern:     SettlementSentPrefix + "<peer>",
+		Value:       "pseudosettle.lastPayment",
+		Version:     1,
+		Description: "total amount and time of the last payment sent to the peer",
+	},
+}
@@ -393,7 +393,7 @@ func (s *Service) TotalReceived(peer swarm.Address) (totalReceived *big.Int, err
 }
 
 // SettlementsSent returns all stored sent settlement values for a given type of prefix
-func (s *Service) SettlementsSent() (map[string]*big.Int, error) {
+func (s *Service) SettlementsSent(context.Context) (map[string]*big.Int, error) {
 	sent := make(map[string]*big.Int)
 	err := s.store.Iterate(SettlementSentPrefix, func(key, val []byte) (stop bool, err error) {
 		addr, err := totalKeyPeer(key, SettlementSentPrefix)
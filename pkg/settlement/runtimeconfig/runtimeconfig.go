@@ -0,0 +1,237 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package runtimeconfig holds the settlement tunables which can be changed
+// while the node is running. Every setting is registered by name together
+// with functions reading, validating and applying its value, so that changes
+// made through the API take effect without a restart. Changes are not
+// persisted, the configured values apply again after a restart.
+package runtimeconfig
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ErrUnknownSetting is the error returned if a setting is not registered.
+	ErrUnknownSetting = errors.New("unknown setting")
+	// ErrInvalidValue is the error returned if a value is rejected by the validation of its setting.
+	ErrInvalidValue = errors.New("invalid setting value")
+)
+
+// Setting is a tunable which can be changed at runtime. Values are passed as
+// strings in the format of the corresponding command line flag.
+type Setting struct {
+	Description string
+	// Get returns the current value.
+	Get func() string
+	// Validate checks the value without applying it.
+	Validate func(value string) error
+	// Set applies a value which passed Validate.
+	Set func(value string) error
+}
+
+// Value is the current value of a setting.
+type Value struct {
+	Name        string
+	Value       string
+	Description string
+}
+
+// Change is an applied change of a setting.
+type Change struct {
+	Name     string
+	Previous string
+	Value    string
+}
+
+// ChangeHook is called for every applied change with the actor who made it.
+type ChangeHook func(change Change, actor string)
+
+// Registry holds the registered settings.
+type Registry struct {
+	mu       sync.Mutex
+	settings map[string]Setting
+	hooks    []ChangeHook
+}
+
+// New creates an empty Registry.
+func New() *Registry {
+	return &Registry{
+		settings: make(map[string]Setting),
+	}
+}
+
+// Register adds the setting under name, replacing a setting of the same name.
+func (r *Registry) Register(name string, s Setting) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings[name] = s
+}
+
+// OnChange registers a hook called for every applied change.
+func (r *Registry) OnChange(hook ChangeHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Values returns the current values of all settings ordered by name.
+func (r *Registry) Values() []Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := make([]Value, 0, len(r.settings))
+	for name, s := range r.settings {
+		values = append(values, Value{Name: name, Value: s.Get(), Description: s.Description})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+	return values
+}
+
+// Update validates all values first and only applies them if every one is
+// valid, so that an update is never applied partially because of a typo.
+// Values equal to the current ones are not changed. The applied changes are
+// returned ordered by name and passed to the change hooks with the actor.
+func (r *Registry) Update(values map[string]string, actor string) ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(values))
+	for name, value := range values {
+		s, ok := r.settings[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSetting, name)
+		}
+		if err := s.Validate(value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidValue, name, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for _, name := range names {
+		s := r.settings[name]
+		previous := s.Get()
+		if previous == values[name] {
+			continue
+		}
+		if err := s.Set(values[name]); err != nil {
+			return changes, fmt.Errorf("apply %s: %w", name, err)
+		}
+		change := Change{Name: name, Previous: previous, Value: s.Get()}
+		changes = append(changes, change)
+		for _, hook := range r.hooks {
+			hook(change, actor)
+		}
+	}
+	return changes, nil
+}
+
+// Bool returns a setting of a boolean value.
+func Bool(description string, get func() bool, set func(bool)) Setting {
+	return Setting{
+		Description: description,
+		Get:         func() string { return strconv.FormatBool(get()) },
+		Validate: func(value string) error {
+			_, err := strconv.ParseBool(value)
+			return err
+		},
+		Set: func(value string) error {
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			set(v)
+			return nil
+		},
+	}
+}
+
+// Int returns a setting of an integer value accepted by set.
+func Int(description string, get func() int, set func(int) error, validate func(int) error) Setting {
+	parse := func(value string) (int, error) {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, err
+		}
+		return v, validate(v)
+	}
+	return Setting{
+		Description: description,
+		Get:         func() string { return strconv.Itoa(get()) },
+		Validate: func(value string) error {
+			_, err := parse(value)
+			return err
+		},
+		Set: func(value string) error {
+			v, err := parse(value)
+			if err != nil {
+				return err
+			}
+			return set(v)
+		},
+	}
+}
+
+// Float returns a setting of a floating point value accepted by set.
+func Float(description string, get func() float64, set func(float64) error, validate func(float64) error) Setting {
+	parse := func(value string) (float64, error) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, err
+		}
+		return v, validate(v)
+	}
+	return Setting{
+		Description: description,
+		Get:         func() string { return strconv.FormatFloat(get(), 'f', -1, 64) },
+		Validate: func(value string) error {
+			_, err := parse(value)
+			return err
+		},
+		Set: func(value string) error {
+			v, err := parse(value)
+			if err != nil {
+				return err
+			}
+			return set(v)
+		},
+	}
+}
+
+// BigInt returns a setting of a non-negative integer amount.
+func BigInt(description string, get func() *big.Int, set func(*big.Int)) Setting {
+	parse := func(value string) (*big.Int, error) {
+		v, ok := new(big.Int).SetString(value, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("%q is not a non-negative integer", value)
+		}
+		return v, nil
+	}
+	return Setting{
+		Description: description,
+		Get:         func() string { return get().String() },
+		Validate: func(value string) error {
+			_, err := parse(value)
+			return err
+		},
+		Set: func(value string) error {
+			v, err := parse(value)
+			if err != nil {
+				return err
+			}
+			set(v)
+			return nil
+		},
+	}
+}
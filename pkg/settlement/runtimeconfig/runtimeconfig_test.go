@@ -0,0 +1,113 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtimeconfig_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/runtimeconfig"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		pause      bool
+		inFlight   = 4
+		maxDecline = big.NewInt(100)
+	)
+	errNotPositive := errors.New("not positive")
+
+	newRegistry := func() *runtimeconfig.Registry {
+		r := runtimeconfig.New()
+		r.Register("pause", runtimeconfig.Bool("pause", func() bool { return pause }, func(v bool) { pause = v }))
+		r.Register("in-flight", runtimeconfig.Int("in flight",
+			func() int { return inFlight },
+			func(v int) error { inFlight = v; return nil },
+			func(v int) error {
+				if v <= 0 {
+					return errNotPositive
+				}
+				return nil
+			},
+		))
+		r.Register("max-decline", runtimeconfig.BigInt("max decline",
+			func() *big.Int { return maxDecline },
+			func(v *big.Int) { maxDecline = v },
+		))
+		return r
+	}
+
+	t.Run("values", func(t *testing.T) {
+		values := newRegistry().Values()
+		want := []runtimeconfig.Value{
+			{Name: "in-flight", Value: "4", Description: "in flight"},
+			{Name: "max-decline", Value: "100", Description: "max decline"},
+			{Name: "pause", Value: "false", Description: "pause"},
+		}
+		if len(values) != len(want) {
+			t.Fatalf("got %d values, want %d", len(values), len(want))
+		}
+		for i := range want {
+			if values[i] != want[i] {
+				t.Fatalf("got value %+v, want %+v", values[i], want[i])
+			}
+		}
+	})
+
+	t.Run("invalid values are not applied", func(t *testing.T) {
+		r := newRegistry()
+
+		_, err := r.Update(map[string]string{"pause": "true", "in-flight": "0"}, "test")
+		if !errors.Is(err, runtimeconfig.ErrInvalidValue) {
+			t.Fatalf("got error %v, want %v", err, runtimeconfig.ErrInvalidValue)
+		}
+		_, err = r.Update(map[string]string{"pause": "true", "unknown": "1"}, "test")
+		if !errors.Is(err, runtimeconfig.ErrUnknownSetting) {
+			t.Fatalf("got error %v, want %v", err, runtimeconfig.ErrUnknownSetting)
+		}
+		_, err = r.Update(map[string]string{"max-decline": "-1"}, "test")
+		if !errors.Is(err, runtimeconfig.ErrInvalidValue) {
+			t.Fatalf("got error %v, want %v", err, runtimeconfig.ErrInvalidValue)
+		}
+		if pause || inFlight != 4 || maxDecline.Int64() != 100 {
+			t.Fatalf("settings changed by a rejected update: %v %d %d", pause, inFlight, maxDecline)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		r := newRegistry()
+
+		var hooked []runtimeconfig.Change
+		r.OnChange(func(change runtimeconfig.Change, actor string) {
+			if actor != "test" {
+				t.Errorf("got actor %q, want %q", actor, "test")
+			}
+			hooked = append(hooked, change)
+		})
+
+		changes, err := r.Update(map[string]string{"pause": "true", "in-flight": "8", "max-decline": "100"}, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []runtimeconfig.Change{
+			{Name: "in-flight", Previous: "4", Value: "8"},
+			{Name: "pause", Previous: "false", Value: "true"},
+		}
+		if len(changes) != len(want) || len(hooked) != len(want) {
+			t.Fatalf("got %d changes and %d hooked, want %d", len(changes), len(hooked), len(want))
+		}
+		for i := range want {
+			if changes[i] != want[i] || hooked[i] != want[i] {
+				t.Fatalf("got change %+v and hooked %+v, want %+v", changes[i], hooked[i], want[i])
+			}
+		}
+		if !pause || inFlight != 8 {
+			t.Fatalf("settings not applied: %v %d", pause, inFlight)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package snapshot takes consistent snapshots of the settlement state, like
+// issued and received cheques, balances, counters and pending transactions,
+// and restores them atomically.
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Version is the version of the snapshots taken by this package.
+const Version = 1
+
+var (
+	// ErrUnsupportedStore is the error returned if the state store is not backed by leveldb.
+	ErrUnsupportedStore = errors.New("state store does not support snapshots")
+	// ErrUnsupportedVersion is the error returned when restoring a snapshot of an unknown version.
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+	// ErrForeignKey is the error returned when restoring a snapshot with a record outside of the settlement state.
+	ErrForeignKey = errors.New("snapshot record outside of settlement state")
+)
+
+// Record is a single stored key and its value. Keys and values are taken as
+// stored in the database, so namespaced keys keep their namespace and
+// encrypted values stay encrypted. A snapshot can therefore only be restored
+// by a node with the same settlement namespace and encryption key.
+type Record struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Snapshot is the settlement state at a point in time.
+type Snapshot struct {
+	Version  int      `json:"version"`
+	Time     int64    `json:"time"`     // unix timestamp when the snapshot was taken
+	Prefixes []string `json:"prefixes"` // key prefixes of the settlement state
+	Records  []Record `json:"records"`  // records in key order
+}
+
+// Service takes and restores snapshots of the keys with the given prefixes.
+type Service struct {
+	store    storage.StateStorer
+	prefixes []string
+}
+
+// New creates a service for the settlement state held in the store under the prefixes.
+func New(store storage.StateStorer, prefixes ...string) *Service {
+	return &Service{
+		store:    store,
+		prefixes: prefixes,
+	}
+}
+
+func (s *Service) db() (*leveldb.DB, error) {
+	db := s.store.DB()
+	if db == nil {
+		return nil, ErrUnsupportedStore
+	}
+	return db, nil
+}
+
+func (s *Service) settlementKey(key string) bool {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns the settlement state as of a single point in time. Writes
+// made while the snapshot is taken are not part of it.
+func (s *Service) Snapshot(ctx context.Context) (*Snapshot, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	dbSnapshot, err := db.GetSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("database snapshot: %w", err)
+	}
+	defer dbSnapshot.Release()
+
+	snapshot := &Snapshot{
+		Version:  Version,
+		Time:     time.Now().Unix(),
+		Prefixes: append([]string(nil), s.prefixes...),
+		Records:  make([]Record, 0),
+	}
+	for _, prefix := range s.prefixes {
+		iter := dbSnapshot.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+		for iter.Next() {
+			if err := ctx.Err(); err != nil {
+				iter.Release()
+				return nil, err
+			}
+			snapshot.Records = append(snapshot.Records, Record{
+				Key:   string(iter.Key()),
+				Value: append([]byte(nil), iter.Value()...),
+			})
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, fmt.Errorf("iterate %s: %w", prefix, err)
+		}
+	}
+
+	sort.Slice(snapshot.Records, func(i, j int) bool {
+		return snapshot.Records[i].Key < snapshot.Records[j].Key
+	})
+	return snapshot, nil
+}
+
+// Restore replaces the settlement state with the one of the snapshot in a
+// single atomic write. Records of the current state missing in the snapshot
+// are deleted. Components caching settlement state in memory do not see the
+// restored state, so the node should not be running while restoring.
+func (s *Service) Restore(ctx context.Context, snapshot *Snapshot) error {
+	if snapshot.Version != Version {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, snapshot.Version)
+	}
+	for _, record := range snapshot.Records {
+		if !s.settlementKey(record.Key) {
+			return fmt.Errorf("%w: %s", ErrForeignKey, record.Key)
+		}
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	for _, prefix := range s.prefixes {
+		iter := db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+		for iter.Next() {
+			if err := ctx.Err(); err != nil {
+				iter.Release()
+				return err
+			}
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return fmt.Errorf("iterate %s: %w", prefix, err)
+		}
+	}
+	for _, record := range snapshot.Records {
+		batch.Put([]byte(record.Key), record.Value)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.Write(batch, nil)
+}
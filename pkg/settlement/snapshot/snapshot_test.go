@@ -0,0 +1,126 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/snapshot"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func newStore(t *testing.T) storage.StateStorer {
+	t.Helper()
+
+	store, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func put(t *testing.T, store storage.StateStorer, key string, value int) {
+	t.Helper()
+
+	if err := store.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expect(t *testing.T, store storage.StateStorer, key string, want int) {
+	t.Helper()
+
+	var got int
+	err := store.Get(key, &got)
+	if want < 0 {
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v for %s, want %v", err, key, storage.ErrNotFound)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("got %d for %s, want %d", got, key, want)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	store := newStore(t)
+	service := snapshot.New(store, "swap_", "accounting_")
+
+	put(t, store, "swap_cheque", 1)
+	put(t, store, "accounting_balance", 2)
+	put(t, store, "other", 3)
+
+	snap, err := service.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Version != snapshot.Version || len(snap.Records) != 2 {
+		t.Fatalf("got snapshot version %d with %d records, want version %d with 2 records", snap.Version, len(snap.Records), snapshot.Version)
+	}
+	if snap.Records[0].Key != "accounting_balance" || snap.Records[1].Key != "swap_cheque" {
+		t.Fatalf("got records %v, want in key order", snap.Records)
+	}
+
+	put(t, store, "swap_cheque", 10)
+	put(t, store, "swap_new", 11)
+	if err := store.Delete("accounting_balance"); err != nil {
+		t.Fatal(err)
+	}
+	put(t, store, "other", 12)
+
+	if err := service.Restore(context.Background(), snap); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, store, "swap_cheque", 1)
+	expect(t, store, "accounting_balance", 2)
+	expect(t, store, "swap_new", -1)
+	// keys outside of the settlement state are not touched
+	expect(t, store, "other", 12)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	t.Parallel()
+
+	store := newStore(t)
+	service := snapshot.New(store, "swap_")
+	put(t, store, "swap_cheque", 1)
+
+	err := service.Restore(context.Background(), &snapshot.Snapshot{Version: snapshot.Version + 1})
+	if !errors.Is(err, snapshot.ErrUnsupportedVersion) {
+		t.Fatalf("got error %v, want %v", err, snapshot.ErrUnsupportedVersion)
+	}
+
+	err = service.Restore(context.Background(), &snapshot.Snapshot{
+		Version: snapshot.Version,
+		Records: []snapshot.Record{{Key: "swap_cheque", Value: []byte("2")}, {Key: "other", Value: []byte("3")}},
+	})
+	if !errors.Is(err, snapshot.ErrForeignKey) {
+		t.Fatalf("got error %v, want %v", err, snapshot.ErrForeignKey)
+	}
+	// nothing was restored
+	expect(t, store, "swap_cheque", 1)
+}
+
+func TestUnsupportedStore(t *testing.T) {
+	t.Parallel()
+
+	service := snapshot.New(mock.NewStateStore(), "swap_")
+	if _, err := service.Snapshot(context.Background()); !errors.Is(err, snapshot.ErrUnsupportedStore) {
+		t.Fatalf("got error %v, want %v", err, snapshot.ErrUnsupportedStore)
+	}
+}
@@ -5,8 +5,12 @@
 package swap
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -32,6 +36,8 @@ type Addressbook interface {
 	BeneficiaryPeer(beneficiary common.Address) (peer swarm.Address, known bool, err error)
 	// ChequebookPeer returns the peer for a beneficiary.
 	ChequebookPeer(chequebook common.Address) (peer swarm.Address, known bool, err error)
+	// Chequebooks returns all chequebooks the peer sent cheques from, the current one first.
+	Chequebooks(peer swarm.Address) ([]common.Address, error)
 	// PutBeneficiary stores the beneficiary for the given peer.
 	PutBeneficiary(peer swarm.Address, beneficiary common.Address) error
 	// PutChequebook stores the chequebook for the given peer.
@@ -82,6 +88,17 @@ func (a *addressbook) MigratePeer(oldPeer, newPeer swarm.Address) error {
 	}
 
 	if known {
+		// chequebooks the peer used before still owe us their uncashed cheques
+		chequebooks, err := a.Chequebooks(oldPeer)
+		if err != nil {
+			return err
+		}
+		for _, chequebook := range chequebooks[1:] {
+			if err := a.store.Put(chequebookPeerKey(chequebook), newPeer); err != nil {
+				return err
+			}
+		}
+
 		if err := a.PutChequebook(newPeer, cb); err != nil {
 			return err
 		}
@@ -141,6 +158,43 @@ func (a *addressbook) ChequebookPeer(chequebook common.Address) (peer swarm.Addr
 	return peer, true, nil
 }
 
+// Chequebooks returns all chequebooks the peer sent cheques from, the current
+// one first. A peer which redeployed its chequebook keeps the mapping of its
+// previous chequebooks to the peer.
+func (a *addressbook) Chequebooks(peer swarm.Address) ([]common.Address, error) {
+	current, known, err := a.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, nil
+	}
+
+	chequebooks := []common.Address{current}
+	err = a.store.Iterate(peerChequebookPrefix, func(key, value []byte) (stop bool, err error) {
+		if !strings.HasPrefix(string(key), peerChequebookPrefix) {
+			return true, nil
+		}
+		var chequebookPeer swarm.Address
+		if err := json.Unmarshal(value, &chequebookPeer); err != nil {
+			return true, fmt.Errorf("chequebook peer %s: %w", key, err)
+		}
+		chequebook := common.HexToAddress(strings.TrimPrefix(string(key), peerChequebookPrefix))
+		if chequebookPeer.Equal(peer) && chequebook != current {
+			chequebooks = append(chequebooks, chequebook)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	previous := chequebooks[1:]
+	sort.Slice(previous, func(i, j int) bool {
+		return bytes.Compare(previous[i].Bytes(), previous[j].Bytes()) < 0
+	})
+	return chequebooks, nil
+}
+
 // PutBeneficiary stores the beneficiary for the given peer.
 func (a *addressbook) PutBeneficiary(peer swarm.Address, beneficiary common.Address) error {
 	err := a.store.Put(peerBeneficiaryKey(peer), beneficiary)
@@ -0,0 +1,250 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package analytics derives operator facing figures from the settlement events
+// of the swap service: the rolling spend per beneficiary, the daily burn rate
+// and the projected time until the chequebook is empty on the issuing side and
+// the earnings per peer, their cashouts and the gas spent on them on the
+// receiving side.
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// DefaultWindow is the default period over which amounts are accumulated.
+	DefaultWindow = 7 * 24 * time.Hour
+
+	day = 24 * time.Hour
+)
+
+// PeerAmount is the amount accumulated for a peer within the window.
+type PeerAmount struct {
+	Peer   swarm.Address
+	Amount *big.Int
+}
+
+// rolling accumulates amounts per peer in daily buckets which are persisted
+// in the state store, so that the figures survive restarts. Buckets older than
+// the window are removed.
+type rolling struct {
+	store  storage.StateStorer
+	prefix string
+	window int64 // number of days
+
+	mu   sync.Mutex
+	days map[int64]map[string]*big.Int // day -> peer -> amount
+}
+
+func newRolling(store storage.StateStorer, prefix string, window time.Duration, now time.Time) (*rolling, error) {
+	days := int64(window / day)
+	if days < 1 {
+		days = 1
+	}
+	r := &rolling{
+		store:  store,
+		prefix: prefix,
+		window: days,
+		days:   make(map[int64]map[string]*big.Int),
+	}
+
+	err := store.Iterate(prefix, func(key, value []byte) (bool, error) {
+		d, peer, err := r.parseKey(string(key))
+		if err != nil {
+			return true, err
+		}
+		amount := new(big.Int)
+		if err := json.Unmarshal(value, amount); err != nil {
+			return true, fmt.Errorf("analytics record %s: %w", key, err)
+		}
+		if r.days[d] == nil {
+			r.days[d] = make(map[string]*big.Int)
+		}
+		r.days[d][peer] = amount
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.prune(dayOf(now)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func dayOf(t time.Time) int64 {
+	return t.Unix() / int64(day/time.Second)
+}
+
+func (r *rolling) key(d int64, peer string) string {
+	return fmt.Sprintf("%s%d_%s", r.prefix, d, peer)
+}
+
+func (r *rolling) parseKey(key string) (int64, string, error) {
+	d, peer, ok := strings.Cut(strings.TrimPrefix(key, r.prefix), "_")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid analytics key %s", key)
+	}
+	n, err := strconv.ParseInt(d, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid analytics key %s: %w", key, err)
+	}
+	return n, peer, nil
+}
+
+// prune removes the buckets before the window ending with the given day. It
+// must be called with the lock held.
+func (r *rolling) prune(today int64) error {
+	for d, peers := range r.days {
+		if d > today-r.window {
+			continue
+		}
+		for peer := range peers {
+			if err := r.store.Delete(r.key(d, peer)); err != nil {
+				return err
+			}
+		}
+		delete(r.days, d)
+	}
+	return nil
+}
+
+// add adds the amount to the bucket of the peer for the day of t.
+func (r *rolling) add(peer swarm.Address, t time.Time, amount *big.Int) error {
+	return r.addKey(peer.String(), t, amount)
+}
+
+// addKey adds the amount to the bucket of the key for the day of t.
+func (r *rolling) addKey(k string, t time.Time, amount *big.Int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := dayOf(t)
+	if err := r.prune(d); err != nil {
+		return err
+	}
+	if r.days[d] == nil {
+		r.days[d] = make(map[string]*big.Int)
+	}
+	total, ok := r.days[d][k]
+	if !ok {
+		total = new(big.Int)
+	}
+	total = new(big.Int).Add(total, amount)
+	if err := r.store.Put(r.key(d, k), total); err != nil {
+		return err
+	}
+	r.days[d][k] = total
+	return nil
+}
+
+// keyTotals returns the totals per key of the last days ending at now. The
+// number of days is capped at the window.
+func (r *rolling) keyTotals(now time.Time, days int64) map[string]*big.Int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if days < 1 || days > r.window {
+		days = r.window
+	}
+	today := dayOf(now)
+	totals := make(map[string]*big.Int)
+	for d, amounts := range r.days {
+		if d <= today-days || d > today {
+			continue
+		}
+		for k, amount := range amounts {
+			if totals[k] == nil {
+				totals[k] = new(big.Int)
+			}
+			totals[k].Add(totals[k], amount)
+		}
+	}
+	return totals
+}
+
+// totals returns the total within the window ending at now, the totals per
+// peer in descending order and the number of days the total was accumulated
+// over, counting from the first day with a record.
+func (r *rolling) totals(now time.Time) (total *big.Int, peers []PeerAmount, days int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	today := dayOf(now)
+	total = new(big.Int)
+	perPeer := make(map[string]*big.Int)
+	first := today
+	for d, amounts := range r.days {
+		if d <= today-r.window || d > today {
+			continue
+		}
+		if d < first {
+			first = d
+		}
+		for p, amount := range amounts {
+			total.Add(total, amount)
+			if perPeer[p] == nil {
+				perPeer[p] = new(big.Int)
+			}
+			perPeer[p].Add(perPeer[p], amount)
+		}
+	}
+
+	for p, amount := range perPeer {
+		peer, err := swarm.ParseHexAddress(p)
+		if err != nil {
+			continue
+		}
+		peers = append(peers, PeerAmount{Peer: peer, Amount: amount})
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if c := peers[i].Amount.Cmp(peers[j].Amount); c != 0 {
+			return c > 0
+		}
+		return peers[i].Peer.String() < peers[j].Peer.String()
+	})
+
+	return total, peers, today - first + 1
+}
+
+// DayAmount is the amount accumulated on a day.
+type DayAmount struct {
+	Day    time.Time // start of the day in UTC
+	Amount *big.Int
+}
+
+// daily returns the totals of every day of the window ending at now, oldest
+// first.
+func (r *rolling) daily(now time.Time) []DayAmount {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	today := dayOf(now)
+	amounts := make([]DayAmount, 0, r.window)
+	for d := today - r.window + 1; d <= today; d++ {
+		total := new(big.Int)
+		for _, amount := range r.days[d] {
+			total.Add(total, amount)
+		}
+		amounts = append(amounts, DayAmount{
+			Day:    time.Unix(d*int64(day/time.Second), 0).UTC(),
+			Amount: total,
+		})
+	}
+	return amounts
+}
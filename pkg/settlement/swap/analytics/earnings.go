@@ -0,0 +1,351 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	earnedKeyPrefix         = "swap_analytics_earned_"
+	cashedKeyPrefix         = "swap_analytics_cashed_"
+	cashoutGasKeyPrefix     = "swap_analytics_cashout_gas_"
+	deductedKeyPrefix       = "swap_analytics_cashout_deducted_"
+	pendingCashoutKeyPrefix = "swap_analytics_cashout_pending_"
+)
+
+// CashoutStatusFunc returns the status of the last cashout of the cheques
+// received from the peer.
+type CashoutStatusFunc func(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error)
+
+// FeeFunc returns the fee paid for a mined transaction.
+type FeeFunc func(ctx context.Context, txHash common.Hash) (*big.Int, error)
+
+// PeerEarnings are the earnings from a peer within the window.
+type PeerEarnings struct {
+	Peer      swarm.Address
+	Earned    *big.Int // tokens received in cheques
+	Cashed    *big.Int // tokens paid out to the node by confirmed cashouts
+	Deducted  *big.Int // tokens of the cashed cheques paid out to the cashout callers
+	Uncashed  *big.Int // tokens of the received cheques not cashed out yet, nil if unknown
+	Reserved  *big.Int // expected deduction when cashing out the uncashed tokens
+	Gas       *big.Int // fees of the cashout transactions
+	Effective *big.Int // earned minus deducted, reserved and gas
+}
+
+// DayEarnings are the earnings of a single day.
+type DayEarnings struct {
+	Day    time.Time // start of the day in UTC
+	Earned *big.Int
+	Cashed *big.Int
+	Gas    *big.Int
+}
+
+// EarningsReport summarizes the cheques received and cashed within the window.
+// The uncashed and reserved amounts are only known for the listed peers.
+type EarningsReport struct {
+	Window    time.Duration
+	Earned    *big.Int
+	Cashed    *big.Int
+	Deducted  *big.Int
+	Uncashed  *big.Int
+	Reserved  *big.Int
+	Gas       *big.Int
+	Effective *big.Int
+	Peers     []PeerEarnings // in descending order of the earned amount
+	Daily     []DayEarnings  // oldest first
+}
+
+// pendingCashout is a cashout whose payout and fee is not known yet.
+type pendingCashout struct {
+	Peer swarm.Address `json:"peer"`
+	Time time.Time     `json:"time"`
+}
+
+// Earnings tracks the tokens received in cheques per peer and the payout and
+// the fees of their cashouts. The tokens expected to be deducted from the
+// uncashed cheques by their cashouts are reserved, so that the effective
+// earnings are net of the payout to the cashout callers.
+type Earnings struct {
+	logger   log.Logger
+	store    storage.StateStorer
+	window   time.Duration
+	earned   *rolling
+	cashed   *rolling
+	deducted *rolling
+	gas      *rolling
+	status   CashoutStatusFunc
+	fee      FeeFunc
+	metrics  earningsMetrics
+	clock    clock.Clock
+
+	mu          sync.Mutex
+	cashoutCost *big.Int
+}
+
+// NewEarnings creates an earnings tracker which accumulates the received
+// cheques and cashouts over the window. They are recorded by registering
+// HandleEvent with the swap service.
+func NewEarnings(logger log.Logger, store storage.StateStorer, window time.Duration, status CashoutStatusFunc, fee FeeFunc) (*Earnings, error) {
+	now := time.Now()
+	e := &Earnings{
+		logger:  logger.WithName(loggerName).Register(),
+		store:   store,
+		window:  window,
+		status:  status,
+		fee:     fee,
+		metrics: newEarningsMetrics(),
+		clock:   clock.System,
+
+		cashoutCost: new(big.Int),
+	}
+	var err error
+	if e.earned, err = newRolling(store, earnedKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	if e.cashed, err = newRolling(store, cashedKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	if e.deducted, err = newRolling(store, deductedKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	if e.gas, err = newRolling(store, cashoutGasKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CashoutCost returns the tokens expected to be deducted from the payout of a
+// cashout.
+func (e *Earnings) CashoutCost() *big.Int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return new(big.Int).Set(e.cashoutCost)
+}
+
+// SetCashoutCost sets the tokens expected to be deducted from the payout of a
+// cashout, like the payout of a caller cashing out on behalf of the node. The
+// cost must not be negative.
+func (e *Earnings) SetCashoutCost(cost *big.Int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cashoutCost = new(big.Int).Set(cost)
+}
+
+// reserved returns the tokens expected to be deducted when cashing out the
+// uncashed amount, which is at most the amount itself.
+func (e *Earnings) reserved(uncashed *big.Int) *big.Int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if uncashed.Sign() <= 0 {
+		return new(big.Int)
+	}
+	if uncashed.Cmp(e.cashoutCost) < 0 {
+		return new(big.Int).Set(uncashed)
+	}
+	return new(big.Int).Set(e.cashoutCost)
+}
+
+func pendingCashoutKey(txHash common.Hash, peer swarm.Address) string {
+	return fmt.Sprintf("%s%x_%s", pendingCashoutKeyPrefix, txHash, peer)
+}
+
+// HandleEvent records received cheques and sent cashouts.
+func (e *Earnings) HandleEvent(event events.Event) {
+	switch event.Type {
+	case events.TypeChequeReceived:
+		if event.Payout == nil {
+			return
+		}
+		if err := e.earned.add(event.Peer, event.Time, event.Payout); err != nil {
+			e.logger.Error(err, "record received cheque failed", "peer", event.Peer)
+		}
+	case events.TypeCashout:
+		err := e.store.Put(pendingCashoutKey(event.TxHash, event.Peer), pendingCashout{Peer: event.Peer, Time: event.Time})
+		if err != nil {
+			e.logger.Error(err, "record cashout failed", "peer", event.Peer, "tx", event.TxHash)
+		}
+	}
+}
+
+// resolveCashouts records the payout and the fee of the pending cashouts once
+// they are mined. The fee of a batch cashout is split evenly between the peers
+// whose cheques it cashed. A cashout superseded by a later one before it is
+// resolved is only recorded with its fee.
+func (e *Earnings) resolveCashouts(ctx context.Context, statuses map[string]*chequebook.CashoutStatus) error {
+	pending := make(map[common.Hash][]pendingCashout)
+	err := e.store.Iterate(pendingCashoutKeyPrefix, func(key, value []byte) (bool, error) {
+		var p pendingCashout
+		if err := json.Unmarshal(value, &p); err != nil {
+			return true, fmt.Errorf("pending cashout %s: %w", key, err)
+		}
+		txHash, _, _ := strings.Cut(strings.TrimPrefix(string(key), pendingCashoutKeyPrefix), "_")
+		pending[common.HexToHash(txHash)] = append(pending[common.HexToHash(txHash)], p)
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for txHash, cashouts := range pending {
+		if err := e.resolveCashout(ctx, txHash, cashouts, statuses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Earnings) resolveCashout(ctx context.Context, txHash common.Hash, cashouts []pendingCashout, statuses map[string]*chequebook.CashoutStatus) error {
+	lasts := make([]*chequebook.LastCashout, len(cashouts))
+	for i, p := range cashouts {
+		status, ok := statuses[p.Peer.String()]
+		if !ok {
+			var err error
+			if status, err = e.status(ctx, p.Peer); err != nil {
+				e.logger.Debug("cashout status failed", "peer", p.Peer, "error", err)
+				return nil
+			}
+			statuses[p.Peer.String()] = status
+		}
+		last := status.Last
+		if last != nil && last.TxHash == txHash && !last.Reverted && last.Result == nil {
+			return nil // not mined yet
+		}
+		if last != nil && last.TxHash == txHash {
+			lasts[i] = last
+		}
+	}
+
+	fee, err := e.fee(ctx, txHash)
+	if err != nil {
+		e.logger.Debug("cashout fee failed", "tx", txHash, "error", err)
+		return nil
+	}
+	e.metrics.CashoutGas.Add(toFloat(fee))
+
+	share, remainder := new(big.Int).QuoRem(fee, big.NewInt(int64(len(cashouts))), new(big.Int))
+	for i, p := range cashouts {
+		peerFee := new(big.Int).Set(share)
+		if i == 0 {
+			peerFee.Add(peerFee, remainder)
+		}
+		if err := e.gas.add(p.Peer, p.Time, peerFee); err != nil {
+			return err
+		}
+		if lasts[i] != nil && lasts[i].Result != nil {
+			result := lasts[i].Result
+			payout := new(big.Int).Set(result.TotalPayout)
+			if result.CallerPayout != nil && result.CallerPayout.Sign() > 0 {
+				payout.Sub(payout, result.CallerPayout)
+				if err := e.deducted.add(p.Peer, p.Time, result.CallerPayout); err != nil {
+					return err
+				}
+			}
+			if err := e.cashed.add(p.Peer, p.Time, payout); err != nil {
+				return err
+			}
+		}
+		if err := e.store.Delete(pendingCashoutKey(txHash, p.Peer)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Report returns the earnings within the window of the top peers by earned
+// amount, all peers if top is zero.
+func (e *Earnings) Report(ctx context.Context, top int) (*EarningsReport, error) {
+	now := e.clock.Now()
+
+	earned, peers, _ := e.earned.totals(now)
+	if top > 0 && len(peers) > top {
+		peers = peers[:top]
+	}
+
+	statuses := make(map[string]*chequebook.CashoutStatus)
+	for _, p := range peers {
+		status, err := e.status(ctx, p.Peer)
+		if err != nil {
+			e.logger.Debug("cashout status failed", "peer", p.Peer, "error", err)
+			continue
+		}
+		statuses[p.Peer.String()] = status
+	}
+	if err := e.resolveCashouts(ctx, statuses); err != nil {
+		return nil, err
+	}
+
+	cashed, cashedPeers, _ := e.cashed.totals(now)
+	deducted, deductedPeers, _ := e.deducted.totals(now)
+	gas, gasPeers, _ := e.gas.totals(now)
+
+	report := &EarningsReport{
+		Window:   e.window,
+		Earned:   earned,
+		Cashed:   cashed,
+		Deducted: deducted,
+		Uncashed: new(big.Int),
+		Reserved: new(big.Int),
+		Gas:      gas,
+		Peers:    make([]PeerEarnings, 0, len(peers)),
+	}
+	for _, p := range peers {
+		pe := PeerEarnings{
+			Peer:     p.Peer,
+			Earned:   p.Amount,
+			Cashed:   amountOf(cashedPeers, p.Peer),
+			Deducted: amountOf(deductedPeers, p.Peer),
+			Reserved: new(big.Int),
+			Gas:      amountOf(gasPeers, p.Peer),
+		}
+		if status, ok := statuses[p.Peer.String()]; ok && status.UncashedAmount != nil {
+			pe.Uncashed = status.UncashedAmount
+			pe.Reserved = e.reserved(status.UncashedAmount)
+			report.Uncashed.Add(report.Uncashed, status.UncashedAmount)
+			report.Reserved.Add(report.Reserved, pe.Reserved)
+		}
+		pe.Effective = new(big.Int).Sub(pe.Earned, pe.Gas)
+		pe.Effective.Sub(pe.Effective, pe.Deducted).Sub(pe.Effective, pe.Reserved)
+		report.Peers = append(report.Peers, pe)
+	}
+	report.Effective = new(big.Int).Sub(earned, gas)
+	report.Effective.Sub(report.Effective, deducted).Sub(report.Effective, report.Reserved)
+
+	earnedDaily, cashedDaily, gasDaily := e.earned.daily(now), e.cashed.daily(now), e.gas.daily(now)
+	for i := range earnedDaily {
+		report.Daily = append(report.Daily, DayEarnings{
+			Day:    earnedDaily[i].Day,
+			Earned: earnedDaily[i].Amount,
+			Cashed: cashedDaily[i].Amount,
+			Gas:    gasDaily[i].Amount,
+		})
+	}
+
+	e.metrics.EffectiveEarnings.Set(toFloat(report.Effective))
+	return report, nil
+}
+
+func amountOf(amounts []PeerAmount, peer swarm.Address) *big.Int {
+	for _, a := range amounts {
+		if a.Peer.Equal(peer) {
+			return a.Amount
+		}
+	}
+	return new(big.Int)
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestEarnings(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	peer1 := swarm.MustParseHexAddress("aaaa")
+	peer2 := swarm.MustParseHexAddress("bbbb")
+	txHash := common.HexToHash("eeee")
+
+	statuses := map[string]*chequebook.CashoutStatus{
+		peer1.String(): {
+			Last:           &chequebook.LastCashout{TxHash: txHash},
+			UncashedAmount: big.NewInt(100),
+		},
+		peer2.String(): {
+			UncashedAmount: big.NewInt(200),
+		},
+	}
+	var feeCalls int
+	earnings, err := analytics.NewEarnings(log.Noop, storemock.NewStateStore(), analytics.DefaultWindow,
+		func(_ context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error) {
+			return statuses[peer.String()], nil
+		},
+		func(_ context.Context, tx common.Hash) (*big.Int, error) {
+			if tx != txHash {
+				t.Fatalf("fee of wrong transaction. wanted %v, got %v", txHash, tx)
+			}
+			feeCalls++
+			return big.NewInt(30), nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	earnings.SetClock(clockmock.New(now))
+
+	for _, e := range []events.Event{
+		{Type: events.TypeChequeReceived, Peer: peer1, Time: now, Payout: big.NewInt(500)},
+		{Type: events.TypeChequeReceived, Peer: peer2, Time: now, Payout: big.NewInt(200)},
+		{Type: events.TypeCashout, Peer: peer1, Time: now, TxHash: txHash},
+		{Type: events.TypeChequeIssued, Peer: peer2, Time: now, Payout: big.NewInt(1000)},
+	} {
+		earnings.HandleEvent(e)
+	}
+
+	// the cashout is not mined yet
+	report, err := earnings.Report(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Earned.Cmp(big.NewInt(700)) != 0 || report.Cashed.Sign() != 0 || report.Gas.Sign() != 0 || feeCalls != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	statuses[peer1.String()] = &chequebook.CashoutStatus{
+		Last: &chequebook.LastCashout{
+			TxHash: txHash,
+			Result: &chequebook.CashChequeResult{TotalPayout: big.NewInt(400)},
+		},
+		UncashedAmount: big.NewInt(100),
+	}
+
+	for i := 0; i < 2; i++ {
+		report, err = earnings.Report(context.Background(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Earned.Cmp(big.NewInt(700)) != 0 || report.Cashed.Cmp(big.NewInt(400)) != 0 || report.Uncashed.Cmp(big.NewInt(300)) != 0 ||
+			report.Gas.Cmp(big.NewInt(30)) != 0 || report.Effective.Cmp(big.NewInt(670)) != 0 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+	}
+	if feeCalls != 1 {
+		t.Fatalf("fee retrieved %d times, want 1", feeCalls)
+	}
+
+	if len(report.Peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(report.Peers))
+	}
+	p := report.Peers[0]
+	if !p.Peer.Equal(peer1) || p.Earned.Cmp(big.NewInt(500)) != 0 || p.Cashed.Cmp(big.NewInt(400)) != 0 ||
+		p.Uncashed.Cmp(big.NewInt(100)) != 0 || p.Gas.Cmp(big.NewInt(30)) != 0 || p.Effective.Cmp(big.NewInt(470)) != 0 {
+		t.Fatalf("unexpected peer earnings %+v", p)
+	}
+
+	if len(report.Daily) != 7 {
+		t.Fatalf("got %d days, want 7", len(report.Daily))
+	}
+	today := report.Daily[6]
+	if today.Earned.Cmp(big.NewInt(700)) != 0 || today.Cashed.Cmp(big.NewInt(400)) != 0 || today.Gas.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("unexpected earnings of today %+v", today)
+	}
+	if report.Daily[0].Earned.Sign() != 0 {
+		t.Fatalf("unexpected earnings of the first day %+v", report.Daily[0])
+	}
+}
+
+func TestEarningsCashoutCost(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	peer1 := swarm.MustParseHexAddress("aaaa")
+	peer2 := swarm.MustParseHexAddress("bbbb")
+	txHash := common.HexToHash("eeee")
+
+	statuses := map[string]*chequebook.CashoutStatus{
+		peer1.String(): {
+			Last: &chequebook.LastCashout{
+				TxHash: txHash,
+				Result: &chequebook.CashChequeResult{TotalPayout: big.NewInt(400), CallerPayout: big.NewInt(15)},
+			},
+			UncashedAmount: big.NewInt(100),
+		},
+		peer2.String(): {
+			UncashedAmount: big.NewInt(5),
+		},
+	}
+	earnings, err := analytics.NewEarnings(log.Noop, storemock.NewStateStore(), analytics.DefaultWindow,
+		func(_ context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error) {
+			return statuses[peer.String()], nil
+		},
+		func(_ context.Context, tx common.Hash) (*big.Int, error) {
+			return big.NewInt(30), nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	earnings.SetClock(clockmock.New(now))
+	earnings.SetCashoutCost(big.NewInt(20))
+
+	for _, e := range []events.Event{
+		{Type: events.TypeChequeReceived, Peer: peer1, Time: now, Payout: big.NewInt(500)},
+		{Type: events.TypeChequeReceived, Peer: peer2, Time: now, Payout: big.NewInt(5)},
+		{Type: events.TypeCashout, Peer: peer1, Time: now, TxHash: txHash},
+	} {
+		earnings.HandleEvent(e)
+	}
+
+	report, err := earnings.Report(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reserve of peer2 is limited to its uncashed amount
+	if report.Cashed.Cmp(big.NewInt(385)) != 0 || report.Deducted.Cmp(big.NewInt(15)) != 0 ||
+		report.Reserved.Cmp(big.NewInt(25)) != 0 || report.Effective.Cmp(big.NewInt(435)) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	p := report.Peers[0]
+	if !p.Peer.Equal(peer1) || p.Cashed.Cmp(big.NewInt(385)) != 0 || p.Deducted.Cmp(big.NewInt(15)) != 0 ||
+		p.Reserved.Cmp(big.NewInt(20)) != 0 || p.Effective.Cmp(big.NewInt(435)) != 0 {
+		t.Fatalf("unexpected peer earnings %+v", p)
+	}
+	p = report.Peers[1]
+	if p.Reserved.Cmp(big.NewInt(5)) != 0 || p.Effective.Sign() != 0 {
+		t.Fatalf("unexpected peer earnings %+v", p)
+	}
+}
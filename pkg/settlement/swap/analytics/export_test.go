@@ -0,0 +1,19 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import "github.com/ethersphere/bee/pkg/settlement/swap/clock"
+
+func (s *Spend) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+func (e *Earnings) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
+func (p *Purposes) SetClock(c clock.Clock) {
+	p.clock = c
+}
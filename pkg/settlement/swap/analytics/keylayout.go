@@ -0,0 +1,83 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the settlement analytics.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     spendKeyPrefix + "<day>_<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount paid to the peer on the day",
+	},
+	{
+		Pattern:     earnedKeyPrefix + "<day>_<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount received from the peer on the day",
+	},
+	{
+		Pattern:     cashedKeyPrefix + "<day>_<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount cashed from cheques of the peer on the day",
+	},
+	{
+		Pattern:     cashoutGasKeyPrefix + "<day>_<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "transaction fees of cashouts of cheques of the peer on the day",
+	},
+	{
+		Pattern:     deductedKeyPrefix + "<day>_<peer>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "caller payouts deducted from cashouts of cheques of the peer on the day",
+	},
+	{
+		Pattern:     pendingCashoutKeyPrefix + "<tx>_<peer>",
+		Value:       "analytics.pendingCashout",
+		Version:     1,
+		Description: "cashout of a cheque of the peer whose outcome is not known yet",
+	},
+	{
+		Pattern:     ledgerEntryKeyPrefix + "<index>",
+		Value:       "analytics.LedgerEntry",
+		Version:     1,
+		Description: "entry of the settlement ledger",
+	},
+	{
+		Pattern:     ledgerNextKey,
+		Value:       "uint64",
+		Version:     1,
+		Description: "index of the next entry of the settlement ledger",
+	},
+	{
+		Pattern:     ledgerPendingCashoutKeyPrefix + "<tx>_<peer>",
+		Value:       "analytics.ledgerCashout",
+		Version:     1,
+		Description: "cashout of a cheque of the peer not booked in the ledger yet",
+	},
+	{
+		Pattern:     purposeTokensKeyPrefix + "<day>_<purpose>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount spent for the purpose on the day",
+	},
+	{
+		Pattern:     purposeGasKeyPrefix + "<day>_<purpose>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "transaction fees spent for the purpose on the day",
+	},
+	{
+		Pattern:     purposePendingKeyPrefix + "<tx>",
+		Value:       "analytics.pendingPurpose",
+		Version:     1,
+		Description: "purpose of a transaction whose fee is not known yet",
+	},
+}
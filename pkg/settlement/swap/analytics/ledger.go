@@ -0,0 +1,322 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	ledgerEntryKeyPrefix          = "swap_analytics_ledger_entry_"
+	ledgerPendingCashoutKeyPrefix = "swap_analytics_ledger_cashout_"
+	ledgerNextKey                 = "swap_analytics_ledger_next"
+)
+
+// Account is an account of the settlement ledger.
+type Account string
+
+const (
+	// AccountIncome is credited with the tokens received in cheques.
+	AccountIncome Account = "income"
+	// AccountExpense is debited with the tokens paid in issued cheques.
+	AccountExpense Account = "expense"
+	// AccountReceivable holds the tokens of received cheques until they are
+	// cashed out.
+	AccountReceivable Account = "cheques_receivable"
+	// AccountChequebook holds the tokens in the chequebook of the node.
+	AccountChequebook Account = "chequebook"
+	// AccountWallet holds the tokens in the wallet of the node.
+	AccountWallet Account = "wallet"
+	// AccountCashoutCost is debited with the tokens of cashed cheques paid out
+	// to the caller of the cashout.
+	AccountCashoutCost Account = "cashout_cost"
+)
+
+// RatesFunc returns the current exchange rate and cheque value deduction of
+// the price oracle.
+type RatesFunc func() (exchangeRate, deduction *big.Int, err error)
+
+// LedgerEntry moves an amount of tokens from the credited to the debited
+// account. The exchange rate and deduction are those of the price oracle at
+// the time of the event, nil if they were not known.
+type LedgerEntry struct {
+	Index        uint64         `json:"index"`
+	Time         time.Time      `json:"time"`
+	Event        events.Type    `json:"event"`
+	Debit        Account        `json:"debit"`
+	Credit       Account        `json:"credit"`
+	Amount       *big.Int       `json:"amount"`
+	Counterparty swarm.Address  `json:"counterparty"`
+	Chequebook   common.Address `json:"chequebook"`
+	TxHash       common.Hash    `json:"txHash"`
+	ExchangeRate *big.Int       `json:"exchangeRate,omitempty"`
+	Deduction    *big.Int       `json:"deduction,omitempty"`
+}
+
+// ledgerCashout is a cashout whose payout is not known yet.
+type ledgerCashout struct {
+	Peer         swarm.Address  `json:"peer"`
+	Chequebook   common.Address `json:"chequebook"`
+	Time         time.Time      `json:"time"`
+	ExchangeRate *big.Int       `json:"exchangeRate,omitempty"`
+	Deduction    *big.Int       `json:"deduction,omitempty"`
+}
+
+// Ledger is a double-entry record of the token movements of the settlements:
+// the issued and received cheques, their cashouts and the deposits to and
+// withdrawals from the chequebook. Cashouts are entered once they are mined,
+// with the time they were sent, so that the entries are not strictly ordered
+// by time. Bounced cheques stay receivable.
+type Ledger struct {
+	logger log.Logger
+	store  storage.StateStorer
+	status CashoutStatusFunc
+	rates  RatesFunc
+
+	mu   sync.Mutex
+	next uint64
+
+	resolveMu sync.Mutex // serializes the entering of mined cashouts
+}
+
+// NewLedger creates a ledger persisted in the store. The entries are recorded
+// by registering HandleEvent with the swap service. The rates may be nil if
+// there is no price oracle.
+func NewLedger(logger log.Logger, store storage.StateStorer, status CashoutStatusFunc, rates RatesFunc) (*Ledger, error) {
+	l := &Ledger{
+		logger: logger.WithName(loggerName).Register(),
+		store:  store,
+		status: status,
+		rates:  rates,
+	}
+	err := store.Get(ledgerNextKey, &l.next)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	return l, nil
+}
+
+func ledgerEntryKey(index uint64) string {
+	return fmt.Sprintf("%s%016x", ledgerEntryKeyPrefix, index)
+}
+
+func ledgerPendingCashoutKey(txHash common.Hash, peer swarm.Address) string {
+	return fmt.Sprintf("%s%x_%s", ledgerPendingCashoutKeyPrefix, txHash, peer)
+}
+
+// HandleEvent records the token movements of the event.
+func (l *Ledger) HandleEvent(event events.Event) {
+	entry := LedgerEntry{
+		Time:         event.Time,
+		Event:        event.Type,
+		Counterparty: event.Peer,
+		Chequebook:   event.Chequebook,
+		TxHash:       event.TxHash,
+	}
+	switch event.Type {
+	case events.TypeChequeReceived:
+		entry.Debit, entry.Credit, entry.Amount = AccountReceivable, AccountIncome, event.Payout
+	case events.TypeChequeIssued:
+		entry.Debit, entry.Credit, entry.Amount = AccountExpense, AccountChequebook, event.Payout
+	case events.TypeDeposited:
+		entry.Debit, entry.Credit, entry.Amount = AccountChequebook, AccountWallet, event.Amount
+	case events.TypeWithdrawn:
+		entry.Debit, entry.Credit, entry.Amount = AccountWallet, AccountChequebook, event.Amount
+	case events.TypeCashout:
+		exchangeRate, deduction := l.currentRates()
+		err := l.store.Put(ledgerPendingCashoutKey(event.TxHash, event.Peer), ledgerCashout{
+			Peer:         event.Peer,
+			Chequebook:   event.Chequebook,
+			Time:         event.Time,
+			ExchangeRate: exchangeRate,
+			Deduction:    deduction,
+		})
+		if err != nil {
+			l.logger.Error(err, "record cashout in ledger failed", "peer", event.Peer, "tx", event.TxHash)
+		}
+		return
+	default:
+		return
+	}
+	if entry.Amount == nil || entry.Amount.Sign() <= 0 {
+		return
+	}
+	entry.ExchangeRate, entry.Deduction = l.currentRates()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(entry); err != nil {
+		l.logger.Error(err, "record ledger entry failed", "event", event.Type, "peer", event.Peer)
+	}
+}
+
+func (l *Ledger) currentRates() (exchangeRate, deduction *big.Int) {
+	if l.rates == nil {
+		return nil, nil
+	}
+	exchangeRate, deduction, err := l.rates()
+	if err != nil {
+		l.logger.Debug("price oracle rates unavailable", "error", err)
+		return nil, nil
+	}
+	return exchangeRate, deduction
+}
+
+// append stores the entry with the next index. Must be called with the lock
+// held.
+func (l *Ledger) append(entry LedgerEntry) error {
+	entry.Index = l.next
+	if err := l.store.Put(ledgerEntryKey(entry.Index), entry); err != nil {
+		return err
+	}
+	if err := l.store.Put(ledgerNextKey, l.next+1); err != nil {
+		return err
+	}
+	l.next++
+	return nil
+}
+
+// resolveCashouts enters the pending cashouts which were mined. The payout to
+// the caller of a cashout is entered as a cashout cost. Cashouts which
+// reverted or were superseded by a later cashout before they were resolved are
+// dropped, the cheques they cashed stay receivable.
+func (l *Ledger) resolveCashouts(ctx context.Context) error {
+	l.resolveMu.Lock()
+	defer l.resolveMu.Unlock()
+
+	type pending struct {
+		key     string
+		txHash  common.Hash
+		cashout ledgerCashout
+	}
+	var cashouts []pending
+	err := l.store.Iterate(ledgerPendingCashoutKeyPrefix, func(key, value []byte) (bool, error) {
+		var c ledgerCashout
+		if err := json.Unmarshal(value, &c); err != nil {
+			return true, fmt.Errorf("pending ledger cashout %s: %w", key, err)
+		}
+		txHash, _, _ := strings.Cut(strings.TrimPrefix(string(key), ledgerPendingCashoutKeyPrefix), "_")
+		cashouts = append(cashouts, pending{key: string(key), txHash: common.HexToHash(txHash), cashout: c})
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range cashouts {
+		status, err := l.status(ctx, p.cashout.Peer)
+		if err != nil {
+			l.logger.Debug("cashout status failed", "peer", p.cashout.Peer, "error", err)
+			continue
+		}
+		last := status.Last
+		if last != nil && last.TxHash == p.txHash && !last.Reverted && last.Result == nil {
+			continue // not mined yet
+		}
+		if last != nil && last.TxHash == p.txHash && last.Result != nil {
+			if err := l.enterCashout(p.txHash, p.cashout, last.Result); err != nil {
+				return err
+			}
+		}
+		if err := l.store.Delete(p.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) enterCashout(txHash common.Hash, c ledgerCashout, result *chequebook.CashChequeResult) error {
+	entry := LedgerEntry{
+		Time:         c.Time,
+		Event:        events.TypeCashout,
+		Credit:       AccountReceivable,
+		Counterparty: c.Peer,
+		Chequebook:   c.Chequebook,
+		TxHash:       txHash,
+		ExchangeRate: c.ExchangeRate,
+		Deduction:    c.Deduction,
+	}
+	payout := new(big.Int).Set(result.TotalPayout)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if result.CallerPayout != nil && result.CallerPayout.Sign() > 0 {
+		payout.Sub(payout, result.CallerPayout)
+		cost := entry
+		cost.Debit, cost.Amount = AccountCashoutCost, result.CallerPayout
+		if err := l.append(cost); err != nil {
+			return err
+		}
+	}
+	if payout.Sign() > 0 {
+		entry.Debit, entry.Amount = AccountWallet, payout
+		if err := l.append(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries in the ledger.
+func (l *Ledger) Len() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.next
+}
+
+// Entries returns up to limit entries starting at offset after entering the
+// cashouts mined in the meantime.
+func (l *Ledger) Entries(ctx context.Context, offset, limit uint64) ([]LedgerEntry, error) {
+	entries := make([]LedgerEntry, 0)
+	err := l.Iterate(ctx, offset, func(entry LedgerEntry) (bool, error) {
+		if uint64(len(entries)) >= limit {
+			return true, nil
+		}
+		entries = append(entries, entry)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Iterate calls fn for every entry starting at offset in the order of the
+// ledger after entering the cashouts mined in the meantime.
+func (l *Ledger) Iterate(ctx context.Context, offset uint64, fn func(entry LedgerEntry) (stop bool, err error)) error {
+	if err := l.resolveCashouts(ctx); err != nil {
+		return err
+	}
+	end := l.Len()
+	for index := offset; index < end; index++ {
+		var entry LedgerEntry
+		if err := l.store.Get(ledgerEntryKey(index), &entry); err != nil {
+			return fmt.Errorf("ledger entry %d: %w", index, err)
+		}
+		stop, err := fn(entry)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
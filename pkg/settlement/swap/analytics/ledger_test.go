@@ -0,0 +1,94 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestLedger(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0).UTC()
+	peer := swarm.MustParseHexAddress("aaaa")
+	txHash := common.HexToHash("eeee")
+	store := storemock.NewStateStore()
+
+	status := &chequebook.CashoutStatus{Last: &chequebook.LastCashout{TxHash: txHash}}
+	statusFunc := func(context.Context, swarm.Address) (*chequebook.CashoutStatus, error) {
+		return status, nil
+	}
+	rates := func() (*big.Int, *big.Int, error) {
+		return big.NewInt(24000), big.NewInt(0), nil
+	}
+
+	ledger, err := analytics.NewLedger(log.Noop, store, statusFunc, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []events.Event{
+		{Type: events.TypeDeposited, Time: now, Amount: big.NewInt(1000), Payout: big.NewInt(1000)},
+		{Type: events.TypeChequeReceived, Peer: peer, Time: now, Amount: big.NewInt(5), Payout: big.NewInt(500)},
+		{Type: events.TypeChequeIssued, Peer: peer, Time: now, Amount: big.NewInt(2), Payout: big.NewInt(200)},
+		{Type: events.TypeCashout, Peer: peer, Time: now, TxHash: txHash},
+		{Type: events.TypeReminderSent, Peer: peer, Time: now, Amount: big.NewInt(100)},
+	} {
+		ledger.HandleEvent(e)
+	}
+
+	// the cashout is not mined yet
+	entries, err := ledger.Entries(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	income := entries[1]
+	if income.Index != 1 || income.Debit != analytics.AccountReceivable || income.Credit != analytics.AccountIncome ||
+		income.Amount.Cmp(big.NewInt(500)) != 0 || !income.Counterparty.Equal(peer) || income.ExchangeRate.Cmp(big.NewInt(24000)) != 0 {
+		t.Fatalf("unexpected income entry %+v", income)
+	}
+
+	status = &chequebook.CashoutStatus{Last: &chequebook.LastCashout{
+		TxHash: txHash,
+		Result: &chequebook.CashChequeResult{TotalPayout: big.NewInt(500), CallerPayout: big.NewInt(20)},
+	}}
+
+	// the ledger is reloaded from the store
+	ledger, err = analytics.NewLedger(log.Noop, store, statusFunc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		entries, err = ledger.Entries(context.Background(), 3, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || ledger.Len() != 5 {
+			t.Fatalf("got %d cashout entries of %d, want 2 of 5", len(entries), ledger.Len())
+		}
+	}
+	cost, payout := entries[0], entries[1]
+	if cost.Debit != analytics.AccountCashoutCost || cost.Credit != analytics.AccountReceivable || cost.Amount.Cmp(big.NewInt(20)) != 0 ||
+		cost.TxHash != txHash || !cost.Time.Equal(now) || cost.ExchangeRate.Cmp(big.NewInt(24000)) != 0 {
+		t.Fatalf("unexpected cashout cost entry %+v", cost)
+	}
+	if payout.Debit != analytics.AccountWallet || payout.Credit != analytics.AccountReceivable || payout.Amount.Cmp(big.NewInt(480)) != 0 {
+		t.Fatalf("unexpected cashout entry %+v", payout)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type spendMetrics struct {
+	DailyBurnRate prometheus.Gauge
+	TimeToEmpty   prometheus.Gauge
+}
+
+func newSpendMetrics() spendMetrics {
+	subsystem := "swap_analytics"
+
+	return spendMetrics{
+		DailyBurnRate: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "daily_burn_rate",
+			Help:      "Average amount of tokens issued in cheques per day.",
+		}),
+		TimeToEmpty: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "time_to_empty_seconds",
+			Help:      "Projected time until the available chequebook balance is issued at the daily burn rate.",
+		}),
+	}
+}
+
+func (s *Spend) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
+
+type earningsMetrics struct {
+	EffectiveEarnings prometheus.Gauge
+	CashoutGas        prometheus.Counter
+}
+
+func newEarningsMetrics() earningsMetrics {
+	subsystem := "swap_analytics"
+
+	return earningsMetrics{
+		EffectiveEarnings: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "effective_earnings",
+			Help:      "Amount of tokens received in cheques within the analytics window minus the gas spent on their cashouts.",
+		}),
+		CashoutGas: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cashout_gas",
+			Help:      "Transaction fees paid for confirmed cashouts.",
+		}),
+	}
+}
+
+func (e *Earnings) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(e.metrics)
+}
@@ -0,0 +1,299 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const (
+	purposeGasKeyPrefix     = "swap_analytics_purpose_gas_"
+	purposeTokensKeyPrefix  = "swap_analytics_purpose_tokens_"
+	purposePendingKeyPrefix = "swap_analytics_purpose_pending_"
+)
+
+// Purpose tags the transactions of the node by what they are sent for.
+type Purpose string
+
+const (
+	PurposeDeposit  Purpose = "deposit"
+	PurposeWithdraw Purpose = "withdraw"
+	PurposeCashout  Purpose = "cashout"
+	PurposeDeploy   Purpose = "deploy"
+	PurposeSweep    Purpose = "sweep"
+	PurposeOther    Purpose = "other"
+)
+
+type purposeKey struct{}
+
+// WithPurpose returns a context which tags the transactions sent with it with
+// the purpose instead of the one determined from the transaction request.
+func WithPurpose(ctx context.Context, purpose Purpose) context.Context {
+	return context.WithValue(ctx, purposeKey{}, purpose)
+}
+
+func purposeFrom(ctx context.Context) (Purpose, bool) {
+	purpose, ok := ctx.Value(purposeKey{}).(Purpose)
+	return purpose, ok
+}
+
+// PurposeFunc determines the purpose of a transaction request.
+type PurposeFunc func(request *transaction.TxRequest) Purpose
+
+// PurposeAmounts are the amounts spent for a purpose within the window.
+type PurposeAmounts struct {
+	Purpose Purpose
+	Gas     *big.Int // fees of the mined transactions
+	Tokens  *big.Int // tokens deposited or withdrawn
+}
+
+// PurposesReport summarizes the gas and token spend per purpose within the
+// window.
+type PurposesReport struct {
+	Window   time.Duration
+	Gas      *big.Int
+	Tokens   *big.Int
+	Purposes []PurposeAmounts // in descending order of gas
+}
+
+// pendingPurpose is a sent transaction whose fee is not known yet.
+type pendingPurpose struct {
+	Purpose Purpose   `json:"purpose"`
+	Time    time.Time `json:"time"`
+}
+
+var _ transaction.Service = (*Purposes)(nil)
+
+// Purposes is a transaction.Service which tags the sent transactions with
+// their purpose and accumulates their fees and the tokens they move per
+// purpose. Token amounts are recorded by registering HandleEvent with the
+// swap service.
+type Purposes struct {
+	transaction.Service
+
+	logger  log.Logger
+	store   storage.StateStorer
+	backend transaction.Backend
+	window  time.Duration
+	purpose PurposeFunc
+	gas     *rolling
+	tokens  *rolling
+	clock   clock.Clock
+}
+
+// NewPurposes creates a purpose tracker wrapping transactionService which
+// accumulates the spend over the window. The purpose of a request is taken
+// from the context it is sent with, see WithPurpose, or else determined by
+// purpose.
+func NewPurposes(logger log.Logger, store storage.StateStorer, window time.Duration, transactionService transaction.Service, backend transaction.Backend, purpose PurposeFunc) (*Purposes, error) {
+	now := time.Now()
+	p := &Purposes{
+		Service: transactionService,
+		logger:  logger.WithName(loggerName).Register(),
+		store:   store,
+		backend: backend,
+		window:  window,
+		purpose: purpose,
+		clock:   clock.System,
+	}
+	var err error
+	if p.gas, err = newRolling(store, purposeGasKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	if p.tokens, err = newRolling(store, purposeTokensKeyPrefix, window, now); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func pendingPurposeKey(txHash common.Hash) string {
+	return fmt.Sprintf("%s%x", purposePendingKeyPrefix, txHash)
+}
+
+// Send sends the request and records the transaction with its purpose, so
+// that its fee is accounted once it is mined.
+func (p *Purposes) Send(ctx context.Context, request *transaction.TxRequest, boostPercent int) (common.Hash, error) {
+	txHash, err := p.Service.Send(ctx, request, boostPercent)
+	if err != nil {
+		return txHash, err
+	}
+
+	purpose, ok := purposeFrom(ctx)
+	if !ok {
+		purpose = p.purpose(request)
+	}
+	if purpose == "" {
+		purpose = PurposeOther
+	}
+	if err := p.store.Put(pendingPurposeKey(txHash), pendingPurpose{Purpose: purpose, Time: p.clock.Now()}); err != nil {
+		p.logger.Error(err, "record transaction purpose failed", "tx", txHash, "purpose", purpose)
+	}
+	return txHash, nil
+}
+
+// HandleEvent records the tokens moved by deposits and withdrawals under the
+// purpose of their transaction.
+func (p *Purposes) HandleEvent(event events.Event) {
+	var purpose Purpose
+	switch event.Type {
+	case events.TypeDeposited:
+		purpose = PurposeDeposit
+	case events.TypeWithdrawn:
+		purpose = PurposeWithdraw
+	default:
+		return
+	}
+	if event.Payout == nil {
+		return
+	}
+
+	var pending pendingPurpose
+	if err := p.store.Get(pendingPurposeKey(event.TxHash), &pending); err == nil {
+		purpose = pending.Purpose
+	}
+	if err := p.tokens.addKey(string(purpose), event.Time, event.Payout); err != nil {
+		p.logger.Error(err, "record token spend failed", "tx", event.TxHash, "purpose", purpose)
+	}
+}
+
+// resolvePending records the fees of the pending transactions which have
+// been mined since. Transactions pending for longer than the window are
+// forgotten.
+func (p *Purposes) resolvePending(ctx context.Context) error {
+	pending := make(map[common.Hash]pendingPurpose)
+	err := p.store.Iterate(purposePendingKeyPrefix, func(key, value []byte) (bool, error) {
+		var pp pendingPurpose
+		if err := json.Unmarshal(value, &pp); err != nil {
+			return true, fmt.Errorf("pending transaction %s: %w", key, err)
+		}
+		pending[common.HexToHash(strings.TrimPrefix(string(key), purposePendingKeyPrefix))] = pp
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := p.clock.Now()
+	for txHash, pp := range pending {
+		if now.Sub(pp.Time) > p.window {
+			// replaced or dropped transactions are never mined
+			if err := p.store.Delete(pendingPurposeKey(txHash)); err != nil {
+				return err
+			}
+			continue
+		}
+		fee, err := p.fee(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue // not mined yet
+		}
+		if err != nil {
+			p.logger.Debug("transaction fee failed", "tx", txHash, "error", err)
+			continue
+		}
+		if err := p.gas.addKey(string(pp.Purpose), pp.Time, fee); err != nil {
+			return err
+		}
+		if err := p.store.Delete(pendingPurposeKey(txHash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fee returns the fee paid by the mined transaction. It returns
+// ethereum.NotFound if the transaction is not mined yet.
+func (p *Purposes) fee(ctx context.Context, txHash common.Hash) (*big.Int, error) {
+	receipt, err := p.backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := p.backend.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := tx.GasPrice()
+	if tx.Type() == types.DynamicFeeTxType {
+		header, err := p.backend.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if header.BaseFee != nil {
+			gasPrice = new(big.Int).Add(header.BaseFee, tx.GasTipCap())
+			if gasPrice.Cmp(tx.GasFeeCap()) > 0 {
+				gasPrice = tx.GasFeeCap()
+			}
+		}
+	}
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)), nil
+}
+
+// Report returns the gas and token spend per purpose within the given window,
+// which is capped at the window of the tracker. A window of zero reports the
+// whole window of the tracker.
+func (p *Purposes) Report(ctx context.Context, window time.Duration) (*PurposesReport, error) {
+	if err := p.resolvePending(ctx); err != nil {
+		return nil, err
+	}
+
+	if window <= 0 || window > p.window {
+		window = p.window
+	}
+	days := int64((window + day - 1) / day)
+	now := p.clock.Now()
+	gas := p.gas.keyTotals(now, days)
+	tokens := p.tokens.keyTotals(now, days)
+
+	report := &PurposesReport{
+		Window: window,
+		Gas:    new(big.Int),
+		Tokens: new(big.Int),
+	}
+	amounts := make(map[string]*PurposeAmounts)
+	get := func(purpose string) *PurposeAmounts {
+		a, ok := amounts[purpose]
+		if !ok {
+			a = &PurposeAmounts{Purpose: Purpose(purpose), Gas: new(big.Int), Tokens: new(big.Int)}
+			amounts[purpose] = a
+		}
+		return a
+	}
+	for purpose, amount := range gas {
+		get(purpose).Gas.Add(get(purpose).Gas, amount)
+		report.Gas.Add(report.Gas, amount)
+	}
+	for purpose, amount := range tokens {
+		get(purpose).Tokens.Add(get(purpose).Tokens, amount)
+		report.Tokens.Add(report.Tokens, amount)
+	}
+
+	report.Purposes = make([]PurposeAmounts, 0, len(amounts))
+	for _, a := range amounts {
+		report.Purposes = append(report.Purposes, *a)
+	}
+	sort.Slice(report.Purposes, func(i, j int) bool {
+		if c := report.Purposes[i].Gas.Cmp(report.Purposes[j].Gas); c != 0 {
+			return c > 0
+		}
+		return report.Purposes[i].Purpose < report.Purposes[j].Purpose
+	})
+	return report, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics_test
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestPurposes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	depositTx := common.HexToHash("0x01")
+	sweepTx := common.HexToHash("0x02")
+	cashoutTx := common.HexToHash("0x03")
+
+	txs := map[string]common.Hash{
+		"token transfer":      depositTx,
+		"chequebook withdraw": sweepTx,
+		"cheque cashout":      cashoutTx,
+	}
+	transactions := map[common.Hash]*types.Transaction{
+		depositTx: types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(10)}),
+		sweepTx:   types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(50), GasTipCap: big.NewInt(2)}),
+		cashoutTx: types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1)}),
+	}
+	receipts := map[common.Hash]*types.Receipt{
+		depositTx: {GasUsed: 100, BlockNumber: big.NewInt(1)},
+		sweepTx:   {GasUsed: 200, BlockNumber: big.NewInt(2)},
+	}
+	var cashoutMined atomic.Bool
+
+	purposes, err := analytics.NewPurposes(log.Noop, storemock.NewStateStore(), analytics.DefaultWindow,
+		transactionmock.New(transactionmock.WithSendFunc(func(_ context.Context, request *transaction.TxRequest, _ int) (common.Hash, error) {
+			return txs[request.Description], nil
+		})),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+				if txHash == cashoutTx && cashoutMined.Load() {
+					return &types.Receipt{GasUsed: 1000, BlockNumber: big.NewInt(3)}, nil
+				}
+				receipt, ok := receipts[txHash]
+				if !ok {
+					return nil, ethereum.NotFound
+				}
+				return receipt, nil
+			}),
+			backendmock.WithTransactionByHashFunc(func(_ context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+				return transactions[txHash], false, nil
+			}),
+			backendmock.WithHeaderbyNumberFunc(func(_ context.Context, number *big.Int) (*types.Header, error) {
+				return &types.Header{Number: number, BaseFee: big.NewInt(20)}, nil
+			}),
+		),
+		func(request *transaction.TxRequest) analytics.Purpose {
+			switch request.Description {
+			case "token transfer":
+				return analytics.PurposeDeposit
+			case "chequebook withdraw":
+				return analytics.PurposeWithdraw
+			case "cheque cashout":
+				return analytics.PurposeCashout
+			}
+			return ""
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	purposes.SetClock(clockmock.New(now))
+
+	ctx := context.Background()
+	if _, err := purposes.Send(ctx, &transaction.TxRequest{Description: "token transfer"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := purposes.Send(analytics.WithPurpose(ctx, analytics.PurposeSweep), &transaction.TxRequest{Description: "chequebook withdraw"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := purposes.Send(ctx, &transaction.TxRequest{Description: "cheque cashout"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	purposes.HandleEvent(events.Event{Type: events.TypeDeposited, Time: now, TxHash: depositTx, Payout: big.NewInt(500)})
+	purposes.HandleEvent(events.Event{Type: events.TypeWithdrawn, Time: now, TxHash: sweepTx, Payout: big.NewInt(300)})
+	purposes.HandleEvent(events.Event{Type: events.TypeDeposited, Time: now.Add(-3 * 24 * time.Hour), Payout: big.NewInt(7)})
+
+	report, err := purposes.Report(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &analytics.PurposesReport{
+		Window: analytics.DefaultWindow,
+		Gas:    big.NewInt(5400),
+		Tokens: big.NewInt(807),
+		Purposes: []analytics.PurposeAmounts{
+			{Purpose: analytics.PurposeSweep, Gas: big.NewInt(4400), Tokens: big.NewInt(300)}, // (20 + 2) * 200
+			{Purpose: analytics.PurposeDeposit, Gas: big.NewInt(1000), Tokens: big.NewInt(507)},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("got report %+v, want %+v", report, want)
+	}
+
+	cashoutMined.Store(true)
+
+	report, err = purposes.Report(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &analytics.PurposesReport{
+		Window: 24 * time.Hour,
+		Gas:    big.NewInt(6400),
+		Tokens: big.NewInt(800),
+		Purposes: []analytics.PurposeAmounts{
+			{Purpose: analytics.PurposeSweep, Gas: big.NewInt(4400), Tokens: big.NewInt(300)},
+			{Purpose: analytics.PurposeCashout, Gas: big.NewInt(1000), Tokens: big.NewInt(0)},
+			{Purpose: analytics.PurposeDeposit, Gas: big.NewInt(1000), Tokens: big.NewInt(500)},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("got report %+v, want %+v", report, want)
+	}
+}
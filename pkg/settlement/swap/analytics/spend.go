@@ -0,0 +1,116 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "analytics"
+
+// spendKeyPrefix is the prefix of the keys of the daily spend per beneficiary.
+const spendKeyPrefix = "swap_analytics_spend_"
+
+// BalanceFunc returns the chequebook balance available for issuing cheques.
+type BalanceFunc func(ctx context.Context) (*big.Int, error)
+
+// SpendReport summarizes the cheques issued within the window.
+type SpendReport struct {
+	Window           time.Duration
+	Total            *big.Int      // tokens issued within the window
+	DailyBurnRate    *big.Int      // average tokens issued per day
+	AvailableBalance *big.Int      // chequebook balance available for issuing
+	TimeToEmpty      time.Duration // projected time until the available balance is issued, zero if nothing was issued
+	Beneficiaries    []PeerAmount  // tokens issued per peer in descending order
+}
+
+// Spend tracks the tokens issued in cheques per beneficiary peer.
+type Spend struct {
+	logger  log.Logger
+	spend   *rolling
+	window  time.Duration
+	balance BalanceFunc
+	metrics spendMetrics
+	clock   clock.Clock
+}
+
+// NewSpend creates a spend tracker which accumulates the issued cheques over
+// the window. Cheques are recorded by registering HandleEvent with the swap
+// service.
+func NewSpend(logger log.Logger, store storage.StateStorer, window time.Duration, balance BalanceFunc) (*Spend, error) {
+	spend, err := newRolling(store, spendKeyPrefix, window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Spend{
+		logger:  logger.WithName(loggerName).Register(),
+		spend:   spend,
+		window:  window,
+		balance: balance,
+		metrics: newSpendMetrics(),
+		clock:   clock.System,
+	}, nil
+}
+
+// HandleEvent records issued cheques.
+func (s *Spend) HandleEvent(event events.Event) {
+	if event.Type != events.TypeChequeIssued || event.Payout == nil {
+		return
+	}
+	if err := s.spend.add(event.Peer, event.Time, event.Payout); err != nil {
+		s.logger.Error(err, "record issued cheque failed", "peer", event.Peer)
+		return
+	}
+	total, _, days := s.spend.totals(s.clock.Now())
+	s.metrics.DailyBurnRate.Set(toFloat(burnRate(total, days)))
+}
+
+// Report returns the spend within the window and the projected time until the
+// chequebook is empty at the current burn rate.
+func (s *Spend) Report(ctx context.Context, top int) (*SpendReport, error) {
+	available, err := s.balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total, peers, days := s.spend.totals(s.clock.Now())
+	if top > 0 && len(peers) > top {
+		peers = peers[:top]
+	}
+
+	report := &SpendReport{
+		Window:           s.window,
+		Total:            total,
+		DailyBurnRate:    burnRate(total, days),
+		AvailableBalance: available,
+		Beneficiaries:    peers,
+	}
+	if report.DailyBurnRate.Sign() > 0 {
+		days := new(big.Float).Quo(new(big.Float).SetInt(available), new(big.Float).SetInt(report.DailyBurnRate))
+		f, _ := days.Float64()
+		report.TimeToEmpty = time.Duration(f * float64(day))
+	}
+
+	s.metrics.DailyBurnRate.Set(toFloat(report.DailyBurnRate))
+	s.metrics.TimeToEmpty.Set(report.TimeToEmpty.Seconds())
+	return report, nil
+}
+
+func burnRate(total *big.Int, days int64) *big.Int {
+	return new(big.Int).Div(total, big.NewInt(days))
+}
+
+func toFloat(i *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSpend(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	now := time.Now()
+	peer1 := swarm.MustParseHexAddress("aaaa")
+	peer2 := swarm.MustParseHexAddress("bbbb")
+	balance := func(context.Context) (*big.Int, error) {
+		return big.NewInt(1000), nil
+	}
+
+	spend, err := analytics.NewSpend(log.Noop, store, analytics.DefaultWindow, balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend.SetClock(clockmock.New(now))
+
+	for _, e := range []events.Event{
+		{Type: events.TypeChequeIssued, Peer: peer1, Time: now.Add(-10 * 24 * time.Hour), Payout: big.NewInt(10)},
+		{Type: events.TypeChequeIssued, Peer: peer1, Time: now.Add(-24 * time.Hour), Payout: big.NewInt(300)},
+		{Type: events.TypeChequeIssued, Peer: peer2, Time: now, Payout: big.NewInt(100)},
+		{Type: events.TypeChequeReceived, Peer: peer2, Time: now, Payout: big.NewInt(5000)},
+	} {
+		spend.HandleEvent(e)
+	}
+
+	check := func(t *testing.T, spend *analytics.Spend) {
+		t.Helper()
+
+		report, err := spend.Report(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Total.Cmp(big.NewInt(400)) != 0 {
+			t.Fatalf("got total %d, want 400", report.Total)
+		}
+		if report.DailyBurnRate.Cmp(big.NewInt(200)) != 0 {
+			t.Fatalf("got daily burn rate %d, want 200", report.DailyBurnRate)
+		}
+		if report.TimeToEmpty != 5*24*time.Hour {
+			t.Fatalf("got time to empty %v, want %v", report.TimeToEmpty, 5*24*time.Hour)
+		}
+		if len(report.Beneficiaries) != 1 || !report.Beneficiaries[0].Peer.Equal(peer1) || report.Beneficiaries[0].Amount.Cmp(big.NewInt(300)) != 0 {
+			t.Fatalf("unexpected beneficiaries %+v", report.Beneficiaries)
+		}
+	}
+	check(t, spend)
+
+	// the spend survives a restart
+	spend, err = analytics.NewSpend(log.Noop, store, analytics.DefaultWindow, balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend.SetClock(clockmock.New(now))
+	check(t, spend)
+}
+
+func TestSpendNothingIssued(t *testing.T) {
+	t.Parallel()
+
+	spend, err := analytics.NewSpend(log.Noop, storemock.NewStateStore(), analytics.DefaultWindow, func(context.Context) (*big.Int, error) {
+		return big.NewInt(1000), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := spend.Report(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total.Sign() != 0 || report.DailyBurnRate.Sign() != 0 || report.TimeToEmpty != 0 || len(report.Beneficiaries) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// SetContractInspector sets the inspector the contract version of chequebooks
+// is checked with before their cheques are assigned. Without an inspector all
+// chequebooks are assumed to support assignments.
+func (s *Service) SetContractInspector(contracts chequebook.ContractInspector) {
+	s.contractsMu.Lock()
+	defer s.contractsMu.Unlock()
+	s.contracts = contracts
+}
+
+// supportsAssignments reports whether the chequebook contract can be cashed
+// to a recipient designated by the beneficiary.
+func (s *Service) supportsAssignments(ctx context.Context, chequebookAddress common.Address) (bool, error) {
+	s.contractsMu.Lock()
+	contracts := s.contracts
+	s.contractsMu.Unlock()
+	if contracts == nil {
+		return true, nil
+	}
+
+	info, err := contracts.ContractInfo(ctx, chequebookAddress)
+	if errors.Is(err, chequebook.ErrContractInfoUnsupported) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Features.Assignments, nil
+}
+
+// AssignCheque assigns the proceeds of the last cheque of the peer to the
+// recipient by authorizing the cashier to cash it, keeping the caller payout.
+// The cheques of the peer are not cashed by the node until the assigned
+// cheque was cashed or the assignment was removed.
+func (s *Service) AssignCheque(ctx context.Context, peer swarm.Address, cashier, recipient common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error) {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, chequebook.ErrNoCheque
+	}
+
+	var assignment *chequebook.ChequeAssignment
+	err = s.run(ctx, workerpool.PriorityCashout, func(ctx context.Context) error {
+		supported, err := s.supportsAssignments(ctx, chequebookAddress)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return chequebook.ErrAssignmentUnsupported
+		}
+		assignment, err = s.cashout.AssignCheque(ctx, chequebookAddress, cashier, recipient, callerPayout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// ChequeAssignment returns the last cheque assignment of the peer and whether
+// the assigned cheque was cashed.
+func (s *Service) ChequeAssignment(ctx context.Context, peer swarm.Address) (*chequebook.ChequeAssignmentStatus, error) {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, chequebook.ErrNoCheque
+	}
+
+	var status *chequebook.ChequeAssignmentStatus
+	err = s.run(ctx, workerpool.PriorityReconciliation, func(ctx context.Context) (err error) {
+		status, err = s.cashout.ChequeAssignment(ctx, chequebookAddress)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// RemoveChequeAssignment forgets the cheque assignment of the peer so that
+// the node cashes its cheques again.
+func (s *Service) RemoveChequeAssignment(peer swarm.Address) error {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return chequebook.ErrNoCheque
+	}
+	return s.cashout.RemoveChequeAssignment(chequebookAddress)
+}
@@ -0,0 +1,379 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package auditlog provides an append-only log of all settlement affecting
+// actions. Every entry contains the hash of the previous entry so that any
+// later modification of the history can be detected. The entries and the head
+// of the log are signed with the node key, so the history cannot be rewritten
+// by someone with access to the statestore only.
+package auditlog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	// prefix for the persistence key of audit log entries
+	entryKeyPrefix = "settlement_audit_entry_"
+	// key of the last entry of the audit log
+	headKey = "settlement_audit_head"
+)
+
+var (
+	// ErrTampered is the error returned if the audit log does not form a valid hash chain.
+	ErrTampered = errors.New("audit log tampered")
+)
+
+// Action is the type of a settlement affecting action.
+type Action string
+
+const (
+	ActionIssue      Action = "issue"
+	ActionReceive    Action = "receive"
+	ActionCashout    Action = "cashout"
+	ActionDeposit    Action = "deposit"
+	ActionWithdraw   Action = "withdraw"
+	ActionAdjustment Action = "adjustment"
+	ActionConfig     Action = "config"
+	ActionArchive    Action = "archive"
+)
+
+// Entry is a single entry of the audit log.
+type Entry struct {
+	Index        uint64
+	Timestamp    int64 // unix timestamp in nanoseconds
+	Action       Action
+	Chequebook   common.Address // chequebook affected by the action
+	Counterparty common.Address // beneficiary or recipient of the action, if any
+	Amount       *big.Int       // amount of the action, if any
+	TxHash       common.Hash    // transaction sent for the action, if any
+	Note         string         // free form description, used for manual adjustments
+	PrevHash     common.Hash    // hash of the previous entry, zero for the first entry
+	Hash         common.Hash    // hash of this entry
+	Signature    []byte         // signature of the hash by the node key
+}
+
+// hashedEntry contains all fields of an entry which are covered by its hash.
+type hashedEntry struct {
+	Index        uint64
+	Timestamp    int64
+	Action       Action
+	Chequebook   common.Address
+	Counterparty common.Address
+	Amount       *big.Int
+	TxHash       common.Hash
+	Note         string
+	PrevHash     common.Hash
+}
+
+// computeHash computes the hash of the entry.
+func (e *Entry) computeHash() (common.Hash, error) {
+	data, err := json.Marshal(hashedEntry{
+		Index:        e.Index,
+		Timestamp:    e.Timestamp,
+		Action:       e.Action,
+		Chequebook:   e.Chequebook,
+		Counterparty: e.Counterparty,
+		Amount:       e.Amount,
+		TxHash:       e.TxHash,
+		Note:         e.Note,
+		PrevHash:     e.PrevHash,
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := crypto.LegacyKeccak256(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// head describes the last entry of the log.
+type head struct {
+	Index     uint64
+	Hash      common.Hash
+	Signature []byte // signature of the index and hash by the node key
+}
+
+// data returns the signed data of the head.
+func (h *head) data() []byte {
+	data := make([]byte, 8, 8+common.HashLength)
+	binary.BigEndian.PutUint64(data, h.Index)
+	return append(data, h.Hash.Bytes()...)
+}
+
+// Log is an append-only, hash chained log of settlement affecting actions.
+type Log struct {
+	lock   sync.Mutex
+	store  storage.StateStorer
+	signer crypto.Signer
+	owner  common.Address // ethereum address of the signer
+	head   *head          // nil if the log is empty
+	clock  clock.Clock
+}
+
+// entryKey computes the key where to store the entry with the given index.
+func entryKey(index uint64) string {
+	return fmt.Sprintf("%s%016x", entryKeyPrefix, index)
+}
+
+// New creates a new audit log persisted in the store and signed by signer.
+// It fails with ErrTampered if the stored head was not signed by signer.
+func New(store storage.StateStorer, signer crypto.Signer) (*Log, error) {
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{
+		store:  store,
+		signer: signer,
+		owner:  owner,
+		clock:  clock.System,
+	}
+
+	var h head
+	err = store.Get(headKey, &h)
+	switch {
+	case err == nil:
+		if err := l.verifySignature(h.data(), h.Signature); err != nil {
+			return nil, fmt.Errorf("head: %w", err)
+		}
+		l.head = &h
+	case errors.Is(err, storage.ErrNotFound):
+	default:
+		return nil, err
+	}
+
+	// an entry might have been stored without updating the head if the node was interrupted
+	next := uint64(0)
+	if l.head != nil {
+		next = l.head.Index + 1
+	}
+	var entry Entry
+	err = store.Get(entryKey(next), &entry)
+	switch {
+	case err == nil:
+		if err := l.verifySignature(entry.Hash.Bytes(), entry.Signature); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", entry.Index, err)
+		}
+		if err := l.setHead(&head{Index: entry.Index, Hash: entry.Hash}); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, storage.ErrNotFound):
+	default:
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// setHead signs and stores the head.
+func (l *Log) setHead(h *head) (err error) {
+	if h.Signature, err = l.signer.Sign(h.data()); err != nil {
+		return err
+	}
+	if err := l.store.Put(headKey, h); err != nil {
+		return err
+	}
+	l.head = h
+	return nil
+}
+
+// Append adds a new entry for the action to the log. The index, timestamp and
+// hashes of the given entry are ignored and filled in by the log.
+func (l *Log) Append(entry Entry) (Entry, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry.Index = 0
+	entry.PrevHash = common.Hash{}
+	if l.head != nil {
+		entry.Index = l.head.Index + 1
+		entry.PrevHash = l.head.Hash
+	}
+	entry.Timestamp = l.clock.Now().UnixNano()
+
+	hash, err := entry.computeHash()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.Hash = hash
+	if entry.Signature, err = l.signer.Sign(hash.Bytes()); err != nil {
+		return Entry{}, err
+	}
+
+	if err := l.store.Put(entryKey(entry.Index), entry); err != nil {
+		return Entry{}, err
+	}
+	if err := l.setHead(&head{Index: entry.Index, Hash: entry.Hash}); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Archive appends an archive entry exporting the settlement records which are
+// removed from the active dataset. The records are stored as JSON in the note
+// so that they are covered by the hash chain.
+func (l *Log) Archive(chequebook, counterparty common.Address, records interface{}) error {
+	note, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshal archive: %w", err)
+	}
+	_, err = l.Append(Entry{
+		Action:       ActionArchive,
+		Chequebook:   chequebook,
+		Counterparty: counterparty,
+		Note:         string(note),
+	})
+	return err
+}
+
+// Len returns the number of entries in the log.
+func (l *Log) Len() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.head == nil {
+		return 0
+	}
+	return l.head.Index + 1
+}
+
+// Head returns the hash of the last entry, zero if the log is empty.
+func (l *Log) Head() common.Hash {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.head == nil {
+		return common.Hash{}
+	}
+	return l.head.Hash
+}
+
+// Entries returns up to limit entries starting at offset.
+func (l *Log) Entries(offset, limit uint64) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	err := l.Iterate(offset, func(entry Entry) (bool, error) {
+		if uint64(len(entries)) >= limit {
+			return true, nil
+		}
+		entries = append(entries, entry)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Iterate calls fn for every entry starting at offset in the order of the log.
+func (l *Log) Iterate(offset uint64, fn func(entry Entry) (stop bool, err error)) error {
+	return l.iterate(offset, l.Len(), fn)
+}
+
+// iterate calls fn for every entry with an index in [offset, end).
+func (l *Log) iterate(offset, end uint64, fn func(entry Entry) (stop bool, err error)) error {
+	for index := offset; index < end; index++ {
+		var entry Entry
+		if err := l.store.Get(entryKey(index), &entry); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("entry %d missing: %w", index, ErrTampered)
+			}
+			return err
+		}
+		stop, err := fn(entry)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Verify checks that the log forms an unbroken hash chain of signed entries
+// up to the signed head.
+func (l *Log) Verify() error {
+	l.lock.Lock()
+	var (
+		length   uint64
+		headHash common.Hash
+	)
+	if l.head != nil {
+		length, headHash = l.head.Index+1, l.head.Hash
+	}
+	l.lock.Unlock()
+
+	var stored head
+	err := l.store.Get(headKey, &stored)
+	switch {
+	case err == nil:
+		if err := l.verifySignature(stored.data(), stored.Signature); err != nil {
+			return fmt.Errorf("head: %w", err)
+		}
+		if stored.Index+1 != length || stored.Hash != headHash {
+			return fmt.Errorf("head mismatch: %w", ErrTampered)
+		}
+	case errors.Is(err, storage.ErrNotFound):
+		if length != 0 {
+			return fmt.Errorf("head missing: %w", ErrTampered)
+		}
+	default:
+		return err
+	}
+
+	var prevHash common.Hash
+	err = l.iterate(0, length, func(entry Entry) (bool, error) {
+		if entry.PrevHash != prevHash {
+			return true, fmt.Errorf("entry %d: broken chain: %w", entry.Index, ErrTampered)
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return true, err
+		}
+		if hash != entry.Hash {
+			return true, fmt.Errorf("entry %d: hash mismatch: %w", entry.Index, ErrTampered)
+		}
+		if err := l.verifySignature(entry.Hash.Bytes(), entry.Signature); err != nil {
+			return true, fmt.Errorf("entry %d: %w", entry.Index, err)
+		}
+		prevHash = entry.Hash
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if prevHash != headHash {
+		return fmt.Errorf("head mismatch: %w", ErrTampered)
+	}
+	return nil
+}
+
+// verifySignature checks that data was signed by the signer of the log.
+func (l *Log) verifySignature(data, signature []byte) error {
+	publicKey, err := crypto.Recover(signature, data)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", ErrTampered)
+	}
+	address, err := crypto.NewEthereumAddress(*publicKey)
+	if err != nil {
+		return err
+	}
+	if common.BytesToAddress(address) != l.owner {
+		return fmt.Errorf("foreign signature: %w", ErrTampered)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auditlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func newSigner(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(key)
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	signer := newSigner(t)
+	log, err := auditlog.New(store, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := log.Verify(); err != nil {
+		t.Fatalf("empty log not valid: %v", err)
+	}
+
+	actions := []auditlog.Action{auditlog.ActionDeposit, auditlog.ActionIssue, auditlog.ActionReceive}
+	var prev common.Hash
+	for i, action := range actions {
+		entry, err := log.Append(auditlog.Entry{
+			Action: action,
+			Amount: big.NewInt(int64(i + 1)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Index != uint64(i) {
+			t.Fatalf("got index %d, want %d", entry.Index, i)
+		}
+		if entry.PrevHash != prev {
+			t.Fatalf("entry %d not chained to previous entry", i)
+		}
+		prev = entry.Hash
+	}
+
+	if log.Len() != uint64(len(actions)) {
+		t.Fatalf("got length %d, want %d", log.Len(), len(actions))
+	}
+	if log.Head() != prev {
+		t.Fatalf("got head %x, want %x", log.Head(), prev)
+	}
+	if err := log.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := log.Entries(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != auditlog.ActionIssue {
+		t.Fatalf("got entries %v, want the issue entry", entries)
+	}
+
+	// the log continues after a restart
+	log, err = auditlog.New(store, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := log.Append(auditlog.Entry{Action: auditlog.ActionWithdraw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Index != uint64(len(actions)) || entry.PrevHash != prev {
+		t.Fatal("log not continued after restart")
+	}
+	if err := log.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogTampered(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	log, err := auditlog.New(store, newSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := log.Append(auditlog.Entry{Action: auditlog.ActionIssue, Amount: big.NewInt(10)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var entry auditlog.Entry
+	if err := store.Get(auditlog.EntryKey(1), &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Amount = big.NewInt(1)
+	if err := store.Put(auditlog.EntryKey(1), entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := log.Verify(); !errors.Is(err, auditlog.ErrTampered) {
+		t.Fatalf("got error %v, want %v", err, auditlog.ErrTampered)
+	}
+}
+
+func TestLogRewritten(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	signer := newSigner(t)
+	log, err := auditlog.New(store, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := log.Append(auditlog.Entry{Action: auditlog.ActionIssue, Amount: big.NewInt(10)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the whole history is rewritten with a valid hash chain, but without the node key
+	rewritten := storemock.NewStateStore()
+	forged, err := auditlog.New(rewritten, newSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := forged.Append(auditlog.Entry{Action: auditlog.ActionIssue, Amount: big.NewInt(1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = rewritten.Iterate("", func(key, value []byte) (bool, error) {
+		return false, store.Put(string(key), json.RawMessage(value))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := log.Verify(); !errors.Is(err, auditlog.ErrTampered) {
+		t.Fatalf("got error %v, want %v", err, auditlog.ErrTampered)
+	}
+	if _, err := auditlog.New(store, signer); !errors.Is(err, auditlog.ErrTampered) {
+		t.Fatalf("got error %v, want %v", err, auditlog.ErrTampered)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auditlog
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the audit log.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     entryKeyPrefix + "<index>",
+		Value:       "auditlog.Entry",
+		Version:     1,
+		Description: "entry of the hash chained audit log",
+	},
+	{
+		Pattern:     headKey,
+		Value:       "auditlog.head",
+		Version:     1,
+		Description: "index and hash of the last entry of the audit log",
+	},
+}
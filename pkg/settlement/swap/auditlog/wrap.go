@@ -0,0 +1,213 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auditlog
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "auditlog"
+
+// recorder appends entries to the log. As the recorded actions already took
+// place, failures to record are logged but not returned to the caller.
+type recorder struct {
+	log    *Log
+	logger log.Logger
+}
+
+func (r *recorder) record(entry Entry) {
+	if _, err := r.log.Append(entry); err != nil {
+		r.logger.Error(err, "failed to record settlement action", "action", entry.Action)
+	}
+}
+
+type chequebookService struct {
+	chequebook.Service
+	recorder
+}
+
+// WrapChequebook returns a chequebook service which records issued cheques,
+// deposits and withdrawals of the given service in the audit log.
+func WrapChequebook(service chequebook.Service, auditLog *Log, logger log.Logger) chequebook.Service {
+	return &chequebookService{
+		Service:  service,
+		recorder: recorder{log: auditLog, logger: logger.WithName(loggerName).Register()},
+	}
+}
+
+func (s *chequebookService) Deposit(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	txHash, err := s.Service.Deposit(ctx, amount)
+	if err != nil {
+		return txHash, err
+	}
+	s.record(Entry{
+		Action:     ActionDeposit,
+		Chequebook: s.Address(),
+		Amount:     amount.BigInt(),
+		TxHash:     txHash,
+	})
+	return txHash, nil
+}
+
+func (s *chequebookService) Withdraw(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	txHash, err := s.Service.Withdraw(ctx, amount)
+	if err != nil {
+		return txHash, err
+	}
+	s.record(Entry{
+		Action:     ActionWithdraw,
+		Chequebook: s.Address(),
+		Amount:     amount.BigInt(),
+		TxHash:     txHash,
+	})
+	return txHash, nil
+}
+
+func (s *chequebookService) Issue(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+	balance, err := s.Service.Issue(ctx, beneficiary, amount, sendChequeFunc)
+	if err != nil {
+		return balance, err
+	}
+	s.record(Entry{
+		Action:       ActionIssue,
+		Chequebook:   s.Address(),
+		Counterparty: beneficiary,
+		Amount:       amount.BigInt(),
+	})
+	return balance, nil
+}
+
+func (s *chequebookService) ImportLastCheque(ctx context.Context, beneficiary common.Address, cumulativePayout chequebook.Tokens) (*chequebook.SignedCheque, error) {
+	cheque, err := s.Service.ImportLastCheque(ctx, beneficiary, cumulativePayout)
+	if err != nil {
+		return cheque, err
+	}
+	s.record(Entry{
+		Action:       ActionAdjustment,
+		Chequebook:   s.Address(),
+		Counterparty: beneficiary,
+		Amount:       cumulativePayout.BigInt(),
+		Note:         "imported cumulative payout of last issued cheque",
+	})
+	return cheque, nil
+}
+
+type chequeStore struct {
+	chequebook.ChequeStore
+	recorder
+}
+
+// WrapChequeStore returns a cheque store which records received cheques in the audit log.
+func WrapChequeStore(store chequebook.ChequeStore, auditLog *Log, logger log.Logger) chequebook.ChequeStore {
+	return &chequeStore{
+		ChequeStore: store,
+		recorder:    recorder{log: auditLog, logger: logger.WithName(loggerName).Register()},
+	}
+}
+
+func (s *chequeStore) ReceiveCheque(ctx context.Context, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error) {
+	amount, err := s.ChequeStore.ReceiveCheque(ctx, cheque, exchangeRate, deduction)
+	if err != nil {
+		return amount, err
+	}
+	s.record(Entry{
+		Action:     ActionReceive,
+		Chequebook: cheque.Chequebook,
+		Amount:     amount,
+	})
+	return amount, nil
+}
+
+func (s *chequeStore) ImportCheque(ctx context.Context, cheque *chequebook.SignedCheque) error {
+	if err := s.ChequeStore.ImportCheque(ctx, cheque); err != nil {
+		return err
+	}
+	s.record(Entry{
+		Action:     ActionAdjustment,
+		Chequebook: cheque.Chequebook,
+		Amount:     cheque.CumulativePayout,
+		Note:       "imported last received cheque",
+	})
+	return nil
+}
+
+func (s *chequeStore) ImportCheques(ctx context.Context, cheques []*chequebook.SignedCheque) []error {
+	errs := s.ChequeStore.ImportCheques(ctx, cheques)
+	for i, cheque := range cheques {
+		if errs[i] != nil {
+			continue
+		}
+		s.record(Entry{
+			Action:     ActionAdjustment,
+			Chequebook: cheque.Chequebook,
+			Amount:     cheque.CumulativePayout,
+			Note:       "imported last received cheque",
+		})
+	}
+	return errs
+}
+
+type cashoutService struct {
+	chequebook.CashoutService
+	recorder
+}
+
+// WrapCashout returns a cashout service which records cashouts in the audit log.
+func WrapCashout(service chequebook.CashoutService, auditLog *Log, logger log.Logger) chequebook.CashoutService {
+	return &cashoutService{
+		CashoutService: service,
+		recorder:       recorder{log: auditLog, logger: logger.WithName(loggerName).Register()},
+	}
+}
+
+func (s *cashoutService) CashCheque(ctx context.Context, chequebookAddress, recipient common.Address) (common.Hash, error) {
+	txHash, err := s.CashoutService.CashCheque(ctx, chequebookAddress, recipient)
+	if err != nil {
+		return txHash, err
+	}
+	s.record(Entry{
+		Action:       ActionCashout,
+		Chequebook:   chequebookAddress,
+		Counterparty: recipient,
+		TxHash:       txHash,
+	})
+	return txHash, nil
+}
+
+func (s *cashoutService) CashChequeBatch(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]chequebook.BatchCashoutResult, error) {
+	results, err := s.CashoutService.CashChequeBatch(ctx, chequebooks, recipient)
+	// cashouts sent before a failure are on-chain nevertheless
+	s.recordBatch(results)
+	return results, err
+}
+
+func (s *cashoutService) CashoutBatch(ctx context.Context, batch []chequebook.BeneficiaryCheques) ([]chequebook.BatchCashoutResult, error) {
+	results, err := s.CashoutService.CashoutBatch(ctx, batch)
+	// cashouts sent before a failure are on-chain nevertheless
+	s.recordBatch(results)
+	return results, err
+}
+
+// recordBatch records the cashouts of a batch which were sent.
+func (s *cashoutService) recordBatch(results []chequebook.BatchCashoutResult) {
+	for _, result := range results {
+		if result.Err != nil || result.TxHash == (common.Hash{}) {
+			continue
+		}
+		s.record(Entry{
+			Action:       ActionCashout,
+			Chequebook:   result.Chequebook,
+			Counterparty: result.Recipient,
+			Amount:       result.Cheque.CumulativePayout,
+			TxHash:       result.TxHash,
+		})
+	}
+}
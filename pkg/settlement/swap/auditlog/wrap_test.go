@@ -0,0 +1,171 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auditlog_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/auditlog"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+type cashoutMock struct {
+	chequebook.CashoutService
+	txHash common.Hash
+}
+
+func (m *cashoutMock) CashCheque(ctx context.Context, chequebook, recipient common.Address) (common.Hash, error) {
+	return m.txHash, nil
+}
+
+// CashoutBatch sends the first cashout of the batch and fails on the others.
+func (m *cashoutMock) CashoutBatch(ctx context.Context, batch []chequebook.BeneficiaryCheques) ([]chequebook.BatchCashoutResult, error) {
+	err := errors.New("send failed")
+	var results []chequebook.BatchCashoutResult
+	for _, b := range batch {
+		for _, c := range b.Chequebooks {
+			result := chequebook.BatchCashoutResult{
+				Chequebook: c,
+				Recipient:  b.Recipient,
+				Cheque:     &chequebook.SignedCheque{Cheque: chequebook.Cheque{Chequebook: c, CumulativePayout: big.NewInt(10)}},
+			}
+			if len(results) == 0 {
+				result.TxHash = m.txHash
+			} else {
+				result.Err = err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, err
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	auditLog, err := auditlog.New(storemock.NewStateStore(), newSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ownChequebook := common.HexToAddress("0xaaaa")
+	peerChequebook := common.HexToAddress("0xbbbb")
+	beneficiary := common.HexToAddress("0xcccc")
+	depositTx := common.HexToHash("0x01")
+	cashoutTx := common.HexToHash("0x02")
+
+	service := auditlog.WrapChequebook(chequebookmock.NewChequebook(
+		chequebookmock.WithChequebookAddressFunc(func() common.Address { return ownChequebook }),
+		chequebookmock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			return depositTx, nil
+		}),
+		chequebookmock.WithChequebookWithdrawFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			return common.Hash{}, errors.New("failed")
+		}),
+		chequebookmock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+			return big.NewInt(0), nil
+		}),
+	), auditLog, log.Noop)
+
+	store := auditlog.WrapChequeStore(chequestoremock.NewChequeStore(
+		chequestoremock.WithReceiveChequeFunc(func(ctx context.Context, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error) {
+			return big.NewInt(30), nil
+		}),
+	), auditLog, log.Noop)
+
+	cashout := auditlog.WrapCashout(&cashoutMock{txHash: cashoutTx}, auditLog, log.Noop)
+
+	ctx := context.Background()
+	if _, err := service.Deposit(ctx, chequebook.TokensFromUint64(100)); err != nil {
+		t.Fatal(err)
+	}
+	// failed actions are not recorded
+	if _, err := service.Withdraw(ctx, chequebook.TokensFromUint64(50)); err == nil {
+		t.Fatal("expected withdraw error")
+	}
+	if _, err := service.Issue(ctx, beneficiary, chequebook.TokensFromUint64(20), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ReceiveCheque(ctx, &chequebook.SignedCheque{Cheque: chequebook.Cheque{Chequebook: peerChequebook}}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cashout.CashCheque(ctx, peerChequebook, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := auditLog.Entries(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []auditlog.Entry{
+		{Action: auditlog.ActionDeposit, Chequebook: ownChequebook, Amount: big.NewInt(100), TxHash: depositTx},
+		{Action: auditlog.ActionIssue, Chequebook: ownChequebook, Counterparty: beneficiary, Amount: big.NewInt(20)},
+		{Action: auditlog.ActionReceive, Chequebook: peerChequebook, Amount: big.NewInt(30)},
+		{Action: auditlog.ActionCashout, Chequebook: peerChequebook, Counterparty: beneficiary, TxHash: cashoutTx},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		w := want[i]
+		if entry.Action != w.Action || entry.Chequebook != w.Chequebook || entry.Counterparty != w.Counterparty || entry.TxHash != w.TxHash {
+			t.Fatalf("entry %d: got %+v, want %+v", i, entry, w)
+		}
+		if (w.Amount == nil) != (entry.Amount == nil) || (w.Amount != nil && w.Amount.Cmp(entry.Amount) != 0) {
+			t.Fatalf("entry %d: got amount %v, want %v", i, entry.Amount, w.Amount)
+		}
+	}
+
+	if err := auditLog.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWrapCashoutBatchPartiallySent(t *testing.T) {
+	t.Parallel()
+
+	auditLog, err := auditlog.New(storemock.NewStateStore(), newSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sentChequebook := common.HexToAddress("0xaaaa")
+	recipient := common.HexToAddress("0xcccc")
+	cashoutTx := common.HexToHash("0x02")
+
+	cashout := auditlog.WrapCashout(&cashoutMock{txHash: cashoutTx}, auditLog, log.Noop)
+
+	results, err := cashout.CashoutBatch(context.Background(), []chequebook.BeneficiaryCheques{{
+		Recipient:   recipient,
+		Chequebooks: []common.Address{sentChequebook, common.HexToAddress("0xbbbb")},
+	}})
+	if err == nil {
+		t.Fatal("expected batch error")
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	// the cashout sent before the failure is recorded
+	entries, err := auditLog.Entries(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.Action != auditlog.ActionCashout || e.Chequebook != sentChequebook || e.Counterparty != recipient || e.TxHash != cashoutTx {
+		t.Fatalf("got entry %+v", e)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// DefaultAutoCashoutInterval is the default interval in which the uncashed amounts are checked.
+	DefaultAutoCashoutInterval = 5 * time.Minute
+	// DefaultAutoCashoutMinInterval is the default minimum time between two automatic cashouts of a peer.
+	DefaultAutoCashoutMinInterval = 24 * time.Hour
+)
+
+// AutoCashoutOptions configures the automatic cashing of received cheques.
+type AutoCashoutOptions struct {
+	// Threshold is the uncashed amount of a peer above which its cheques are
+	// cashed. Nil or zero disables automatic cashouts.
+	Threshold *big.Int
+	// MinInterval is the minimum time since the last cashout of a peer before
+	// its cheques are cashed again, to limit the gas spent on cashouts.
+	MinInterval time.Duration
+	// Interval in which the uncashed amounts are checked.
+	Interval time.Duration
+}
+
+type autoCashier struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (c *autoCashier) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// StartAutoCashout starts cashing the received cheques of every peer whose
+// uncashed amount exceeds the threshold once per interval. A peer is skipped
+// if one of its cheques was cashed within the minimum interval, so that a
+// peer sending many small cheques does not make us spend gas on every one.
+func (s *Service) StartAutoCashout(o AutoCashoutOptions) io.Closer {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &autoCashier{cancel: cancel}
+	if o.Threshold == nil || o.Threshold.Sign() <= 0 || o.Interval <= 0 {
+		return c
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(o.Interval):
+			}
+
+			if err := s.autoCashout(ctx, o); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to cash out cheques automatically")
+			}
+		}
+	}()
+
+	return c
+}
+
+// autoCashout cashes the cheques of all peers whose uncashed amount exceeds
+// the threshold and which were not cashed within the minimum interval. The
+// amounts are computed from the cashouts sent by this node without querying
+// the chain.
+func (s *Service) autoCashout(ctx context.Context, o AutoCashoutOptions) error {
+	debtors, err := s.debtors()
+	if err != nil {
+		return err
+	}
+
+	type peerDebt struct {
+		peer        swarm.Address
+		uncashed    *big.Int
+		lastCashout int64
+	}
+	var (
+		peers []*peerDebt
+		byKey = make(map[string]*peerDebt)
+	)
+	for _, d := range debtors {
+		p, ok := byKey[d.peer.ByteString()]
+		if !ok {
+			p = &peerDebt{peer: d.peer, uncashed: new(big.Int)}
+			byKey[d.peer.ByteString()] = p
+			peers = append(peers, p)
+		}
+		p.uncashed.Add(p.uncashed, d.uncashed)
+
+		cashouts, err := s.cashout.ChequeCashouts(d.chequebook)
+		if err != nil {
+			return err
+		}
+		if len(cashouts) > 0 && cashouts[len(cashouts)-1].Time > p.lastCashout {
+			p.lastCashout = cashouts[len(cashouts)-1].Time
+		}
+	}
+
+	now := s.clock.Now()
+	for _, p := range peers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if p.uncashed.Cmp(o.Threshold) <= 0 {
+			continue
+		}
+		if p.lastCashout > 0 && now.Sub(time.Unix(p.lastCashout, 0)) < o.MinInterval {
+			continue
+		}
+
+		results, err := s.CashChequebooks(ctx, p.peer)
+		if err != nil {
+			s.logger.Error(err, "automatic cashout failed", "peer_address", p.peer, "uncashed", p.uncashed)
+		}
+		for _, result := range results {
+			if result.Err != nil {
+				s.logger.Error(result.Err, "automatic cashout failed", "peer_address", p.peer, "chequebook", result.Chequebook)
+				continue
+			}
+			s.metrics.AutoCashouts.Inc()
+			s.logger.Info("cashed out cheque automatically", "peer_address", p.peer, "chequebook", result.Chequebook, "uncashed", p.uncashed, "tx", result.TxHash)
+		}
+	}
+	return nil
+}
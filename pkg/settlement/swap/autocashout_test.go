@@ -0,0 +1,132 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestAutoCashout(t *testing.T) {
+	t.Parallel()
+
+	debtorPeer := swarm.MustParseHexAddress("aaaa")
+	debtorChequebook := common.HexToAddress("0xaaaa")
+	smallPeer := swarm.MustParseHexAddress("bbbb")
+	smallChequebook := common.HexToAddress("0xbbbb")
+
+	peers := map[common.Address]swarm.Address{
+		debtorChequebook: debtorPeer,
+		smallChequebook:  smallPeer,
+	}
+	cheques := map[common.Address]*chequebook.SignedCheque{
+		debtorChequebook: {Cheque: chequebook.Cheque{Chequebook: debtorChequebook, CumulativePayout: big.NewInt(100)}},
+		smallChequebook:  {Cheque: chequebook.Cheque{Chequebook: smallChequebook, CumulativePayout: big.NewInt(30)}},
+	}
+	clock := clockmock.New(time.Unix(1000, 0))
+	cashouts := make(map[common.Address][]chequebook.ChequeCashout)
+	cashed := func(c common.Address) *big.Int {
+		if n := len(cashouts[c]); n > 0 {
+			return cashouts[c][n-1].Cheque.CumulativePayout
+		}
+		return big.NewInt(0)
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(
+			mockchequestore.WithLastChequesFunc(func() (map[common.Address]*chequebook.SignedCheque, error) {
+				return cheques, nil
+			}),
+			mockchequestore.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheques[c], nil
+			}),
+		),
+		&addressbookMock{
+			chequebookPeer: func(c common.Address) (swarm.Address, bool, error) {
+				peer, ok := peers[c]
+				return peer, ok, nil
+			},
+			chequebooks: func(p swarm.Address) ([]common.Address, error) {
+				for c, peer := range peers {
+					if peer.Equal(p) {
+						return []common.Address{c}, nil
+					}
+				}
+				return nil, nil
+			},
+		},
+		uint64(1),
+		&cashoutMock{
+			chequeCashouts: func(c common.Address) ([]chequebook.ChequeCashout, error) {
+				return cashouts[c], nil
+			},
+			cashoutStatus: func(ctx context.Context, c common.Address) (*chequebook.CashoutStatus, error) {
+				return &chequebook.CashoutStatus{UncashedAmount: new(big.Int).Sub(cheques[c].CumulativePayout, cashed(c))}, nil
+			},
+			cashBatch: func(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]chequebook.BatchCashoutResult, error) {
+				results := make([]chequebook.BatchCashoutResult, 0, len(chequebooks))
+				for _, c := range chequebooks {
+					cashouts[c] = append(cashouts[c], chequebook.ChequeCashout{Cheque: *cheques[c], Time: clock.Now().Unix()})
+					results = append(results, chequebook.BatchCashoutResult{Chequebook: c, Cheque: cheques[c]})
+				}
+				return results, nil
+			},
+		},
+		nil,
+		common.Address{},
+	)
+	swapService.SetClock(clock)
+
+	o := swap.AutoCashoutOptions{
+		Threshold:   big.NewInt(50),
+		MinInterval: time.Hour,
+	}
+	ctx := context.Background()
+
+	// only the peer owing more than the threshold is cashed
+	if err := swapService.AutoCashout(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if len(cashouts[debtorChequebook]) != 1 || len(cashouts[smallChequebook]) != 0 {
+		t.Fatalf("got %d and %d cashouts, want 1 and 0", len(cashouts[debtorChequebook]), len(cashouts[smallChequebook]))
+	}
+
+	// the peer is not cashed again within the minimum interval
+	cheques[debtorChequebook] = &chequebook.SignedCheque{Cheque: chequebook.Cheque{Chequebook: debtorChequebook, CumulativePayout: big.NewInt(200)}}
+	clock.Advance(30 * time.Minute)
+	if err := swapService.AutoCashout(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if len(cashouts[debtorChequebook]) != 1 {
+		t.Fatalf("got %d cashouts within the minimum interval, want 1", len(cashouts[debtorChequebook]))
+	}
+
+	clock.Advance(time.Hour)
+	if err := swapService.AutoCashout(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if got := cashed(debtorChequebook); len(cashouts[debtorChequebook]) != 2 || got.Cmp(big.NewInt(200)) != 0 {
+		t.Fatalf("got %d cashouts up to %d, want 2 up to 200", len(cashouts[debtorChequebook]), got)
+	}
+	if len(cashouts[smallChequebook]) != 0 {
+		t.Fatalf("got %d cashouts of the peer below the threshold, want none", len(cashouts[smallChequebook]))
+	}
+}
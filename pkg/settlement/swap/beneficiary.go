@@ -0,0 +1,196 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// DefaultBeneficiaryGracePeriod is the default duration cheques to a previous
+// beneficiary are accepted for after a rotation.
+const DefaultBeneficiaryGracePeriod = 7 * 24 * time.Hour
+
+var (
+	// payoutBeneficiaryPrefix is the prefix of the key under which the beneficiary announced by a peer is stored.
+	payoutBeneficiaryPrefix = "swap_peer_payout_beneficiary_"
+	// payoutBeneficiaryPeerPrefix is the prefix of the key under which the peer which announced a beneficiary is stored.
+	payoutBeneficiaryPeerPrefix = "swap_payout_beneficiary_peer_"
+)
+
+// PeerLister lists the connected peers.
+type PeerLister interface {
+	Peers() []p2p.Peer
+}
+
+// BeneficiaryAnnouncement is the result of announcing a rotated beneficiary to a connected peer.
+type BeneficiaryAnnouncement struct {
+	Peer swarm.Address
+	Err  error
+}
+
+func payoutBeneficiaryKey(peer swarm.Address) string {
+	return fmt.Sprintf("%s%s", payoutBeneficiaryPrefix, peer)
+}
+
+func payoutBeneficiaryPeerKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", payoutBeneficiaryPeerPrefix, beneficiary)
+}
+
+// SetPeerLister registers the lister of the connected peers a rotated
+// beneficiary is announced to.
+func (s *Service) SetPeerLister(peers PeerLister) {
+	s.peersMu.Lock()
+	defer s.peersMu.Unlock()
+	s.peers = peers
+}
+
+// Beneficiaries returns the beneficiary cheques to us have to be addressed to
+// and the previous ones which are still accepted.
+func (s *Service) Beneficiaries() (*chequebook.BeneficiaryRotation, error) {
+	return s.chequeStore.Beneficiaries()
+}
+
+// RotateBeneficiary changes the beneficiary cheques to us have to be
+// addressed to and announces it to all connected peers. Peers connecting later
+// receive the announcement after the handshake. Cheques addressed to the
+// previous beneficiary are accepted for the grace period.
+func (s *Service) RotateBeneficiary(ctx context.Context, beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, []BeneficiaryAnnouncement, error) {
+	rotation, err := s.chequeStore.RotateBeneficiary(beneficiary, grace)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.logger.Info("beneficiary rotated", "beneficiary", beneficiary, "grace_period", grace)
+
+	s.peersMu.Lock()
+	peers := s.peers
+	s.peersMu.Unlock()
+	if peers == nil {
+		return rotation, nil, nil
+	}
+
+	connected := peers.Peers()
+	announcements := make([]BeneficiaryAnnouncement, len(connected))
+	var wg sync.WaitGroup
+	for i, p := range connected {
+		announcements[i].Peer = p.Address
+		wg.Add(1)
+		go func(a *BeneficiaryAnnouncement) {
+			defer wg.Done()
+			a.Err = s.proto.AnnounceBeneficiary(ctx, a.Peer, beneficiary)
+			if a.Err != nil {
+				s.logger.Debug("beneficiary announcement failed", "peer_address", a.Peer, "error", a.Err)
+			}
+		}(&announcements[i])
+	}
+	wg.Wait()
+
+	return rotation, announcements, nil
+}
+
+// AnnounceBeneficiary announces our beneficiary to the peer if it was ever
+// rotated, so that peers which missed the rotation address cheques correctly.
+func (s *Service) AnnounceBeneficiary(ctx context.Context, peer swarm.Address) error {
+	rotation, err := s.chequeStore.Beneficiaries()
+	if err != nil {
+		return err
+	}
+	if rotation.Rotated.IsZero() {
+		return nil
+	}
+	return s.proto.AnnounceBeneficiary(ctx, peer, rotation.Current)
+}
+
+// ReceiveBeneficiaryAnnouncement is called by the swap protocol if a peer
+// announces the beneficiary cheques to it have to be addressed to. The
+// beneficiary from the handshake remains the issuer of its chequebook.
+func (s *Service) ReceiveBeneficiaryAnnouncement(ctx context.Context, peer swarm.Address, beneficiary common.Address) error {
+	identity, known, err := s.addressbook.Beneficiary(peer)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return ErrUnknownBeneficary
+	}
+
+	current, err := s.payoutBeneficiary(peer, identity)
+	if err != nil {
+		return err
+	}
+	if current == beneficiary {
+		return nil
+	}
+
+	if err := s.verifyRegistered(ctx, peer, identity, registry.Record{Beneficiary: beneficiary}); err != nil {
+		return fmt.Errorf("rejecting beneficiary announcement: %w", err)
+	}
+
+	// the peer of previously announced beneficiaries is kept for the cheques already sent to them
+	if beneficiary == identity {
+		// the peer rotated back to the beneficiary of the handshake
+		if err := s.store.Delete(payoutBeneficiaryKey(peer)); err != nil {
+			return err
+		}
+	} else {
+		if err := s.store.Put(payoutBeneficiaryKey(peer), beneficiary); err != nil {
+			return err
+		}
+		if err := s.store.Put(payoutBeneficiaryPeerKey(beneficiary), peer); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("peer announced a new beneficiary", "peer_address", peer, "old_beneficiary", current, "new_beneficiary", beneficiary)
+	return nil
+}
+
+// beneficiary returns the beneficiary cheques to the peer are addressed to.
+func (s *Service) beneficiary(peer swarm.Address) (beneficiary common.Address, known bool, err error) {
+	identity, known, err := s.addressbook.Beneficiary(peer)
+	if err != nil || !known {
+		return common.Address{}, known, err
+	}
+	beneficiary, err = s.payoutBeneficiary(peer, identity)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+	return beneficiary, true, nil
+}
+
+// payoutBeneficiary returns the beneficiary announced by the peer or identity
+// if it never announced one.
+func (s *Service) payoutBeneficiary(peer swarm.Address, identity common.Address) (common.Address, error) {
+	var beneficiary common.Address
+	err := s.store.Get(payoutBeneficiaryKey(peer), &beneficiary)
+	if errors.Is(err, storage.ErrNotFound) {
+		return identity, nil
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	return beneficiary, nil
+}
+
+// beneficiaryPeer returns the peer cheques to the beneficiary are sent to.
+func (s *Service) beneficiaryPeer(beneficiary common.Address) (peer swarm.Address, known bool, err error) {
+	err = s.store.Get(payoutBeneficiaryPeerKey(beneficiary), &peer)
+	if err == nil {
+		return peer, true, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return swarm.ZeroAddress, false, err
+	}
+	return s.addressbook.BeneficiaryPeer(beneficiary)
+}
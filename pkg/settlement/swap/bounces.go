@@ -0,0 +1,161 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// maxRecentBounces is the number of bounced cashouts kept in memory.
+const maxRecentBounces = 32
+
+// DefaultBounceCheckInterval is the default interval in which the status of
+// sent cashouts is checked for bounced cheques.
+const DefaultBounceCheckInterval = time.Minute
+
+// Bounce is a cashout of a received cheque which bounced.
+type Bounce struct {
+	Peer       swarm.Address
+	Chequebook common.Address
+	TxHash     common.Hash
+	Amount     *big.Int // cumulative payout of the bounced cheque
+	Time       time.Time
+}
+
+// recordBounce remembers a bounced cashout, dropping the oldest once more
+// than maxRecentBounces are known. Must be called with disconnectMu held.
+func (s *Service) recordBounce(b Bounce) {
+	s.bounces = append(s.bounces, b)
+	if len(s.bounces) > maxRecentBounces {
+		s.bounces = s.bounces[len(s.bounces)-maxRecentBounces:]
+	}
+}
+
+// RecentBounces returns the most recently detected bounced cashouts, newest first.
+func (s *Service) RecentBounces() []Bounce {
+	s.disconnectMu.Lock()
+	defer s.disconnectMu.Unlock()
+
+	bounces := make([]Bounce, len(s.bounces))
+	for i, b := range s.bounces {
+		bounces[len(s.bounces)-1-i] = b
+	}
+	return bounces
+}
+
+// cashoutStatus gets the status of the latest cashout transaction for the
+// chequebook of the peer. A bounced cashout is reported once by a disconnect
+// notification for the peer.
+func (s *Service) cashoutStatus(ctx context.Context, peer swarm.Address, chequebookAddress common.Address) (*chequebook.CashoutStatus, error) {
+	var status *chequebook.CashoutStatus
+	err := s.run(ctx, workerpool.PriorityReconciliation, func(ctx context.Context) (err error) {
+		status, err = s.cashout.CashoutStatus(ctx, chequebookAddress)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status.Last != nil && status.Last.Result != nil && status.Last.Result.Bounced {
+		if err := s.notifyBounced(peer, chequebookAddress, status.Last); err != nil {
+			s.logger.Error(err, "bounced cheque disconnect notification failed", "peer_address", peer)
+		}
+	}
+	return status, nil
+}
+
+// trackCashout remembers the chequebook of a cashout sent for a peer until
+// the outcome of its transaction is known.
+func (s *Service) trackCashout(event events.Event) {
+	if event.Type != events.TypeCashout || event.Peer.IsZero() {
+		return
+	}
+	s.disconnectMu.Lock()
+	defer s.disconnectMu.Unlock()
+	s.pendingCashouts[event.Chequebook] = event.Peer
+}
+
+// checkCashouts checks the status of the sent cashouts whose outcome is not
+// known yet, so that bounced cheques are reported as soon as their cashout
+// transactions are confirmed.
+func (s *Service) checkCashouts(ctx context.Context) error {
+	s.disconnectMu.Lock()
+	pending := make(map[common.Address]swarm.Address, len(s.pendingCashouts))
+	for chequebookAddress, peer := range s.pendingCashouts {
+		pending[chequebookAddress] = peer
+	}
+	s.disconnectMu.Unlock()
+
+	for chequebookAddress, peer := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		status, err := s.cashoutStatus(ctx, peer, chequebookAddress)
+		if err != nil && !errors.Is(err, chequebook.ErrNoCheque) {
+			return err
+		}
+		if err == nil && status.Last != nil && status.Last.Result == nil && !status.Last.Reverted {
+			continue
+		}
+
+		s.disconnectMu.Lock()
+		// a cashout sent in the meantime is checked in the next round
+		if p, ok := s.pendingCashouts[chequebookAddress]; ok && p.Equal(peer) {
+			delete(s.pendingCashouts, chequebookAddress)
+		}
+		s.disconnectMu.Unlock()
+	}
+	return nil
+}
+
+type bounceWatch struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (w *bounceWatch) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	return nil
+}
+
+// StartBounceWatch starts checking the status of the sent cashouts once per
+// interval until their outcome is known. Bounced cheques are reported by
+// disconnect notifications for their peers.
+func (s *Service) StartBounceWatch(interval time.Duration) io.Closer {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &bounceWatch{cancel: cancel}
+	if interval <= 0 {
+		return w
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(interval):
+			}
+
+			if err := s.checkCashouts(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to check cashouts for bounced cheques")
+			}
+		}
+	}()
+
+	return w
+}
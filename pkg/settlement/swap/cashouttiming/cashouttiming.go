@@ -0,0 +1,567 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cashouttiming holds back scheduled cashouts until the base fee is low
+// compared to the recent fee history or their deadline is reached. Cashouts
+// which are due together are sent with bounded parallelism and a limit on the
+// number of their transactions waiting to be mined. Cashouts whose transaction
+// reverted or was dropped are rescheduled a limited number of times.
+package cashouttiming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "cashouttiming"
+
+// scheduledCashoutPrefix is the prefix of the key under which a scheduled cashout of a peer is stored.
+const scheduledCashoutPrefix = "swap_cashout_scheduled_"
+
+const (
+	// DefaultCheckInterval is the default interval in which the base fee is checked.
+	DefaultCheckInterval = time.Minute
+	// DefaultFeeHistoryBlocks is the default number of blocks the current base fee is compared with.
+	DefaultFeeHistoryBlocks = 100
+	// DefaultFeePercentile is the default percentile of the fee history at or below which the base fee is low.
+	DefaultFeePercentile = 25
+	// DefaultParallelism is the default number of cashouts sent at the same time.
+	DefaultParallelism = 4
+	// DefaultMaxInFlight is the default number of cashout transactions waiting to be mined.
+	DefaultMaxInFlight = 16
+	// DefaultMaxRetries is the default number of times a cashout whose transaction reverted or was dropped is rescheduled.
+	DefaultMaxRetries = 2
+
+	// estimatedCashoutGas is the gas a cashout is assumed to use when estimating savings.
+	estimatedCashoutGas = 100_000
+)
+
+var (
+	// ErrInvalidDeadline is the error returned if a cashout is scheduled with a
+	// deadline which is not in the future or later than the maximum delay.
+	ErrInvalidDeadline = errors.New("invalid cashout deadline")
+	// ErrNoFeeHistory is the error returned if the backend returned no base fees.
+	ErrNoFeeHistory = errors.New("no fee history")
+	// ErrInvalidFeePercentile is the error returned if a fee percentile is not in (0, 100].
+	ErrInvalidFeePercentile = errors.New("fee percentile must be above 0 and at most 100")
+	// ErrInvalidMaxInFlight is the error returned if the limit of cashouts in flight is not positive.
+	ErrInvalidMaxInFlight = errors.New("cashouts in flight must be positive")
+)
+
+// CashoutFunc sends the cashout transaction for the last cheque of the peer.
+type CashoutFunc func(ctx context.Context, peer swarm.Address) (common.Hash, error)
+
+// Options configures the Optimizer.
+type Options struct {
+	MaxDelay         time.Duration // latest deadline a cashout can be scheduled with
+	CheckInterval    time.Duration // interval in which the base fee is checked
+	FeeHistoryBlocks uint64        // number of past blocks the base fee is compared with
+	FeePercentile    float64       // percentile of the past base fees at or below which the base fee is low
+	Parallelism      int           // number of cashouts sent at the same time
+	MaxInFlight      int           // number of cashout transactions waiting to be mined after which no more are sent
+	MaxRetries       int           // number of times a cashout whose transaction reverted or was dropped is rescheduled, negative for none
+	Clock            clock.Clock   // clock the schedule follows, the system clock by default
+}
+
+// ScheduledCashout is a cashout waiting for a low base fee.
+type ScheduledCashout struct {
+	Peer      swarm.Address
+	Scheduled time.Time
+	Deadline  time.Time
+	BaseFee   *big.Int // base fee when the cashout was scheduled, nil if unknown
+	Retries   int      // number of earlier attempts whose transaction reverted or was dropped
+}
+
+// inFlightCashout is a sent cashout transaction not yet mined.
+type inFlightCashout struct {
+	Peer    swarm.Address
+	Sent    time.Time
+	Retries int
+}
+
+// Optimizer sends scheduled cashouts once the base fee is at or below the
+// configured percentile of the fee history, or at their deadline at the latest.
+type Optimizer struct {
+	logger  log.Logger
+	store   storage.StateStorer
+	backend transaction.Backend
+	cashout CashoutFunc
+	options Options
+	metrics metrics
+	clock   clock.Clock
+
+	mu        sync.Mutex
+	scheduled map[string]*ScheduledCashout
+	inFlight  map[common.Hash]inFlightCashout
+
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func scheduledCashoutKey(peer swarm.Address) string {
+	return scheduledCashoutPrefix + peer.String()
+}
+
+// New creates a new Optimizer sending the cashouts through cashout. Cashouts
+// scheduled before a restart are loaded from the store.
+func New(logger log.Logger, store storage.StateStorer, backend transaction.Backend, cashout CashoutFunc, o Options) (*Optimizer, error) {
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = DefaultCheckInterval
+	}
+	if o.FeeHistoryBlocks == 0 {
+		o.FeeHistoryBlocks = DefaultFeeHistoryBlocks
+	}
+	if o.FeePercentile <= 0 || o.FeePercentile > 100 {
+		o.FeePercentile = DefaultFeePercentile
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = DefaultParallelism
+	}
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = DefaultMaxInFlight
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+
+	s := &Optimizer{
+		logger:    logger.WithName(loggerName).Register(),
+		store:     store,
+		backend:   backend,
+		cashout:   cashout,
+		options:   o,
+		metrics:   newMetrics(),
+		clock:     o.Clock,
+		scheduled: make(map[string]*ScheduledCashout),
+		inFlight:  make(map[common.Hash]inFlightCashout),
+		quit:      make(chan struct{}),
+	}
+
+	err := store.Iterate(scheduledCashoutPrefix, func(key, value []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), scheduledCashoutPrefix) {
+			return true, nil
+		}
+		var scheduled ScheduledCashout
+		if err := json.Unmarshal(value, &scheduled); err != nil {
+			return true, fmt.Errorf("scheduled cashout %s: %w", key, err)
+		}
+		s.scheduled[scheduled.Peer.ByteString()] = &scheduled
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.ScheduledCashouts.Set(float64(len(s.scheduled)))
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// Schedule schedules the cashout of the last cheque of the peer to be sent
+// within delay. If a cashout of the peer is already scheduled, the earlier
+// deadline is kept.
+func (s *Optimizer) Schedule(ctx context.Context, peer swarm.Address, delay time.Duration) (ScheduledCashout, error) {
+	if delay <= 0 || delay > s.options.MaxDelay {
+		return ScheduledCashout{}, fmt.Errorf("%w: delay %s, maximum %s", ErrInvalidDeadline, delay, s.options.MaxDelay)
+	}
+
+	// the base fee at scheduling is the reference for the savings estimate
+	baseFee, _, err := s.feeWindow(ctx)
+	if err != nil {
+		s.logger.Debug("base fee unavailable, savings of cashout not estimated", "peer_address", peer, "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	scheduled := &ScheduledCashout{
+		Peer:      peer,
+		Scheduled: now,
+		Deadline:  now.Add(delay),
+		BaseFee:   baseFee,
+	}
+	if prev, ok := s.scheduled[peer.ByteString()]; ok {
+		if prev.Deadline.Before(scheduled.Deadline) {
+			return *prev, nil
+		}
+		scheduled.Scheduled = prev.Scheduled
+		scheduled.BaseFee = prev.BaseFee
+	}
+
+	if err := s.store.Put(scheduledCashoutKey(peer), scheduled); err != nil {
+		return ScheduledCashout{}, err
+	}
+	s.scheduled[peer.ByteString()] = scheduled
+	s.metrics.ScheduledCashouts.Set(float64(len(s.scheduled)))
+
+	s.logger.Debug("cashout scheduled", "peer_address", peer, "deadline", scheduled.Deadline)
+
+	return *scheduled, nil
+}
+
+// Scheduled returns the cashouts waiting for a low base fee, earliest deadline first.
+func (s *Optimizer) Scheduled() []ScheduledCashout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled := make([]ScheduledCashout, 0, len(s.scheduled))
+	for _, c := range s.scheduled {
+		scheduled = append(scheduled, *c)
+	}
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].Deadline.Before(scheduled[j].Deadline)
+	})
+	return scheduled
+}
+
+func (s *Optimizer) run() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.quit
+		cancel()
+	}()
+
+	ticker := s.clock.NewTicker(s.options.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+		case <-s.quit:
+			return
+		}
+		s.check(ctx)
+	}
+}
+
+// check sends all scheduled cashouts if the base fee is low and otherwise the
+// ones which reached their deadline. Up to Parallelism cashouts are sent at the
+// same time, the transaction service assigns their nonces one after another.
+// Cashouts exceeding MaxInFlight pending transactions wait for the next check.
+// The sent transactions are checked first so that failed ones are retried.
+func (s *Optimizer) check(ctx context.Context) {
+	inFlight := s.pruneInFlight(ctx)
+
+	pending := s.Scheduled()
+	if len(pending) == 0 {
+		return
+	}
+
+	now := s.clock.Now()
+	baseFee, threshold, err := s.feeWindow(ctx)
+	if err != nil {
+		// without fee history cashouts are only sent at their deadline
+		s.logger.Debug("fee history unavailable", "error", err)
+	}
+	low := err == nil && baseFee.Cmp(threshold) <= 0
+
+	maxInFlight := s.MaxInFlight()
+	capacity := maxInFlight - inFlight
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, s.options.Parallelism)
+	)
+	defer wg.Wait()
+
+	for _, c := range pending {
+		if !low && now.Before(c.Deadline) {
+			continue
+		}
+		if capacity <= 0 {
+			s.metrics.ThrottledCashouts.Inc()
+			s.logger.Debug("too many cashout transactions in flight, cashout postponed", "peer_address", c.Peer, "max_in_flight", maxInFlight)
+			continue
+		}
+		capacity--
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func(c ScheduledCashout) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.send(ctx, c, low, baseFee)
+		}(c)
+	}
+}
+
+// send sends the scheduled cashout and removes it unless it failed and is retried.
+func (s *Optimizer) send(ctx context.Context, c ScheduledCashout, low bool, baseFee *big.Int) {
+	txHash, err := s.cashout(ctx, c.Peer)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		if !errors.Is(err, chequebook.ErrNoCheque) {
+			s.metrics.FailedCashouts.Inc()
+			s.logger.Error(err, "scheduled cashout failed, retrying", "peer_address", c.Peer)
+			return
+		}
+		s.logger.Debug("no cheque to cash, scheduled cashout dropped", "peer_address", c.Peer)
+	} else {
+		s.addInFlight(txHash, c)
+		if low {
+			s.metrics.LowFeeCashouts.Inc()
+		} else {
+			s.metrics.DeadlineCashouts.Inc()
+		}
+		if c.BaseFee != nil && baseFee != nil {
+			s.reportSavings(new(big.Int).Sub(c.BaseFee, baseFee))
+		}
+		s.logger.Info("scheduled cashout sent", "peer_address", c.Peer, "transaction", txHash, "base_fee", baseFee, "low_fee", low)
+	}
+
+	s.remove(c)
+}
+
+// addInFlight records a sent cashout transaction until it is mined.
+func (s *Optimizer) addInFlight(txHash common.Hash, c ScheduledCashout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight[txHash] = inFlightCashout{
+		Peer:    c.Peer,
+		Sent:    s.clock.Now(),
+		Retries: c.Retries,
+	}
+	s.metrics.InFlightCashouts.Set(float64(len(s.inFlight)))
+}
+
+// pruneInFlight forgets the cashout transactions which were mined or dropped
+// and returns the number of the ones still pending. Cashouts whose transaction
+// reverted or was dropped are rescheduled. Transactions whose receipt cannot
+// be fetched count as pending.
+func (s *Optimizer) pruneInFlight(ctx context.Context) int {
+	s.mu.Lock()
+	inFlight := make(map[common.Hash]inFlightCashout, len(s.inFlight))
+	for txHash, c := range s.inFlight {
+		inFlight[txHash] = c
+	}
+	s.mu.Unlock()
+
+	var (
+		done   []common.Hash
+		failed = make(map[common.Hash]string)
+	)
+	for txHash, c := range inFlight {
+		receipt, err := s.backend.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			done = append(done, txHash)
+			if receipt.Status == types.ReceiptStatusFailed {
+				failed[txHash] = "reverted"
+			}
+			continue
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			s.logger.Debug("cashout transaction receipt unavailable", "transaction", txHash, "error", err)
+			continue
+		}
+		if s.clock.Now().Sub(c.Sent) < chequebook.DroppedCashoutTimeout {
+			continue
+		}
+		if _, _, err := s.backend.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+			done = append(done, txHash)
+			failed[txHash] = "dropped"
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, txHash := range done {
+		if reason, ok := failed[txHash]; ok {
+			s.retry(inFlight[txHash], txHash, reason)
+		}
+		delete(s.inFlight, txHash)
+	}
+	s.metrics.InFlightCashouts.Set(float64(len(s.inFlight)))
+	return len(s.inFlight)
+}
+
+// retry reschedules the cashout whose transaction reverted or was dropped to
+// be sent at the next check, unless it was retried MaxRetries times already.
+// It must be called with the lock held.
+func (s *Optimizer) retry(c inFlightCashout, txHash common.Hash, reason string) {
+	if c.Retries >= s.options.MaxRetries {
+		s.logger.Warning("cashout transaction failed, not retrying", "peer_address", c.Peer, "transaction", txHash, "reason", reason, "retries", c.Retries)
+		return
+	}
+
+	now := s.clock.Now()
+	scheduled := &ScheduledCashout{
+		Peer:      c.Peer,
+		Scheduled: now,
+		Deadline:  now,
+		Retries:   c.Retries + 1,
+	}
+	if prev, ok := s.scheduled[c.Peer.ByteString()]; ok {
+		// a cashout scheduled meanwhile sends the last cheque as well
+		prev.Deadline = now
+		scheduled = prev
+	}
+	if err := s.store.Put(scheduledCashoutKey(c.Peer), scheduled); err != nil {
+		s.logger.Error(err, "failed to reschedule cashout", "peer_address", c.Peer)
+		return
+	}
+	s.scheduled[c.Peer.ByteString()] = scheduled
+	s.metrics.ScheduledCashouts.Set(float64(len(s.scheduled)))
+	s.metrics.RetriedCashouts.Inc()
+
+	s.logger.Info("cashout transaction failed, retrying", "peer_address", c.Peer, "transaction", txHash, "reason", reason, "retry", scheduled.Retries)
+}
+
+// reportSavings adds the estimated savings of a cashout sent with a base fee
+// lower by difference than at scheduling. A negative difference is an extra cost.
+func (s *Optimizer) reportSavings(difference *big.Int) {
+	amount, _ := new(big.Float).SetInt(new(big.Int).Mul(difference, big.NewInt(estimatedCashoutGas))).Float64()
+	if amount >= 0 {
+		s.metrics.EstimatedSavings.Add(amount)
+	} else {
+		s.metrics.EstimatedExtraCost.Add(-amount)
+	}
+}
+
+// remove removes the scheduled cashout unless it was rescheduled meanwhile.
+func (s *Optimizer) remove(c ScheduledCashout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.scheduled[c.Peer.ByteString()]
+	if !ok || !current.Deadline.Equal(c.Deadline) {
+		return
+	}
+	if err := s.store.Delete(scheduledCashoutKey(c.Peer)); err != nil {
+		s.logger.Error(err, "failed to delete scheduled cashout", "peer_address", c.Peer)
+	}
+	delete(s.scheduled, c.Peer.ByteString())
+	s.metrics.ScheduledCashouts.Set(float64(len(s.scheduled)))
+}
+
+// feeWindow returns the base fee of the next block and the configured
+// percentile of the base fees of the past blocks.
+func (s *Optimizer) feeWindow(ctx context.Context) (baseFee, threshold *big.Int, err error) {
+	history, err := s.backend.FeeHistory(ctx, s.options.FeeHistoryBlocks, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the base fees include the one of the block after the newest block
+	if len(history.BaseFee) < 2 {
+		return nil, nil, ErrNoFeeHistory
+	}
+	for _, fee := range history.BaseFee {
+		if fee == nil {
+			return nil, nil, ErrNoFeeHistory
+		}
+	}
+
+	baseFee = history.BaseFee[len(history.BaseFee)-1]
+	past := make([]*big.Int, len(history.BaseFee)-1)
+	copy(past, history.BaseFee)
+	sort.Slice(past, func(i, j int) bool {
+		return past[i].Cmp(past[j]) < 0
+	})
+	threshold = past[int(s.FeePercentile()/100*float64(len(past)-1))]
+
+	return baseFee, threshold, nil
+}
+
+// FeePercentile returns the percentile of the past base fees at or below
+// which the base fee is low.
+func (s *Optimizer) FeePercentile() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.options.FeePercentile
+}
+
+// SetFeePercentile changes the percentile of the past base fees at or below
+// which the base fee is low, starting with the next check.
+func (s *Optimizer) SetFeePercentile(percentile float64) error {
+	if err := ValidateFeePercentile(percentile); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options.FeePercentile = percentile
+	return nil
+}
+
+// MaxInFlight returns the number of cashout transactions waiting to be mined
+// after which no more are sent.
+func (s *Optimizer) MaxInFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.options.MaxInFlight
+}
+
+// SetMaxInFlight changes the number of cashout transactions waiting to be
+// mined after which no more are sent, starting with the next check.
+func (s *Optimizer) SetMaxInFlight(maxInFlight int) error {
+	if err := ValidateMaxInFlight(maxInFlight); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options.MaxInFlight = maxInFlight
+	return nil
+}
+
+// ValidateFeePercentile returns ErrInvalidFeePercentile if the percentile is
+// not in (0, 100].
+func ValidateFeePercentile(percentile float64) error {
+	if percentile <= 0 || percentile > 100 {
+		return ErrInvalidFeePercentile
+	}
+	return nil
+}
+
+// ValidateMaxInFlight returns ErrInvalidMaxInFlight if the limit of cashouts
+// in flight is not positive.
+func ValidateMaxInFlight(maxInFlight int) error {
+	if maxInFlight <= 0 {
+		return ErrInvalidMaxInFlight
+	}
+	return nil
+}
+
+// Close stops sending scheduled cashouts. They are kept in the store and
+// resumed after a restart.
+func (s *Optimizer) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cashouttiming_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/cashouttiming"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+)
+
+var (
+	txHash = common.HexToHash("0xabcd")
+	peer   = swarm.MustParseHexAddress("abcd")
+)
+
+// baseFees returns a fee history of blocks with the past base fees followed by the current one.
+func baseFees(fees *atomic.Pointer[[]int64]) backendmock.Option {
+	return backendmock.WithFeeHistoryFunc(func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+		history := &ethereum.FeeHistory{}
+		for _, fee := range *fees.Load() {
+			history.BaseFee = append(history.BaseFee, big.NewInt(fee))
+		}
+		return history, nil
+	})
+}
+
+func newOptimizer(t *testing.T, store storage.StateStorer, fees *atomic.Pointer[[]int64]) (*cashouttiming.Optimizer, *atomic.Int32) {
+	t.Helper()
+
+	var sent atomic.Int32
+	o, err := cashouttiming.New(
+		log.Noop,
+		store,
+		backendmock.New(baseFees(fees)),
+		func(ctx context.Context, p swarm.Address) (common.Hash, error) {
+			if !p.Equal(peer) {
+				t.Errorf("cashout for peer %v, want %v", p, peer)
+			}
+			sent.Add(1)
+			return txHash, nil
+		},
+		cashouttiming.Options{
+			MaxDelay:      time.Hour,
+			CheckInterval: 10 * time.Millisecond,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = o.Close() })
+	return o, &sent
+}
+
+func fees(f ...int64) *atomic.Pointer[[]int64] {
+	p := new(atomic.Pointer[[]int64])
+	p.Store(&f)
+	return p
+}
+
+func waitSent(t *testing.T, o *cashouttiming.Optimizer, sent *atomic.Int32) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if sent.Load() == 1 && len(o.Scheduled()) == 0 {
+			return
+		}
+	}
+	t.Fatalf("got %d cashouts and %d scheduled, want 1 cashout sent", sent.Load(), len(o.Scheduled()))
+}
+
+func TestScheduleLowFee(t *testing.T) {
+	t.Parallel()
+
+	history := fees(40, 30, 50, 60, 90)
+	o, sent := newOptimizer(t, mockstore.NewStateStore(), history)
+
+	scheduled, err := o.Schedule(context.Background(), peer, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheduled.BaseFee.Cmp(big.NewInt(90)) != 0 {
+		t.Fatalf("got base fee %v, want %v", scheduled.BaseFee, 90)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n != 0 {
+		t.Fatalf("sent %d cashouts while the base fee is high", n)
+	}
+
+	// the base fee falls to the lowest quarter of the fee history
+	history.Store(&[]int64{40, 30, 50, 60, 30})
+	waitSent(t, o, sent)
+}
+
+func TestScheduleDeadline(t *testing.T) {
+	t.Parallel()
+
+	o, sent := newOptimizer(t, mockstore.NewStateStore(), fees(10, 10, 10, 10, 50))
+
+	if _, err := o.Schedule(context.Background(), peer, 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	// a later deadline does not postpone the cashout
+	scheduled, err := o.Schedule(context.Background(), peer, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(scheduled.Deadline) > 100*time.Millisecond {
+		t.Fatalf("got deadline %v, want the earlier one", scheduled.Deadline)
+	}
+
+	waitSent(t, o, sent)
+}
+
+func TestScheduleDeadlineClock(t *testing.T) {
+	t.Parallel()
+
+	clock := clockmock.New(time.Unix(1000, 0))
+	var sent atomic.Int32
+	o, err := cashouttiming.New(
+		log.Noop,
+		mockstore.NewStateStore(),
+		backendmock.New(baseFees(fees(10, 10, 10, 10, 50))),
+		func(ctx context.Context, p swarm.Address) (common.Hash, error) {
+			sent.Add(1)
+			return txHash, nil
+		},
+		cashouttiming.Options{
+			MaxDelay:      time.Hour,
+			CheckInterval: time.Minute,
+			Clock:         clock,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = o.Close() })
+
+	scheduled, err := o.Schedule(context.Background(), peer, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := clock.Now().Add(10 * time.Minute); !scheduled.Deadline.Equal(want) {
+		t.Fatalf("got deadline %v, want %v", scheduled.Deadline, want)
+	}
+
+	for start := time.Now(); clock.Waiters() == 0; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("optimizer does not wait for the clock")
+		}
+	}
+
+	// the base fee stays high until just before the deadline
+	clock.Advance(9 * time.Minute)
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n != 0 {
+		t.Fatalf("sent %d cashouts before the deadline", n)
+	}
+
+	clock.Advance(time.Minute)
+	waitSent(t, o, &sent)
+}
+
+func TestScheduleInvalidDeadline(t *testing.T) {
+	t.Parallel()
+
+	o, _ := newOptimizer(t, mockstore.NewStateStore(), fees(10, 10))
+
+	for _, delay := range []time.Duration{0, 2 * time.Hour} {
+		if _, err := o.Schedule(context.Background(), peer, delay); !errors.Is(err, cashouttiming.ErrInvalidDeadline) {
+			t.Fatalf("delay %s: got error %v, want %v", delay, err, cashouttiming.ErrInvalidDeadline)
+		}
+	}
+}
+
+func TestScheduleRestart(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	history := fees(10, 10, 10, 10, 50)
+
+	o, _ := newOptimizer(t, store, history)
+	if _, err := o.Schedule(context.Background(), peer, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	o, sent := newOptimizer(t, store, history)
+	scheduled := o.Scheduled()
+	if len(scheduled) != 1 || !scheduled[0].Peer.Equal(peer) {
+		t.Fatalf("got scheduled cashouts %v, want one of peer %v", scheduled, peer)
+	}
+
+	history.Store(&[]int64{10, 10, 10, 10, 10})
+	waitSent(t, o, sent)
+}
+
+func TestScheduleParallel(t *testing.T) {
+	t.Parallel()
+
+	const (
+		peers       = 8
+		parallelism = 3
+	)
+
+	var (
+		running, maxRunning, sent atomic.Int32
+		mined                     atomic.Bool
+	)
+	o, err := cashouttiming.New(
+		log.Noop,
+		mockstore.NewStateStore(),
+		backendmock.New(
+			baseFees(fees(10, 10, 10, 10, 50)),
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				if !mined.Load() {
+					return nil, ethereum.NotFound
+				}
+				return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+			}),
+		),
+		func(ctx context.Context, p swarm.Address) (common.Hash, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+			}
+			time.Sleep(20 * time.Millisecond)
+			sent.Add(1)
+			return common.BytesToHash(p.Bytes()), nil
+		},
+		cashouttiming.Options{
+			MaxDelay:      time.Hour,
+			CheckInterval: 10 * time.Millisecond,
+			Parallelism:   parallelism,
+			MaxInFlight:   peers - 2,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = o.Close() })
+
+	for i := 0; i < peers; i++ {
+		if _, err := o.Schedule(context.Background(), swarm.MustParseHexAddress(fmt.Sprintf("%02x", i+1)), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitScheduled := func(want int) {
+		t.Helper()
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			if len(o.Scheduled()) == want {
+				return
+			}
+		}
+		t.Fatalf("got %d scheduled cashouts, want %d", len(o.Scheduled()), want)
+	}
+
+	// the transactions of the first cashouts are not mined and hold back the rest
+	waitScheduled(2)
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n != peers-2 {
+		t.Fatalf("sent %d cashouts, want %d", n, peers-2)
+	}
+	if n := maxRunning.Load(); n < 2 || n > parallelism {
+		t.Fatalf("sent %d cashouts at the same time, want between 2 and %d", n, parallelism)
+	}
+
+	mined.Store(true)
+	waitScheduled(0)
+	if n := sent.Load(); n != peers {
+		t.Fatalf("sent %d cashouts, want %d", n, peers)
+	}
+}
+
+func TestScheduleRetryReverted(t *testing.T) {
+	t.Parallel()
+
+	const maxRetries = 2
+
+	var sent atomic.Int32
+	o, err := cashouttiming.New(
+		log.Noop,
+		mockstore.NewStateStore(),
+		backendmock.New(
+			baseFees(fees(10, 10, 10, 10, 50)),
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusFailed}, nil
+			}),
+		),
+		func(ctx context.Context, p swarm.Address) (common.Hash, error) {
+			return common.BigToHash(big.NewInt(int64(sent.Add(1)))), nil
+		},
+		cashouttiming.Options{
+			MaxDelay:      time.Hour,
+			CheckInterval: 10 * time.Millisecond,
+			MaxRetries:    maxRetries,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = o.Close() })
+
+	if _, err := o.Schedule(context.Background(), peer, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	// every reverted cashout is sent again until the retries are exhausted
+	for start := time.Now(); time.Since(start) < 5*time.Second && sent.Load() < maxRetries+1; time.Sleep(10 * time.Millisecond) {
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := sent.Load(); n != maxRetries+1 {
+		t.Fatalf("sent %d cashouts, want %d", n, maxRetries+1)
+	}
+	if scheduled := o.Scheduled(); len(scheduled) != 0 {
+		t.Fatalf("got scheduled cashouts %v, want none", scheduled)
+	}
+}
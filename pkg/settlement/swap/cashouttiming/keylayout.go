@@ -0,0 +1,17 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cashouttiming
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the cashout timing optimizer.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     scheduledCashoutPrefix + "<peer>",
+		Value:       "cashouttiming.ScheduledCashout",
+		Version:     1,
+		Description: "cashout of the cheques of the peer scheduled for a low base fee",
+	},
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cashouttiming
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	ScheduledCashouts  prometheus.Gauge
+	LowFeeCashouts     prometheus.Counter
+	DeadlineCashouts   prometheus.Counter
+	FailedCashouts     prometheus.Counter
+	RetriedCashouts    prometheus.Counter
+	InFlightCashouts   prometheus.Gauge
+	ThrottledCashouts  prometheus.Counter
+	EstimatedSavings   prometheus.Counter
+	EstimatedExtraCost prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "cashout_timing"
+
+	return metrics{
+		ScheduledCashouts: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scheduled_cashouts",
+			Help:      "Number of cashouts waiting for a low base fee",
+		}),
+		LowFeeCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "low_fee_cashouts",
+			Help:      "Number of scheduled cashouts sent while the base fee was low",
+		}),
+		DeadlineCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "deadline_cashouts",
+			Help:      "Number of scheduled cashouts sent at their deadline without a low base fee",
+		}),
+		FailedCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failed_cashouts",
+			Help:      "Number of failed attempts to send a scheduled cashout",
+		}),
+		RetriedCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "retried_cashouts",
+			Help:      "Number of cashouts rescheduled because their transaction reverted or was dropped",
+		}),
+		InFlightCashouts: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "in_flight_cashouts",
+			Help:      "Number of sent cashout transactions not yet mined",
+		}),
+		ThrottledCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "throttled_cashouts",
+			Help:      "Number of due cashouts postponed because too many cashout transactions were in flight",
+		}),
+		EstimatedSavings: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "estimated_savings",
+			Help:      "Estimated fees in wei saved by sending scheduled cashouts at a lower base fee than when they were scheduled",
+		}),
+		EstimatedExtraCost: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "estimated_extra_cost",
+			Help:      "Estimated fees in wei paid extra by sending scheduled cashouts at a higher base fee than when they were scheduled",
+		}),
+	}
+}
+
+func (s *Optimizer) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// prefix for the persistence key of the cheque assignment of a chequebook
+const assignmentKeyPrefix = "swap_cashout_assignment_"
+
+var (
+	// ErrNoAssignment is the error returned if the last cheque of a chequebook is not assigned.
+	ErrNoAssignment = errors.New("no cheque assignment")
+	// ErrChequeAssigned is the error returned if a cheque is cashed or assigned
+	// while the proceeds of an earlier cheque are assigned and not cashed yet.
+	ErrChequeAssigned = errors.New("cheque assigned to a third party")
+	// ErrAssignmentUnsupported is the error returned if the chequebook contract
+	// does not support cashouts to a recipient designated by the beneficiary.
+	ErrAssignmentUnsupported = errors.New("chequebook does not support cheque assignments")
+	// ErrInvalidCallerPayout is the error returned if the payout to the caller
+	// of an assigned cashout exceeds the uncashed amount of the cheque.
+	ErrInvalidCallerPayout = errors.New("caller payout exceeds uncashed amount")
+)
+
+// ChequeAssignment assigns the proceeds of a received cheque to a recipient.
+// The cashier may cash the cheque with the cashCheque call of the chequebook
+// using the signature of the beneficiary, keeping the caller payout.
+type ChequeAssignment struct {
+	Cheque       SignedCheque
+	Cashier      common.Address // caller of cashCheque authorized by the beneficiary
+	Recipient    common.Address // address receiving the payout
+	CallerPayout *big.Int       // part of the payout going to the cashier
+	Signature    []byte         // cashout authorization of the beneficiary
+	Time         int64          // unix timestamp of the assignment
+}
+
+// CallData returns the call data of the cashCheque call the cashier has to
+// send to the chequebook.
+func (a *ChequeAssignment) CallData() ([]byte, error) {
+	return chequebookABI.Pack("cashCheque", a.Cheque.Beneficiary, a.Recipient, a.Cheque.CumulativePayout, a.Signature, a.CallerPayout, a.Cheque.Signature)
+}
+
+// ChequeAssignmentStatus is an assignment together with the progress of its
+// cashout.
+type ChequeAssignmentStatus struct {
+	Assignment ChequeAssignment
+	PaidOut    *big.Int // amount the chequebook paid out to the beneficiary in total
+	Cashed     bool     // the assigned cheque was cashed
+}
+
+// assignmentKey computes the key where to store the cheque assignment of the chequebook.
+func assignmentKey(chequebook common.Address) string {
+	return fmt.Sprintf("%s%x", assignmentKeyPrefix, chequebook)
+}
+
+func (s *cashoutService) assignment(chequebook common.Address) (*ChequeAssignment, error) {
+	var assignment ChequeAssignment
+	err := s.store.Get(assignmentKey(chequebook), &assignment)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNoAssignment
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// pendingAssignment returns ErrChequeAssigned if the chequebook has an
+// assignment which was not cashed yet.
+func (s *cashoutService) pendingAssignment(ctx context.Context, chequebook common.Address) error {
+	status, err := s.ChequeAssignment(ctx, chequebook)
+	if errors.Is(err, ErrNoAssignment) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !status.Cashed {
+		return ErrChequeAssigned
+	}
+	return nil
+}
+
+// AssignCheque authorizes the cashier to cash the last cheque of the
+// chequebook to the recipient, keeping the caller payout. The node does not
+// cash cheques of the chequebook until the assigned cheque was cashed or the
+// assignment was removed.
+func (s *cashoutService) AssignCheque(ctx context.Context, chequebook, cashier, recipient common.Address, callerPayout *big.Int) (*ChequeAssignment, error) {
+	if s.cashoutSigner == nil {
+		return nil, ErrNoCashoutSigner
+	}
+	if err := s.pendingAssignment(ctx, chequebook); err != nil {
+		return nil, err
+	}
+
+	cheque, err := s.chequeStore.LastCheque(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	paidOut, err := s.paidOut(ctx, chequebook, cheque.Beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	uncashed := new(big.Int).Sub(cheque.CumulativePayout, paidOut)
+	if uncashed.Sign() <= 0 {
+		return nil, ErrNoCheque
+	}
+	if callerPayout.Sign() < 0 || callerPayout.Cmp(uncashed) > 0 {
+		return nil, ErrInvalidCallerPayout
+	}
+
+	signature, err := s.signCashout(cheque, cashier, recipient, callerPayout)
+	if err != nil {
+		return nil, err
+	}
+	assignment := &ChequeAssignment{
+		Cheque:       *cheque,
+		Cashier:      cashier,
+		Recipient:    recipient,
+		CallerPayout: callerPayout,
+		Signature:    signature,
+		Time:         s.clock.Now().Unix(),
+	}
+	if err := s.store.Put(assignmentKey(chequebook), assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// ChequeAssignment returns the last assignment of a cheque of the chequebook
+// and whether it was cashed.
+func (s *cashoutService) ChequeAssignment(ctx context.Context, chequebook common.Address) (*ChequeAssignmentStatus, error) {
+	assignment, err := s.assignment(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	paidOut, err := s.paidOut(ctx, chequebook, assignment.Cheque.Beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	return &ChequeAssignmentStatus{
+		Assignment: *assignment,
+		PaidOut:    paidOut,
+		Cashed:     paidOut.Cmp(assignment.Cheque.CumulativePayout) >= 0,
+	}, nil
+}
+
+// RemoveChequeAssignment forgets the assignment of the chequebook so that the
+// node cashes its cheques again. The signed authorization stays valid until
+// the cheque is cashed.
+func (s *cashoutService) RemoveChequeAssignment(chequebook common.Address) error {
+	if _, err := s.assignment(chequebook); err != nil {
+		return err
+	}
+	return s.store.Delete(assignmentKey(chequebook))
+}
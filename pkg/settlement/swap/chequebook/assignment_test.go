@@ -0,0 +1,139 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+	signermock "github.com/ethersphere/bee/pkg/crypto/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequeAssignment(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("0x01")
+	cashier := common.HexToAddress("0xcccc")
+	recipient := common.HexToAddress("0xefff")
+	beneficiarySignature := []byte{2}
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      common.HexToAddress("0xaaaa"),
+			CumulativePayout: big.NewInt(500),
+		},
+		Signature: []byte{1},
+	}
+
+	cashoutSigner := chequebook.NewCashoutSigner(signermock.New(
+		signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+			if data.Message["sender"].(string) != cashier.Hex() {
+				t.Fatal("cashout not authorized for the cashier")
+			}
+			if data.Message["recipient"].(string) != recipient.Hex() {
+				t.Fatal("cashout not authorized to the recipient")
+			}
+			if data.Message["callerPayout"].(string) != "10" {
+				t.Fatal("cashout authorized with wrong caller payout")
+			}
+			return beneficiarySignature, nil
+		}),
+	), 1)
+
+	paidOut := big.NewInt(200)
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				return chequebookABI.Methods["paidOut"].Outputs.Pack(paidOut)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				return common.HexToHash("0xdddd"), nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+		),
+		cashoutSigner,
+		common.Address{},
+	)
+
+	ctx := context.Background()
+
+	if _, err := cashoutService.ChequeAssignment(ctx, chequebookAddress); !errors.Is(err, chequebook.ErrNoAssignment) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoAssignment)
+	}
+	if _, err := cashoutService.AssignCheque(ctx, chequebookAddress, cashier, recipient, big.NewInt(301)); !errors.Is(err, chequebook.ErrInvalidCallerPayout) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidCallerPayout)
+	}
+
+	assignment, err := cashoutService.AssignCheque(ctx, chequebookAddress, cashier, recipient, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callData, err := assignment.CallData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := chequebookABI.Pack("cashCheque", cheque.Beneficiary, recipient, cheque.CumulativePayout, beneficiarySignature, big.NewInt(10), cheque.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(callData, want) {
+		t.Fatal("wrong cashCheque call data")
+	}
+
+	// the node leaves the assigned cheque to the cashier
+	if _, err := cashoutService.CashCheque(ctx, chequebookAddress, recipient); !errors.Is(err, chequebook.ErrChequeAssigned) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeAssigned)
+	}
+	if _, err := cashoutService.AssignCheque(ctx, chequebookAddress, cashier, recipient, big.NewInt(10)); !errors.Is(err, chequebook.ErrChequeAssigned) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeAssigned)
+	}
+
+	status, err := cashoutService.ChequeAssignment(ctx, chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Cashed || status.PaidOut.Cmp(paidOut) != 0 {
+		t.Fatalf("got status cashed %t paid out %d, want pending at %d", status.Cashed, status.PaidOut, paidOut)
+	}
+
+	// the cashier cashed the cheque
+	paidOut = big.NewInt(500)
+	status, err = cashoutService.ChequeAssignment(ctx, chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Cashed {
+		t.Fatal("assigned cheque not cashed")
+	}
+	// the cheque is no longer assigned, but the cashier left nothing to cash
+	if _, err := cashoutService.CashCheque(ctx, chequebookAddress, recipient); !errors.Is(err, chequebook.ErrChequeCashed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeCashed)
+	}
+
+	if err := cashoutService.RemoveChequeAssignment(chequebookAddress); err != nil {
+		t.Fatal(err)
+	}
+	if err := cashoutService.RemoveChequeAssignment(chequebookAddress); !errors.Is(err, chequebook.ErrNoAssignment) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoAssignment)
+	}
+}
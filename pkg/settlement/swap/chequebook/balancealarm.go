@@ -0,0 +1,191 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+)
+
+// ErrBalanceDeclining is the error returned if issuance is paused because the
+// available balance declined faster than allowed.
+var ErrBalanceDeclining = errors.New("cheque issuance paused, available balance declining too fast")
+
+// BalanceDecline describes a decline of the available balance faster than allowed.
+type BalanceDecline struct {
+	Amount           *big.Int      // decline of the available balance within the window
+	MaxDecline       *big.Int      // decline allowed within the window
+	Window           time.Duration // window the decline is measured over
+	AvailableBalance *big.Int      // available balance after the last issued cheque
+}
+
+// BalanceDeclineHook is called once the available balance starts declining
+// faster than allowed.
+type BalanceDeclineHook func(decline BalanceDecline)
+
+// issuedAmount is the amount of a cheque issued at a point in time.
+type issuedAmount struct {
+	at     time.Time
+	amount *big.Int
+}
+
+// BalanceAlarm is a Service which raises an alarm if the available balance
+// declines by more than the allowed amount within the window. The available
+// balance declines by exactly the amounts of the issued cheques, so the
+// decline is measured from them, which leaves out deposits and withdrawals.
+type BalanceAlarm struct {
+	Service
+	window time.Duration
+	clock  clock.Clock
+
+	mu         sync.Mutex
+	maxDecline *big.Int
+	pause      bool
+	issued     []issuedAmount // cheques issued within the window, oldest first
+	declined   *big.Int       // sum of the issued amounts
+	alarmed    bool
+	hooks      []BalanceDeclineHook
+}
+
+// NewBalanceAlarm wraps the service so that the registered hooks are called
+// once more than maxDecline was issued within the window. If pause is set, no
+// cheques are issued while the decline within the window exceeds maxDecline,
+// which stops leaks from misbehaving accounting before the chequebook is
+// drained.
+func NewBalanceAlarm(service Service, maxDecline *big.Int, window time.Duration, pause bool) *BalanceAlarm {
+	return &BalanceAlarm{
+		Service:    service,
+		maxDecline: new(big.Int).Set(maxDecline),
+		window:     window,
+		pause:      pause,
+		clock:      clock.System,
+		declined:   big.NewInt(0),
+	}
+}
+
+// OnDecline registers a hook called once the available balance starts
+// declining faster than allowed.
+func (a *BalanceAlarm) OnDecline(hook BalanceDeclineHook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook)
+}
+
+// Declining reports whether the available balance currently declines faster
+// than allowed.
+func (a *BalanceAlarm) Declining() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prune(a.clock.Now())
+	return a.alarmed
+}
+
+// MaxDecline returns the decline allowed within the window.
+func (a *BalanceAlarm) MaxDecline() *big.Int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return new(big.Int).Set(a.maxDecline)
+}
+
+// SetMaxDecline changes the decline allowed within the window. A decline
+// already beyond it raises the alarm with the next issued cheque.
+func (a *BalanceAlarm) SetMaxDecline(maxDecline *big.Int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxDecline = new(big.Int).Set(maxDecline)
+}
+
+// Pauses reports whether issuance is paused while the alarm is raised.
+func (a *BalanceAlarm) Pauses() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pause
+}
+
+// SetPause changes whether issuance is paused while the alarm is raised.
+func (a *BalanceAlarm) SetPause(pause bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pause = pause
+}
+
+// paused reports whether issuance is currently paused.
+func (a *BalanceAlarm) paused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prune(a.clock.Now())
+	return a.pause && a.alarmed
+}
+
+func (a *BalanceAlarm) Issue(ctx context.Context, beneficiary common.Address, amount Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+	if a.paused() {
+		return nil, ErrBalanceDeclining
+	}
+
+	availableBalance, err := a.Service.Issue(ctx, beneficiary, amount, sendChequeFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	now := a.clock.Now()
+	a.prune(now)
+	a.issued = append(a.issued, issuedAmount{at: now, amount: amount.BigInt()})
+	a.declined.Add(a.declined, amount.amountOrZero())
+
+	if a.alarmed || a.declined.Cmp(a.maxDecline) <= 0 {
+		a.mu.Unlock()
+		return availableBalance, nil
+	}
+	a.alarmed = true
+	decline := BalanceDecline{
+		Amount:           new(big.Int).Set(a.declined),
+		MaxDecline:       new(big.Int).Set(a.maxDecline),
+		Window:           a.window,
+		AvailableBalance: availableBalance,
+	}
+	hooks := append([]BalanceDeclineHook(nil), a.hooks...)
+	a.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(decline)
+	}
+	return availableBalance, nil
+}
+
+func (a *BalanceAlarm) PreviewIssue(ctx context.Context, beneficiary common.Address, amount Tokens) (*IssuePreview, error) {
+	preview, err := a.Service.PreviewIssue(ctx, beneficiary, amount)
+	if err != nil {
+		return nil, err
+	}
+	check := PolicyCheck{Policy: PolicyBalanceDecline}
+	if a.paused() {
+		check.Err = ErrBalanceDeclining
+	}
+	preview.Policies = append(preview.Policies, check)
+	return preview, nil
+}
+
+// prune drops the cheques issued before the window and clears the alarm once
+// the decline within the window is allowed again. It must be called with the
+// lock held.
+func (a *BalanceAlarm) prune(now time.Time) {
+	cutoff := now.Add(-a.window)
+	i := 0
+	for ; i < len(a.issued) && !a.issued[i].at.After(cutoff); i++ {
+		a.declined.Sub(a.declined, a.issued[i].amount)
+	}
+	a.issued = a.issued[i:]
+
+	if a.alarmed && a.declined.Cmp(a.maxDecline) <= 0 {
+		a.alarmed = false
+	}
+}
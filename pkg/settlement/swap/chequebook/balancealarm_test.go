@@ -0,0 +1,103 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+)
+
+func TestBalanceAlarm(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xbeef")
+	available := big.NewInt(1000)
+	alarm := chequebook.NewBalanceAlarm(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				available = new(big.Int).Sub(available, amount)
+				return available, nil
+			}),
+			mock.WithPreviewIssueFunc(func(ctx context.Context, b common.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+				return &chequebook.IssuePreview{Beneficiary: b, Amount: amount}, nil
+			}),
+		),
+		big.NewInt(100),
+		time.Minute,
+		true,
+	)
+
+	clock := clockmock.New(time.Unix(1000, 0))
+	chequebook.SetBalanceAlarmClock(alarm, clock)
+
+	var declines []chequebook.BalanceDecline
+	alarm.OnDecline(func(decline chequebook.BalanceDecline) {
+		declines = append(declines, decline)
+	})
+
+	ctx := context.Background()
+	issue := func(amount int64) error {
+		_, err := alarm.Issue(ctx, beneficiary, chequebook.MustNewTokens(big.NewInt(amount)), nil)
+		return err
+	}
+
+	if err := issue(60); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Second)
+	if err := issue(40); err != nil {
+		t.Fatal(err)
+	}
+	if len(declines) != 0 {
+		t.Fatalf("got %d alarms at the allowed decline, want none", len(declines))
+	}
+
+	// a decline of 110 within the minute raises the alarm and pauses issuance
+	clock.Advance(10 * time.Second)
+	if err := issue(10); err != nil {
+		t.Fatal(err)
+	}
+	if len(declines) != 1 {
+		t.Fatalf("got %d alarms, want 1", len(declines))
+	}
+	if declines[0].Amount.Cmp(big.NewInt(110)) != 0 || declines[0].AvailableBalance.Cmp(big.NewInt(890)) != 0 {
+		t.Fatalf("got decline of %d to %d, want 110 to 890", declines[0].Amount, declines[0].AvailableBalance)
+	}
+	if err := issue(1); !errors.Is(err, chequebook.ErrBalanceDeclining) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrBalanceDeclining)
+	}
+	preview, err := alarm.PreviewIssue(ctx, beneficiary, chequebook.TokensFromUint64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(preview.Err(), chequebook.ErrBalanceDeclining) {
+		t.Fatalf("got preview error %v, want %v", preview.Err(), chequebook.ErrBalanceDeclining)
+	}
+
+	// once the first cheque left the window issuance resumes
+	clock.Advance(20 * time.Second)
+	if err := issue(10); err != nil {
+		t.Fatal(err)
+	}
+	if len(declines) != 1 {
+		t.Fatalf("got %d alarms, want 1", len(declines))
+	}
+
+	// the alarm is raised again after it cleared
+	if err := issue(50); err != nil {
+		t.Fatal(err)
+	}
+	if len(declines) != 2 {
+		t.Fatalf("got %d alarms, want 2", len(declines))
+	}
+}
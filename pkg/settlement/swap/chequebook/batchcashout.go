@@ -0,0 +1,394 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+// multicallABI is the part of the Multicall3 ABI used for batch cashouts.
+const multicallABIJSON = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var multicallABI = abiutil.MustParseABI(multicallABIJSON)
+
+// cashoutGasLimit is the default gas limit of a single cashout.
+const cashoutGasLimit = 300_000
+
+// MaxBatchCashouts is the largest number of cashouts bundled into a single
+// transaction, so that the gas limit of a batch stays well below the block
+// gas limit.
+const MaxBatchCashouts = 32
+
+var (
+	// ErrCashoutSimulationFailed is the error returned for cheques of a batch cashout
+	// which would fail on-chain and were therefore left out of the batch.
+	ErrCashoutSimulationFailed = errors.New("cashout simulation failed")
+	// ErrNoCashoutSigner is the error returned if a batch cashout through a multicall
+	// contract is requested without a signer for the cashout authorization.
+	ErrNoCashoutSigner = errors.New("no cashout signer")
+)
+
+// Cashout is the authorization of the beneficiary to cash its cheque through another caller.
+type Cashout struct {
+	Chequebook    common.Address // chequebook the cheque is cashed from
+	Sender        common.Address // caller of cashCheque
+	RequestPayout *big.Int       // cumulative payout of the cheque
+	Recipient     common.Address // address receiving the payout
+	CallerPayout  *big.Int       // part of the payout going to the caller
+}
+
+// CashoutTypes are the needed type descriptions for cashout signing
+var CashoutTypes = eip712.Types{
+	"EIP712Domain": eip712.EIP712DomainType,
+	"Cashout": []eip712.Type{
+		{
+			Name: "chequebook",
+			Type: "address",
+		},
+		{
+			Name: "sender",
+			Type: "address",
+		},
+		{
+			Name: "requestPayout",
+			Type: "uint256",
+		},
+		{
+			Name: "recipient",
+			Type: "address",
+		},
+		{
+			Name: "callerPayout",
+			Type: "uint256",
+		},
+	},
+}
+
+// CashoutSigner signs cashout authorizations
+type CashoutSigner interface {
+	// Sign signs a cashout
+	Sign(cashout *Cashout) ([]byte, error)
+}
+
+type cashoutSigner struct {
+	signer  crypto.Signer // the underlying signer used
+	chainID int64         // the chainID used for EIP712
+}
+
+// NewCashoutSigner creates a new cashout signer for the given chainID.
+func NewCashoutSigner(signer crypto.Signer, chainID int64) CashoutSigner {
+	return &cashoutSigner{
+		signer:  signer,
+		chainID: chainID,
+	}
+}
+
+// eip712DataForCashout converts a cashout into the correct TypedData structure.
+func eip712DataForCashout(cashout *Cashout, chainID int64) *eip712.TypedData {
+	return &eip712.TypedData{
+		Domain: chequebookDomain(chainID),
+		Types:  CashoutTypes,
+		Message: eip712.TypedDataMessage{
+			"chequebook":    cashout.Chequebook.Hex(),
+			"sender":        cashout.Sender.Hex(),
+			"requestPayout": cashout.RequestPayout.String(),
+			"recipient":     cashout.Recipient.Hex(),
+			"callerPayout":  cashout.CallerPayout.String(),
+		},
+		PrimaryType: "Cashout",
+	}
+}
+
+// Sign signs a cashout.
+func (s *cashoutSigner) Sign(cashout *Cashout) ([]byte, error) {
+	return s.signer.SignTypedData(eip712DataForCashout(cashout, s.chainID))
+}
+
+// BeneficiaryCheques are chequebooks whose last cheques are cashed to the
+// same recipient in a batch cashout.
+type BeneficiaryCheques struct {
+	Recipient   common.Address   // address receiving the payouts
+	Chequebooks []common.Address // chequebooks whose last cheques are cashed
+}
+
+// BatchCashoutResult is the result of cashing the last cheque of one chequebook
+// for one beneficiary in a batch.
+type BatchCashoutResult struct {
+	Chequebook common.Address
+	Recipient  common.Address // address receiving the payout
+	Cheque     *SignedCheque  // the cheque that was cashed, nil if there is none
+	TxHash     common.Hash    // transaction containing the cashout, zero if it was not sent
+	Err        error          // reason why the cheque was not sent
+}
+
+// chequebookCheques returns the last cheques received from the chequebook for
+// every beneficiary, the one returned by ChequeStore.LastCheque last. Cheques
+// for previous beneficiaries for which a cashout was sent already are left out.
+func (s *cashoutService) chequebookCheques(chequebook common.Address) ([]*SignedCheque, error) {
+	last, err := s.chequeStore.LastCheque(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	cheques, err := s.chequeStore.LastBeneficiaryCheques(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	if len(cheques) <= 1 {
+		return []*SignedCheque{last}, nil
+	}
+
+	cashouts, err := s.ChequeCashouts(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	previous := make([]*SignedCheque, 0, len(cheques))
+	for beneficiary, cheque := range cheques {
+		if beneficiary == last.Beneficiary || cashedBefore(cashouts, cheque) {
+			continue
+		}
+		previous = append(previous, cheque)
+	}
+	sort.Slice(previous, func(i, j int) bool {
+		return bytes.Compare(previous[i].Beneficiary.Bytes(), previous[j].Beneficiary.Bytes()) < 0
+	})
+	return append(previous, last), nil
+}
+
+// cashedBefore reports whether one of the cashouts was sent for the cheque.
+func cashedBefore(cashouts []ChequeCashout, cheque *SignedCheque) bool {
+	for _, cashout := range cashouts {
+		if cashout.Cheque.Beneficiary == cheque.Beneficiary && cashout.Cheque.CumulativePayout.Cmp(cheque.CumulativePayout) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// multicallCall is a call of the Multicall3 aggregate3 function.
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicallResult is the result of a single call of aggregate3.
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// CashChequeBatch sends cashout transactions for the last cheques of all the
+// given chequebooks. If a multicall contract is configured, all cashouts are
+// bundled into a single transaction, otherwise they are sent one after another.
+// The status of each cashout can be tracked with CashoutStatus.
+func (s *cashoutService) CashChequeBatch(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]BatchCashoutResult, error) {
+	return s.CashoutBatch(ctx, []BeneficiaryCheques{{Recipient: recipient, Chequebooks: chequebooks}})
+}
+
+// CashoutBatch sends cashout transactions for the last cheques of all the
+// given chequebooks to their recipients. A chequebook which sent cheques to
+// several of our beneficiaries gets one result for each of them, the cheques
+// for previous beneficiaries first and the one returned by
+// ChequeStore.LastCheque last, so that its cashout is the last cashout action
+// of the chequebook. If a multicall contract is
+// configured, the cashouts are bundled into transactions of at most
+// MaxBatchCashouts cashouts with a gas limit of cashoutGasLimit each,
+// otherwise they are sent one after another with consecutive nonces. The
+// results are in the order of the chequebooks. If a transaction cannot be
+// sent, the results are returned with the error, and the cashouts of the
+// transactions sent before keep their transaction hash.
+func (s *cashoutService) CashoutBatch(ctx context.Context, batch []BeneficiaryCheques) ([]BatchCashoutResult, error) {
+	var results []BatchCashoutResult
+	for _, b := range batch {
+		for _, chequebook := range b.Chequebooks {
+			cheques, err := s.chequebookCheques(chequebook)
+			if err != nil {
+				results = append(results, BatchCashoutResult{Chequebook: chequebook, Recipient: b.Recipient, Err: err})
+				continue
+			}
+			for _, cheque := range cheques {
+				result := BatchCashoutResult{Chequebook: chequebook, Recipient: b.Recipient, Cheque: cheque}
+				// assigned cheques are left to their cashier
+				result.Err = s.pendingAssignment(ctx, chequebook)
+				results = append(results, result)
+			}
+		}
+	}
+
+	if s.multicall == (common.Address{}) {
+		for i := range results {
+			if results[i].Err != nil {
+				continue
+			}
+			results[i].TxHash, results[i].Err = s.cashCheque(ctx, results[i].Cheque, results[i].Recipient, cashoutGasLimit)
+		}
+		return results, nil
+	}
+
+	if s.cashoutSigner == nil {
+		return nil, ErrNoCashoutSigner
+	}
+
+	var (
+		calls   []multicallCall
+		indices []int // index into results of every call
+	)
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		callData, err := s.cashChequeCallData(results[i].Cheque, s.multicall, results[i].Recipient)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		calls = append(calls, multicallCall{
+			Target:       results[i].Chequebook,
+			AllowFailure: true,
+			CallData:     callData,
+		})
+		indices = append(indices, i)
+	}
+
+	// leave out all cashouts which would fail so that they do not waste gas
+	simulated, err := callMulticall(ctx, s.transactionService, s.multicall, calls)
+	if err != nil {
+		return nil, err
+	}
+	included := calls[:0]
+	includedIndices := make([]int, 0, len(indices))
+	for j, result := range simulated {
+		if !result.Success {
+			results[indices[j]].Err = fmt.Errorf("chequebook %x: %w", calls[j].Target, ErrCashoutSimulationFailed)
+			continue
+		}
+		included = append(included, calls[j])
+		includedIndices = append(includedIndices, indices[j])
+	}
+
+	for len(included) > 0 {
+		n := len(included)
+		if n > MaxBatchCashouts {
+			n = MaxBatchCashouts
+		}
+		if err := s.sendMulticall(ctx, included[:n], includedIndices[:n], results); err != nil {
+			// the cashouts of earlier transactions were sent nevertheless
+			for _, i := range includedIndices {
+				if results[i].TxHash == (common.Hash{}) && results[i].Err == nil {
+					results[i].Err = err
+				}
+			}
+			return results, err
+		}
+		included, includedIndices = included[n:], includedIndices[n:]
+	}
+
+	return results, nil
+}
+
+// sendMulticall sends the calls in a single transaction through the multicall
+// contract and records the cashouts of the results at the indices.
+func (s *cashoutService) sendMulticall(ctx context.Context, calls []multicallCall, indices []int, results []BatchCashoutResult) error {
+	callData, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return err
+	}
+	request := &transaction.TxRequest{
+		To:          &s.multicall,
+		Data:        callData,
+		GasPrice:    sctx.GetGasPrice(ctx),
+		GasLimit:    sctx.GetGasLimitWithDefault(ctx, uint64(len(calls))*cashoutGasLimit),
+		Value:       big.NewInt(0),
+		Description: "batch cheque cashout",
+	}
+
+	txHash, err := s.transactionService.Send(ctx, request, transaction.DefaultTipBoostPercent)
+	for _, i := range indices {
+		if recordErr := s.recordAttempt(results[i].Cheque, txHash, err); recordErr != nil {
+			return errors.Join(err, recordErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	cheques := make([]*SignedCheque, 0, len(indices))
+	for _, i := range indices {
+		results[i].TxHash = txHash
+		results[i].Err = s.store.Put(cashoutActionKey(results[i].Chequebook), &cashoutAction{
+			TxHash: txHash,
+			Cheque: *results[i].Cheque,
+		})
+		cheques = append(cheques, results[i].Cheque)
+	}
+	return s.recordCashout(txHash, cheques...)
+}
+
+// cashChequeCallData encodes a cashCheque call authorizing the sender to cash
+// the cheque on behalf of the beneficiary.
+func (s *cashoutService) cashChequeCallData(cheque *SignedCheque, sender, recipient common.Address) ([]byte, error) {
+	callerPayout := big.NewInt(0)
+	beneficiarySig, err := s.signCashout(cheque, sender, recipient, callerPayout)
+	if err != nil {
+		return nil, err
+	}
+
+	return chequebookABI.Pack("cashCheque", cheque.Beneficiary, recipient, cheque.CumulativePayout, beneficiarySig, callerPayout, cheque.Signature)
+}
+
+// signCashout signs the authorization of the sender to cash the cheque to the
+// recipient, keeping the caller payout.
+func (s *cashoutService) signCashout(cheque *SignedCheque, sender, recipient common.Address, callerPayout *big.Int) ([]byte, error) {
+	return s.cashoutSigner.Sign(&Cashout{
+		Chequebook:    cheque.Chequebook,
+		Sender:        sender,
+		RequestPayout: cheque.CumulativePayout,
+		Recipient:     recipient,
+		CallerPayout:  callerPayout,
+	})
+}
+
+// callMulticall executes the calls through the multicall contract without
+// sending a transaction and returns their results.
+func callMulticall(ctx context.Context, transactionService transaction.Service, multicall common.Address, calls []multicallCall) ([]multicallResult, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	callData, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &multicall,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []multicallResult
+	if err := multicallABI.UnpackIntoInterface(&results, "aggregate3", output); err != nil {
+		return nil, err
+	}
+	if len(results) != len(calls) {
+		return nil, errDecodeABI
+	}
+
+	return results, nil
+}
@@ -0,0 +1,557 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+	signermock "github.com/ethersphere/bee/pkg/crypto/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+func unpackMulticall(t *testing.T, data []byte) []multicallCall {
+	t.Helper()
+
+	method, ok := chequebook.MulticallABI.Methods["aggregate3"]
+	if !ok || len(data) < 4 || string(data[:4]) != string(method.ID) {
+		t.Fatal("not an aggregate3 call")
+	}
+	var calls []multicallCall
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := method.Inputs.Copy(&calls, args); err != nil {
+		t.Fatal(err)
+	}
+	return calls
+}
+
+func TestSignCashout(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	cashout := &chequebook.Cashout{
+		Chequebook:    common.HexToAddress("0xfa02D396842E6e1D319E8E3D4D870338F791AA25"),
+		Sender:        common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11"),
+		RequestPayout: big.NewInt(500),
+		Recipient:     common.HexToAddress("0x98E6C644aFeB94BBfB9FF60EB26fc9D83BBEcA79"),
+		CallerPayout:  big.NewInt(0),
+	}
+
+	var signed *eip712.TypedData
+	cashoutSigner := chequebook.NewCashoutSigner(signermock.New(
+		signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+			signed = data
+			return signer.SignTypedData(data)
+		}),
+	), 1)
+
+	signature, err := cashoutSigner.Sign(cashout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the type has to match the CASHOUT_TYPEHASH of the chequebook contract
+	encodedType := string(signed.EncodeType("Cashout"))
+	if encodedType != "Cashout(address chequebook,address sender,uint256 requestPayout,address recipient,uint256 callerPayout)" {
+		t.Fatalf("got encoded type %s", encodedType)
+	}
+
+	pubKey, err := crypto.RecoverEIP712(signature, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pubKey.Equal(&privKey.PublicKey) {
+		t.Fatal("signature recovers wrong key")
+	}
+}
+
+func TestCashoutBatchMulticall(t *testing.T) {
+	t.Parallel()
+
+	multicall := common.HexToAddress("0xca11")
+	recipient := common.HexToAddress("0xefff")
+	beneficiary := common.HexToAddress("0xaaaa")
+	txHash := common.HexToHash("0xdddd")
+
+	chequebookOK := common.HexToAddress("0x01")
+	chequebookFailing := common.HexToAddress("0x02")
+	chequebookNoCheque := common.HexToAddress("0x03")
+
+	cheques := map[common.Address]*chequebook.SignedCheque{}
+	for _, c := range []common.Address{chequebookOK, chequebookFailing} {
+		cheques[c] = &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       c,
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(500),
+			},
+			Signature: []byte{1},
+		}
+	}
+
+	cashoutSigner := chequebook.NewCashoutSigner(signermock.New(
+		signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+			if data.Message["sender"].(string) != multicall.Hex() {
+				t.Fatal("cashout not authorized for the multicall contract")
+			}
+			return []byte{2}, nil
+		}),
+	), 1)
+
+	store := storemock.NewStateStore()
+	cashoutService := chequebook.NewCashoutService(
+		store,
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if *request.To != multicall {
+					t.Fatalf("simulating call to %x, want %x", *request.To, multicall)
+				}
+				calls := unpackMulticall(t, request.Data)
+				results := make([]multicallResult, len(calls))
+				for i, call := range calls {
+					results[i].Success = call.Target != chequebookFailing
+				}
+				return chequebook.MulticallABI.Methods["aggregate3"].Outputs.Pack(results)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				if *request.To != multicall {
+					t.Fatalf("sending to %x, want %x", *request.To, multicall)
+				}
+				calls := unpackMulticall(t, request.Data)
+				if len(calls) != 1 || calls[0].Target != chequebookOK || !calls[0].AllowFailure {
+					t.Fatalf("got calls %+v, want only cashout of %x", calls, chequebookOK)
+				}
+				expected, err := chequebookABI.Pack("cashCheque", beneficiary, recipient, big.NewInt(500), []byte{2}, big.NewInt(0), []byte{1})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(calls[0].CallData) != string(expected) {
+					t.Fatal("wrong cashCheque call data")
+				}
+				return txHash, nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				cheque, ok := cheques[c]
+				if !ok {
+					return nil, chequebook.ErrNoCheque
+				}
+				return cheque, nil
+			}),
+		),
+		cashoutSigner,
+		multicall,
+	)
+
+	results, err := cashoutService.CashChequeBatch(context.Background(), []common.Address{chequebookOK, chequebookFailing, chequebookNoCheque}, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want %d", len(results), 3)
+	}
+	if results[0].Err != nil || results[0].TxHash != txHash {
+		t.Fatalf("got result %+v, want cashout in %x", results[0], txHash)
+	}
+	if !errors.Is(results[1].Err, chequebook.ErrCashoutSimulationFailed) {
+		t.Fatalf("got error %v, want %v", results[1].Err, chequebook.ErrCashoutSimulationFailed)
+	}
+	if !errors.Is(results[2].Err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", results[2].Err, chequebook.ErrNoCheque)
+	}
+
+	// the cashout is tracked per chequebook
+	var action struct{ TxHash common.Hash }
+	if err := store.Get(chequebook.CashoutActionKey(chequebookOK), &action); err != nil {
+		t.Fatal(err)
+	}
+	if action.TxHash != txHash {
+		t.Fatalf("got tracked transaction %x, want %x", action.TxHash, txHash)
+	}
+	if err := store.Get(chequebook.CashoutActionKey(chequebookFailing), &action); err == nil {
+		t.Fatal("tracked cashout which was not sent")
+	}
+}
+
+func TestCashoutBatchSequential(t *testing.T) {
+	t.Parallel()
+
+	recipient := common.HexToAddress("0xefff")
+	chequebooks := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+
+	sent := 0
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				if *request.To != chequebooks[sent] {
+					t.Fatalf("sending to %x, want %x", *request.To, chequebooks[sent])
+				}
+				sent++
+				return common.BigToHash(big.NewInt(int64(sent))), nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return &chequebook.SignedCheque{
+					Cheque: chequebook.Cheque{
+						Chequebook:       c,
+						Beneficiary:      common.HexToAddress("0xaaaa"),
+						CumulativePayout: big.NewInt(500),
+					},
+				}, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	results, err := cashoutService.CashChequeBatch(context.Background(), chequebooks, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 2 {
+		t.Fatalf("sent %d transactions, want %d", sent, 2)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.TxHash != common.BigToHash(big.NewInt(int64(i+1))) {
+			t.Fatalf("got result %+v", result)
+		}
+	}
+}
+
+func TestCashoutBatchBeneficiaries(t *testing.T) {
+	t.Parallel()
+
+	recipient := common.HexToAddress("0xefff")
+	chequebookAddress := common.HexToAddress("0x01")
+	previousBeneficiary := common.HexToAddress("0xaaaa")
+	beneficiary := common.HexToAddress("0xbbbb")
+	cheque := func(beneficiary common.Address, cumulativePayout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(cumulativePayout),
+			},
+		}
+	}
+	cheques := map[common.Address]*chequebook.SignedCheque{
+		previousBeneficiary: cheque(previousBeneficiary, 500),
+		beneficiary:         cheque(beneficiary, 100),
+	}
+
+	store := storemock.NewStateStore()
+	sent := 0
+	cashoutService := chequebook.NewCashoutService(
+		store,
+		backendmock.New(),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sent++
+				return common.BigToHash(big.NewInt(int64(sent))), nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheques[beneficiary], nil
+			}),
+			chequestoremock.WithLastBeneficiaryChequesFunc(func(c common.Address) (map[common.Address]*chequebook.SignedCheque, error) {
+				return cheques, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	results, err := cashoutService.CashChequeBatch(context.Background(), []common.Address{chequebookAddress}, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cheque for the previous beneficiary is cashed first, so that the one
+	// for the current beneficiary is the last cashout action
+	if len(results) != 2 || sent != 2 {
+		t.Fatalf("got %d results and %d transactions, want %d", len(results), sent, 2)
+	}
+	for i, want := range []*chequebook.SignedCheque{cheques[previousBeneficiary], cheques[beneficiary]} {
+		if results[i].Err != nil || results[i].Chequebook != chequebookAddress || !results[i].Cheque.Equal(want) {
+			t.Fatalf("got result %+v, want cheque %v", results[i], want)
+		}
+	}
+	var action struct {
+		Cheque chequebook.SignedCheque
+	}
+	if err := store.Get(chequebook.CashoutActionKey(chequebookAddress), &action); err != nil {
+		t.Fatal(err)
+	}
+	if action.Cheque.Beneficiary != beneficiary {
+		t.Fatalf("last cashout action for %x, want %x", action.Cheque.Beneficiary, beneficiary)
+	}
+
+	// the cheque for the previous beneficiary is not cashed again
+	results, err = cashoutService.CashChequeBatch(context.Background(), []common.Address{chequebookAddress}, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Cheque.Equal(cheques[beneficiary]) {
+		t.Fatalf("got results %+v", results)
+	}
+}
+
+func TestCashoutBatchRecipients(t *testing.T) {
+	t.Parallel()
+
+	multicall := common.HexToAddress("0xca11")
+	recipients := []common.Address{common.HexToAddress("0xeeee"), common.HexToAddress("0xffff")}
+
+	// more cheques than fit into one transaction
+	batch := make([]chequebook.BeneficiaryCheques, len(recipients))
+	for i := 0; i < chequebook.MaxBatchCashouts+2; i++ {
+		r := i % len(recipients)
+		batch[r].Recipient = recipients[r]
+		batch[r].Chequebooks = append(batch[r].Chequebooks, common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+
+	var sent []int // number of cashouts of every sent transaction
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				calls := unpackMulticall(t, request.Data)
+				results := make([]multicallResult, len(calls))
+				for i := range results {
+					results[i].Success = true
+				}
+				return chequebook.MulticallABI.Methods["aggregate3"].Outputs.Pack(results)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				calls := unpackMulticall(t, request.Data)
+				if request.GasLimit != uint64(len(calls))*300_000 {
+					t.Fatalf("got gas limit %d for %d cashouts", request.GasLimit, len(calls))
+				}
+				sent = append(sent, len(calls))
+				return common.BigToHash(big.NewInt(int64(len(sent)))), nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return &chequebook.SignedCheque{
+					Cheque: chequebook.Cheque{
+						Chequebook:       c,
+						Beneficiary:      common.HexToAddress("0xaaaa"),
+						CumulativePayout: big.NewInt(500),
+					},
+					Signature: []byte{1},
+				}, nil
+			}),
+		),
+		chequebook.NewCashoutSigner(signermock.New(
+			signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+				return []byte{2}, nil
+			}),
+		), 1),
+		multicall,
+	)
+
+	results, err := cashoutService.CashoutBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 2 || sent[0] != chequebook.MaxBatchCashouts || sent[1] != 2 {
+		t.Fatalf("sent transactions with %v cashouts, want [%d 2]", sent, chequebook.MaxBatchCashouts)
+	}
+	if len(results) != chequebook.MaxBatchCashouts+2 {
+		t.Fatalf("got %d results, want %d", len(results), chequebook.MaxBatchCashouts+2)
+	}
+	i := 0
+	for _, b := range batch {
+		for _, c := range b.Chequebooks {
+			if r := results[i]; r.Err != nil || r.Chequebook != c || r.Recipient != b.Recipient || r.TxHash == (common.Hash{}) {
+				t.Fatalf("got result %+v for chequebook %x of recipient %x", r, c, b.Recipient)
+			}
+			i++
+		}
+	}
+}
+
+func TestCashoutBatchPartiallySent(t *testing.T) {
+	t.Parallel()
+
+	multicall := common.HexToAddress("0xca11")
+	recipient := common.HexToAddress("0xefff")
+	firstTx := common.HexToHash("0x01")
+	errSend := errors.New("send failed")
+
+	var chequebooks []common.Address
+	for i := 0; i < chequebook.MaxBatchCashouts+2; i++ {
+		chequebooks = append(chequebooks, common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+
+	sent := 0
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				results := make([]multicallResult, len(unpackMulticall(t, request.Data)))
+				for i := range results {
+					results[i].Success = true
+				}
+				return chequebook.MulticallABI.Methods["aggregate3"].Outputs.Pack(results)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sent++
+				if sent > 1 {
+					return common.Hash{}, errSend
+				}
+				return firstTx, nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return &chequebook.SignedCheque{
+					Cheque: chequebook.Cheque{
+						Chequebook:       c,
+						Beneficiary:      common.HexToAddress("0xaaaa"),
+						CumulativePayout: big.NewInt(500),
+					},
+					Signature: []byte{1},
+				}, nil
+			}),
+		),
+		chequebook.NewCashoutSigner(signermock.New(
+			signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+				return []byte{2}, nil
+			}),
+		), 1),
+		multicall,
+	)
+
+	results, err := cashoutService.CashChequeBatch(context.Background(), chequebooks, recipient)
+	if !errors.Is(err, errSend) {
+		t.Fatalf("got error %v, want %v", err, errSend)
+	}
+
+	// the cashouts of the first transaction are reported although the second failed
+	if len(results) != len(chequebooks) {
+		t.Fatalf("got %d results, want %d", len(results), len(chequebooks))
+	}
+	for i, result := range results {
+		if i < chequebook.MaxBatchCashouts {
+			if result.Err != nil || result.TxHash != firstTx {
+				t.Fatalf("result %d: got %+v, want cashout in %x", i, result, firstTx)
+			}
+			continue
+		}
+		if !errors.Is(result.Err, errSend) || result.TxHash != (common.Hash{}) {
+			t.Fatalf("result %d: got %+v, want error %v", i, result, errSend)
+		}
+	}
+}
+
+// accountTransactionService sends transactions from a smart contract account.
+type accountTransactionService struct {
+	transaction.Service
+	account common.Address
+}
+
+func (s *accountTransactionService) Account() common.Address {
+	return s.account
+}
+
+func TestCashoutThroughAccount(t *testing.T) {
+	t.Parallel()
+
+	account := common.HexToAddress("0xacc0")
+	chequebookAddress := common.HexToAddress("0x01")
+	recipient := common.HexToAddress("0xefff")
+	txHash := common.HexToHash("0xdddd")
+	cashoutSignature := []byte{2}
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      common.HexToAddress("0xaaaa"),
+			CumulativePayout: big.NewInt(500),
+		},
+		Signature: []byte{1},
+	}
+
+	cashoutSigner := chequebook.NewCashoutSigner(signermock.New(
+		signermock.WithSignTypedDataFunc(func(data *eip712.TypedData) ([]byte, error) {
+			if data.Message["sender"].(string) != account.Hex() {
+				t.Fatal("cashout not authorized for the account")
+			}
+			return cashoutSignature, nil
+		}),
+	), 1)
+
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		&accountTransactionService{
+			Service: transactionmock.New(
+				withPaidOut(big.NewInt(0)),
+				// the account is not the beneficiary and has to use the authorized cashCheque
+				transactionmock.WithABISend(&chequebookABI, txHash, chequebookAddress, big.NewInt(0), "cashCheque", cheque.Beneficiary, recipient, cheque.CumulativePayout, cashoutSignature, big.NewInt(0), cheque.Signature),
+			),
+			account: account,
+		},
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+		),
+		cashoutSigner,
+		common.Address{},
+	)
+
+	returnedTxHash, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if returnedTxHash != txHash {
+		t.Fatalf("returned wrong transaction hash. wanted %v, got %v", txHash, returnedTxHash)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// verificationWorkers is the number of cheques or chequebooks verified concurrently in a batch.
+	verificationWorkers = 8
+	// maxVerificationCalls is the maximum number of deployedContracts calls bundled into one multicall.
+	maxVerificationCalls = 100
+)
+
+// ChequebookBatchVerifier is implemented by factories which verify many
+// chequebooks at once.
+type ChequebookBatchVerifier interface {
+	// VerifyChequebooks checks every chequebook like VerifyChequebook and
+	// returns the errors in the order of the chequebooks.
+	VerifyChequebooks(ctx context.Context, chequebooks []common.Address) []error
+}
+
+// MulticallFactory is implemented by factories which can bundle the calls of
+// VerifyChequebooks through a Multicall3 contract.
+type MulticallFactory interface {
+	// SetMulticall makes VerifyChequebooks bundle its calls through the
+	// Multicall3 contract at the address. The zero address disables bundling.
+	SetMulticall(multicall common.Address)
+}
+
+// forEach calls f for every index below n with a bounded pool of workers and
+// returns once all calls returned.
+func forEach(n int, f func(i int)) {
+	var g errgroup.Group
+	g.SetLimit(verificationWorkers)
+	for i := 0; i < n; i++ {
+		i := i
+		g.Go(func() error {
+			f(i)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// verifyChequebooks checks the chequebooks with the factory, in a batch if
+// the factory supports it and otherwise concurrently one by one.
+func verifyChequebooks(ctx context.Context, factory Factory, chequebooks []common.Address) []error {
+	if batch, ok := factory.(ChequebookBatchVerifier); ok {
+		return batch.VerifyChequebooks(ctx, chequebooks)
+	}
+
+	errs := make([]error, len(chequebooks))
+	forEach(len(chequebooks), func(i int) {
+		errs[i] = factory.VerifyChequebook(ctx, chequebooks[i])
+	})
+	return errs
+}
+
+// SetMulticall makes VerifyChequebooks bundle its calls through the Multicall3
+// contract at the address.
+func (c *factory) SetMulticall(multicall common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.multicall = multicall
+}
+
+func (c *factory) multicallAddress() common.Address {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.multicall
+}
+
+// VerifyChequebooks checks every chequebook like VerifyChequebook. If a
+// multicall contract is set, the factories are asked which chequebooks they
+// deployed with one call per factory and batch of chequebooks instead of one
+// call per factory and chequebook. The bytecode of the chequebooks is then
+// verified concurrently.
+func (c *factory) VerifyChequebooks(ctx context.Context, chequebooks []common.Address) []error {
+	errs := make([]error, len(chequebooks))
+
+	multicall := c.multicallAddress()
+	if multicall == (common.Address{}) {
+		forEach(len(chequebooks), func(i int) {
+			errs[i] = c.VerifyChequebook(ctx, chequebooks[i])
+		})
+		return errs
+	}
+
+	// the factory which deployed each of the chequebooks
+	deployedBy := make([]common.Address, len(chequebooks))
+	pending := make([]int, len(chequebooks))
+	for i := range chequebooks {
+		pending[i] = i
+	}
+
+	for _, factoryAddress := range append([]common.Address{c.address}, c.legacyFactories()...) {
+		var notDeployed []int
+		for start := 0; start < len(pending); start += maxVerificationCalls {
+			end := start + maxVerificationCalls
+			if end > len(pending) {
+				end = len(pending)
+			}
+
+			batch := pending[start:end]
+			deployed, err := c.deployedByFactory(ctx, multicall, factoryAddress, chequebooks, batch)
+			if err != nil {
+				for _, i := range batch {
+					errs[i] = err
+				}
+				continue
+			}
+			for j, i := range batch {
+				if deployed[j] {
+					deployedBy[i] = factoryAddress
+				} else {
+					notDeployed = append(notDeployed, i)
+				}
+			}
+		}
+		pending = notDeployed
+	}
+	for _, i := range pending {
+		errs[i] = ErrNotDeployedByFactory
+	}
+
+	forEach(len(chequebooks), func(i int) {
+		if errs[i] == nil {
+			errs[i] = c.verifyChequebookBytecode(ctx, deployedBy[i], chequebooks[i])
+		}
+	})
+	return errs
+}
+
+// deployedByFactory asks the factory with a single multicall whether it
+// deployed the chequebooks at the given indexes.
+func (c *factory) deployedByFactory(ctx context.Context, multicall, factory common.Address, chequebooks []common.Address, indexes []int) ([]bool, error) {
+	calls := make([]multicallCall, 0, len(indexes))
+	for _, i := range indexes {
+		callData, err := factoryABI.Pack("deployedContracts", chequebooks[i])
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, multicallCall{
+			Target:   factory,
+			CallData: callData,
+		})
+	}
+
+	results, err := callMulticall(ctx, c.transactionService, multicall, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make([]bool, len(results))
+	for j, result := range results {
+		if !result.Success {
+			return nil, errDecodeABI
+		}
+		deployed[j], err = unpackDeployed(result.ReturnData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deployed, nil
+}
+
+// SetMulticall sets the multicall contract of the wrapped factory.
+func (c *cachingFactory) SetMulticall(multicall common.Address) {
+	if f, ok := c.Factory.(MulticallFactory); ok {
+		f.SetMulticall(multicall)
+	}
+}
+
+// VerifyChequebooks checks every chequebook like VerifyChequebook. Only the
+// chequebooks without a cached result are verified by the wrapped factory, in
+// a batch if it supports it.
+func (c *cachingFactory) VerifyChequebooks(ctx context.Context, chequebooks []common.Address) []error {
+	now := c.clock.Now()
+	errs := make([]error, len(chequebooks))
+
+	var (
+		missed  []common.Address
+		indexes []int
+	)
+	for i, chequebook := range chequebooks {
+		result, ok, err := c.cached(now, chequebook)
+		switch {
+		case err != nil:
+			errs[i] = err
+		case !ok:
+			missed = append(missed, chequebook)
+			indexes = append(indexes, i)
+		case !result.Deployed:
+			errs[i] = ErrNotDeployedByFactory
+		}
+	}
+	if len(missed) == 0 {
+		return errs
+	}
+
+	generation := c.currentGeneration()
+	verified := verifyChequebooks(ctx, c.Factory, missed)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for j, i := range indexes {
+		errs[i] = verified[j]
+
+		result, ok := c.result(now, verified[j])
+		// do not cache a result which was obtained with an outdated list of trusted factories
+		if !ok || generation != c.generation {
+			continue
+		}
+		if err := c.store.Put(verificationKey(missed[j]), result); err != nil {
+			errs[i] = err
+		}
+	}
+	return errs
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+// batchFactoryMock is a factoryMock which also verifies chequebooks in a batch.
+type batchFactoryMock struct {
+	factoryMock
+	verifyChequebooks func(ctx context.Context, chequebooks []common.Address) []error
+}
+
+func (m *batchFactoryMock) VerifyChequebooks(ctx context.Context, chequebooks []common.Address) []error {
+	return m.verifyChequebooks(ctx, chequebooks)
+}
+
+func TestFactoryVerifyChequebooks(t *testing.T) {
+	t.Parallel()
+
+	multicall := common.HexToAddress("0xca11")
+	factoryAddress := common.HexToAddress("0xabcd")
+	legacyFactory := common.HexToAddress("0xbbbb")
+	masterAddress := common.HexToAddress("0x5555")
+
+	valid := common.HexToAddress("0x01")
+	notDeployed := common.HexToAddress("0x02")
+	wrongBytecode := common.HexToAddress("0x03")
+
+	var (
+		mu         sync.Mutex
+		multicalls = make(map[common.Address]int) // number of calls bundled per factory
+	)
+	factory := chequebook.NewFactory(
+		backendWithCodeAt(map[common.Address]string{
+			factoryAddress: sw3abi.SimpleSwapFactoryDeployedBinv0_4_0,
+			valid:          common.Bytes2Hex(chequebook.MinimalProxyCode(masterAddress)),
+			wrongBytecode:  common.Bytes2Hex(chequebook.MinimalProxyCode(common.HexToAddress("0x6666"))),
+		}),
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if *request.To == factoryAddress {
+					return masterAddress.Hash().Bytes(), nil
+				}
+				if *request.To != multicall {
+					t.Fatalf("call to %x, want %x", *request.To, multicall)
+				}
+
+				calls := unpackMulticall(t, request.Data)
+				results := make([]multicallResult, len(calls))
+				for i, call := range calls {
+					args, err := factoryABI.Methods["deployedContracts"].Inputs.Unpack(call.CallData[4:])
+					if err != nil {
+						t.Fatal(err)
+					}
+					chequebookAddress := args[0].(common.Address)
+					deployed := call.Target == factoryAddress && chequebookAddress != notDeployed
+
+					output, err := factoryABI.Methods["deployedContracts"].Outputs.Pack(deployed)
+					if err != nil {
+						t.Fatal(err)
+					}
+					results[i] = multicallResult{Success: true, ReturnData: output}
+
+					mu.Lock()
+					multicalls[call.Target]++
+					mu.Unlock()
+				}
+				return chequebook.MulticallABI.Methods["aggregate3"].Outputs.Pack(results)
+			}),
+		),
+		factoryAddress,
+		[]common.Address{legacyFactory},
+	)
+	factory.(chequebook.MulticallFactory).SetMulticall(multicall)
+
+	errs := factory.(chequebook.ChequebookBatchVerifier).VerifyChequebooks(context.Background(), []common.Address{valid, notDeployed, wrongBytecode})
+	if len(errs) != 3 {
+		t.Fatalf("got %d errors, want %d", len(errs), 3)
+	}
+	if errs[0] != nil {
+		t.Fatalf("got error %v, want none", errs[0])
+	}
+	if !errors.Is(errs[1], chequebook.ErrNotDeployedByFactory) {
+		t.Fatalf("got error %v, want %v", errs[1], chequebook.ErrNotDeployedByFactory)
+	}
+	if !errors.Is(errs[2], chequebook.ErrUnknownChequebookBytecode) {
+		t.Fatalf("got error %v, want %v", errs[2], chequebook.ErrUnknownChequebookBytecode)
+	}
+
+	// only the chequebook not deployed by the current factory is checked against the legacy one
+	if multicalls[factoryAddress] != 3 || multicalls[legacyFactory] != 1 {
+		t.Fatalf("got bundled calls %v, want 3 to the factory and 1 to the legacy factory", multicalls)
+	}
+}
+
+func TestCachingFactoryVerifyChequebooks(t *testing.T) {
+	t.Parallel()
+
+	trusted := common.HexToAddress("0x01")
+	untrusted := common.HexToAddress("0x02")
+	other := common.HexToAddress("0x03")
+
+	var batches [][]common.Address
+	factory := chequebook.NewCachingFactory(&batchFactoryMock{
+		verifyChequebooks: func(ctx context.Context, chequebooks []common.Address) []error {
+			batches = append(batches, chequebooks)
+			errs := make([]error, len(chequebooks))
+			for i, c := range chequebooks {
+				if c == untrusted {
+					errs[i] = chequebook.ErrNotDeployedByFactory
+				}
+			}
+			return errs
+		},
+	}, storemock.NewStateStore(), time.Hour, time.Hour)
+
+	verifier := factory.(chequebook.ChequebookBatchVerifier)
+
+	errs := verifier.VerifyChequebooks(context.Background(), []common.Address{trusted, untrusted})
+	if errs[0] != nil || !errors.Is(errs[1], chequebook.ErrNotDeployedByFactory) {
+		t.Fatalf("got errors %v", errs)
+	}
+
+	errs = verifier.VerifyChequebooks(context.Background(), []common.Address{trusted, untrusted, other})
+	if errs[0] != nil || !errors.Is(errs[1], chequebook.ErrNotDeployedByFactory) || errs[2] != nil {
+		t.Fatalf("got errors %v", errs)
+	}
+
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0] != other {
+		t.Fatalf("got batches %v, want cached chequebooks not verified again", batches)
+	}
+}
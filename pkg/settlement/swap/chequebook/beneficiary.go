@@ -0,0 +1,140 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// beneficiaryRotationKey is the key under which the rotation of the beneficiary of received cheques is stored.
+const beneficiaryRotationKey = "swap_beneficiary_rotation"
+
+var (
+	// ErrBeneficiaryUnchanged is the error returned if the beneficiary is rotated to the current one.
+	ErrBeneficiaryUnchanged = errors.New("beneficiary unchanged")
+	// ErrInvalidBeneficiary is the error returned if the beneficiary is rotated to the zero address.
+	ErrInvalidBeneficiary = errors.New("invalid beneficiary")
+)
+
+// PreviousBeneficiary is a beneficiary we rotated away from whose cheques are
+// still accepted during a grace period.
+type PreviousBeneficiary struct {
+	Beneficiary common.Address `json:"beneficiary"`
+	AcceptUntil time.Time      `json:"acceptUntil"`
+}
+
+// BeneficiaryRotation is the beneficiary expected in received cheques and the
+// previous ones still accepted.
+type BeneficiaryRotation struct {
+	Current  common.Address        `json:"current"`
+	Previous []PreviousBeneficiary `json:"previous"`
+	Rotated  time.Time             `json:"rotated"` // time of the last rotation, zero if the beneficiary was never rotated
+}
+
+// accepts returns whether cheques to beneficiary are accepted at time now.
+func (r *BeneficiaryRotation) accepts(beneficiary common.Address, now time.Time) bool {
+	if beneficiary == r.Current {
+		return true
+	}
+	for _, p := range r.Previous {
+		if p.Beneficiary == beneficiary && now.Before(p.AcceptUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the previous beneficiaries whose grace period ended.
+func (r *BeneficiaryRotation) prune(now time.Time) {
+	previous := r.Previous[:0]
+	for _, p := range r.Previous {
+		if now.Before(p.AcceptUntil) {
+			previous = append(previous, p)
+		}
+	}
+	r.Previous = previous
+}
+
+// Beneficiaries returns the beneficiary expected in received cheques and the
+// previous ones whose grace period has not ended yet.
+func (s *chequeStore) Beneficiaries() (*BeneficiaryRotation, error) {
+	rotation, err := s.beneficiaryRotation()
+	if err != nil {
+		return nil, err
+	}
+	rotation.prune(s.clock.Now())
+	return rotation, nil
+}
+
+// RotateBeneficiary makes beneficiary the one expected in received cheques.
+// Cheques to the current beneficiary are accepted until the grace period ends.
+func (s *chequeStore) RotateBeneficiary(beneficiary common.Address, grace time.Duration) (*BeneficiaryRotation, error) {
+	if beneficiary == (common.Address{}) {
+		return nil, ErrInvalidBeneficiary
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rotation, err := s.beneficiaryRotation()
+	if err != nil {
+		return nil, err
+	}
+	if rotation.Current == beneficiary {
+		return nil, ErrBeneficiaryUnchanged
+	}
+
+	now := s.clock.Now()
+	rotation.prune(now)
+
+	previous := rotation.Previous[:0]
+	for _, p := range rotation.Previous {
+		// rotating back to a previous beneficiary ends its grace period
+		if p.Beneficiary != beneficiary && p.Beneficiary != rotation.Current {
+			previous = append(previous, p)
+		}
+	}
+	if grace > 0 {
+		previous = append(previous, PreviousBeneficiary{
+			Beneficiary: rotation.Current,
+			AcceptUntil: now.Add(grace),
+		})
+	}
+	rotation.Previous = previous
+	rotation.Current = beneficiary
+	rotation.Rotated = now
+
+	if err := s.store.Put(beneficiaryRotationKey, rotation); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+// acceptsBeneficiary returns whether cheques to beneficiary are accepted.
+func (s *chequeStore) acceptsBeneficiary(beneficiary common.Address) (bool, error) {
+	rotation, err := s.beneficiaryRotation()
+	if err != nil {
+		return false, err
+	}
+	return rotation.accepts(beneficiary, s.clock.Now()), nil
+}
+
+// beneficiaryRotation loads the stored rotation. The beneficiary the cheque
+// store was created with is the current one until it is rotated.
+func (s *chequeStore) beneficiaryRotation() (*BeneficiaryRotation, error) {
+	var rotation BeneficiaryRotation
+	err := s.store.Get(beneficiaryRotationKey, &rotation)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &BeneficiaryRotation{Current: s.beneficiary}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
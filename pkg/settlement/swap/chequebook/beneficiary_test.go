@@ -0,0 +1,251 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestRotateBeneficiary(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	newBeneficiary := common.HexToAddress("0xfffe")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	chainID := int64(1)
+	grace := time.Hour
+	clock := clockmock.New(time.Unix(1000, 0))
+
+	chequestore := chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		chainID,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", newBeneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+	chequebook.SetChequeStoreClock(chequestore, clock)
+
+	cheque := func(beneficiary common.Address, cumulativePayout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(cumulativePayout),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	rotation, err := chequestore.Beneficiaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Current != beneficiary || len(rotation.Previous) != 0 || !rotation.Rotated.IsZero() {
+		t.Fatalf("unexpected initial rotation %+v", rotation)
+	}
+
+	if _, err := chequestore.RotateBeneficiary(beneficiary, grace); !errors.Is(err, chequebook.ErrBeneficiaryUnchanged) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrBeneficiaryUnchanged, err)
+	}
+	if _, err := chequestore.RotateBeneficiary(common.Address{}, grace); !errors.Is(err, chequebook.ErrInvalidBeneficiary) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrInvalidBeneficiary, err)
+	}
+
+	rotation, err = chequestore.RotateBeneficiary(newBeneficiary, grace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Current != newBeneficiary || !rotation.Rotated.Equal(clock.Now()) {
+		t.Fatalf("unexpected rotation %+v", rotation)
+	}
+	if len(rotation.Previous) != 1 || rotation.Previous[0].Beneficiary != beneficiary || !rotation.Previous[0].AcceptUntil.Equal(clock.Now().Add(grace)) {
+		t.Fatalf("unexpected previous beneficiaries %+v", rotation.Previous)
+	}
+
+	// cheques to the previous beneficiary are accepted during the grace period
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque(beneficiary, 10), big.NewInt(1), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque(newBeneficiary, 20), big.NewInt(1), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(grace)
+
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque(beneficiary, 30), big.NewInt(1), big.NewInt(0)); !errors.Is(err, chequebook.ErrWrongBeneficiary) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrWrongBeneficiary, err)
+	}
+
+	rotation, err = chequestore.Beneficiaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Current != newBeneficiary || len(rotation.Previous) != 0 {
+		t.Fatalf("unexpected rotation after grace period %+v", rotation)
+	}
+
+	// the rotation is persisted
+	restarted := chequebook.NewChequeStore(store, &factoryMock{}, chainID, beneficiary, transactionmock.New(), nil)
+	rotation, err = restarted.Beneficiaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Current != newBeneficiary {
+		t.Fatalf("got beneficiary %x after restart, want %x", rotation.Current, newBeneficiary)
+	}
+}
+
+func TestRotateBeneficiaryReceiveCheques(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	newBeneficiary := common.HexToAddress("0xfffe")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	chainID := int64(1)
+
+	chequestore := chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		chainID,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", newBeneficiary),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	cheque := func(beneficiary common.Address, cumulativePayout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(cumulativePayout),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	receive := func(cheque *chequebook.SignedCheque, want int64) {
+		t.Helper()
+		amount, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(1), big.NewInt(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if amount.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("credited %d for cheque to %x, want %d", amount, cheque.Beneficiary, want)
+		}
+	}
+
+	receive(cheque(beneficiary, 100), 100)
+
+	if _, err := chequestore.RotateBeneficiary(newBeneficiary, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// the issuer counts the cumulative payout per beneficiary, so it restarts
+	// for the new one and is credited in full
+	receive(cheque(newBeneficiary, 30), 30)
+	// cheques to the previous beneficiary are credited against its own last cheque
+	receive(cheque(beneficiary, 120), 20)
+
+	cheques, err := chequestore.LastBeneficiaryCheques(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 2 || !cheques[beneficiary].Equal(cheque(beneficiary, 120)) || !cheques[newBeneficiary].Equal(cheque(newBeneficiary, 30)) {
+		t.Fatalf("unexpected cheques %v", cheques)
+	}
+
+	// the cheque for the current beneficiary is the last one
+	lastCheque, err := chequestore.LastCheque(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastCheque.Equal(cheque(newBeneficiary, 30)) {
+		t.Fatalf("got last cheque %v, want %v", lastCheque, cheque(newBeneficiary, 30))
+	}
+	lastCheques, err := chequestore.LastCheques()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lastCheques) != 1 || !lastCheques[chequebookAddress].Equal(cheque(newBeneficiary, 30)) {
+		t.Fatalf("unexpected last cheques %v", lastCheques)
+	}
+}
+
+func TestReceivedChequesMigration(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(10),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	// stored before the cheques were kept per beneficiary
+	if err := store.Put(chequebook.LegacyLastReceivedChequeKey(chequebookAddress), cheque); err != nil {
+		t.Fatal(err)
+	}
+
+	chequestore := chequebook.NewChequeStore(store, &factoryMock{}, 1, beneficiary, transactionmock.New(), nil)
+	cheques, err := chequestore.LastBeneficiaryCheques(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 1 || !cheques[beneficiary].Equal(cheque) {
+		t.Fatalf("unexpected cheques %v", cheques)
+	}
+	if err := store.Get(chequebook.LegacyLastReceivedChequeKey(chequebookAddress), new(chequebook.SignedCheque)); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("legacy cheque not removed: %v", err)
+	}
+}
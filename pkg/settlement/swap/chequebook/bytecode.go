@@ -0,0 +1,114 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// ErrUnknownChequebookBytecode is the error returned if the code of a chequebook does not match any known version.
+var ErrUnknownChequebookBytecode = errors.New("chequebook bytecode does not match a known version")
+
+var (
+	// chequebooks deployed by v0.3.1 factories are full contracts. Their runtime
+	// bytecode is embedded in the factory bytecode and does not contain immutables,
+	// so all of them share the same code hash.
+	chequebookCodeHashv0_3_1 = common.HexToHash("0x925fa7384049febb1eddca32821f1f1d709687628c1cf77ef40ca5013d04bdef")
+
+	// chequebooks deployed by v0.4.0 factories are minimal proxies (EIP-1167)
+	// delegating to the master copy of the factory.
+	minimalProxyCodePrefix = common.FromHex("363d3d373d3d3d363d73")
+	minimalProxyCodeSuffix = common.FromHex("5af43d82803e903d91602b57fd5bf3")
+)
+
+// minimalProxyCode returns the runtime bytecode of a minimal proxy delegating to master.
+func minimalProxyCode(master common.Address) []byte {
+	code := make([]byte, 0, len(minimalProxyCodePrefix)+common.AddressLength+len(minimalProxyCodeSuffix))
+	code = append(code, minimalProxyCodePrefix...)
+	code = append(code, master.Bytes()...)
+	return append(code, minimalProxyCodeSuffix...)
+}
+
+// master returns the address of the master copy used by a v0.4.0 factory.
+func (c *factory) master(ctx context.Context, factory common.Address) (common.Address, error) {
+	callData, err := factoryABI.Pack("master")
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &factory,
+		Data: callData,
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	results, err := factoryABI.Unpack("master", output)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if len(results) != 1 {
+		return common.Address{}, errDecodeABI
+	}
+
+	master, ok := abi.ConvertType(results[0], new(common.Address)).(*common.Address)
+	if !ok || master == nil {
+		return common.Address{}, errDecodeABI
+	}
+	return *master, nil
+}
+
+// verifyChequebookBytecode checks that the code of the chequebook is the code
+// which the given factory deploys. This protects against contracts which only
+// pretend to be chequebooks.
+func (c *factory) verifyChequebookBytecode(ctx context.Context, factory, chequebook common.Address) error {
+	_, err := c.chequebookVersion(ctx, factory, chequebook)
+	return err
+}
+
+// chequebookVersion returns the contract version of the chequebook if its code
+// is the code which the given factory deploys.
+func (c *factory) chequebookVersion(ctx context.Context, factory, chequebook common.Address) (string, error) {
+	factoryCode, err := c.backend.CodeAt(ctx, factory, nil)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := c.backend.CodeAt(ctx, chequebook, nil)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case bytes.Equal(factoryCode, currentDeployVersion):
+		master, err := c.master(ctx, factory)
+		if err != nil {
+			return "", err
+		}
+		if bytes.Equal(code, minimalProxyCode(master)) {
+			return ContractVersionv0_4_0, nil
+		}
+	case bytes.Equal(factoryCode, legacyDeployVersion):
+		codeHash, err := crypto.LegacyKeccak256(code)
+		if err != nil {
+			return "", err
+		}
+		if common.BytesToHash(codeHash) == chequebookCodeHashv0_3_1 {
+			return ContractVersionv0_3_1, nil
+		}
+	}
+
+	return "", fmt.Errorf("chequebook %x: %w", chequebook, ErrUnknownChequebookBytecode)
+}
@@ -5,6 +5,7 @@
 package chequebook
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
 )
@@ -22,14 +24,42 @@ import (
 var (
 	// ErrNoCashout is the error if there has not been any cashout action for the chequebook
 	ErrNoCashout = errors.New("no prior cashout")
+	// ErrChequeCashed is the error if the last cheque of the chequebook has already been cashed completely
+	ErrChequeCashed = errors.New("cheque already cashed")
 )
 
 // CashoutService is the service responsible for managing cashout actions
 type CashoutService interface {
 	// CashCheque sends a cashing transaction for the last cheque of the chequebook
 	CashCheque(ctx context.Context, chequebook common.Address, recipient common.Address) (common.Hash, error)
+	// CashChequeBatch sends cashing transactions for the last cheques of several chequebooks, bundled into one transaction if possible
+	CashChequeBatch(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]BatchCashoutResult, error)
+	// CashoutBatch sends cashing transactions for the last cheques of several chequebooks to their recipients, bundled into as few transactions as possible
+	CashoutBatch(ctx context.Context, batch []BeneficiaryCheques) ([]BatchCashoutResult, error)
 	// CashoutStatus gets the status of the latest cashout transaction for the chequebook
 	CashoutStatus(ctx context.Context, chequebookAddress common.Address) (*CashoutStatus, error)
+	// ChequeCashouts returns all cashout transactions sent for cheques of the chequebook
+	ChequeCashouts(chequebook common.Address) ([]ChequeCashout, error)
+	// TransactionCheques returns the cheques cashed by the cashout transaction
+	TransactionCheques(txHash common.Hash) ([]SignedCheque, error)
+	// ReconcileCashouts finds received cheques which were never cashed or cashed more than once
+	ReconcileCashouts(ctx context.Context) (*CashoutReconciliation, error)
+	// CashoutAttempts returns the recorded attempts to cash the cheques of the chequebook
+	CashoutAttempts(ctx context.Context, chequebook common.Address) ([]CashoutAttempt, error)
+	// RetryCashout cashes the last cheque of the chequebook again if the last attempt failed
+	RetryCashout(ctx context.Context, chequebook, recipient common.Address) (common.Hash, error)
+	// AssignCheque authorizes the cashier to cash the last cheque of the chequebook to the recipient
+	AssignCheque(ctx context.Context, chequebook, cashier, recipient common.Address, callerPayout *big.Int) (*ChequeAssignment, error)
+	// ChequeAssignment returns the last cheque assignment of the chequebook and whether it was cashed
+	ChequeAssignment(ctx context.Context, chequebook common.Address) (*ChequeAssignmentStatus, error)
+	// RemoveChequeAssignment forgets the cheque assignment of the chequebook
+	RemoveChequeAssignment(chequebook common.Address) error
+}
+
+// accountTransactionService is implemented by transaction services which send
+// transactions from a smart contract account instead of the node wallet.
+type accountTransactionService interface {
+	Account() common.Address
 }
 
 type cashoutService struct {
@@ -37,6 +67,9 @@ type cashoutService struct {
 	backend            transaction.Backend
 	transactionService transaction.Service
 	chequeStore        ChequeStore
+	cashoutSigner      CashoutSigner
+	multicall          common.Address
+	clock              clock.Clock
 }
 
 // LastCashout contains information about the last cashout
@@ -78,24 +111,34 @@ type chequeCashedEvent struct {
 	CallerPayout     *big.Int
 }
 
-// NewCashoutService creates a new CashoutService
+// NewCashoutService creates a new CashoutService. If multicall is not the zero
+// address, batch cashouts are bundled through the Multicall3 contract at that
+// address and authorized with signatures of the cashoutSigner.
 func NewCashoutService(
 	store storage.StateStorer,
 	backend transaction.Backend,
 	transactionService transaction.Service,
 	chequeStore ChequeStore,
+	cashoutSigner CashoutSigner,
+	multicall common.Address,
 ) CashoutService {
 	return &cashoutService{
 		store:              store,
 		backend:            backend,
 		transactionService: transactionService,
 		chequeStore:        chequeStore,
+		cashoutSigner:      cashoutSigner,
+		multicall:          multicall,
+		clock:              clock.System,
 	}
 }
 
+// prefix for the persistence key of the last cashout action for a chequebook
+const cashoutActionKeyPrefix = "swap_cashout_"
+
 // cashoutActionKey computes the store key for the last cashout action for the chequebook
 func cashoutActionKey(chequebook common.Address) string {
-	return fmt.Sprintf("swap_cashout_%x", chequebook)
+	return fmt.Sprintf("%s%x", cashoutActionKeyPrefix, chequebook)
 }
 
 func (s *cashoutService) paidOut(ctx context.Context, chequebook, beneficiary common.Address) (*big.Int, error) {
@@ -136,7 +179,46 @@ func (s *cashoutService) CashCheque(ctx context.Context, chequebook, recipient c
 		return common.Hash{}, err
 	}
 
-	callData, err := chequebookABI.Pack("cashChequeBeneficiary", recipient, cheque.CumulativePayout, cheque.Signature)
+	return s.cashCheque(ctx, cheque, recipient, cashoutGasLimit)
+}
+
+// CashoutCallDataSample returns the call data of a cashout of a cheque with
+// the largest possible payout and a full signature. Fees depending on the size
+// of the transaction, like L1 data fees on rollups, are estimated with it.
+func CashoutCallDataSample() ([]byte, error) {
+	recipient := common.BytesToAddress(bytes.Repeat([]byte{0xff}, common.AddressLength))
+	payout := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	signature := bytes.Repeat([]byte{0xff}, 65)
+	return chequebookABI.Pack("cashChequeBeneficiary", recipient, payout, signature)
+}
+
+// cashCheque sends a cashout transaction for the cheque and records it as the last cashout action
+func (s *cashoutService) cashCheque(ctx context.Context, cheque *SignedCheque, recipient common.Address, defaultGasLimit uint64) (common.Hash, error) {
+	chequebook := cheque.Chequebook
+	if err := s.pendingAssignment(ctx, chequebook); err != nil {
+		return common.Hash{}, err
+	}
+
+	// a cheque which was cashed already would only revert and waste gas
+	paidOut, err := s.paidOut(ctx, chequebook, cheque.Beneficiary)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if cheque.CumulativePayout.Cmp(paidOut) <= 0 {
+		return common.Hash{}, fmt.Errorf("paid out %d: %w", paidOut, ErrChequeCashed)
+	}
+
+	var callData []byte
+	if a, ok := s.transactionService.(accountTransactionService); ok {
+		// only the beneficiary may call cashChequeBeneficiary, an account
+		// sending on its behalf needs a cashout authorization
+		if s.cashoutSigner == nil {
+			return common.Hash{}, ErrNoCashoutSigner
+		}
+		callData, err = s.cashChequeCallData(cheque, a.Account(), recipient)
+	} else {
+		callData, err = chequebookABI.Pack("cashChequeBeneficiary", recipient, cheque.CumulativePayout, cheque.Signature)
+	}
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -144,12 +226,15 @@ func (s *cashoutService) CashCheque(ctx context.Context, chequebook, recipient c
 		To:          &chequebook,
 		Data:        callData,
 		GasPrice:    sctx.GetGasPrice(ctx),
-		GasLimit:    sctx.GetGasLimitWithDefault(ctx, 300_000),
+		GasLimit:    sctx.GetGasLimitWithDefault(ctx, defaultGasLimit),
 		Value:       big.NewInt(0),
 		Description: "cheque cashout",
 	}
 
 	txHash, err := s.transactionService.Send(ctx, request, transaction.DefaultTipBoostPercent)
+	if recordErr := s.recordAttempt(cheque, txHash, err); recordErr != nil {
+		return common.Hash{}, errors.Join(err, recordErr)
+	}
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -162,89 +247,123 @@ func (s *cashoutService) CashCheque(ctx context.Context, chequebook, recipient c
 		return common.Hash{}, err
 	}
 
+	err = s.recordCashout(txHash, cheque)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
 	return txHash, nil
 }
 
-// CashoutStatus gets the status of the latest cashout transaction for the chequebook
+// CashoutStatus gets the status of the latest cashout transaction for the
+// chequebook. The uncashed amount sums up the last cheques for all our
+// beneficiaries. For the beneficiaries other than the one of the latest
+// cashout it is computed from the amount paid out on chain.
 func (s *cashoutService) CashoutStatus(ctx context.Context, chequebookAddress common.Address) (*CashoutStatus, error) {
-	cheque, err := s.chequeStore.LastCheque(chequebookAddress)
+	cheques, err := s.chequeStore.LastBeneficiaryCheques(chequebookAddress)
 	if err != nil {
 		return nil, err
 	}
+	if len(cheques) == 0 {
+		return nil, ErrNoCheque
+	}
 
 	var action cashoutAction
 	err = s.store.Get(cashoutActionKey(chequebookAddress), &action)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
+			// if we never cashed out, assume everything is uncashed
+			uncashed := new(big.Int)
+			for _, cheque := range cheques {
+				uncashed.Add(uncashed, cheque.CumulativePayout)
+			}
 			return &CashoutStatus{
 				Last:           nil,
-				UncashedAmount: cheque.CumulativePayout, // if we never cashed out, assume everything is uncashed
+				UncashedAmount: uncashed,
 			}, nil
 		}
 		return nil, err
 	}
 
+	last, cashed, err := s.lastCashout(ctx, chequebookAddress, &action)
+	if err != nil {
+		return nil, err
+	}
+
+	uncashed := new(big.Int)
+	for beneficiary, cheque := range cheques {
+		if beneficiary != action.Cheque.Beneficiary || cashed == nil {
+			paidOut, err := s.paidOut(ctx, chequebookAddress, beneficiary)
+			if err != nil {
+				return nil, err
+			}
+			uncashed.Add(uncashed, new(big.Int).Sub(cheque.CumulativePayout, paidOut))
+			continue
+		}
+		uncashed.Add(uncashed, new(big.Int).Sub(cheque.CumulativePayout, cashed))
+	}
+
+	return &CashoutStatus{
+		Last:           last,
+		UncashedAmount: uncashed,
+	}, nil
+}
+
+// lastCashout returns the state of the cashout action and the cumulative
+// payout cashed for its beneficiary. The cashed payout is nil if it is only
+// known on chain.
+func (s *cashoutService) lastCashout(ctx context.Context, chequebookAddress common.Address, action *cashoutAction) (*LastCashout, *big.Int, error) {
 	_, pending, err := s.backend.TransactionByHash(ctx, action.TxHash)
 	if err != nil {
 		// treat not found as pending
 		if !errors.Is(err, ethereum.NotFound) {
-			return nil, err
+			return nil, nil, err
 		}
 		pending = true
 	}
 
 	if pending {
-		return &CashoutStatus{
-			Last: &LastCashout{
-				TxHash:   action.TxHash,
-				Cheque:   action.Cheque,
-				Result:   nil,
-				Reverted: false,
-			},
-			// uncashed is the difference since the last sent cashout. we assume that the entire cheque will clear in the pending transaction.
-			UncashedAmount: new(big.Int).Sub(cheque.CumulativePayout, action.Cheque.CumulativePayout),
-		}, nil
+		// we assume that the entire cheque will clear in the pending transaction.
+		return &LastCashout{
+			TxHash:   action.TxHash,
+			Cheque:   action.Cheque,
+			Result:   nil,
+			Reverted: false,
+		}, action.Cheque.CumulativePayout, nil
 	}
 
 	receipt, err := s.backend.TransactionReceipt(ctx, action.TxHash)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if receipt.Status == types.ReceiptStatusFailed {
-		// if a tx failed (should be almost impossible in practice) we no longer have the necessary information to compute uncashed locally
-		// assume there are no pending transactions and that the on-chain paidOut is the last cashout action
-		paidOut, err := s.paidOut(ctx, chequebookAddress, cheque.Beneficiary)
-		if err != nil {
-			return nil, err
+	var result *CashChequeResult
+	if receipt.Status != types.ReceiptStatusFailed {
+		result, err = s.parseCashChequeBeneficiaryReceipt(chequebookAddress, receipt)
+		// a batch cashout succeeds even if some of its cashouts failed
+		if err != nil && !errors.Is(err, transaction.ErrEventNotFound) {
+			return nil, nil, err
 		}
-
-		return &CashoutStatus{
-			Last: &LastCashout{
-				TxHash:   action.TxHash,
-				Cheque:   action.Cheque,
-				Result:   nil,
-				Reverted: true,
-			},
-			UncashedAmount: new(big.Int).Sub(cheque.CumulativePayout, paidOut),
-		}, nil
 	}
 
-	result, err := s.parseCashChequeBeneficiaryReceipt(chequebookAddress, receipt)
-	if err != nil {
-		return nil, err
-	}
-
-	return &CashoutStatus{
-		Last: &LastCashout{
+	if result == nil {
+		// if a tx failed (should be almost impossible in practice) we no longer have the necessary information to compute uncashed locally
+		// assume there are no pending transactions and that the on-chain paidOut is the last cashout action
+		return &LastCashout{
 			TxHash:   action.TxHash,
 			Cheque:   action.Cheque,
-			Result:   result,
-			Reverted: false,
-		},
-		// uncashed is the difference since the last sent (and confirmed) cashout.
-		UncashedAmount: new(big.Int).Sub(cheque.CumulativePayout, result.CumulativePayout),
-	}, nil
+			Result:   nil,
+			Reverted: true,
+		}, nil, nil
+	}
+
+	// uncashed is the difference since the last sent (and confirmed) cashout.
+	return &LastCashout{
+		TxHash:   action.TxHash,
+		Cheque:   action.Cheque,
+		Result:   result,
+		Reverted: false,
+	}, result.CumulativePayout, nil
 }
 
 // parseCashChequeBeneficiaryReceipt processes the receipt from a CashChequeBeneficiary transaction
@@ -6,6 +6,7 @@ package chequebook_test
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
 	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/ethersphere/bee/pkg/transaction/backendmock"
 	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
 	"github.com/ethersphere/bee/pkg/util/abiutil"
@@ -77,6 +79,7 @@ func TestCashout(t *testing.T) {
 			}),
 		),
 		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
 			transactionmock.WithABISend(&chequebookABI, txHash, chequebookAddress, big.NewInt(0), "cashChequeBeneficiary", recipientAddress, cheque.CumulativePayout, cheque.Signature),
 		),
 		chequestoremock.NewChequeStore(
@@ -87,6 +90,8 @@ func TestCashout(t *testing.T) {
 				return cheque, nil
 			}),
 		),
+		nil,
+		common.Address{},
 	)
 
 	returnedTxHash, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipientAddress)
@@ -122,6 +127,52 @@ func TestCashout(t *testing.T) {
 	})
 }
 
+func TestCashoutAlreadyCashed(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("abcd")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("aaaa"),
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithABICall(&chequebookABI, chequebookAddress, big.NewInt(500).FillBytes(make([]byte, 32)), "paidOut", cheque.Beneficiary),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				t.Fatal("sent cashout of a cashed cheque")
+				return common.Hash{}, nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	_, err := cashoutService.CashCheque(context.Background(), chequebookAddress, common.HexToAddress("efff"))
+	if !errors.Is(err, chequebook.ErrChequeCashed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeCashed)
+	}
+
+	attempts, err := cashoutService.CashoutAttempts(context.Background(), chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 0 {
+		t.Fatalf("got %d cashout attempts, want none", len(attempts))
+	}
+}
+
 func TestCashoutBounced(t *testing.T) {
 	t.Parallel()
 
@@ -177,6 +228,7 @@ func TestCashoutBounced(t *testing.T) {
 			}),
 		),
 		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
 			transactionmock.WithABISend(&chequebookABI, txHash, chequebookAddress, big.NewInt(0), "cashChequeBeneficiary", recipientAddress, cheque.CumulativePayout, cheque.Signature),
 		),
 		chequestoremock.NewChequeStore(
@@ -187,6 +239,8 @@ func TestCashoutBounced(t *testing.T) {
 				return cheque, nil
 			}),
 		),
+		nil,
+		common.Address{},
 	)
 
 	returnedTxHash, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipientAddress)
@@ -225,6 +279,21 @@ func TestCashoutBounced(t *testing.T) {
 func TestCashoutStatusReverted(t *testing.T) {
 	t.Parallel()
 
+	t.Run("reverted", func(t *testing.T) {
+		t.Parallel()
+		testCashoutStatusReverted(t, types.ReceiptStatusFailed)
+	})
+
+	// a cashout which failed within a successful batch cashout does not emit any event
+	t.Run("failed in batch", func(t *testing.T) {
+		t.Parallel()
+		testCashoutStatusReverted(t, types.ReceiptStatusSuccessful)
+	})
+}
+
+func testCashoutStatusReverted(t *testing.T, receiptStatus uint64) {
+	t.Helper()
+
 	chequebookAddress := common.HexToAddress("abcd")
 	recipientAddress := common.HexToAddress("efff")
 	txHash := common.HexToHash("dddd")
@@ -256,13 +325,16 @@ func TestCashoutStatusReverted(t *testing.T) {
 					t.Fatalf("fetching receipt for transaction. wanted %v, got %v", txHash, hash)
 				}
 				return &types.Receipt{
-					Status: types.ReceiptStatusFailed,
+					Status: receiptStatus,
 				}, nil
 			}),
 		),
 		transactionmock.New(
 			transactionmock.WithABISend(&chequebookABI, txHash, chequebookAddress, big.NewInt(0), "cashChequeBeneficiary", recipientAddress, cheque.CumulativePayout, cheque.Signature),
-			transactionmock.WithABICall(&chequebookABI, chequebookAddress, onChainPaidOut.FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, onChainPaidOut.FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
 		),
 		chequestoremock.NewChequeStore(
 			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
@@ -272,6 +344,8 @@ func TestCashoutStatusReverted(t *testing.T) {
 				return cheque, nil
 			}),
 		),
+		nil,
+		common.Address{},
 	)
 
 	returnedTxHash, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipientAddress)
@@ -327,6 +401,7 @@ func TestCashoutStatusPending(t *testing.T) {
 			}),
 		),
 		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
 			transactionmock.WithABISend(&chequebookABI, txHash, chequebookAddress, big.NewInt(0), "cashChequeBeneficiary", recipientAddress, cheque.CumulativePayout, cheque.Signature),
 		),
 		chequestoremock.NewChequeStore(
@@ -337,6 +412,8 @@ func TestCashoutStatusPending(t *testing.T) {
 				return cheque, nil
 			}),
 		),
+		nil,
+		common.Address{},
 	)
 
 	returnedTxHash, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipientAddress)
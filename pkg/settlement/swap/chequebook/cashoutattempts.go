@@ -0,0 +1,192 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// prefix for the persistence key of the cashout attempts of a chequebook
+	cashoutAttemptKeyPrefix = "swap_cashout_attempt_"
+	// maxCashoutAttempts is the number of cashout attempts kept per chequebook.
+	maxCashoutAttempts = 32
+)
+
+// DroppedCashoutTimeout is the time after which a sent cashout transaction
+// unknown to the backend is considered dropped.
+const DroppedCashoutTimeout = 10 * time.Minute
+
+// ErrCashoutNotRetryable is the error returned if a cashout is retried while
+// the last attempt is pending or succeeded.
+var ErrCashoutNotRetryable = errors.New("last cashout attempt did not fail")
+
+// CashoutAttemptStatus is the state of a cashout attempt.
+type CashoutAttemptStatus string
+
+const (
+	CashoutAttemptPending   CashoutAttemptStatus = "pending"   // sent and not mined yet
+	CashoutAttemptConfirmed CashoutAttemptStatus = "confirmed" // mined and cashed the cheque
+	CashoutAttemptFailed    CashoutAttemptStatus = "failed"    // the transaction could not be sent
+	CashoutAttemptReverted  CashoutAttemptStatus = "reverted"  // mined without cashing the cheque
+	CashoutAttemptDropped   CashoutAttemptStatus = "dropped"   // sent but never mined
+)
+
+// Retryable reports whether a cashout can be retried after an attempt with
+// the status.
+func (s CashoutAttemptStatus) Retryable() bool {
+	return s == CashoutAttemptFailed || s == CashoutAttemptReverted || s == CashoutAttemptDropped
+}
+
+// CashoutAttempt is a recorded attempt to cash the last cheque of a chequebook.
+type CashoutAttempt struct {
+	TxHash           common.Hash // zero if the transaction could not be sent
+	CumulativePayout *big.Int    // cumulative payout of the cheque
+	Time             int64       // unix timestamp in nanoseconds of the attempt
+	Status           CashoutAttemptStatus
+	Reason           string // why the attempt failed
+}
+
+// cashoutAttemptKey computes the key where to store a cashout attempt of the chequebook.
+func cashoutAttemptKey(chequebook common.Address, t int64) string {
+	return fmt.Sprintf("%s%x_%020d", cashoutAttemptKeyPrefix, chequebook, t)
+}
+
+// recordAttempt records the attempt to cash the cheque with the transaction
+// or the error it could not be sent with. Only the last maxCashoutAttempts
+// are kept.
+func (s *cashoutService) recordAttempt(cheque *SignedCheque, txHash common.Hash, sendErr error) error {
+	attempt := CashoutAttempt{
+		TxHash:           txHash,
+		CumulativePayout: cheque.CumulativePayout,
+		Time:             s.clock.Now().UnixNano(),
+		Status:           CashoutAttemptPending,
+	}
+	if sendErr != nil {
+		attempt.Status = CashoutAttemptFailed
+		attempt.Reason = sendErr.Error()
+	}
+	if err := s.store.Put(cashoutAttemptKey(cheque.Chequebook, attempt.Time), &attempt); err != nil {
+		return err
+	}
+
+	attempts, err := s.storedAttempts(cheque.Chequebook)
+	if err != nil {
+		return err
+	}
+	for len(attempts) > maxCashoutAttempts {
+		if err := s.store.Delete(cashoutAttemptKey(cheque.Chequebook, attempts[0].Time)); err != nil {
+			return err
+		}
+		attempts = attempts[1:]
+	}
+	return nil
+}
+
+// storedAttempts returns the recorded cashout attempts of the chequebook, oldest first.
+func (s *cashoutService) storedAttempts(chequebook common.Address) ([]CashoutAttempt, error) {
+	prefix := fmt.Sprintf("%s%x_", cashoutAttemptKeyPrefix, chequebook)
+	attempts := make([]CashoutAttempt, 0)
+	err := s.store.Iterate(prefix, func(key, value []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), prefix) {
+			return true, nil
+		}
+		var attempt CashoutAttempt
+		if err := json.Unmarshal(value, &attempt); err != nil {
+			return true, fmt.Errorf("decode cashout attempt %s: %w", string(key), err)
+		}
+		attempts = append(attempts, attempt)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].Time < attempts[j].Time
+	})
+	return attempts, nil
+}
+
+// CashoutAttempts returns the recorded attempts to cash the cheques of the
+// chequebook, oldest first. Pending attempts are resolved against the
+// backend and stored with their outcome once it is known.
+func (s *cashoutService) CashoutAttempts(ctx context.Context, chequebook common.Address) ([]CashoutAttempt, error) {
+	attempts, err := s.storedAttempts(chequebook)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, attempt := range attempts {
+		if attempt.Status != CashoutAttemptPending {
+			continue
+		}
+		status, err := s.attemptStatus(ctx, chequebook, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if status == CashoutAttemptPending {
+			continue
+		}
+		attempts[i].Status = status
+		if err := s.store.Put(cashoutAttemptKey(chequebook, attempt.Time), &attempts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return attempts, nil
+}
+
+// attemptStatus checks the outcome of the transaction of a pending attempt.
+func (s *cashoutService) attemptStatus(ctx context.Context, chequebook common.Address, attempt CashoutAttempt) (CashoutAttemptStatus, error) {
+	outcome, err := s.cashoutOutcome(ctx, chequebook, attempt.TxHash)
+	if err != nil {
+		return "", err
+	}
+	switch outcome {
+	case cashoutSucceeded:
+		return CashoutAttemptConfirmed, nil
+	case cashoutFailed:
+		return CashoutAttemptReverted, nil
+	}
+
+	if s.clock.Now().Sub(time.Unix(0, attempt.Time)) < DroppedCashoutTimeout {
+		return CashoutAttemptPending, nil
+	}
+	_, _, err = s.backend.TransactionByHash(ctx, attempt.TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return CashoutAttemptDropped, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return CashoutAttemptPending, nil
+}
+
+// RetryCashout sends a new cashout transaction for the last cheque of the
+// chequebook if the last attempt failed, reverted or was dropped. It returns
+// ErrNoCashout if there was no attempt and ErrCashoutNotRetryable if the last
+// attempt is pending or succeeded.
+func (s *cashoutService) RetryCashout(ctx context.Context, chequebook, recipient common.Address) (common.Hash, error) {
+	attempts, err := s.CashoutAttempts(ctx, chequebook)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(attempts) == 0 {
+		return common.Hash{}, ErrNoCashout
+	}
+	if last := attempts[len(attempts)-1]; !last.Status.Retryable() {
+		return common.Hash{}, fmt.Errorf("%w: last attempt %s", ErrCashoutNotRetryable, last.Status)
+	}
+	return s.CashCheque(ctx, chequebook, recipient)
+}
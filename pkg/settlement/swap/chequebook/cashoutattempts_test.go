@@ -0,0 +1,208 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestCashoutAttempts(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("abcd")
+	recipientAddress := common.HexToAddress("efff")
+	revertedTx := common.HexToHash("dddd")
+	pendingTx := common.HexToHash("eeee")
+	errSend := errors.New("nonce too low")
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("aaaa"),
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+
+	sends := []struct {
+		txHash common.Hash
+		err    error
+	}{
+		{err: errSend},
+		{txHash: revertedTx},
+		{txHash: pendingTx},
+	}
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				if hash == revertedTx {
+					return &types.Receipt{Status: types.ReceiptStatusFailed}, nil
+				}
+				return nil, ethereum.NotFound
+			}),
+		),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				if len(sends) == 0 {
+					t.Fatal("unexpected cashout transaction")
+				}
+				send := sends[0]
+				sends = sends[1:]
+				return send.txHash, send.err
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	ctx := context.Background()
+
+	if _, err := cashoutService.RetryCashout(ctx, chequebookAddress, recipientAddress); !errors.Is(err, chequebook.ErrNoCashout) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCashout)
+	}
+
+	if _, err := cashoutService.CashCheque(ctx, chequebookAddress, recipientAddress); !errors.Is(err, errSend) {
+		t.Fatalf("got error %v, want %v", err, errSend)
+	}
+	txHash, err := cashoutService.RetryCashout(ctx, chequebookAddress, recipientAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txHash != revertedTx {
+		t.Fatalf("got transaction %v, want %v", txHash, revertedTx)
+	}
+	// the reverted transaction is resolved before retrying
+	txHash, err = cashoutService.RetryCashout(ctx, chequebookAddress, recipientAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txHash != pendingTx {
+		t.Fatalf("got transaction %v, want %v", txHash, pendingTx)
+	}
+	if _, err := cashoutService.RetryCashout(ctx, chequebookAddress, recipientAddress); !errors.Is(err, chequebook.ErrCashoutNotRetryable) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrCashoutNotRetryable)
+	}
+
+	attempts, err := cashoutService.CashoutAttempts(ctx, chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		txHash common.Hash
+		status chequebook.CashoutAttemptStatus
+		reason string
+	}{
+		{status: chequebook.CashoutAttemptFailed, reason: errSend.Error()},
+		{txHash: revertedTx, status: chequebook.CashoutAttemptReverted},
+		{txHash: pendingTx, status: chequebook.CashoutAttemptPending},
+	}
+	if len(attempts) != len(want) {
+		t.Fatalf("got %d attempts, want %d", len(attempts), len(want))
+	}
+	for i, w := range want {
+		a := attempts[i]
+		if a.TxHash != w.txHash || a.Status != w.status || a.Reason != w.reason {
+			t.Fatalf("attempt %d: got %+v, want %+v", i, a, w)
+		}
+		if a.CumulativePayout.Cmp(cheque.CumulativePayout) != 0 {
+			t.Fatalf("attempt %d: got cumulative payout %v, want %v", i, a.CumulativePayout, cheque.CumulativePayout)
+		}
+	}
+}
+
+func TestCashoutAttemptDropped(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("abcd")
+	recipientAddress := common.HexToAddress("efff")
+	droppedTx := common.HexToHash("dddd")
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("aaaa"),
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				return nil, ethereum.NotFound
+			}),
+			backendmock.WithTransactionByHashFunc(func(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+				return nil, false, ethereum.NotFound
+			}),
+		),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				return droppedTx, nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+	clock := clockmock.New(time.Unix(1000, 0))
+	chequebook.SetCashoutServiceClock(cashoutService, clock)
+
+	ctx := context.Background()
+
+	if _, err := cashoutService.CashCheque(ctx, chequebookAddress, recipientAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	status := func() chequebook.CashoutAttemptStatus {
+		t.Helper()
+		attempts, err := cashoutService.CashoutAttempts(ctx, chequebookAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(attempts) != 1 {
+			t.Fatalf("got %d attempts, want 1", len(attempts))
+		}
+		return attempts[0].Status
+	}
+
+	// a transaction unknown to the backend is given time to propagate
+	clock.Advance(chequebook.DroppedCashoutTimeout - time.Second)
+	if got := status(); got != chequebook.CashoutAttemptPending {
+		t.Fatalf("got status %s, want %s", got, chequebook.CashoutAttemptPending)
+	}
+
+	clock.Advance(time.Second)
+	if got := status(); got != chequebook.CashoutAttemptDropped {
+		t.Fatalf("got status %s, want %s", got, chequebook.CashoutAttemptDropped)
+	}
+}
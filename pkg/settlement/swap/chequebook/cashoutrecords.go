@@ -0,0 +1,230 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	// prefix for the persistence key of the cheques cashed by a transaction
+	cashoutTransactionKeyPrefix = "swap_cashout_transaction_"
+	// prefix for the persistence key of the cashout transactions of a chequebook
+	chequeCashoutKeyPrefix = "swap_cheque_cashout_"
+)
+
+// ErrUnknownCashoutTransaction is the error returned if a transaction was not sent as a cashout by this node.
+var ErrUnknownCashoutTransaction = errors.New("unknown cashout transaction")
+
+// ChequeCashout links a cashout transaction to the received cheque it cashes.
+type ChequeCashout struct {
+	TxHash common.Hash
+	Cheque SignedCheque
+	Time   int64 // unix timestamp when the transaction was sent
+}
+
+// CashoutReconciliation lists received cheques whose cashouts need attention.
+type CashoutReconciliation struct {
+	NeverCashed []SignedCheque    // last received cheques without a successful or pending cashout transaction
+	CashedTwice [][]ChequeCashout // cheques cashed by more than one successful transaction, one entry per cheque
+}
+
+// cashoutTransaction is the stored record of a cashout transaction.
+type cashoutTransaction struct {
+	Cheques []SignedCheque
+	Time    int64
+}
+
+// cashoutTransactionKey computes the key where to store the cheques cashed by a transaction.
+func cashoutTransactionKey(txHash common.Hash) string {
+	return fmt.Sprintf("%s%x", cashoutTransactionKeyPrefix, txHash)
+}
+
+// chequeCashoutKey computes the key where to store a cashout of a cheque of the chequebook.
+func chequeCashoutKey(chequebook common.Address, txHash common.Hash) string {
+	return fmt.Sprintf("%s%x_%x", chequeCashoutKeyPrefix, chequebook, txHash)
+}
+
+// recordCashout links the transaction to the cheques it cashes in both directions.
+func (s *cashoutService) recordCashout(txHash common.Hash, cheques ...*SignedCheque) error {
+	now := s.clock.Now().Unix()
+	record := cashoutTransaction{Time: now}
+	for _, cheque := range cheques {
+		record.Cheques = append(record.Cheques, *cheque)
+		err := s.store.Put(chequeCashoutKey(cheque.Chequebook, txHash), &ChequeCashout{
+			TxHash: txHash,
+			Cheque: *cheque,
+			Time:   now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.store.Put(cashoutTransactionKey(txHash), &record)
+}
+
+// TransactionCheques returns the cheques cashed by the cashout transaction.
+func (s *cashoutService) TransactionCheques(txHash common.Hash) ([]SignedCheque, error) {
+	var record cashoutTransaction
+	err := s.store.Get(cashoutTransactionKey(txHash), &record)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUnknownCashoutTransaction
+		}
+		return nil, err
+	}
+	return record.Cheques, nil
+}
+
+// ChequeCashouts returns all cashout transactions sent for cheques of the
+// chequebook, oldest first.
+func (s *cashoutService) ChequeCashouts(chequebook common.Address) ([]ChequeCashout, error) {
+	prefix := fmt.Sprintf("%s%x_", chequeCashoutKeyPrefix, chequebook)
+	cashouts := make([]ChequeCashout, 0)
+	err := s.store.Iterate(prefix, func(key, value []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), prefix) {
+			return true, nil
+		}
+		var cashout ChequeCashout
+		if err := json.Unmarshal(value, &cashout); err != nil {
+			return true, fmt.Errorf("decode cashout %s: %w", string(key), err)
+		}
+		cashouts = append(cashouts, cashout)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cashouts, func(i, j int) bool {
+		if cashouts[i].Time != cashouts[j].Time {
+			return cashouts[i].Time < cashouts[j].Time
+		}
+		return cashouts[i].Cheque.CumulativePayout.Cmp(cashouts[j].Cheque.CumulativePayout) < 0
+	})
+	return cashouts, nil
+}
+
+// cashoutOutcome is the on-chain result of a cashout transaction for a chequebook.
+type cashoutOutcome int
+
+const (
+	cashoutPending cashoutOutcome = iota
+	cashoutSucceeded
+	cashoutFailed
+)
+
+// cashoutOutcome checks whether the transaction cashed a cheque of the chequebook.
+func (s *cashoutService) cashoutOutcome(ctx context.Context, chequebook common.Address, txHash common.Hash) (cashoutOutcome, error) {
+	receipt, err := s.backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return cashoutPending, nil
+		}
+		return 0, err
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return cashoutFailed, nil
+	}
+	// a batch cashout succeeds even if some of its cashouts failed
+	if _, err := s.parseCashChequeBeneficiaryReceipt(chequebook, receipt); err != nil {
+		return cashoutFailed, nil
+	}
+	return cashoutSucceeded, nil
+}
+
+// ReconcileCashouts compares the received cheques with the recorded cashout
+// transactions and their receipts. It finds last received cheques which were
+// never cashed and cheques which were cashed by more than one transaction.
+// The cheques of a chequebook are compared per beneficiary.
+func (s *cashoutService) ReconcileCashouts(ctx context.Context) (*CashoutReconciliation, error) {
+	lastCheques, err := s.chequeStore.LastCheques()
+	if err != nil {
+		return nil, err
+	}
+
+	chequebooks := make([]common.Address, 0, len(lastCheques))
+	for chequebook := range lastCheques {
+		chequebooks = append(chequebooks, chequebook)
+	}
+	sort.Slice(chequebooks, func(i, j int) bool {
+		return chequebooks[i].Hex() < chequebooks[j].Hex()
+	})
+
+	result := &CashoutReconciliation{
+		NeverCashed: make([]SignedCheque, 0),
+		CashedTwice: make([][]ChequeCashout, 0),
+	}
+	for _, chequebook := range chequebooks {
+		cheques, err := s.chequeStore.LastBeneficiaryCheques(chequebook)
+		if err != nil {
+			return nil, err
+		}
+		beneficiaries := make([]common.Address, 0, len(cheques))
+		for beneficiary := range cheques {
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
+		sort.Slice(beneficiaries, func(i, j int) bool {
+			return beneficiaries[i].Hex() < beneficiaries[j].Hex()
+		})
+
+		cashouts, err := s.ChequeCashouts(chequebook)
+		if err != nil {
+			return nil, err
+		}
+		outcomes := make([]cashoutOutcome, len(cashouts))
+		for i, cashout := range cashouts {
+			if outcomes[i], err = s.cashoutOutcome(ctx, chequebook, cashout.TxHash); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, beneficiary := range beneficiaries {
+			// successful cashouts per cumulative payout
+			var (
+				succeeded  = make(map[string][]ChequeCashout)
+				payouts    []string
+				lastCashed bool
+			)
+			last := cheques[beneficiary]
+			for i, cashout := range cashouts {
+				if cashout.Cheque.Beneficiary != beneficiary || outcomes[i] == cashoutFailed {
+					continue
+				}
+				if cashout.Cheque.CumulativePayout.Cmp(last.CumulativePayout) == 0 {
+					lastCashed = true
+				}
+				if outcomes[i] != cashoutSucceeded {
+					continue
+				}
+				payout := cashout.Cheque.CumulativePayout.String()
+				if _, ok := succeeded[payout]; !ok {
+					payouts = append(payouts, payout)
+				}
+				succeeded[payout] = append(succeeded[payout], cashout)
+			}
+
+			if !lastCashed {
+				result.NeverCashed = append(result.NeverCashed, *last)
+			}
+			for _, payout := range payouts {
+				if len(succeeded[payout]) > 1 {
+					result.CashedTwice = append(result.CashedTwice, succeeded[payout])
+				}
+			}
+		}
+	}
+	return result, nil
+}
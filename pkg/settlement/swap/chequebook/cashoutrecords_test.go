@@ -0,0 +1,195 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequestoremock "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestCashoutRecords(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("abcd")
+	uncashedChequebook := common.HexToAddress("bcde")
+	recipientAddress := common.HexToAddress("efff")
+	beneficiary := common.HexToAddress("aaaa")
+	txHashes := []common.Hash{common.HexToHash("d1"), common.HexToHash("d2"), common.HexToHash("d3")}
+	revertedTx := txHashes[2]
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+	uncashedCheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(300),
+			Chequebook:       uncashedChequebook,
+		},
+		Signature: []byte{},
+	}
+
+	sent := 0
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				if hash == revertedTx {
+					return &types.Receipt{Status: types.ReceiptStatusFailed}, nil
+				}
+				logData, err := chequeCashedEventType.Inputs.NonIndexed().Pack(big.NewInt(0), cheque.CumulativePayout, big.NewInt(0))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return &types.Receipt{
+					Status: types.ReceiptStatusSuccessful,
+					Logs: []*types.Log{
+						{
+							Address: chequebookAddress,
+							Topics:  []common.Hash{chequeCashedEventType.ID, beneficiary.Hash(), recipientAddress.Hash(), beneficiary.Hash()},
+							Data:    logData,
+						},
+					},
+				}, nil
+			}),
+		),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sent++
+				return txHashes[sent-1], nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+			chequestoremock.WithLastChequesFunc(func() (map[common.Address]*chequebook.SignedCheque, error) {
+				return map[common.Address]*chequebook.SignedCheque{
+					chequebookAddress:  cheque,
+					uncashedChequebook: uncashedCheque,
+				}, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	// the same cheque is cashed three times of which one reverts
+	for range txHashes {
+		if _, err := cashoutService.CashCheque(context.Background(), chequebookAddress, recipientAddress); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cheques, err := cashoutService.TransactionCheques(txHashes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 1 || !cheques[0].Equal(cheque) {
+		t.Fatalf("got cheques %v, want %v", cheques, cheque)
+	}
+	if _, err := cashoutService.TransactionCheques(common.HexToHash("ff")); !errors.Is(err, chequebook.ErrUnknownCashoutTransaction) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrUnknownCashoutTransaction)
+	}
+
+	cashouts, err := cashoutService.ChequeCashouts(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cashouts) != len(txHashes) {
+		t.Fatalf("got %d cashouts, want %d", len(cashouts), len(txHashes))
+	}
+	for _, cashout := range cashouts {
+		if !cashout.Cheque.Equal(cheque) {
+			t.Fatalf("got cheque %v, want %v", cashout.Cheque, cheque)
+		}
+	}
+
+	reconciliation, err := cashoutService.ReconcileCashouts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reconciliation.NeverCashed) != 1 || !reconciliation.NeverCashed[0].Equal(uncashedCheque) {
+		t.Fatalf("got never cashed %v, want %v", reconciliation.NeverCashed, uncashedCheque)
+	}
+	if len(reconciliation.CashedTwice) != 1 || len(reconciliation.CashedTwice[0]) != 2 {
+		t.Fatalf("got cashed twice %v, want one cheque with two transactions", reconciliation.CashedTwice)
+	}
+	for _, cashout := range reconciliation.CashedTwice[0] {
+		if cashout.TxHash == revertedTx {
+			t.Fatal("reverted transaction reported as cashing the cheque")
+		}
+	}
+}
+
+func TestCashoutRecordsPending(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("abcd")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("aaaa"),
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+
+	cashoutService := chequebook.NewCashoutService(
+		storemock.NewStateStore(),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				return nil, ethereum.NotFound
+			}),
+		),
+		transactionmock.New(
+			withPaidOut(big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				return common.HexToHash("d1"), nil
+			}),
+		),
+		chequestoremock.NewChequeStore(
+			chequestoremock.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheque, nil
+			}),
+			chequestoremock.WithLastChequesFunc(func() (map[common.Address]*chequebook.SignedCheque, error) {
+				return map[common.Address]*chequebook.SignedCheque{chequebookAddress: cheque}, nil
+			}),
+		),
+		nil,
+		common.Address{},
+	)
+
+	if _, err := cashoutService.CashCheque(context.Background(), chequebookAddress, common.HexToAddress("efff")); err != nil {
+		t.Fatal(err)
+	}
+
+	// a pending cashout is neither missing nor a duplicate
+	reconciliation, err := cashoutService.ReconcileCashouts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reconciliation.NeverCashed) != 0 || len(reconciliation.CashedTwice) != 0 {
+		t.Fatalf("got reconciliation %+v, want none", reconciliation)
+	}
+}
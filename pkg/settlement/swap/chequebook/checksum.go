@@ -0,0 +1,243 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// ErrCorruptedRecord is the error returned if a stored cheque or counter does
+// not match its checksum.
+var ErrCorruptedRecord = errors.New("corrupted settlement record")
+
+// CorruptionError is returned when reading a stored cheque or counter which
+// does not match its checksum. It matches ErrCorruptedRecord with errors.Is.
+// Corrupted cheques are repaired by importing them again, see
+// ImportLastCheque and ImportCheque; the total issued counter is recomputed
+// from the issued cheques.
+type CorruptionError struct {
+	Key string
+}
+
+func (e *CorruptionError) Unwrap() error {
+	return ErrCorruptedRecord
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrCorruptedRecord, e.Key)
+}
+
+// checksumKeyPrefixes are the prefixes of the keys of the cheques and
+// counters stored with a checksum.
+var checksumKeyPrefixes = []string{
+	lastIssuedChequeKeyPrefix,
+	totalIssuedKey,
+	lastReceivedChequePrefix,
+}
+
+const (
+	// checksumMarker starts the checksum appended to the stored value. It never
+	// occurs in the JSON encoded values stored before checksums were added, so
+	// these are told apart and migrated, see checksumStore.migrate.
+	checksumMarker = 0x00
+	checksumSize   = 1 + crc32.Size
+
+	// issuedChecksumsMigratedKey marks the issued cheques and the total issued
+	// counter of the own chequebook as migrated to checksums.
+	issuedChecksumsMigratedKey = "swap_chequebook_issued_checksums_migrated"
+	// receivedChecksumsMigratedKey marks the received cheques as migrated to
+	// checksums.
+	receivedChecksumsMigratedKey = "swap_chequebook_received_checksums_migrated"
+)
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumStore stores the cheques and counters with a checksum of their key
+// and value, which is verified on every read. Values moved to another key
+// therefore fail the verification, too. Other keys are passed on unchanged.
+//
+// Before its first use the store adds checksums to the values of the migrated
+// prefixes stored before checksums were added, and records this under the
+// migrated key. Values without checksum are rejected as corrupted afterwards.
+type checksumStore struct {
+	storage.StateStorer
+	migratedKey string
+	prefixes    []string
+
+	once       sync.Once
+	migrateErr error
+}
+
+func newChecksumStore(store storage.StateStorer, migratedKey string, prefixes ...string) *checksumStore {
+	return &checksumStore{
+		StateStorer: store,
+		migratedKey: migratedKey,
+		prefixes:    prefixes,
+	}
+}
+
+// migrate adds checksums to the legacy values once per store. Values which
+// are neither checksummed nor valid JSON are left as they are and reported as
+// corrupted when read.
+func (s *checksumStore) migrate() error {
+	s.once.Do(func() {
+		s.migrateErr = s.migrateLegacy()
+	})
+	return s.migrateErr
+}
+
+func (s *checksumStore) migrateLegacy() error {
+	var migrated bool
+	err := s.StateStorer.Get(s.migratedKey, &migrated)
+	if err == nil && migrated {
+		return nil
+	}
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	legacy := make(map[string][]byte)
+	for _, prefix := range s.prefixes {
+		err := s.StateStorer.Iterate(prefix, func(key, value []byte) (bool, error) {
+			if k := string(key); checksummed(k) && !marked(value) && json.Valid(value) {
+				legacy[k] = append([]byte(nil), value...)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for key, data := range legacy {
+		if err := s.put(key, data); err != nil {
+			return err
+		}
+	}
+	return s.StateStorer.Put(s.migratedKey, true)
+}
+
+func checksummed(key string) bool {
+	for _, prefix := range checksumKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func checksum(key string, data []byte) uint32 {
+	sum := crc32.Update(0, checksumTable, []byte(key))
+	return crc32.Update(sum, checksumTable, data)
+}
+
+// marked reports whether the stored value ends with a checksum.
+func marked(stored []byte) bool {
+	n := len(stored) - checksumSize
+	return n >= 0 && stored[n] == checksumMarker
+}
+
+// verifyChecksum returns the value stored under the key without its checksum.
+func verifyChecksum(key string, stored []byte) ([]byte, error) {
+	if !marked(stored) {
+		return nil, &CorruptionError{Key: key}
+	}
+	n := len(stored) - checksumSize
+	data := stored[:n]
+	if binary.BigEndian.Uint32(stored[n+1:]) != checksum(key, data) {
+		return nil, &CorruptionError{Key: key}
+	}
+	return data, nil
+}
+
+// Get implements storage.StateStorer.Get method.
+func (s *checksumStore) Get(key string, i interface{}) error {
+	if !checksummed(key) {
+		return s.StateStorer.Get(key, i)
+	}
+	if err := s.migrate(); err != nil {
+		return err
+	}
+
+	var stored rawValue
+	if err := s.StateStorer.Get(key, &stored); err != nil {
+		return err
+	}
+	data, err := verifyChecksum(key, stored)
+	if err != nil {
+		return err
+	}
+	if unmarshaler, ok := i.(encoding.BinaryUnmarshaler); ok {
+		return unmarshaler.UnmarshalBinary(data)
+	}
+	return json.Unmarshal(data, i)
+}
+
+// Put implements storage.StateStorer.Put method.
+func (s *checksumStore) Put(key string, i interface{}) (err error) {
+	if !checksummed(key) {
+		return s.StateStorer.Put(key, i)
+	}
+	if err := s.migrate(); err != nil {
+		return err
+	}
+
+	var data []byte
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		if data, err = marshaler.MarshalBinary(); err != nil {
+			return err
+		}
+	} else if data, err = json.Marshal(i); err != nil {
+		return err
+	}
+	return s.put(key, data)
+}
+
+// put stores the encoded value with its checksum.
+func (s *checksumStore) put(key string, data []byte) error {
+	stored := make(rawValue, len(data), len(data)+checksumSize)
+	copy(stored, data)
+	stored = append(stored, checksumMarker)
+	stored = binary.BigEndian.AppendUint32(stored, checksum(key, data))
+	return s.StateStorer.Put(key, stored)
+}
+
+// Iterate implements storage.StateStorer.Iterate method. It stops with a
+// CorruptionError at the first corrupted value.
+func (s *checksumStore) Iterate(prefix string, iterFunc storage.StateIterFunc) error {
+	if err := s.migrate(); err != nil {
+		return err
+	}
+	return s.StateStorer.Iterate(prefix, func(key, value []byte) (bool, error) {
+		if k := string(key); checksummed(k) {
+			data, err := verifyChecksum(k, value)
+			if err != nil {
+				return true, err
+			}
+			value = data
+		}
+		return iterFunc(key, value)
+	})
+}
+
+// rawValue is stored as it is.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *rawValue) UnmarshalBinary(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+// corrupt flips a bit in the first stored value whose key starts with the
+// prefix and ends with the suffix.
+func corrupt(t *testing.T, store storage.StateStorer, prefix, suffix string) {
+	t.Helper()
+	corruptAt(t, store, prefix, suffix, 0)
+}
+
+// corruptAt flips a bit of the byte at the position in the first stored value
+// whose key starts with the prefix and ends with the suffix. Negative positions
+// count from the end of the value.
+func corruptAt(t *testing.T, store storage.StateStorer, prefix, suffix string, pos int) {
+	t.Helper()
+
+	var (
+		key   string
+		value []byte
+	)
+	err := store.Iterate(prefix, func(k, v []byte) (bool, error) {
+		if !strings.HasSuffix(string(k), suffix) {
+			return false, nil
+		}
+		key, value = string(k), v
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key == "" {
+		t.Fatalf("no record with prefix %s", prefix)
+	}
+	if pos < 0 {
+		pos += len(value)
+	}
+	value[pos] ^= 0x01
+	if err := store.Put(key, rawValue(value)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChequebookCorruptedRecords(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	address := common.HexToAddress("0xabcd")
+	beneficiary1 := common.HexToAddress("0xdddd")
+	beneficiary2 := common.HexToAddress("0xeeee")
+	store := storemock.NewStateStore()
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary1),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary2),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary1),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "totalPaidOut"),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		store,
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				return make([]byte, 65), nil
+			},
+		},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chequebookService.ImportLastCheque(ctx, beneficiary1, chequebook.TokensFromUint64(100)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chequebookService.ImportLastCheque(ctx, beneficiary2, chequebook.TokensFromUint64(50)); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt(t, store, "swap_chequebook_last_issued_cheque_", fmt.Sprintf("%x", beneficiary1))
+
+	_, err = chequebookService.LastCheque(beneficiary1)
+	var corruptionErr *chequebook.CorruptionError
+	if !errors.As(err, &corruptionErr) {
+		t.Fatalf("wrong error. wanted %T got %v", corruptionErr, err)
+	}
+	if !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+	if _, err := chequebookService.LastCheques(ctx); !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+
+	// the other records are still readable
+	if _, err := chequebookService.LastCheque(beneficiary2); err != nil {
+		t.Fatal(err)
+	}
+
+	// importing the cheque again repairs it
+	if _, err := chequebookService.ImportLastCheque(ctx, beneficiary1, chequebook.TokensFromUint64(120)); err != nil {
+		t.Fatal(err)
+	}
+	lastCheque, err := chequebookService.LastCheque(beneficiary1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastCheque.CumulativePayout.Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("wrong cumulative payout. wanted %d got %d", 120, lastCheque.CumulativePayout)
+	}
+
+	// the corrupted total issued counter is recomputed from the cheques
+	corrupt(t, store, "swap_chequebook_total_issued_", "")
+
+	available, err := chequebookService.AvailableBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(830)) != 0 {
+		t.Fatalf("wrong available balance. wanted %d got %d", 830, available)
+	}
+}
+
+func TestChequeStoreCorruptedCheque(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	cheque := func(cumulativePayout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(cumulativePayout),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	chequestore := chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque(10), big.NewInt(1), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt(t, store, "swap_chequebook_last_received_cheque_", "")
+
+	if _, err := chequestore.LastCheque(chequebookAddress); !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+	// no cheque is accepted based on the corrupted one
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque(20), big.NewInt(1), big.NewInt(0)); !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+
+	// importing the cheque again repairs it
+	if err := chequestore.ImportCheque(context.Background(), cheque(20)); err != nil {
+		t.Fatal(err)
+	}
+	lastCheque, err := chequestore.LastCheque(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastCheque.CumulativePayout.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("wrong cumulative payout. wanted %d got %d", 20, lastCheque.CumulativePayout)
+	}
+}
+
+func TestChequeStoreChecksumMigration(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	otherChequebookAddress := common.HexToAddress("0xdddd")
+
+	cheque := func(chequebookAddress common.Address) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(10),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	// cheques stored before checksums were added
+	for _, address := range []common.Address{chequebookAddress, otherChequebookAddress} {
+		if err := store.Put(chequebook.LegacyLastReceivedChequeKey(address), cheque(address)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chequestore := chequebook.NewChequeStore(store, &factoryMock{}, 1, beneficiary, transactionmock.New(), nil)
+
+	// the legacy cheques are migrated on the first use
+	for _, address := range []common.Address{chequebookAddress, otherChequebookAddress} {
+		lastCheque, err := chequestore.LastCheque(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !lastCheque.Equal(cheque(address)) {
+			t.Fatalf("wrong cheque. wanted %v got %v", cheque(address), lastCheque)
+		}
+	}
+
+	// a corrupted checksum marker is not mistaken for a legacy value
+	corruptAt(t, store, fmt.Sprintf("swap_chequebook_last_received_cheque__%x", chequebookAddress), "", -5)
+	if _, err := chequestore.LastCheque(chequebookAddress); !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+
+	// values without checksum are rejected after the migration, also by a
+	// new cheque store on the same state
+	if err := store.Put(chequebook.LastReceivedChequeKey(otherChequebookAddress, beneficiary), cheque(otherChequebookAddress)); err != nil {
+		t.Fatal(err)
+	}
+	chequestore = chequebook.NewChequeStore(store, &factoryMock{}, 1, beneficiary, transactionmock.New(), nil)
+	if _, err := chequestore.LastCheque(otherChequebookAddress); !errors.Is(err, chequebook.ErrCorruptedRecord) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrCorruptedRecord, err)
+	}
+}
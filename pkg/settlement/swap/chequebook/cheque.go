@@ -89,6 +89,15 @@ func eip712DataForCheque(cheque *Cheque, chainID int64) *eip712.TypedData {
 	}
 }
 
+// ChequeHash computes the EIP712 hash of the cheque which is signed by the issuer.
+func ChequeHash(cheque *Cheque, chainID int64) ([]byte, error) {
+	rawData, err := eip712.EncodeForSigning(eip712DataForCheque(cheque, chainID))
+	if err != nil {
+		return nil, err
+	}
+	return crypto.LegacyKeccak256(rawData)
+}
+
 // Sign signs a cheque.
 func (s *chequeSigner) Sign(cheque *Cheque) ([]byte, error) {
 	return s.signer.SignTypedData(eip712DataForCheque(cheque, s.chainID))
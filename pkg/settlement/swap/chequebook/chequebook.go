@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/settlement/swap/erc20"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -43,14 +44,39 @@ var (
 	chequeBouncedEventType = chequebookABI.Events["ChequeBounced"]
 )
 
+// OutOfFundsError is returned by Issue if the chequebook has not enough free
+// funds for the cheque. It contains the figures the decision was based on.
+// It matches ErrOutOfFunds with errors.Is.
+type OutOfFundsError struct {
+	Requested    *big.Int // amount of the cheque
+	Available    *big.Int // balance not covered by issued cheques
+	Reserved     *big.Int // amount of cheques currently being issued
+	Balance      *big.Int // on-chain token balance of the chequebook
+	TotalPaidOut *big.Int // total amount ever cashed from the chequebook
+	TotalIssued  *big.Int // total amount of all cheques ever issued
+}
+
+func (e *OutOfFundsError) Unwrap() error {
+	return ErrOutOfFunds
+}
+
+func (e *OutOfFundsError) Error() string {
+	return fmt.Sprintf("%v: requested %d, available %d (balance %d + paid out %d - issued %d), reserved %d", ErrOutOfFunds, e.Requested, e.Available, e.Balance, e.TotalPaidOut, e.TotalIssued, e.Reserved)
+}
+
 // Service is the main interface for interacting with the nodes chequebook.
 type Service interface {
 	// Deposit starts depositing erc20 token into the chequebook. This returns once the transactions has been broadcast.
-	Deposit(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
+	Deposit(ctx context.Context, amount Tokens) (hash common.Hash, err error)
 	// Withdraw starts withdrawing erc20 token from the chequebook. This returns once the transactions has been broadcast.
-	Withdraw(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
+	Withdraw(ctx context.Context, amount Tokens) (hash common.Hash, err error)
+	// SplitDeposit deposits in transfers of at most maxTransfer and waits for them to confirm.
+	SplitDeposit(ctx context.Context, amount, maxTransfer Tokens) (*SplitDepositResult, error)
 	// WaitForDeposit waits for the deposit transaction to confirm and verifies the result.
+	// Waiting for the same transaction again returns the same result.
 	WaitForDeposit(ctx context.Context, txHash common.Hash) error
+	// WaitForDepositWithProgress is WaitForDeposit reporting the confirmations of the transaction as they accrue.
+	WaitForDepositWithProgress(ctx context.Context, txHash common.Hash, progressFn DepositProgressFunc) error
 	// Balance returns the token balance of the chequebook.
 	Balance(ctx context.Context) (*big.Int, error)
 	// AvailableBalance returns the token balance of the chequebook which is not yet used for uncashed cheques.
@@ -58,39 +84,71 @@ type Service interface {
 	// Address returns the address of the used chequebook contract.
 	Address() common.Address
 	// Issue a new cheque for the beneficiary with an cumulativePayout amount higher than the last.
-	Issue(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc SendChequeFunc) (*big.Int, error)
+	Issue(ctx context.Context, beneficiary common.Address, amount Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error)
+	// PreviewIssue evaluates issuing a cheque for the beneficiary without signing or sending it.
+	PreviewIssue(ctx context.Context, beneficiary common.Address, amount Tokens) (*IssuePreview, error)
 	// LastCheque returns the last cheque we issued for the beneficiary.
 	LastCheque(beneficiary common.Address) (*SignedCheque, error)
-	// LastCheque returns the last cheques for all beneficiaries.
-	LastCheques() (map[common.Address]*SignedCheque, error)
+	// LastCheques returns the last cheques for all beneficiaries.
+	LastCheques(ctx context.Context) (map[common.Address]*SignedCheque, error)
+	// LastChequesCount returns the number of beneficiaries cheques were issued to.
+	LastChequesCount(ctx context.Context) (int, error)
+	// LastChequesSnapshot returns the last cheques for all beneficiaries together with the total issued counter.
+	LastChequesSnapshot(ctx context.Context) (*ChequesSnapshot, error)
+	// Approve starts approving the spender to transfer erc20 token on behalf of the owner. This returns once the transaction has been broadcast.
+	Approve(ctx context.Context, spender common.Address, amount Tokens) (hash common.Hash, err error)
+	// Allowance returns the amount of erc20 token the spender is still allowed to transfer on behalf of the owner.
+	Allowance(ctx context.Context, spender common.Address) (*big.Int, error)
+	// Token returns the metadata of the erc20 token used by the chequebook.
+	Token(ctx context.Context) (*Token, error)
+	// DepositHistory returns all token transfers into the chequebook found on chain.
+	DepositHistory(ctx context.Context) ([]Deposit, error)
+	// ChequeHistory returns up to limit cheques issued to the beneficiary starting at offset and the number of all cheques issued to it.
+	ChequeHistory(ctx context.Context, beneficiary common.Address, offset, limit uint64) ([]IssuedCheque, uint64, error)
+	// ImportLastCheque records the cumulative payout of the last cheque issued to the beneficiary before the state of the node was lost or migrated.
+	ImportLastCheque(ctx context.Context, beneficiary common.Address, cumulativePayout Tokens) (*SignedCheque, error)
 }
 
 type service struct {
 	lock               sync.Mutex
 	transactionService transaction.Service
 
+	// issuedMu is held for writing while the issued cheques and the total
+	// issued counter are updated, so that readers see both in a consistent
+	// state. Writers hold lock as well.
+	issuedMu sync.RWMutex
+
 	address      common.Address
 	contract     *chequebookContract
 	ownerAddress common.Address
 
 	erc20Service erc20.Service
 
-	store               storage.StateStorer
+	store               contextStore
 	chequeSigner        ChequeSigner
 	totalIssuedReserved *big.Int
+
+	tokenMu sync.Mutex
+	token   *Token // cached token metadata
+
+	backend        transaction.Backend
+	depositMu      sync.Mutex
+	splitDepositMu sync.Mutex // split deposits are serialized to attribute the received amounts
+	depositWaits   depositWaits
 }
 
 // New creates a new chequebook service for the provided chequebook contract.
-func New(transactionService transaction.Service, address, ownerAddress common.Address, store storage.StateStorer, chequeSigner ChequeSigner, erc20Service erc20.Service) (Service, error) {
+func New(transactionService transaction.Service, address, ownerAddress common.Address, store storage.StateStorer, chequeSigner ChequeSigner, erc20Service erc20.Service, backend transaction.Backend) (Service, error) {
 	return &service{
 		transactionService:  transactionService,
 		address:             address,
 		contract:            newChequebookContract(address, transactionService),
 		ownerAddress:        ownerAddress,
 		erc20Service:        erc20Service,
-		store:               store,
+		store:               contextStore{newChecksumStore(namespacedStore(store, address), issuedChecksumsMigratedKey, lastIssuedChequeKeyPrefix, totalIssuedKey)},
 		chequeSigner:        chequeSigner,
 		totalIssuedReserved: big.NewInt(0),
+		backend:             backend,
 	}, nil
 }
 
@@ -100,7 +158,9 @@ func (s *service) Address() common.Address {
 }
 
 // Deposit starts depositing erc20 token into the chequebook. This returns once the transactions has been broadcast.
-func (s *service) Deposit(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
+func (s *service) Deposit(ctx context.Context, tokens Tokens) (hash common.Hash, err error) {
+	amount := tokens.BigInt()
+
 	balance, err := s.erc20Service.BalanceOf(ctx, s.ownerAddress)
 	if err != nil {
 		return common.Hash{}, err
@@ -114,6 +174,17 @@ func (s *service) Deposit(ctx context.Context, amount *big.Int) (hash common.Has
 	return s.erc20Service.Transfer(ctx, s.address, amount)
 }
 
+// Approve starts approving the spender to transfer erc20 token on behalf of the owner. This returns once the transaction has been broadcast.
+// An allowance does not move any token, so it may exceed the current balance of the owner.
+func (s *service) Approve(ctx context.Context, spender common.Address, tokens Tokens) (hash common.Hash, err error) {
+	return s.erc20Service.Approve(ctx, spender, tokens.BigInt())
+}
+
+// Allowance returns the amount of erc20 token the spender is still allowed to transfer on behalf of the owner.
+func (s *service) Allowance(ctx context.Context, spender common.Address) (*big.Int, error) {
+	return s.erc20Service.Allowance(ctx, s.ownerAddress, spender)
+}
+
 // Balance returns the token balance of the chequebook.
 func (s *service) Balance(ctx context.Context) (*big.Int, error) {
 	return s.contract.Balance(ctx)
@@ -121,38 +192,115 @@ func (s *service) Balance(ctx context.Context) (*big.Int, error) {
 
 // AvailableBalance returns the token balance of the chequebook which is not yet used for uncashed cheques.
 func (s *service) AvailableBalance(ctx context.Context) (*big.Int, error) {
-	totalIssued, err := s.totalIssued()
+	b, err := s.balanceBreakdown(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return b.available, nil
+}
+
+// bigIntPool holds temporaries of the balance computations when issuing cheques.
+var bigIntPool = sync.Pool{
+	New: func() interface{} {
+		return new(big.Int)
+	},
+}
+
+// balanceBreakdown is the available balance together with the values it is computed from.
+type balanceBreakdown struct {
+	available    *big.Int
+	balance      *big.Int
+	totalPaidOut *big.Int
+	totalIssued  *big.Int
+}
+
+func (s *service) balanceBreakdown(ctx context.Context) (balanceBreakdown, error) {
+	totalIssued, err := s.totalIssued()
+	if err != nil {
+		return balanceBreakdown{}, err
+	}
 
 	balance, err := s.Balance(ctx)
 	if err != nil {
-		return nil, err
+		return balanceBreakdown{}, err
 	}
 
 	totalPaidOut, err := s.contract.TotalPaidOut(ctx)
 	if err != nil {
-		return nil, err
+		return balanceBreakdown{}, err
 	}
 
 	// balance plus totalPaidOut is the total amount ever put into the chequebook (ignoring deposits and withdrawals which cancelled out)
 	// minus the total amount we issued from this chequebook this gives use the portion of the balance not covered by any cheques
 	availableBalance := big.NewInt(0).Add(balance, totalPaidOut)
 	availableBalance = availableBalance.Sub(availableBalance, totalIssued)
-	return availableBalance, nil
+	return balanceBreakdown{
+		available:    availableBalance,
+		balance:      balance,
+		totalPaidOut: totalPaidOut,
+		totalIssued:  totalIssued,
+	}, nil
 }
 
-// WaitForDeposit waits for the deposit transaction to confirm and verifies the result.
+// WaitForDeposit waits for the deposit transaction to confirm and verifies the
+// result. Waiting again for the same transaction returns the remembered result.
 func (s *service) WaitForDeposit(ctx context.Context, txHash common.Hash) error {
+	return s.depositWaits.wait(ctx, txHash, func(ctx context.Context, txHash common.Hash) error {
+		_, err := s.waitForDeposit(ctx, txHash)
+		return err
+	})
+}
+
+// WaitForDepositWithProgress is WaitForDeposit reporting the progress of the
+// wait to progressFn: first that the transaction is pending, then its
+// confirmations as they accrue and finally that it is final. If the result is
+// remembered or the same transaction is already waited for by another caller,
+// the progress is not reported.
+func (s *service) WaitForDepositWithProgress(ctx context.Context, txHash common.Hash, progressFn DepositProgressFunc) error {
+	if progressFn == nil {
+		return s.WaitForDeposit(ctx, txHash)
+	}
+
+	return s.depositWaits.wait(ctx, txHash, func(ctx context.Context, txHash common.Hash) error {
+		last := DepositProgress{TxHash: txHash}
+		progressFn(last)
+
+		ctx = transaction.WithConfirmationsFunc(ctx, func(c transaction.Confirmations) {
+			last = DepositProgress{
+				TxHash:        txHash,
+				Mined:         true,
+				BlockNumber:   c.BlockNumber,
+				Confirmations: c.Confirmations,
+				Required:      c.Required,
+			}
+			progressFn(last)
+		})
+
+		receipt, err := s.waitForDeposit(ctx, txHash)
+		if receipt != nil {
+			last.Mined = true
+			last.Final = true
+			last.Confirmations = last.Required
+			if receipt.BlockNumber != nil {
+				last.BlockNumber = receipt.BlockNumber.Uint64()
+			}
+			progressFn(last)
+		}
+		return err
+	})
+}
+
+// waitForDeposit waits for the receipt of the deposit transaction. The
+// receipt is returned if the transaction was mined, even if it reverted.
+func (s *service) waitForDeposit(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	receipt, err := s.transactionService.WaitForReceipt(ctx, txHash)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if receipt.Status != 1 {
-		return transaction.ErrTransactionReverted
+		return receipt, transaction.ErrTransactionReverted
 	}
-	return nil
+	return receipt, nil
 }
 
 // lastIssuedChequeKey computes the key where to store the last cheque for a beneficiary.
@@ -164,17 +312,29 @@ func (s *service) reserveTotalIssued(ctx context.Context, amount *big.Int) (*big
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	availableBalance, err := s.AvailableBalance(ctx)
+	breakdown, err := s.balanceBreakdown(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	if amount.Cmp(big.NewInt(0).Sub(availableBalance, s.totalIssuedReserved)) > 0 {
-		return nil, ErrOutOfFunds
+	availableBalance := breakdown.available
+
+	unreserved := bigIntPool.Get().(*big.Int)
+	defer bigIntPool.Put(unreserved)
+
+	if amount.Cmp(unreserved.Sub(availableBalance, s.totalIssuedReserved)) > 0 {
+		return nil, &OutOfFundsError{
+			Requested:    new(big.Int).Set(amount),
+			Available:    availableBalance,
+			Reserved:     new(big.Int).Set(s.totalIssuedReserved),
+			Balance:      breakdown.balance,
+			TotalPaidOut: breakdown.totalPaidOut,
+			TotalIssued:  breakdown.totalIssued,
+		}
 	}
 
 	s.totalIssuedReserved = s.totalIssuedReserved.Add(s.totalIssuedReserved, amount)
-	return big.NewInt(0).Sub(availableBalance, amount), nil
+	// the available balance is computed for this call only and can be reused
+	return availableBalance.Sub(availableBalance, amount), nil
 }
 
 func (s *service) unreserveTotalIssued(amount *big.Int) {
@@ -187,7 +347,9 @@ func (s *service) unreserveTotalIssued(amount *big.Int) {
 // The cheque is considered sent and saved when sendChequeFunc succeeds.
 // The available balance which is available after sending the cheque is passed
 // to the caller for it to be communicated over metrics.
-func (s *service) Issue(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+func (s *service) Issue(ctx context.Context, beneficiary common.Address, tokens Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+	amount := tokens.BigInt()
+
 	availableBalance, err := s.reserveTotalIssued(ctx, amount)
 	if err != nil {
 		return nil, err
@@ -195,7 +357,7 @@ func (s *service) Issue(ctx context.Context, beneficiary common.Address, amount
 	defer s.unreserveTotalIssued(amount)
 
 	var cumulativePayout *big.Int
-	lastCheque, err := s.LastCheque(beneficiary)
+	lastCheque, err := s.lastCheque(ctx, beneficiary)
 	if err != nil {
 		if !errors.Is(err, ErrNoCheque) {
 			return nil, err
@@ -215,31 +377,40 @@ func (s *service) Issue(ctx context.Context, beneficiary common.Address, amount
 		Beneficiary:      beneficiary,
 	}
 
-	sig, err := s.chequeSigner.Sign(&Cheque{
-		Chequebook:       s.address,
-		CumulativePayout: cumulativePayout,
-		Beneficiary:      beneficiary,
-	})
+	sig, err := s.chequeSigner.Sign(&cheque)
 	if err != nil {
 		return nil, err
 	}
 
-	// actually send the check before saving to avoid double payment
-	err = sendChequeFunc(&SignedCheque{
+	signedCheque := &SignedCheque{
 		Cheque:    cheque,
 		Signature: sig,
-	})
-	if err != nil {
-		return nil, err
 	}
 
-	err = s.store.Put(lastIssuedChequeKey(beneficiary), cheque)
+	// actually send the check before saving to avoid double payment
+	err = sendChequeFunc(signedCheque)
 	if err != nil {
 		return nil, err
 	}
 
+	// the cheque and the total issued counter are updated together so that
+	// consistency checks never see one without the other
 	s.lock.Lock()
 	defer s.lock.Unlock()
+	s.issuedMu.Lock()
+	defer s.issuedMu.Unlock()
+
+	// the cheque was sent, so its state is stored regardless of the context
+	err = s.appendChequeHistory(signedCheque, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature is kept so that the cheque can be sent again
+	err = s.store.Put(lastIssuedChequeKey(beneficiary), signedCheque)
+	if err != nil {
+		return nil, err
+	}
 
 	totalIssued, err := s.totalIssued()
 	if err != nil {
@@ -253,6 +424,9 @@ func (s *service) Issue(ctx context.Context, beneficiary common.Address, amount
 func (s *service) totalIssued() (totalIssued *big.Int, err error) {
 	err = s.store.Get(totalIssuedKey, &totalIssued)
 	if err != nil {
+		if errors.Is(err, ErrCorruptedRecord) {
+			return s.repairTotalIssued(context.Background())
+		}
 		if !errors.Is(err, storage.ErrNotFound) {
 			return nil, err
 		}
@@ -261,10 +435,32 @@ func (s *service) totalIssued() (totalIssued *big.Int, err error) {
 	return totalIssued, nil
 }
 
+// repairTotalIssued recomputes the total amount in cheques issued so far from
+// the last cheques issued to every beneficiary and stores it. It fails if one
+// of the cheques is corrupted itself.
+func (s *service) repairTotalIssued(ctx context.Context) (*big.Int, error) {
+	cheques, err := s.lastCheques(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repair total issued: %w", err)
+	}
+	totalIssued := big.NewInt(0)
+	for _, cheque := range cheques {
+		totalIssued.Add(totalIssued, cheque.CumulativePayout)
+	}
+	if err := s.store.Put(totalIssuedKey, totalIssued); err != nil {
+		return nil, fmt.Errorf("repair total issued: %w", err)
+	}
+	return totalIssued, nil
+}
+
 // LastCheque returns the last cheque we issued for the beneficiary.
 func (s *service) LastCheque(beneficiary common.Address) (*SignedCheque, error) {
+	return s.lastCheque(context.Background(), beneficiary)
+}
+
+func (s *service) lastCheque(ctx context.Context, beneficiary common.Address) (*SignedCheque, error) {
 	var lastCheque *SignedCheque
-	err := s.store.Get(lastIssuedChequeKey(beneficiary), &lastCheque)
+	err := s.store.GetContext(ctx, lastIssuedChequeKey(beneficiary), &lastCheque)
 	if err != nil {
 		if !errors.Is(err, storage.ErrNotFound) {
 			return nil, err
@@ -275,42 +471,34 @@ func (s *service) LastCheque(beneficiary common.Address) (*SignedCheque, error)
 }
 
 func keyBeneficiary(key []byte, prefix string) (beneficiary common.Address, err error) {
-	k := string(key)
-
-	split := strings.SplitAfter(k, prefix)
-	if len(split) != 2 {
+	k, ok := strings.CutPrefix(string(key), prefix)
+	if !ok {
 		return common.Address{}, errors.New("no beneficiary in key")
 	}
-	return common.HexToAddress(split[1]), nil
+	return common.HexToAddress(k), nil
 }
 
-// LastCheque returns the last cheques for all beneficiaries.
-func (s *service) LastCheques() (map[common.Address]*SignedCheque, error) {
-	result := make(map[common.Address]*SignedCheque)
-	err := s.store.Iterate(lastIssuedChequeKeyPrefix, func(key, val []byte) (stop bool, err error) {
-		addr, err := keyBeneficiary(key, lastIssuedChequeKeyPrefix)
-		if err != nil {
-			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
-		}
-
-		if _, ok := result[addr]; !ok {
-
-			lastCheque, err := s.LastCheque(addr)
-			if err != nil {
-				return false, err
-			}
+// LastCheques returns the last cheques for all beneficiaries.
+func (s *service) LastCheques(ctx context.Context) (map[common.Address]*SignedCheque, error) {
+	s.issuedMu.RLock()
+	defer s.issuedMu.RUnlock()
+	return s.lastCheques(ctx)
+}
 
-			result[addr] = lastCheque
-		}
-		return false, nil
+func (s *service) lastCheques(ctx context.Context) (map[common.Address]*SignedCheque, error) {
+	return loadCheques(ctx, s.store, lastIssuedChequeKeyPrefix, func(key []byte) (common.Address, error) {
+		return keyBeneficiary(key, lastIssuedChequeKeyPrefix)
 	})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
 }
 
-func (s *service) Withdraw(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
+// LastChequesCount returns the number of beneficiaries cheques were issued to.
+func (s *service) LastChequesCount(ctx context.Context) (int, error) {
+	return countCheques(ctx, s.store, lastIssuedChequeKeyPrefix)
+}
+
+func (s *service) Withdraw(ctx context.Context, tokens Tokens) (hash common.Hash, err error) {
+	amount := tokens.BigInt()
+
 	availableBalance, err := s.AvailableBalance(ctx)
 	if err != nil {
 		return common.Hash{}, err
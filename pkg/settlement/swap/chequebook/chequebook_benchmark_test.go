@@ -0,0 +1,98 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func newBenchmarkService(b *testing.B) chequebook.Service {
+	b.Helper()
+
+	// the balance and the total paid out are answered with the same value
+	result := new(big.Int).Lsh(big.NewInt(1), 128).FillBytes(make([]byte, 32))
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				return result, nil
+			}),
+		),
+		common.HexToAddress("0xabcd"),
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+			return make([]byte, 65), nil
+		}},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return chequebookService
+}
+
+func issue(b *testing.B, chequebookService chequebook.Service, beneficiary common.Address) {
+	b.Helper()
+
+	_, err := chequebookService.Issue(context.Background(), beneficiary, chequebook.TokensFromUint64(1000), func(*chequebook.SignedCheque) error {
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkIssue(b *testing.B) {
+	chequebookService := newBenchmarkService(b)
+	beneficiary := common.HexToAddress("0xdddd")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		issue(b, chequebookService, beneficiary)
+	}
+}
+
+func BenchmarkAvailableBalance(b *testing.B) {
+	chequebookService := newBenchmarkService(b)
+	issue(b, chequebookService, common.HexToAddress("0xdddd"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := chequebookService.AvailableBalance(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLastCheques(b *testing.B) {
+	for _, beneficiaries := range []int{10, 1000} {
+		b.Run(big.NewInt(int64(beneficiaries)).String(), func(b *testing.B) {
+			chequebookService := newBenchmarkService(b)
+			for i := 1; i <= beneficiaries; i++ {
+				issue(b, chequebookService, common.BigToAddress(big.NewInt(int64(i))))
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := chequebookService.LastCheques(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -9,14 +9,18 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
 	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
 	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
 )
 
@@ -32,6 +36,7 @@ func TestChequebookAddress(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -57,6 +62,7 @@ func TestChequebookBalance(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -103,12 +109,58 @@ func TestChequebookDeposit(t *testing.T) {
 				return txHash, nil
 			}),
 		),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	returnedTxHash, err := chequebookService.Deposit(context.Background(), depositAmount)
+	returnedTxHash, err := chequebookService.Deposit(context.Background(), chequebook.MustNewTokens(depositAmount))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if txHash != returnedTxHash {
+		t.Fatalf("returned wrong transaction hash. wanted %v, got %v", txHash, returnedTxHash)
+	}
+}
+
+func TestChequebookApprove(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	ownerAdress := common.HexToAddress("0xfff")
+	spender := common.HexToAddress("0xeeee")
+	// the allowance may exceed the balance of the owner
+	approveAmount := big.NewInt(20)
+	txHash := common.HexToHash("0xdddd")
+	chequebookService, err := chequebook.New(
+		transactionmock.New(),
+		address,
+		ownerAdress,
+		nil,
+		&chequeSignerMock{},
+		erc20mock.New(
+			erc20mock.WithBalanceOfFunc(func(ctx context.Context, address common.Address) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+			erc20mock.WithApproveFunc(func(ctx context.Context, to common.Address, value *big.Int) (common.Hash, error) {
+				if to != spender {
+					return common.Hash{}, fmt.Errorf("approving wrong spender. wanted %x, got %x", spender, to)
+				}
+				if approveAmount.Cmp(value) != 0 {
+					return common.Hash{}, fmt.Errorf("approving wrong value. wanted %d, got %d", approveAmount, value)
+				}
+				return txHash, nil
+			}),
+		),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	returnedTxHash, err := chequebookService.Approve(context.Background(), spender, chequebook.MustNewTokens(approveAmount))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -140,6 +192,7 @@ func TestChequebookWaitForDeposit(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -173,6 +226,7 @@ func TestChequebookWaitForDepositReverted(t *testing.T) {
 		nil,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -187,6 +241,121 @@ func TestChequebookWaitForDepositReverted(t *testing.T) {
 	}
 }
 
+func TestChequebookWaitForDepositIdempotent(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0xdddd")
+	errRPC := errors.New("rpc unavailable")
+	var calls int
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, tx common.Hash) (*types.Receipt, error) {
+				calls++
+				if calls == 1 {
+					return nil, errRPC
+				}
+				return &types.Receipt{
+					Status: 0,
+				}, nil
+			}),
+		),
+		common.HexToAddress("0xabcd"),
+		common.HexToAddress("0xfff"),
+		nil,
+		&chequeSignerMock{},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// transient errors are not remembered
+	if err := chequebookService.WaitForDeposit(context.Background(), txHash); !errors.Is(err, errRPC) {
+		t.Fatalf("wrong error. wanted %v, got %v", errRPC, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := chequebookService.WaitForDeposit(context.Background(), txHash); !errors.Is(err, transaction.ErrTransactionReverted) {
+			t.Fatalf("wrong error. wanted %v, got %v", transaction.ErrTransactionReverted, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("waited for the receipt %d times, want 2", calls)
+	}
+}
+
+func TestChequebookWaitForDepositWithProgress(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0xdddd")
+	head := uint64(100)
+	chequebookService, err := chequebook.New(
+		transaction.NewFinalityService(
+			transactionmock.New(
+				transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, tx common.Hash) (*types.Receipt, error) {
+					return &types.Receipt{Status: 1, BlockNumber: big.NewInt(100)}, nil
+				}),
+			),
+			backendmock.New(
+				backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+					head++
+					return head, nil
+				}),
+				backendmock.WithTransactionReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+					return &types.Receipt{Status: 1, BlockNumber: big.NewInt(100)}, nil
+				}),
+			),
+			3,
+			time.Millisecond,
+		),
+		common.HexToAddress("0xabcd"),
+		common.HexToAddress("0xfff"),
+		nil,
+		&chequeSignerMock{},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []chequebook.DepositProgress
+	err = chequebookService.WaitForDepositWithProgress(context.Background(), txHash, func(p chequebook.DepositProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []chequebook.DepositProgress{
+		{TxHash: txHash},
+		{TxHash: txHash, Mined: true, BlockNumber: 100, Confirmations: 1, Required: 3},
+		{TxHash: txHash, Mined: true, BlockNumber: 100, Confirmations: 2, Required: 3},
+		{TxHash: txHash, Mined: true, BlockNumber: 100, Confirmations: 3, Required: 3},
+		{TxHash: txHash, Mined: true, BlockNumber: 100, Confirmations: 3, Required: 3, Final: true},
+	}
+	if len(progress) != len(want) {
+		t.Fatalf("got progress %+v, want %+v", progress, want)
+	}
+	for i := range want {
+		if progress[i] != want[i] {
+			t.Fatalf("got progress %+v, want %+v", progress[i], want[i])
+		}
+	}
+
+	// the result is remembered and not waited for again
+	progress = nil
+	if err := chequebookService.WaitForDepositWithProgress(context.Background(), txHash, func(p chequebook.DepositProgress) {
+		progress = append(progress, p)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 0 {
+		t.Fatalf("got progress %+v for remembered result", progress)
+	}
+}
+
 func TestChequebookIssue(t *testing.T) {
 	t.Parallel()
 
@@ -216,6 +385,7 @@ func TestChequebookIssue(t *testing.T) {
 		store,
 		chequeSigner,
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -238,7 +408,7 @@ func TestChequebookIssue(t *testing.T) {
 		return sig, nil
 	}
 
-	_, err = chequebookService.Issue(context.Background(), beneficiary, amount, func(cheque *chequebook.SignedCheque) error {
+	_, err = chequebookService.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
 		if !cheque.Equal(expectedCheque) {
 			t.Fatalf("wrong cheque. wanted %v got %v", expectedCheque, cheque)
 		}
@@ -274,7 +444,7 @@ func TestChequebookIssue(t *testing.T) {
 		return sig, nil
 	}
 
-	_, err = chequebookService.Issue(context.Background(), beneficiary, amount2, func(cheque *chequebook.SignedCheque) error {
+	_, err = chequebookService.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(amount2), func(cheque *chequebook.SignedCheque) error {
 		if !cheque.Equal(expectedCheque) {
 			t.Fatalf("wrong cheque. wanted %v got %v", expectedCheque, cheque)
 		}
@@ -310,7 +480,7 @@ func TestChequebookIssue(t *testing.T) {
 		return sig, nil
 	}
 
-	_, err = chequebookService.Issue(context.Background(), ownerAdress, amount, func(cheque *chequebook.SignedCheque) error {
+	_, err = chequebookService.Issue(context.Background(), ownerAdress, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
 		if !cheque.Equal(expectedChequeOwner) {
 			t.Fatalf("wrong cheque. wanted %v got %v", expectedChequeOwner, cheque)
 		}
@@ -338,6 +508,16 @@ func TestChequebookIssue(t *testing.T) {
 	if !lastCheque.Equal(expectedCheque) {
 		t.Fatalf("wrong cheque stored. wanted %v got %v", expectedCheque, lastCheque)
 	}
+
+	// the cheques of another chequebook sharing the store are kept apart
+	otherChequebookService, err := chequebook.New(transactionmock.New(), common.HexToAddress("0xef01"), ownerAdress, store, chequeSigner, erc20mock.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = otherChequebookService.LastCheque(beneficiary)
+	if !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
 }
 
 func TestChequebookIssueErrorSend(t *testing.T) {
@@ -358,6 +538,7 @@ func TestChequebookIssueErrorSend(t *testing.T) {
 		store,
 		chequeSigner,
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -367,7 +548,7 @@ func TestChequebookIssueErrorSend(t *testing.T) {
 		return sig, nil
 	}
 
-	_, err = chequebookService.Issue(context.Background(), beneficiary, amount, func(cheque *chequebook.SignedCheque) error {
+	_, err = chequebookService.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
 		return errors.New("err")
 	})
 	if err == nil {
@@ -393,8 +574,8 @@ func TestChequebookIssueOutOfFunds(t *testing.T) {
 	chequebookService, err := chequebook.New(
 		transactionmock.New(
 			transactionmock.WithABICallSequence(
-				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "balance"),
-				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "totalPaidOut"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(5).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(3).FillBytes(make([]byte, 32)), "totalPaidOut"),
 			),
 		),
 		address,
@@ -402,18 +583,32 @@ func TestChequebookIssueOutOfFunds(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = chequebookService.Issue(context.Background(), beneficiary, amount, func(cheque *chequebook.SignedCheque) error {
+	_, err = chequebookService.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
 		return nil
 	})
 	if !errors.Is(err, chequebook.ErrOutOfFunds) {
 		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrOutOfFunds, err)
 	}
 
+	var outOfFunds *chequebook.OutOfFundsError
+	if !errors.As(err, &outOfFunds) {
+		t.Fatalf("got error %T, want %T", err, outOfFunds)
+	}
+	if outOfFunds.Requested.Cmp(amount) != 0 ||
+		outOfFunds.Available.Int64() != 8 ||
+		outOfFunds.Balance.Int64() != 5 ||
+		outOfFunds.TotalPaidOut.Int64() != 3 ||
+		outOfFunds.TotalIssued.Sign() != 0 ||
+		outOfFunds.Reserved.Sign() != 0 {
+		t.Fatalf("unexpected breakdown %v", outOfFunds)
+	}
+
 	// verify the cheque was not saved
 	_, err = chequebookService.LastCheque(beneficiary)
 
@@ -422,6 +617,117 @@ func TestChequebookIssueOutOfFunds(t *testing.T) {
 	}
 }
 
+func TestChequebookLastCheques(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			// balance and total paid out
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				return big.NewInt(1_000_000).FillBytes(make([]byte, 32)), nil
+			}),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+			return []byte{1}, nil
+		}},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const beneficiaries = 100
+	for i := 1; i <= beneficiaries; i++ {
+		_, err := chequebookService.Issue(context.Background(), common.BigToAddress(big.NewInt(int64(i))), chequebook.MustNewTokens(big.NewInt(int64(i))), func(*chequebook.SignedCheque) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cheques, err := chequebookService.LastCheques(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != beneficiaries {
+		t.Fatalf("got %d cheques, want %d", len(cheques), beneficiaries)
+	}
+	for beneficiary, cheque := range cheques {
+		if cheque.Beneficiary != beneficiary || cheque.CumulativePayout.Cmp(beneficiary.Hash().Big()) != 0 {
+			t.Fatalf("wrong cheque %v for beneficiary %x", cheque, beneficiary)
+		}
+	}
+
+	count, err := chequebookService.LastChequesCount(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != beneficiaries {
+		t.Fatalf("got count %d, want %d", count, beneficiaries)
+	}
+}
+
+// slowStore delays reads of the last issued cheques.
+type slowStore struct {
+	storage.StateStorer
+	delay time.Duration
+}
+
+func (s *slowStore) Get(key string, i interface{}) error {
+	if strings.HasPrefix(key, "swap_chequebook_last_issued_cheque_") {
+		time.Sleep(s.delay)
+	}
+	return s.StateStorer.Get(key, i)
+}
+
+func TestChequebookIssueContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	store := &slowStore{StateStorer: storemock.NewStateStore(), delay: 100 * time.Millisecond}
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "totalPaidOut"),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		store,
+		&chequeSignerMock{},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = chequebookService.Issue(ctx, beneficiary, chequebook.TokensFromUint64(20), func(cheque *chequebook.SignedCheque) error {
+		t.Fatal("cheque sent after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	_, err = chequebookService.LastCheques(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
 func TestChequebookWithdraw(t *testing.T) {
 	t.Parallel()
 
@@ -444,12 +750,13 @@ func TestChequebookWithdraw(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	returnedTxHash, err := chequebookService.Withdraw(context.Background(), withdrawAmount)
+	returnedTxHash, err := chequebookService.Withdraw(context.Background(), chequebook.MustNewTokens(withdrawAmount))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -480,12 +787,13 @@ func TestChequebookWithdrawInsufficientFunds(t *testing.T) {
 		store,
 		&chequeSignerMock{},
 		erc20mock.New(),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = chequebookService.Withdraw(context.Background(), withdrawAmount)
+	_, err = chequebookService.Withdraw(context.Background(), chequebook.MustNewTokens(withdrawAmount))
 	if !errors.Is(err, chequebook.ErrInsufficientFunds) {
 		t.Fatalf("got wrong error. wanted %v, got %v", chequebook.ErrInsufficientFunds, err)
 	}
@@ -506,8 +814,25 @@ func TestStateStoreKeys(t *testing.T) {
 		t.Fatalf("wrong last issued cheque key. wanted %s, got %s", expected, chequebook.LastIssuedChequeKey(address))
 	}
 
-	expected = "swap_chequebook_last_received_cheque__000000000000000000000000000000000000abcd"
-	if chequebook.LastReceivedChequeKey(address) != expected {
-		t.Fatalf("wrong last received cheque key. wanted %s, got %s", expected, chequebook.LastReceivedChequeKey(address))
+	expected = "swap_chequebook_last_received_cheque__000000000000000000000000000000000000abcd_000000000000000000000000000000000000dcba"
+	if chequebook.LastReceivedChequeKey(address, common.HexToAddress("0xdcba")) != expected {
+		t.Fatalf("wrong last received cheque key. wanted %s, got %s", expected, chequebook.LastReceivedChequeKey(address, common.HexToAddress("0xdcba")))
+	}
+
+	expected = "swap_chequebook_verification_000000000000000000000000000000000000abcd"
+	if chequebook.VerificationKey(address) != expected {
+		t.Fatalf("wrong verification key. wanted %s, got %s", expected, chequebook.VerificationKey(address))
+	}
+
+	txHash := common.HexToHash("0xdddd")
+
+	expected = "swap_cashout_transaction_000000000000000000000000000000000000000000000000000000000000dddd"
+	if chequebook.CashoutTransactionKey(txHash) != expected {
+		t.Fatalf("wrong cashout transaction key. wanted %s, got %s", expected, chequebook.CashoutTransactionKey(txHash))
+	}
+
+	expected = "swap_cheque_cashout_000000000000000000000000000000000000abcd_000000000000000000000000000000000000000000000000000000000000dddd"
+	if chequebook.ChequeCashoutKey(address, txHash) != expected {
+		t.Fatalf("wrong cheque cashout key. wanted %s, got %s", expected, chequebook.ChequeCashoutKey(address, txHash))
 	}
 }
@@ -0,0 +1,93 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	// prefix for the persistence keys of the history of issued cheques
+	chequeHistoryKeyPrefix = "swap_chequebook_cheque_history_"
+	// prefix for the persistence key of the length of the history of a beneficiary
+	chequeHistoryLenKeyPrefix = chequeHistoryKeyPrefix + "len_"
+)
+
+// IssuedCheque is a cheque in the history of the cheques issued to a
+// beneficiary.
+type IssuedCheque struct {
+	Cheque SignedCheque
+	Amount *big.Int // amount the cheque increased the cumulative payout by
+	Time   int64    // unix timestamp when the cheque was sent
+}
+
+func chequeHistoryKey(beneficiary common.Address, index uint64) string {
+	return fmt.Sprintf("%s%x_%020d", chequeHistoryKeyPrefix, beneficiary, index)
+}
+
+func chequeHistoryLenKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", chequeHistoryLenKeyPrefix, beneficiary)
+}
+
+// chequeHistoryLen returns the number of cheques in the history of the beneficiary.
+func (s *service) chequeHistoryLen(ctx context.Context, beneficiary common.Address) (uint64, error) {
+	var length uint64
+	err := s.store.GetContext(ctx, chequeHistoryLenKey(beneficiary), &length)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, err
+	}
+	return length, nil
+}
+
+// appendChequeHistory appends the sent cheque to the history of its
+// beneficiary. The entry is written before the length, so that a failed
+// write leaves no gap and is overwritten by the next cheque. Must be called
+// with the lock held.
+func (s *service) appendChequeHistory(cheque *SignedCheque, amount *big.Int) error {
+	length, err := s.chequeHistoryLen(context.Background(), cheque.Beneficiary)
+	if err != nil {
+		return err
+	}
+	entry := IssuedCheque{
+		Cheque: *cheque,
+		Amount: new(big.Int).Set(amount),
+		Time:   time.Now().Unix(),
+	}
+	if err := s.store.Put(chequeHistoryKey(cheque.Beneficiary, length), &entry); err != nil {
+		return err
+	}
+	return s.store.Put(chequeHistoryLenKey(cheque.Beneficiary), length+1)
+}
+
+// ChequeHistory returns up to limit cheques issued to the beneficiary starting
+// at offset in the order they were issued, together with the total number of
+// cheques issued to it. Cheques issued before the history was kept and
+// imported last cheques are not part of it.
+func (s *service) ChequeHistory(ctx context.Context, beneficiary common.Address, offset, limit uint64) ([]IssuedCheque, uint64, error) {
+	s.issuedMu.RLock()
+	defer s.issuedMu.RUnlock()
+
+	total, err := s.chequeHistoryLen(ctx, beneficiary)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cheques := make([]IssuedCheque, 0)
+	for index := offset; index < total && uint64(len(cheques)) < limit; index++ {
+		var entry IssuedCheque
+		if err := s.store.GetContext(ctx, chequeHistoryKey(beneficiary, index), &entry); err != nil {
+			return nil, 0, fmt.Errorf("cheque history entry %d: %w", index, err)
+		}
+		cheques = append(cheques, entry)
+	}
+	return cheques, total, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequeHistory(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	other := common.HexToAddress("0xeeee")
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if bytes.HasPrefix(request.Data, chequebookABI.Methods["balance"].ID) {
+					return big.NewInt(1000).FillBytes(make([]byte, 32)), nil
+				}
+				return big.NewInt(0).FillBytes(make([]byte, 32)), nil
+			}),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+			return cheque.CumulativePayout.Bytes(), nil
+		}},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(beneficiary common.Address, amount int64, sendErr error) {
+		t.Helper()
+		_, err := chequebookService.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(big.NewInt(amount)), func(*chequebook.SignedCheque) error {
+			return sendErr
+		})
+		if !errors.Is(err, sendErr) {
+			t.Fatalf("got error %v, want %v", err, sendErr)
+		}
+	}
+	issue(beneficiary, 10, nil)
+	issue(other, 5, nil)
+	issue(beneficiary, 20, nil)
+	issue(beneficiary, 30, errors.New("send failed"))
+	issue(beneficiary, 40, nil)
+
+	cheques, total, err := chequebookService.ChequeHistory(context.Background(), beneficiary, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Fatalf("got %d cheques in total, want 3", total)
+	}
+	want := []struct{ amount, cumulativePayout int64 }{{20, 30}, {40, 70}}
+	if len(cheques) != len(want) {
+		t.Fatalf("got %d cheques, want %d", len(cheques), len(want))
+	}
+	for i, w := range want {
+		c := cheques[i]
+		if c.Amount.Int64() != w.amount || c.Cheque.CumulativePayout.Int64() != w.cumulativePayout ||
+			c.Cheque.Beneficiary != beneficiary || c.Cheque.Chequebook != address || c.Time == 0 {
+			t.Fatalf("got cheque %+v, want amount %d cumulative payout %d", c, w.amount, w.cumulativePayout)
+		}
+		if !bytes.Equal(c.Cheque.Signature, big.NewInt(w.cumulativePayout).Bytes()) {
+			t.Fatalf("got signature %x of cheque %d", c.Cheque.Signature, i)
+		}
+	}
+
+	cheques, total, err = chequebookService.ChequeHistory(context.Background(), other, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(cheques) != 1 || cheques[0].Amount.Int64() != 5 {
+		t.Fatalf("got history %+v of %d cheques of the other beneficiary", cheques, total)
+	}
+
+	cheques, total, err = chequebookService.ChequeHistory(context.Background(), common.HexToAddress("0x1"), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 || len(cheques) != 0 {
+		t.Fatalf("got history %+v of %d cheques of an unknown beneficiary", cheques, total)
+	}
+}
@@ -5,15 +5,19 @@
 package chequebook
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
 )
@@ -21,6 +25,8 @@ import (
 const (
 	// prefix for the persistence key
 	lastReceivedChequePrefix = "swap_chequebook_last_received_cheque_"
+	// prefix for the persistence key of the issuers of chequebooks found on chain
+	chequebookIssuerPrefix = "swap_chequebook_issuer_"
 )
 
 var (
@@ -36,71 +42,303 @@ var (
 	ErrBouncingCheque = errors.New("bouncing cheque")
 	// ErrChequeValueTooLow is the error returned if the after deduction value of a cheque did not cover 1 accounting credit
 	ErrChequeValueTooLow = errors.New("cheque value lower than acceptable")
+	// ErrChequebookNotOnChain is the error returned if there is no chequebook contract at the address on the configured chain.
+	ErrChequebookNotOnChain = errors.New("chequebook does not exist on this chain")
+	// ErrWrongIssuer is the error returned if a chequebook is not issued by the expected issuer.
+	ErrWrongIssuer = errors.New("wrong chequebook issuer")
+	// ErrDuplicateCheque is the error returned if the cheque is the last one already received from its chequebook.
+	ErrDuplicateCheque = errors.New("duplicate cheque")
 )
 
 // ChequeStore handles the verification and storage of received cheques
 type ChequeStore interface {
 	// ReceiveCheque verifies and stores a cheque. It returns the total amount earned.
 	ReceiveCheque(ctx context.Context, cheque *SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error)
-	// LastCheque returns the last cheque we received from a specific chequebook.
+	// LastCheque returns the last cheque we received from a specific chequebook for the current beneficiary, or for the most recent previous one if there is none.
 	LastCheque(chequebook common.Address) (*SignedCheque, error)
-	// LastCheques returns the last received cheques from every known chequebook.
+	// LastBeneficiaryCheques returns the last cheques we received from a specific chequebook for every beneficiary.
+	LastBeneficiaryCheques(chequebook common.Address) (map[common.Address]*SignedCheque, error)
+	// LastCheques returns the last received cheques from every known chequebook like LastCheque.
 	LastCheques() (map[common.Address]*SignedCheque, error)
+	// VerifyChequebookIssuer checks that the chequebook was deployed by a trusted factory and is issued by issuer.
+	VerifyChequebookIssuer(ctx context.Context, chequebook, issuer common.Address) error
+	// ImportCheque verifies and stores a cheque received before the state of the node was lost or migrated.
+	ImportCheque(ctx context.Context, cheque *SignedCheque) error
+	// ImportCheques imports many cheques like ImportCheque, verifying them in a batch. It returns the errors in the order of the cheques.
+	ImportCheques(ctx context.Context, cheques []*SignedCheque) []error
+	// RotateBeneficiary makes beneficiary the one expected in received cheques. Cheques to the previous beneficiary are accepted for the grace period.
+	RotateBeneficiary(beneficiary common.Address, grace time.Duration) (*BeneficiaryRotation, error)
+	// Beneficiaries returns the beneficiary expected in received cheques and the previous ones still accepted.
+	Beneficiaries() (*BeneficiaryRotation, error)
+	// ChequeVerifier verifies cheques locally, also for nodes which delegate their verification.
+	ChequeVerifier
+	// DelegateVerification makes the verifier verify received cheques, a nil verifier restores the local verification.
+	DelegateVerification(verifier ChequeVerifier)
+	// SetReputation makes the cheque store skip the liquidity checks of chequebooks trusted by the reputation, nil verifies all cheques fully.
+	SetReputation(reputation *Reputation)
 }
 
 type chequeStore struct {
 	lock               sync.Mutex
-	store              storage.StateStorer
+	store              contextStore
+	rawStore           storage.StateStorer // store without checksum verification, only used to find legacy cheques
 	factory            Factory
 	chaindID           int64
 	transactionService transaction.Service
-	beneficiary        common.Address // the beneficiary we expect in cheques sent to us
+	beneficiary        common.Address // the beneficiary we expect in cheques sent to us until it is rotated
 	recoverChequeFunc  RecoverChequeFunc
+	validators         []namedValidator
+	clock              clock.Clock
+
+	verifierMu sync.Mutex
+	verifier   ChequeVerifier // verifies received cheques instead of the cheque store if set
+	reputation *Reputation    // trusted chequebooks are verified on the fast path if set
+
+	migrateOnce sync.Once
+	migrateErr  error
 }
 
 type RecoverChequeFunc func(cheque *SignedCheque, chainID int64) (common.Address, error)
 
-// NewChequeStore creates new ChequeStore
+// NewChequeStore creates new ChequeStore. Received cheques have to pass the
+// given validators followed by the registered ones.
 func NewChequeStore(
 	store storage.StateStorer,
 	factory Factory,
 	chainID int64,
 	beneficiary common.Address,
 	transactionService transaction.Service,
-	recoverChequeFunc RecoverChequeFunc) ChequeStore {
+	recoverChequeFunc RecoverChequeFunc,
+	validators ...ChequeValidator) ChequeStore {
+	named := make([]namedValidator, 0, len(validators))
+	for _, v := range validators {
+		named = append(named, namedValidator{validator: v})
+	}
 	return &chequeStore{
-		store:              store,
+		store:              contextStore{newChecksumStore(store, receivedChecksumsMigratedKey, lastReceivedChequePrefix)},
+		rawStore:           store,
 		factory:            factory,
 		chaindID:           chainID,
 		transactionService: transactionService,
 		beneficiary:        beneficiary,
 		recoverChequeFunc:  recoverChequeFunc,
+		validators:         append(named, registeredValidators()...),
+		clock:              clock.System,
 	}
 }
 
-// lastReceivedChequeKey computes the key where to store the last cheque received from a chequebook.
-func lastReceivedChequeKey(chequebook common.Address) string {
+// lastReceivedChequeKey computes the key where to store the last cheque
+// received from a chequebook for the beneficiary. The issuer counts the
+// cumulative payout per beneficiary, so the cheques to every beneficiary we
+// rotated through are kept apart.
+func lastReceivedChequeKey(chequebook, beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", receivedChequesPrefix(chequebook), beneficiary)
+}
+
+// receivedChequesPrefix computes the prefix of the keys of the last cheques
+// received from a chequebook.
+func receivedChequesPrefix(chequebook common.Address) string {
+	return fmt.Sprintf("%s_%x_", lastReceivedChequePrefix, chequebook)
+}
+
+// legacyLastReceivedChequeKey computes the key under which the last cheque
+// received from a chequebook was stored before the cheques were kept per
+// beneficiary.
+func legacyLastReceivedChequeKey(chequebook common.Address) string {
 	return fmt.Sprintf("%s_%x", lastReceivedChequePrefix, chequebook)
 }
 
-// LastCheque returns the last cheque we received from a specific chequebook.
-func (s *chequeStore) LastCheque(chequebook common.Address) (*SignedCheque, error) {
-	var cheque *SignedCheque
-	err := s.store.Get(lastReceivedChequeKey(chequebook), &cheque)
+// migrate moves the cheques stored per chequebook to the key of their
+// beneficiary once. Corrupted cheques are left in place, so that they are
+// reported until they are imported again.
+func (s *chequeStore) migrate() error {
+	s.migrateOnce.Do(func() {
+		s.migrateErr = s.migrateLegacyCheques()
+	})
+	return s.migrateErr
+}
+
+func (s *chequeStore) migrateLegacyCheques() error {
+	legacyKeyLen := len(legacyLastReceivedChequeKey(common.Address{}))
+
+	var legacy []string
+	err := s.rawStore.Iterate(lastReceivedChequePrefix, func(key, _ []byte) (bool, error) {
+		if k := string(key); len(k) == legacyKeyLen {
+			legacy = append(legacy, k)
+		}
+		return false, nil
+	})
 	if err != nil {
-		if !errors.Is(err, storage.ErrNotFound) {
-			return nil, err
+		return err
+	}
+
+	for _, key := range legacy {
+		var cheque *SignedCheque
+		err := s.store.Get(key, &cheque)
+		if errors.Is(err, ErrCorruptedRecord) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.store.Put(lastReceivedChequeKey(cheque.Chequebook, cheque.Beneficiary), cheque); err != nil {
+			return err
+		}
+		if err := s.store.Delete(key); err != nil {
+			return err
 		}
-		return nil, ErrNoCheque
 	}
+	return nil
+}
 
+// lastReceivedCheque returns the stored last cheque received from the
+// chequebook for the beneficiary.
+func (s *chequeStore) lastReceivedCheque(ctx context.Context, chequebook, beneficiary common.Address) (*SignedCheque, error) {
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	var cheque *SignedCheque
+	if err := s.store.GetContext(ctx, lastReceivedChequeKey(chequebook, beneficiary), &cheque); err != nil {
+		return nil, err
+	}
 	return cheque, nil
 }
 
+// chequebookIssuerKey computes the key where to store the issuer of a chequebook.
+func chequebookIssuerKey(chequebook common.Address) string {
+	return fmt.Sprintf("%s%x", chequebookIssuerPrefix, chequebook)
+}
+
+// chequebookIssuer returns the issuer of the chequebook on the configured
+// chain. As the issuer of a chequebook cannot change, it is only queried once
+// and then kept in the store. Chequebooks which do not exist are not cached as
+// they might still be deployed.
+func (s *chequeStore) chequebookIssuer(ctx context.Context, contract *chequebookContract) (common.Address, error) {
+	var issuer common.Address
+	err := s.store.GetContext(ctx, chequebookIssuerKey(contract.address), &issuer)
+	if err == nil {
+		return issuer, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return common.Address{}, err
+	}
+
+	issuer, err = contract.Issuer(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if err := s.store.PutContext(ctx, chequebookIssuerKey(contract.address), issuer); err != nil {
+		return common.Address{}, err
+	}
+	return issuer, nil
+}
+
+// VerifyChequebookIssuer checks that the chequebook was deployed by a trusted
+// factory and is issued by issuer.
+func (s *chequeStore) VerifyChequebookIssuer(ctx context.Context, chequebook, issuer common.Address) error {
+	if err := s.factory.VerifyChequebook(ctx, chequebook); err != nil {
+		return err
+	}
+
+	actual, err := s.chequebookIssuer(ctx, newChequebookContract(chequebook, s.transactionService))
+	if err != nil {
+		return err
+	}
+	if actual != issuer {
+		return ErrWrongIssuer
+	}
+	return nil
+}
+
+// LastCheque returns the last cheque we received from a specific chequebook
+// for the current beneficiary. If there is none, it returns the one for the
+// beneficiary we rotated away from most recently.
+func (s *chequeStore) LastCheque(chequebook common.Address) (*SignedCheque, error) {
+	rotation, err := s.beneficiaryRotation()
+	if err != nil {
+		return nil, err
+	}
+
+	cheque, err := s.lastReceivedCheque(context.Background(), chequebook, rotation.Current)
+	if err == nil {
+		return cheque, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	cheques, err := s.LastBeneficiaryCheques(chequebook)
+	if err != nil {
+		return nil, err
+	}
+	if len(cheques) == 0 {
+		return nil, ErrNoCheque
+	}
+	return preferredCheque(cheques, rotation), nil
+}
+
+// LastBeneficiaryCheques returns the last cheques we received from a specific
+// chequebook for every beneficiary.
+func (s *chequeStore) LastBeneficiaryCheques(chequebook common.Address) (map[common.Address]*SignedCheque, error) {
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	prefix := receivedChequesPrefix(chequebook)
+	return loadCheques(context.Background(), s.store, prefix, func(key []byte) (common.Address, error) {
+		return keyChequebook(key, prefix)
+	})
+}
+
+// preferredCheque returns the cheque for the current beneficiary of the
+// rotation, else the one for the most recent previous beneficiary. Cheques
+// for beneficiaries whose grace period ended are picked in a fixed order.
+func preferredCheque(cheques map[common.Address]*SignedCheque, rotation *BeneficiaryRotation) *SignedCheque {
+	if cheque, ok := cheques[rotation.Current]; ok {
+		return cheque
+	}
+	for i := len(rotation.Previous) - 1; i >= 0; i-- {
+		if cheque, ok := cheques[rotation.Previous[i].Beneficiary]; ok {
+			return cheque
+		}
+	}
+	beneficiaries := make([]common.Address, 0, len(cheques))
+	for beneficiary := range cheques {
+		beneficiaries = append(beneficiaries, beneficiary)
+	}
+	sort.Slice(beneficiaries, func(i, j int) bool {
+		return bytes.Compare(beneficiaries[i].Bytes(), beneficiaries[j].Bytes()) < 0
+	})
+	return cheques[beneficiaries[0]]
+}
+
 // ReceiveCheque verifies and stores a cheque. It returns the totam amount earned.
+// A rejected cheque resets the reputation of its chequebook. A resent copy of
+// the last received cheque is not credited again and fails with
+// ErrDuplicateCheque without affecting the reputation.
 func (s *chequeStore) ReceiveCheque(ctx context.Context, cheque *SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error) {
+	amount, err := s.receiveCheque(ctx, cheque, exchangeRate, deduction)
+	if err != nil {
+		if errors.Is(err, ErrDuplicateCheque) {
+			return nil, err
+		}
+		if reputation := s.chequeReputation(); reputation != nil {
+			if err := reputation.Anomaly(cheque.Chequebook); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	return amount, nil
+}
+
+func (s *chequeStore) receiveCheque(ctx context.Context, cheque *SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error) {
 	// verify we are the beneficiary
-	if cheque.Beneficiary != s.beneficiary {
+	accepted, err := s.acceptsBeneficiary(cheque.Beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
 		return nil, ErrWrongBeneficiary
 	}
 
@@ -109,31 +347,38 @@ func (s *chequeStore) ReceiveCheque(ctx context.Context, cheque *SignedCheque, e
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	// load the lastCumulativePayout for the cheques chequebook
+	// load the lastCumulativePayout for the cheques chequebook and beneficiary
 	var lastCumulativePayout *big.Int
-	var lastReceivedCheque *SignedCheque
-	err := s.store.Get(lastReceivedChequeKey(cheque.Chequebook), &lastReceivedCheque)
+	lastReceivedCheque, err := s.lastReceivedCheque(ctx, cheque.Chequebook, cheque.Beneficiary)
 	if err != nil {
 		if !errors.Is(err, storage.ErrNotFound) {
 			return nil, err
 		}
-
-		// if this is the first cheque from this chequebook, verify with the factory.
-		err = s.factory.VerifyChequebook(ctx, cheque.Chequebook)
-		if err != nil {
-			return nil, err
-		}
-
-		lastCumulativePayout = big.NewInt(0)
 	} else {
+		if cheque.CumulativePayout != nil && lastReceivedCheque.Equal(cheque) {
+			return nil, ErrDuplicateCheque
+		}
 		lastCumulativePayout = lastReceivedCheque.CumulativePayout
 	}
 
-	// check this cheque is actually increasing in value
-	amount := big.NewInt(0).Sub(cheque.CumulativePayout, lastCumulativePayout)
+	// cheques of trusted chequebooks are verified locally and their liquidity
+	// is only queried again once the last known one does not cover them
+	reputation := s.chequeReputation()
+	trusted := reputation != nil && lastCumulativePayout != nil && cheque.CumulativePayout != nil &&
+		reputation.Trusted(cheque.Chequebook, cheque.CumulativePayout)
+	verifier := s.chequeVerifier()
+	if trusted {
+		verifier = s
+	}
 
-	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return nil, ErrChequeNotIncreasing
+	// if this is the first cheque from this chequebook, it is also verified with the factory.
+	if err := verifier.VerifyCheque(ctx, cheque, lastCumulativePayout); err != nil {
+		return nil, err
+	}
+
+	amount := new(big.Int).Set(cheque.CumulativePayout)
+	if lastCumulativePayout != nil {
+		amount.Sub(amount, lastCumulativePayout)
 	}
 
 	deducedAmount := new(big.Int).Sub(amount, deduction)
@@ -142,48 +387,73 @@ func (s *chequeStore) ReceiveCheque(ctx context.Context, cheque *SignedCheque, e
 		return nil, ErrChequeValueTooLow
 	}
 
-	// blockchain calls below
-	contract := newChequebookContract(cheque.Chequebook, s.transactionService)
+	var balance, alreadyPaidOut *big.Int
+	if !trusted {
+		// blockchain calls below
+		contract := newChequebookContract(cheque.Chequebook, s.transactionService)
 
-	// this does not change for the same chequebook
-	expectedIssuer, err := contract.Issuer(ctx)
-	if err != nil {
-		return nil, err
-	}
+		// basic liquidity check
+		// could be omitted as it is not particularly useful
+		balance, err = contract.Balance(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	// verify the cheque signature
-	issuer, err := s.recoverChequeFunc(cheque, s.chaindID)
-	if err != nil {
-		return nil, err
-	}
+		alreadyPaidOut, err = contract.PaidOut(ctx, cheque.Beneficiary)
+		if err != nil {
+			return nil, err
+		}
 
-	if issuer != expectedIssuer {
-		return nil, ErrChequeInvalid
+		if balance.Cmp(big.NewInt(0).Sub(cheque.CumulativePayout, alreadyPaidOut)) < 0 {
+			return nil, ErrBouncingCheque
+		}
 	}
 
-	// basic liquidity check
-	// could be omitted as it is not particularly useful
-	balance, err := contract.Balance(ctx)
-	if err != nil {
+	// the cheque is valid, the validators decide whether it is trusted
+	if err := validateCheque(ctx, s.validators, cheque, amount); err != nil {
 		return nil, err
 	}
 
-	alreadyPaidOut, err := contract.PaidOut(ctx, s.beneficiary)
+	// store the accepted cheque
+	err = s.store.PutContext(ctx, lastReceivedChequeKey(cheque.Chequebook, cheque.Beneficiary), cheque)
 	if err != nil {
 		return nil, err
 	}
 
-	if balance.Cmp(big.NewInt(0).Sub(cheque.CumulativePayout, alreadyPaidOut)) < 0 {
-		return nil, ErrBouncingCheque
+	if reputation != nil && !trusted {
+		if err := reputation.Verified(cheque.Chequebook, balance, alreadyPaidOut); err != nil {
+			return nil, err
+		}
 	}
 
-	// store the accepted cheque
-	err = s.store.Put(lastReceivedChequeKey(cheque.Chequebook), cheque)
+	return amount, nil
+}
+
+// verifySignature checks that the cheque is signed by the issuer of its chequebook.
+func (s *chequeStore) verifySignature(ctx context.Context, contract *chequebookContract, cheque *SignedCheque) error {
+	// a cheque naming a chequebook which only exists on another chain must
+	// not be credited, even if it is signed by the issuer of that chequebook
+	expectedIssuer, err := s.chequebookIssuer(ctx, contract)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return amount, nil
+	issuer, err := s.recoverChequeFunc(cheque, s.chaindID)
+	if err != nil || issuer != expectedIssuer {
+		// the issuer might be a contract wallet signing through EIP-1271
+		valid, contractErr := IsValidContractSignature(ctx, s.transactionService, expectedIssuer, cheque, s.chaindID)
+		if contractErr != nil {
+			// a reverting isValidSignature call means the signature is not valid
+			return fmt.Errorf("contract signature: %v: %w", contractErr, ErrChequeInvalid)
+		}
+		if !valid {
+			if err != nil {
+				return err
+			}
+			return ErrChequeInvalid
+		}
+	}
+	return nil
 }
 
 // RecoverCheque recovers the issuer ethereum address from a signed cheque
@@ -205,40 +475,45 @@ func RecoverCheque(cheque *SignedCheque, chaindID int64) (common.Address, error)
 	return issuer, nil
 }
 
-// keyChequebook computes the chequebook a store entry is for.
+// keyChequebook computes the address following the prefix in a store entry,
+// the chequebook or the beneficiary of the cheque.
 func keyChequebook(key []byte, prefix string) (chequebook common.Address, err error) {
 	k := string(key)
 
 	split := strings.SplitAfter(k, prefix)
-	if len(split) != 2 {
+	if len(split) != 2 || len(split[1]) < 2*common.AddressLength {
 		return common.Address{}, errors.New("no peer in key")
 	}
-	return common.HexToAddress(split[1]), nil
+	return common.HexToAddress(split[1][:2*common.AddressLength]), nil
 }
 
-// LastCheques returns the last received cheques from every known chequebook.
+// LastCheques returns the last received cheques from every known chequebook,
+// for every chequebook the one LastCheque returns.
 func (s *chequeStore) LastCheques() (map[common.Address]*SignedCheque, error) {
-	result := make(map[common.Address]*SignedCheque)
-	err := s.store.Iterate(lastReceivedChequePrefix, func(key, val []byte) (stop bool, err error) {
-		addr, err := keyChequebook(key, lastReceivedChequePrefix+"_")
-		if err != nil {
-			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
-		}
-
-		if _, ok := result[addr]; !ok {
-			lastCheque, err := s.LastCheque(addr)
-			if err != nil && !errors.Is(err, ErrNoCheque) {
-				return false, err
-			} else if errors.Is(err, ErrNoCheque) {
-				return false, nil
-			}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	rotation, err := s.beneficiaryRotation()
+	if err != nil {
+		return nil, err
+	}
 
-			result[addr] = lastCheque
+	received := make(map[common.Address]map[common.Address]*SignedCheque)
+	err = decodeCheques(context.Background(), s.store, lastReceivedChequePrefix, func(key []byte) (common.Address, error) {
+		return keyChequebook(key, lastReceivedChequePrefix+"_")
+	}, func(chequebook common.Address, cheque *SignedCheque) {
+		if received[chequebook] == nil {
+			received[chequebook] = make(map[common.Address]*SignedCheque)
 		}
-		return false, nil
+		received[chequebook][cheque.Beneficiary] = cheque
 	})
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+
+	cheques := make(map[common.Address]*SignedCheque, len(received))
+	for chequebook, beneficiaryCheques := range received {
+		cheques[chequebook] = preferredCheque(beneficiaryCheques, rotation)
+	}
+	return cheques, nil
 }
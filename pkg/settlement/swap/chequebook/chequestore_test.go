@@ -60,7 +60,7 @@ func TestReceiveCheque(t *testing.T) {
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, cumulativePayout2.FillBytes(make([]byte, 32)), "balance"),
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
-				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				// the issuer is not queried again
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, cumulativePayout2.FillBytes(make([]byte, 32)), "balance"),
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
 			),
@@ -122,6 +122,64 @@ func TestReceiveCheque(t *testing.T) {
 	}
 }
 
+func TestReceiveChequeDuplicate(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	cumulativePayout := big.NewInt(101)
+	chequebookAddress := common.HexToAddress("0xeeee")
+	chainID := int64(1)
+	exchangeRate := big.NewInt(10)
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: cumulativePayout,
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	chequestore := chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		chainID,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, cumulativePayout.FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				// the duplicate is not verified on chain again
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque, exchangeRate, big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := chequestore.ReceiveCheque(context.Background(), cheque, exchangeRate, big.NewInt(0))
+	if !errors.Is(err, chequebook.ErrDuplicateCheque) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrDuplicateCheque, err)
+	}
+
+	// a different cheque with the same cumulative payout is not a duplicate
+	forged := &chequebook.SignedCheque{Cheque: cheque.Cheque, Signature: append([]byte{1}, make([]byte, 64)...)}
+	_, err = chequestore.ReceiveCheque(context.Background(), forged, exchangeRate, big.NewInt(0))
+	if !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrChequeNotIncreasing, err)
+	}
+}
+
 func TestReceiveChequeInvalidBeneficiary(t *testing.T) {
 	t.Parallel()
 
@@ -263,6 +321,49 @@ func TestReceiveChequeInvalidChequebook(t *testing.T) {
 	}
 }
 
+func TestReceiveChequeChequebookNotOnChain(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(101),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(
+			// there is no contract at the address on this chain
+			transactionmock.WithABICall(&chequebookABI, chequebookAddress, nil, "issuer"),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	_, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(10), big.NewInt(0))
+	if !errors.Is(err, chequebook.ErrChequebookNotOnChain) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequebookNotOnChain)
+	}
+
+	if _, err := chequestore.LastCheque(chequebookAddress); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+}
+
 func TestReceiveChequeInvalidSignature(t *testing.T) {
 	t.Parallel()
 
@@ -274,6 +375,22 @@ func TestReceiveChequeInvalidSignature(t *testing.T) {
 	sig := make([]byte, 65)
 	chainID := int64(1)
 
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: cumulativePayout,
+			Chequebook:       chequebookAddress,
+		},
+		Signature: sig,
+	}
+
+	chequeHash, err := chequebook.ChequeHash(&cheque.Cheque, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digest [32]byte
+	copy(digest[:], chequeHash)
+
 	chequestore := chequebook.NewChequeStore(
 		store,
 		&factoryMock{
@@ -286,23 +403,91 @@ func TestReceiveChequeInvalidSignature(t *testing.T) {
 		transactionmock.New(
 			transactionmock.WithABICallSequence(
 				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				// the issuer is not a contract, the call returns no data
+				transactionmock.ABICall(&chequebook.EIP1271ABI, issuer, nil, "isValidSignature", digest, sig),
 			),
 		),
 		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
 			return common.Address{}, nil
 		})
 
-	_, err := chequestore.ReceiveCheque(context.Background(), &chequebook.SignedCheque{
+	_, err = chequestore.ReceiveCheque(context.Background(), cheque, cumulativePayout, big.NewInt(0))
+	if !errors.Is(err, chequebook.ErrChequeInvalid) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrChequeInvalid, err)
+	}
+}
+
+func TestReceiveChequeContractSignature(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	cumulativePayout := big.NewInt(10)
+	chequebookAddress := common.HexToAddress("0xeeee")
+	// contract signatures can have any format, e.g. concatenated owner signatures
+	sig := make([]byte, 130)
+	chainID := int64(1)
+
+	cheque := &chequebook.SignedCheque{
 		Cheque: chequebook.Cheque{
 			Beneficiary:      beneficiary,
 			CumulativePayout: cumulativePayout,
 			Chequebook:       chequebookAddress,
 		},
 		Signature: sig,
-	}, cumulativePayout, big.NewInt(0))
-	if !errors.Is(err, chequebook.ErrChequeInvalid) {
-		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrChequeInvalid, err)
 	}
+
+	chequeHash, err := chequebook.ChequeHash(&cheque.Cheque, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digest [32]byte
+	copy(digest[:], chequeHash)
+
+	newChequeStore := func(result []byte) chequebook.ChequeStore {
+		return chequebook.NewChequeStore(
+			storemock.NewStateStore(),
+			&factoryMock{
+				verifyChequebook: func(ctx context.Context, address common.Address) error {
+					return nil
+				},
+			},
+			chainID,
+			beneficiary,
+			transactionmock.New(
+				transactionmock.WithABICallSequence(
+					transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+					transactionmock.ABICall(&chequebook.EIP1271ABI, issuer, result, "isValidSignature", digest, sig),
+					transactionmock.ABICall(&chequebookABI, chequebookAddress, cumulativePayout.FillBytes(make([]byte, 32)), "balance"),
+					transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				),
+			),
+			func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+				return common.Address{}, errors.New("invalid signature length")
+			})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		magicValue := common.RightPadBytes(common.FromHex("0x1626ba7e"), 32)
+		received, err := newChequeStore(magicValue).ReceiveCheque(context.Background(), cheque, cumulativePayout, big.NewInt(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if received.Cmp(cumulativePayout) != 0 {
+			t.Fatalf("calculated wrong received cumulativePayout. wanted %d, got %d", cumulativePayout, received)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newChequeStore(make([]byte, 32)).ReceiveCheque(context.Background(), cheque, cumulativePayout, big.NewInt(0))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
 }
 
 func TestReceiveChequeInsufficientBalance(t *testing.T) {
@@ -515,3 +700,42 @@ func TestReceiveChequeNotEnoughValueAfterDeduction(t *testing.T) {
 		t.Fatalf("got wrong error. wanted %v, got %v", chequebook.ErrChequeValueTooLow, err)
 	}
 }
+
+func TestVerifyChequebookIssuer(t *testing.T) {
+	t.Parallel()
+
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	var verifiedWithFactory bool
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				verifiedWithFactory = true
+				return nil
+			},
+		},
+		1,
+		common.HexToAddress("0xffff"),
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+			),
+		),
+		chequebook.RecoverCheque,
+	)
+
+	if err := chequestore.VerifyChequebookIssuer(context.Background(), chequebookAddress, issuer); err != nil {
+		t.Fatal(err)
+	}
+	if !verifiedWithFactory {
+		t.Fatal("did not verify with factory")
+	}
+
+	// the issuer is cached
+	err := chequestore.VerifyChequebookIssuer(context.Background(), chequebookAddress, common.HexToAddress("0xbeef"))
+	if !errors.Is(err, chequebook.ErrWrongIssuer) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrWrongIssuer)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package chequetest provides deterministic test vectors for cheque signing.
+
+A vector is a canonical cheque signed with a well-known key for a chain ID and
+chequebook contract version together with its EIP712 digest and signature.
+The vectors are kept as a golden file so that accidental changes of the
+digest format are caught and other implementations can check that they sign
+cheques compatibly:
+
+	vectors, err := chequetest.Generate(chequetest.ChainIDs, chequetest.ContractVersions)
+	// ...
+	for _, v := range vectors {
+		if err := chequetest.Verify(v); err != nil {
+			// ...
+		}
+	}
+*/
+package chequetest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+var (
+	// ErrDigestMismatch is the error returned if the digest of a vector differs
+	// from the digest computed for its cheque.
+	ErrDigestMismatch = errors.New("cheque digest mismatch")
+	// ErrSignatureMismatch is the error returned if the signature of a vector
+	// differs from the signature computed with its issuer key.
+	ErrSignatureMismatch = errors.New("cheque signature mismatch")
+	// ErrIssuerMismatch is the error returned if the signature of a vector does
+	// not recover to its issuer.
+	ErrIssuerMismatch = errors.New("cheque issuer mismatch")
+)
+
+var (
+	// ChainIDs are the chain IDs vectors are generated for by default: the
+	// ethereum mainnet, the goerli testnet and the gnosis chain.
+	ChainIDs = []int64{1, 5, 100}
+	// ContractVersions are the chequebook contract versions vectors are
+	// generated for by default.
+	ContractVersions = []string{chequebook.ContractVersionv0_3_1, chequebook.ContractVersionv0_4_0}
+)
+
+// issuerKeys are the well-known private keys the canonical cheques are signed
+// with. They must never be used for anything but tests.
+var issuerKeys = []string{
+	"634fb5a872396d9693e5c9f9d7233cfa93f395c093371017ff44aa9ae6564cdd",
+	"0c7ea2a2f9e1fe7f0b5bb4b3f1b9ef9d4c1b6f0e5a0f1a7f3c1e4e2d7b8a9c0d",
+}
+
+// CanonicalCheque is a cheque signed with one of the issuer keys.
+type CanonicalCheque struct {
+	Name      string
+	IssuerKey int // index of the issuer key
+	Cheque    chequebook.Cheque
+}
+
+// Cheques returns the canonical cheques. They cover a zero, a small, a typical
+// and the largest cumulative payout as well as several issuers.
+func Cheques() []CanonicalCheque {
+	chequebookAddress := common.HexToAddress("0xfa02D396842E6e1D319E8E3D4D870338F791AA25")
+	beneficiary := common.HexToAddress("0x98E6C644aFeB94BBfB9FF60EB26fc9D83BBEcA79")
+	maxPayout := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	typicalPayout, _ := new(big.Int).SetString("10000000000000000", 10)
+
+	return []CanonicalCheque{
+		{Name: "zero", Cheque: chequebook.Cheque{Chequebook: chequebookAddress, Beneficiary: beneficiary, CumulativePayout: big.NewInt(0)}},
+		{Name: "small", Cheque: chequebook.Cheque{Chequebook: chequebookAddress, Beneficiary: beneficiary, CumulativePayout: big.NewInt(500)}},
+		{Name: "typical", Cheque: chequebook.Cheque{Chequebook: chequebookAddress, Beneficiary: beneficiary, CumulativePayout: typicalPayout}},
+		{Name: "max", Cheque: chequebook.Cheque{Chequebook: chequebookAddress, Beneficiary: beneficiary, CumulativePayout: maxPayout}},
+		{
+			Name:      "other-issuer",
+			IssuerKey: 1,
+			Cheque: chequebook.Cheque{
+				Chequebook:       common.HexToAddress("0x8d3766440f0d7b949a5e32995d09619a7f86e632"),
+				Beneficiary:      common.HexToAddress("0xb8d424e9662fe0837fb1d728f1ac97cebb1085fe"),
+				CumulativePayout: big.NewInt(1),
+			},
+		},
+	}
+}
+
+// Vector is a canonical cheque together with its digest and signature for a
+// chain ID and chequebook contract version.
+type Vector struct {
+	Name             string         `json:"name"`
+	ChainID          int64          `json:"chainId"`
+	ContractVersion  string         `json:"contractVersion"`
+	IssuerKey        hexutil.Bytes  `json:"issuerKey"`
+	Issuer           common.Address `json:"issuer"`
+	Chequebook       common.Address `json:"chequebook"`
+	Beneficiary      common.Address `json:"beneficiary"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Digest           hexutil.Bytes  `json:"digest"`
+	Signature        hexutil.Bytes  `json:"signature"`
+}
+
+// SignedCheque returns the signed cheque of the vector.
+func (v *Vector) SignedCheque() *chequebook.SignedCheque {
+	return &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       v.Chequebook,
+			Beneficiary:      v.Beneficiary,
+			CumulativePayout: v.CumulativePayout.Int,
+		},
+		Signature: v.Signature,
+	}
+}
+
+// Generate signs the canonical cheques for every chain ID and contract
+// version. The result only depends on its arguments.
+func Generate(chainIDs []int64, contractVersions []string) ([]Vector, error) {
+	var vectors []Vector
+	for _, chainID := range chainIDs {
+		for _, version := range contractVersions {
+			for _, c := range Cheques() {
+				v, err := NewVector(c, chainID, version)
+				if err != nil {
+					return nil, fmt.Errorf("vector %s for chain %d and version %s: %w", c.Name, chainID, version, err)
+				}
+				vectors = append(vectors, v)
+			}
+		}
+	}
+	return vectors, nil
+}
+
+// NewVector signs the canonical cheque for the chain ID and contract version.
+func NewVector(c CanonicalCheque, chainID int64, contractVersion string) (Vector, error) {
+	if c.IssuerKey < 0 || c.IssuerKey >= len(issuerKeys) {
+		return Vector{}, fmt.Errorf("unknown issuer key %d", c.IssuerKey)
+	}
+	key, err := hex.DecodeString(issuerKeys[c.IssuerKey])
+	if err != nil {
+		return Vector{}, err
+	}
+	signature, issuer, err := sign(key, &c.Cheque, chainID)
+	if err != nil {
+		return Vector{}, err
+	}
+	digest, err := chequebook.ChequeHash(&c.Cheque, chainID)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	return Vector{
+		Name:             c.Name,
+		ChainID:          chainID,
+		ContractVersion:  contractVersion,
+		IssuerKey:        key,
+		Issuer:           issuer,
+		Chequebook:       c.Cheque.Chequebook,
+		Beneficiary:      c.Cheque.Beneficiary,
+		CumulativePayout: bigint.Wrap(new(big.Int).Set(c.Cheque.CumulativePayout)),
+		Digest:           digest,
+		Signature:        signature,
+	}, nil
+}
+
+// Verify checks the vector against this implementation: the digest of its
+// cheque, the signature made with its issuer key and the issuer recovered from
+// the signature all have to match.
+func Verify(v Vector) error {
+	cheque := v.SignedCheque()
+
+	digest, err := chequebook.ChequeHash(&cheque.Cheque, v.ChainID)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, v.Digest) {
+		return fmt.Errorf("%w: got %x, want %x", ErrDigestMismatch, digest, []byte(v.Digest))
+	}
+
+	signature, _, err := sign(v.IssuerKey, &cheque.Cheque, v.ChainID)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signature, v.Signature) {
+		return fmt.Errorf("%w: got %x, want %x", ErrSignatureMismatch, signature, []byte(v.Signature))
+	}
+
+	issuer, err := chequebook.RecoverCheque(cheque, v.ChainID)
+	if err != nil {
+		return err
+	}
+	if issuer != v.Issuer {
+		return fmt.Errorf("%w: got %x, want %x", ErrIssuerMismatch, issuer, v.Issuer)
+	}
+	return nil
+}
+
+// ReadVectors reads vectors written by WriteVectors.
+func ReadVectors(r io.Reader) ([]Vector, error) {
+	var vectors []Vector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// WriteVectors writes the vectors as indented json as used for golden files.
+func WriteVectors(w io.Writer, vectors []Vector) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vectors)
+}
+
+func sign(key []byte, cheque *chequebook.Cheque, chainID int64) ([]byte, common.Address, error) {
+	privKey, err := crypto.DecodeSecp256k1PrivateKey(key)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	signature, err := chequebook.NewChequeSigner(signer, chainID).Sign(cheque)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return signature, issuer, nil
+}
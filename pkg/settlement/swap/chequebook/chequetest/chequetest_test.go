@@ -0,0 +1,96 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequetest_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/chequetest"
+)
+
+var update = flag.Bool("update", false, "update the golden file")
+
+var goldenFile = filepath.Join("testdata", "cheques.golden.json")
+
+func TestGolden(t *testing.T) {
+	t.Parallel()
+
+	vectors, err := chequetest.Generate(chequetest.ChainIDs, chequetest.ContractVersions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := chequetest.WriteVectors(&buf, vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenFile, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Fatalf("generated vectors differ from %s, the cheque digest format changed; run the test with -update if this is intended", goldenFile)
+	}
+
+	goldenVectors, err := chequetest.ReadVectors(bytes.NewReader(golden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goldenVectors) != len(chequetest.ChainIDs)*len(chequetest.ContractVersions)*len(chequetest.Cheques()) {
+		t.Fatalf("got %d vectors", len(goldenVectors))
+	}
+	for _, v := range goldenVectors {
+		if err := chequetest.Verify(v); err != nil {
+			t.Fatalf("vector %s for chain %d and version %s: %v", v.Name, v.ChainID, v.ContractVersion, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	vectors, err := chequetest.Generate([]int64{1}, chequetest.ContractVersions[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// computed using ganache
+	want := "171b63fc598ae2c7987f4a756959dadddd84ccd2071e7b5c3aa3437357be47286125edc370c344a163ba7f4183dfd3611996274a13e4b3496610fc00c0e2fc421c"
+	if got := hex.EncodeToString(vectors[1].Signature); got != want {
+		t.Fatalf("got signature %s, want %s", got, want)
+	}
+
+	v := vectors[1]
+	v.Digest = append([]byte(nil), v.Digest...)
+	v.Digest[0] ^= 1
+	if err := chequetest.Verify(v); !errors.Is(err, chequetest.ErrDigestMismatch) {
+		t.Fatalf("got error %v, want %v", err, chequetest.ErrDigestMismatch)
+	}
+
+	v = vectors[1]
+	v.ChainID = 5
+	if err := chequetest.Verify(v); !errors.Is(err, chequetest.ErrDigestMismatch) {
+		t.Fatalf("got error %v, want %v", err, chequetest.ErrDigestMismatch)
+	}
+
+	v = vectors[1]
+	v.Issuer = vectors[len(vectors)-1].Issuer
+	if err := chequetest.Verify(v); !errors.Is(err, chequetest.ErrIssuerMismatch) {
+		t.Fatalf("got error %v, want %v", err, chequetest.ErrIssuerMismatch)
+	}
+}
@@ -10,8 +10,17 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
 )
 
+// withPaidOut answers the paidOut queries of chequebooks with the amount.
+func withPaidOut(paidOut *big.Int) transactionmock.Option {
+	return transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+		return chequebookABI.Methods["paidOut"].Outputs.Pack(paidOut)
+	})
+}
+
 type chequeSignerMock struct {
 	sign func(cheque *chequebook.Cheque) ([]byte, error)
 }
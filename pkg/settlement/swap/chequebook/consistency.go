@@ -0,0 +1,205 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrTotalIssuedInconsistent is the error returned by Issue while the total
+	// issued counter diverges from the issued cheques.
+	ErrTotalIssuedInconsistent = errors.New("total issued inconsistent with issued cheques")
+	// ErrConsistencyUnsupported is the error returned if the chequebook service
+	// cannot compare the total issued counter with the issued cheques.
+	ErrConsistencyUnsupported = errors.New("total issued consistency check not supported")
+)
+
+// TotalIssuedCheck is the comparison of the stored total issued counter with
+// the sum of the cumulative payouts of the last cheques of all beneficiaries.
+type TotalIssuedCheck struct {
+	Stored     *big.Int // total issued counter
+	Computed   *big.Int // sum of the last cheques
+	Difference *big.Int // stored minus computed
+	Tolerance  *big.Int // largest accepted absolute difference
+	Consistent bool     // whether the difference is within the tolerance
+	Overridden bool     // whether an operator allowed issuing despite the difference
+}
+
+// totalIssuedChecker is implemented by the chequebook service.
+type totalIssuedChecker interface {
+	checkTotalIssued(ctx context.Context) (stored, computed *big.Int, err error)
+	reconcileTotalIssued(ctx context.Context) (*big.Int, error)
+}
+
+// checkTotalIssued returns the stored total issued counter and the sum of the
+// last cheques of all beneficiaries.
+func (s *service) checkTotalIssued(ctx context.Context) (stored, computed *big.Int, err error) {
+	snapshot, err := s.LastChequesSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	computed = big.NewInt(0)
+	for _, cheque := range snapshot.Cheques {
+		computed.Add(computed, cheque.CumulativePayout)
+	}
+	return snapshot.TotalIssued, computed, nil
+}
+
+// reconcileTotalIssued replaces the total issued counter with the sum of the
+// last cheques of all beneficiaries.
+func (s *service) reconcileTotalIssued(ctx context.Context) (*big.Int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.issuedMu.Lock()
+	defer s.issuedMu.Unlock()
+	return s.repairTotalIssued(ctx)
+}
+
+// ConsistencyGuard is a Service which refuses to issue cheques while the
+// total issued counter diverges from the issued cheques by more than a
+// tolerance. A counter lower than the issued cheques overstates the available
+// balance and would let the node issue cheques its chequebook cannot cover.
+type ConsistencyGuard struct {
+	Service
+	checker   totalIssuedChecker
+	tolerance *big.Int
+
+	mu         sync.Mutex
+	check      *TotalIssuedCheck // result of the last check
+	overridden bool
+}
+
+// NewConsistencyGuard wraps the chequebook service and checks the consistency
+// of its total issued counter once. Issuing fails with
+// ErrTotalIssuedInconsistent if the check fails until the counter is
+// reconciled or an operator overrides the check.
+func NewConsistencyGuard(ctx context.Context, service Service, tolerance *big.Int) (*ConsistencyGuard, error) {
+	checker, ok := service.(totalIssuedChecker)
+	if !ok {
+		return nil, ErrConsistencyUnsupported
+	}
+	g := &ConsistencyGuard{
+		Service:   service,
+		checker:   checker,
+		tolerance: new(big.Int).Set(tolerance),
+	}
+	if _, err := g.Check(ctx); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// newTotalIssuedCheck compares the stored and computed totals. It must be
+// called with the lock held.
+func (g *ConsistencyGuard) newTotalIssuedCheck(stored, computed *big.Int) *TotalIssuedCheck {
+	difference := new(big.Int).Sub(stored, computed)
+	return &TotalIssuedCheck{
+		Stored:     stored,
+		Computed:   computed,
+		Difference: difference,
+		Tolerance:  new(big.Int).Set(g.tolerance),
+		Consistent: new(big.Int).Abs(difference).Cmp(g.tolerance) <= 0,
+		Overridden: g.overridden,
+	}
+}
+
+// Check compares the total issued counter with the issued cheques again. A
+// consistent result allows issuing again.
+func (g *ConsistencyGuard) Check(ctx context.Context) (*TotalIssuedCheck, error) {
+	stored, computed, err := g.checker.checkTotalIssued(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// an override only lasts as long as the inconsistency
+	if g.check = g.newTotalIssuedCheck(stored, computed); g.check.Consistent {
+		g.overridden = false
+		g.check.Overridden = false
+	}
+	return g.check, nil
+}
+
+// LastCheck returns the result of the last check.
+func (g *ConsistencyGuard) LastCheck() *TotalIssuedCheck {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	check := *g.check
+	check.Overridden = g.overridden
+	return &check
+}
+
+// Reconcile replaces the total issued counter with the sum of the issued
+// cheques, which allows issuing again.
+func (g *ConsistencyGuard) Reconcile(ctx context.Context) (*TotalIssuedCheck, error) {
+	if _, err := g.checker.reconcileTotalIssued(ctx); err != nil {
+		return nil, fmt.Errorf("reconcile: %w", err)
+	}
+	return g.Check(ctx)
+}
+
+// Override allows issuing cheques despite an inconsistent total issued counter
+// until a check finds it consistent again.
+func (g *ConsistencyGuard) Override() *TotalIssuedCheck {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.overridden = true
+	check := *g.check
+	check.Overridden = true
+	return &check
+}
+
+func (g *ConsistencyGuard) Issue(ctx context.Context, beneficiary common.Address, amount Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+	g.mu.Lock()
+	check := g.check
+	blocked := !check.Consistent && !g.overridden
+	g.mu.Unlock()
+
+	if blocked {
+		return nil, fmt.Errorf("%w: stored %d, issued cheques %d", ErrTotalIssuedInconsistent, check.Stored, check.Computed)
+	}
+	return g.Service.Issue(ctx, beneficiary, amount, sendChequeFunc)
+}
+
+func (g *ConsistencyGuard) PreviewIssue(ctx context.Context, beneficiary common.Address, amount Tokens) (*IssuePreview, error) {
+	preview, err := g.Service.PreviewIssue(ctx, beneficiary, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	check := g.check
+	blocked := !check.Consistent && !g.overridden
+	g.mu.Unlock()
+
+	policy := PolicyCheck{Policy: PolicyTotalIssuedConsistency}
+	if blocked {
+		policy.Err = fmt.Errorf("%w: stored %d, issued cheques %d", ErrTotalIssuedInconsistent, check.Stored, check.Computed)
+	}
+	preview.Policies = append(preview.Policies, policy)
+	return preview, nil
+}
+
+// TotalIssuedGuard checks the consistency of the total issued counter and lets
+// an operator resolve an inconsistency.
+type TotalIssuedGuard interface {
+	// Check compares the total issued counter with the issued cheques.
+	Check(ctx context.Context) (*TotalIssuedCheck, error)
+	// Reconcile replaces the total issued counter with the sum of the issued cheques.
+	Reconcile(ctx context.Context) (*TotalIssuedCheck, error)
+	// Override allows issuing cheques despite an inconsistent total issued counter.
+	Override() *TotalIssuedCheck
+}
+
+var _ TotalIssuedGuard = (*ConsistencyGuard)(nil)
@@ -0,0 +1,111 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestConsistencyGuard(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	store := storemock.NewStateStore()
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(0).FillBytes(make([]byte, 32)), "totalPaidOut"),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		store,
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				return make([]byte, 65), nil
+			},
+		},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chequebookService.ImportLastCheque(ctx, beneficiary, chequebook.TokensFromUint64(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	guard, err := chequebook.NewConsistencyGuard(ctx, chequebookService, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check := guard.LastCheck(); !check.Consistent || check.Stored.Cmp(big.NewInt(100)) != 0 || check.Computed.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected check %+v", check)
+	}
+
+	// the counter lost the last cheque, e.g. after a crash
+	var totalIssuedKey string
+	if err := store.Iterate("swap_chequebook_total_issued_", func(key, _ []byte) (bool, error) {
+		totalIssuedKey = string(key)
+		return true, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(totalIssuedKey, rawValue(chequebook.WithChecksum("swap_chequebook_total_issued_", []byte("80")))); err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := guard.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Consistent || check.Difference.Cmp(big.NewInt(-20)) != 0 {
+		t.Fatalf("unexpected check %+v", check)
+	}
+
+	sendCheque := func(cheque *chequebook.SignedCheque) error { return nil }
+	if _, err := guard.Issue(ctx, beneficiary, chequebook.TokensFromUint64(10), sendCheque); !errors.Is(err, chequebook.ErrTotalIssuedInconsistent) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrTotalIssuedInconsistent, err)
+	}
+
+	if check := guard.Override(); !check.Overridden {
+		t.Fatalf("check not overridden %+v", check)
+	}
+	if _, err := guard.Issue(ctx, beneficiary, chequebook.TokensFromUint64(10), sendCheque); err != nil {
+		t.Fatal(err)
+	}
+
+	// the difference persists, so does the override
+	check, err = guard.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Consistent || !check.Overridden {
+		t.Fatalf("unexpected check %+v", check)
+	}
+
+	check, err = guard.Reconcile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Consistent || check.Overridden || check.Stored.Cmp(big.NewInt(110)) != 0 {
+		t.Fatalf("unexpected check after reconciliation %+v", check)
+	}
+}
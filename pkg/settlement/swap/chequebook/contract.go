@@ -6,6 +6,8 @@ package chequebook
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -13,6 +15,31 @@ import (
 	"github.com/ethersphere/bee/pkg/transaction"
 )
 
+// The call data of the getters used when issuing cheques is packed only once.
+var (
+	balanceCallData      = mustPack("balance")
+	totalPaidOutCallData = mustPack("totalPaidOut")
+)
+
+var errShortOutput = errors.New("abi: output too short for uint256")
+
+func mustPack(method string) []byte {
+	data, err := chequebookABI.Pack(method)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// unpackUint256 decodes the output of a call returning a single uint256
+// without the reflection of the generic ABI decoding.
+func unpackUint256(output []byte) (*big.Int, error) {
+	if len(output) < 32 {
+		return nil, errShortOutput
+	}
+	return new(big.Int).SetBytes(output[:32]), nil
+}
+
 type chequebookContract struct {
 	address            common.Address
 	transactionService transaction.Service
@@ -38,6 +65,10 @@ func (c *chequebookContract) Issuer(ctx context.Context) (common.Address, error)
 	if err != nil {
 		return common.Address{}, err
 	}
+	// calls to addresses without code succeed without output
+	if len(output) == 0 {
+		return common.Address{}, fmt.Errorf("%x: %w", c.address, ErrChequebookNotOnChain)
+	}
 
 	results, err := chequebookABI.Unpack("issuer", output)
 	if err != nil {
@@ -49,25 +80,15 @@ func (c *chequebookContract) Issuer(ctx context.Context) (common.Address, error)
 
 // Balance returns the token balance of the chequebook.
 func (c *chequebookContract) Balance(ctx context.Context) (*big.Int, error) {
-	callData, err := chequebookABI.Pack("balance")
-	if err != nil {
-		return nil, err
-	}
-
 	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
 		To:   &c.address,
-		Data: callData,
+		Data: balanceCallData,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := chequebookABI.Unpack("balance", output)
-	if err != nil {
-		return nil, err
-	}
-
-	return abi.ConvertType(results[0], new(big.Int)).(*big.Int), nil
+	return unpackUint256(output)
 }
 
 func (c *chequebookContract) PaidOut(ctx context.Context, address common.Address) (*big.Int, error) {
@@ -93,23 +114,36 @@ func (c *chequebookContract) PaidOut(ctx context.Context, address common.Address
 }
 
 func (c *chequebookContract) TotalPaidOut(ctx context.Context) (*big.Int, error) {
-	callData, err := chequebookABI.Pack("totalPaidOut")
+	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &c.address,
+		Data: totalPaidOutCallData,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	return unpackUint256(output)
+}
+
+// Token returns the address of the erc20 token used by the chequebook.
+func (c *chequebookContract) Token(ctx context.Context) (common.Address, error) {
+	callData, err := chequebookABI.Pack("token")
+	if err != nil {
+		return common.Address{}, err
+	}
+
 	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
 		To:   &c.address,
 		Data: callData,
 	})
 	if err != nil {
-		return nil, err
+		return common.Address{}, err
 	}
 
-	results, err := chequebookABI.Unpack("totalPaidOut", output)
+	results, err := chequebookABI.Unpack("token", output)
 	if err != nil {
-		return nil, err
+		return common.Address{}, err
 	}
 
-	return abi.ConvertType(results[0], new(big.Int)).(*big.Int), nil
+	return *abi.ConvertType(results[0], new(common.Address)).(*common.Address), nil
 }
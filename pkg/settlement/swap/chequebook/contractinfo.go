@@ -0,0 +1,106 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// ContractVersionv0_3_1 is the version of the full chequebook contracts deployed by legacy factories.
+	ContractVersionv0_3_1 = "0.3.1"
+	// ContractVersionv0_4_0 is the version of the minimal proxy chequebooks deployed by current factories.
+	ContractVersionv0_4_0 = "0.4.0"
+)
+
+// ErrContractInfoUnsupported is the error returned if the factory cannot inspect chequebooks.
+var ErrContractInfoUnsupported = errors.New("chequebook contract info not supported")
+
+// ContractFeatures are the features supported by a chequebook contract version.
+type ContractFeatures struct {
+	HardDeposits    bool // deposits reserved for a beneficiary for a timeout
+	PartialCashouts bool // cashouts paying out what the balance covers if it is insufficient
+	MinimalProxy    bool // the chequebook delegates to the master copy of its factory
+	Assignments     bool // cashouts by a caller authorized by the beneficiary to a recipient of its choice
+}
+
+// contractFeatures are the features of the known chequebook contract versions.
+var contractFeatures = map[string]ContractFeatures{
+	ContractVersionv0_3_1: {HardDeposits: true, PartialCashouts: true, Assignments: true},
+	ContractVersionv0_4_0: {HardDeposits: true, PartialCashouts: true, MinimalProxy: true, Assignments: true},
+}
+
+// ContractInfo describes a deployed chequebook contract.
+type ContractInfo struct {
+	Address         common.Address
+	Version         string
+	Features        ContractFeatures
+	Factory         common.Address // factory which deployed the chequebook
+	DeploymentBlock uint64         // 0 if the backend cannot serve the history of the chain
+}
+
+// ContractInspector is implemented by factories which can report the contract
+// version of the chequebooks they deployed.
+type ContractInspector interface {
+	// ContractInfo returns the version, features, factory and deployment block
+	// of a chequebook deployed by one of the trusted factories.
+	ContractInfo(ctx context.Context, chequebook common.Address) (*ContractInfo, error)
+}
+
+// ContractInfo returns the version, features, factory and deployment block of
+// a chequebook deployed by the current or one of the legacy factories.
+func (c *factory) ContractInfo(ctx context.Context, chequebook common.Address) (*ContractInfo, error) {
+	for _, factoryAddress := range append([]common.Address{c.address}, c.legacyFactories()...) {
+		deployed, err := c.verifyChequebookAgainstFactory(ctx, factoryAddress, chequebook)
+		if err != nil {
+			return nil, err
+		}
+		if !deployed {
+			continue
+		}
+
+		version, err := c.chequebookVersion(ctx, factoryAddress, chequebook)
+		if err != nil {
+			return nil, err
+		}
+		return &ContractInfo{
+			Address:         chequebook,
+			Version:         version,
+			Features:        contractFeatures[version],
+			Factory:         factoryAddress,
+			DeploymentBlock: c.deploymentBlock(ctx, chequebook),
+		}, nil
+	}
+
+	return nil, ErrNotDeployedByFactory
+}
+
+// deploymentBlock searches the first block in which the chequebook has code.
+// It returns 0 if the backend cannot serve the code at past blocks.
+func (c *factory) deploymentBlock(ctx context.Context, chequebook common.Address) uint64 {
+	head, err := c.backend.BlockNumber(ctx)
+	if err != nil {
+		return 0
+	}
+
+	low, high := uint64(0), head
+	for low < high {
+		mid := low + (high-low)/2
+		code, err := c.backend.CodeAt(ctx, chequebook, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0
+		}
+		if len(code) > 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+func TestFactoryContractInfo(t *testing.T) {
+	t.Parallel()
+
+	factoryAddress := common.HexToAddress("0xabcd")
+	chequebookAddress := common.HexToAddress("0xefff")
+	legacyFactory := common.HexToAddress("0xbbbb")
+	masterAddress := common.HexToAddress("0x5555")
+	const (
+		head            = 1000
+		deploymentBlock = 377
+	)
+
+	// the backend serves the code of the chequebook from the deployment block
+	// on, past blocks only if it is an archive node
+	newBackend := func(factory common.Address, factoryCode string, chequebookCode []byte, archive bool) []backendmock.Option {
+		return []backendmock.Option{
+			backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+				return head, nil
+			}),
+			backendmock.WithCodeAtFunc(func(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+				switch contract {
+				case factory:
+					return common.FromHex(factoryCode), nil
+				case chequebookAddress:
+					if blockNumber == nil {
+						return chequebookCode, nil
+					}
+					if !archive {
+						return nil, errors.New("missing trie node")
+					}
+					if blockNumber.Uint64() < deploymentBlock {
+						return nil, nil
+					}
+					return chequebookCode, nil
+				}
+				return nil, errors.New("unexpected contract")
+			}),
+		}
+	}
+
+	t.Run("current", func(t *testing.T) {
+		t.Parallel()
+
+		factory := chequebook.NewFactory(
+			backendmock.New(newBackend(factoryAddress, sw3abi.SimpleSwapFactoryDeployedBinv0_4_0, chequebook.MinimalProxyCode(masterAddress), true)...),
+			transactionmock.New(
+				transactionmock.WithABICallSequence(
+					transactionmock.ABICall(&factoryABI, factoryAddress, common.BigToHash(big.NewInt(1)).Bytes(), "deployedContracts", chequebookAddress),
+					transactionmock.ABICall(&factoryABI, factoryAddress, masterAddress.Hash().Bytes(), "master"),
+				),
+			),
+			factoryAddress,
+			[]common.Address{legacyFactory},
+		)
+
+		info, err := factory.(chequebook.ContractInspector).ContractInfo(context.Background(), chequebookAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := chequebook.ContractInfo{
+			Address:         chequebookAddress,
+			Version:         chequebook.ContractVersionv0_4_0,
+			Features:        chequebook.ContractFeatures{HardDeposits: true, PartialCashouts: true, MinimalProxy: true, Assignments: true},
+			Factory:         factoryAddress,
+			DeploymentBlock: deploymentBlock,
+		}
+		if *info != want {
+			t.Fatalf("got contract info %+v, want %+v", *info, want)
+		}
+	})
+
+	t.Run("legacy without archive", func(t *testing.T) {
+		t.Parallel()
+
+		factory := chequebook.NewFactory(
+			backendmock.New(newBackend(legacyFactory, sw3abi.SimpleSwapFactoryDeployedBinv0_3_1, chequebook.ChequebookCodev0_3_1, false)...),
+			transactionmock.New(
+				transactionmock.WithABICallSequence(
+					transactionmock.ABICall(&factoryABI, factoryAddress, common.BigToHash(big.NewInt(0)).Bytes(), "deployedContracts", chequebookAddress),
+					transactionmock.ABICall(&factoryABI, legacyFactory, common.BigToHash(big.NewInt(1)).Bytes(), "deployedContracts", chequebookAddress),
+				),
+			),
+			factoryAddress,
+			[]common.Address{legacyFactory},
+		)
+
+		info, err := factory.(chequebook.ContractInspector).ContractInfo(context.Background(), chequebookAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := chequebook.ContractInfo{
+			Address:  chequebookAddress,
+			Version:  chequebook.ContractVersionv0_3_1,
+			Features: chequebook.ContractFeatures{HardDeposits: true, PartialCashouts: true, Assignments: true},
+			Factory:  legacyFactory,
+		}
+		if *info != want {
+			t.Fatalf("got contract info %+v, want %+v", *info, want)
+		}
+	})
+
+	t.Run("not deployed by factory", func(t *testing.T) {
+		t.Parallel()
+
+		factory := chequebook.NewFactory(
+			backendmock.New(),
+			transactionmock.New(
+				transactionmock.WithABICall(&factoryABI, factoryAddress, common.BigToHash(big.NewInt(0)).Bytes(), "deployedContracts", chequebookAddress),
+			),
+			factoryAddress,
+			nil,
+		)
+
+		_, err := factory.(chequebook.ContractInspector).ContractInfo(context.Background(), chequebookAddress)
+		if !errors.Is(err, chequebook.ErrNotDeployedByFactory) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrNotDeployedByFactory)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/logcursor"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+const (
+	depositKeyPrefix    = "swap_chequebook_deposit_"
+	depositLastBlockKey = "swap_chequebook_last_scanned_deposit_block"
+	transferEventName   = "Transfer"
+)
+
+var (
+	// ErrDepositHistoryUnavailable is the error if the deposit history cannot be scanned because there is no chain backend.
+	ErrDepositHistoryUnavailable = errors.New("deposit history unavailable")
+
+	erc20ABI          = abiutil.MustParseABI(sw3abi.ERC20ABIv0_3_1)
+	transferEventType = erc20ABI.Events[transferEventName]
+)
+
+// Deposit is a token transfer into the chequebook found on chain.
+type Deposit struct {
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+	From        common.Address
+	Amount      *big.Int
+}
+
+type transferEvent struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// depositKey computes the key where to store a deposit. The key is unique per
+// log so rescanning a block range does not record a deposit twice.
+func depositKey(blockNumber uint64, logIndex uint) string {
+	return fmt.Sprintf("%s%016x_%08x", depositKeyPrefix, blockNumber, logIndex)
+}
+
+// DepositHistory returns all token transfers into the chequebook, including
+// the ones not made through this service. Blocks which were not yet scanned are
+// scanned for transfer events before the history is returned.
+func (s *service) DepositHistory(ctx context.Context) ([]Deposit, error) {
+	s.depositMu.Lock()
+	defer s.depositMu.Unlock()
+
+	if err := s.scanDeposits(ctx); err != nil {
+		return nil, err
+	}
+
+	deposits := make([]Deposit, 0)
+	err := s.store.Iterate(depositKeyPrefix, func(key, val []byte) (stop bool, err error) {
+		if !strings.HasPrefix(string(key), depositKeyPrefix) {
+			return true, nil
+		}
+		var deposit Deposit
+		if err := json.Unmarshal(val, &deposit); err != nil {
+			return true, err
+		}
+		deposits = append(deposits, deposit)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deposits, func(i, j int) bool {
+		if deposits[i].BlockNumber != deposits[j].BlockNumber {
+			return deposits[i].BlockNumber < deposits[j].BlockNumber
+		}
+		return deposits[i].LogIndex < deposits[j].LogIndex
+	})
+
+	return deposits, nil
+}
+
+// scanDeposits scans all confirmed blocks after the last scanned block for
+// token transfers into the chequebook and stores them.
+func (s *service) scanDeposits(ctx context.Context) error {
+	if s.backend == nil {
+		return ErrDepositHistoryUnavailable
+	}
+
+	token, err := s.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanner := logcursor.New("deposits", s.backend, s.store, depositLastBlockKey, logcursor.Options{
+		Start:  s.depositScanStart,
+		Rewind: s.removeDeposits,
+	})
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{token.Address},
+		Topics: [][]common.Hash{
+			{transferEventType.ID},
+			nil,
+			{common.BytesToHash(s.address.Bytes())},
+		},
+	}
+	return scanner.Scan(ctx, query, func(ctx context.Context, logs []types.Log) error {
+		for _, log := range logs {
+			var event transferEvent
+			if err := transaction.ParseEvent(&erc20ABI, transferEventName, &event, log); err != nil {
+				return err
+			}
+			if event.To != s.address {
+				continue
+			}
+			// deposits are keyed by their log, so rescanning a range does not duplicate them
+			err := s.store.Put(depositKey(log.BlockNumber, log.Index), Deposit{
+				TxHash:      log.TxHash,
+				BlockNumber: log.BlockNumber,
+				LogIndex:    log.Index,
+				From:        event.From,
+				Amount:      event.Value,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// removeDeposits removes the deposits found in the blocks from to including
+// to. They are scanned again after a reorg, which records them again unless
+// they were orphaned.
+func (s *service) removeDeposits(_ context.Context, from, to uint64) error {
+	var keys []string
+	err := s.store.Iterate(depositKeyPrefix, func(key, val []byte) (stop bool, err error) {
+		if !strings.HasPrefix(string(key), depositKeyPrefix) {
+			return true, nil
+		}
+		var deposit Deposit
+		if err := json.Unmarshal(val, &deposit); err != nil {
+			return true, err
+		}
+		if deposit.BlockNumber >= from && deposit.BlockNumber <= to {
+			keys = append(keys, string(key))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := s.store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// depositScanStart returns the first block to scan for deposits if none was
+// scanned yet. This is the block the chequebook was deployed in, if known.
+func (s *service) depositScanStart(ctx context.Context, _ uint64) (uint64, error) {
+	var txHash common.Hash
+	err := s.store.Get(ChequebookDeploymentKey, &txHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	receipt, err := s.backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return 0, fmt.Errorf("chequebook deployment receipt: %w", err)
+	}
+
+	return receipt.BlockNumber.Uint64(), nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+var erc20ABI = abiutil.MustParseABI(sw3abi.ERC20ABIv0_3_1)
+
+func transferLog(t *testing.T, token, from, to common.Address, amount *big.Int, blockNumber uint64, index uint) types.Log {
+	t.Helper()
+
+	data, err := erc20ABI.Events["Transfer"].Inputs.NonIndexed().Pack(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			erc20ABI.Events["Transfer"].ID,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:        data,
+		BlockNumber: blockNumber,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(blockNumber)),
+		Index:       index,
+	}
+}
+
+func TestChequebookDepositHistory(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	ownerAdress := common.HexToAddress("0xfff")
+	tokenAddress := common.HexToAddress("0xeeee")
+	wallet := common.HexToAddress("0xcccc")
+	deploymentTx := common.HexToHash("0xdddd")
+	deploymentBlock := uint64(100)
+
+	store := storemock.NewStateStore()
+	if err := store.Put(chequebook.ChequebookDeploymentKey, deploymentTx); err != nil {
+		t.Fatal(err)
+	}
+
+	head := uint64(200)
+	var queries []ethereum.FilterQuery
+	logs := []types.Log{
+		transferLog(t, tokenAddress, wallet, address, big.NewInt(20), 150, 1),
+		transferLog(t, tokenAddress, ownerAdress, address, big.NewInt(10), 120, 0),
+	}
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICall(&chequebookABI, address, common.LeftPadBytes(tokenAddress.Bytes(), 32), "token"),
+		),
+		address,
+		ownerAdress,
+		store,
+		&chequeSignerMock{},
+		erc20mock.New(
+			erc20mock.WithSymbolFunc(func(ctx context.Context) (string, error) { return "BZZ", nil }),
+			erc20mock.WithNameFunc(func(ctx context.Context) (string, error) { return "Swarm Token", nil }),
+			erc20mock.WithDecimalsFunc(func(ctx context.Context) (uint8, error) { return 16, nil }),
+		),
+		backendmock.New(
+			backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+				return head, nil
+			}),
+			backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+				return &types.Header{Number: number}, nil
+			}),
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				if txHash != deploymentTx {
+					t.Fatalf("receipt for wrong transaction. wanted %v, got %v", deploymentTx, txHash)
+				}
+				return &types.Receipt{BlockNumber: new(big.Int).SetUint64(deploymentBlock)}, nil
+			}),
+			backendmock.WithFilterLogsFunc(func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+				queries = append(queries, query)
+				if len(query.Addresses) != 1 || query.Addresses[0] != tokenAddress {
+					t.Fatalf("filtering logs of wrong contract %v", query.Addresses)
+				}
+				var result []types.Log
+				for _, l := range logs {
+					if l.BlockNumber >= query.FromBlock.Uint64() && l.BlockNumber <= query.ToBlock.Uint64() {
+						result = append(result, l)
+					}
+				}
+				return result, nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, err := chequebookService.DepositHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("got %d queries, want 1", len(queries))
+	}
+	if queries[0].FromBlock.Uint64() != deploymentBlock || queries[0].ToBlock.Uint64() != head-4 {
+		t.Fatalf("scanned wrong range %v-%v", queries[0].FromBlock, queries[0].ToBlock)
+	}
+
+	if len(deposits) != 2 {
+		t.Fatalf("got %d deposits, want 2", len(deposits))
+	}
+	if deposits[0].BlockNumber != 120 || deposits[0].From != ownerAdress || deposits[0].Amount.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("wrong first deposit %+v", deposits[0])
+	}
+	if deposits[1].BlockNumber != 150 || deposits[1].From != wallet || deposits[1].Amount.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("wrong second deposit %+v", deposits[1])
+	}
+
+	// only blocks which were not yet scanned are scanned again
+	head = 300
+	logs = append(logs, transferLog(t, tokenAddress, wallet, address, big.NewInt(30), 250, 0))
+
+	deposits, err = chequebookService.DepositHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("got %d queries, want 2", len(queries))
+	}
+	if queries[1].FromBlock.Uint64() != 197 || queries[1].ToBlock.Uint64() != 296 {
+		t.Fatalf("scanned wrong range %v-%v", queries[1].FromBlock, queries[1].ToBlock)
+	}
+	if len(deposits) != 3 {
+		t.Fatalf("got %d deposits, want 3", len(deposits))
+	}
+}
+
+func TestChequebookDepositHistoryReorg(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	tokenAddress := common.HexToAddress("0xeeee")
+	wallet := common.HexToAddress("0xcccc")
+
+	head := uint64(200)
+	// blocks from forkAt on have a different hash after the fork
+	var fork byte
+	forkAt := uint64(180)
+	logs := []types.Log{
+		transferLog(t, tokenAddress, wallet, address, big.NewInt(10), 120, 0),
+		transferLog(t, tokenAddress, wallet, address, big.NewInt(20), 150, 0),
+		transferLog(t, tokenAddress, wallet, address, big.NewInt(30), 190, 0),
+	}
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICall(&chequebookABI, address, common.LeftPadBytes(tokenAddress.Bytes(), 32), "token"),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{},
+		erc20mock.New(
+			erc20mock.WithSymbolFunc(func(ctx context.Context) (string, error) { return "BZZ", nil }),
+			erc20mock.WithNameFunc(func(ctx context.Context) (string, error) { return "Swarm Token", nil }),
+			erc20mock.WithDecimalsFunc(func(ctx context.Context) (uint8, error) { return 16, nil }),
+		),
+		backendmock.New(
+			backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+				return head, nil
+			}),
+			backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+				header := &types.Header{Number: number}
+				if number.Uint64() >= forkAt {
+					header.Extra = []byte{fork}
+				}
+				return header, nil
+			}),
+			backendmock.WithFilterLogsFunc(func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+				var result []types.Log
+				for _, l := range logs {
+					if l.BlockNumber >= query.FromBlock.Uint64() && l.BlockNumber <= query.ToBlock.Uint64() {
+						result = append(result, l)
+					}
+				}
+				return result, nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, err := chequebookService.DepositHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 3 {
+		t.Fatalf("got %d deposits, want 3", len(deposits))
+	}
+
+	// the deposit in block 190 is orphaned by a reorg
+	fork = 1
+	head = 300
+	logs = logs[:2]
+
+	deposits, err = chequebookService.DepositHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 2 {
+		t.Fatalf("got %d deposits, want 2", len(deposits))
+	}
+	if deposits[0].BlockNumber != 120 || deposits[1].BlockNumber != 150 {
+		t.Fatalf("got deposits %+v, want the ones in blocks 120 and 150", deposits)
+	}
+}
+
+func TestChequebookDepositHistoryNoBackend(t *testing.T) {
+	t.Parallel()
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(),
+		common.HexToAddress("0xabcd"),
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chequebookService.DepositHistory(context.Background())
+	if !errors.Is(err, chequebook.ErrDepositHistoryUnavailable) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrDepositHistoryUnavailable)
+	}
+}
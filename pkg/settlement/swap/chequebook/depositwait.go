@@ -0,0 +1,92 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"golang.org/x/sync/singleflight"
+)
+
+// depositWaitCacheSize is the number of completed deposit waits remembered.
+// The oldest result is evicted first.
+const depositWaitCacheSize = 1024
+
+// DepositProgress is the progress of waiting for a deposit transaction.
+type DepositProgress struct {
+	TxHash        common.Hash
+	Mined         bool   // whether the transaction was included in a block
+	BlockNumber   uint64 // block the transaction was included in
+	Confirmations uint64 // blocks on top of the block of the transaction
+	Required      uint64 // confirmations after which the deposit is final
+	Final         bool
+}
+
+// DepositProgressFunc is called whenever the progress of waiting for a
+// deposit changes.
+type DepositProgressFunc func(DepositProgress)
+
+// depositWaits makes WaitForDeposit idempotent. Concurrent waits for the same
+// transaction share a single wait and the final result of a wait, confirmed or
+// reverted, is returned for the transaction without waiting again. Other
+// errors, e.g. a cancelled context, are not remembered so that the wait can
+// be retried.
+type depositWaits struct {
+	group singleflight.Group
+
+	mu      sync.Mutex
+	results map[common.Hash]error
+	order   []common.Hash // completed waits, oldest first
+}
+
+func (d *depositWaits) result(txHash common.Hash) (err error, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err, ok = d.results[txHash]
+	return err, ok
+}
+
+func (d *depositWaits) complete(txHash common.Hash, err error) {
+	if err != nil && !errors.Is(err, transaction.ErrTransactionReverted) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.results == nil {
+		d.results = make(map[common.Hash]error)
+	}
+	if _, ok := d.results[txHash]; ok {
+		return
+	}
+	if len(d.order) == depositWaitCacheSize {
+		delete(d.results, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.results[txHash] = err
+	d.order = append(d.order, txHash)
+}
+
+// wait returns the remembered result for the transaction or calls waitFunc
+// once for all concurrent callers.
+func (d *depositWaits) wait(ctx context.Context, txHash common.Hash, waitFunc func(context.Context, common.Hash) error) error {
+	if err, ok := d.result(txHash); ok {
+		return err
+	}
+	_, err, _ := d.group.Do(txHash.Hex(), func() (interface{}, error) {
+		if err, ok := d.result(txHash); ok {
+			return nil, err
+		}
+		err := waitFunc(ctx, txHash)
+		d.complete(txHash, err)
+		return nil, err
+	})
+	return err
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+// eip1271ABIJSON is the ABI of the EIP-1271 signature validation function.
+const eip1271ABIJSON = `[{"inputs":[{"internalType":"bytes32","name":"hash","type":"bytes32"},{"internalType":"bytes","name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"internalType":"bytes4","name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var eip1271ABI = abiutil.MustParseABI(eip1271ABIJSON)
+
+// eip1271MagicValue is returned by isValidSignature for valid signatures.
+var eip1271MagicValue = []byte{0x16, 0x26, 0xba, 0x7e}
+
+// IsValidContractSignature checks with EIP-1271 whether the contract at signer,
+// e.g. a multisig or account abstraction wallet, accepts the signature of the
+// cheque. It returns false if the signer is not such a contract. An error is
+// only returned if the contract could not be queried.
+func IsValidContractSignature(ctx context.Context, transactionService transaction.Service, signer common.Address, cheque *SignedCheque, chainID int64) (bool, error) {
+	hash, err := ChequeHash(&cheque.Cheque, chainID)
+	if err != nil {
+		return false, err
+	}
+
+	var digest [32]byte
+	copy(digest[:], hash)
+	callData, err := eip1271ABI.Pack("isValidSignature", digest, cheque.Signature)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &signer,
+		Data: callData,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// calls to accounts without code succeed with empty output
+	return len(output) >= len(eip1271MagicValue) && bytes.Equal(output[:len(eip1271MagicValue)], eip1271MagicValue), nil
+}
@@ -1,7 +1,66 @@
 package chequebook
 
+import (
+	"encoding/binary"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+)
+
 var (
-	LastIssuedChequeKey   = lastIssuedChequeKey
-	LastReceivedChequeKey = lastReceivedChequeKey
-	CashoutActionKey      = cashoutActionKey
+	LastIssuedChequeKey         = lastIssuedChequeKey
+	LastReceivedChequeKey       = lastReceivedChequeKey
+	LegacyLastReceivedChequeKey = legacyLastReceivedChequeKey
+	CashoutActionKey            = cashoutActionKey
+	VerificationKey             = verificationKey
+	CashoutTransactionKey       = cashoutTransactionKey
+	ChequeCashoutKey            = chequeCashoutKey
+	MinimalProxyCode            = minimalProxyCode
+	MulticallABI                = multicallABI
+	EIP1271ABI                  = eip1271ABI
+	DepositFactoryABI           = depositFactoryABI
+
+	ChequebookCodeHashv0_3_1 = chequebookCodeHashv0_3_1
+	// ChequebookCodev0_3_1 is the runtime bytecode of v0.3.1 chequebooks as embedded in the factory bytecode.
+	ChequebookCodev0_3_1 = legacyDeployVersion[589 : 589+0x1936]
 )
+
+// WithChecksum returns the data as stored under the key by the checksum store.
+// The key is the one used by the chequebook, without its namespace.
+func WithChecksum(key string, data []byte) []byte {
+	stored := append([]byte(nil), data...)
+	stored = append(stored, checksumMarker)
+	return binary.BigEndian.AppendUint32(stored, checksum(key, data))
+}
+
+func SetChequeStoreClock(s ChequeStore, c clock.Clock) {
+	s.(*chequeStore).clock = c
+}
+
+func SetCachingFactoryClock(f Factory, c clock.Clock) {
+	f.(*cachingFactory).clock = c
+}
+
+func SetCashoutServiceClock(s CashoutService, c clock.Clock) {
+	s.(*cashoutService).clock = c
+}
+
+func SetIssueRateLimiterClock(s Service, c clock.Clock) {
+	s.(*rateLimitedService).clock = c
+}
+
+// RateLimitedBeneficiaries returns the number of beneficiaries whose
+// issuances are tracked by the rate limiter.
+func RateLimitedBeneficiaries(s Service) int {
+	l := s.(*rateLimitedService)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.lastIssue)
+}
+
+func SetBalanceAlarmClock(a *BalanceAlarm, c clock.Clock) {
+	a.clock = c
+}
+
+func SetReputationClock(r *Reputation, c clock.Clock) {
+	r.clock = c
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -42,11 +43,25 @@ type Factory interface {
 	VerifyChequebook(ctx context.Context, chequebook common.Address) error
 }
 
+// TrustedFactories is implemented by factories whose set of trusted legacy
+// factories can be changed at runtime.
+type TrustedFactories interface {
+	// TrustedFactories returns the address of the factory used for deployments
+	// and the addresses of the legacy factories whose chequebooks are also accepted.
+	TrustedFactories() (common.Address, []common.Address)
+	// SetLegacyFactories replaces the legacy factories whose chequebooks are accepted.
+	// The bytecode of every factory is verified before the change is applied.
+	SetLegacyFactories(ctx context.Context, addresses []common.Address) error
+}
+
 type factory struct {
 	backend            transaction.Backend
 	transactionService transaction.Service
-	address            common.Address   // address of the factory to use for deployments
-	legacyAddresses    []common.Address // addresses of old factories which were allowed for deployment
+	address            common.Address // address of the factory to use for deployments
+
+	lock            sync.RWMutex
+	legacyAddresses []common.Address // addresses of old factories which were allowed for deployment
+	multicall       common.Address   // Multicall3 contract bundling the calls of VerifyChequebooks, if set
 }
 
 type simpleSwapDeployedEvent struct {
@@ -56,10 +71,13 @@ type simpleSwapDeployedEvent struct {
 // the bytecode of factories which can be used for deployment
 var currentDeployVersion []byte = common.FromHex(sw3abi.SimpleSwapFactoryDeployedBinv0_4_0)
 
+// the bytecode of legacy factories which deployed full chequebook contracts
+var legacyDeployVersion []byte = common.FromHex(sw3abi.SimpleSwapFactoryDeployedBinv0_3_1)
+
 // the bytecode of factories from which we accept chequebooks
 var supportedVersions = [][]byte{
 	currentDeployVersion,
-	common.FromHex(sw3abi.SimpleSwapFactoryDeployedBinv0_3_1),
+	legacyDeployVersion,
 }
 
 // NewFactory creates a new factory service for the provided factory contract.
@@ -123,8 +141,13 @@ func (c *factory) VerifyBytecode(ctx context.Context) (err error) {
 		return ErrInvalidFactory
 	}
 
+	return c.verifyLegacyBytecode(ctx, c.legacyFactories())
+}
+
+// verifyLegacyBytecode checks that all given factories are of a supported version.
+func (c *factory) verifyLegacyBytecode(ctx context.Context, legacyAddresses []common.Address) error {
 LOOP:
-	for _, factoryAddress := range c.legacyAddresses {
+	for _, factoryAddress := range legacyAddresses {
 		code, err := c.backend.CodeAt(ctx, factoryAddress, nil)
 		if err != nil {
 			return err
@@ -156,6 +179,11 @@ func (c *factory) verifyChequebookAgainstFactory(ctx context.Context, factory, c
 		return false, err
 	}
 
+	return unpackDeployed(output)
+}
+
+// unpackDeployed decodes the output of a deployedContracts call.
+func unpackDeployed(output []byte) (bool, error) {
 	results, err := factoryABI.Unpack("deployedContracts", output)
 	if err != nil {
 		return false, err
@@ -169,29 +197,27 @@ func (c *factory) verifyChequebookAgainstFactory(ctx context.Context, factory, c
 	if !ok || deployed == nil {
 		return false, errDecodeABI
 	}
-	if !*deployed {
-		return false, nil
-	}
-	return true, nil
+	return *deployed, nil
 }
 
-// VerifyChequebook checks that the supplied chequebook has been deployed by a supported factory.
+// VerifyChequebook checks that the supplied chequebook has been deployed by a
+// supported factory and that its bytecode is the one deployed by that factory.
 func (c *factory) VerifyChequebook(ctx context.Context, chequebook common.Address) error {
 	deployed, err := c.verifyChequebookAgainstFactory(ctx, c.address, chequebook)
 	if err != nil {
 		return err
 	}
 	if deployed {
-		return nil
+		return c.verifyChequebookBytecode(ctx, c.address, chequebook)
 	}
 
-	for _, factoryAddress := range c.legacyAddresses {
+	for _, factoryAddress := range c.legacyFactories() {
 		deployed, err := c.verifyChequebookAgainstFactory(ctx, factoryAddress, chequebook)
 		if err != nil {
 			return err
 		}
 		if deployed {
-			return nil
+			return c.verifyChequebookBytecode(ctx, factoryAddress, chequebook)
 		}
 	}
 
@@ -228,3 +254,29 @@ func (c *factory) ERC20Address(ctx context.Context) (common.Address, error) {
 	}
 	return *erc20Address, nil
 }
+
+func (c *factory) legacyFactories() []common.Address {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.legacyAddresses
+}
+
+// TrustedFactories returns the address of the factory used for deployments
+// and the addresses of the legacy factories whose chequebooks are also accepted.
+func (c *factory) TrustedFactories() (common.Address, []common.Address) {
+	legacyAddresses := c.legacyFactories()
+	return c.address, append([]common.Address(nil), legacyAddresses...)
+}
+
+// SetLegacyFactories replaces the legacy factories whose chequebooks are accepted.
+func (c *factory) SetLegacyFactories(ctx context.Context, addresses []common.Address) error {
+	legacyAddresses := append([]common.Address(nil), addresses...)
+	if err := c.verifyLegacyBytecode(ctx, legacyAddresses); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.legacyAddresses = legacyAddresses
+	return nil
+}
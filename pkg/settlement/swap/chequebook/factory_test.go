@@ -5,6 +5,7 @@
 package chequebook_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/ethersphere/bee/pkg/transaction/backendmock"
@@ -142,6 +144,55 @@ func TestFactoryVerifySelf(t *testing.T) {
 	})
 }
 
+func TestFactorySetLegacyFactories(t *testing.T) {
+	t.Parallel()
+
+	factoryAddress := common.HexToAddress("0xabcd")
+	legacyFactory1 := common.HexToAddress("0xbbbb")
+	legacyFactory2 := common.HexToAddress("0xcccc")
+	invalidFactory := common.HexToAddress("0xdddd")
+
+	factory := chequebook.NewFactory(
+		backendWithCodeAt(map[common.Address]string{
+			factoryAddress: sw3abi.SimpleSwapFactoryDeployedBinv0_4_0,
+			legacyFactory1: sw3abi.SimpleSwapFactoryDeployedBinv0_3_1,
+			legacyFactory2: sw3abi.SimpleSwapFactoryDeployedBinv0_3_1,
+			invalidFactory: "abcd",
+		}),
+		transactionmock.New(),
+		factoryAddress,
+		[]common.Address{legacyFactory1},
+	)
+
+	trusted, ok := factory.(chequebook.TrustedFactories)
+	if !ok {
+		t.Fatal("factory does not implement TrustedFactories")
+	}
+
+	err := trusted.SetLegacyFactories(context.Background(), []common.Address{legacyFactory2, invalidFactory})
+	if !errors.Is(err, chequebook.ErrInvalidFactory) {
+		t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrInvalidFactory, err)
+	}
+
+	current, legacy := trusted.TrustedFactories()
+	if current != factoryAddress {
+		t.Fatalf("got factory %x, want %x", current, factoryAddress)
+	}
+	if len(legacy) != 1 || legacy[0] != legacyFactory1 {
+		t.Fatalf("legacy factories changed after failed update: %v", legacy)
+	}
+
+	err = trusted.SetLegacyFactories(context.Background(), []common.Address{legacyFactory2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, legacy = trusted.TrustedFactories()
+	if len(legacy) != 1 || legacy[0] != legacyFactory2 {
+		t.Fatalf("got legacy factories %v, want %v", legacy, []common.Address{legacyFactory2})
+	}
+}
+
 func TestFactoryVerifyChequebook(t *testing.T) {
 	t.Parallel()
 
@@ -149,19 +200,31 @@ func TestFactoryVerifyChequebook(t *testing.T) {
 	chequebookAddress := common.HexToAddress("0xefff")
 	legacyFactory1 := common.HexToAddress("0xbbbb")
 	legacyFactory2 := common.HexToAddress("0xcccc")
+	masterAddress := common.HexToAddress("0x5555")
 
 	t.Run("valid", func(t *testing.T) {
 		t.Parallel()
 
 		factory := chequebook.NewFactory(
-			backendmock.New(),
+			backendWithCodeAt(map[common.Address]string{
+				factoryAddress:    sw3abi.SimpleSwapFactoryDeployedBinv0_4_0,
+				chequebookAddress: common.Bytes2Hex(chequebook.MinimalProxyCode(masterAddress)),
+			}),
 			transactionmock.New(
-				transactionmock.WithABICall(
-					&factoryABI,
-					factoryAddress,
-					common.Hex2Bytes("0000000000000000000000000000000000000000000000000000000000000001"),
-					"deployedContracts",
-					chequebookAddress,
+				transactionmock.WithABICallSequence(
+					transactionmock.ABICall(
+						&factoryABI,
+						factoryAddress,
+						common.Hex2Bytes("0000000000000000000000000000000000000000000000000000000000000001"),
+						"deployedContracts",
+						chequebookAddress,
+					),
+					transactionmock.ABICall(
+						&factoryABI,
+						factoryAddress,
+						masterAddress.Hash().Bytes(),
+						"master",
+					),
 				),
 			),
 			factoryAddress,
@@ -173,11 +236,48 @@ func TestFactoryVerifyChequebook(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid bytecode", func(t *testing.T) {
+		t.Parallel()
+
+		factory := chequebook.NewFactory(
+			backendWithCodeAt(map[common.Address]string{
+				factoryAddress:    sw3abi.SimpleSwapFactoryDeployedBinv0_4_0,
+				chequebookAddress: common.Bytes2Hex(chequebook.MinimalProxyCode(common.HexToAddress("0x6666"))),
+			}),
+			transactionmock.New(
+				transactionmock.WithABICallSequence(
+					transactionmock.ABICall(
+						&factoryABI,
+						factoryAddress,
+						common.Hex2Bytes("0000000000000000000000000000000000000000000000000000000000000001"),
+						"deployedContracts",
+						chequebookAddress,
+					),
+					transactionmock.ABICall(
+						&factoryABI,
+						factoryAddress,
+						masterAddress.Hash().Bytes(),
+						"master",
+					),
+				),
+			),
+			factoryAddress,
+			nil,
+		)
+		err := factory.VerifyChequebook(context.Background(), chequebookAddress)
+		if !errors.Is(err, chequebook.ErrUnknownChequebookBytecode) {
+			t.Fatalf("wrong error. wanted %v, got %v", chequebook.ErrUnknownChequebookBytecode, err)
+		}
+	})
+
 	t.Run("valid legacy", func(t *testing.T) {
 		t.Parallel()
 
 		factory := chequebook.NewFactory(
-			backendmock.New(),
+			backendWithCodeAt(map[common.Address]string{
+				legacyFactory2:    sw3abi.SimpleSwapFactoryDeployedBinv0_3_1,
+				chequebookAddress: common.Bytes2Hex(chequebook.ChequebookCodev0_3_1),
+			}),
 			transactionmock.New(
 				transactionmock.WithABICallSequence(
 					transactionmock.ABICall(
@@ -255,6 +355,18 @@ func TestFactoryVerifyChequebook(t *testing.T) {
 	})
 }
 
+func TestChequebookCodeHashv0_3_1(t *testing.T) {
+	t.Parallel()
+
+	code := chequebook.ChequebookCodev0_3_1
+	if !bytes.HasPrefix(code, common.FromHex("6080604052")) || !bytes.HasSuffix(code, common.FromHex("64736f6c634300060c0033")) {
+		t.Fatal("extracted chequebook code is not a complete contract")
+	}
+	if hash := crypto.Keccak256Hash(code); hash != chequebook.ChequebookCodeHashv0_3_1 {
+		t.Fatalf("got code hash %x, want %x", hash, chequebook.ChequebookCodeHashv0_3_1)
+	}
+}
+
 func TestFactoryDeploy(t *testing.T) {
 	t.Parallel()
 
@@ -344,3 +456,72 @@ func TestFactoryDeployReverted(t *testing.T) {
 		t.Fatalf("wrong error. wanted %v, got %v", transaction.ErrTransactionReverted, err)
 	}
 }
+
+func TestDepositFactoryDeploymentFee(t *testing.T) {
+	t.Parallel()
+
+	factoryAddress := common.HexToAddress("0xabcd")
+	fee := big.NewInt(1000)
+	factory := chequebook.NewDepositFactory(
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithABICall(
+				&chequebook.DepositFactoryABI,
+				factoryAddress,
+				common.BigToHash(fee).Bytes(),
+				"deploymentFee",
+			),
+		),
+		factoryAddress,
+		nil,
+	)
+
+	depositFactory, ok := factory.(chequebook.DepositFactory)
+	if !ok {
+		t.Fatal("factory does not support deposits at deployment")
+	}
+	if depositFactory.Address() != factoryAddress {
+		t.Fatalf("wrong factory address. wanted %x, got %x", factoryAddress, depositFactory.Address())
+	}
+
+	got, err := depositFactory.DeploymentFee(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(fee) != 0 {
+		t.Fatalf("wrong deployment fee. wanted %d, got %d", fee, got)
+	}
+
+	if _, ok := chequebook.NewFactory(backendmock.New(), transactionmock.New(), factoryAddress, nil).(chequebook.DepositFactory); ok {
+		t.Fatal("factory without deposit support implements DepositFactory")
+	}
+}
+
+func TestDepositFactoryDeployWithDeposit(t *testing.T) {
+	t.Parallel()
+
+	factoryAddress := common.HexToAddress("0xabcd")
+	issuerAddress := common.HexToAddress("0xefff")
+	defaultTimeout := big.NewInt(1)
+	deployTransactionHash := common.HexToHash("0xffff")
+	nonce := common.HexToHash("eeff")
+	deposit := big.NewInt(10000)
+
+	factory := chequebook.NewDepositFactory(
+		backendmock.New(),
+		transactionmock.New(
+			transactionmock.WithABISend(&chequebook.DepositFactoryABI, deployTransactionHash, factoryAddress, big.NewInt(0), "deploySimpleSwapWithDeposit", issuerAddress, defaultTimeout, nonce, deposit),
+		),
+		factoryAddress,
+		nil,
+	)
+
+	txHash, err := factory.(chequebook.DepositFactory).DeployWithDeposit(context.Background(), issuerAddress, defaultTimeout, nonce, deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if txHash != deployTransactionHash {
+		t.Fatalf("returning wrong transaction hash. wanted %x, got %x", deployTransactionHash, txHash)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+// depositFactoryABIJSON is the part of the factory interface of factory versions
+// which fund the chequebook in the deployment transaction. The factory
+// transfers the initial deposit and its fee from the sender, which needs to
+// approve the factory for both beforehand.
+const depositFactoryABIJSON = `[{"inputs":[],"name":"deploymentFee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"issuer","type":"address"},{"internalType":"uint256","name":"defaultHardDepositTimeoutDuration","type":"uint256"},{"internalType":"bytes32","name":"salt","type":"bytes32"},{"internalType":"uint256","name":"initialDeposit","type":"uint256"}],"name":"deploySimpleSwapWithDeposit","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"nonpayable","type":"function"}]`
+
+// deployWithDepositGasLimit is the gas limit of deployments which also transfer the initial deposit.
+const deployWithDepositGasLimit = 250000
+
+var (
+	depositFactoryABI = abiutil.MustParseABI(depositFactoryABIJSON)
+
+	// ErrDeployDepositMismatch is the error if the balance of a chequebook
+	// deployed with an initial deposit does not match the deposit.
+	ErrDeployDepositMismatch = errors.New("chequebook balance does not match initial deposit")
+)
+
+// DepositFactory is implemented by factories which fund the chequebook with
+// the initial deposit in the deployment transaction.
+type DepositFactory interface {
+	// Address returns the address of the factory, which transfers the deposit.
+	Address() common.Address
+	// DeploymentFee returns the amount of tokens the factory charges for a deployment.
+	DeploymentFee(ctx context.Context) (*big.Int, error)
+	// DeployWithDeposit deploys a new chequebook funded with deposit and
+	// returns once the transaction has been submitted. The factory needs an
+	// allowance covering the deposit and the deployment fee.
+	DeployWithDeposit(ctx context.Context, issuer common.Address, defaultHardDepositTimeoutDuration *big.Int, nonce common.Hash, deposit *big.Int) (common.Hash, error)
+}
+
+type depositFactory struct {
+	*factory
+}
+
+// NewDepositFactory creates a new factory service for a factory contract which
+// accepts an initial deposit at deployment.
+func NewDepositFactory(backend transaction.Backend, transactionService transaction.Service, address common.Address, legacyAddresses []common.Address) Factory {
+	return &depositFactory{
+		factory: NewFactory(backend, transactionService, address, legacyAddresses).(*factory),
+	}
+}
+
+// Address returns the address of the factory, which transfers the deposit.
+func (c *depositFactory) Address() common.Address {
+	return c.address
+}
+
+// DeploymentFee returns the amount of tokens the factory charges for a deployment.
+func (c *depositFactory) DeploymentFee(ctx context.Context) (*big.Int, error) {
+	callData, err := depositFactoryABI.Pack("deploymentFee")
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &c.address,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := depositFactoryABI.Unpack("deploymentFee", output)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, errDecodeABI
+	}
+	fee, ok := abi.ConvertType(results[0], new(big.Int)).(*big.Int)
+	if !ok || fee == nil {
+		return nil, errDecodeABI
+	}
+	return fee, nil
+}
+
+// DeployWithDeposit deploys a new chequebook funded with deposit and returns
+// once the transaction has been submitted.
+func (c *depositFactory) DeployWithDeposit(ctx context.Context, issuer common.Address, defaultHardDepositTimeoutDuration *big.Int, nonce common.Hash, deposit *big.Int) (common.Hash, error) {
+	callData, err := depositFactoryABI.Pack("deploySimpleSwapWithDeposit", issuer, big.NewInt(0).Set(defaultHardDepositTimeoutDuration), nonce, big.NewInt(0).Set(deposit))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	request := &transaction.TxRequest{
+		To:          &c.address,
+		Data:        callData,
+		GasPrice:    sctx.GetGasPrice(ctx),
+		GasLimit:    deployWithDepositGasLimit,
+		Value:       big.NewInt(0),
+		Description: "chequebook deployment with deposit",
+	}
+
+	txHash, err := c.transactionService.Send(ctx, request, transaction.DefaultTipBoostPercent)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return txHash, nil
+}
+
+// verifyDeployDeposit checks that the balance of a chequebook deployed with an
+// initial deposit matches the deposit.
+func verifyDeployDeposit(ctx context.Context, chequebookService Service, deposit *big.Int) error {
+	balance, err := chequebookService.Balance(ctx)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(deposit) != 0 {
+		return fmt.Errorf("%w: balance %d, deposit %d", ErrDeployDepositMismatch, balance, deposit)
+	}
+	return nil
+}
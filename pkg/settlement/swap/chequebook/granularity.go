@@ -0,0 +1,170 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+	"resenje.org/multex"
+)
+
+// prefix for the persistence key of the prepaid credit of a beneficiary
+const prepaidCreditKeyPrefix = "swap_chequebook_prepaid_credit_"
+
+// prepaidCreditKey computes the key where to store the prepaid credit of a beneficiary.
+func prepaidCreditKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", prepaidCreditKeyPrefix, beneficiary)
+}
+
+// ErrCreditInsufficient is the error returned if the prepaid credit of a
+// beneficiary does not cover a payment.
+var ErrCreditInsufficient = errors.New("prepaid credit insufficient")
+
+// ErrNoChequeRequired is the error returned by Issue of a GranularService if
+// the prepaid credit covers the payment. No cheque is issued and the credit
+// is left unchanged, the payment has to be made with PayFromCredit.
+var ErrNoChequeRequired = errors.New("payment covered by prepaid credit")
+
+// CreditPayer is implemented by services which keep a prepaid credit for the
+// beneficiaries. Payments covered by it are made without sending a cheque.
+type CreditPayer interface {
+	// PayFromCredit pays amount to the beneficiary from the prepaid credit
+	// and returns the available balance. ErrCreditInsufficient is returned
+	// and the credit is left unchanged if it does not cover the payment.
+	PayFromCredit(ctx context.Context, beneficiary common.Address, amount Tokens) (*big.Int, error)
+}
+
+// GranularService is a Service which issues cheques in multiples of a granularity.
+type GranularService struct {
+	Service
+	store       storage.StateStorer
+	granularity *big.Int
+
+	// locks serializes the payments and credit updates of a beneficiary
+	locks *multex.Multex
+}
+
+// NewGranularService wraps the service so that the amounts of issued cheques
+// are rounded up to the next multiple of granularity. The excess paid to a
+// beneficiary is kept as prepaid credit and used for the following payments.
+// Payments fully covered by the prepaid credit are made with PayFromCredit.
+func NewGranularService(service Service, store storage.StateStorer, granularity *big.Int) *GranularService {
+	return &GranularService{
+		Service:     service,
+		store:       namespacedStore(store, service.Address()),
+		granularity: new(big.Int).Set(granularity),
+		locks:       multex.New(),
+	}
+}
+
+// PrepaidCredit returns the amount already paid to the beneficiary in excess
+// of the issued payments.
+func (s *GranularService) PrepaidCredit(beneficiary common.Address) (*big.Int, error) {
+	s.locks.Lock(beneficiary.Hex())
+	defer s.locks.Unlock(beneficiary.Hex())
+	return s.prepaidCredit(beneficiary)
+}
+
+func (s *GranularService) prepaidCredit(beneficiary common.Address) (*big.Int, error) {
+	var credit *big.Int
+	err := s.store.Get(prepaidCreditKey(beneficiary), &credit)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		return big.NewInt(0), nil
+	}
+	return credit, nil
+}
+
+// PayFromCredit pays amount to the beneficiary from the prepaid credit.
+func (s *GranularService) PayFromCredit(ctx context.Context, beneficiary common.Address, tokens Tokens) (*big.Int, error) {
+	amount := tokens.BigInt()
+
+	s.locks.Lock(beneficiary.Hex())
+	defer s.locks.Unlock(beneficiary.Hex())
+
+	credit, err := s.prepaidCredit(beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	if credit.Cmp(amount) < 0 {
+		return nil, ErrCreditInsufficient
+	}
+
+	if err := s.store.Put(prepaidCreditKey(beneficiary), credit.Sub(credit, amount)); err != nil {
+		return nil, err
+	}
+	return s.AvailableBalance(ctx)
+}
+
+// Issue pays amount to the beneficiary with a cheque rounded up to the
+// granularity after deducting the prepaid credit. ErrNoChequeRequired is
+// returned if the credit covers the payment.
+func (s *GranularService) Issue(ctx context.Context, beneficiary common.Address, tokens Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+	amount := tokens.BigInt()
+
+	// the lock is held until the credit is updated, so that concurrent
+	// payments to the beneficiary do not use the same credit twice
+	s.locks.Lock(beneficiary.Hex())
+	defer s.locks.Unlock(beneficiary.Hex())
+
+	credit, err := s.prepaidCredit(beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	if credit.Cmp(amount) >= 0 {
+		return nil, ErrNoChequeRequired
+	}
+
+	due := new(big.Int).Sub(amount, credit)
+	rounded := s.round(due)
+
+	// the credit is stored before the cheque is sent, so that it is not lost
+	// if the cheque was sent but the credit could not be stored
+	return s.Service.Issue(ctx, beneficiary, Tokens{amount: rounded}, func(cheque *SignedCheque) error {
+		key := prepaidCreditKey(beneficiary)
+		if err := s.store.Put(key, new(big.Int).Sub(rounded, due)); err != nil {
+			return err
+		}
+		if err := sendChequeFunc(cheque); err != nil {
+			if rollbackErr := s.store.Put(key, credit); rollbackErr != nil {
+				return fmt.Errorf("%w: restore prepaid credit: %v", err, rollbackErr)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// PreviewIssue evaluates paying amount to the beneficiary. The amount of the
+// previewed cheque is zero if the prepaid credit covers the payment and
+// rounded up to the granularity otherwise.
+func (s *GranularService) PreviewIssue(ctx context.Context, beneficiary common.Address, tokens Tokens) (*IssuePreview, error) {
+	amount := tokens.BigInt()
+
+	credit, err := s.PrepaidCredit(beneficiary)
+	if err != nil {
+		return nil, err
+	}
+
+	if credit.Cmp(amount) >= 0 {
+		return s.Service.PreviewIssue(ctx, beneficiary, Tokens{})
+	}
+	return s.Service.PreviewIssue(ctx, beneficiary, Tokens{amount: s.round(new(big.Int).Sub(amount, credit))})
+}
+
+// round rounds the amount up to the next multiple of the granularity.
+func (s *GranularService) round(amount *big.Int) *big.Int {
+	rounded := new(big.Int).Add(amount, s.granularity)
+	rounded.Sub(rounded, big.NewInt(1))
+	rounded.Div(rounded, s.granularity)
+	return rounded.Mul(rounded, s.granularity)
+}
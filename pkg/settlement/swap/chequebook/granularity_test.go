@@ -0,0 +1,206 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func sendCheque(*chequebook.SignedCheque) error { return nil }
+
+func TestGranularService(t *testing.T) {
+	t.Parallel()
+
+	var issued []int64
+	service := chequebook.NewGranularService(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				issued = append(issued, amount.Int64())
+				return big.NewInt(1000), sendChequeFunc(&chequebook.SignedCheque{})
+			}),
+			mock.WithChequebookAvailableBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(900), nil
+			}),
+		),
+		storemock.NewStateStore(),
+		big.NewInt(100),
+	)
+
+	beneficiary := common.HexToAddress("0xbeef")
+
+	for _, tc := range []struct {
+		amount  int64
+		issued  []int64
+		credit  int64
+		balance int64
+	}{
+		// rounded up, the excess becomes credit
+		{amount: 30, issued: []int64{100}, credit: 70, balance: 1000},
+		// covered by the credit, no cheque is issued
+		{amount: 50, issued: []int64{100}, credit: 20, balance: 900},
+		// the credit is used before rounding
+		{amount: 120, issued: []int64{100, 100}, credit: 0, balance: 1000},
+		// multiples of the granularity are not rounded
+		{amount: 200, issued: []int64{100, 100, 200}, credit: 0, balance: 1000},
+	} {
+		balance, err := service.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(big.NewInt(tc.amount)), sendCheque)
+		if errors.Is(err, chequebook.ErrNoChequeRequired) {
+			balance, err = service.PayFromCredit(context.Background(), beneficiary, chequebook.MustNewTokens(big.NewInt(tc.amount)))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.Int64() != tc.balance {
+			t.Fatalf("amount %d: got balance %d, want %d", tc.amount, balance, tc.balance)
+		}
+		if len(issued) != len(tc.issued) || issued[len(issued)-1] != tc.issued[len(tc.issued)-1] {
+			t.Fatalf("amount %d: got issued %v, want %v", tc.amount, issued, tc.issued)
+		}
+		credit, err := service.PrepaidCredit(beneficiary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credit.Int64() != tc.credit {
+			t.Fatalf("amount %d: got credit %d, want %d", tc.amount, credit, tc.credit)
+		}
+	}
+
+	_, err := service.PayFromCredit(context.Background(), beneficiary, chequebook.MustNewTokens(big.NewInt(1)))
+	if !errors.Is(err, chequebook.ErrCreditInsufficient) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrCreditInsufficient)
+	}
+
+	credit, err := service.PrepaidCredit(common.HexToAddress("0xdead"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credit.Sign() != 0 {
+		t.Fatalf("got credit %d for unknown beneficiary", credit)
+	}
+}
+
+// TestGranularServiceConcurrentIssue checks that the credit stays consistent
+// with the issued cheques if payments to a beneficiary are made concurrently.
+func TestGranularServiceConcurrentIssue(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		issued int64
+	)
+	service := chequebook.NewGranularService(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				issued += amount.Int64()
+				mu.Unlock()
+				return big.NewInt(1000), sendChequeFunc(&chequebook.SignedCheque{})
+			}),
+		),
+		storemock.NewStateStore(),
+		big.NewInt(100),
+	)
+
+	beneficiary := common.HexToAddress("0xbeef")
+	const payments, amount = 10, 150
+
+	var wg sync.WaitGroup
+	for i := 0; i < payments; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the credit is always below the amount, so a cheque is issued
+			if _, err := service.Issue(context.Background(), beneficiary, chequebook.MustNewTokens(big.NewInt(amount)), sendCheque); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	credit, err := service.PrepaidCredit(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := issued - payments*amount; credit.Int64() != want {
+		t.Fatalf("got credit %d, want %d for %d issued", credit, want, issued)
+	}
+}
+
+// TestGranularServiceCreditStore checks that the prepaid credit is stored
+// before the cheque is sent and restored if sending fails.
+func TestGranularServiceCreditStore(t *testing.T) {
+	t.Parallel()
+
+	store := &failingStore{StateStorer: storemock.NewStateStore()}
+	service := chequebook.NewGranularService(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				if err := sendChequeFunc(&chequebook.SignedCheque{}); err != nil {
+					return nil, err
+				}
+				return big.NewInt(1000), nil
+			}),
+		),
+		store,
+		big.NewInt(100),
+	)
+	beneficiary := common.HexToAddress("0xbeef")
+	tokens := chequebook.MustNewTokens(big.NewInt(30))
+
+	// no cheque is sent if the credit cannot be stored
+	store.err = errors.New("put")
+	sent := false
+	_, err := service.Issue(context.Background(), beneficiary, tokens, func(*chequebook.SignedCheque) error {
+		sent = true
+		return nil
+	})
+	if !errors.Is(err, store.err) {
+		t.Fatalf("got error %v, want %v", err, store.err)
+	}
+	if sent {
+		t.Fatal("cheque sent without storing the credit")
+	}
+
+	// the credit is restored if the cheque cannot be sent
+	store.err = nil
+	errSend := errors.New("send")
+	_, err = service.Issue(context.Background(), beneficiary, tokens, func(*chequebook.SignedCheque) error {
+		return errSend
+	})
+	if !errors.Is(err, errSend) {
+		t.Fatalf("got error %v, want %v", err, errSend)
+	}
+	credit, err := service.PrepaidCredit(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credit.Sign() != 0 {
+		t.Fatalf("got credit %d after a failed payment, want 0", credit)
+	}
+}
+
+type failingStore struct {
+	storage.StateStorer
+	err error
+}
+
+func (s *failingStore) Put(key string, i interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.StateStorer.Put(key, i)
+}
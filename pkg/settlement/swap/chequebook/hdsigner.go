@@ -0,0 +1,170 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+)
+
+const (
+	// minSeedLength and maxSeedLength are the bounds of the seed length in bytes defined by BIP-32.
+	minSeedLength = 16
+	maxSeedLength = 64
+)
+
+var (
+	// ErrInvalidSeed is the error returned if the seed of an HD wallet is not between 16 and 64 bytes long.
+	ErrInvalidSeed = errors.New("invalid hd wallet seed")
+	// ErrInvalidDerivedKey is the error returned for the rare derivation paths which do not lead to a valid key.
+	ErrInvalidDerivedKey = errors.New("invalid derived key")
+)
+
+// IssuerChequeSigner is implemented by cheque signers whose key is not the
+// node key. New chequebooks are deployed with the key of the signer as issuer.
+type IssuerChequeSigner interface {
+	ChequeSigner
+	// Issuer returns the address of the signing key.
+	Issuer() common.Address
+}
+
+var _ IssuerChequeSigner = (*HDChequeSigner)(nil)
+
+// HDChequeSigner is a ChequeSigner signing with a key derived from the seed of
+// an HD wallet along a BIP-32 derivation path. Payment keys are rotated by
+// deriving along another path, which does not affect the node identity. The
+// key is zeroised when the signer is closed.
+type HDChequeSigner struct {
+	path   accounts.DerivationPath
+	issuer common.Address
+
+	mu     sync.RWMutex
+	key    *ecdsa.PrivateKey
+	signer ChequeSigner
+}
+
+// NewHDChequeSigner derives the cheque signing key from seed along path, for
+// example m/44'/60'/0'/0/0.
+func NewHDChequeSigner(seed []byte, path string, chainID int64) (*HDChequeSigner, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := DeriveKey(seed, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		zeroKey(key)
+		return nil, err
+	}
+
+	return &HDChequeSigner{
+		path:   derivationPath,
+		issuer: common.BytesToAddress(address),
+		key:    key,
+		signer: NewChequeSigner(crypto.NewDefaultSigner(key), chainID),
+	}, nil
+}
+
+// Issuer returns the address of the derived key.
+func (s *HDChequeSigner) Issuer() common.Address {
+	return s.issuer
+}
+
+// Path returns the derivation path of the key.
+func (s *HDChequeSigner) Path() string {
+	return s.path.String()
+}
+
+// Sign signs the cheque with the derived key.
+func (s *HDChequeSigner) Sign(cheque *Cheque) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.signer == nil {
+		return nil, ErrChequeSignerClosed
+	}
+	return s.signer.Sign(cheque)
+}
+
+// Close zeroises the key. Signing afterwards fails with ErrChequeSignerClosed.
+func (s *HDChequeSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key != nil {
+		zeroKey(s.key)
+		s.key = nil
+		s.signer = nil
+	}
+	return nil
+}
+
+// DeriveKey derives the private key at path from the seed of an HD wallet as
+// specified by BIP-32.
+func DeriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	if len(seed) < minSeedLength || len(seed) > maxSeedLength {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidSeed, len(seed))
+	}
+
+	curve := btcec.S256()
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	_, _ = mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(curve.N) >= 0 {
+		return nil, ErrInvalidDerivedKey
+	}
+
+	data := make([]byte, 37)
+	for _, index := range path {
+		if index >= 0x80000000 {
+			// hardened child keys are derived from the private key
+			data[0] = 0
+			key.FillBytes(data[1:33])
+		} else {
+			_, public := btcec.PrivKeyFromBytes(curve, key.FillBytes(make([]byte, 32)))
+			copy(data[:33], public.SerializeCompressed())
+		}
+		binary.BigEndian.PutUint32(data[33:], index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		_, _ = mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curve.N) >= 0 {
+			return nil, fmt.Errorf("%w: index %d", ErrInvalidDerivedKey, index)
+		}
+		key.Add(key, tweak).Mod(key, curve.N)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("%w: index %d", ErrInvalidDerivedKey, index)
+		}
+		chainCode = sum[32:]
+	}
+	for i := range data {
+		data[i] = 0
+	}
+
+	private, _ := btcec.PrivKeyFromBytes(curve, key.FillBytes(make([]byte, 32)))
+	key.SetInt64(0)
+	return private.ToECDSA(), nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestDeriveKey(t *testing.T) {
+	t.Parallel()
+
+	// test vector 1 of BIP-32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	for _, tc := range []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	} {
+		path, err := accounts.ParseDerivationPath(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := chequebook.DeriveKey(seed, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.D.FillBytes(make([]byte, 32))); got != tc.key {
+			t.Fatalf("path %s: got key %s, want %s", tc.path, got, tc.key)
+		}
+	}
+
+	if _, err := chequebook.DeriveKey(seed[:15], accounts.DefaultBaseDerivationPath); !errors.Is(err, chequebook.ErrInvalidSeed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidSeed)
+	}
+}
+
+func TestHDChequeSigner(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(10)
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	signer, err := chequebook.NewHDChequeSigner(seed, "m/44'/60'/0'/0/0", chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := chequebook.NewHDChequeSigner(seed, "m/44'/60'/0'/0/1", chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Issuer() == rotated.Issuer() {
+		t.Fatalf("got the same issuer %x for different paths", signer.Issuer())
+	}
+
+	cheque := &chequebook.Cheque{
+		Chequebook:       common.HexToAddress("0xfa02D396842E6e1D319E8E3D4D870338F791AA25"),
+		Beneficiary:      common.HexToAddress("0x98E6C644aFeB94BBfB9FF60EB26fc9D83BBEcA79"),
+		CumulativePayout: big.NewInt(500),
+	}
+	signature, err := signer.Sign(cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := chequebook.RecoverCheque(&chequebook.SignedCheque{Cheque: *cheque, Signature: signature}, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != signer.Issuer() {
+		t.Fatalf("cheque signed by %x, want %x", recovered, signer.Issuer())
+	}
+
+	if err := signer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(cheque); !errors.Is(err, chequebook.ErrChequeSignerClosed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeSignerClosed)
+	}
+
+	if _, err := chequebook.NewHDChequeSigner(seed, "m/invalid", chainID); err == nil {
+		t.Fatal("expected error for invalid derivation path")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// ErrImportBelowPaidOut is the error returned if an imported cumulative payout
+// is lower than the amount the chequebook already paid out to the beneficiary.
+var ErrImportBelowPaidOut = errors.New("imported cumulative payout below paid out amount")
+
+// ImportLastCheque records the cumulative payout of the last cheque issued to
+// the beneficiary, e.g. by the node this one replaces. Following cheques
+// continue from it so the beneficiary accepts them. The payout may not be
+// lower than what the chequebook already paid out on chain nor than the last
+// cheque known to this node. The cheque is signed like an issued one, so that
+// it can be sent to the beneficiary again. Importing the payout of the last
+// cheque again has no effect. A corrupted last cheque is replaced by the
+// imported one.
+func (s *service) ImportLastCheque(ctx context.Context, beneficiary common.Address, tokens Tokens) (*SignedCheque, error) {
+	cumulativePayout := tokens.BigInt()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	lastCumulativePayout := big.NewInt(0)
+	corrupted := false
+	lastCheque, err := s.lastCheque(ctx, beneficiary)
+	switch {
+	case errors.Is(err, ErrNoCheque):
+	case errors.Is(err, ErrCorruptedRecord):
+		corrupted = true
+	case err != nil:
+		return nil, err
+	case lastCheque.CumulativePayout.Cmp(cumulativePayout) == 0:
+		return lastCheque, nil
+	case lastCheque.CumulativePayout.Cmp(cumulativePayout) > 0:
+		return nil, fmt.Errorf("last cheque cumulative payout %d: %w", lastCheque.CumulativePayout, ErrChequeNotIncreasing)
+	default:
+		lastCumulativePayout = lastCheque.CumulativePayout
+	}
+
+	paidOut, err := s.contract.PaidOut(ctx, beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	if cumulativePayout.Cmp(paidOut) < 0 {
+		return nil, fmt.Errorf("paid out %d: %w", paidOut, ErrImportBelowPaidOut)
+	}
+
+	cheque := Cheque{
+		Chequebook:       s.address,
+		CumulativePayout: new(big.Int).Set(cumulativePayout),
+		Beneficiary:      beneficiary,
+	}
+	sig, err := s.chequeSigner.Sign(&cheque)
+	if err != nil {
+		return nil, err
+	}
+	signedCheque := &SignedCheque{
+		Cheque:    cheque,
+		Signature: sig,
+	}
+
+	s.issuedMu.Lock()
+	defer s.issuedMu.Unlock()
+
+	if err := s.store.Put(lastIssuedChequeKey(beneficiary), signedCheque); err != nil {
+		return nil, err
+	}
+
+	// the payout of the corrupted cheque is unknown, so the total is recomputed
+	if corrupted {
+		if _, err := s.repairTotalIssued(ctx); err != nil {
+			return nil, err
+		}
+		return signedCheque, nil
+	}
+
+	totalIssued, err := s.totalIssued()
+	if err != nil {
+		return nil, err
+	}
+	totalIssued.Add(totalIssued, new(big.Int).Sub(cumulativePayout, lastCumulativePayout))
+	if err := s.store.Put(totalIssuedKey, totalIssued); err != nil {
+		return nil, err
+	}
+
+	return signedCheque, nil
+}
+
+// ImportCheque verifies and stores the last cheque received from a chequebook,
+// e.g. by the node this one replaces, so that it can be cashed and following
+// cheques are only accepted if they increase it. The cheque has to be validly
+// signed and may not be lower than what the chequebook already paid out on
+// chain nor than the last cheque known to this node. Importing the last cheque
+// again has no effect. A corrupted last cheque is replaced by the imported one.
+func (s *chequeStore) ImportCheque(ctx context.Context, cheque *SignedCheque) error {
+	return s.importCheque(ctx, cheque, s.factory.VerifyChequebook)
+}
+
+// ImportCheques imports the cheques like ImportCheque and returns the errors
+// in the order of the cheques. Every chequebook without a stored cheque is
+// verified once, all of them in a batch if the factory supports it, and the
+// cheques are verified concurrently by a bounded pool of workers. Of several
+// cheques from the same chequebook the highest one is kept, lower ones may
+// fail with ErrChequeNotIncreasing.
+func (s *chequeStore) ImportCheques(ctx context.Context, cheques []*SignedCheque) []error {
+	var (
+		chequebooks []common.Address
+		index       = make(map[common.Address]int)
+	)
+	for _, cheque := range cheques {
+		if _, ok := index[cheque.Chequebook]; ok {
+			continue
+		}
+		if _, err := s.lastReceivedCheque(ctx, cheque.Chequebook, cheque.Beneficiary); err == nil {
+			continue
+		}
+		index[cheque.Chequebook] = len(chequebooks)
+		chequebooks = append(chequebooks, cheque.Chequebook)
+	}
+
+	verified := verifyChequebooks(ctx, s.factory, chequebooks)
+	verifyChequebook := func(ctx context.Context, chequebook common.Address) error {
+		i, ok := index[chequebook]
+		if !ok {
+			// the stored cheque was lost or corrupted in the meantime
+			return s.factory.VerifyChequebook(ctx, chequebook)
+		}
+		return verified[i]
+	}
+
+	errs := make([]error, len(cheques))
+	forEach(len(cheques), func(i int) {
+		errs[i] = s.importCheque(ctx, cheques[i], verifyChequebook)
+	})
+	return errs
+}
+
+// importCheque imports the cheque. If no cheque of its chequebook is stored
+// for its beneficiary, the chequebook is checked with verifyChequebook. The verification happens
+// without holding the lock so that cheques can be imported concurrently.
+func (s *chequeStore) importCheque(ctx context.Context, cheque *SignedCheque, verifyChequebook func(context.Context, common.Address) error) error {
+	accepted, err := s.acceptsBeneficiary(cheque.Beneficiary)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return ErrWrongBeneficiary
+	}
+
+	stored, known, err := s.storedImport(ctx, cheque)
+	if err != nil || known {
+		return err
+	}
+	if !stored {
+		if err := verifyChequebook(ctx, cheque.Chequebook); err != nil {
+			return err
+		}
+	}
+
+	contract := newChequebookContract(cheque.Chequebook, s.transactionService)
+	if err := s.verifySignature(ctx, contract, cheque); err != nil {
+		return err
+	}
+
+	paidOut, err := contract.PaidOut(ctx, cheque.Beneficiary)
+	if err != nil {
+		return err
+	}
+	if cheque.CumulativePayout.Cmp(paidOut) < 0 {
+		return fmt.Errorf("paid out %d: %w", paidOut, ErrImportBelowPaidOut)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// another cheque of the chequebook might have been stored in the meantime
+	_, known, err = s.storedImport(ctx, cheque)
+	if err != nil || known {
+		return err
+	}
+
+	if err := s.store.PutContext(ctx, lastReceivedChequeKey(cheque.Chequebook, cheque.Beneficiary), cheque); err != nil {
+		return err
+	}
+	// a corrupted cheque stored before the cheques were kept per beneficiary
+	// is replaced, too
+	return s.store.Delete(legacyLastReceivedChequeKey(cheque.Chequebook))
+}
+
+// storedImport reports whether a cheque of the chequebook is stored for the
+// beneficiary and whether it is the imported one. It fails with
+// ErrChequeNotIncreasing if the stored cheque is higher. Corrupted cheques
+// count as not stored.
+func (s *chequeStore) storedImport(ctx context.Context, cheque *SignedCheque) (stored, known bool, err error) {
+	lastReceivedCheque, err := s.lastReceivedCheque(ctx, cheque.Chequebook, cheque.Beneficiary)
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, ErrCorruptedRecord):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	}
+
+	switch lastReceivedCheque.CumulativePayout.Cmp(cheque.CumulativePayout) {
+	case 0:
+		return true, true, nil
+	case 1:
+		return true, false, fmt.Errorf("last cheque cumulative payout %d: %w", lastReceivedCheque.CumulativePayout, ErrChequeNotIncreasing)
+	}
+	return true, false, nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequebookImportLastCheque(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	store := storemock.NewStateStore()
+	sig := []byte{1, 2, 3}
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(40).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(40).FillBytes(make([]byte, 32)), "totalPaidOut"),
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(40).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		store,
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				if cheque.CumulativePayout.Cmp(big.NewInt(100)) != 0 {
+					t.Fatalf("signing wrong cheque %v", cheque)
+				}
+				return sig, nil
+			},
+		},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheque, err := chequebookService.ImportLastCheque(context.Background(), beneficiary, chequebook.TokensFromUint64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cheque.CumulativePayout.Cmp(big.NewInt(100)) != 0 || cheque.Beneficiary != beneficiary || cheque.Chequebook != address {
+		t.Fatalf("wrong cheque imported: %v", cheque)
+	}
+
+	lastCheque, err := chequebookService.LastCheque(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastCheque.Equal(cheque) {
+		t.Fatalf("wrong cheque stored. wanted %v got %v", cheque, lastCheque)
+	}
+	// the imported cheque is signed so that it can be sent again
+	if !bytes.Equal(lastCheque.Signature, sig) {
+		t.Fatalf("wrong signature stored. wanted %x got %x", sig, lastCheque.Signature)
+	}
+
+	// the imported cheque is counted as issued but not yet cashed
+	available, err := chequebookService.AvailableBalance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(940)) != 0 {
+		t.Fatalf("wrong available balance. wanted %d got %d", 940, available)
+	}
+
+	// importing the same payout again has no effect and does not query the chain
+	if _, err := chequebookService.ImportLastCheque(context.Background(), beneficiary, chequebook.TokensFromUint64(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chequebookService.ImportLastCheque(context.Background(), beneficiary, chequebook.TokensFromUint64(90))
+	if !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrChequeNotIncreasing, err)
+	}
+
+	_, err = chequebookService.ImportLastCheque(context.Background(), common.HexToAddress("0xeeee"), chequebook.TokensFromUint64(30))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestChequebookImportLastChequeBelowPaidOut(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, big.NewInt(200).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chequebookService.ImportLastCheque(context.Background(), beneficiary, chequebook.TokensFromUint64(100))
+	if !errors.Is(err, chequebook.ErrImportBelowPaidOut) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrImportBelowPaidOut, err)
+	}
+
+	if _, err := chequebookService.LastCheque(beneficiary); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrNoCheque, err)
+	}
+}
+
+func TestImportCheque(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	chainID := int64(1)
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(100),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	var verifiedWithFactory bool
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				verifiedWithFactory = true
+				return nil
+			},
+		},
+		chainID,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(60).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+				// the issuer is not queried again
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(60).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	if err := chequestore.ImportCheque(context.Background(), cheque); err != nil {
+		t.Fatal(err)
+	}
+	if !verifiedWithFactory {
+		t.Fatal("did not verify with factory")
+	}
+
+	lastCheque, err := chequestore.LastCheque(chequebookAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cheque.Equal(lastCheque) {
+		t.Fatalf("stored wrong cheque. wanted %v, got %v", cheque, lastCheque)
+	}
+
+	// importing the same cheque again has no effect and does not query the chain
+	if err := chequestore.ImportCheque(context.Background(), cheque); err != nil {
+		t.Fatal(err)
+	}
+
+	lower := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(50),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+	if err := chequestore.ImportCheque(context.Background(), lower); !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrChequeNotIncreasing, err)
+	}
+
+	wrongBeneficiary := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("0xaaaa"),
+			CumulativePayout: big.NewInt(200),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+	if err := chequestore.ImportCheque(context.Background(), wrongBeneficiary); !errors.Is(err, chequebook.ErrWrongBeneficiary) {
+		t.Fatalf("wrong error. wanted %v got %v", chequebook.ErrWrongBeneficiary, err)
+	}
+
+	higher := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(200),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+	verifiedWithFactory = false
+	if err := chequestore.ImportCheque(context.Background(), higher); err != nil {
+		t.Fatal(err)
+	}
+	if verifiedWithFactory {
+		t.Fatal("verified known chequebook with factory again")
+	}
+}
+
+func TestImportCheques(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	trusted1 := common.HexToAddress("0xeee1")
+	trusted2 := common.HexToAddress("0xeee2")
+	untrusted := common.HexToAddress("0xeee3")
+
+	cheque := func(chequebookAddress common.Address) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(100),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+	}
+
+	var batches [][]common.Address
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&batchFactoryMock{
+			factoryMock: factoryMock{
+				verifyChequebook: func(ctx context.Context, address common.Address) error {
+					t.Fatal("chequebook verified one by one")
+					return nil
+				},
+			},
+			verifyChequebooks: func(ctx context.Context, chequebooks []common.Address) []error {
+				batches = append(batches, chequebooks)
+				errs := make([]error, len(chequebooks))
+				for i, c := range chequebooks {
+					if c == untrusted {
+						errs[i] = chequebook.ErrNotDeployedByFactory
+					}
+				}
+				return errs
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if string(request.Data[:4]) == string(chequebookABI.Methods["issuer"].ID) {
+					return issuer.Hash().Bytes(), nil
+				}
+				return big.NewInt(0).FillBytes(make([]byte, 32)), nil
+			}),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	cheques := []*chequebook.SignedCheque{cheque(trusted1), cheque(trusted2), cheque(trusted1), cheque(untrusted)}
+	errs := chequestore.ImportCheques(context.Background(), cheques)
+	if len(errs) != len(cheques) {
+		t.Fatalf("got %d errors, want %d", len(errs), len(cheques))
+	}
+	for i, err := range errs[:3] {
+		if err != nil {
+			t.Fatalf("cheque %d: got error %v, want none", i, err)
+		}
+	}
+	if !errors.Is(errs[3], chequebook.ErrNotDeployedByFactory) {
+		t.Fatalf("got error %v, want %v", errs[3], chequebook.ErrNotDeployedByFactory)
+	}
+
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("got batches %v, want every chequebook verified once in one batch", batches)
+	}
+
+	for _, c := range []common.Address{trusted1, trusted2} {
+		lastCheque, err := chequestore.LastCheque(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cheque(c).Equal(lastCheque) {
+			t.Fatalf("stored wrong cheque. wanted %v, got %v", cheque(c), lastCheque)
+		}
+	}
+	if _, err := chequestore.LastCheque(untrusted); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	chaincfg "github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/sctx"
@@ -24,6 +25,9 @@ import (
 const (
 	chequebookKey           = "swap_chequebook"
 	ChequebookDeploymentKey = "swap_chequebook_transaction_deployment"
+	// deploymentDepositKey is the key under which the initial deposit sent
+	// with the deployment transaction is stored until it was verified.
+	deploymentDepositKey = "swap_chequebook_deployment_deposit"
 
 	balanceCheckBackoffDuration = 20 * time.Second
 	balanceCheckMaxRetries      = 10
@@ -41,12 +45,13 @@ func checkBalance(
 	swapBackend transaction.Backend,
 	chainId int64,
 	overlayEthAddress common.Address,
+	tokenOwner common.Address,
 	erc20Token erc20.Service,
 ) error {
 	timeoutCtx, cancel := context.WithTimeout(ctx, balanceCheckBackoffDuration*time.Duration(balanceCheckMaxRetries))
 	defer cancel()
 	for {
-		erc20Balance, err := erc20Token.BalanceOf(timeoutCtx, overlayEthAddress)
+		erc20Balance, err := erc20Token.BalanceOf(timeoutCtx, tokenOwner)
 		if err != nil {
 			return err
 		}
@@ -90,13 +95,13 @@ func checkBalance(
 
 			if insufficientETH && insufficientERC20 {
 				msg := fmt.Sprintf("cannot continue until there is at least min %s (for Gas) and at least min %s available on address", nativeTokenName, swarmTokenName)
-				logger.Warning(msg, "min_xdai_amount", neededETH, "min_bzz_amount", neededERC20, "address", overlayEthAddress)
+				logger.Warning(msg, "min_native_token_amount", neededETH, "min_bzz_amount", neededERC20, "address", overlayEthAddress, "token_address", tokenOwner)
 			} else if insufficientETH {
 				msg := fmt.Sprintf("cannot continue until there is at least min %s (for Gas) available on address", nativeTokenName)
-				logger.Warning(msg, "min_xdai_amount", neededETH, "address", overlayEthAddress)
+				logger.Warning(msg, "min_native_token_amount", neededETH, "address", overlayEthAddress)
 			} else {
 				msg := fmt.Sprintf("cannot continue until there is at least min %s available on address", swarmTokenName)
-				logger.Warning(msg, "min_bzz_amount", neededERC20, "address", overlayEthAddress)
+				logger.Warning(msg, "min_bzz_amount", neededERC20, "address", tokenOwner)
 			}
 			if chainId == chaincfg.Testnet.ChainID {
 				logger.Warning("learn how to fund your node by visiting our docs at https://docs.ethswarm.org/docs/installation/fund-your-node")
@@ -117,7 +122,9 @@ func checkBalance(
 	}
 }
 
-// Init initialises the chequebook service.
+// Init initialises the chequebook service. A new chequebook is issued by
+// overlayEthAddress, or by the key of the cheque signer if it is an
+// IssuerChequeSigner. Deposits are paid from the tokens held by tokenOwner.
 func Init(
 	ctx context.Context,
 	chequebookFactory Factory,
@@ -128,6 +135,7 @@ func Init(
 	swapBackend transaction.Backend,
 	chainId int64,
 	overlayEthAddress common.Address,
+	tokenOwner common.Address,
 	chequeSigner ChequeSigner,
 	erc20Service erc20.Service,
 ) (chequebookService Service, err error) {
@@ -139,6 +147,11 @@ func Init(
 		return nil, err
 	}
 
+	issuer := overlayEthAddress
+	if signer, ok := chequeSigner.(IssuerChequeSigner); ok {
+		issuer = signer.Issuer()
+	}
+
 	var chequebookAddress common.Address
 	err = stateStore.Get(chequebookKey, &chequebookAddress)
 	if err != nil {
@@ -153,7 +166,22 @@ func Init(
 		}
 		if errors.Is(err, storage.ErrNotFound) {
 			logger.Info("no chequebook found, deploying new one.")
-			err = checkBalance(ctx, logger, swapInitialDeposit, swapBackend, chainId, overlayEthAddress, erc20Service)
+
+			// the factory can only fund the chequebook at deployment if the
+			// deposit is paid by the issuer itself
+			depositFactory, ok := chequebookFactory.(DepositFactory)
+			depositOnDeploy := ok && swapInitialDeposit.Sign() > 0 && tokenOwner == overlayEthAddress
+			required := swapInitialDeposit
+			var fee *big.Int
+			if depositOnDeploy {
+				fee, err = depositFactory.DeploymentFee(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("deployment fee: %w", err)
+				}
+				required = new(big.Int).Add(swapInitialDeposit, fee)
+			}
+
+			err = checkBalance(ctx, logger, required, swapBackend, chainId, overlayEthAddress, tokenOwner, erc20Service)
 			if err != nil {
 				return nil, err
 			}
@@ -165,7 +193,11 @@ func Init(
 			}
 
 			// if we don't yet have a chequebook, deploy a new one
-			txHash, err = chequebookFactory.Deploy(ctx, overlayEthAddress, big.NewInt(0), common.BytesToHash(nonce))
+			if depositOnDeploy {
+				txHash, err = deployWithDeposit(ctx, logger, depositFactory, stateStore, transactionService, erc20Service, overlayEthAddress, issuer, common.BytesToHash(nonce), swapInitialDeposit, fee)
+			} else {
+				txHash, err = chequebookFactory.Deploy(ctx, issuer, big.NewInt(0), common.BytesToHash(nonce))
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -193,20 +225,41 @@ func Init(
 			return nil, err
 		}
 
-		chequebookService, err = New(transactionService, chequebookAddress, overlayEthAddress, stateStore, chequeSigner, erc20Service)
+		chequebookService, err = New(transactionService, chequebookAddress, tokenOwner, stateStore, chequeSigner, erc20Service, swapBackend)
 		if err != nil {
 			return nil, err
 		}
 
-		if swapInitialDeposit.Cmp(big.NewInt(0)) != 0 {
+		var deployDeposit *big.Int
+		err = stateStore.Get(deploymentDepositKey, &deployDeposit)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		if deployDeposit != nil {
+			if err = verifyDeployDeposit(ctx, chequebookService, deployDeposit); err != nil {
+				return nil, err
+			}
+			if err = stateStore.Delete(deploymentDepositKey); err != nil {
+				return nil, err
+			}
+			logger.Info("chequebook funded at deployment", "amount", deployDeposit)
+		} else if swapInitialDeposit.Cmp(big.NewInt(0)) != 0 {
 			logger.Info("depositing token into new chequebook", "amount", swapInitialDeposit)
-			depositHash, err := chequebookService.Deposit(ctx, swapInitialDeposit)
+			deposit, err := NewTokens(swapInitialDeposit)
+			if err != nil {
+				return nil, err
+			}
+			depositHash, err := chequebookService.Deposit(ctx, deposit)
 			if err != nil {
 				return nil, err
 			}
 
 			logger.Info("sent deposit transaction", "tx", depositHash)
-			err = chequebookService.WaitForDeposit(ctx, depositHash)
+			err = chequebookService.WaitForDepositWithProgress(ctx, depositHash, func(p DepositProgress) {
+				if p.Mined && !p.Final {
+					logger.Info("waiting for deposit confirmations", "tx", depositHash, "block", p.BlockNumber, "confirmations", p.Confirmations, "required", p.Required)
+				}
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -214,7 +267,17 @@ func Init(
 			logger.Info("successfully deposited to chequebook")
 		}
 	} else {
-		chequebookService, err = New(transactionService, chequebookAddress, overlayEthAddress, stateStore, chequeSigner, erc20Service)
+		// the state of the chequebook was not namespaced by its address before
+		var migrated int
+		migrated, err = namespacedStore(stateStore, chequebookAddress).Migrate()
+		if err != nil {
+			return nil, fmt.Errorf("migrate chequebook state: %w", err)
+		}
+		if migrated > 0 {
+			logger.Info("migrated chequebook state", "chequebook_address", chequebookAddress, "keys", migrated)
+		}
+
+		chequebookService, err = New(transactionService, chequebookAddress, tokenOwner, stateStore, chequeSigner, erc20Service, swapBackend)
 		if err != nil {
 			return nil, err
 		}
@@ -222,6 +285,17 @@ func Init(
 		logger.Info("using existing chequebook", "chequebook_address", chequebookAddress)
 	}
 
+	// cheques signed by another key than the issuer would bounce
+	if issuer != overlayEthAddress {
+		chequebookIssuer, err := newChequebookContract(chequebookService.Address(), transactionService).Issuer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if chequebookIssuer != issuer {
+			return nil, fmt.Errorf("chequebook issuer %x, signer %x: %w", chequebookIssuer, issuer, ErrChequeSignerNotIssuer)
+		}
+	}
+
 	// regardless of how the chequebook service was initialised make sure that the chequebook is valid
 	err = chequebookFactory.VerifyChequebook(ctx, chequebookService.Address())
 	if err != nil {
@@ -230,3 +304,48 @@ func Init(
 
 	return chequebookService, nil
 }
+
+// deployWithDeposit approves the factory to transfer the initial deposit and
+// the deployment fee from owner and deploys a chequebook of issuer funded with
+// the deposit. The
+// deposit is stored so that the balance of the chequebook is verified once the
+// deployment is confirmed, also after a restart.
+func deployWithDeposit(
+	ctx context.Context,
+	logger log.Logger,
+	factory DepositFactory,
+	stateStore storage.StateStorer,
+	transactionService transaction.Service,
+	erc20Service erc20.Service,
+	owner common.Address,
+	issuer common.Address,
+	nonce common.Hash,
+	deposit, fee *big.Int,
+) (common.Hash, error) {
+	spender := factory.Address()
+
+	allowance := new(big.Int).Add(deposit, fee)
+	current, err := erc20Service.Allowance(ctx, owner, spender)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if current.Cmp(allowance) < 0 {
+		logger.Info("approving factory to transfer initial deposit", "amount", deposit, "fee", fee)
+		approveHash, err := erc20Service.Approve(ctx, spender, allowance)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		receipt, err := transactionService.WaitForReceipt(ctx, approveHash)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return common.Hash{}, transaction.ErrTransactionReverted
+		}
+	}
+
+	if err = stateStore.Put(deploymentDepositKey, deposit); err != nil {
+		return common.Hash{}, err
+	}
+	return factory.DeployWithDeposit(ctx, issuer, big.NewInt(0), nonce, deposit)
+}
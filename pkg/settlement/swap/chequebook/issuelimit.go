@@ -0,0 +1,93 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/ratelimit"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+)
+
+// ErrIssueRateLimited is the error returned if more cheques are issued for a
+// beneficiary than the rate limit allows.
+var ErrIssueRateLimited = errors.New("cheque issuance rate limited")
+
+// rateLimitedService is a Service which limits the rate of issued cheques per beneficiary.
+type rateLimitedService struct {
+	Service
+	limiter *ratelimit.Limiter
+	// refill is the time in which the limit of a beneficiary is fully
+	// restored. Its limiter is dropped after being idle for this long, as a
+	// new one allows the same.
+	refill time.Duration
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	lastIssue map[common.Address]time.Time
+	lastPrune time.Time
+}
+
+// NewIssueRateLimiter wraps the service so that at most burst cheques can be
+// issued for a beneficiary at once, refilled by one cheque every interval.
+// Issuing beyond the limit fails with ErrIssueRateLimited before anything is
+// signed, which protects the available balance against accounting bugs which
+// would otherwise send a flood of cheques to a single peer.
+func NewIssueRateLimiter(service Service, interval time.Duration, burst int) Service {
+	return &rateLimitedService{
+		Service:   service,
+		limiter:   ratelimit.New(interval, burst),
+		refill:    interval * time.Duration(burst),
+		clock:     clock.System,
+		lastIssue: make(map[common.Address]time.Time),
+	}
+}
+
+func (s *rateLimitedService) Issue(ctx context.Context, beneficiary common.Address, amount Tokens, sendChequeFunc SendChequeFunc) (*big.Int, error) {
+	s.track(beneficiary)
+	if !s.limiter.Allow(beneficiary.Hex(), 1) {
+		return nil, ErrIssueRateLimited
+	}
+	return s.Service.Issue(ctx, beneficiary, amount, sendChequeFunc)
+}
+
+// track records the issuance for the beneficiary and drops the limiters of
+// the beneficiaries idle for longer than the refill time, at most once per
+// refill time, so that the limiters of past peers are not kept forever.
+func (s *rateLimitedService) track(beneficiary common.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.lastIssue[beneficiary] = now
+	if now.Sub(s.lastPrune) < s.refill {
+		return
+	}
+	s.lastPrune = now
+	for b, last := range s.lastIssue {
+		if now.Sub(last) >= s.refill {
+			s.limiter.Clear(b.Hex())
+			delete(s.lastIssue, b)
+		}
+	}
+}
+
+func (s *rateLimitedService) PreviewIssue(ctx context.Context, beneficiary common.Address, amount Tokens) (*IssuePreview, error) {
+	preview, err := s.Service.PreviewIssue(ctx, beneficiary, amount)
+	if err != nil {
+		return nil, err
+	}
+	check := PolicyCheck{Policy: PolicyRateLimit}
+	if !s.limiter.Peek(beneficiary.Hex(), 1) {
+		check.Err = ErrIssueRateLimited
+	}
+	preview.Policies = append(preview.Policies, check)
+	return preview, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+)
+
+func TestIssueRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	var issued int
+	service := chequebook.NewIssueRateLimiter(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				issued++
+				return big.NewInt(0), nil
+			}),
+		),
+		time.Hour,
+		2,
+	)
+
+	beneficiary := common.HexToAddress("0xbeef")
+	other := common.HexToAddress("0xdead")
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Issue(context.Background(), beneficiary, chequebook.TokensFromUint64(1), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := service.Issue(context.Background(), beneficiary, chequebook.TokensFromUint64(1), nil)
+	if !errors.Is(err, chequebook.ErrIssueRateLimited) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrIssueRateLimited)
+	}
+
+	// the limit applies per beneficiary
+	if _, err := service.Issue(context.Background(), other, chequebook.TokensFromUint64(1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if issued != 3 {
+		t.Fatalf("got %d issued cheques, want 3", issued)
+	}
+}
+
+func TestIssueRateLimiterDropsIdle(t *testing.T) {
+	t.Parallel()
+
+	service := chequebook.NewIssueRateLimiter(
+		mock.NewChequebook(
+			mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				return big.NewInt(0), nil
+			}),
+		),
+		time.Hour,
+		2,
+	)
+	clock := clockmock.New(time.Unix(0, 0))
+	chequebook.SetIssueRateLimiterClock(service, clock)
+
+	beneficiary := common.HexToAddress("0xbeef")
+	other := common.HexToAddress("0xdead")
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Issue(context.Background(), beneficiary, chequebook.TokensFromUint64(1), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.Issue(context.Background(), other, chequebook.TokensFromUint64(1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := chequebook.RateLimitedBeneficiaries(service); got != 2 {
+		t.Fatalf("got %d tracked beneficiaries, want 2", got)
+	}
+
+	// after the refill time the limiters of the idle beneficiaries are dropped
+	clock.Advance(2 * time.Hour)
+	if _, err := service.Issue(context.Background(), other, chequebook.TokensFromUint64(1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := chequebook.RateLimitedBeneficiaries(service); got != 1 {
+		t.Fatalf("got %d tracked beneficiaries, want 1", got)
+	}
+
+	// a dropped limiter allows the full burst again
+	if _, err := service.Issue(context.Background(), beneficiary, chequebook.TokensFromUint64(1), nil); err != nil {
+		t.Fatal(err)
+	}
+}
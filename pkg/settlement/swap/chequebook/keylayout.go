@@ -0,0 +1,151 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"github.com/ethersphere/bee/pkg/settlement/keylayout"
+	"github.com/ethersphere/bee/pkg/statestore/namespaced"
+)
+
+// ownNamespace is the namespace of the state of the own chequebook in the
+// key patterns, see namespacedStore.
+const ownNamespace = "<namespace>" + namespaced.Separator
+
+// KeyLayout describes the statestore keys of the chequebook, the cheque store
+// and the cashout service.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     chequebookKey,
+		Value:       "common.Address",
+		Version:     1,
+		Description: "address of the own chequebook",
+	},
+	{
+		Pattern:     ChequebookDeploymentKey,
+		Value:       "common.Hash",
+		Version:     1,
+		Description: "deployment transaction of the own chequebook",
+	},
+	{
+		Pattern:     deploymentDepositKey,
+		Value:       "big.Int",
+		Version:     1,
+		Description: "initial deposit sent with the deployment until it was verified",
+	},
+	{
+		Pattern:     lastIssuedChequeKeyPrefix + ownNamespace + "<beneficiary>",
+		Value:       "chequebook.SignedCheque",
+		Version:     3,
+		Description: "last cheque issued to the beneficiary and its signature, stored with a checksum",
+	},
+	{
+		Pattern:     totalIssuedKey + ownNamespace,
+		Value:       "big.Int",
+		Version:     3,
+		Description: "total amount issued by the own chequebook, stored with a checksum",
+	},
+	{
+		Pattern:     issuedChecksumsMigratedKey + ownNamespace,
+		Value:       "bool",
+		Version:     1,
+		Description: "set once checksums were added to the issued cheques and the total issued counter",
+	},
+	{
+		Pattern:     chequeHistoryKeyPrefix + ownNamespace + "<beneficiary>_<index>",
+		Value:       "chequebook.IssuedCheque",
+		Version:     1,
+		Description: "cheque issued to the beneficiary, indexed in the order of issuance",
+	},
+	{
+		Pattern:     chequeHistoryKeyPrefix + ownNamespace + "len_<beneficiary>",
+		Value:       "uint64",
+		Version:     1,
+		Description: "number of cheques in the history of the beneficiary",
+	},
+	{
+		Pattern:     prepaidCreditKeyPrefix + ownNamespace + "<beneficiary>",
+		Value:       "big.Int",
+		Version:     1,
+		Description: "amount issued to the beneficiary beyond the debt when rounding cheques",
+	},
+	{
+		Pattern:     depositKeyPrefix + ownNamespace + "<block>_<log>",
+		Value:       "chequebook.Deposit",
+		Version:     2,
+		Description: "deposit into the own chequebook found on chain",
+	},
+	{
+		Pattern:     depositLastBlockKey + ownNamespace,
+		Value:       "logcursor.Cursor",
+		Version:     2,
+		Description: "last block scanned for deposits",
+	},
+	{
+		Pattern:     lastReceivedChequePrefix + "_<chequebook>_<beneficiary>",
+		Value:       "chequebook.SignedCheque",
+		Version:     3,
+		Description: "last cheque received from the chequebook for the beneficiary, stored with a checksum",
+	},
+	{
+		Pattern:     receivedChecksumsMigratedKey,
+		Value:       "bool",
+		Version:     1,
+		Description: "set once checksums were added to the received cheques",
+	},
+	{
+		Pattern:     chequebookIssuerPrefix + "<chequebook>",
+		Value:       "common.Address",
+		Version:     1,
+		Description: "issuer of the chequebook found on chain",
+	},
+	{
+		Pattern:     verificationKeyPrefix + "<chequebook>",
+		Value:       "chequebook.verificationResult",
+		Version:     1,
+		Description: "cached result of the verification of the chequebook with the trusted factories",
+	},
+	{
+		Pattern:     reputationKeyPrefix + "<chequebook>",
+		Value:       "chequebook.reputation",
+		Version:     1,
+		Description: "reputation of the chequebook from the cheques verified fully",
+	},
+	{
+		Pattern:     beneficiaryRotationKey,
+		Value:       "chequebook.BeneficiaryRotation",
+		Version:     1,
+		Description: "beneficiary expected in received cheques and the previous ones still accepted",
+	},
+	{
+		Pattern:     cashoutActionKeyPrefix + "<chequebook>",
+		Value:       "chequebook.cashoutAction",
+		Version:     1,
+		Description: "last cashout transaction of the chequebook",
+	},
+	{
+		Pattern:     cashoutAttemptKeyPrefix + "<chequebook>_<time>",
+		Value:       "chequebook.CashoutAttempt",
+		Version:     1,
+		Description: "attempt to cash a cheque of the chequebook",
+	},
+	{
+		Pattern:     cashoutTransactionKeyPrefix + "<tx>",
+		Value:       "chequebook.cashoutTransaction",
+		Version:     1,
+		Description: "cheques cashed by the cashout transaction",
+	},
+	{
+		Pattern:     chequeCashoutKeyPrefix + "<chequebook>_<tx>",
+		Value:       "chequebook.ChequeCashout",
+		Version:     1,
+		Description: "cashout transaction sent for a cheque of the chequebook",
+	},
+	{
+		Pattern:     assignmentKeyPrefix + "<chequebook>",
+		Value:       "chequebook.ChequeAssignment",
+		Version:     1,
+		Description: "last cheque of the chequebook assigned to a third party cashier",
+	},
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/keystore"
+)
+
+var (
+	// ErrChequeSignerClosed is the error returned when signing with a closed keystore signer.
+	ErrChequeSignerClosed = errors.New("cheque signer closed")
+	// ErrPassphraseRotationUnsupported is the error returned if the cheque signer key is not stored in a keystore.
+	ErrPassphraseRotationUnsupported = errors.New("cheque signer passphrase cannot be rotated")
+	// ErrChequeSignerNotIssuer is the error returned if the cheque signer key is not the key of the chequebook issuer.
+	ErrChequeSignerNotIssuer = errors.New("cheque signer key is not the chequebook issuer")
+)
+
+// PassphraseRotator is implemented by cheque signers whose key is stored in
+// an encrypted keystore.
+type PassphraseRotator interface {
+	// RotatePassphrase re-encrypts the keystore with the new passphrase.
+	RotatePassphrase(oldPassphrase, newPassphrase string) error
+}
+
+var (
+	_ ChequeSigner      = (*KeystoreChequeSigner)(nil)
+	_ PassphraseRotator = (*KeystoreChequeSigner)(nil)
+)
+
+// KeystoreChequeSigner is a ChequeSigner signing with a key loaded from an
+// encrypted keystore. The passphrase is not kept in memory and the key is
+// zeroised when the signer is closed.
+type KeystoreChequeSigner struct {
+	keystore keystore.Service
+	name     string
+	chainID  int64
+
+	mu     sync.RWMutex
+	key    *ecdsa.PrivateKey
+	signer ChequeSigner
+}
+
+// NewKeystoreChequeSigner loads the key with the given name from the keystore.
+// The key must exist and belong to the issuer of the chequebook.
+func NewKeystoreChequeSigner(ks keystore.Service, name, passphrase string, issuer common.Address, chainID int64) (*KeystoreChequeSigner, error) {
+	exists, err := ks.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", name, keystore.ErrKeyNotFound)
+	}
+
+	key, _, err := ks.Key(name, passphrase, crypto.EDGSecp256_K1)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		zeroKey(key)
+		return nil, err
+	}
+	if common.BytesToAddress(address) != issuer {
+		zeroKey(key)
+		return nil, fmt.Errorf("%x: %w", address, ErrChequeSignerNotIssuer)
+	}
+
+	return &KeystoreChequeSigner{
+		keystore: ks,
+		name:     name,
+		chainID:  chainID,
+		key:      key,
+		signer:   NewChequeSigner(crypto.NewDefaultSigner(key), chainID),
+	}, nil
+}
+
+// Sign signs the cheque with the keystore key.
+func (s *KeystoreChequeSigner) Sign(cheque *Cheque) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.signer == nil {
+		return nil, ErrChequeSignerClosed
+	}
+	return s.signer.Sign(cheque)
+}
+
+// RotatePassphrase re-encrypts the keystore with the new passphrase. The
+// loaded key is not affected.
+func (s *KeystoreChequeSigner) RotatePassphrase(oldPassphrase, newPassphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.signer == nil {
+		return ErrChequeSignerClosed
+	}
+	return s.keystore.ChangePassword(s.name, oldPassphrase, newPassphrase, crypto.EDGSecp256_K1)
+}
+
+// Close zeroises the key. Signing afterwards fails with ErrChequeSignerClosed.
+func (s *KeystoreChequeSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key != nil {
+		zeroKey(s.key)
+		s.key = nil
+		s.signer = nil
+	}
+	return nil
+}
+
+// zeroKey overwrites the private scalar of the key in place.
+func zeroKey(key *ecdsa.PrivateKey) {
+	words := key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	key.D.SetInt64(0)
+}
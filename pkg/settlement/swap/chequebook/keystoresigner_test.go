@@ -0,0 +1,95 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestKeystoreChequeSigner(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(10)
+	ks := filekeystore.New(t.TempDir())
+	key, err := ks.SetKey("cheque", "old", crypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := crypto.NewDefaultSigner(key).EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chequebook.NewKeystoreChequeSigner(ks, "missing", "old", issuer, chainID); !errors.Is(err, keystore.ErrKeyNotFound) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrKeyNotFound)
+	}
+	if _, err := chequebook.NewKeystoreChequeSigner(ks, "cheque", "wrong", issuer, chainID); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+	if _, err := chequebook.NewKeystoreChequeSigner(ks, "cheque", "old", common.HexToAddress("0xab"), chainID); !errors.Is(err, chequebook.ErrChequeSignerNotIssuer) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeSignerNotIssuer)
+	}
+
+	signer, err := chequebook.NewKeystoreChequeSigner(ks, "cheque", "old", issuer, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheque := &chequebook.Cheque{
+		Chequebook:       common.HexToAddress("0xfa"),
+		Beneficiary:      common.HexToAddress("0xbe"),
+		CumulativePayout: big.NewInt(500),
+	}
+	signature, err := signer.Sign(cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := chequebook.RecoverCheque(&chequebook.SignedCheque{Cheque: *cheque, Signature: signature}, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != issuer {
+		t.Fatalf("signed by %x, want %x", recovered, issuer)
+	}
+
+	if err := signer.RotatePassphrase("wrong", "new"); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+	if err := signer.RotatePassphrase("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ks.Key("cheque", "old", crypto.EDGSecp256_K1); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+	// the loaded key keeps signing after the rotation
+	if _, err := signer.Sign(cheque); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := signer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(cheque); !errors.Is(err, chequebook.ErrChequeSignerClosed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeSignerClosed)
+	}
+	if err := signer.RotatePassphrase("new", "newer"); !errors.Is(err, chequebook.ErrChequeSignerClosed) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeSignerClosed)
+	}
+
+	// the keystore still holds the key under the new passphrase
+	signer, err = chequebook.NewKeystoreChequeSigner(ks, "cheque", "new", issuer, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = signer.Close()
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// lastChequesWorkers is the number of workers decoding stored cheques concurrently.
+const lastChequesWorkers = 8
+
+type storedCheque struct {
+	address common.Address
+	value   []byte
+}
+
+// loadCheques streams the cheques stored under the prefix and decodes them
+// with a bounded pool of workers. The address of every cheque is parsed from
+// its key with keyAddress.
+func loadCheques(ctx context.Context, store contextStore, prefix string, keyAddress func(key []byte) (common.Address, error)) (map[common.Address]*SignedCheque, error) {
+	result := make(map[common.Address]*SignedCheque)
+	err := decodeCheques(ctx, store, prefix, keyAddress, func(address common.Address, cheque *SignedCheque) {
+		result[address] = cheque
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeCheques streams the cheques stored under the prefix, decodes them with
+// a bounded pool of workers and passes them to add together with the address
+// parsed from their key with keyAddress. The calls of add are serialized.
+func decodeCheques(ctx context.Context, store contextStore, prefix string, keyAddress func(key []byte) (common.Address, error), add func(address common.Address, cheque *SignedCheque)) error {
+	var mu sync.Mutex
+
+	g, gctx := errgroup.WithContext(ctx)
+	storedC := make(chan storedCheque, lastChequesWorkers)
+
+	for i := 0; i < lastChequesWorkers; i++ {
+		g.Go(func() error {
+			for stored := range storedC {
+				var cheque *SignedCheque
+				if err := json.Unmarshal(stored.value, &cheque); err != nil {
+					return fmt.Errorf("decode cheque of %x: %w", stored.address, err)
+				}
+				mu.Lock()
+				add(stored.address, cheque)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		defer close(storedC)
+		return store.IterateContext(gctx, prefix, func(key, value []byte) (bool, error) {
+			address, err := keyAddress(key)
+			if err != nil {
+				return true, fmt.Errorf("parse address from key: %s: %w", string(key), err)
+			}
+			select {
+			// the iterator may reuse the value slice
+			case storedC <- storedCheque{address: address, value: append([]byte(nil), value...)}:
+				return false, nil
+			case <-gctx.Done():
+				return true, gctx.Err()
+			}
+		})
+	})
+
+	return g.Wait()
+}
+
+// countCheques returns the number of cheques stored under the prefix without
+// decoding them.
+func countCheques(ctx context.Context, store contextStore, prefix string) (int, error) {
+	count := 0
+	err := store.IterateContext(ctx, prefix, func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ChequesSnapshot is a consistent view of the issued cheques.
+type ChequesSnapshot struct {
+	Cheques     map[common.Address]*SignedCheque // last cheque of every beneficiary
+	TotalIssued *big.Int                         // total issued counter matching the cheques
+}
+
+// LastChequesSnapshot returns the last cheques for all beneficiaries together
+// with the total issued counter. Cheques issued concurrently are either
+// fully contained in the snapshot or not at all, so listings and exports built
+// from it are internally consistent.
+func (s *service) LastChequesSnapshot(ctx context.Context) (*ChequesSnapshot, error) {
+	s.issuedMu.RLock()
+	defer s.issuedMu.RUnlock()
+
+	cheques, err := s.lastCheques(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// a corrupted counter is repaired from the cheques, which no writer
+	// changes while the snapshot is taken
+	totalIssued, err := s.totalIssued()
+	if err != nil {
+		return nil, err
+	}
+	return &ChequesSnapshot{Cheques: cheques, TotalIssued: totalIssued}, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestLastChequesSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	address := common.HexToAddress("0xabcd")
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(_ context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if bytes.HasPrefix(request.Data, chequebookABI.Methods["balance"].ID) {
+					return big.NewInt(1_000_000).FillBytes(make([]byte, 32)), nil
+				}
+				return big.NewInt(0).FillBytes(make([]byte, 32)), nil
+			}),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				return make([]byte, 65), nil
+			},
+		},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const issuers, cheques = 4, 25
+	sendCheque := func(cheque *chequebook.SignedCheque) error { return nil }
+
+	var wg sync.WaitGroup
+	for i := 0; i < issuers; i++ {
+		beneficiary := common.BigToAddress(big.NewInt(int64(i + 1)))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < cheques; j++ {
+				if _, err := chequebookService.Issue(ctx, beneficiary, chequebook.TokensFromUint64(10), sendCheque); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+
+		snapshot, err := chequebookService.LastChequesSnapshot(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := big.NewInt(0)
+		for _, cheque := range snapshot.Cheques {
+			sum.Add(sum, cheque.CumulativePayout)
+		}
+		if sum.Cmp(snapshot.TotalIssued) != 0 {
+			t.Fatalf("snapshot inconsistent: cheques sum to %d, total issued %d", sum, snapshot.TotalIssued)
+		}
+	}
+
+	snapshot, err := chequebookService.LastChequesSnapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(issuers * cheques * 10); snapshot.TotalIssued.Cmp(want) != 0 {
+		t.Fatalf("got total issued %d, want %d", snapshot.TotalIssued, want)
+	}
+	if len(snapshot.Cheques) != issuers {
+		t.Fatalf("got cheques of %d beneficiaries, want %d", len(snapshot.Cheques), issuers)
+	}
+}
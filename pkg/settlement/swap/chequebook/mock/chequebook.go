@@ -19,13 +19,26 @@ type Service struct {
 	chequebookAvailableBalanceFunc func(context.Context) (*big.Int, error)
 	chequebookAddressFunc          func() common.Address
 	chequebookIssueFunc            func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)
+	previewIssueFunc               func(ctx context.Context, beneficiary common.Address, amount *big.Int) (*chequebook.IssuePreview, error)
 	chequebookWithdrawFunc         func(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
 	chequebookDepositFunc          func(ctx context.Context, amount *big.Int) (hash common.Hash, err error)
+	splitDepositFunc               func(ctx context.Context, amount, maxTransfer *big.Int) (*chequebook.SplitDepositResult, error)
+	waitForDepositFunc             func(ctx context.Context, txHash common.Hash) error
+	waitForDepositProgressFunc     func(ctx context.Context, txHash common.Hash, progressFn chequebook.DepositProgressFunc) error
 	lastChequeFunc                 func(common.Address) (*chequebook.SignedCheque, error)
-	lastChequesFunc                func() (map[common.Address]*chequebook.SignedCheque, error)
+	lastChequesFunc                func(context.Context) (map[common.Address]*chequebook.SignedCheque, error)
+	lastChequesCountFunc           func(context.Context) (int, error)
+	lastChequesSnapshotFunc        func(context.Context) (*chequebook.ChequesSnapshot, error)
+	approveFunc                    func(ctx context.Context, spender common.Address, amount *big.Int) (hash common.Hash, err error)
+	allowanceFunc                  func(ctx context.Context, spender common.Address) (*big.Int, error)
+	tokenFunc                      func(ctx context.Context) (*chequebook.Token, error)
+	depositHistoryFunc             func(ctx context.Context) ([]chequebook.Deposit, error)
+	importLastChequeFunc           func(ctx context.Context, beneficiary common.Address, cumulativePayout *big.Int) (*chequebook.SignedCheque, error)
+	chequeHistoryFunc              func(ctx context.Context, beneficiary common.Address, offset, limit uint64) ([]chequebook.IssuedCheque, uint64, error)
 }
 
-// WithChequebook*Functions set the mock chequebook functions
+// WithChequebook*Functions set the mock chequebook functions. Amounts of
+// tokens are passed to them in base units.
 func WithChequebookBalanceFunc(f func(ctx context.Context) (*big.Int, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.chequebookBalanceFunc = f
@@ -50,12 +63,48 @@ func WithChequebookDepositFunc(f func(ctx context.Context, amount *big.Int) (has
 	})
 }
 
+func WithSplitDepositFunc(f func(ctx context.Context, amount, maxTransfer *big.Int) (*chequebook.SplitDepositResult, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.splitDepositFunc = f
+	})
+}
+
+func WithWaitForDepositFunc(f func(ctx context.Context, txHash common.Hash) error) Option {
+	return optionFunc(func(s *Service) {
+		s.waitForDepositFunc = f
+	})
+}
+
+func WithWaitForDepositWithProgressFunc(f func(ctx context.Context, txHash common.Hash, progressFn chequebook.DepositProgressFunc) error) Option {
+	return optionFunc(func(s *Service) {
+		s.waitForDepositProgressFunc = f
+	})
+}
+
+func WithImportLastChequeFunc(f func(ctx context.Context, beneficiary common.Address, cumulativePayout *big.Int) (*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.importLastChequeFunc = f
+	})
+}
+
+func WithChequeHistoryFunc(f func(ctx context.Context, beneficiary common.Address, offset, limit uint64) ([]chequebook.IssuedCheque, uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.chequeHistoryFunc = f
+	})
+}
+
 func WithChequebookIssueFunc(f func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.chequebookIssueFunc = f
 	})
 }
 
+func WithPreviewIssueFunc(f func(ctx context.Context, beneficiary common.Address, amount *big.Int) (*chequebook.IssuePreview, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.previewIssueFunc = f
+	})
+}
+
 func WithChequebookWithdrawFunc(f func(ctx context.Context, amount *big.Int) (hash common.Hash, err error)) Option {
 	return optionFunc(func(s *Service) {
 		s.chequebookWithdrawFunc = f
@@ -68,12 +117,48 @@ func WithLastChequeFunc(f func(beneficiary common.Address) (*chequebook.SignedCh
 	})
 }
 
-func WithLastChequesFunc(f func() (map[common.Address]*chequebook.SignedCheque, error)) Option {
+func WithLastChequesFunc(f func(context.Context) (map[common.Address]*chequebook.SignedCheque, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.lastChequesFunc = f
 	})
 }
 
+func WithLastChequesCountFunc(f func(context.Context) (int, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastChequesCountFunc = f
+	})
+}
+
+func WithLastChequesSnapshotFunc(f func(context.Context) (*chequebook.ChequesSnapshot, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastChequesSnapshotFunc = f
+	})
+}
+
+func WithApproveFunc(f func(ctx context.Context, spender common.Address, amount *big.Int) (hash common.Hash, err error)) Option {
+	return optionFunc(func(s *Service) {
+		s.approveFunc = f
+	})
+}
+
+func WithAllowanceFunc(f func(ctx context.Context, spender common.Address) (*big.Int, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.allowanceFunc = f
+	})
+}
+
+func WithTokenFunc(f func(ctx context.Context) (*chequebook.Token, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.tokenFunc = f
+	})
+}
+
+func WithDepositHistoryFunc(f func(ctx context.Context) ([]chequebook.Deposit, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.depositHistoryFunc = f
+	})
+}
+
 // NewChequebook creates the mock chequebook implementation
 func NewChequebook(opts ...Option) chequebook.Service {
 	mock := new(Service)
@@ -99,15 +184,34 @@ func (s *Service) AvailableBalance(ctx context.Context) (bal *big.Int, err error
 }
 
 // Deposit mocks the chequebook .Deposit function
-func (s *Service) Deposit(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
+func (s *Service) Deposit(ctx context.Context, amount chequebook.Tokens) (hash common.Hash, err error) {
 	if s.chequebookDepositFunc != nil {
-		return s.chequebookDepositFunc(ctx, amount)
+		return s.chequebookDepositFunc(ctx, amount.BigInt())
 	}
 	return common.Hash{}, errors.New("Error")
 }
 
+// SplitDeposit mocks the chequebook .SplitDeposit function
+func (s *Service) SplitDeposit(ctx context.Context, amount, maxTransfer chequebook.Tokens) (*chequebook.SplitDepositResult, error) {
+	if s.splitDepositFunc != nil {
+		return s.splitDepositFunc(ctx, amount.BigInt(), maxTransfer.BigInt())
+	}
+	return nil, errors.New("Error")
+}
+
 // WaitForDeposit mocks the chequebook .WaitForDeposit function
 func (s *Service) WaitForDeposit(ctx context.Context, txHash common.Hash) error {
+	if s.waitForDepositFunc != nil {
+		return s.waitForDepositFunc(ctx, txHash)
+	}
+	return errors.New("Error")
+}
+
+// WaitForDepositWithProgress mocks the chequebook .WaitForDepositWithProgress function
+func (s *Service) WaitForDepositWithProgress(ctx context.Context, txHash common.Hash, progressFn chequebook.DepositProgressFunc) error {
+	if s.waitForDepositProgressFunc != nil {
+		return s.waitForDepositProgressFunc(ctx, txHash, progressFn)
+	}
 	return errors.New("Error")
 }
 
@@ -119,13 +223,20 @@ func (s *Service) Address() common.Address {
 	return common.Address{}
 }
 
-func (s *Service) Issue(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+func (s *Service) Issue(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
 	if s.chequebookIssueFunc != nil {
-		return s.chequebookIssueFunc(ctx, beneficiary, amount, sendChequeFunc)
+		return s.chequebookIssueFunc(ctx, beneficiary, amount.BigInt(), sendChequeFunc)
 	}
 	return big.NewInt(0), nil
 }
 
+func (s *Service) PreviewIssue(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens) (*chequebook.IssuePreview, error) {
+	if s.previewIssueFunc != nil {
+		return s.previewIssueFunc(ctx, beneficiary, amount.BigInt())
+	}
+	return nil, errors.New("Error")
+}
+
 func (s *Service) LastCheque(beneficiary common.Address) (*chequebook.SignedCheque, error) {
 	if s.lastChequeFunc != nil {
 		return s.lastChequeFunc(beneficiary)
@@ -133,15 +244,71 @@ func (s *Service) LastCheque(beneficiary common.Address) (*chequebook.SignedCheq
 	return nil, errors.New("Error")
 }
 
-func (s *Service) LastCheques() (map[common.Address]*chequebook.SignedCheque, error) {
+func (s *Service) LastCheques(ctx context.Context) (map[common.Address]*chequebook.SignedCheque, error) {
 	if s.lastChequesFunc != nil {
-		return s.lastChequesFunc()
+		return s.lastChequesFunc(ctx)
 	}
 	return nil, errors.New("Error")
 }
 
-func (s *Service) Withdraw(ctx context.Context, amount *big.Int) (hash common.Hash, err error) {
-	return s.chequebookWithdrawFunc(ctx, amount)
+func (s *Service) LastChequesCount(ctx context.Context) (int, error) {
+	if s.lastChequesCountFunc != nil {
+		return s.lastChequesCountFunc(ctx)
+	}
+	return 0, errors.New("Error")
+}
+
+func (s *Service) LastChequesSnapshot(ctx context.Context) (*chequebook.ChequesSnapshot, error) {
+	if s.lastChequesSnapshotFunc != nil {
+		return s.lastChequesSnapshotFunc(ctx)
+	}
+	return nil, errors.New("Error")
+}
+
+func (s *Service) Withdraw(ctx context.Context, amount chequebook.Tokens) (hash common.Hash, err error) {
+	return s.chequebookWithdrawFunc(ctx, amount.BigInt())
+}
+
+func (s *Service) Approve(ctx context.Context, spender common.Address, amount chequebook.Tokens) (hash common.Hash, err error) {
+	if s.approveFunc != nil {
+		return s.approveFunc(ctx, spender, amount.BigInt())
+	}
+	return common.Hash{}, errors.New("Error")
+}
+
+func (s *Service) Allowance(ctx context.Context, spender common.Address) (*big.Int, error) {
+	if s.allowanceFunc != nil {
+		return s.allowanceFunc(ctx, spender)
+	}
+	return big.NewInt(0), errors.New("Error")
+}
+
+func (s *Service) Token(ctx context.Context) (*chequebook.Token, error) {
+	if s.tokenFunc != nil {
+		return s.tokenFunc(ctx)
+	}
+	return nil, errors.New("Error")
+}
+
+func (s *Service) DepositHistory(ctx context.Context) ([]chequebook.Deposit, error) {
+	if s.depositHistoryFunc != nil {
+		return s.depositHistoryFunc(ctx)
+	}
+	return nil, errors.New("Error")
+}
+
+func (s *Service) ImportLastCheque(ctx context.Context, beneficiary common.Address, cumulativePayout chequebook.Tokens) (*chequebook.SignedCheque, error) {
+	if s.importLastChequeFunc != nil {
+		return s.importLastChequeFunc(ctx, beneficiary, cumulativePayout.BigInt())
+	}
+	return nil, errors.New("Error")
+}
+
+func (s *Service) ChequeHistory(ctx context.Context, beneficiary common.Address, offset, limit uint64) ([]chequebook.IssuedCheque, uint64, error) {
+	if s.chequeHistoryFunc != nil {
+		return s.chequeHistoryFunc(ctx, beneficiary, offset, limit)
+	}
+	return nil, 0, errors.New("Error")
 }
 
 // Option is the option passed to the mock Chequebook service
@@ -0,0 +1,101 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mpcsigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrCoordinator is the error returned if the coordinator responded with an error.
+var ErrCoordinator = errors.New("coordinator error")
+
+type httpCoordinator struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewHTTPCoordinator returns a Coordinator for a signing service reachable at
+// endpoint. The service has to provide the following endpoints:
+//
+//	GET  /participants/{id}/health  responds with 200 if the participant is ready
+//	POST /sign                      takes {"digest", "participants"} and responds with {"signature"}
+func NewHTTPCoordinator(endpoint string, client *http.Client) Coordinator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpCoordinator{
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+}
+
+type signRequest struct {
+	Digest       hexutil.Bytes `json:"digest"`
+	Participants []string      `json:"participants"`
+}
+
+type signResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Health implements the Coordinator interface.
+func (c *httpCoordinator) Health(ctx context.Context, participant string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/participants/"+url.PathEscape(participant)+"/health", nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("participant %s: status %d: %w", participant, res.StatusCode, ErrCoordinator)
+	}
+	return nil
+}
+
+// Sign implements the Coordinator interface.
+func (c *httpCoordinator) Sign(ctx context.Context, digest []byte, participants []string) ([]byte, error) {
+	body, err := json.Marshal(signRequest{Digest: digest, Participants: participants})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/sign", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("sign: status %d: %w", res.StatusCode, ErrCoordinator)
+	}
+
+	var response signResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response.Signature, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mpcsigner provides a cheque signer which obtains the signatures of
+// the chequebook issuer from a threshold signature (MPC) service, so that no
+// single machine has to hold the issuer key.
+package mpcsigner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "mpcsigner"
+
+const (
+	// DefaultTimeout is the default time a signing round may take.
+	DefaultTimeout = 30 * time.Second
+	// DefaultHealthCheckInterval is the default interval between participant health checks.
+	DefaultHealthCheckInterval = time.Minute
+)
+
+var (
+	// ErrNotEnoughParticipants is the error returned if fewer participants than the threshold are healthy.
+	ErrNotEnoughParticipants = errors.New("not enough healthy participants")
+	// ErrInvalidSignature is the error returned if the signature does not belong to the issuer.
+	ErrInvalidSignature = errors.New("signature not from issuer")
+	// ErrInvalidThreshold is the error returned if the threshold cannot be reached with the configured participants.
+	ErrInvalidThreshold = errors.New("invalid threshold")
+)
+
+// Coordinator runs the threshold signing protocol among the participants.
+type Coordinator interface {
+	// Health checks whether the participant is reachable and ready to sign.
+	Health(ctx context.Context, participant string) error
+	// Sign runs a signing round for the digest with the given participants
+	// and returns the resulting signature in the ethereum (r,s,v) format.
+	Sign(ctx context.Context, digest []byte, participants []string) ([]byte, error)
+}
+
+// FallbackPolicy decides what happens if no threshold signature can be obtained.
+type FallbackPolicy int
+
+const (
+	// FallbackReject fails the cheque. The payment is retried with the next settlement.
+	FallbackReject FallbackPolicy = iota
+	// FallbackLocal signs the cheque with a local signer, e.g. while migrating to threshold signing.
+	FallbackLocal
+)
+
+// ParseFallbackPolicy parses the name of a fallback policy.
+func ParseFallbackPolicy(s string) (FallbackPolicy, error) {
+	switch s {
+	case "", "reject":
+		return FallbackReject, nil
+	case "local":
+		return FallbackLocal, nil
+	}
+	return 0, fmt.Errorf("unknown fallback policy %q", s)
+}
+
+// Options configures the signer.
+type Options struct {
+	Participants        []string      // identifiers of all participants known to the coordinator
+	Threshold           int           // number of participants needed for a signature
+	Timeout             time.Duration // maximum duration of a signing round
+	HealthCheckInterval time.Duration // interval between participant health checks
+	Fallback            FallbackPolicy
+	Local               chequebook.ChequeSigner // signer used by FallbackLocal
+}
+
+// Signer is a chequebook.ChequeSigner using a threshold signature service.
+type Signer struct {
+	logger      log.Logger
+	coordinator Coordinator
+	issuer      common.Address
+	chainID     int64
+	options     Options
+
+	mu      sync.Mutex
+	healthy []string // participants which passed the last health check
+	checked bool     // whether a health check has completed
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ chequebook.IssuerChequeSigner = (*Signer)(nil)
+
+// New creates a new threshold cheque signer. Signatures are only accepted if
+// they belong to the issuer of the chequebook. The participants are checked
+// periodically in the background until the signer is closed.
+func New(logger log.Logger, coordinator Coordinator, issuer common.Address, chainID int64, o Options) (*Signer, error) {
+	if o.Threshold <= 0 || o.Threshold > len(o.Participants) {
+		return nil, ErrInvalidThreshold
+	}
+	if o.Fallback == FallbackLocal && o.Local == nil {
+		return nil, errors.New("local fallback requires a local signer")
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.HealthCheckInterval <= 0 {
+		o.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+
+	s := &Signer{
+		logger:      logger.WithName(loggerName).Register(),
+		coordinator: coordinator,
+		issuer:      issuer,
+		chainID:     chainID,
+		options:     o,
+		quit:        make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.healthLoop()
+
+	return s, nil
+}
+
+// Issuer returns the address of the chequebook issuer signatures are accepted from.
+func (s *Signer) Issuer() common.Address {
+	return s.issuer
+}
+
+func (s *Signer) healthLoop() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.quit
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.options.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkHealth(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// checkHealth checks all participants concurrently and records the healthy ones.
+func (s *Signer) checkHealth(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, s.options.Timeout)
+	defer cancel()
+
+	results := make([]error, len(s.options.Participants))
+	var wg sync.WaitGroup
+	for i, participant := range s.options.Participants {
+		wg.Add(1)
+		go func(i int, participant string) {
+			defer wg.Done()
+			results[i] = s.coordinator.Health(ctx, participant)
+		}(i, participant)
+	}
+	wg.Wait()
+
+	healthy := make([]string, 0, len(s.options.Participants))
+	for i, participant := range s.options.Participants {
+		if results[i] != nil {
+			s.logger.Warning("threshold signing participant unhealthy", "participant", participant, "error", results[i])
+			continue
+		}
+		healthy = append(healthy, participant)
+	}
+
+	s.mu.Lock()
+	s.healthy = healthy
+	s.checked = true
+	s.mu.Unlock()
+
+	return healthy
+}
+
+// Healthy returns the participants which passed the last health check.
+func (s *Signer) Healthy() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.healthy...)
+}
+
+// healthyParticipants returns the healthy participants, checking them first
+// if no health check has completed yet.
+func (s *Signer) healthyParticipants(ctx context.Context) []string {
+	s.mu.Lock()
+	checked, healthy := s.checked, s.healthy
+	s.mu.Unlock()
+	if !checked {
+		return s.checkHealth(ctx)
+	}
+	return healthy
+}
+
+// Sign implements the chequebook.ChequeSigner interface.
+func (s *Signer) Sign(cheque *chequebook.Cheque) ([]byte, error) {
+	signature, err := s.sign(cheque)
+	if err == nil {
+		return signature, nil
+	}
+
+	if s.options.Fallback == FallbackLocal {
+		s.logger.Warning("threshold signing failed, falling back to local signer", "error", err)
+		return s.options.Local.Sign(cheque)
+	}
+	return nil, err
+}
+
+func (s *Signer) sign(cheque *chequebook.Cheque) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+	defer cancel()
+
+	participants := s.healthyParticipants(ctx)
+	if len(participants) < s.options.Threshold {
+		return nil, fmt.Errorf("%d of %d needed: %w", len(participants), s.options.Threshold, ErrNotEnoughParticipants)
+	}
+
+	digest, err := chequebook.ChequeHash(cheque, s.chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.coordinator.Sign(ctx, digest, participants)
+	if err != nil {
+		return nil, fmt.Errorf("threshold signing: %w", err)
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("signature length %d: %w", len(signature), ErrInvalidSignature)
+	}
+
+	// threshold signature services commonly return the recovery id as v
+	signature = append([]byte(nil), signature...)
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+
+	issuer, err := chequebook.RecoverCheque(&chequebook.SignedCheque{Cheque: *cheque, Signature: signature}, s.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("recover signer: %w", err)
+	}
+	if issuer != s.issuer {
+		return nil, fmt.Errorf("signed by %x: %w", issuer, ErrInvalidSignature)
+	}
+
+	return signature, nil
+}
+
+// Close stops the health checks.
+func (s *Signer) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mpcsigner_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mpcsigner"
+)
+
+const chainID = 1
+
+// coordinatorMock signs with a single key on behalf of all participants.
+type coordinatorMock struct {
+	mu        sync.Mutex
+	key       *ecdsa.PrivateKey
+	unhealthy map[string]bool
+	signers   []string
+}
+
+func (c *coordinatorMock) Health(_ context.Context, participant string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unhealthy[participant] {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+func (c *coordinatorMock) Sign(_ context.Context, digest []byte, participants []string) ([]byte, error) {
+	c.mu.Lock()
+	c.signers = participants
+	c.mu.Unlock()
+	signature, err := ethSign(c.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	// return the plain recovery id like most threshold signature services
+	signature[64] -= 27
+	return signature, nil
+}
+
+// ethSign signs the digest in the ethereum (r,s,v) format with v being 27 or 28.
+func ethSign(key *ecdsa.PrivateKey, digest []byte) ([]byte, error) {
+	signature, err := gethcrypto.Sign(digest, key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func newCheque() *chequebook.Cheque {
+	return &chequebook.Cheque{
+		Chequebook:       common.HexToAddress("0xfa02D396842E6e1D319E8E3D4D870338F791AA25"),
+		Beneficiary:      common.HexToAddress("0x98E6C644aFeB94BBfB9FF60EB26fc9D83BBEcA79"),
+		CumulativePayout: big.NewInt(500),
+	}
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, address
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	key, issuer := newKey(t)
+	coordinator := &coordinatorMock{key: key, unhealthy: map[string]bool{"c": true}}
+
+	signer, err := mpcsigner.New(log.Noop, coordinator, issuer, chainID, mpcsigner.Options{
+		Participants: []string{"a", "b", "c"},
+		Threshold:    2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	cheque := newCheque()
+	signature, err := signer.Sign(cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := chequebook.NewChequeSigner(crypto.NewDefaultSigner(key), chainID).Sign(cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signature, expected) {
+		t.Fatalf("got signature %x, want %x", signature, expected)
+	}
+
+	// only healthy participants take part in the signing round
+	coordinator.mu.Lock()
+	signers := coordinator.signers
+	coordinator.mu.Unlock()
+	if strings.Join(signers, ",") != "a,b" {
+		t.Fatalf("got signers %v, want %v", signers, []string{"a", "b"})
+	}
+}
+
+func TestSignNotEnoughParticipants(t *testing.T) {
+	t.Parallel()
+
+	key, issuer := newKey(t)
+	coordinator := &coordinatorMock{key: key, unhealthy: map[string]bool{"b": true, "c": true}}
+
+	signer, err := mpcsigner.New(log.Noop, coordinator, issuer, chainID, mpcsigner.Options{
+		Participants: []string{"a", "b", "c"},
+		Threshold:    2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	if _, err := signer.Sign(newCheque()); !errors.Is(err, mpcsigner.ErrNotEnoughParticipants) {
+		t.Fatalf("got error %v, want %v", err, mpcsigner.ErrNotEnoughParticipants)
+	}
+}
+
+func TestSignWrongIssuer(t *testing.T) {
+	t.Parallel()
+
+	key, _ := newKey(t)
+	_, issuer := newKey(t)
+	localKey, _ := newKey(t)
+	local := chequebook.NewChequeSigner(crypto.NewDefaultSigner(localKey), chainID)
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+
+		signer, err := mpcsigner.New(log.Noop, &coordinatorMock{key: key}, issuer, chainID, mpcsigner.Options{
+			Participants: []string{"a"},
+			Threshold:    1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer signer.Close()
+
+		if _, err := signer.Sign(newCheque()); !errors.Is(err, mpcsigner.ErrInvalidSignature) {
+			t.Fatalf("got error %v, want %v", err, mpcsigner.ErrInvalidSignature)
+		}
+	})
+
+	t.Run("local fallback", func(t *testing.T) {
+		t.Parallel()
+
+		signer, err := mpcsigner.New(log.Noop, &coordinatorMock{key: key}, issuer, chainID, mpcsigner.Options{
+			Participants: []string{"a"},
+			Threshold:    1,
+			Fallback:     mpcsigner.FallbackLocal,
+			Local:        local,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer signer.Close()
+
+		signature, err := signer.Sign(newCheque())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := local.Sign(newCheque())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(signature, expected) {
+			t.Fatal("cheque not signed by local signer")
+		}
+	})
+}
+
+func TestInvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	_, err := mpcsigner.New(log.Noop, &coordinatorMock{}, common.Address{}, chainID, mpcsigner.Options{
+		Participants: []string{"a"},
+		Threshold:    2,
+	})
+	if !errors.Is(err, mpcsigner.ErrInvalidThreshold) {
+		t.Fatalf("got error %v, want %v", err, mpcsigner.ErrInvalidThreshold)
+	}
+}
+
+func TestHTTPCoordinator(t *testing.T) {
+	t.Parallel()
+
+	key, issuer := newKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/participants/a/health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/participants/b/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Digest       hexutil.Bytes `json:"digest"`
+			Participants []string      `json:"participants"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		signature, err := ethSign(key, request.Digest)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(struct {
+			Signature hexutil.Bytes `json:"signature"`
+		}{Signature: signature})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	signer, err := mpcsigner.New(log.Noop, mpcsigner.NewHTTPCoordinator(server.URL, server.Client()), issuer, chainID, mpcsigner.Options{
+		Participants:        []string{"a", "b"},
+		Threshold:           1,
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	if _, err := signer.Sign(newCheque()); err != nil {
+		t.Fatal(err)
+	}
+	if healthy := signer.Healthy(); len(healthy) != 1 || healthy[0] != "a" {
+		t.Fatalf("got healthy participants %v, want %v", healthy, []string{"a"})
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/statestore/namespaced"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// ownKeyPrefixes are the prefixes of the keys holding the state of the own
+// chequebook, like issued cheques and deposits.
+var ownKeyPrefixes = []string{
+	lastIssuedChequeKeyPrefix,
+	totalIssuedKey,
+	chequeHistoryKeyPrefix,
+	prepaidCreditKeyPrefix,
+	depositKeyPrefix,
+	depositLastBlockKey,
+	issuedChecksumsMigratedKey,
+}
+
+// namespacedStore returns a view of store in which the state of the own
+// chequebook is kept in a namespace of its address. The state of a
+// previously used chequebook is therefore never mistaken for the current one.
+func namespacedStore(store storage.StateStorer, chequebook common.Address) *namespaced.Store {
+	return namespaced.New(store, fmt.Sprintf("%x", chequebook), ownKeyPrefixes...)
+}
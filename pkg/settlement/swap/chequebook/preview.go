@@ -0,0 +1,100 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Names of the policies evaluated before a cheque is issued.
+const (
+	PolicyFunds                  = "funds"
+	PolicyRateLimit              = "rate_limit"
+	PolicyTotalIssuedConsistency = "total_issued_consistency"
+	PolicyBalanceDecline         = "balance_decline"
+)
+
+// PolicyCheck is the evaluation of an issuance policy for a cheque.
+type PolicyCheck struct {
+	Policy string
+	Err    error // why the policy rejects the cheque, nil if it allows it
+}
+
+// Allowed reports whether the policy allows issuing the cheque.
+func (c PolicyCheck) Allowed() bool {
+	return c.Err == nil
+}
+
+// IssuePreview is what issuing a cheque to a beneficiary would result in.
+type IssuePreview struct {
+	Beneficiary      common.Address
+	Amount           *big.Int // amount of the cheque, after rounding to the granularity or using prepaid credit
+	CumulativePayout *big.Int // cumulative payout of the cheque
+	AvailableBalance *big.Int // available balance after issuing the cheque
+	Policies         []PolicyCheck
+}
+
+// Err returns the error of the first policy rejecting the cheque, or nil if
+// the cheque would be issued.
+func (p *IssuePreview) Err() error {
+	for _, c := range p.Policies {
+		if !c.Allowed() {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// PreviewIssue evaluates issuing a cheque of amount to the beneficiary
+// without signing or sending it and without reserving the amount.
+func (s *service) PreviewIssue(ctx context.Context, beneficiary common.Address, tokens Tokens) (*IssuePreview, error) {
+	amount := tokens.BigInt()
+
+	s.lock.Lock()
+	breakdown, err := s.balanceBreakdown(ctx)
+	reserved := new(big.Int).Set(s.totalIssuedReserved)
+	s.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	cumulativePayout := new(big.Int).Set(amount)
+	lastCheque, err := s.lastCheque(ctx, beneficiary)
+	if err != nil {
+		if !errors.Is(err, ErrNoCheque) {
+			return nil, err
+		}
+	} else {
+		cumulativePayout.Add(cumulativePayout, lastCheque.CumulativePayout)
+	}
+
+	preview := &IssuePreview{
+		Beneficiary:      beneficiary,
+		Amount:           new(big.Int).Set(amount),
+		CumulativePayout: cumulativePayout,
+		AvailableBalance: new(big.Int).Set(breakdown.available),
+	}
+
+	funds := PolicyCheck{Policy: PolicyFunds}
+	if amount.Cmp(new(big.Int).Sub(breakdown.available, reserved)) > 0 {
+		funds.Err = &OutOfFundsError{
+			Requested:    new(big.Int).Set(amount),
+			Available:    breakdown.available,
+			Reserved:     reserved,
+			Balance:      breakdown.balance,
+			TotalPaidOut: breakdown.totalPaidOut,
+			TotalIssued:  breakdown.totalIssued,
+		}
+	} else {
+		preview.AvailableBalance.Sub(preview.AvailableBalance, amount)
+	}
+	preview.Policies = append(preview.Policies, funds)
+
+	return preview, nil
+}
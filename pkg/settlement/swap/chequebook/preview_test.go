@@ -0,0 +1,160 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequebookPreviewIssue(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	beneficiary := common.HexToAddress("0xdddd")
+	balance := big.NewInt(100).FillBytes(make([]byte, 32))
+	paidOut := big.NewInt(0).FillBytes(make([]byte, 32))
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, address, balance, "balance"),
+				transactionmock.ABICall(&chequebookABI, address, paidOut, "totalPaidOut"),
+				transactionmock.ABICall(&chequebookABI, address, balance, "balance"),
+				transactionmock.ABICall(&chequebookABI, address, paidOut, "totalPaidOut"),
+				transactionmock.ABICall(&chequebookABI, address, balance, "balance"),
+				transactionmock.ABICall(&chequebookABI, address, paidOut, "totalPaidOut"),
+			),
+		),
+		address,
+		common.HexToAddress("0xfff"),
+		storemock.NewStateStore(),
+		&chequeSignerMock{
+			sign: func(cheque *chequebook.Cheque) ([]byte, error) {
+				return []byte{1}, nil
+			},
+		},
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := chequebookService.Issue(ctx, beneficiary, chequebook.TokensFromUint64(30), func(*chequebook.SignedCheque) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := chequebookService.PreviewIssue(ctx, beneficiary, chequebook.TokensFromUint64(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Err() != nil {
+		t.Fatalf("got error %v, want none", preview.Err())
+	}
+	if preview.CumulativePayout.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got cumulative payout %v, want %v", preview.CumulativePayout, 50)
+	}
+	if preview.AvailableBalance.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got available balance %v, want %v", preview.AvailableBalance, 50)
+	}
+
+	preview, err = chequebookService.PreviewIssue(ctx, beneficiary, chequebook.TokensFromUint64(80))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(preview.Err(), chequebook.ErrOutOfFunds) {
+		t.Fatalf("got error %v, want %v", preview.Err(), chequebook.ErrOutOfFunds)
+	}
+	if len(preview.Policies) != 1 || preview.Policies[0].Policy != chequebook.PolicyFunds {
+		t.Fatalf("got policies %v, want the funds policy", preview.Policies)
+	}
+	if preview.AvailableBalance.Cmp(big.NewInt(70)) != 0 {
+		t.Fatalf("got available balance %v, want %v", preview.AvailableBalance, 70)
+	}
+
+	// previewing neither issues nor stores a cheque
+	lastCheque, err := chequebookService.LastCheque(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastCheque.CumulativePayout.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("got last cheque payout %v, want %v", lastCheque.CumulativePayout, 30)
+	}
+}
+
+func TestPreviewIssuePolicies(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xbeef")
+	var previewed []*big.Int
+	service := chequebook.NewIssueRateLimiter(
+		chequebook.NewGranularService(
+			mock.NewChequebook(
+				mock.WithPreviewIssueFunc(func(ctx context.Context, b common.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+					previewed = append(previewed, amount)
+					return &chequebook.IssuePreview{
+						Beneficiary: b,
+						Amount:      amount,
+						Policies:    []chequebook.PolicyCheck{{Policy: chequebook.PolicyFunds}},
+					}, nil
+				}),
+				mock.WithChequebookIssueFunc(func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+					return big.NewInt(0), sendChequeFunc(&chequebook.SignedCheque{})
+				}),
+			),
+			storemock.NewStateStore(),
+			big.NewInt(100),
+		),
+		time.Hour,
+		1,
+	)
+
+	ctx := context.Background()
+	preview, err := service.PreviewIssue(ctx, beneficiary, chequebook.TokensFromUint64(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Err() != nil {
+		t.Fatalf("got error %v, want none", preview.Err())
+	}
+
+	// the cheque rounded up to the granularity leaves a prepaid credit of 70
+	if _, err := service.Issue(ctx, beneficiary, chequebook.TokensFromUint64(30), sendCheque); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err = service.PreviewIssue(ctx, beneficiary, chequebook.TokensFromUint64(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(preview.Err(), chequebook.ErrIssueRateLimited) {
+		t.Fatalf("got error %v, want %v", preview.Err(), chequebook.ErrIssueRateLimited)
+	}
+	if len(preview.Policies) != 2 || preview.Policies[1].Policy != chequebook.PolicyRateLimit || preview.Policies[1].Allowed() {
+		t.Fatalf("got policies %v, want funds allowed and rate limit rejected", preview.Policies)
+	}
+
+	want := []*big.Int{big.NewInt(100), big.NewInt(0)}
+	if len(previewed) != len(want) {
+		t.Fatalf("got previewed amounts %v, want %v", previewed, want)
+	}
+	for i := range want {
+		if previewed[i].Cmp(want[i]) != 0 {
+			t.Fatalf("got previewed amounts %v, want %v", previewed, want)
+		}
+	}
+}
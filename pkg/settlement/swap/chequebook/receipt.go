@@ -0,0 +1,114 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+)
+
+// ErrReceiptInvalid is the error returned if a receipt does not match the cheque it acknowledges
+// or was not signed by the beneficiary of the cheque.
+var ErrReceiptInvalid = errors.New("invalid receipt")
+
+// Receipt is issued by the beneficiary of a cheque after the cheque was stored.
+// It serves the issuer as proof that the cheque was delivered.
+type Receipt struct {
+	Cheque
+	Signature []byte // signature of the beneficiary
+}
+
+// receiptDomain computes chainId-dependant EIP712 domain for receipts
+func receiptDomain(chainID int64) eip712.TypedDataDomain {
+	return eip712.TypedDataDomain{
+		Name:    "ChequeReceipt",
+		Version: "1.0",
+		ChainId: math.NewHexOrDecimal256(chainID),
+	}
+}
+
+// ReceiptTypes are the needed type descriptions for receipt signing
+var ReceiptTypes = eip712.Types{
+	"EIP712Domain": eip712.EIP712DomainType,
+	"Receipt": []eip712.Type{
+		{
+			Name: "chequebook",
+			Type: "address",
+		},
+		{
+			Name: "beneficiary",
+			Type: "address",
+		},
+		{
+			Name: "cumulativePayout",
+			Type: "uint256",
+		},
+	},
+}
+
+// eip712DataForReceipt converts an acknowledged cheque into the correct TypedData structure.
+func eip712DataForReceipt(cheque *Cheque, chainID int64) *eip712.TypedData {
+	return &eip712.TypedData{
+		Domain: receiptDomain(chainID),
+		Types:  ReceiptTypes,
+		Message: eip712.TypedDataMessage{
+			"chequebook":       cheque.Chequebook.Hex(),
+			"beneficiary":      cheque.Beneficiary.Hex(),
+			"cumulativePayout": cheque.CumulativePayout.String(),
+		},
+		PrimaryType: "Receipt",
+	}
+}
+
+// SignReceipt creates a receipt for a received cheque signed by signer.
+func SignReceipt(signer crypto.Signer, cheque *Cheque, chainID int64) (*Receipt, error) {
+	signature, err := signer.SignTypedData(eip712DataForReceipt(cheque, chainID))
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		Cheque:    *cheque,
+		Signature: signature,
+	}, nil
+}
+
+// RecoverReceipt recovers the ethereum address of the receipt signer.
+func RecoverReceipt(receipt *Receipt, chainID int64) (common.Address, error) {
+	pubkey, err := crypto.RecoverEIP712(receipt.Signature, eip712DataForReceipt(&receipt.Cheque, chainID))
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	ethAddr, err := crypto.NewEthereumAddress(*pubkey)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var beneficiary common.Address
+	copy(beneficiary[:], ethAddr)
+	return beneficiary, nil
+}
+
+// VerifyReceipt checks that the receipt acknowledges the given cheque and was signed by its beneficiary.
+func VerifyReceipt(receipt *Receipt, cheque *Cheque, chainID int64) error {
+	if receipt.CumulativePayout == nil || !receipt.Cheque.Equal(cheque) {
+		return ErrReceiptInvalid
+	}
+
+	signer, err := RecoverReceipt(receipt, chainID)
+	if err != nil {
+		return err
+	}
+
+	if signer != cheque.Beneficiary {
+		return ErrReceiptInvalid
+	}
+
+	return nil
+}
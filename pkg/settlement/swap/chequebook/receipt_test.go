@@ -0,0 +1,79 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestReceipt(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(1)
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	beneficiary, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheque := &chequebook.Cheque{
+		Chequebook:       common.HexToAddress("0x8d3766440f0d7b949a5e32995d09619a7f86e632"),
+		Beneficiary:      beneficiary,
+		CumulativePayout: big.NewInt(10),
+	}
+
+	receipt, err := chequebook.SignReceipt(signer, cheque, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		recovered, err := chequebook.RecoverReceipt(receipt, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recovered != beneficiary {
+			t.Fatalf("recovered wrong signer. wanted %x, got %x", beneficiary, recovered)
+		}
+
+		if err := chequebook.VerifyReceipt(receipt, cheque, chainID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("different cheque", func(t *testing.T) {
+		t.Parallel()
+
+		other := *cheque
+		other.CumulativePayout = big.NewInt(11)
+
+		err := chequebook.VerifyReceipt(receipt, &other, chainID)
+		if !errors.Is(err, chequebook.ErrReceiptInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrReceiptInvalid)
+		}
+	})
+
+	t.Run("different chain", func(t *testing.T) {
+		t.Parallel()
+
+		err := chequebook.VerifyReceipt(receipt, cheque, chainID+1)
+		if !errors.Is(err, chequebook.ErrReceiptInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrReceiptInvalid)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package remoteverifier delegates the verification of received cheques to
+// a trusted node, e.g. so that light or bootstrapping nodes do not need to
+// query the chain for every new chequebook.
+package remoteverifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// Path is the path of the verification endpoint on the API of the trusted node.
+const Path = "/chequebook/verification"
+
+// ErrVerifier is the error returned if the trusted node could not verify the cheque.
+var ErrVerifier = errors.New("cheque verifier error")
+
+// reasons are the verification errors passed on to the delegating node.
+var reasons = map[string]error{
+	"invalid":        chequebook.ErrChequeInvalid,
+	"not_increasing": chequebook.ErrChequeNotIncreasing,
+	"untrusted":      chequebook.ErrNotDeployedByFactory,
+	"not_on_chain":   chequebook.ErrChequebookNotOnChain,
+}
+
+// Request is the verification request sent to the trusted node.
+type Request struct {
+	Cheque               *chequebook.SignedCheque `json:"cheque"`
+	LastCumulativePayout *bigint.BigInt           `json:"lastCumulativePayout,omitempty"`
+}
+
+// Response is the result of a verification. Reason is empty if the cheque is valid.
+type Response struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Reason returns the reason a cheque is rejected for with err, or false if
+// err is not a verification error but e.g. a failed query of the chain.
+func Reason(err error) (string, bool) {
+	for reason, e := range reasons {
+		if errors.Is(err, e) {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+type httpVerifier struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewHTTPVerifier returns a ChequeVerifier which verifies cheques with the
+// node whose API is reachable at endpoint.
+func NewHTTPVerifier(endpoint string, client *http.Client) chequebook.ChequeVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpVerifier{
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+}
+
+// VerifyCheque implements the ChequeVerifier interface.
+func (v *httpVerifier) VerifyCheque(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error {
+	request := Request{Cheque: cheque}
+	if lastCumulativePayout != nil {
+		request.LastCumulativePayout = bigint.Wrap(lastCumulativePayout)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint+Path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("verify cheque: status %d: %w", res.StatusCode, ErrVerifier)
+	}
+
+	var response Response
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return err
+	}
+	if response.Reason == "" {
+		return nil
+	}
+	if err, ok := reasons[response.Reason]; ok {
+		return err
+	}
+	return fmt.Errorf("unknown reason %q: %w", response.Reason, chequebook.ErrChequeInvalid)
+}
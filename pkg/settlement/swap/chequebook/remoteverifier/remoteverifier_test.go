@@ -0,0 +1,80 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remoteverifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/remoteverifier"
+)
+
+func TestHTTPVerifier(t *testing.T) {
+	t.Parallel()
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       common.HexToAddress("0xfff5"),
+			Beneficiary:      common.HexToAddress("0xbeee"),
+			CumulativePayout: big.NewInt(10),
+		},
+		Signature: []byte{1, 2, 3},
+	}
+
+	var (
+		status   int
+		response remoteverifier.Response
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != remoteverifier.Path {
+			t.Errorf("got request %s %s", r.Method, r.URL.Path)
+		}
+		var request remoteverifier.Request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		if !request.Cheque.Equal(cheque) {
+			t.Errorf("got cheque %v, want %v", request.Cheque, cheque)
+		}
+		if request.LastCumulativePayout == nil || request.LastCumulativePayout.Cmp(big.NewInt(5)) != 0 {
+			t.Errorf("got last cumulative payout %v, want 5", request.LastCumulativePayout)
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	verifier := remoteverifier.NewHTTPVerifier(server.URL+"/", nil)
+
+	for _, tc := range []struct {
+		name     string
+		status   int
+		response remoteverifier.Response
+		wantErr  error
+	}{
+		{name: "valid", status: http.StatusOK},
+		{name: "invalid signature", status: http.StatusOK, response: remoteverifier.Response{Reason: "invalid"}, wantErr: chequebook.ErrChequeInvalid},
+		{name: "not on chain", status: http.StatusOK, response: remoteverifier.Response{Reason: "not_on_chain"}, wantErr: chequebook.ErrChequebookNotOnChain},
+		{name: "unknown reason", status: http.StatusOK, response: remoteverifier.Response{Reason: "revoked"}, wantErr: chequebook.ErrChequeInvalid},
+		{name: "verifier failure", status: http.StatusInternalServerError, wantErr: remoteverifier.ErrVerifier},
+	} {
+		status, response = tc.status, tc.response
+
+		err := verifier.VerifyCheque(context.Background(), cheque, big.NewInt(5))
+		if tc.wantErr == nil && err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%s: got error %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
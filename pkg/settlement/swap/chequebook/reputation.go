@@ -0,0 +1,140 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	// prefix for the persistence key of the reputation of chequebooks
+	reputationKeyPrefix = "swap_chequebook_reputation_"
+
+	// DefaultReverifyInterval is how long cheques of a trusted chequebook are
+	// accepted without querying its liquidity.
+	DefaultReverifyInterval = time.Hour
+)
+
+// reputation is the settlement history of a chequebook.
+type reputation struct {
+	Score    uint64   // cheques fully verified and accepted since the last anomaly
+	Covered  *big.Int // cumulative payout covered by the liquidity of the last full verification
+	Verified int64    // unix timestamp of the last full verification
+}
+
+// Reputation scores chequebooks by their settlement history, so that the
+// liquidity of trusted chequebooks does not have to be queried for every
+// cheque. A chequebook is trusted once score cheques in a row passed the full
+// verification. Its cheques are then accepted without querying the chain as
+// long as the balance and paid out amount seen at the last full verification
+// cover them, for at most the reverify interval. Any anomaly, like a bounced
+// cheque, a withdrawal from the chequebook or a rejected cheque, resets the
+// score.
+type Reputation struct {
+	store    storage.StateStorer
+	score    uint64
+	reverify time.Duration
+	clock    clock.Clock
+
+	mu sync.Mutex
+}
+
+// NewReputation creates a reputation persisted in the store. Anomalies are
+// recorded by registering HandleEvent with the swap service.
+func NewReputation(store storage.StateStorer, score uint64, reverify time.Duration) *Reputation {
+	return &Reputation{
+		store:    store,
+		score:    score,
+		reverify: reverify,
+		clock:    clock.System,
+	}
+}
+
+// reputationKey computes the key where to store the reputation of a chequebook.
+func reputationKey(chequebook common.Address) string {
+	return fmt.Sprintf("%s%x", reputationKeyPrefix, chequebook)
+}
+
+func (r *Reputation) get(chequebook common.Address) (reputation, error) {
+	var rep reputation
+	err := r.store.Get(reputationKey(chequebook), &rep)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return reputation{}, err
+	}
+	return rep, nil
+}
+
+// Score returns the number of cheques from the chequebook which were fully
+// verified and accepted since its last anomaly.
+func (r *Reputation) Score(chequebook common.Address) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, err := r.get(chequebook)
+	return rep.Score, err
+}
+
+// Trusted reports whether the cheque with the cumulative payout can be
+// accepted without querying the liquidity of the chequebook.
+func (r *Reputation) Trusted(chequebook common.Address, cumulativePayout *big.Int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, err := r.get(chequebook)
+	if err != nil || rep.Covered == nil || rep.Score < r.score {
+		return false
+	}
+	if r.clock.Now().Sub(time.Unix(rep.Verified, 0)) >= r.reverify {
+		return false
+	}
+	return cumulativePayout.Cmp(rep.Covered) <= 0
+}
+
+// Verified records that a cheque from the chequebook was accepted after the
+// full verification found the balance and paid out amount of the chequebook.
+func (r *Reputation) Verified(chequebook common.Address, balance, paidOut *big.Int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, err := r.get(chequebook)
+	if err != nil {
+		return err
+	}
+	rep.Score++
+	rep.Covered = new(big.Int).Add(balance, paidOut)
+	rep.Verified = r.clock.Now().Unix()
+	return r.store.Put(reputationKey(chequebook), rep)
+}
+
+// Anomaly resets the reputation of the chequebook, so that its cheques are
+// fully verified again.
+func (r *Reputation) Anomaly(chequebook common.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.store.Delete(reputationKey(chequebook))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// HandleEvent resets the reputation of chequebooks whose cheques bounced or
+// whose issuer withdrew from them.
+func (r *Reputation) HandleEvent(event events.Event) {
+	switch event.Type {
+	case events.TypeChequeBounced, events.TypePeerWithdrawn:
+		_ = r.Anomaly(event.Chequebook)
+	}
+}
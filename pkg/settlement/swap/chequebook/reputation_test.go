@@ -0,0 +1,97 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestReceiveChequeTrusted(t *testing.T) {
+	t.Parallel()
+
+	store := storemock.NewStateStore()
+	beneficiary := common.HexToAddress("0xffff")
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	liquidity := func(balance int64) []transactionmock.Call {
+		return []transactionmock.Call{
+			transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(balance).FillBytes(make([]byte, 32)), "balance"),
+			transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+		}
+	}
+	// the liquidity is only queried for the cheques which are fully verified
+	calls := []transactionmock.Call{
+		transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+	}
+	calls = append(calls, liquidity(1000)...) // first cheque
+	calls = append(calls, liquidity(2000)...) // not covered by the last known liquidity
+	calls = append(calls, liquidity(2000)...) // after a bounce
+	calls = append(calls, liquidity(2000)...) // after the reverify interval
+
+	chequestore := chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(context.Context, common.Address) error {
+				return nil
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(transactionmock.WithABICallSequence(calls...)),
+		func(*chequebook.SignedCheque, int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	clock := clockmock.New(time.Unix(1000, 0))
+	reputation := chequebook.NewReputation(store, 1, time.Hour)
+	chequebook.SetReputationClock(reputation, clock)
+	chequestore.SetReputation(reputation)
+
+	receive := func(cumulativePayout int64) {
+		t.Helper()
+		cheque := &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(cumulativePayout),
+				Chequebook:       chequebookAddress,
+			},
+			Signature: make([]byte, 65),
+		}
+		if _, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(10), big.NewInt(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receive(100)
+	if score, err := reputation.Score(chequebookAddress); err != nil || score != 1 {
+		t.Fatalf("got score %d, %v, want 1", score, err)
+	}
+
+	receive(500)
+	receive(1000)
+	receive(1100)
+
+	reputation.HandleEvent(events.Event{Type: events.TypeChequeBounced, Chequebook: chequebookAddress})
+	if score, err := reputation.Score(chequebookAddress); err != nil || score != 0 {
+		t.Fatalf("got score %d, %v after bounce, want 0", score, err)
+	}
+	receive(1200)
+	receive(1300)
+
+	clock.Advance(time.Hour)
+	receive(1400)
+	receive(1500)
+}
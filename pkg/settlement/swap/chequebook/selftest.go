@@ -0,0 +1,181 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// Names of the steps of the self-test.
+const (
+	SelfTestStepDeposit  = "deposit"
+	SelfTestStepIssue    = "issue"
+	SelfTestStepCash     = "cash"
+	SelfTestStepWithdraw = "withdraw"
+)
+
+var (
+	// DefaultSelfTestAmount is the default amount of the cheque issued by the self-test.
+	DefaultSelfTestAmount = big.NewInt(1000)
+
+	// ErrInvalidSelfTestAmount is the error returned if the self-test amount is not positive.
+	ErrInvalidSelfTestAmount = errors.New("invalid self-test amount")
+	// ErrSelfTestChequeNotIssued is the error returned if issuing the self-test cheque did not produce a cheque.
+	ErrSelfTestChequeNotIssued = errors.New("self-test cheque not issued")
+	// ErrSelfTestReverted is the error returned if a transaction of the self-test reverted.
+	ErrSelfTestReverted = errors.New("self-test transaction reverted")
+)
+
+// SelfTestStep is the outcome of a single step of the self-test.
+type SelfTestStep struct {
+	Name     string
+	Duration time.Duration
+	TxHash   common.Hash // transaction of the step, zero for off-chain steps
+	GasUsed  uint64
+	Cost     *big.Int // native currency paid for the gas of the transaction
+	Error    string   // reason the step failed, empty if it succeeded
+}
+
+// SelfTestReport is the outcome of the self-test. Steps holds the steps which
+// were run, the first failed step being the last one.
+type SelfTestReport struct {
+	Amount      *big.Int // amount of the issued cheque
+	Beneficiary common.Address
+	Steps       []SelfTestStep
+	TotalCost   *big.Int // native currency paid for the gas of all transactions
+	Duration    time.Duration
+	Passed      bool
+}
+
+// SelfTest exercises the settlement pipeline against the chain. It deposits
+// twice the amount into the chequebook, issues a cheque of the amount to the
+// beneficiary, cashes it as the beneficiary and withdraws the amount, so that
+// the balance of the chequebook is unchanged if all steps succeed. The
+// beneficiary has to be the sender of the transactions, unless they are sent
+// from an account on its behalf. The report carries the timings and costs of
+// every step; a failed step ends the test without an error being returned.
+func SelfTest(ctx context.Context, service Service, transactionService transaction.Service, backend transaction.Backend, beneficiary common.Address, amount *big.Int) (*SelfTestReport, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, ErrInvalidSelfTestAmount
+	}
+	tokens := Tokens{amount: new(big.Int).Set(amount)}
+	if a, ok := transactionService.(accountTransactionService); ok {
+		beneficiary = a.Account()
+	}
+
+	report := &SelfTestReport{
+		Amount:      new(big.Int).Set(amount),
+		Beneficiary: beneficiary,
+		TotalCost:   big.NewInt(0),
+	}
+	start := time.Now()
+	defer func() { report.Duration = time.Since(start) }()
+
+	var cheque *SignedCheque
+	steps := []struct {
+		name string
+		run  func(ctx context.Context) (common.Hash, error)
+	}{
+		{SelfTestStepDeposit, func(ctx context.Context) (common.Hash, error) {
+			txHash, err := service.Deposit(ctx, tokens.Add(tokens))
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return txHash, service.WaitForDeposit(ctx, txHash)
+		}},
+		{SelfTestStepIssue, func(ctx context.Context) (common.Hash, error) {
+			_, err := service.Issue(ctx, beneficiary, tokens, func(signed *SignedCheque) error {
+				cheque = signed
+				return nil
+			})
+			if err == nil && cheque == nil {
+				err = ErrSelfTestChequeNotIssued
+			}
+			return common.Hash{}, err
+		}},
+		{SelfTestStepCash, func(ctx context.Context) (common.Hash, error) {
+			callData, err := chequebookABI.Pack("cashChequeBeneficiary", beneficiary, cheque.CumulativePayout, cheque.Signature)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			chequebookAddress := service.Address()
+			return transactionService.Send(ctx, &transaction.TxRequest{
+				To:          &chequebookAddress,
+				Data:        callData,
+				GasPrice:    sctx.GetGasPrice(ctx),
+				GasLimit:    sctx.GetGasLimitWithDefault(ctx, cashoutGasLimit),
+				Value:       big.NewInt(0),
+				Description: "self-test cheque cashout",
+			}, transaction.DefaultTipBoostPercent)
+		}},
+		{SelfTestStepWithdraw, func(ctx context.Context) (common.Hash, error) {
+			return service.Withdraw(ctx, tokens)
+		}},
+	}
+
+	for _, step := range steps {
+		result := SelfTestStep{Name: step.name, Cost: big.NewInt(0)}
+		stepStart := time.Now()
+		txHash, err := step.run(ctx)
+		if err == nil && txHash != (common.Hash{}) {
+			result.TxHash = txHash
+			var receipt *types.Receipt
+			receipt, err = transactionService.WaitForReceipt(ctx, txHash)
+			if err == nil {
+				result.GasUsed = receipt.GasUsed
+				var cost *big.Int
+				if cost, err = transactionFee(ctx, backend, txHash, receipt); err == nil {
+					result.Cost = cost
+				}
+			}
+			if err == nil && receipt.Status != types.ReceiptStatusSuccessful {
+				err = ErrSelfTestReverted
+			}
+		}
+		result.Duration = time.Since(stepStart)
+		report.TotalCost.Add(report.TotalCost, result.Cost)
+		if err != nil {
+			result.Error = fmt.Sprintf("%s: %v", step.name, err)
+			report.Steps = append(report.Steps, result)
+			return report, nil
+		}
+		report.Steps = append(report.Steps, result)
+	}
+
+	report.Passed = true
+	return report, nil
+}
+
+// transactionFee returns the fee paid by the mined transaction.
+func transactionFee(ctx context.Context, backend transaction.Backend, txHash common.Hash, receipt *types.Receipt) (*big.Int, error) {
+	tx, _, err := backend.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := tx.GasPrice()
+	if tx.Type() == types.DynamicFeeTxType {
+		header, err := backend.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if header.BaseFee != nil {
+			gasPrice = new(big.Int).Add(header.BaseFee, tx.GasTipCap())
+			if gasPrice.Cmp(tx.GasFeeCap()) > 0 {
+				gasPrice = tx.GasFeeCap()
+			}
+		}
+	}
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)), nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestSelfTest(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("0xcccc")
+	beneficiary := common.HexToAddress("0xbbbb")
+	depositHash := common.HexToHash("0x01")
+	cashHash := common.HexToHash("0x02")
+	withdrawHash := common.HexToHash("0x03")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(1500),
+		},
+		Signature: []byte{1},
+	}
+
+	newService := func(t *testing.T) chequebook.Service {
+		t.Helper()
+		return chequebookmock.NewChequebook(
+			chequebookmock.WithChequebookAddressFunc(func() common.Address {
+				return chequebookAddress
+			}),
+			chequebookmock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+				if amount.Cmp(big.NewInt(200)) != 0 {
+					t.Fatalf("got deposit of %d, want 200", amount)
+				}
+				return depositHash, nil
+			}),
+			chequebookmock.WithWaitForDepositFunc(func(ctx context.Context, txHash common.Hash) error {
+				return nil
+			}),
+			chequebookmock.WithChequebookIssueFunc(func(ctx context.Context, b common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				if b != beneficiary {
+					t.Fatalf("got cheque to %v, want %v", b, beneficiary)
+				}
+				return amount, sendChequeFunc(cheque)
+			}),
+			chequebookmock.WithChequebookWithdrawFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+				if amount.Cmp(big.NewInt(100)) != 0 {
+					t.Fatalf("got withdrawal of %d, want 100", amount)
+				}
+				return withdrawHash, nil
+			}),
+		)
+	}
+
+	backend := backendmock.New(
+		backendmock.WithTransactionByHashFunc(func(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+			return types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(2)}), false, nil
+		}),
+	)
+
+	t.Run("passed", func(t *testing.T) {
+		t.Parallel()
+
+		transactionService := transactionmock.New(
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				want, err := chequebookABI.Pack("cashChequeBeneficiary", beneficiary, cheque.CumulativePayout, cheque.Signature)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if *request.To != chequebookAddress || !bytes.Equal(request.Data, want) {
+					t.Fatal("wrong cashout transaction")
+				}
+				return cashHash, nil
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 10}, nil
+			}),
+		)
+
+		report, err := chequebook.SelfTest(context.Background(), newService(t), transactionService, backend, beneficiary, big.NewInt(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Passed {
+			t.Fatalf("self-test failed: %+v", report.Steps)
+		}
+
+		wantSteps := []struct {
+			name   string
+			txHash common.Hash
+		}{
+			{chequebook.SelfTestStepDeposit, depositHash},
+			{chequebook.SelfTestStepIssue, common.Hash{}},
+			{chequebook.SelfTestStepCash, cashHash},
+			{chequebook.SelfTestStepWithdraw, withdrawHash},
+		}
+		if len(report.Steps) != len(wantSteps) {
+			t.Fatalf("got %d steps, want %d", len(report.Steps), len(wantSteps))
+		}
+		for i, want := range wantSteps {
+			if report.Steps[i].Name != want.name || report.Steps[i].TxHash != want.txHash {
+				t.Fatalf("got step %s tx %v, want %s tx %v", report.Steps[i].Name, report.Steps[i].TxHash, want.name, want.txHash)
+			}
+		}
+		if report.TotalCost.Cmp(big.NewInt(60)) != 0 {
+			t.Fatalf("got total cost %d, want 60", report.TotalCost)
+		}
+	})
+
+	t.Run("reverted cashout", func(t *testing.T) {
+		t.Parallel()
+
+		transactionService := transactionmock.New(
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				return cashHash, nil
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				if txHash == cashHash {
+					return &types.Receipt{Status: types.ReceiptStatusFailed, GasUsed: 10}, nil
+				}
+				return &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 10}, nil
+			}),
+		)
+
+		report, err := chequebook.SelfTest(context.Background(), newService(t), transactionService, backend, beneficiary, big.NewInt(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Passed {
+			t.Fatal("self-test passed with a reverted cashout")
+		}
+		last := report.Steps[len(report.Steps)-1]
+		if len(report.Steps) != 3 || last.Name != chequebook.SelfTestStepCash || last.Error == "" {
+			t.Fatalf("got last step %+v of %d, want failed cashout", last, len(report.Steps))
+		}
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidMaxTransfer is the error if the maximum transfer amount of a split deposit is not positive.
+var ErrInvalidMaxTransfer = errors.New("maximum transfer amount must be positive")
+
+// SplitDepositResult is the outcome of a deposit made in multiple transfers.
+type SplitDepositResult struct {
+	TxHashes []common.Hash // transfers in the order they were sent
+	Amount   *big.Int      // nominal amount sent
+	Received *big.Int      // amount which arrived in the chequebook
+}
+
+// Fee returns the amount lost to transfer fees.
+func (r *SplitDepositResult) Fee() *big.Int {
+	return new(big.Int).Sub(r.Amount, r.Received)
+}
+
+// SplitDeposit deposits amount in transfers of at most maxTransfer each for
+// tokens capping the amount of a single transfer. Every transfer is confirmed
+// before the next one is sent. As tokens may charge a fee on transfer, the
+// received amount is taken from the chequebook and not assumed to be the
+// nominal amount. If a transfer fails, the result of the confirmed transfers
+// is returned together with the error.
+func (s *service) SplitDeposit(ctx context.Context, tokens, maxTransferTokens Tokens) (*SplitDepositResult, error) {
+	amount, maxTransfer := tokens.BigInt(), maxTransferTokens.BigInt()
+	if maxTransfer.Sign() <= 0 {
+		return nil, ErrInvalidMaxTransfer
+	}
+
+	balance, err := s.erc20Service.BalanceOf(ctx, s.ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// check we can afford this so we don't waste gas
+	if balance.Cmp(amount) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+
+	s.splitDepositMu.Lock()
+	defer s.splitDepositMu.Unlock()
+
+	before, err := s.funded(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SplitDepositResult{
+		Amount:   big.NewInt(0),
+		Received: big.NewInt(0),
+	}
+	remaining := new(big.Int).Set(amount)
+	for remaining.Sign() > 0 {
+		value := new(big.Int).Set(maxTransfer)
+		if remaining.Cmp(maxTransfer) < 0 {
+			value.Set(remaining)
+		}
+
+		txHash, err := s.erc20Service.Transfer(ctx, s.address, value)
+		if err != nil {
+			return result, s.splitDepositError(ctx, result, before, err)
+		}
+		if err := s.WaitForDeposit(ctx, txHash); err != nil {
+			return result, s.splitDepositError(ctx, result, before, fmt.Errorf("transfer %x: %w", txHash, err))
+		}
+
+		result.TxHashes = append(result.TxHashes, txHash)
+		result.Amount.Add(result.Amount, value)
+		remaining.Sub(remaining, value)
+	}
+
+	after, err := s.funded(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Received.Sub(after, before)
+	return result, nil
+}
+
+// splitDepositError records the amount received by the confirmed transfers
+// of a failed split deposit.
+func (s *service) splitDepositError(ctx context.Context, result *SplitDepositResult, before *big.Int, err error) error {
+	if len(result.TxHashes) == 0 {
+		return err
+	}
+	after, ferr := s.funded(ctx)
+	if ferr != nil {
+		return errors.Join(err, ferr)
+	}
+	result.Received.Sub(after, before)
+	return err
+}
+
+// funded returns the total amount ever put into the chequebook. Unlike the
+// balance it is not lowered by cashouts happening during a deposit.
+func (s *service) funded(ctx context.Context) (*big.Int, error) {
+	balance, err := s.contract.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalPaidOut, err := s.contract.TotalPaidOut(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return balance.Add(balance, totalPaidOut), nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+// feeToken simulates a token capping transfers and charging a fee on each of them.
+type feeToken struct {
+	mu           sync.Mutex
+	maxTransfer  *big.Int
+	fee          *big.Int
+	balance      *big.Int // of the chequebook
+	totalPaidOut *big.Int
+	transfers    []*big.Int
+	failAt       int // index of the transfer that fails, -1 for none
+}
+
+func (f *feeToken) chequebook(t *testing.T, address, owner common.Address) chequebook.Service {
+	t.Helper()
+
+	service, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+
+				switch {
+				case bytes.HasPrefix(request.Data, chequebookABI.Methods["balance"].ID):
+					return f.balance.FillBytes(make([]byte, 32)), nil
+				case bytes.HasPrefix(request.Data, chequebookABI.Methods["totalPaidOut"].ID):
+					return f.totalPaidOut.FillBytes(make([]byte, 32)), nil
+				}
+				return nil, errors.New("unexpected call")
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{Status: 1}, nil
+			}),
+		),
+		address,
+		owner,
+		nil,
+		&chequeSignerMock{},
+		erc20mock.New(
+			erc20mock.WithBalanceOfFunc(func(ctx context.Context, address common.Address) (*big.Int, error) {
+				return big.NewInt(1000), nil
+			}),
+			erc20mock.WithTransferFunc(func(ctx context.Context, to common.Address, value *big.Int) (common.Hash, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+
+				if to != address {
+					t.Fatalf("transfer to %x, want %x", to, address)
+				}
+				if value.Cmp(f.maxTransfer) > 0 {
+					return common.Hash{}, errors.New("transfer amount exceeds limit")
+				}
+				if len(f.transfers) == f.failAt {
+					return common.Hash{}, errors.New("transfer failed")
+				}
+				f.transfers = append(f.transfers, value)
+				f.balance.Add(f.balance, value)
+				f.balance.Sub(f.balance, f.fee)
+				// a cashout happening during the deposit
+				f.balance.Sub(f.balance, big.NewInt(5))
+				f.totalPaidOut.Add(f.totalPaidOut, big.NewInt(5))
+				return common.BigToHash(big.NewInt(int64(len(f.transfers)))), nil
+			}),
+		),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestSplitDeposit(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	owner := common.HexToAddress("0xfff")
+
+	t.Run("fee on transfer", func(t *testing.T) {
+		t.Parallel()
+
+		token := &feeToken{
+			maxTransfer:  big.NewInt(100),
+			fee:          big.NewInt(2),
+			balance:      big.NewInt(50),
+			totalPaidOut: big.NewInt(0),
+			failAt:       -1,
+		}
+		service := token.chequebook(t, address, owner)
+
+		result, err := service.SplitDeposit(context.Background(), chequebook.TokensFromUint64(250), chequebook.TokensFromUint64(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []int64{100, 100, 50}
+		if len(token.transfers) != len(want) {
+			t.Fatalf("got %d transfers, want %d", len(token.transfers), len(want))
+		}
+		for i, v := range want {
+			if token.transfers[i].Int64() != v {
+				t.Fatalf("transfer %d: got %d, want %d", i, token.transfers[i], v)
+			}
+		}
+		if len(result.TxHashes) != len(want) {
+			t.Fatalf("got %d transaction hashes, want %d", len(result.TxHashes), len(want))
+		}
+		if result.Amount.Int64() != 250 {
+			t.Fatalf("got amount %d, want 250", result.Amount)
+		}
+		if result.Received.Int64() != 244 {
+			t.Fatalf("got received %d, want 244", result.Received)
+		}
+		if result.Fee().Int64() != 6 {
+			t.Fatalf("got fee %d, want 6", result.Fee())
+		}
+	})
+
+	t.Run("failed transfer", func(t *testing.T) {
+		t.Parallel()
+
+		token := &feeToken{
+			maxTransfer:  big.NewInt(100),
+			fee:          big.NewInt(1),
+			balance:      big.NewInt(0),
+			totalPaidOut: big.NewInt(0),
+			failAt:       1,
+		}
+		service := token.chequebook(t, address, owner)
+
+		result, err := service.SplitDeposit(context.Background(), chequebook.TokensFromUint64(250), chequebook.TokensFromUint64(100))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if len(result.TxHashes) != 1 {
+			t.Fatalf("got %d transaction hashes, want 1", len(result.TxHashes))
+		}
+		if result.Received.Int64() != 99 {
+			t.Fatalf("got received %d, want 99", result.Received)
+		}
+	})
+
+	t.Run("invalid max transfer", func(t *testing.T) {
+		t.Parallel()
+
+		token := &feeToken{failAt: -1}
+		service := token.chequebook(t, address, owner)
+
+		_, err := service.SplitDeposit(context.Background(), chequebook.TokensFromUint64(250), chequebook.TokensFromUint64(0))
+		if !errors.Is(err, chequebook.ErrInvalidMaxTransfer) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidMaxTransfer)
+		}
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		t.Parallel()
+
+		token := &feeToken{failAt: -1}
+		service := token.chequebook(t, address, owner)
+
+		_, err := service.SplitDeposit(context.Background(), chequebook.TokensFromUint64(2000), chequebook.TokensFromUint64(100))
+		if !errors.Is(err, chequebook.ErrInsufficientFunds) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrInsufficientFunds)
+		}
+	})
+}
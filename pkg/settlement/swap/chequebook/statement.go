@@ -0,0 +1,123 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+)
+
+// ErrStatementInvalid is the error returned if a statement is incomplete or
+// was not signed by the expected node.
+var ErrStatementInvalid = errors.New("invalid statement")
+
+// Statement is the view of the issuing node of its settlement with the
+// beneficiary at a point in time. It is signed by the node so that the
+// beneficiary can prove in a dispute what the issuer claimed to have paid.
+type Statement struct {
+	Chequebook       common.Address // chequebook of the issuer
+	Beneficiary      common.Address
+	CumulativePayout *big.Int // total issued to the beneficiary, the cumulative payout of the last cheque
+	Timestamp        int64    // unix time at which the statement was made
+	Signature        []byte   // signature of the issuing node
+}
+
+// statementDomain computes chainId-dependant EIP712 domain for statements
+func statementDomain(chainID int64) eip712.TypedDataDomain {
+	return eip712.TypedDataDomain{
+		Name:    "SettlementStatement",
+		Version: "1.0",
+		ChainId: math.NewHexOrDecimal256(chainID),
+	}
+}
+
+// StatementTypes are the needed type descriptions for statement signing
+var StatementTypes = eip712.Types{
+	"EIP712Domain": eip712.EIP712DomainType,
+	"Statement": []eip712.Type{
+		{
+			Name: "chequebook",
+			Type: "address",
+		},
+		{
+			Name: "beneficiary",
+			Type: "address",
+		},
+		{
+			Name: "cumulativePayout",
+			Type: "uint256",
+		},
+		{
+			Name: "timestamp",
+			Type: "uint256",
+		},
+	},
+}
+
+// eip712DataForStatement converts a statement into the correct TypedData structure.
+func eip712DataForStatement(statement *Statement, chainID int64) *eip712.TypedData {
+	return &eip712.TypedData{
+		Domain: statementDomain(chainID),
+		Types:  StatementTypes,
+		Message: eip712.TypedDataMessage{
+			"chequebook":       statement.Chequebook.Hex(),
+			"beneficiary":      statement.Beneficiary.Hex(),
+			"cumulativePayout": statement.CumulativePayout.String(),
+			"timestamp":        big.NewInt(statement.Timestamp).String(),
+		},
+		PrimaryType: "Statement",
+	}
+}
+
+// SignStatement signs the statement with signer and sets its signature.
+func SignStatement(signer crypto.Signer, statement *Statement, chainID int64) error {
+	if statement.CumulativePayout == nil {
+		return ErrStatementInvalid
+	}
+	signature, err := signer.SignTypedData(eip712DataForStatement(statement, chainID))
+	if err != nil {
+		return err
+	}
+	statement.Signature = signature
+	return nil
+}
+
+// RecoverStatement recovers the ethereum address of the statement signer.
+func RecoverStatement(statement *Statement, chainID int64) (common.Address, error) {
+	if statement.CumulativePayout == nil {
+		return common.Address{}, ErrStatementInvalid
+	}
+
+	pubkey, err := crypto.RecoverEIP712(statement.Signature, eip712DataForStatement(statement, chainID))
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	ethAddr, err := crypto.NewEthereumAddress(*pubkey)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var signer common.Address
+	copy(signer[:], ethAddr)
+	return signer, nil
+}
+
+// VerifyStatement checks that the statement was signed by the given node.
+func VerifyStatement(statement *Statement, signer common.Address, chainID int64) error {
+	recovered, err := RecoverStatement(statement, chainID)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return ErrStatementInvalid
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestStatement(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(1)
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := &chequebook.Statement{
+		Chequebook:       common.HexToAddress("0x8d3766440f0d7b949a5e32995d09619a7f86e632"),
+		Beneficiary:      common.HexToAddress("0xbe"),
+		CumulativePayout: big.NewInt(10),
+		Timestamp:        1000,
+	}
+	if err := chequebook.SignStatement(signer, statement, chainID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		recovered, err := chequebook.RecoverStatement(statement, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recovered != issuer {
+			t.Fatalf("recovered wrong signer. wanted %x, got %x", issuer, recovered)
+		}
+		if err := chequebook.VerifyStatement(statement, issuer, chainID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		t.Parallel()
+
+		modified := *statement
+		modified.CumulativePayout = big.NewInt(20)
+		if err := chequebook.VerifyStatement(&modified, issuer, chainID); !errors.Is(err, chequebook.ErrStatementInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrStatementInvalid)
+		}
+	})
+
+	t.Run("other chain", func(t *testing.T) {
+		t.Parallel()
+
+		if err := chequebook.VerifyStatement(statement, issuer, chainID+1); !errors.Is(err, chequebook.ErrStatementInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrStatementInvalid)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		t.Parallel()
+
+		if err := chequebook.SignStatement(signer, &chequebook.Statement{}, chainID); !errors.Is(err, chequebook.ErrStatementInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrStatementInvalid)
+		}
+	})
+}
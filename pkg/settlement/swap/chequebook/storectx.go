@@ -0,0 +1,55 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// contextStore extends a state store with operations respecting a context,
+// so that settlement operations stop at the next store access once they are
+// cancelled.
+type contextStore struct {
+	storage.StateStorer
+}
+
+// GetContext reads the value of key into i unless the context is done before
+// or after the read. In the latter case i must not be used anymore.
+func (s contextStore) GetContext(ctx context.Context, key string, i interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := s.Get(key, i)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// PutContext writes the value i under key unless the context is already
+// done. A started write is not abandoned so that the stored state stays
+// consistent with what was sent to peers.
+func (s contextStore) PutContext(ctx context.Context, key string, i interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Put(key, i)
+}
+
+// IterateContext iterates over the keys with the prefix and stops with the
+// error of the context once it is done.
+func (s contextStore) IterateContext(ctx context.Context, prefix string, iterFunc storage.StateIterFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Iterate(prefix, func(key, value []byte) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		return iterFunc(key, value)
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const (
+	// DefaultCallTimeout is the default timeout of contract reads.
+	DefaultCallTimeout = 30 * time.Second
+	// DefaultSendTimeout is the default timeout of signing and submitting a transaction.
+	DefaultSendTimeout = time.Minute
+	// DefaultReceiptTimeout is the default timeout of waiting for a transaction to be mined.
+	DefaultReceiptTimeout = 10 * time.Minute
+)
+
+// Timeouts bound the contract reads and writes of the settlement services.
+// They apply on top of the context of the caller, so a caller can only
+// shorten them. A zero timeout leaves the respective operations unbounded.
+type Timeouts struct {
+	// Call bounds contract reads and transaction fee lookups.
+	Call time.Duration
+	// Send bounds signing and submitting transactions, including resends and cancellations.
+	Send time.Duration
+	// Receipt bounds waiting for a sent transaction to be mined.
+	Receipt time.Duration
+}
+
+// DefaultTimeouts returns the default timeouts.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Call:    DefaultCallTimeout,
+		Send:    DefaultSendTimeout,
+		Receipt: DefaultReceiptTimeout,
+	}
+}
+
+type timeoutTransactionService struct {
+	transaction.Service
+	timeouts Timeouts
+}
+
+// NewTimeoutTransactionService wraps the transaction service such that no
+// contract read or write made through it can hang forever, regardless of the
+// context passed by the caller. Operations exceeding their timeout fail with
+// an error wrapping context.DeadlineExceeded.
+func NewTimeoutTransactionService(service transaction.Service, timeouts Timeouts) transaction.Service {
+	return &timeoutTransactionService{
+		Service:  service,
+		timeouts: timeouts,
+	}
+}
+
+func (s *timeoutTransactionService) Call(ctx context.Context, request *transaction.TxRequest) (result []byte, err error) {
+	err = withTimeout(ctx, "contract call", s.timeouts.Call, func(ctx context.Context) error {
+		result, err = s.Service.Call(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+func (s *timeoutTransactionService) Send(ctx context.Context, request *transaction.TxRequest, tipCapBoostPercent int) (txHash common.Hash, err error) {
+	err = withTimeout(ctx, "send transaction", s.timeouts.Send, func(ctx context.Context) error {
+		txHash, err = s.Service.Send(ctx, request, tipCapBoostPercent)
+		return err
+	})
+	return txHash, err
+}
+
+func (s *timeoutTransactionService) WaitForReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = withTimeout(ctx, "wait for receipt", s.timeouts.Receipt, func(ctx context.Context) error {
+		receipt, err = s.Service.WaitForReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (s *timeoutTransactionService) ResendTransaction(ctx context.Context, txHash common.Hash) error {
+	return withTimeout(ctx, "resend transaction", s.timeouts.Send, func(ctx context.Context) error {
+		return s.Service.ResendTransaction(ctx, txHash)
+	})
+}
+
+func (s *timeoutTransactionService) CancelTransaction(ctx context.Context, originalTxHash common.Hash) (txHash common.Hash, err error) {
+	err = withTimeout(ctx, "cancel transaction", s.timeouts.Send, func(ctx context.Context) error {
+		txHash, err = s.Service.CancelTransaction(ctx, originalTxHash)
+		return err
+	})
+	return txHash, err
+}
+
+func (s *timeoutTransactionService) TransactionFee(ctx context.Context, txHash common.Hash) (fee *big.Int, err error) {
+	err = withTimeout(ctx, "transaction fee", s.timeouts.Call, func(ctx context.Context) error {
+		fee, err = s.Service.TransactionFee(ctx, txHash)
+		return err
+	})
+	return fee, err
+}
+
+// withTimeout runs f with the context bounded by the timeout. Errors caused by
+// the timeout rather than by the caller's context are annotated as such.
+func withTimeout(ctx context.Context, operation string, timeout time.Duration, f func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := f(tctx)
+	if err != nil && ctx.Err() == nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+		}
+		return fmt.Errorf("%s timed out after %s: %w", operation, timeout, err)
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestTimeoutTransactionService(t *testing.T) {
+	t.Parallel()
+
+	// the mocked operations block until their context is done
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	service := chequebook.NewTimeoutTransactionService(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, _ *transaction.TxRequest) ([]byte, error) {
+				return nil, hang(ctx)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, _ *transaction.TxRequest, _ int) (common.Hash, error) {
+				return common.Hash{}, hang(ctx)
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, _ common.Hash) (*types.Receipt, error) {
+				return nil, hang(ctx)
+			}),
+			transactionmock.WithTransactionFeeFunc(func(ctx context.Context, _ common.Hash) (*big.Int, error) {
+				// the underlying error does not need to be a context error
+				<-ctx.Done()
+				return nil, errors.New("request aborted")
+			}),
+		),
+		chequebook.Timeouts{
+			Call:    10 * time.Millisecond,
+			Send:    10 * time.Millisecond,
+			Receipt: 10 * time.Millisecond,
+		},
+	)
+
+	ctx := context.Background()
+	for name, f := range map[string]func() error{
+		"call": func() error {
+			_, err := service.Call(ctx, &transaction.TxRequest{})
+			return err
+		},
+		"send": func() error {
+			_, err := service.Send(ctx, &transaction.TxRequest{}, 0)
+			return err
+		},
+		"receipt": func() error {
+			_, err := service.WaitForReceipt(ctx, common.Hash{})
+			return err
+		},
+		"fee": func() error {
+			_, err := service.TransactionFee(ctx, common.Hash{})
+			return err
+		},
+	} {
+		if err := f(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("%s: got error %v, want %v", name, err, context.DeadlineExceeded)
+		}
+	}
+
+	// a cancelled caller context is reported as such
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := service.Call(cctx, &transaction.TxRequest{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTimeoutTransactionServiceDisabled(t *testing.T) {
+	t.Parallel()
+
+	service := chequebook.NewTimeoutTransactionService(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, _ *transaction.TxRequest) ([]byte, error) {
+				if _, ok := ctx.Deadline(); ok {
+					t.Fatal("unexpected deadline")
+				}
+				return []byte{1}, nil
+			}),
+		),
+		chequebook.Timeouts{},
+	)
+
+	if _, err := service.Call(context.Background(), &transaction.TxRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
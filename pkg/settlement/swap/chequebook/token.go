@@ -0,0 +1,58 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Token describes the erc20 token the chequebook is denominated in.
+type Token struct {
+	Address  common.Address
+	Symbol   string
+	Name     string
+	Decimals uint8
+}
+
+// Token returns the metadata of the erc20 token used by the chequebook.
+// The metadata does not change for a deployed token so it is only fetched once.
+func (s *service) Token(ctx context.Context) (*Token, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	if s.token != nil {
+		return s.token, nil
+	}
+
+	address, err := s.contract.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := s.erc20Service.Symbol(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.erc20Service.Name(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := s.erc20Service.Decimals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.token = &Token{
+		Address:  address,
+		Symbol:   symbol,
+		Name:     name,
+		Decimals: decimals,
+	}
+	return s.token, nil
+}
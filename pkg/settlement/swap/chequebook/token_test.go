@@ -0,0 +1,71 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestChequebookToken(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	ownerAdress := common.HexToAddress("0xfff")
+	tokenAddress := common.HexToAddress("0xeeee")
+
+	symbolCalls := 0
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICall(&chequebookABI, address, common.LeftPadBytes(tokenAddress.Bytes(), 32), "token"),
+		),
+		address,
+		ownerAdress,
+		nil,
+		&chequeSignerMock{},
+		erc20mock.New(
+			erc20mock.WithSymbolFunc(func(ctx context.Context) (string, error) {
+				symbolCalls++
+				return "BZZ", nil
+			}),
+			erc20mock.WithNameFunc(func(ctx context.Context) (string, error) {
+				return "Swarm Token", nil
+			}),
+			erc20mock.WithDecimalsFunc(func(ctx context.Context) (uint8, error) {
+				return 16, nil
+			}),
+		),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := chequebook.Token{
+		Address:  tokenAddress,
+		Symbol:   "BZZ",
+		Name:     "Swarm Token",
+		Decimals: 16,
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := chequebookService.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *token != expected {
+			t.Fatalf("got wrong token. wanted %+v, got %+v", expected, *token)
+		}
+	}
+
+	if symbolCalls != 1 {
+		t.Fatalf("expected token metadata to be cached, got %d symbol calls", symbolCalls)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidTokens is the error returned if an amount of tokens is missing,
+// negative or malformed.
+var ErrInvalidTokens = errors.New("invalid token amount")
+
+// Tokens is an amount of the token the chequebook is denominated in, in its
+// smallest unit (PLUR for BZZ). Amounts in accounting units or whole tokens
+// have to be converted explicitly before they are passed to the chequebook,
+// so that they cannot be mistaken for each other. Tokens are never negative
+// and immutable, the zero value is no tokens.
+type Tokens struct {
+	amount *big.Int
+}
+
+// NewTokens returns the amount of tokens in base units. It fails with
+// ErrInvalidTokens if the amount is nil or negative.
+func NewTokens(amount *big.Int) (Tokens, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return Tokens{}, fmt.Errorf("%v: %w", amount, ErrInvalidTokens)
+	}
+	return Tokens{amount: new(big.Int).Set(amount)}, nil
+}
+
+// MustNewTokens is NewTokens panicking on invalid amounts.
+func MustNewTokens(amount *big.Int) Tokens {
+	t, err := NewTokens(amount)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TokensFromUint64 returns the amount of tokens in base units.
+func TokensFromUint64(amount uint64) Tokens {
+	return Tokens{amount: new(big.Int).SetUint64(amount)}
+}
+
+// ParseTokens parses an amount of whole tokens like "1.5" into base units of
+// a token with the given decimals. It fails with ErrInvalidTokens if the
+// amount is negative, malformed or finer than a base unit.
+func ParseTokens(s string, decimals uint8) (Tokens, error) {
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" || len(fraction) > int(decimals) || strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") {
+		return Tokens{}, fmt.Errorf("%q: %w", s, ErrInvalidTokens)
+	}
+	amount, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	if !ok {
+		return Tokens{}, fmt.Errorf("%q: %w", s, ErrInvalidTokens)
+	}
+	return Tokens{amount: amount}, nil
+}
+
+// AccountingToTokens converts an amount in accounting units into tokens at
+// the exchange rate of the price oracle.
+func AccountingToTokens(amount, exchangeRate *big.Int) (Tokens, error) {
+	if amount == nil || exchangeRate == nil {
+		return Tokens{}, ErrInvalidTokens
+	}
+	return NewTokens(new(big.Int).Mul(amount, exchangeRate))
+}
+
+// BigInt returns a copy of the amount in base units.
+func (t Tokens) BigInt() *big.Int {
+	return new(big.Int).Set(t.amountOrZero())
+}
+
+// Sign returns 0 for no tokens and 1 otherwise.
+func (t Tokens) Sign() int {
+	return t.amountOrZero().Sign()
+}
+
+// Cmp compares the amounts like big.Int.Cmp.
+func (t Tokens) Cmp(o Tokens) int {
+	return t.amountOrZero().Cmp(o.amountOrZero())
+}
+
+// Add returns the sum of the amounts.
+func (t Tokens) Add(o Tokens) Tokens {
+	return Tokens{amount: new(big.Int).Add(t.amountOrZero(), o.amountOrZero())}
+}
+
+// Sub returns the difference of the amounts. It fails with ErrInvalidTokens if
+// o is larger than t.
+func (t Tokens) Sub(o Tokens) (Tokens, error) {
+	return NewTokens(new(big.Int).Sub(t.amountOrZero(), o.amountOrZero()))
+}
+
+// Decimal formats the amount in whole tokens of a token with the given
+// decimals, like "1.5".
+func (t Tokens) Decimal(decimals uint8) string {
+	digits := t.amountOrZero().String()
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+// String returns the amount in base units.
+func (t Tokens) String() string {
+	return t.amountOrZero().String()
+}
+
+func (t Tokens) amountOrZero() *big.Int {
+	if t.amount == nil {
+		return new(big.Int)
+	}
+	return t.amount
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+
+	t.Run("new", func(t *testing.T) {
+		t.Parallel()
+
+		for _, amount := range []*big.Int{nil, big.NewInt(-1)} {
+			if _, err := chequebook.NewTokens(amount); !errors.Is(err, chequebook.ErrInvalidTokens) {
+				t.Fatalf("got error %v for %v, want %v", err, amount, chequebook.ErrInvalidTokens)
+			}
+		}
+
+		amount := big.NewInt(10)
+		tokens, err := chequebook.NewTokens(amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		amount.SetInt64(20)
+		if tokens.String() != "10" {
+			t.Fatalf("got %s tokens, want 10 after changing the source", tokens)
+		}
+		tokens.BigInt().SetInt64(30)
+		if tokens.String() != "10" {
+			t.Fatalf("got %s tokens, want 10 after changing the copy", tokens)
+		}
+	})
+
+	t.Run("arithmetic", func(t *testing.T) {
+		t.Parallel()
+
+		var zero chequebook.Tokens
+		if zero.Sign() != 0 || zero.String() != "0" {
+			t.Fatalf("got zero value %s", zero)
+		}
+
+		a, b := chequebook.TokensFromUint64(30), chequebook.TokensFromUint64(20)
+		if sum := a.Add(b); sum.Cmp(chequebook.TokensFromUint64(50)) != 0 {
+			t.Fatalf("got sum %s, want 50", sum)
+		}
+		if diff, err := a.Sub(b); err != nil || diff.Cmp(chequebook.TokensFromUint64(10)) != 0 {
+			t.Fatalf("got difference %s, %v, want 10", diff, err)
+		}
+		if _, err := b.Sub(a); !errors.Is(err, chequebook.ErrInvalidTokens) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidTokens)
+		}
+	})
+
+	t.Run("accounting", func(t *testing.T) {
+		t.Parallel()
+
+		tokens, err := chequebook.AccountingToTokens(big.NewInt(25), big.NewInt(4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens.String() != "100" {
+			t.Fatalf("got %s tokens, want 100", tokens)
+		}
+		if _, err := chequebook.AccountingToTokens(big.NewInt(-1), big.NewInt(4)); !errors.Is(err, chequebook.ErrInvalidTokens) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidTokens)
+		}
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			decimal  string
+			decimals uint8
+			base     string
+			format   string
+		}{
+			{"1.5", 16, "15000000000000000", "1.5"},
+			{"0.0000000000000001", 16, "1", "0.0000000000000001"},
+			{"2", 16, "20000000000000000", "2"},
+			{".25", 2, "25", "0.25"},
+			{"7", 0, "7", "7"},
+		} {
+			tokens, err := chequebook.ParseTokens(tc.decimal, tc.decimals)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tc.decimal, err)
+			}
+			if tokens.String() != tc.base {
+				t.Fatalf("got %s base units for %q, want %s", tokens, tc.decimal, tc.base)
+			}
+			if got := tokens.Decimal(tc.decimals); got != tc.format {
+				t.Fatalf("got %q formatting %s, want %q", got, tokens, tc.format)
+			}
+		}
+
+		for _, s := range []string{"", ".", "-1", "+1", "1.005", "1e3", "abc"} {
+			if _, err := chequebook.ParseTokens(s, 2); !errors.Is(err, chequebook.ErrInvalidTokens) {
+				t.Fatalf("got error %v parsing %q, want %v", err, s, chequebook.ErrInvalidTokens)
+			}
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+var (
+	// ErrChequeRejected is the error returned if a cheque validator rejects a received cheque.
+	ErrChequeRejected = errors.New("cheque rejected by validator")
+	// ErrChequebookTooYoung is the error returned if the chequebook was deployed less than the required number of blocks ago.
+	ErrChequebookTooYoung = errors.New("chequebook too young")
+)
+
+// ChequeValidator is a rule received cheques have to satisfy on top of the
+// builtin checks. It is called for cheques which passed the builtin checks
+// with the amount the cheque adds to the last received cheque. A non-nil
+// error rejects the cheque.
+type ChequeValidator interface {
+	ValidateCheque(ctx context.Context, cheque *SignedCheque, amount *big.Int) error
+}
+
+// ChequeValidatorFunc is an adapter to use a function as a ChequeValidator.
+type ChequeValidatorFunc func(ctx context.Context, cheque *SignedCheque, amount *big.Int) error
+
+// ValidateCheque calls f.
+func (f ChequeValidatorFunc) ValidateCheque(ctx context.Context, cheque *SignedCheque, amount *big.Int) error {
+	return f(ctx, cheque, amount)
+}
+
+// namedValidator is a validator together with the name reported when it
+// rejects a cheque. Builtin validators passed to the cheque store have no name.
+type namedValidator struct {
+	name      string
+	validator ChequeValidator
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = make(map[string]ChequeValidator)
+)
+
+// RegisterChequeValidator makes a validator available under the name for
+// deployments which build the node with their own trust rules, typically
+// from the init function of the package defining the rule. Registered
+// validators are applied by every cheque store created afterwards.
+// It panics if the name is already taken or the validator is nil.
+func RegisterChequeValidator(name string, validator ChequeValidator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+
+	if validator == nil {
+		panic("chequebook: register nil cheque validator " + name)
+	}
+	if _, ok := validators[name]; ok {
+		panic("chequebook: cheque validator registered twice " + name)
+	}
+	validators[name] = validator
+}
+
+// RegisteredChequeValidators returns the names of the registered validators in sorted order.
+func RegisteredChequeValidators() []string {
+	registered := registeredValidators()
+	names := make([]string, 0, len(registered))
+	for _, v := range registered {
+		names = append(names, v.name)
+	}
+	return names
+}
+
+// registeredValidators returns the registered validators in the order of their names.
+func registeredValidators() []namedValidator {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	v := make([]namedValidator, 0, len(names))
+	for _, name := range names {
+		v = append(v, namedValidator{name: name, validator: validators[name]})
+	}
+	return v
+}
+
+// validateCheque runs the validators in order and stops at the first rejection.
+func validateCheque(ctx context.Context, validators []namedValidator, cheque *SignedCheque, amount *big.Int) error {
+	for _, v := range validators {
+		err := v.validator.ValidateCheque(ctx, cheque, amount)
+		if err == nil {
+			continue
+		}
+		if v.name == "" {
+			return fmt.Errorf("%w: %w", ErrChequeRejected, err)
+		}
+		return fmt.Errorf("%s: %w: %w", v.name, ErrChequeRejected, err)
+	}
+	return nil
+}
+
+// MinChequebookAgeValidator rejects cheques from chequebooks deployed less
+// than a number of blocks ago. A chequebook is old enough if its code
+// already existed the given number of blocks before the chain head, so the
+// backend has to serve the state of that block.
+type MinChequebookAgeValidator struct {
+	backend   transaction.Backend
+	minBlocks uint64
+
+	mu  sync.Mutex
+	old map[common.Address]struct{} // chequebooks known to be old enough
+}
+
+// NewMinChequebookAgeValidator creates a validator requiring chequebooks to
+// be at least minBlocks old.
+func NewMinChequebookAgeValidator(backend transaction.Backend, minBlocks uint64) *MinChequebookAgeValidator {
+	return &MinChequebookAgeValidator{
+		backend:   backend,
+		minBlocks: minBlocks,
+		old:       make(map[common.Address]struct{}),
+	}
+}
+
+// ValidateCheque checks the age of the chequebook of the cheque.
+func (v *MinChequebookAgeValidator) ValidateCheque(ctx context.Context, cheque *SignedCheque, _ *big.Int) error {
+	v.mu.Lock()
+	_, ok := v.old[cheque.Chequebook]
+	v.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	head, err := v.backend.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head < v.minBlocks {
+		return ErrChequebookTooYoung
+	}
+
+	code, err := v.backend.CodeAt(ctx, cheque.Chequebook, new(big.Int).SetUint64(head-v.minBlocks))
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("%x not deployed before block %d: %w", cheque.Chequebook, head-v.minBlocks, ErrChequebookTooYoung)
+	}
+
+	// once old enough the chequebook stays old enough
+	v.mu.Lock()
+	v.old[cheque.Chequebook] = struct{}{}
+	v.mu.Unlock()
+	return nil
+}
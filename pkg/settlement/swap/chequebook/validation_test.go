@@ -0,0 +1,192 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+// validatedChequeStore returns a cheque store accepting cheques of the chequebook up to the validators.
+func validatedChequeStore(t *testing.T, store storage.StateStorer, beneficiary, chequebookAddress common.Address, validators ...chequebook.ChequeValidator) chequebook.ChequeStore {
+	t.Helper()
+
+	issuer := common.HexToAddress("0xbeee")
+	return chequebook.NewChequeStore(
+		store,
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				return nil
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(1000).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		},
+		validators...,
+	)
+}
+
+func TestReceiveChequeValidator(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	chequebookAddress := common.HexToAddress("0xeeee")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(101),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+
+		errUntrusted := errors.New("untrusted")
+		var gotAmount *big.Int
+		chequestore := validatedChequeStore(t, storemock.NewStateStore(), beneficiary, chequebookAddress,
+			chequebook.ChequeValidatorFunc(func(ctx context.Context, c *chequebook.SignedCheque, amount *big.Int) error {
+				gotAmount = amount
+				return errUntrusted
+			}),
+		)
+
+		_, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(10), big.NewInt(0))
+		if !errors.Is(err, chequebook.ErrChequeRejected) || !errors.Is(err, errUntrusted) {
+			t.Fatalf("got error %v, want %v and %v", err, chequebook.ErrChequeRejected, errUntrusted)
+		}
+		if gotAmount == nil || gotAmount.Cmp(big.NewInt(101)) != 0 {
+			t.Fatalf("validator got amount %v, want 101", gotAmount)
+		}
+		if _, err := chequestore.LastCheque(chequebookAddress); !errors.Is(err, chequebook.ErrNoCheque) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+		}
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		t.Parallel()
+
+		chequestore := validatedChequeStore(t, storemock.NewStateStore(), beneficiary, chequebookAddress,
+			chequebook.ChequeValidatorFunc(func(ctx context.Context, c *chequebook.SignedCheque, amount *big.Int) error {
+				return nil
+			}),
+		)
+
+		if _, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(10), big.NewInt(0)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestRegisterChequeValidator(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	// the registered validator only rejects this chequebook so it does not affect other tests
+	chequebookAddress := common.HexToAddress("0xe443")
+
+	chequebook.RegisterChequeValidator("test-reject", chequebook.ChequeValidatorFunc(func(ctx context.Context, c *chequebook.SignedCheque, amount *big.Int) error {
+		if c.Chequebook == chequebookAddress {
+			return errors.New("blocked chequebook")
+		}
+		return nil
+	}))
+
+	found := false
+	for _, name := range chequebook.RegisteredChequeValidators() {
+		if name == "test-reject" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("validator not registered")
+	}
+
+	chequestore := validatedChequeStore(t, storemock.NewStateStore(), beneficiary, chequebookAddress)
+	_, err := chequestore.ReceiveCheque(context.Background(), &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(101),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}, big.NewInt(10), big.NewInt(0))
+	if !errors.Is(err, chequebook.ErrChequeRejected) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeRejected)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("registering a name twice did not panic")
+			}
+		}()
+		chequebook.RegisterChequeValidator("test-reject", chequebook.ChequeValidatorFunc(func(ctx context.Context, c *chequebook.SignedCheque, amount *big.Int) error {
+			return nil
+		}))
+	}()
+}
+
+func TestMinChequebookAgeValidator(t *testing.T) {
+	t.Parallel()
+
+	oldChequebook := common.HexToAddress("0xaaaa")
+	newChequebook := common.HexToAddress("0xbbbb")
+	head := uint64(1000)
+	codeCalls := 0
+
+	validator := chequebook.NewMinChequebookAgeValidator(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			return head, nil
+		}),
+		backendmock.WithCodeAtFunc(func(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+			codeCalls++
+			if blockNumber.Uint64() != head-100 {
+				t.Fatalf("got code at block %d, want %d", blockNumber, head-100)
+			}
+			if contract == oldChequebook {
+				return []byte{1}, nil
+			}
+			return nil, nil
+		}),
+	), 100)
+
+	cheque := func(address common.Address) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{Cheque: chequebook.Cheque{Chequebook: address}}
+	}
+
+	if err := validator.ValidateCheque(context.Background(), cheque(newChequebook), big.NewInt(1)); !errors.Is(err, chequebook.ErrChequebookTooYoung) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequebookTooYoung)
+	}
+	if err := validator.ValidateCheque(context.Background(), cheque(oldChequebook), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	// old enough chequebooks are not checked again
+	if err := validator.ValidateCheque(context.Background(), cheque(oldChequebook), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if codeCalls != 2 {
+		t.Fatalf("got %d code calls, want 2", codeCalls)
+	}
+}
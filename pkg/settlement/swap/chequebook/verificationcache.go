@@ -0,0 +1,204 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	// prefix for the persistence key of chequebook verification results
+	verificationKeyPrefix = "swap_chequebook_verification_"
+
+	// DefaultVerificationTTL is how long a chequebook stays verified before the factories are queried again.
+	DefaultVerificationTTL = 24 * time.Hour
+	// DefaultNegativeVerificationTTL is how long a chequebook which was not deployed by a trusted factory stays rejected.
+	DefaultNegativeVerificationTTL = 10 * time.Minute
+)
+
+// ErrTrustedFactoriesUnsupported is the error returned if the trusted factories cannot be changed at runtime.
+var ErrTrustedFactoriesUnsupported = errors.New("trusted factories cannot be changed")
+
+// verificationResult is the cached result of a chequebook verification.
+type verificationResult struct {
+	Deployed bool  // whether the chequebook was deployed by a trusted factory
+	Expiry   int64 // unix timestamp after which the result has to be verified again
+}
+
+// cachingFactory is a Factory which caches chequebook verification results in the statestore.
+type cachingFactory struct {
+	Factory
+	store       storage.StateStorer
+	ttl         time.Duration
+	negativeTTL time.Duration
+	clock       clock.Clock
+
+	lock       sync.Mutex
+	generation uint64 // incremented whenever the trusted factories change
+}
+
+// NewCachingFactory wraps the factory so that the results of VerifyChequebook are
+// cached in the store. Chequebooks deployed by a trusted factory are cached for ttl,
+// chequebooks which are not are cached for negativeTTL. Other errors are not cached.
+// The returned factory implements TrustedFactories if the wrapped factory does.
+func NewCachingFactory(factory Factory, store storage.StateStorer, ttl, negativeTTL time.Duration) Factory {
+	return &cachingFactory{
+		Factory:     factory,
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		clock:       clock.System,
+	}
+}
+
+// verificationKey computes the key where to store the verification result of a chequebook.
+func verificationKey(chequebook common.Address) string {
+	return fmt.Sprintf("%s%x", verificationKeyPrefix, chequebook)
+}
+
+// VerifyChequebook checks that the supplied chequebook has been deployed by a
+// trusted factory, using the cached result if it did not yet expire.
+func (c *cachingFactory) VerifyChequebook(ctx context.Context, chequebook common.Address) error {
+	now := c.clock.Now()
+
+	result, ok, err := c.cached(now, chequebook)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if result.Deployed {
+			return nil
+		}
+		return ErrNotDeployedByFactory
+	}
+
+	generation := c.currentGeneration()
+
+	err = c.Factory.VerifyChequebook(ctx, chequebook)
+	result, ok = c.result(now, err)
+	if !ok {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// do not cache a result which was obtained with an outdated list of trusted factories
+	if generation != c.generation {
+		return err
+	}
+
+	if putErr := c.store.Put(verificationKey(chequebook), result); putErr != nil {
+		return putErr
+	}
+
+	return err
+}
+
+// cached returns the cached verification result of the chequebook. It
+// returns false if there is none which did not yet expire.
+func (c *cachingFactory) cached(now time.Time, chequebook common.Address) (verificationResult, bool, error) {
+	var result verificationResult
+	err := c.store.Get(verificationKey(chequebook), &result)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return verificationResult{}, false, nil
+	case err != nil:
+		return verificationResult{}, false, err
+	}
+	return result, now.Unix() < result.Expiry, nil
+}
+
+// result converts the outcome of a verification into a cacheable result.
+// It returns false if the outcome must not be cached.
+func (c *cachingFactory) result(now time.Time, err error) (verificationResult, bool) {
+	switch {
+	case err == nil:
+		return verificationResult{Deployed: true, Expiry: now.Add(c.ttl).Unix()}, true
+	case errors.Is(err, ErrNotDeployedByFactory):
+		return verificationResult{Deployed: false, Expiry: now.Add(c.negativeTTL).Unix()}, true
+	default:
+		return verificationResult{}, false
+	}
+}
+
+func (c *cachingFactory) currentGeneration() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+// TrustedFactories returns the trusted factories of the wrapped factory.
+func (c *cachingFactory) TrustedFactories() (common.Address, []common.Address) {
+	trusted, ok := c.Factory.(TrustedFactories)
+	if !ok {
+		return common.Address{}, nil
+	}
+	return trusted.TrustedFactories()
+}
+
+// ContractInfo returns the chequebook contract info of the wrapped factory.
+func (c *cachingFactory) ContractInfo(ctx context.Context, chequebook common.Address) (*ContractInfo, error) {
+	inspector, ok := c.Factory.(ContractInspector)
+	if !ok {
+		return nil, ErrContractInfoUnsupported
+	}
+	return inspector.ContractInfo(ctx, chequebook)
+}
+
+// SetLegacyFactories replaces the legacy factories of the wrapped factory and
+// revalidates all cached verification results against the new set of factories.
+// Results which cannot be revalidated are removed so they are verified again on next use.
+func (c *cachingFactory) SetLegacyFactories(ctx context.Context, addresses []common.Address) error {
+	trusted, ok := c.Factory.(TrustedFactories)
+	if !ok {
+		return ErrTrustedFactoriesUnsupported
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := trusted.SetLegacyFactories(ctx, addresses); err != nil {
+		return err
+	}
+	c.generation++
+
+	var chequebooks []common.Address
+	err := c.store.Iterate(verificationKeyPrefix, func(key, _ []byte) (stop bool, err error) {
+		if !strings.HasPrefix(string(key), verificationKeyPrefix) {
+			return true, nil
+		}
+		chequebooks = append(chequebooks, common.HexToAddress(strings.TrimPrefix(string(key), verificationKeyPrefix)))
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	for _, chequebook := range chequebooks {
+		result, ok := c.result(now, c.Factory.VerifyChequebook(ctx, chequebook))
+		if !ok {
+			if err := c.store.Delete(verificationKey(chequebook)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.store.Put(verificationKey(chequebook), result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestCachingFactory(t *testing.T) {
+	t.Parallel()
+
+	trusted := common.HexToAddress("0xaaaa")
+	untrusted := common.HexToAddress("0xbbbb")
+	failing := common.HexToAddress("0xcccc")
+	rpcErr := errors.New("rpc error")
+
+	calls := make(map[common.Address]int)
+	factory := chequebook.NewCachingFactory(&factoryMock{
+		verifyChequebook: func(ctx context.Context, address common.Address) error {
+			calls[address]++
+			switch address {
+			case trusted:
+				return nil
+			case untrusted:
+				return chequebook.ErrNotDeployedByFactory
+			default:
+				return rpcErr
+			}
+		},
+	}, storemock.NewStateStore(), time.Hour, time.Minute)
+
+	clock := clockmock.New(time.Unix(1000, 0))
+	chequebook.SetCachingFactoryClock(factory, clock)
+
+	verify := func(address common.Address, wantErr error) {
+		t.Helper()
+		err := factory.VerifyChequebook(context.Background(), address)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("got error %v, want %v", err, wantErr)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		verify(trusted, nil)
+		verify(untrusted, chequebook.ErrNotDeployedByFactory)
+		verify(failing, rpcErr)
+	}
+
+	if calls[trusted] != 1 {
+		t.Fatalf("trusted chequebook verified %d times, want 1", calls[trusted])
+	}
+	if calls[untrusted] != 1 {
+		t.Fatalf("untrusted chequebook verified %d times, want 1", calls[untrusted])
+	}
+	if calls[failing] != 3 {
+		t.Fatalf("failed verifications must not be cached, verified %d times, want 3", calls[failing])
+	}
+
+	// the negative result expires first
+	clock.Advance(2 * time.Minute)
+	verify(trusted, nil)
+	verify(untrusted, chequebook.ErrNotDeployedByFactory)
+	if calls[trusted] != 1 {
+		t.Fatalf("trusted chequebook verified %d times, want 1", calls[trusted])
+	}
+	if calls[untrusted] != 2 {
+		t.Fatalf("untrusted chequebook verified %d times, want 2", calls[untrusted])
+	}
+
+	clock.Advance(time.Hour)
+	verify(trusted, nil)
+	if calls[trusted] != 2 {
+		t.Fatalf("trusted chequebook verified %d times, want 2", calls[trusted])
+	}
+}
+
+// trustedFactoryMock is a factory mock whose legacy factories can be changed.
+type trustedFactoryMock struct {
+	factoryMock
+	legacy []common.Address
+}
+
+func (m *trustedFactoryMock) TrustedFactories() (common.Address, []common.Address) {
+	return common.Address{}, m.legacy
+}
+
+func (m *trustedFactoryMock) SetLegacyFactories(ctx context.Context, addresses []common.Address) error {
+	m.legacy = addresses
+	return nil
+}
+
+func TestCachingFactorySetLegacyFactories(t *testing.T) {
+	t.Parallel()
+
+	legacyFactory := common.HexToAddress("0xffff")
+	oldChequebook := common.HexToAddress("0xaaaa")
+	newChequebook := common.HexToAddress("0xbbbb")
+
+	calls := make(map[common.Address]int)
+	mock := &trustedFactoryMock{}
+	mock.verifyChequebook = func(ctx context.Context, address common.Address) error {
+		calls[address]++
+		// the old chequebook is only trusted without the legacy factory, the new one only with it
+		trustsLegacy := len(mock.legacy) == 1 && mock.legacy[0] == legacyFactory
+		if (address == newChequebook) == trustsLegacy {
+			return nil
+		}
+		return chequebook.ErrNotDeployedByFactory
+	}
+
+	store := storemock.NewStateStore()
+	factory := chequebook.NewCachingFactory(mock, store, time.Hour, time.Hour)
+
+	verify := func(address common.Address, wantErr error) {
+		t.Helper()
+		err := factory.VerifyChequebook(context.Background(), address)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("got error %v, want %v", err, wantErr)
+		}
+	}
+
+	verify(oldChequebook, nil)
+	verify(newChequebook, chequebook.ErrNotDeployedByFactory)
+
+	err := factory.(chequebook.TrustedFactories).SetLegacyFactories(context.Background(), []common.Address{legacyFactory})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls[oldChequebook] != 2 || calls[newChequebook] != 2 {
+		t.Fatalf("cached verifications not revalidated, got calls %v", calls)
+	}
+
+	// the revalidated results are served from the cache
+	verify(oldChequebook, chequebook.ErrNotDeployedByFactory)
+	verify(newChequebook, nil)
+	if calls[oldChequebook] != 2 || calls[newChequebook] != 2 {
+		t.Fatalf("revalidated results not cached, got calls %v", calls)
+	}
+
+	_, legacy := factory.(chequebook.TrustedFactories).TrustedFactories()
+	if len(legacy) != 1 || legacy[0] != legacyFactory {
+		t.Fatalf("got legacy factories %v, want %v", legacy, []common.Address{legacyFactory})
+	}
+}
+
+func TestCachingFactorySetLegacyFactoriesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	factory := chequebook.NewCachingFactory(&factoryMock{}, storemock.NewStateStore(), time.Hour, time.Hour)
+
+	err := factory.(chequebook.TrustedFactories).SetLegacyFactories(context.Background(), nil)
+	if !errors.Is(err, chequebook.ErrTrustedFactoriesUnsupported) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrTrustedFactoriesUnsupported)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"math/big"
+)
+
+// ChequeVerifier verifies received cheques without keeping any state about
+// them, so that the verification can be delegated to a trusted node.
+type ChequeVerifier interface {
+	// VerifyCheque checks that the cheque pays out more than
+	// lastCumulativePayout and is signed by the issuer of its chequebook. If
+	// lastCumulativePayout is nil, the cheque is the first one received from the
+	// chequebook and the chequebook also has to be deployed by a trusted factory.
+	VerifyCheque(ctx context.Context, cheque *SignedCheque, lastCumulativePayout *big.Int) error
+}
+
+// VerifyCheque checks the cheque against the chain. The verification is done
+// locally even if received cheques are verified by a delegate.
+func (s *chequeStore) VerifyCheque(ctx context.Context, cheque *SignedCheque, lastCumulativePayout *big.Int) error {
+	if cheque.CumulativePayout == nil {
+		return ErrChequeInvalid
+	}
+
+	if lastCumulativePayout == nil {
+		if err := s.factory.VerifyChequebook(ctx, cheque.Chequebook); err != nil {
+			return err
+		}
+		lastCumulativePayout = big.NewInt(0)
+	}
+	if cheque.CumulativePayout.Cmp(lastCumulativePayout) <= 0 {
+		return ErrChequeNotIncreasing
+	}
+
+	return s.verifySignature(ctx, newChequebookContract(cheque.Chequebook, s.transactionService), cheque)
+}
+
+// DelegateVerification makes the verifier verify received cheques instead
+// of the cheque store itself. A nil verifier restores the local verification.
+func (s *chequeStore) DelegateVerification(verifier ChequeVerifier) {
+	s.verifierMu.Lock()
+	defer s.verifierMu.Unlock()
+	s.verifier = verifier
+}
+
+// SetReputation makes the cheque store verify the cheques of the chequebooks
+// trusted by the reputation on the fast path: locally, even if the
+// verification is delegated, and without querying the liquidity of the
+// chequebook. A nil reputation verifies all cheques fully.
+func (s *chequeStore) SetReputation(reputation *Reputation) {
+	s.verifierMu.Lock()
+	defer s.verifierMu.Unlock()
+	s.reputation = reputation
+}
+
+// chequeReputation returns the reputation of chequebooks, nil if there is none.
+func (s *chequeStore) chequeReputation() *Reputation {
+	s.verifierMu.Lock()
+	defer s.verifierMu.Unlock()
+	return s.reputation
+}
+
+// chequeVerifier returns the verifier of received cheques.
+func (s *chequeStore) chequeVerifier() ChequeVerifier {
+	s.verifierMu.Lock()
+	defer s.verifierMu.Unlock()
+	if s.verifier == nil {
+		return s
+	}
+	return s.verifier
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+type chequeVerifierFunc func(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error
+
+func (f chequeVerifierFunc) VerifyCheque(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error {
+	return f(ctx, cheque, lastCumulativePayout)
+}
+
+func TestVerifyCheque(t *testing.T) {
+	t.Parallel()
+
+	issuer := common.HexToAddress("0xbeee")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	var verifiedWithFactory bool
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				verifiedWithFactory = true
+				return nil
+			},
+		},
+		1,
+		common.HexToAddress("0xffff"),
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, issuer.Hash().Bytes(), "issuer"),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			return issuer, nil
+		})
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("0xffff"),
+			CumulativePayout: big.NewInt(10),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	if err := chequestore.VerifyCheque(context.Background(), cheque, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !verifiedWithFactory {
+		t.Fatal("did not verify with factory")
+	}
+
+	verifiedWithFactory = false
+	if err := chequestore.VerifyCheque(context.Background(), cheque, big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if verifiedWithFactory {
+		t.Fatal("needlessly verify with factory")
+	}
+
+	if err := chequestore.VerifyCheque(context.Background(), cheque, big.NewInt(10)); !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeNotIncreasing)
+	}
+}
+
+func TestReceiveChequeDelegatedVerification(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xffff")
+	chequebookAddress := common.HexToAddress("0xeeee")
+
+	chequestore := chequebook.NewChequeStore(
+		storemock.NewStateStore(),
+		&factoryMock{
+			verifyChequebook: func(ctx context.Context, address common.Address) error {
+				t.Fatal("verified with factory despite delegation")
+				return nil
+			},
+		},
+		1,
+		beneficiary,
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(100).FillBytes(make([]byte, 32)), "balance"),
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		func(c *chequebook.SignedCheque, cid int64) (common.Address, error) {
+			t.Fatal("recovered cheque despite delegation")
+			return common.Address{}, nil
+		})
+
+	var (
+		verifyErr error
+		gotLast   = big.NewInt(-1)
+	)
+	chequestore.DelegateVerification(chequeVerifierFunc(func(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error {
+		gotLast = lastCumulativePayout
+		return verifyErr
+	}))
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(10),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: make([]byte, 65),
+	}
+
+	verifyErr = chequebook.ErrChequeInvalid
+	if _, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(1), big.NewInt(0)); !errors.Is(err, chequebook.ErrChequeInvalid) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeInvalid)
+	}
+	if gotLast != nil {
+		t.Fatalf("got last cumulative payout %v for the first cheque, want nil", gotLast)
+	}
+
+	verifyErr = nil
+	received, err := chequestore.ReceiveCheque(context.Background(), cheque, big.NewInt(1), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("got received amount %d, want %d", received, 10)
+	}
+
+	lower := &chequebook.SignedCheque{Cheque: cheque.Cheque, Signature: cheque.Signature}
+	lower.CumulativePayout = big.NewInt(5)
+	verifyErr = chequebook.ErrChequeNotIncreasing
+	if _, err := chequestore.ReceiveCheque(context.Background(), lower, big.NewInt(1), big.NewInt(0)); !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeNotIncreasing)
+	}
+	if gotLast == nil || gotLast.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("got last cumulative payout %v, want %d", gotLast, 10)
+	}
+}
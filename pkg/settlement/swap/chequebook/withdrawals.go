@@ -0,0 +1,63 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const withdrawEventName = "Withdraw"
+
+var withdrawEventType = chequebookABI.Events[withdrawEventName]
+
+// Withdrawal is a withdrawal of tokens from a chequebook found on chain.
+type Withdrawal struct {
+	Chequebook  common.Address
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+	Amount      *big.Int
+}
+
+type withdrawEvent struct {
+	Amount *big.Int
+}
+
+// WithdrawalsQuery returns the query for the logs of withdrawals from the
+// chequebooks.
+func WithdrawalsQuery(chequebooks []common.Address) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: chequebooks,
+		Topics:    [][]common.Hash{{withdrawEventType.ID}},
+	}
+}
+
+// ParseWithdrawals returns the withdrawals of the logs matching the
+// WithdrawalsQuery in the order they happened.
+func ParseWithdrawals(logs []types.Log) ([]Withdrawal, error) {
+	var withdrawals []Withdrawal
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		var event withdrawEvent
+		if err := transaction.ParseEvent(&chequebookABI, withdrawEventName, &event, log); err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, Withdrawal{
+			Chequebook:  log.Address,
+			TxHash:      log.TxHash,
+			BlockNumber: log.BlockNumber,
+			LogIndex:    log.Index,
+			Amount:      event.Amount,
+		})
+	}
+	return withdrawals, nil
+}
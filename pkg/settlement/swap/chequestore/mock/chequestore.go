@@ -6,7 +6,9 @@ package mock
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
@@ -17,6 +19,14 @@ type Service struct {
 	receiveCheque func(ctx context.Context, cheque *chequebook.SignedCheque, exchangeRate *big.Int, deduction *big.Int) (*big.Int, error)
 	lastCheque    func(chequebook common.Address) (*chequebook.SignedCheque, error)
 	lastCheques   func() (map[common.Address]*chequebook.SignedCheque, error)
+
+	lastBeneficiaryCheques func(chequebook common.Address) (map[common.Address]*chequebook.SignedCheque, error)
+	verifyIssuer           func(ctx context.Context, chequebook, issuer common.Address) error
+	importCheque           func(ctx context.Context, cheque *chequebook.SignedCheque) error
+	importCheques          func(ctx context.Context, cheques []*chequebook.SignedCheque) []error
+	rotate                 func(beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, error)
+	beneficiaries          func() (*chequebook.BeneficiaryRotation, error)
+	verifyCheque           func(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error
 }
 
 func WithReceiveChequeFunc(f func(ctx context.Context, cheque *chequebook.SignedCheque, exchangeRate *big.Int, deduction *big.Int) (*big.Int, error)) Option {
@@ -37,6 +47,48 @@ func WithLastChequesFunc(f func() (map[common.Address]*chequebook.SignedCheque,
 	})
 }
 
+func WithLastBeneficiaryChequesFunc(f func(chequebook common.Address) (map[common.Address]*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastBeneficiaryCheques = f
+	})
+}
+
+func WithVerifyChequebookIssuerFunc(f func(ctx context.Context, chequebook, issuer common.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.verifyIssuer = f
+	})
+}
+
+func WithImportChequeFunc(f func(ctx context.Context, cheque *chequebook.SignedCheque) error) Option {
+	return optionFunc(func(s *Service) {
+		s.importCheque = f
+	})
+}
+
+func WithImportChequesFunc(f func(ctx context.Context, cheques []*chequebook.SignedCheque) []error) Option {
+	return optionFunc(func(s *Service) {
+		s.importCheques = f
+	})
+}
+
+func WithRotateBeneficiaryFunc(f func(beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.rotate = f
+	})
+}
+
+func WithBeneficiariesFunc(f func() (*chequebook.BeneficiaryRotation, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.beneficiaries = f
+	})
+}
+
+func WithVerifyChequeFunc(f func(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error) Option {
+	return optionFunc(func(s *Service) {
+		s.verifyCheque = f
+	})
+}
+
 // NewChequeStore creates the mock chequeStore implementation
 func NewChequeStore(opts ...Option) chequebook.ChequeStore {
 	mock := new(Service)
@@ -54,10 +106,90 @@ func (s *Service) LastCheque(chequebook common.Address) (*chequebook.SignedChequ
 	return s.lastCheque(chequebook)
 }
 
+// LastBeneficiaryCheques returns the cheque of the chequebook in LastCheques,
+// or else the one of LastCheque, unless a function was configured.
+func (s *Service) LastBeneficiaryCheques(chequebookAddress common.Address) (map[common.Address]*chequebook.SignedCheque, error) {
+	if s.lastBeneficiaryCheques != nil {
+		return s.lastBeneficiaryCheques(chequebookAddress)
+	}
+
+	cheques := make(map[common.Address]*chequebook.SignedCheque)
+	if s.lastCheques != nil {
+		lastCheques, err := s.lastCheques()
+		if err != nil {
+			return nil, err
+		}
+		if cheque, ok := lastCheques[chequebookAddress]; ok {
+			cheques[cheque.Beneficiary] = cheque
+		}
+		return cheques, nil
+	}
+
+	cheque, err := s.LastCheque(chequebookAddress)
+	if errors.Is(err, chequebook.ErrNoCheque) {
+		return cheques, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cheques[cheque.Beneficiary] = cheque
+	return cheques, nil
+}
+
 func (s *Service) LastCheques() (map[common.Address]*chequebook.SignedCheque, error) {
 	return s.lastCheques()
 }
 
+func (s *Service) VerifyChequebookIssuer(ctx context.Context, chequebook, issuer common.Address) error {
+	if s.verifyIssuer != nil {
+		return s.verifyIssuer(ctx, chequebook, issuer)
+	}
+	return nil
+}
+
+func (s *Service) ImportCheque(ctx context.Context, cheque *chequebook.SignedCheque) error {
+	if s.importCheque != nil {
+		return s.importCheque(ctx, cheque)
+	}
+	return nil
+}
+
+func (s *Service) ImportCheques(ctx context.Context, cheques []*chequebook.SignedCheque) []error {
+	if s.importCheques != nil {
+		return s.importCheques(ctx, cheques)
+	}
+	errs := make([]error, len(cheques))
+	for i, cheque := range cheques {
+		errs[i] = s.ImportCheque(ctx, cheque)
+	}
+	return errs
+}
+
+func (s *Service) RotateBeneficiary(beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, error) {
+	if s.rotate != nil {
+		return s.rotate(beneficiary, grace)
+	}
+	return &chequebook.BeneficiaryRotation{Current: beneficiary}, nil
+}
+
+func (s *Service) Beneficiaries() (*chequebook.BeneficiaryRotation, error) {
+	if s.beneficiaries != nil {
+		return s.beneficiaries()
+	}
+	return &chequebook.BeneficiaryRotation{}, nil
+}
+
+func (s *Service) VerifyCheque(ctx context.Context, cheque *chequebook.SignedCheque, lastCumulativePayout *big.Int) error {
+	if s.verifyCheque != nil {
+		return s.verifyCheque(ctx, cheque, lastCumulativePayout)
+	}
+	return nil
+}
+
+func (s *Service) DelegateVerification(verifier chequebook.ChequeVerifier) {}
+
+func (s *Service) SetReputation(reputation *chequebook.Reputation) {}
+
 // Option is the option passed to the mock ChequeStore service
 type Option interface {
 	apply(*Service)
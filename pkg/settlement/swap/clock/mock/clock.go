@@ -0,0 +1,126 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+)
+
+// Clock is a clock which only advances when told to. Waits and tickers fire
+// once the clock is advanced past their time.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	at       time.Time
+	interval time.Duration // zero for waits firing once
+	c        chan time.Time
+}
+
+var _ clock.Clock = (*Clock)(nil)
+
+// New creates a Clock starting at now.
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &ticker{clock: c, waiter: c.add(d, d)}
+}
+
+// Waiters returns the number of waits and tickers which have not fired or
+// were not stopped, so that tests can advance the clock once a component
+// waits for it.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// Advance moves the clock forward by d and fires the waits and tickers due.
+// A ticker fires at most once per call, like a ticker dropping ticks of a
+// slow receiver.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if c.now.Before(w.at) {
+			waiters = append(waiters, w)
+			continue
+		}
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		if w.interval > 0 {
+			for !c.now.Before(w.at) {
+				w.at = w.at.Add(w.interval)
+			}
+			waiters = append(waiters, w)
+		}
+	}
+	c.waiters = waiters
+}
+
+func (c *Clock) add(d, interval time.Duration) *waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &waiter{at: c.now.Add(d), interval: interval, c: make(chan time.Time, 1)}
+	if d <= 0 && interval == 0 {
+		w.c <- c.now
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (c *Clock) remove(w *waiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, v := range c.waiters {
+		if v == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type ticker struct {
+	clock  *Clock
+	waiter *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *ticker) Stop() {
+	t.clock.remove(t.waiter)
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// DisconnectReason describes why a peer should be disconnected because of unsettled debt.
+type DisconnectReason int
+
+const (
+	// DisconnectChequeBounced is used when cashing a cheque received from the peer bounced.
+	DisconnectChequeBounced DisconnectReason = iota + 1
+	// DisconnectThresholdExceeded is used when the peer exceeded the disconnect threshold without settling.
+	DisconnectThresholdExceeded
+	// DisconnectGracePeriodExceeded is used when the debt of the peer stayed above the payment threshold for too long.
+	DisconnectGracePeriodExceeded
+)
+
+func (r DisconnectReason) String() string {
+	switch r {
+	case DisconnectChequeBounced:
+		return "cheque bounced"
+	case DisconnectThresholdExceeded:
+		return "disconnect threshold exceeded"
+	case DisconnectGracePeriodExceeded:
+		return "payment grace period exceeded"
+	default:
+		return fmt.Sprintf("unknown reason %d", int(r))
+	}
+}
+
+// DisconnectNotification is emitted when a peer should be disconnected because it does not settle its debt.
+type DisconnectNotification struct {
+	Peer       swarm.Address
+	Reason     DisconnectReason
+	Chequebook common.Address // chequebook of the peer, zero if unknown
+	TxHash     common.Hash    // cashout transaction in which the cheque bounced, if any
+	Debt       *big.Int       // debt of the peer at the time of the notification, nil if unknown
+	// BlocklistDuration is how long the peer should be blocklisted after the
+	// disconnect. Zero means the peer should only be disconnected.
+	BlocklistDuration time.Duration
+}
+
+// DisconnectNotifier is implemented by the component of the p2p or topology
+// layer that acts upon debt based disconnect notifications.
+type DisconnectNotifier interface {
+	NotifyDisconnect(notification DisconnectNotification) error
+}
+
+// DisconnectNotifierFunc is an adapter to allow the use of ordinary functions as DisconnectNotifier.
+type DisconnectNotifierFunc func(notification DisconnectNotification) error
+
+// NotifyDisconnect calls f(notification).
+func (f DisconnectNotifierFunc) NotifyDisconnect(notification DisconnectNotification) error {
+	return f(notification)
+}
+
+type blocklistNotifier struct {
+	disconnecter p2p.Disconnecter
+}
+
+// NewBlocklistNotifier returns a DisconnectNotifier which disconnects the peer
+// and blocklists it for the duration specified in the notification.
+func NewBlocklistNotifier(disconnecter p2p.Disconnecter) DisconnectNotifier {
+	return &blocklistNotifier{disconnecter: disconnecter}
+}
+
+func (n *blocklistNotifier) NotifyDisconnect(notification DisconnectNotification) error {
+	reason := fmt.Sprintf("swap: %s", notification.Reason)
+	if notification.BlocklistDuration <= 0 {
+		return n.disconnecter.Disconnect(notification.Peer, reason)
+	}
+	return n.disconnecter.Blocklist(notification.Peer, notification.BlocklistDuration, reason)
+}
+
+// SetDisconnectNotifier registers the notifier which is informed about peers
+// that should be disconnected and blocklisted for blocklistDuration.
+func (s *Service) SetDisconnectNotifier(notifier DisconnectNotifier, blocklistDuration time.Duration) {
+	s.disconnectMu.Lock()
+	defer s.disconnectMu.Unlock()
+	s.disconnectNotifier = notifier
+	s.blocklistDuration = blocklistDuration
+}
+
+// NotifyDisconnectThresholdExceeded emits a disconnect notification for a
+// peer which exceeded the disconnect threshold without settling.
+func (s *Service) NotifyDisconnectThresholdExceeded(peer swarm.Address, debt *big.Int) error {
+	chequebookAddress, _, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return err
+	}
+	return s.notifyDisconnect(DisconnectNotification{
+		Peer:       peer,
+		Reason:     DisconnectThresholdExceeded,
+		Chequebook: chequebookAddress,
+		Debt:       debt,
+	})
+}
+
+// notifyBounced records the bounced cashout and emits a disconnect
+// notification for the peer. Every transaction is only reported once.
+func (s *Service) notifyBounced(peer swarm.Address, chequebookAddress common.Address, last *chequebook.LastCashout) error {
+	txHash := last.TxHash
+	s.disconnectMu.Lock()
+	if _, ok := s.bouncedNotified[txHash]; ok {
+		s.disconnectMu.Unlock()
+		return nil
+	}
+	s.bouncedNotified[txHash] = struct{}{}
+	s.recordBounce(Bounce{
+		Peer:       peer,
+		Chequebook: chequebookAddress,
+		TxHash:     txHash,
+		Amount:     last.Cheque.CumulativePayout,
+		Time:       s.clock.Now(),
+	})
+	s.disconnectMu.Unlock()
+
+	s.publish(events.Event{
+		Type:       events.TypeChequeBounced,
+		Peer:       peer,
+		Chequebook: chequebookAddress,
+		Amount:     last.Cheque.CumulativePayout,
+		TxHash:     txHash,
+	})
+
+	var debt *big.Int
+	if s.accounting != nil {
+		if d, err := s.accounting.PeerDebt(peer); err == nil {
+			debt = d
+		}
+	}
+
+	return s.notifyDisconnect(DisconnectNotification{
+		Peer:       peer,
+		Reason:     DisconnectChequeBounced,
+		Chequebook: chequebookAddress,
+		TxHash:     txHash,
+		Debt:       debt,
+	})
+}
+
+func (s *Service) notifyDisconnect(notification DisconnectNotification) error {
+	s.disconnectMu.Lock()
+	notifier := s.disconnectNotifier
+	notification.BlocklistDuration = s.blocklistDuration
+	s.disconnectMu.Unlock()
+
+	if notifier == nil {
+		return nil
+	}
+
+	s.logger.Debug("disconnecting peer", "peer_address", notification.Peer, "reason", notification.Reason, "blocklist_duration", notification.BlocklistDuration)
+	s.metrics.DisconnectNotifications.Inc()
+
+	return notifier.NotifyDisconnect(notification)
+}
@@ -0,0 +1,309 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// disputePrefix is the prefix of the key under which a dispute is stored.
+	disputePrefix = "swap_dispute_"
+	// disputeSeqKey is the key under which the id of the last opened dispute is stored.
+	disputeSeqKey = "swap_dispute_seq"
+)
+
+var (
+	// ErrDisputeNotFound is the error returned if there is no dispute with the given id.
+	ErrDisputeNotFound = errors.New("dispute not found")
+	// ErrDisputeResolved is the error returned if a dispute is resolved again.
+	ErrDisputeResolved = errors.New("dispute already resolved")
+	// ErrInvalidDisputeDirection is the error returned for an unknown dispute direction.
+	ErrInvalidDisputeDirection = errors.New("invalid dispute direction")
+	// ErrInvalidResolution is the error returned if the resolution does not apply to the dispute.
+	ErrInvalidResolution = errors.New("invalid dispute resolution")
+	// ErrChequeNotSigned is the error returned if our last cheque is resent
+	// but was stored without its signature by an earlier version of the node.
+	ErrChequeNotSigned = errors.New("last cheque not signed")
+)
+
+// DisputeDirection tells which cheques are disputed.
+type DisputeDirection string
+
+const (
+	// DisputeSent disputes the cheques we sent to the peer.
+	DisputeSent DisputeDirection = "sent"
+	// DisputeReceived disputes the cheques the peer sent to us.
+	DisputeReceived DisputeDirection = "received"
+)
+
+// DisputeStatus is the status of a dispute.
+type DisputeStatus string
+
+const (
+	DisputeOpen     DisputeStatus = "open"
+	DisputeResolved DisputeStatus = "resolved"
+)
+
+// DisputeResolution is how a dispute is resolved.
+type DisputeResolution string
+
+const (
+	// ResolutionResend sends our last cheque to the peer again. It only applies
+	// to disputes about sent cheques.
+	ResolutionResend DisputeResolution = "resend"
+	// ResolutionAdjust adopts the cumulative payout confirmed by the peer as
+	// the payout of our last cheque, so that the next cheque covers it. It only
+	// applies to disputes about sent cheques the peer received more of than we
+	// know of.
+	ResolutionAdjust DisputeResolution = "adjust"
+	// ResolutionDismiss closes the dispute without changes.
+	ResolutionDismiss DisputeResolution = "dismiss"
+)
+
+// DisputeEntry is an entry of the trail of a dispute.
+type DisputeEntry struct {
+	Time   time.Time
+	Action string
+	Note   string
+}
+
+// Dispute records a disagreement with a peer about the cheques exchanged with
+// it together with the evidence of both sides.
+type Dispute struct {
+	ID          uint64
+	Peer        swarm.Address
+	Direction   DisputeDirection
+	Status      DisputeStatus
+	Resolution  DisputeResolution
+	LocalPayout *big.Int                 // cumulative payout of our last cheque in the direction
+	PeerPayout  *big.Int                 // cumulative payout according to the peer
+	Cheque      *chequebook.SignedCheque // our last cheque in the direction, if any
+	Receipt     *chequebook.Receipt      // receipt of the peer for the last cheque it received from us, if any
+	Statement   *chequebook.Statement    // statement of the peer about the cheques it sent to us
+	Trail       []DisputeEntry
+}
+
+// Matches reports whether both sides agree on the cumulative payout.
+func (d *Dispute) Matches() bool {
+	return d.LocalPayout != nil && d.PeerPayout != nil && d.LocalPayout.Cmp(d.PeerPayout) == 0
+}
+
+func disputeKey(id uint64) string {
+	return fmt.Sprintf("%s%020d", disputePrefix, id)
+}
+
+func (d *Dispute) record(now time.Time, action, note string) {
+	d.Trail = append(d.Trail, DisputeEntry{Time: now, Action: action, Note: note})
+}
+
+// OpenDispute opens a dispute about the cheques sent to or received from the
+// peer. The cumulative payouts of both sides are compared by requesting a
+// receipt for the last cheque we sent or a statement about the cheques the
+// peer sent from the peer.
+func (s *Service) OpenDispute(ctx context.Context, peer swarm.Address, direction DisputeDirection, note string) (*Dispute, error) {
+	d := &Dispute{
+		Peer:      peer,
+		Direction: direction,
+		Status:    DisputeOpen,
+	}
+
+	switch direction {
+	case DisputeSent:
+		if err := s.compareSent(ctx, d); err != nil {
+			return nil, err
+		}
+	case DisputeReceived:
+		check, err := s.PeerStatement(ctx, peer)
+		if err != nil {
+			return nil, err
+		}
+		cheque, err := s.LastReceivedCheque(peer)
+		if err != nil && !errors.Is(err, chequebook.ErrNoCheque) {
+			return nil, err
+		}
+		d.Cheque = cheque
+		d.Statement = check.Statement
+		d.LocalPayout = check.CumulativePayout
+		d.PeerPayout = check.Statement.CumulativePayout
+	default:
+		return nil, ErrInvalidDisputeDirection
+	}
+
+	s.disputesMu.Lock()
+	defer s.disputesMu.Unlock()
+
+	var id uint64
+	if err := s.store.Get(disputeSeqKey, &id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	d.ID = id + 1
+	d.record(s.clock.Now(), "opened", note)
+	d.record(s.clock.Now(), "compared", fmt.Sprintf("local cumulative payout %d, peer cumulative payout %d", d.LocalPayout, d.PeerPayout))
+
+	if err := s.store.Put(disputeKey(d.ID), d); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(disputeSeqKey, d.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("settlement dispute opened", "id", d.ID, "peer_address", peer, "direction", direction, "local_payout", d.LocalPayout, "peer_payout", d.PeerPayout)
+	return d, nil
+}
+
+// compareSent sets our last cheque sent to the peer and the receipt of the
+// peer for the last cheque it received from us.
+func (s *Service) compareSent(ctx context.Context, d *Dispute) error {
+	if s.chequebook == nil {
+		return ErrNoChequebook
+	}
+	cheque, err := s.LastSentCheque(d.Peer)
+	if err != nil && !errors.Is(err, chequebook.ErrNoCheque) {
+		return err
+	}
+	receipt, err := s.proto.RequestReceipt(ctx, d.Peer)
+	if err != nil {
+		return err
+	}
+	if receipt != nil {
+		if receipt.Chequebook != s.chequebook.Address() {
+			return chequebook.ErrReceiptInvalid
+		}
+		if cheque != nil && receipt.Beneficiary != cheque.Beneficiary {
+			return chequebook.ErrReceiptInvalid
+		}
+	}
+
+	d.Cheque = cheque
+	d.Receipt = receipt
+	d.LocalPayout = big.NewInt(0)
+	if cheque != nil {
+		d.LocalPayout = cheque.CumulativePayout
+	}
+	d.PeerPayout = big.NewInt(0)
+	if receipt != nil {
+		d.PeerPayout = receipt.CumulativePayout
+	}
+	return nil
+}
+
+// Dispute returns the dispute with the given id.
+func (s *Service) Dispute(id uint64) (*Dispute, error) {
+	var d Dispute
+	if err := s.store.Get(disputeKey(id), &d); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrDisputeNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Disputes returns all disputes, the most recently opened first.
+func (s *Service) Disputes() ([]Dispute, error) {
+	var disputes []Dispute
+	err := s.store.Iterate(disputePrefix, func(key, value []byte) (bool, error) {
+		if string(key) == disputeSeqKey {
+			return false, nil
+		}
+		var d Dispute
+		if err := json.Unmarshal(value, &d); err != nil {
+			return true, fmt.Errorf("dispute %s: %w", key, err)
+		}
+		disputes = append(disputes, d)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(disputes, func(i, j int) bool {
+		return disputes[i].ID > disputes[j].ID
+	})
+	return disputes, nil
+}
+
+// ResolveDispute resolves the open dispute. Resending our last cheque is
+// followed by comparing the payouts again. Adjusting our last cheque is
+// recorded in the audit log of the chequebook if there is one.
+func (s *Service) ResolveDispute(ctx context.Context, id uint64, resolution DisputeResolution, note string) (*Dispute, error) {
+	s.disputesMu.Lock()
+	defer s.disputesMu.Unlock()
+
+	d, err := s.Dispute(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == DisputeResolved {
+		return nil, ErrDisputeResolved
+	}
+
+	switch resolution {
+	case ResolutionResend:
+		if d.Direction != DisputeSent || d.Cheque == nil {
+			return nil, ErrInvalidResolution
+		}
+		if err := s.resendCheque(ctx, d.Peer, d.Cheque); err != nil {
+			return nil, err
+		}
+		d.record(s.clock.Now(), "resent", fmt.Sprintf("cheque with cumulative payout %d", d.Cheque.CumulativePayout))
+		if err := s.compareSent(ctx, d); err != nil {
+			return nil, err
+		}
+		d.record(s.clock.Now(), "compared", fmt.Sprintf("local cumulative payout %d, peer cumulative payout %d", d.LocalPayout, d.PeerPayout))
+	case ResolutionAdjust:
+		if d.Direction != DisputeSent || d.Receipt == nil || d.PeerPayout.Cmp(d.LocalPayout) <= 0 {
+			return nil, ErrInvalidResolution
+		}
+		peerPayout, err := chequebook.NewTokens(d.PeerPayout)
+		if err != nil {
+			return nil, err
+		}
+		cheque, err := s.chequebook.ImportLastCheque(ctx, d.Receipt.Beneficiary, peerPayout)
+		if err != nil {
+			return nil, err
+		}
+		d.Cheque = cheque
+		d.LocalPayout = cheque.CumulativePayout
+		d.record(s.clock.Now(), "adjusted", fmt.Sprintf("cumulative payout of last cheque set to %d", d.PeerPayout))
+	case ResolutionDismiss:
+	default:
+		return nil, ErrInvalidResolution
+	}
+
+	d.Status = DisputeResolved
+	d.Resolution = resolution
+	d.record(s.clock.Now(), "resolved", note)
+	if err := s.store.Put(disputeKey(d.ID), d); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("settlement dispute resolved", "id", d.ID, "peer_address", d.Peer, "resolution", resolution, "matches", d.Matches())
+	return d, nil
+}
+
+// resendCheque sends the already issued cheque to the peer again. A peer
+// which already has it acknowledges it again without crediting it twice.
+// The peer would reject a cheque without signature, so it is not sent.
+func (s *Service) resendCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque) error {
+	if len(cheque.Signature) == 0 {
+		return ErrChequeNotSigned
+	}
+	_, err := s.proto.EmitCheque(ctx, peer, cheque.Beneficiary, big.NewInt(0), func(ctx context.Context, _ common.Address, _ chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+		return nil, sendChequeFunc(cheque)
+	})
+	return err
+}
@@ -0,0 +1,353 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/swapprotocol"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+func newDisputeCheque(chequebookAddress, beneficiary common.Address, payout int64) chequebook.Cheque {
+	return chequebook.Cheque{
+		Chequebook:       chequebookAddress,
+		Beneficiary:      beneficiary,
+		CumulativePayout: big.NewInt(payout),
+	}
+}
+
+func TestDisputeResend(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+	lastCheque := &chequebook.SignedCheque{Cheque: newDisputeCheque(chequebookAddress, beneficiary, 500), Signature: []byte{1}}
+
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	received := big.NewInt(300)
+	var resent *chequebook.SignedCheque
+	proto := &swapProtocolMock{
+		requestReceipt: func(_ context.Context, p swarm.Address) (*chequebook.Receipt, error) {
+			return &chequebook.Receipt{Cheque: newDisputeCheque(chequebookAddress, beneficiary, received.Int64())}, nil
+		},
+		emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, amount *big.Int, issue swapprotocol.IssueFunc) (*big.Int, error) {
+			return issue(ctx, b, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
+				resent = cheque
+				received = cheque.CumulativePayout
+				return nil
+			})
+		},
+	}
+
+	swapService := swap.New(
+		proto,
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(
+			mockchequebook.WithChequebookAddressFunc(func() common.Address { return chequebookAddress }),
+			mockchequebook.WithLastChequeFunc(func(common.Address) (*chequebook.SignedCheque, error) { return lastCheque, nil }),
+		),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		0,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	if _, err := swapService.OpenDispute(context.Background(), peer, "sideways", ""); !errors.Is(err, swap.ErrInvalidDisputeDirection) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrInvalidDisputeDirection)
+	}
+
+	dispute, err := swapService.OpenDispute(context.Background(), peer, swap.DisputeSent, "cheque lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dispute.ID != 1 || dispute.Status != swap.DisputeOpen || dispute.Matches() {
+		t.Fatalf("unexpected dispute %+v", dispute)
+	}
+	if dispute.LocalPayout.Cmp(big.NewInt(500)) != 0 || dispute.PeerPayout.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("got local payout %v and peer payout %v, want 500 and 300", dispute.LocalPayout, dispute.PeerPayout)
+	}
+
+	if _, err := swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionAdjust, ""); !errors.Is(err, swap.ErrInvalidResolution) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrInvalidResolution)
+	}
+
+	dispute, err = swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionResend, "resent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resent == nil || !resent.Equal(lastCheque) {
+		t.Fatalf("resent cheque %v, want %v", resent, lastCheque)
+	}
+	if dispute.Status != swap.DisputeResolved || dispute.Resolution != swap.ResolutionResend || !dispute.Matches() {
+		t.Fatalf("unexpected resolved dispute %+v", dispute)
+	}
+
+	var actions []string
+	for _, e := range dispute.Trail {
+		actions = append(actions, e.Action)
+	}
+	if want := []string{"opened", "compared", "resent", "compared", "resolved"}; !reflect.DeepEqual(actions, want) {
+		t.Fatalf("got trail %v, want %v", actions, want)
+	}
+
+	if _, err := swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionDismiss, ""); !errors.Is(err, swap.ErrDisputeResolved) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrDisputeResolved)
+	}
+	if _, err := swapService.ResolveDispute(context.Background(), 2, swap.ResolutionDismiss, ""); !errors.Is(err, swap.ErrDisputeNotFound) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrDisputeNotFound)
+	}
+
+	disputes, err := swapService.Disputes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disputes) != 1 || disputes[0].Status != swap.DisputeResolved {
+		t.Fatalf("unexpected disputes %+v", disputes)
+	}
+}
+
+func TestDisputeAdjust(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	var imported *big.Int
+	swapService := swap.New(
+		&swapProtocolMock{
+			requestReceipt: func(context.Context, swarm.Address) (*chequebook.Receipt, error) {
+				return &chequebook.Receipt{Cheque: newDisputeCheque(chequebookAddress, beneficiary, 800)}, nil
+			},
+		},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(
+			mockchequebook.WithChequebookAddressFunc(func() common.Address { return chequebookAddress }),
+			mockchequebook.WithLastChequeFunc(func(common.Address) (*chequebook.SignedCheque, error) {
+				return &chequebook.SignedCheque{Cheque: newDisputeCheque(chequebookAddress, beneficiary, 500)}, nil
+			}),
+			mockchequebook.WithImportLastChequeFunc(func(_ context.Context, b common.Address, cumulativePayout *big.Int) (*chequebook.SignedCheque, error) {
+				imported = cumulativePayout
+				return &chequebook.SignedCheque{Cheque: newDisputeCheque(chequebookAddress, b, cumulativePayout.Int64())}, nil
+			}),
+		),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		0,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	dispute, err := swapService.OpenDispute(context.Background(), peer, swap.DisputeSent, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispute, err = swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionAdjust, "restored from backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported == nil || imported.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("imported cumulative payout %v, want 800", imported)
+	}
+	if !dispute.Matches() || dispute.Resolution != swap.ResolutionAdjust {
+		t.Fatalf("unexpected resolved dispute %+v", dispute)
+	}
+
+	got, err := swapService.Dispute(dispute.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != swap.DisputeResolved || got.LocalPayout.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("unexpected stored dispute %+v", got)
+	}
+}
+
+func TestDisputeResendUnsigned(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{
+			requestReceipt: func(context.Context, swarm.Address) (*chequebook.Receipt, error) {
+				return &chequebook.Receipt{Cheque: newDisputeCheque(chequebookAddress, beneficiary, 300)}, nil
+			},
+			emitCheque: func(context.Context, swarm.Address, common.Address, *big.Int, swapprotocol.IssueFunc) (*big.Int, error) {
+				t.Fatal("unsigned cheque sent")
+				return nil, nil
+			},
+		},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(
+			mockchequebook.WithChequebookAddressFunc(func() common.Address { return chequebookAddress }),
+			// stored by an earlier version of the node without its signature
+			mockchequebook.WithLastChequeFunc(func(common.Address) (*chequebook.SignedCheque, error) {
+				return &chequebook.SignedCheque{Cheque: newDisputeCheque(chequebookAddress, beneficiary, 500)}, nil
+			}),
+		),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		0,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	dispute, err := swapService.OpenDispute(context.Background(), peer, swap.DisputeSent, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionResend, ""); !errors.Is(err, swap.ErrChequeNotSigned) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrChequeNotSigned)
+	}
+}
+
+// TestDisputeResendAfterImport checks that a last cheque adjusted to the
+// payout confirmed by the peer can be resent if the peer loses it later.
+func TestDisputeResendAfterImport(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+	chainID := int64(1)
+	chequebookABI := abiutil.MustParseABI(sw3abi.ERC20SimpleSwapABIv0_3_1)
+
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chequebookService, err := chequebook.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&chequebookABI, chequebookAddress, big.NewInt(0).FillBytes(make([]byte, 32)), "paidOut", beneficiary),
+			),
+		),
+		chequebookAddress,
+		issuer,
+		store,
+		chequebook.NewChequeSigner(signer, chainID),
+		erc20mock.New(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := big.NewInt(800)
+	var resent *chequebook.SignedCheque
+	swapService := swap.New(
+		&swapProtocolMock{
+			requestReceipt: func(context.Context, swarm.Address) (*chequebook.Receipt, error) {
+				return &chequebook.Receipt{Cheque: newDisputeCheque(chequebookAddress, beneficiary, received.Int64())}, nil
+			},
+			emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, amount *big.Int, issue swapprotocol.IssueFunc) (*big.Int, error) {
+				return issue(ctx, b, chequebook.MustNewTokens(amount), func(cheque *chequebook.SignedCheque) error {
+					resent = cheque
+					received = cheque.CumulativePayout
+					return nil
+				})
+			},
+		},
+		log.Noop,
+		store,
+		chequebookService,
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		0,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	dispute, err := swapService.OpenDispute(context.Background(), peer, swap.DisputeSent, "restored from backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionAdjust, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the peer loses the cheque matching the imported payout
+	received = big.NewInt(300)
+	dispute, err = swapService.OpenDispute(context.Background(), peer, swap.DisputeSent, "cheque lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispute, err = swapService.ResolveDispute(context.Background(), dispute.ID, swap.ResolutionResend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resent == nil || resent.CumulativePayout.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("resent cheque %v, want cumulative payout 800", resent)
+	}
+	if !dispute.Matches() {
+		t.Fatalf("unexpected resolved dispute %+v", dispute)
+	}
+
+	recovered, err := chequebook.RecoverCheque(resent, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != issuer {
+		t.Fatalf("resent cheque signed by %x, want %x", recovered, issuer)
+	}
+}
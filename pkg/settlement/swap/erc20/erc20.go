@@ -25,6 +25,11 @@ var (
 type Service interface {
 	BalanceOf(ctx context.Context, address common.Address) (*big.Int, error)
 	Transfer(ctx context.Context, address common.Address, value *big.Int) (common.Hash, error)
+	Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error)
+	Approve(ctx context.Context, spender common.Address, value *big.Int) (common.Hash, error)
+	Name(ctx context.Context) (string, error)
+	Symbol(ctx context.Context) (string, error)
+	Decimals(ctx context.Context) (uint8, error)
 }
 
 type erc20Service struct {
@@ -91,3 +96,117 @@ func (c *erc20Service) Transfer(ctx context.Context, address common.Address, val
 
 	return txHash, nil
 }
+
+func (c *erc20Service) Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error) {
+	callData, err := erc20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &c.address,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := erc20ABI.Unpack("allowance", output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) != 1 {
+		return nil, errDecodeABI
+	}
+
+	allowance, ok := abi.ConvertType(results[0], new(big.Int)).(*big.Int)
+	if !ok || allowance == nil {
+		return nil, errDecodeABI
+	}
+	return allowance, nil
+}
+
+func (c *erc20Service) Approve(ctx context.Context, spender common.Address, value *big.Int) (common.Hash, error) {
+	callData, err := erc20ABI.Pack("approve", spender, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	request := &transaction.TxRequest{
+		To:          &c.address,
+		Data:        callData,
+		GasPrice:    sctx.GetGasPrice(ctx),
+		GasLimit:    65000,
+		Value:       big.NewInt(0),
+		Description: "token approval",
+	}
+
+	txHash, err := c.transactionService.Send(ctx, request, transaction.DefaultTipBoostPercent)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return txHash, nil
+}
+
+func (c *erc20Service) Name(ctx context.Context) (string, error) {
+	return c.stringCall(ctx, "name")
+}
+
+func (c *erc20Service) Symbol(ctx context.Context) (string, error) {
+	return c.stringCall(ctx, "symbol")
+}
+
+func (c *erc20Service) Decimals(ctx context.Context) (uint8, error) {
+	results, err := c.call(ctx, "decimals")
+	if err != nil {
+		return 0, err
+	}
+
+	decimals, ok := abi.ConvertType(results[0], new(uint8)).(*uint8)
+	if !ok || decimals == nil {
+		return 0, errDecodeABI
+	}
+	return *decimals, nil
+}
+
+// stringCall calls a parameterless token method returning a single string.
+func (c *erc20Service) stringCall(ctx context.Context, method string) (string, error) {
+	results, err := c.call(ctx, method)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := abi.ConvertType(results[0], new(string)).(*string)
+	if !ok || value == nil {
+		return "", errDecodeABI
+	}
+	return *value, nil
+}
+
+// call calls a parameterless token method returning a single value.
+func (c *erc20Service) call(ctx context.Context, method string) ([]interface{}, error) {
+	callData, err := erc20ABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &c.address,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := erc20ABI.Unpack(method, output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) != 1 {
+		return nil, errDecodeABI
+	}
+	return results, nil
+}
@@ -74,3 +74,114 @@ func TestTransfer(t *testing.T) {
 		t.Fatalf("returned wrong transaction hash. wanted %v, got %v", txHash, returnedTxHash)
 	}
 }
+
+func TestAllowance(t *testing.T) {
+	t.Parallel()
+
+	erc20Address := common.HexToAddress("00")
+	owner := common.HexToAddress("01")
+	spender := common.HexToAddress("02")
+	expectedAllowance := big.NewInt(100)
+
+	erc20 := erc20.New(
+		transactionmock.New(
+			transactionmock.WithABICall(
+				&erc20ABI,
+				erc20Address,
+				expectedAllowance.FillBytes(make([]byte, 32)),
+				"allowance",
+				owner,
+				spender,
+			),
+		),
+		erc20Address,
+	)
+
+	allowance, err := erc20.Allowance(context.Background(), owner, spender)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expectedAllowance.Cmp(allowance) != 0 {
+		t.Fatalf("got wrong allowance. wanted %d, got %d", expectedAllowance, allowance)
+	}
+}
+
+func TestApprove(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0xabcd")
+	spender := common.HexToAddress("01")
+	value := big.NewInt(20)
+	txHash := common.HexToHash("0xdddd")
+
+	erc20 := erc20.New(
+		transactionmock.New(
+			transactionmock.WithABISend(&erc20ABI, txHash, address, big.NewInt(0), "approve", spender, value),
+		),
+		address,
+	)
+
+	returnedTxHash, err := erc20.Approve(context.Background(), spender, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if txHash != returnedTxHash {
+		t.Fatalf("returned wrong transaction hash. wanted %v, got %v", txHash, returnedTxHash)
+	}
+}
+
+func TestTokenMetadata(t *testing.T) {
+	t.Parallel()
+
+	erc20Address := common.HexToAddress("00")
+
+	name, err := erc20ABI.Methods["name"].Outputs.Pack("Swarm Token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbol, err := erc20ABI.Methods["symbol"].Outputs.Pack("BZZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decimals, err := erc20ABI.Methods["decimals"].Outputs.Pack(uint8(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	erc20 := erc20.New(
+		transactionmock.New(
+			transactionmock.WithABICallSequence(
+				transactionmock.ABICall(&erc20ABI, erc20Address, name, "name"),
+				transactionmock.ABICall(&erc20ABI, erc20Address, symbol, "symbol"),
+				transactionmock.ABICall(&erc20ABI, erc20Address, decimals, "decimals"),
+			),
+		),
+		erc20Address,
+	)
+
+	gotName, err := erc20.Name(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotName != "Swarm Token" {
+		t.Fatalf("got wrong name. wanted %q, got %q", "Swarm Token", gotName)
+	}
+
+	gotSymbol, err := erc20.Symbol(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSymbol != "BZZ" {
+		t.Fatalf("got wrong symbol. wanted %q, got %q", "BZZ", gotSymbol)
+	}
+
+	gotDecimals, err := erc20.Decimals(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDecimals != 16 {
+		t.Fatalf("got wrong decimals. wanted %d, got %d", 16, gotDecimals)
+	}
+}
@@ -16,6 +16,11 @@ import (
 type Service struct {
 	balanceOfFunc func(ctx context.Context, address common.Address) (*big.Int, error)
 	transferFunc  func(ctx context.Context, address common.Address, value *big.Int) (common.Hash, error)
+	allowanceFunc func(ctx context.Context, owner, spender common.Address) (*big.Int, error)
+	approveFunc   func(ctx context.Context, spender common.Address, value *big.Int) (common.Hash, error)
+	nameFunc      func(ctx context.Context) (string, error)
+	symbolFunc    func(ctx context.Context) (string, error)
+	decimalsFunc  func(ctx context.Context) (uint8, error)
 }
 
 func WithBalanceOfFunc(f func(ctx context.Context, address common.Address) (*big.Int, error)) Option {
@@ -30,6 +35,36 @@ func WithTransferFunc(f func(ctx context.Context, address common.Address, value
 	})
 }
 
+func WithAllowanceFunc(f func(ctx context.Context, owner, spender common.Address) (*big.Int, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.allowanceFunc = f
+	})
+}
+
+func WithApproveFunc(f func(ctx context.Context, spender common.Address, value *big.Int) (common.Hash, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.approveFunc = f
+	})
+}
+
+func WithNameFunc(f func(ctx context.Context) (string, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.nameFunc = f
+	})
+}
+
+func WithSymbolFunc(f func(ctx context.Context) (string, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.symbolFunc = f
+	})
+}
+
+func WithDecimalsFunc(f func(ctx context.Context) (uint8, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.decimalsFunc = f
+	})
+}
+
 func New(opts ...Option) erc20.Service {
 	mock := new(Service)
 	for _, o := range opts {
@@ -52,6 +87,41 @@ func (s *Service) Transfer(ctx context.Context, address common.Address, value *b
 	return common.Hash{}, errors.New("Error")
 }
 
+func (s *Service) Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error) {
+	if s.allowanceFunc != nil {
+		return s.allowanceFunc(ctx, owner, spender)
+	}
+	return big.NewInt(0), errors.New("Error")
+}
+
+func (s *Service) Approve(ctx context.Context, spender common.Address, value *big.Int) (common.Hash, error) {
+	if s.approveFunc != nil {
+		return s.approveFunc(ctx, spender, value)
+	}
+	return common.Hash{}, errors.New("Error")
+}
+
+func (s *Service) Name(ctx context.Context) (string, error) {
+	if s.nameFunc != nil {
+		return s.nameFunc(ctx)
+	}
+	return "", errors.New("Error")
+}
+
+func (s *Service) Symbol(ctx context.Context) (string, error) {
+	if s.symbolFunc != nil {
+		return s.symbolFunc(ctx)
+	}
+	return "", errors.New("Error")
+}
+
+func (s *Service) Decimals(ctx context.Context) (uint8, error) {
+	if s.decimalsFunc != nil {
+		return s.decimalsFunc(ctx)
+	}
+	return 0, errors.New("Error")
+}
+
 // Option is the option passed to the mock Chequebook service
 type Option interface {
 	apply(*Service)
@@ -0,0 +1,164 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// EventHandler is called with every settlement event of the swap service:
+// issued, received and bounced cheques, cashouts, deposits and withdrawals.
+// Handlers are called synchronously in the order of the events and must not
+// block; consumers which may fall behind subscribe to an events.Feed instead.
+type EventHandler func(events.Event)
+
+// eventBus dispatches the settlement events of the service to the registered
+// handlers, so that the call sites producing the events do not know about
+// metrics, the event stream or any other consumer.
+type eventBus struct {
+	mu       sync.RWMutex
+	handlers map[uint64]EventHandler
+	nextID   uint64
+}
+
+func (b *eventBus) subscribe(handler EventHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.handlers == nil {
+		b.handlers = make(map[uint64]EventHandler)
+	}
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+func (b *eventBus) publish(event events.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
+
+// SubscribeEvents registers a handler for the settlement events of the service
+// and returns a function which removes it again.
+func (s *Service) SubscribeEvents(handler EventHandler) (cancel func()) {
+	return s.bus.subscribe(handler)
+}
+
+// SetEventPublisher registers the publisher which is informed about the
+// settlement events of the service. It replaces a previously set publisher.
+func (s *Service) SetEventPublisher(publisher events.Publisher) {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	if s.eventsCancel != nil {
+		s.eventsCancel()
+		s.eventsCancel = nil
+	}
+	if publisher != nil {
+		s.eventsCancel = s.bus.subscribe(publisher.Publish)
+	}
+}
+
+func (s *Service) publish(event events.Event) {
+	if event.Time.IsZero() {
+		event.Time = s.clock.Now()
+	}
+	s.bus.publish(event)
+}
+
+// handleEvent updates the metrics of the service.
+func (m metrics) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.TypeChequeIssued:
+		amount, _ := big.NewFloat(0).SetInt(event.Amount).Float64()
+		m.TotalSent.Add(amount)
+		m.ChequesSent.Inc()
+	case events.TypeChequeReceived:
+		payout, _ := big.NewFloat(0).SetInt(event.Payout).Float64()
+		m.TotalReceived.Add(payout)
+		m.ChequesReceived.Inc()
+	case events.TypeChequeBounced:
+		m.ChequesBounced.Inc()
+	case events.TypeCashout:
+		m.Cashouts.Inc()
+	case events.TypeReminderSent:
+		m.RemindersSent.Inc()
+	case events.TypeReminderReceived:
+		m.RemindersReceived.Inc()
+	}
+}
+
+type eventChequebook struct {
+	chequebook.Service
+	publish func(events.Event)
+}
+
+// ChequebookWithEvents returns a chequebook service which publishes the
+// deposits and withdrawals of the given service to the handlers of the swap
+// service once their transactions have been broadcast.
+func (s *Service) ChequebookWithEvents(service chequebook.Service) chequebook.Service {
+	return &eventChequebook{Service: service, publish: s.publish}
+}
+
+func (c *eventChequebook) Deposit(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	txHash, err := c.Service.Deposit(ctx, amount)
+	if err != nil {
+		return txHash, err
+	}
+	c.publish(events.Event{
+		Type:       events.TypeDeposited,
+		Chequebook: c.Address(),
+		Amount:     amount.BigInt(),
+		Payout:     amount.BigInt(),
+		TxHash:     txHash,
+	})
+	return txHash, nil
+}
+
+func (c *eventChequebook) SplitDeposit(ctx context.Context, amount, maxTransfer chequebook.Tokens) (*chequebook.SplitDepositResult, error) {
+	result, err := c.Service.SplitDeposit(ctx, amount, maxTransfer)
+	if err != nil || len(result.TxHashes) == 0 {
+		return result, err
+	}
+	c.publish(events.Event{
+		Type:       events.TypeDeposited,
+		Chequebook: c.Address(),
+		Amount:     result.Received,
+		Payout:     result.Amount,
+		TxHash:     result.TxHashes[len(result.TxHashes)-1],
+	})
+	return result, nil
+}
+
+func (c *eventChequebook) Withdraw(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	txHash, err := c.Service.Withdraw(ctx, amount)
+	if err != nil {
+		return txHash, err
+	}
+	c.publish(events.Event{
+		Type:       events.TypeWithdrawn,
+		Chequebook: c.Address(),
+		Amount:     amount.BigInt(),
+		Payout:     amount.BigInt(),
+		TxHash:     txHash,
+	})
+	return txHash, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestChequebookWithEvents(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("fffa")
+	depositHash := common.HexToHash("dddd")
+	withdrawHash := common.HexToHash("eeee")
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{},
+		uint64(1),
+		&cashoutMock{},
+		nil,
+		chequebookAddress,
+	)
+
+	var handled []events.Event
+	cancel := swapService.SubscribeEvents(func(e events.Event) {
+		handled = append(handled, e)
+	})
+
+	feed := events.NewFeed()
+	defer feed.Close()
+	swapService.SetEventPublisher(feed)
+	c, cancelFeed := feed.Subscribe()
+	defer cancelFeed()
+
+	chequebookService := swapService.ChequebookWithEvents(mockchequebook.NewChequebook(
+		mockchequebook.WithChequebookAddressFunc(func() common.Address {
+			return chequebookAddress
+		}),
+		mockchequebook.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			return depositHash, nil
+		}),
+		mockchequebook.WithChequebookWithdrawFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			return withdrawHash, nil
+		}),
+	))
+
+	if _, err := chequebookService.Deposit(context.Background(), chequebook.TokensFromUint64(100)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := chequebookService.Withdraw(context.Background(), chequebook.TokensFromUint64(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 1 {
+		t.Fatalf("got %d events after cancelling the handler, want 1", len(handled))
+	}
+
+	for _, want := range []events.Event{
+		{Type: events.TypeDeposited, Amount: big.NewInt(100), TxHash: depositHash},
+		{Type: events.TypeWithdrawn, Amount: big.NewInt(40), TxHash: withdrawHash},
+	} {
+		e := <-c
+		if e.Type != want.Type || e.Chequebook != chequebookAddress || e.Amount.Cmp(want.Amount) != 0 || e.Payout.Cmp(want.Amount) != 0 || e.TxHash != want.TxHash {
+			t.Fatalf("unexpected event %+v", e)
+		}
+		if e.Time.IsZero() {
+			t.Fatal("event time not set")
+		}
+	}
+	if handled[0].Type != events.TypeDeposited {
+		t.Fatalf("unexpected handled event %+v", handled[0])
+	}
+
+	// replacing the publisher stops the delivery to the previous one
+	swapService.SetEventPublisher(nil)
+	if _, err := chequebookService.Deposit(context.Background(), chequebook.TokensFromUint64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 0 {
+		t.Fatal("event delivered to the replaced publisher")
+	}
+}
@@ -1,5 +1,12 @@
 package swap
 
+import (
+	"context"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
 var (
 	PeerKey            = peerKey
 	ChequebookPeerKey  = chequebookPeerKey
@@ -7,4 +14,27 @@ var (
 	BeneficiaryPeerKey = beneficiaryPeerKey
 	PeerDeductedByKey  = peerDeductedByKey
 	PeerDeductedForKey = peerDeductedForKey
+	ReceiptKey         = receiptKey
+
+	AnnouncedChequebookKey = announcedChequebookKey
 )
+
+func (s *Service) CheckGrace(ctx context.Context, now time.Time) error {
+	return s.checkGrace(ctx, now)
+}
+
+func (s *Service) CheckWithdrawals(ctx context.Context, backend transaction.Backend, o WithdrawWatchOptions) error {
+	return s.checkWithdrawals(ctx, backend, o)
+}
+
+func (s *Service) CleanupStalePeers(ctx context.Context, o StaleCleanupOptions) error {
+	return s.cleanupStalePeers(ctx, o)
+}
+
+func (s *Service) AutoCashout(ctx context.Context, o AutoCashoutOptions) error {
+	return s.autoCashout(ctx, o)
+}
+
+func (s *Service) CheckCashouts(ctx context.Context) error {
+	return s.checkCashouts(ctx)
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fleet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrExportUnsupported is the error returned if the chequebook of a node cannot export its cheques.
+var ErrExportUnsupported = errors.New("cheque export not supported")
+
+// ChequeRecord is the last cheque exchanged with a peer.
+type ChequeRecord struct {
+	Beneficiary      common.Address
+	Chequebook       common.Address
+	CumulativePayout *big.Int
+}
+
+// PeerCheques are the last cheques a node sent to and received from a peer.
+type PeerCheques struct {
+	Peer         swarm.Address
+	LastSent     *ChequeRecord // nil if no cheque was sent
+	LastReceived *ChequeRecord // nil if no cheque was received
+}
+
+// ChequeExport is the settlement export of a node the cheques of the nodes of
+// a fleet are reconciled with.
+type ChequeExport struct {
+	Name       string
+	Overlay    swarm.Address
+	Chequebook common.Address
+	Cheques    []PeerCheques
+}
+
+// ChequeExporter exports the last cheques of a node. It is implemented by
+// Remote.
+type ChequeExporter interface {
+	ChequeExport(ctx context.Context) (*ChequeExport, error)
+}
+
+// Inconsistency is the kind of disagreement between the cheques two nodes
+// recorded for each other.
+type Inconsistency string
+
+const (
+	// ReceivedBehind means the receiver has a lower cumulative payout than
+	// the issuer sent, cheques were lost or are still in flight.
+	ReceivedBehind Inconsistency = "received_behind"
+	// IssuedBehind means the receiver holds a higher cumulative payout than
+	// the issuer knows of. The issuer lost state and has to import its last
+	// cheque before issuing again.
+	IssuedBehind Inconsistency = "issued_behind"
+	// MissingReceived means the receiver has no cheque of the issuer.
+	MissingReceived Inconsistency = "missing_received"
+	// MissingIssued means the issuer has no record of the cheques the
+	// receiver holds.
+	MissingIssued Inconsistency = "missing_issued"
+	// ChequebookMismatch means the receiver holds cheques of another
+	// chequebook than the one of the issuer.
+	ChequebookMismatch Inconsistency = "chequebook_mismatch"
+	// BeneficiaryMismatch means the cheques were issued to another
+	// beneficiary than the one the receiver holds them for.
+	BeneficiaryMismatch Inconsistency = "beneficiary_mismatch"
+)
+
+// ChequePair compares the last cheque one node of the fleet issued to another
+// with the last cheque the other one received from it.
+type ChequePair struct {
+	Issuer        string
+	Receiver      string
+	Issued        *ChequeRecord // nil if the issuer has no record
+	Received      *ChequeRecord // nil if the receiver has no record
+	Difference    *big.Int      // issued minus received cumulative payout
+	Inconsistency Inconsistency // empty if the records agree
+}
+
+// ExportError is the failure to export the cheques of a node.
+type ExportError struct {
+	Name string
+	Err  error
+}
+
+// ReconciliationReport is the comparison of the cheques exchanged between
+// the nodes of a fleet.
+type ReconciliationReport struct {
+	Pairs        []ChequePair // all pairs of nodes which exchanged cheques, by issuer and receiver
+	Inconsistent int          // number of pairs whose records disagree
+	Errors       []ExportError
+}
+
+// Reconcile compares the last cheques each node of the fleet issued to another
+// node of the fleet with the last cheques the other node received from it.
+// Cheques exchanged with peers outside of the fleet are ignored.
+func Reconcile(exports ...*ChequeExport) *ReconciliationReport {
+	byOverlay := make(map[string]*ChequeExport, len(exports))
+	for _, export := range exports {
+		byOverlay[export.Overlay.ByteString()] = export
+	}
+
+	type pairKey struct{ issuer, receiver string }
+	pairs := make(map[pairKey]*ChequePair)
+	pair := func(issuer, receiver *ChequeExport) *ChequePair {
+		key := pairKey{issuer.Overlay.ByteString(), receiver.Overlay.ByteString()}
+		p, ok := pairs[key]
+		if !ok {
+			p = &ChequePair{Issuer: issuer.Name, Receiver: receiver.Name}
+			pairs[key] = p
+		}
+		return p
+	}
+
+	for _, export := range exports {
+		for _, c := range export.Cheques {
+			peer, ok := byOverlay[c.Peer.ByteString()]
+			if !ok {
+				continue
+			}
+			if c.LastSent != nil {
+				pair(export, peer).Issued = c.LastSent
+			}
+			if c.LastReceived != nil {
+				pair(peer, export).Received = c.LastReceived
+			}
+		}
+	}
+
+	report := &ReconciliationReport{Pairs: make([]ChequePair, 0, len(pairs))}
+	for key, p := range pairs {
+		p.Inconsistency = inconsistency(byOverlay[key.issuer].Chequebook, p)
+		if p.Issued != nil && p.Received != nil {
+			p.Difference = new(big.Int).Sub(p.Issued.CumulativePayout, p.Received.CumulativePayout)
+		}
+		if p.Inconsistency != "" {
+			report.Inconsistent++
+		}
+		report.Pairs = append(report.Pairs, *p)
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		if report.Pairs[i].Issuer != report.Pairs[j].Issuer {
+			return report.Pairs[i].Issuer < report.Pairs[j].Issuer
+		}
+		return report.Pairs[i].Receiver < report.Pairs[j].Receiver
+	})
+	return report
+}
+
+// inconsistency returns how the records of the pair disagree, empty if they
+// agree.
+func inconsistency(issuerChequebook common.Address, p *ChequePair) Inconsistency {
+	switch {
+	case p.Received == nil:
+		return MissingReceived
+	case p.Received.Chequebook != issuerChequebook:
+		return ChequebookMismatch
+	case p.Issued == nil:
+		return MissingIssued
+	case p.Issued.Beneficiary != p.Received.Beneficiary:
+		return BeneficiaryMismatch
+	}
+	switch p.Issued.CumulativePayout.Cmp(p.Received.CumulativePayout) {
+	case 1:
+		return ReceivedBehind
+	case -1:
+		return IssuedBehind
+	}
+	return ""
+}
+
+// Reconcile exports the cheques of all nodes concurrently and reconciles them.
+// Nodes whose cheques could not be exported are reported with their error and
+// left out of the comparison.
+func (m *Manager) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	if len(m.nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	exports := make([]*ChequeExport, len(m.nodes))
+	errs := make([]error, len(m.nodes))
+	var wg sync.WaitGroup
+	for i, node := range m.nodes {
+		wg.Add(1)
+		go func(i int, node Node) {
+			defer wg.Done()
+			exporter, ok := node.Chequebook.(ChequeExporter)
+			if !ok {
+				errs[i] = ErrExportUnsupported
+				return
+			}
+			exports[i], errs[i] = exporter.ChequeExport(ctx)
+			if errs[i] == nil {
+				exports[i].Name = node.Name
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	var exported []*ChequeExport
+	var failed []ExportError
+	for i, node := range m.nodes {
+		if errs[i] != nil {
+			failed = append(failed, ExportError{Name: node.Name, Err: errs[i]})
+			continue
+		}
+		exported = append(exported, exports[i])
+	}
+
+	report := Reconcile(exported...)
+	report.Errors = failed
+	return report, nil
+}
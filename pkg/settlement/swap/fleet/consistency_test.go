@@ -0,0 +1,147 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fleet_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/fleet"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	a := &fleet.ChequeExport{Name: "a", Overlay: swarm.MustParseHexAddress("aa"), Chequebook: common.HexToAddress("0xaa")}
+	b := &fleet.ChequeExport{Name: "b", Overlay: swarm.MustParseHexAddress("bb"), Chequebook: common.HexToAddress("0xbb")}
+	c := &fleet.ChequeExport{Name: "c", Overlay: swarm.MustParseHexAddress("cc"), Chequebook: common.HexToAddress("0xcc")}
+	beneficiary := func(e *fleet.ChequeExport) common.Address {
+		return common.BytesToAddress(append([]byte{1}, e.Chequebook.Bytes()...))
+	}
+	record := func(from, to *fleet.ChequeExport, payout int64) *fleet.ChequeRecord {
+		return &fleet.ChequeRecord{Beneficiary: beneficiary(to), Chequebook: from.Chequebook, CumulativePayout: big.NewInt(payout)}
+	}
+	outsider := swarm.MustParseHexAddress("ff")
+
+	a.Cheques = []fleet.PeerCheques{
+		// a and b agree on what a sent, b lost track of what it sent to a
+		{Peer: b.Overlay, LastSent: record(a, b, 100), LastReceived: record(b, a, 40)},
+		{Peer: c.Overlay, LastSent: record(a, c, 70)},
+		{Peer: outsider, LastReceived: &fleet.ChequeRecord{CumulativePayout: big.NewInt(1)}},
+	}
+	b.Cheques = []fleet.PeerCheques{
+		{Peer: a.Overlay, LastSent: record(b, a, 30), LastReceived: record(a, b, 100)},
+	}
+	c.Cheques = []fleet.PeerCheques{
+		{Peer: b.Overlay, LastReceived: record(b, c, 10)},
+	}
+
+	report := fleet.Reconcile(a, b, c)
+
+	want := []struct {
+		issuer, receiver string
+		inconsistency    fleet.Inconsistency
+	}{
+		{"a", "b", ""},
+		{"a", "c", fleet.MissingReceived},
+		{"b", "a", fleet.IssuedBehind},
+		{"b", "c", fleet.MissingIssued},
+	}
+	if len(report.Pairs) != len(want) {
+		t.Fatalf("got %d pairs, want %d", len(report.Pairs), len(want))
+	}
+	for i, w := range want {
+		p := report.Pairs[i]
+		if p.Issuer != w.issuer || p.Receiver != w.receiver || p.Inconsistency != w.inconsistency {
+			t.Fatalf("got pair %s->%s %q, want %s->%s %q", p.Issuer, p.Receiver, p.Inconsistency, w.issuer, w.receiver, w.inconsistency)
+		}
+	}
+	if report.Inconsistent != 3 {
+		t.Fatalf("got %d inconsistent pairs, want 3", report.Inconsistent)
+	}
+	if d := report.Pairs[2].Difference; d.Cmp(big.NewInt(-10)) != 0 {
+		t.Fatalf("got difference %d, want -10", d)
+	}
+}
+
+func TestRemoteChequeExport(t *testing.T) {
+	t.Parallel()
+
+	overlay := swarm.MustParseHexAddress("aa")
+	peer1 := swarm.MustParseHexAddress("bb")
+	peer2 := swarm.MustParseHexAddress("cc")
+	chequebookAddress := common.HexToAddress("0xabcd")
+
+	type cheque struct {
+		Beneficiary string `json:"beneficiary"`
+		Chequebook  string `json:"chequebook"`
+		Payout      string `json:"payout"`
+	}
+	type peerCheques struct {
+		Peer         string  `json:"peer"`
+		LastReceived *cheque `json:"lastreceived"`
+		LastSent     *cheque `json:"lastsent"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chequebook/address", func(w http.ResponseWriter, r *http.Request) {
+		jsonhttp.OK(w, struct {
+			Address string `json:"chequebookAddress"`
+		}{Address: chequebookAddress.String()})
+	})
+	mux.HandleFunc("/addresses", func(w http.ResponseWriter, r *http.Request) {
+		jsonhttp.OK(w, struct {
+			Overlay string `json:"overlay"`
+		}{Overlay: overlay.String()})
+	})
+	mux.HandleFunc("/chequebook/cheque", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" {
+			jsonhttp.OK(w, struct {
+				LastCheques []peerCheques `json:"lastcheques"`
+				Next        string        `json:"next"`
+			}{
+				LastCheques: []peerCheques{{Peer: peer1.String(), LastSent: &cheque{Beneficiary: common.HexToAddress("0x01").String(), Chequebook: chequebookAddress.String(), Payout: "5"}}},
+				Next:        "next",
+			})
+			return
+		}
+		jsonhttp.OK(w, struct {
+			LastCheques []peerCheques `json:"lastcheques"`
+		}{
+			LastCheques: []peerCheques{{Peer: peer2.String(), LastReceived: &cheque{Beneficiary: common.HexToAddress("0x02").String(), Chequebook: common.HexToAddress("0x03").String(), Payout: "7"}}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	remote, err := fleet.NewRemote(ctx, server.URL, fleet.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := remote.ChequeExport(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !export.Overlay.Equal(overlay) || export.Chequebook != chequebookAddress {
+		t.Fatalf("got overlay %s chequebook %v, want %s %v", export.Overlay, export.Chequebook, overlay, chequebookAddress)
+	}
+	if len(export.Cheques) != 2 {
+		t.Fatalf("got cheques of %d peers, want 2", len(export.Cheques))
+	}
+	if !export.Cheques[0].Peer.Equal(peer1) || export.Cheques[0].LastSent.CumulativePayout.Cmp(big.NewInt(5)) != 0 || export.Cheques[0].LastReceived != nil {
+		t.Fatalf("got first page %+v", export.Cheques[0])
+	}
+	if !export.Cheques[1].Peer.Equal(peer2) || export.Cheques[1].LastReceived.Chequebook != common.HexToAddress("0x03") {
+		t.Fatalf("got second page %+v", export.Cheques[1])
+	}
+}
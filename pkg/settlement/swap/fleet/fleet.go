@@ -0,0 +1,231 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fleet provides a manager administering the chequebooks of several
+// bee nodes at once. It aggregates their balances and coordinates sweeps and
+// top-ups for operators running a fleet of nodes.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/analytics"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// ErrNoNodes is the error returned if the manager has no nodes.
+var ErrNoNodes = errors.New("no nodes")
+
+// Chequebook is the part of a chequebook service the manager needs. It is
+// implemented both by chequebook.Service of nodes running in the same process
+// and by Remote for nodes administered through their API.
+type Chequebook interface {
+	// Address returns the address of the chequebook.
+	Address() common.Address
+	// Balance returns the token balance of the chequebook.
+	Balance(ctx context.Context) (*big.Int, error)
+	// AvailableBalance returns the token balance of the chequebook which is not yet used for uncashed cheques.
+	AvailableBalance(ctx context.Context) (*big.Int, error)
+	// Deposit starts depositing erc20 token from the node wallet into the chequebook.
+	Deposit(ctx context.Context, amount chequebook.Tokens) (common.Hash, error)
+	// Withdraw starts withdrawing erc20 token from the chequebook to the node wallet.
+	Withdraw(ctx context.Context, amount chequebook.Tokens) (common.Hash, error)
+}
+
+// Node is a bee node administered by the manager.
+type Node struct {
+	Name       string
+	Chequebook Chequebook
+}
+
+// NodeBalance is the chequebook balance of a single node.
+type NodeBalance struct {
+	Name             string
+	Chequebook       common.Address
+	TotalBalance     *big.Int
+	AvailableBalance *big.Int
+	Err              error // set if the balance could not be retrieved
+}
+
+// Balances is the aggregated chequebook balance of all nodes.
+type Balances struct {
+	Nodes            []NodeBalance
+	TotalBalance     *big.Int // sum over the nodes without error
+	AvailableBalance *big.Int // sum over the nodes without error
+}
+
+// Result is the outcome of an operation on a single node.
+type Result struct {
+	Name            string
+	Chequebook      common.Address
+	Amount          *big.Int
+	TransactionHash common.Hash
+	Err             error
+}
+
+// Manager administers the chequebooks of several nodes.
+type Manager struct {
+	nodes []Node
+}
+
+// New creates a new manager for the given nodes.
+func New(nodes ...Node) *Manager {
+	return &Manager{nodes: nodes}
+}
+
+// Nodes returns the nodes administered by the manager.
+func (m *Manager) Nodes() []Node {
+	return append([]Node(nil), m.nodes...)
+}
+
+// Balances retrieves the balances of all nodes concurrently. A node whose
+// balance could not be retrieved is reported with its error and left out of
+// the totals.
+func (m *Manager) Balances(ctx context.Context) (*Balances, error) {
+	if len(m.nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	balances := &Balances{
+		Nodes:            make([]NodeBalance, len(m.nodes)),
+		TotalBalance:     big.NewInt(0),
+		AvailableBalance: big.NewInt(0),
+	}
+
+	var wg sync.WaitGroup
+	for i, node := range m.nodes {
+		wg.Add(1)
+		go func(i int, node Node) {
+			defer wg.Done()
+			balances.Nodes[i] = nodeBalance(ctx, node)
+		}(i, node)
+	}
+	wg.Wait()
+
+	for _, balance := range balances.Nodes {
+		if balance.Err != nil {
+			continue
+		}
+		balances.TotalBalance.Add(balances.TotalBalance, balance.TotalBalance)
+		balances.AvailableBalance.Add(balances.AvailableBalance, balance.AvailableBalance)
+	}
+
+	return balances, nil
+}
+
+func nodeBalance(ctx context.Context, node Node) NodeBalance {
+	balance := NodeBalance{
+		Name:       node.Name,
+		Chequebook: node.Chequebook.Address(),
+	}
+	balance.TotalBalance, balance.Err = node.Chequebook.Balance(ctx)
+	if balance.Err != nil {
+		return balance
+	}
+	balance.AvailableBalance, balance.Err = node.Chequebook.AvailableBalance(ctx)
+	return balance
+}
+
+// Sweep withdraws from every chequebook all of its available balance exceeding
+// retain. Nodes with nothing to sweep are not part of the results. The
+// withdrawals of nodes running in the same process are tagged as sweeps in
+// their spending reports.
+func (m *Manager) Sweep(ctx context.Context, retain *big.Int) ([]Result, error) {
+	ctx = analytics.WithPurpose(ctx, analytics.PurposeSweep)
+
+	balances, err := m.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(m.nodes))
+	for i, balance := range balances.Nodes {
+		if balance.Err != nil {
+			results = append(results, Result{Name: balance.Name, Chequebook: balance.Chequebook, Err: balance.Err})
+			continue
+		}
+
+		amount := new(big.Int).Sub(balance.AvailableBalance, retain)
+		if amount.Sign() <= 0 {
+			continue
+		}
+
+		result := Result{Name: balance.Name, Chequebook: balance.Chequebook, Amount: amount}
+		var tokens chequebook.Tokens
+		if tokens, result.Err = chequebook.NewTokens(amount); result.Err == nil {
+			result.TransactionHash, result.Err = m.nodes[i].Chequebook.Withdraw(ctx, tokens)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// TopUp deposits into every chequebook whose available balance is below
+// target the difference to target. The chequebooks with the lowest balance are
+// topped up first. If budget is not nil, at most budget is deposited in total
+// and the last top-up might only be partial. Nodes which need no top-up are
+// not part of the results.
+func (m *Manager) TopUp(ctx context.Context, target, budget *big.Int) ([]Result, error) {
+	balances, err := m.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, len(balances.Nodes))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		x, y := balances.Nodes[indices[a]], balances.Nodes[indices[b]]
+		if x.Err != nil || y.Err != nil {
+			return x.Err != nil && y.Err == nil
+		}
+		return x.AvailableBalance.Cmp(y.AvailableBalance) < 0
+	})
+
+	var remaining *big.Int
+	if budget != nil {
+		remaining = new(big.Int).Set(budget)
+	}
+
+	results := make([]Result, 0, len(m.nodes))
+	for _, i := range indices {
+		balance := balances.Nodes[i]
+		if balance.Err != nil {
+			results = append(results, Result{Name: balance.Name, Chequebook: balance.Chequebook, Err: balance.Err})
+			continue
+		}
+
+		amount := new(big.Int).Sub(target, balance.AvailableBalance)
+		if amount.Sign() <= 0 {
+			continue
+		}
+		if remaining != nil {
+			if remaining.Sign() <= 0 {
+				break
+			}
+			if amount.Cmp(remaining) > 0 {
+				amount.Set(remaining)
+			}
+		}
+
+		result := Result{Name: balance.Name, Chequebook: balance.Chequebook, Amount: amount}
+		var tokens chequebook.Tokens
+		if tokens, result.Err = chequebook.NewTokens(amount); result.Err == nil {
+			result.TransactionHash, result.Err = m.nodes[i].Chequebook.Deposit(ctx, tokens)
+		}
+		if result.Err == nil && remaining != nil {
+			remaining.Sub(remaining, amount)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fleet_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/fleet"
+)
+
+type testNode struct {
+	mu        sync.Mutex
+	name      string
+	address   common.Address
+	available *big.Int
+	err       error
+	deposited *big.Int
+	withdrawn *big.Int
+}
+
+func newTestNode(name string, available int64) *testNode {
+	return &testNode{
+		name:      name,
+		address:   common.HexToAddress("0xab" + name),
+		available: big.NewInt(available),
+		deposited: big.NewInt(0),
+		withdrawn: big.NewInt(0),
+	}
+}
+
+func (n *testNode) node() fleet.Node {
+	return fleet.Node{Name: n.name, Chequebook: n.chequebook()}
+}
+
+func (n *testNode) chequebook() chequebook.Service {
+	balance := func(ctx context.Context) (*big.Int, error) {
+		if n.err != nil {
+			return nil, n.err
+		}
+		return new(big.Int).Set(n.available), nil
+	}
+	return mock.NewChequebook(
+		mock.WithChequebookAddressFunc(func() common.Address { return n.address }),
+		mock.WithChequebookBalanceFunc(balance),
+		mock.WithChequebookAvailableBalanceFunc(balance),
+		mock.WithChequebookDepositFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			n.deposited.Add(n.deposited, amount)
+			return common.HexToHash("0x1"), nil
+		}),
+		mock.WithChequebookWithdrawFunc(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			n.withdrawn.Add(n.withdrawn, amount)
+			return common.HexToHash("0x2"), nil
+		}),
+	)
+}
+
+func TestBalances(t *testing.T) {
+	t.Parallel()
+
+	errBalance := errors.New("balance error")
+	a, b, c := newTestNode("a", 10), newTestNode("b", 20), newTestNode("c", 30)
+	c.err = errBalance
+
+	balances, err := fleet.New(a.node(), b.node(), c.node()).Balances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balances.TotalBalance.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("got total balance %d, want %d", balances.TotalBalance, 30)
+	}
+	if balances.AvailableBalance.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("got available balance %d, want %d", balances.AvailableBalance, 30)
+	}
+	if len(balances.Nodes) != 3 {
+		t.Fatalf("got %d nodes, want %d", len(balances.Nodes), 3)
+	}
+	if balances.Nodes[1].Chequebook != b.address {
+		t.Fatalf("got chequebook %x, want %x", balances.Nodes[1].Chequebook, b.address)
+	}
+	if !errors.Is(balances.Nodes[2].Err, errBalance) {
+		t.Fatalf("got error %v, want %v", balances.Nodes[2].Err, errBalance)
+	}
+
+	if _, err := fleet.New().Balances(context.Background()); !errors.Is(err, fleet.ErrNoNodes) {
+		t.Fatalf("got error %v, want %v", err, fleet.ErrNoNodes)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	t.Parallel()
+
+	a, b := newTestNode("a", 10), newTestNode("b", 50)
+
+	results, err := fleet.New(a.node(), b.node()).Sweep(context.Background(), big.NewInt(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want %d", len(results), 1)
+	}
+	if results[0].Name != "b" || results[0].Err != nil {
+		t.Fatalf("got result %+v, want sweep of b", results[0])
+	}
+	if a.withdrawn.Sign() != 0 {
+		t.Fatalf("withdrew %d from a", a.withdrawn)
+	}
+	if b.withdrawn.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("withdrew %d from b, want %d", b.withdrawn, 30)
+	}
+}
+
+func TestTopUp(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+
+		a, b, c := newTestNode("a", 10), newTestNode("b", 50), newTestNode("c", 0)
+
+		results, err := fleet.New(a.node(), b.node(), c.node()).TopUp(context.Background(), big.NewInt(40), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("got %d results, want %d", len(results), 2)
+		}
+		if a.deposited.Cmp(big.NewInt(30)) != 0 {
+			t.Fatalf("deposited %d to a, want %d", a.deposited, 30)
+		}
+		if b.deposited.Sign() != 0 {
+			t.Fatalf("deposited %d to b", b.deposited)
+		}
+		if c.deposited.Cmp(big.NewInt(40)) != 0 {
+			t.Fatalf("deposited %d to c, want %d", c.deposited, 40)
+		}
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		t.Parallel()
+
+		a, b, c := newTestNode("a", 10), newTestNode("b", 50), newTestNode("c", 0)
+
+		results, err := fleet.New(a.node(), b.node(), c.node()).TopUp(context.Background(), big.NewInt(40), big.NewInt(50))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the lowest balance is topped up first
+		if len(results) != 2 || results[0].Name != "c" || results[1].Name != "a" {
+			t.Fatalf("got results %+v, want top-up of c and a", results)
+		}
+		if c.deposited.Cmp(big.NewInt(40)) != 0 {
+			t.Fatalf("deposited %d to c, want %d", c.deposited, 40)
+		}
+		if a.deposited.Cmp(big.NewInt(10)) != 0 {
+			t.Fatalf("deposited %d to a, want %d", a.deposited, 10)
+		}
+	})
+}
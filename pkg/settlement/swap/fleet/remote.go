@@ -0,0 +1,230 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrRemote is the error returned if a remote node responded with an error.
+var ErrRemote = errors.New("remote node error")
+
+var (
+	_ Chequebook     = (*Remote)(nil)
+	_ ChequeExporter = (*Remote)(nil)
+)
+
+// exportPageSize is the number of peers whose cheques are requested at once.
+const exportPageSize = 500
+
+// Remote is the chequebook of a node administered through its API.
+type Remote struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	address  common.Address
+}
+
+// RemoteOption configures a Remote.
+type RemoteOption func(*Remote)
+
+// WithHTTPClient sets the http client used to reach the node.
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(r *Remote) {
+		r.client = client
+	}
+}
+
+// WithAuthToken sets the bearer token sent to nodes running in restricted mode.
+func WithAuthToken(token string) RemoteOption {
+	return func(r *Remote) {
+		r.token = token
+	}
+}
+
+// NewRemote connects to the API of the node at endpoint and looks up the
+// address of its chequebook.
+func NewRemote(ctx context.Context, endpoint string, opts ...RemoteOption) (*Remote, error) {
+	r := &Remote{
+		client:   http.DefaultClient,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	var response struct {
+		Address common.Address `json:"chequebookAddress"`
+	}
+	if err := r.request(ctx, http.MethodGet, "/chequebook/address", nil, &response); err != nil {
+		return nil, err
+	}
+	r.address = response.Address
+
+	return r, nil
+}
+
+// Address implements the Chequebook interface.
+func (r *Remote) Address() common.Address {
+	return r.address
+}
+
+// Balance implements the Chequebook interface.
+func (r *Remote) Balance(ctx context.Context) (*big.Int, error) {
+	balance, _, err := r.balances(ctx)
+	return balance, err
+}
+
+// AvailableBalance implements the Chequebook interface.
+func (r *Remote) AvailableBalance(ctx context.Context) (*big.Int, error) {
+	_, available, err := r.balances(ctx)
+	return available, err
+}
+
+func (r *Remote) balances(ctx context.Context) (total, available *big.Int, err error) {
+	var response struct {
+		TotalBalance     *bigint.BigInt `json:"totalBalance"`
+		AvailableBalance *bigint.BigInt `json:"availableBalance"`
+	}
+	if err := r.request(ctx, http.MethodGet, "/chequebook/balance", nil, &response); err != nil {
+		return nil, nil, err
+	}
+	if response.TotalBalance == nil || response.AvailableBalance == nil {
+		return nil, nil, fmt.Errorf("%s: incomplete balance response: %w", r.endpoint, ErrRemote)
+	}
+	return response.TotalBalance.Int, response.AvailableBalance.Int, nil
+}
+
+// Deposit implements the Chequebook interface.
+func (r *Remote) Deposit(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	return r.transact(ctx, "/chequebook/deposit", amount)
+}
+
+// Withdraw implements the Chequebook interface.
+func (r *Remote) Withdraw(ctx context.Context, amount chequebook.Tokens) (common.Hash, error) {
+	return r.transact(ctx, "/chequebook/withdraw", amount)
+}
+
+func (r *Remote) transact(ctx context.Context, path string, amount chequebook.Tokens) (common.Hash, error) {
+	var response struct {
+		TransactionHash common.Hash `json:"transactionHash"`
+	}
+	query := url.Values{"amount": {amount.String()}}
+	if err := r.request(ctx, http.MethodPost, path, query, &response); err != nil {
+		return common.Hash{}, err
+	}
+	return response.TransactionHash, nil
+}
+
+// ChequeExport implements the ChequeExporter interface. It pages through the
+// last cheques of all peers of the node.
+func (r *Remote) ChequeExport(ctx context.Context) (*ChequeExport, error) {
+	var addresses struct {
+		Overlay swarm.Address `json:"overlay"`
+	}
+	if err := r.request(ctx, http.MethodGet, "/addresses", nil, &addresses); err != nil {
+		return nil, err
+	}
+	export := &ChequeExport{
+		Overlay:    addresses.Overlay,
+		Chequebook: r.address,
+	}
+
+	type cheque struct {
+		Beneficiary common.Address `json:"beneficiary"`
+		Chequebook  common.Address `json:"chequebook"`
+		Payout      *bigint.BigInt `json:"payout"`
+	}
+	record := func(c *cheque) (*ChequeRecord, error) {
+		if c == nil {
+			return nil, nil
+		}
+		if c.Payout == nil {
+			return nil, fmt.Errorf("%s: incomplete cheque response: %w", r.endpoint, ErrRemote)
+		}
+		return &ChequeRecord{Beneficiary: c.Beneficiary, Chequebook: c.Chequebook, CumulativePayout: c.Payout.Int}, nil
+	}
+
+	cursor := ""
+	for {
+		var response struct {
+			LastCheques []struct {
+				Peer         swarm.Address `json:"peer"`
+				LastReceived *cheque       `json:"lastreceived"`
+				LastSent     *cheque       `json:"lastsent"`
+			} `json:"lastcheques"`
+			Next string `json:"next"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(exportPageSize)}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		if err := r.request(ctx, http.MethodGet, "/chequebook/cheque", query, &response); err != nil {
+			return nil, err
+		}
+		for _, c := range response.LastCheques {
+			sent, err := record(c.LastSent)
+			if err != nil {
+				return nil, err
+			}
+			received, err := record(c.LastReceived)
+			if err != nil {
+				return nil, err
+			}
+			export.Cheques = append(export.Cheques, PeerCheques{Peer: c.Peer, LastSent: sent, LastReceived: received})
+		}
+		if response.Next == "" {
+			return export, nil
+		}
+		cursor = response.Next
+	}
+}
+
+func (r *Remote) request(ctx context.Context, method, path string, query url.Values, v interface{}) error {
+	u := r.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var status jsonhttp.StatusResponse
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<16))
+		if err := json.Unmarshal(data, &status); err != nil || status.Message == "" {
+			status.Message = http.StatusText(res.StatusCode)
+		}
+		return fmt.Errorf("%s %s: %s: %w", method, u, status.Message, ErrRemote)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
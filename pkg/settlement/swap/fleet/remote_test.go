@@ -0,0 +1,99 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fleet_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/fleet"
+)
+
+func TestRemote(t *testing.T) {
+	t.Parallel()
+
+	chequebookAddress := common.HexToAddress("0xabcd")
+	txHash := common.HexToHash("0xff")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chequebook/address", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			jsonhttp.Unauthorized(w, "unauthorized")
+			return
+		}
+		jsonhttp.OK(w, struct {
+			Address string `json:"chequebookAddress"`
+		}{Address: chequebookAddress.String()})
+	})
+	mux.HandleFunc("/chequebook/balance", func(w http.ResponseWriter, r *http.Request) {
+		jsonhttp.OK(w, struct {
+			TotalBalance     string `json:"totalBalance"`
+			AvailableBalance string `json:"availableBalance"`
+		}{TotalBalance: "100", AvailableBalance: "60"})
+	})
+	mux.HandleFunc("/chequebook/deposit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("amount") != "42" {
+			jsonhttp.BadRequest(w, "bad request")
+			return
+		}
+		jsonhttp.OK(w, struct {
+			TransactionHash common.Hash `json:"transactionHash"`
+		}{TransactionHash: txHash})
+	})
+	mux.HandleFunc("/chequebook/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		jsonhttp.BadRequest(w, "insufficient balance")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	if _, err := fleet.NewRemote(ctx, server.URL); !errors.Is(err, fleet.ErrRemote) {
+		t.Fatalf("got error %v, want %v", err, fleet.ErrRemote)
+	}
+
+	remote, err := fleet.NewRemote(ctx, server.URL+"/", fleet.WithAuthToken("token"), fleet.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remote.Address() != chequebookAddress {
+		t.Fatalf("got address %x, want %x", remote.Address(), chequebookAddress)
+	}
+
+	balance, err := remote.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got balance %d, want %d", balance, 100)
+	}
+	available, err := remote.AvailableBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(60)) != 0 {
+		t.Fatalf("got available balance %d, want %d", available, 60)
+	}
+
+	hash, err := remote.Deposit(ctx, chequebook.TokensFromUint64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != txHash {
+		t.Fatalf("got transaction hash %x, want %x", hash, txHash)
+	}
+
+	if _, err := remote.Withdraw(ctx, chequebook.TokensFromUint64(1)); !errors.Is(err, fleet.ErrRemote) {
+		t.Fatalf("got error %v, want %v", err, fleet.ErrRemote)
+	}
+}
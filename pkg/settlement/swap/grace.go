@@ -0,0 +1,292 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// DefaultEscalationLadder is the default escalation ladder for peers
+	// whose debt stays above the payment threshold.
+	DefaultEscalationLadder = "remind:1m,remind:5m,throttle:10m,disconnect:30m"
+	// DefaultGraceCheckInterval is the default interval in which the debt of
+	// the connected peers is checked against the payment threshold.
+	DefaultGraceCheckInterval = 15 * time.Second
+)
+
+// EscalationAction is an action taken against a peer whose debt stays above
+// the payment threshold.
+type EscalationAction int
+
+const (
+	// EscalationRemind sends a settlement reminder to the peer.
+	EscalationRemind EscalationAction = iota + 1
+	// EscalationThrottle marks the peer as throttled until it settles.
+	EscalationThrottle
+	// EscalationDisconnect emits a disconnect notification for the peer.
+	EscalationDisconnect
+)
+
+func (a EscalationAction) String() string {
+	switch a {
+	case EscalationRemind:
+		return "remind"
+	case EscalationThrottle:
+		return "throttle"
+	case EscalationDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("unknown action %d", int(a))
+	}
+}
+
+// EscalationStep is taken once the debt of a peer has been above the payment
+// threshold for After.
+type EscalationStep struct {
+	After  time.Duration
+	Action EscalationAction
+}
+
+// ParseEscalationLadder parses a comma separated list of action:duration
+// steps, e.g. "remind:1m,throttle:10m,disconnect:30m". The steps are returned
+// ordered by their duration. An empty string yields no steps.
+func ParseEscalationLadder(s string) ([]EscalationStep, error) {
+	var steps []EscalationStep
+	for _, step := range strings.Split(s, ",") {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+		name, after, ok := strings.Cut(step, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid escalation step %q", step)
+		}
+		var action EscalationAction
+		switch strings.TrimSpace(name) {
+		case "remind":
+			action = EscalationRemind
+		case "throttle":
+			action = EscalationThrottle
+		case "disconnect":
+			action = EscalationDisconnect
+		default:
+			return nil, fmt.Errorf("invalid escalation action %q", name)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(after))
+		if err != nil {
+			return nil, fmt.Errorf("invalid escalation step %q: %w", step, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid escalation step %q: negative duration", step)
+		}
+		steps = append(steps, EscalationStep{After: d, Action: action})
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].After < steps[j].After
+	})
+	return steps, nil
+}
+
+// PaymentThresholdFunc returns the payment threshold at which the peer is
+// expected to pay us.
+type PaymentThresholdFunc func(peer swarm.Address) *big.Int
+
+type graceTracker struct {
+	mu        sync.Mutex
+	ladder    []EscalationStep
+	threshold PaymentThresholdFunc
+	peers     map[string]*peerGrace
+}
+
+// peerGrace is the state of a peer whose debt exceeded the payment threshold.
+type peerGrace struct {
+	since     time.Time // when the debt first exceeded the threshold
+	next      int       // index of the next escalation step
+	throttled bool
+}
+
+type graceLoop struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (l *graceLoop) Close() error {
+	l.cancel()
+	l.wg.Wait()
+	return nil
+}
+
+// StartGraceTracking starts checking the debt of the connected peers against
+// their payment threshold once per interval. The longer the debt of a peer
+// stays above the threshold, the further it is escalated along the ladder:
+// usually reminders are sent first before the peer is throttled and finally
+// disconnected. A peer settling its debt starts over. No steps or a zero
+// interval disable the tracking.
+func (s *Service) StartGraceTracking(ladder []EscalationStep, threshold PaymentThresholdFunc, interval time.Duration) io.Closer {
+	s.grace.mu.Lock()
+	s.grace.ladder = ladder
+	s.grace.threshold = threshold
+	s.grace.peers = make(map[string]*peerGrace)
+	s.grace.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &graceLoop{cancel: cancel}
+	if len(ladder) == 0 || interval <= 0 || threshold == nil {
+		return l
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(interval):
+			}
+
+			if err := s.checkGrace(ctx, s.clock.Now()); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to check payment grace periods")
+			}
+		}
+	}()
+
+	return l
+}
+
+// checkGrace escalates the connected peers whose debt is above the payment
+// threshold. Only the furthest step reached since the last check is taken.
+func (s *Service) checkGrace(ctx context.Context, now time.Time) error {
+	s.peersMu.Lock()
+	lister := s.peers
+	s.peersMu.Unlock()
+
+	s.grace.mu.Lock()
+	thresholdFunc := s.grace.threshold
+	s.grace.mu.Unlock()
+	if lister == nil || s.accounting == nil || thresholdFunc == nil {
+		return nil
+	}
+
+	connected := make(map[string]struct{})
+	for _, p := range lister.Peers() {
+		peer := p.Address
+		connected[peer.String()] = struct{}{}
+
+		debt, err := s.accounting.PeerDebt(peer)
+		if err != nil {
+			return fmt.Errorf("debt of peer %s: %w", peer, err)
+		}
+		threshold := thresholdFunc(peer)
+
+		s.grace.mu.Lock()
+		if debt.Cmp(threshold) <= 0 {
+			delete(s.grace.peers, peer.String())
+			s.grace.mu.Unlock()
+			continue
+		}
+		state, ok := s.grace.peers[peer.String()]
+		if !ok {
+			state = &peerGrace{since: now}
+			s.grace.peers[peer.String()] = state
+		}
+		overdue := now.Sub(state.since)
+		step := -1
+		for i := state.next; i < len(s.grace.ladder) && s.grace.ladder[i].After <= overdue; i++ {
+			step = i
+		}
+		if step < 0 {
+			s.grace.mu.Unlock()
+			continue
+		}
+		state.next = step + 1
+		action := s.grace.ladder[step].Action
+		switch action {
+		case EscalationThrottle:
+			state.throttled = true
+		case EscalationDisconnect:
+			delete(s.grace.peers, peer.String())
+		}
+		s.grace.mu.Unlock()
+
+		s.logger.Debug("payment grace period escalated", "peer_address", peer, "action", action, "debt", debt, "threshold", threshold, "overdue", overdue)
+		if err := s.escalate(ctx, peer, action, debt, threshold, overdue); err != nil {
+			s.logger.Debug("payment grace escalation failed", "peer_address", peer, "action", action, "error", err)
+		}
+	}
+
+	// forget peers which disconnected meanwhile
+	s.grace.mu.Lock()
+	for peer := range s.grace.peers {
+		if _, ok := connected[peer]; !ok {
+			delete(s.grace.peers, peer)
+		}
+	}
+	s.grace.mu.Unlock()
+
+	return nil
+}
+
+func (s *Service) escalate(ctx context.Context, peer swarm.Address, action EscalationAction, debt, threshold *big.Int, overdue time.Duration) error {
+	switch action {
+	case EscalationRemind:
+		if err := s.proto.SendReminder(ctx, peer, debt, threshold, overdue); err != nil {
+			return err
+		}
+		s.publish(events.Event{
+			Type:   events.TypeReminderSent,
+			Peer:   peer,
+			Amount: debt,
+		})
+	case EscalationThrottle:
+		s.metrics.PeersThrottled.Inc()
+		s.logger.Info("throttling peer not settling its debt", "peer_address", peer, "debt", debt, "overdue", overdue)
+	case EscalationDisconnect:
+		chequebookAddress, _, err := s.addressbook.Chequebook(peer)
+		if err != nil {
+			return err
+		}
+		return s.notifyDisconnect(DisconnectNotification{
+			Peer:       peer,
+			Reason:     DisconnectGracePeriodExceeded,
+			Chequebook: chequebookAddress,
+			Debt:       debt,
+		})
+	}
+	return nil
+}
+
+// Throttled reports whether the peer is throttled because its debt stayed
+// above the payment threshold for too long. Components serving requests of
+// peers consult it to slow down peers which do not settle.
+func (s *Service) Throttled(peer swarm.Address) bool {
+	s.grace.mu.Lock()
+	defer s.grace.mu.Unlock()
+	state, ok := s.grace.peers[peer.String()]
+	return ok && state.throttled
+}
+
+// ReceiveReminder is called by the swap protocol if a peer reminds us that
+// our debt exceeded its payment threshold.
+func (s *Service) ReceiveReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error {
+	s.logger.Info("peer reminds us to settle our debt", "peer_address", peer, "debt", debt, "threshold", threshold, "overdue", overdue)
+	s.publish(events.Event{
+		Type:   events.TypeReminderReceived,
+		Peer:   peer,
+		Amount: debt,
+	})
+	return nil
+}
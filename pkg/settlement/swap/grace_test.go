@@ -0,0 +1,213 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// debtObserver is a testObserver reporting the configured debt of peers.
+type debtObserver struct {
+	*testObserver
+	mu   sync.Mutex
+	debt *big.Int
+}
+
+func (o *debtObserver) setDebt(debt int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.debt = big.NewInt(debt)
+}
+
+func (o *debtObserver) PeerDebt(swarm.Address) (*big.Int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return new(big.Int).Set(o.debt), nil
+}
+
+func TestParseEscalationLadder(t *testing.T) {
+	t.Parallel()
+
+	steps, err := swap.ParseEscalationLadder(" disconnect:30m, remind:1m,throttle:10m,remind:5m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []swap.EscalationStep{
+		{After: time.Minute, Action: swap.EscalationRemind},
+		{After: 5 * time.Minute, Action: swap.EscalationRemind},
+		{After: 10 * time.Minute, Action: swap.EscalationThrottle},
+		{After: 30 * time.Minute, Action: swap.EscalationDisconnect},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Fatalf("got steps %v, want %v", steps, want)
+	}
+
+	if steps, err := swap.ParseEscalationLadder(""); err != nil || len(steps) != 0 {
+		t.Fatalf("got steps %v and error %v, want none", steps, err)
+	}
+
+	for _, ladder := range []string{"remind", "ban:1m", "remind:soon", "remind:-1m"} {
+		if _, err := swap.ParseEscalationLadder(ladder); err == nil {
+			t.Fatalf("expected error for ladder %q", ladder)
+		}
+	}
+}
+
+func TestGraceEscalation(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("abcd")
+	store := mockstore.NewStateStore()
+	observer := &debtObserver{testObserver: newTestObserver(), debt: big.NewInt(150)}
+
+	var reminders []time.Duration
+	proto := &swapProtocolMock{
+		sendReminder: func(_ context.Context, p swarm.Address, debt, threshold *big.Int, overdue time.Duration) error {
+			if !p.Equal(peer) {
+				t.Fatalf("reminder sent to %v, want %v", p, peer)
+			}
+			if debt.Cmp(big.NewInt(150)) != 0 || threshold.Cmp(big.NewInt(100)) != 0 {
+				t.Fatalf("got debt %v and threshold %v, want 150 and 100", debt, threshold)
+			}
+			reminders = append(reminders, overdue)
+			return nil
+		},
+	}
+
+	swapService := swap.New(
+		proto,
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		swap.NewAddressbook(store),
+		0,
+		&cashoutMock{},
+		observer,
+		common.Address{},
+	)
+	swapService.SetPeerLister(peerListerMock{{Address: peer}})
+
+	var notifications []swap.DisconnectNotification
+	swapService.SetDisconnectNotifier(swap.DisconnectNotifierFunc(func(n swap.DisconnectNotification) error {
+		notifications = append(notifications, n)
+		return nil
+	}), 0)
+
+	var sent int
+	cancel := swapService.SubscribeEvents(func(e events.Event) {
+		if e.Type == events.TypeReminderSent {
+			sent++
+		}
+	})
+	defer cancel()
+
+	ladder, err := swap.ParseEscalationLadder("remind:1m,throttle:10m,disconnect:30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closer := swapService.StartGraceTracking(ladder, func(swarm.Address) *big.Int {
+		return big.NewInt(100)
+	}, 0)
+	defer closer.Close()
+
+	start := time.Unix(1000, 0)
+	check := func(d time.Duration) {
+		t.Helper()
+		if err := swapService.CheckGrace(context.Background(), start.Add(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check(0)
+	check(30 * time.Second)
+	if len(reminders) != 0 {
+		t.Fatalf("got %d reminders within the grace period, want none", len(reminders))
+	}
+
+	check(time.Minute)
+	check(2 * time.Minute)
+	if !reflect.DeepEqual(reminders, []time.Duration{time.Minute}) {
+		t.Fatalf("got reminders %v, want one after a minute", reminders)
+	}
+	if sent != 1 {
+		t.Fatalf("got %d reminder events, want 1", sent)
+	}
+	if swapService.Throttled(peer) {
+		t.Fatal("peer throttled too early")
+	}
+
+	check(10 * time.Minute)
+	if !swapService.Throttled(peer) {
+		t.Fatal("peer not throttled")
+	}
+
+	// settling the debt starts over
+	observer.setDebt(50)
+	check(11 * time.Minute)
+	if swapService.Throttled(peer) {
+		t.Fatal("peer still throttled after settling")
+	}
+
+	// only the furthest step reached is taken
+	observer.setDebt(150)
+	check(12 * time.Minute)
+	check(42 * time.Minute)
+	if len(reminders) != 1 {
+		t.Fatalf("got %d reminders, want 1", len(reminders))
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("got %d disconnect notifications, want 1", len(notifications))
+	}
+	if n := notifications[0]; !n.Peer.Equal(peer) || n.Reason != swap.DisconnectGracePeriodExceeded || n.Debt.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("unexpected disconnect notification %+v", n)
+	}
+}
+
+func TestReceiveReminder(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	peer := swarm.MustParseHexAddress("abcd")
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		swap.NewAddressbook(store),
+		0,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	var got []events.Event
+	cancel := swapService.SubscribeEvents(func(e events.Event) {
+		got = append(got, e)
+	})
+	defer cancel()
+
+	if err := swapService.ReceiveReminder(context.Background(), peer, big.NewInt(150), big.NewInt(100), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Type != events.TypeReminderReceived || !got[0].Peer.Equal(peer) || got[0].Amount.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("unexpected events %+v", got)
+	}
+}
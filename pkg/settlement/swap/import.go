@@ -0,0 +1,173 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// PeerImport is the swap state of a peer carried over from another node,
+// e.g. after data loss or when migrating from another implementation.
+type PeerImport struct {
+	// Beneficiary of the peer, needed to import the sent cumulative payout
+	// if the peer did not announce its beneficiary yet.
+	Beneficiary *common.Address
+	// SentCumulativePayout is the cumulative payout of the last cheque sent to the peer.
+	SentCumulativePayout *big.Int
+	// ReceivedCheque is the last cheque received from the peer.
+	ReceivedCheque *chequebook.SignedCheque
+}
+
+// PeerImportEntry is the swap state of a peer to import with ImportPeers.
+type PeerImportEntry struct {
+	Peer  swarm.Address
+	State PeerImport
+}
+
+// ImportPeer imports the swap state of the peer so that settlement resumes
+// where the other node left off. Cheques sent afterwards continue from the
+// sent cumulative payout and the received cheque can be cashed. Both are
+// validated against the amounts the chequebooks already paid out on chain.
+// Importing the same state again has no effect.
+func (s *Service) ImportPeer(ctx context.Context, peer swarm.Address, state PeerImport) error {
+	if state.SentCumulativePayout != nil {
+		if err := s.importSent(ctx, peer, state.Beneficiary, state.SentCumulativePayout); err != nil {
+			return fmt.Errorf("sent cumulative payout: %w", err)
+		}
+	}
+	if state.ReceivedCheque != nil {
+		if err := s.importReceived(ctx, peer, state.ReceivedCheque); err != nil {
+			return fmt.Errorf("received cheque: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) importSent(ctx context.Context, peer swarm.Address, beneficiary *common.Address, cumulativePayout *big.Int) error {
+	if s.chequebook == nil {
+		return ErrNoChequebook
+	}
+
+	known, ok, err := s.addressbook.Beneficiary(peer)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ok && beneficiary != nil && known != *beneficiary:
+		return fmt.Errorf("peer announced beneficiary %x: %w", known, chequebook.ErrWrongBeneficiary)
+	case !ok && beneficiary == nil:
+		return ErrUnknownBeneficary
+	case !ok:
+		if err := s.addressbook.PutBeneficiary(peer, *beneficiary); err != nil {
+			return err
+		}
+		known = *beneficiary
+	}
+
+	tokens, err := chequebook.NewTokens(cumulativePayout)
+	if err != nil {
+		return err
+	}
+	_, err = s.chequebook.ImportLastCheque(ctx, known, tokens)
+	return err
+}
+
+// ImportPeers imports the swap state of the peers like ImportPeer and returns
+// the errors in the order of the peers. The received cheques of all peers are
+// verified and imported in one batch before the sent cumulative payouts.
+func (s *Service) ImportPeers(ctx context.Context, imports []PeerImportEntry) []error {
+	errs := make([]error, len(imports))
+
+	var (
+		cheques []*chequebook.SignedCheque
+		indexes []int
+	)
+	for i, entry := range imports {
+		cheque := entry.State.ReceivedCheque
+		if cheque == nil {
+			continue
+		}
+		if _, err := s.importedChequebook(entry.Peer, cheque); err != nil {
+			errs[i] = fmt.Errorf("received cheque: %w", err)
+			continue
+		}
+		cheques = append(cheques, cheque)
+		indexes = append(indexes, i)
+	}
+
+	if len(cheques) > 0 {
+		start := time.Now()
+		chequeErrs := s.chequeStore.ImportCheques(ctx, cheques)
+		s.metrics.ChequeImportTime.Observe(time.Since(start).Seconds())
+
+		for j, i := range indexes {
+			err := chequeErrs[j]
+			if err == nil {
+				err = s.rememberImportedChequebook(imports[i].Peer, cheques[j])
+			}
+			if err != nil {
+				s.metrics.ChequeImportsFailed.Inc()
+				errs[i] = fmt.Errorf("received cheque: %w", err)
+				continue
+			}
+			s.metrics.ChequesImported.Inc()
+		}
+	}
+
+	for i, entry := range imports {
+		if errs[i] != nil || entry.State.SentCumulativePayout == nil {
+			continue
+		}
+		if err := s.importSent(ctx, entry.Peer, entry.State.Beneficiary, entry.State.SentCumulativePayout); err != nil {
+			errs[i] = fmt.Errorf("sent cumulative payout: %w", err)
+		}
+	}
+
+	return errs
+}
+
+func (s *Service) importReceived(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque) error {
+	if _, err := s.importedChequebook(peer, cheque); err != nil {
+		return err
+	}
+
+	if err := s.chequeStore.ImportCheque(ctx, cheque); err != nil {
+		s.metrics.ChequeImportsFailed.Inc()
+		return err
+	}
+	s.metrics.ChequesImported.Inc()
+
+	return s.rememberImportedChequebook(peer, cheque)
+}
+
+// importedChequebook checks that the imported cheque is from the chequebook
+// known for the peer. It reports whether the chequebook of the peer is known.
+func (s *Service) importedChequebook(peer swarm.Address, cheque *chequebook.SignedCheque) (bool, error) {
+	known, ok, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return false, err
+	}
+	if ok && known != cheque.Chequebook {
+		return true, ErrWrongChequebook
+	}
+	return ok, nil
+}
+
+// rememberImportedChequebook stores the chequebook of the imported cheque as
+// the one of the peer if it did not have one yet.
+func (s *Service) rememberImportedChequebook(peer swarm.Address, cheque *chequebook.SignedCheque) error {
+	ok, err := s.importedChequebook(peer, cheque)
+	if err != nil || ok {
+		return err
+	}
+	return s.addressbook.PutChequebook(peer, cheque.Chequebook)
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestImportPeer(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+	cumulativePayout := big.NewInt(500)
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      common.HexToAddress("0xff"),
+			CumulativePayout: big.NewInt(300),
+		},
+	}
+
+	var importedSent, importedReceived bool
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(mockchequebook.WithImportLastChequeFunc(func(ctx context.Context, b common.Address, c *big.Int) (*chequebook.SignedCheque, error) {
+			if b != beneficiary || c.Cmp(cumulativePayout) != 0 {
+				t.Fatalf("imported sent %d to %x, want %d to %x", c, b, cumulativePayout, beneficiary)
+			}
+			importedSent = true
+			return &chequebook.SignedCheque{}, nil
+		})),
+		mockchequestore.NewChequeStore(mockchequestore.WithImportChequeFunc(func(ctx context.Context, c *chequebook.SignedCheque) error {
+			if !c.Equal(cheque) {
+				t.Fatalf("imported cheque %v, want %v", c, cheque)
+			}
+			importedReceived = true
+			return nil
+		})),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+
+	// the beneficiary is needed if the peer never announced it
+	err := swapService.ImportPeer(context.Background(), peer, swap.PeerImport{SentCumulativePayout: cumulativePayout})
+	if !errors.Is(err, swap.ErrUnknownBeneficary) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrUnknownBeneficary)
+	}
+
+	err = swapService.ImportPeer(context.Background(), peer, swap.PeerImport{
+		Beneficiary:          &beneficiary,
+		SentCumulativePayout: cumulativePayout,
+		ReceivedCheque:       cheque,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !importedSent || !importedReceived {
+		t.Fatalf("imported sent %t, received %t", importedSent, importedReceived)
+	}
+
+	if got, known, err := addressbook.Beneficiary(peer); err != nil || !known || got != beneficiary {
+		t.Fatalf("got beneficiary %x (known %t, err %v), want %x", got, known, err, beneficiary)
+	}
+	if got, known, err := addressbook.Chequebook(peer); err != nil || !known || got != chequebookAddress {
+		t.Fatalf("got chequebook %x (known %t, err %v), want %x", got, known, err, chequebookAddress)
+	}
+
+	otherBeneficiary := common.HexToAddress("0xbf")
+	err = swapService.ImportPeer(context.Background(), peer, swap.PeerImport{
+		Beneficiary:          &otherBeneficiary,
+		SentCumulativePayout: cumulativePayout,
+	})
+	if !errors.Is(err, chequebook.ErrWrongBeneficiary) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrWrongBeneficiary)
+	}
+
+	otherCheque := *cheque
+	otherCheque.Chequebook = common.HexToAddress("0xcc")
+	err = swapService.ImportPeer(context.Background(), peer, swap.PeerImport{ReceivedCheque: &otherCheque})
+	if !errors.Is(err, swap.ErrWrongChequebook) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrWrongChequebook)
+	}
+}
+
+func TestImportPeers(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer1 := swarm.MustParseHexAddress("abcd")
+	peer2 := swarm.MustParseHexAddress("abce")
+	peer3 := swarm.MustParseHexAddress("abcf")
+
+	cheque := func(chequebookAddress common.Address) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      common.HexToAddress("0xff"),
+				CumulativePayout: big.NewInt(300),
+			},
+		}
+	}
+	rejected := common.HexToAddress("0xcc")
+
+	var batches int
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithImportChequesFunc(func(ctx context.Context, cheques []*chequebook.SignedCheque) []error {
+			batches++
+			errs := make([]error, len(cheques))
+			for i, c := range cheques {
+				if c.Chequebook == rejected {
+					errs[i] = chequebook.ErrNotDeployedByFactory
+				}
+			}
+			return errs
+		})),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+
+	errs := swapService.ImportPeers(context.Background(), []swap.PeerImportEntry{
+		{Peer: peer1, State: swap.PeerImport{ReceivedCheque: cheque(common.HexToAddress("0xca"))}},
+		{Peer: peer2, State: swap.PeerImport{ReceivedCheque: cheque(rejected)}},
+		{Peer: peer3, State: swap.PeerImport{ReceivedCheque: cheque(common.HexToAddress("0xcb"))}},
+	})
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("got errors %v, want none for the accepted cheques", errs)
+	}
+	if !errors.Is(errs[1], chequebook.ErrNotDeployedByFactory) {
+		t.Fatalf("got error %v, want %v", errs[1], chequebook.ErrNotDeployedByFactory)
+	}
+	if batches != 1 {
+		t.Fatalf("got %d batches, want 1", batches)
+	}
+
+	if got, known, err := addressbook.Chequebook(peer1); err != nil || !known || got != common.HexToAddress("0xca") {
+		t.Fatalf("got chequebook %x (known %t, err %v), want %x", got, known, err, common.HexToAddress("0xca"))
+	}
+	if _, known, err := addressbook.Chequebook(peer2); err != nil || known {
+		t.Fatalf("remembered chequebook of rejected cheque (known %t, err %v)", known, err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import "github.com/ethersphere/bee/pkg/settlement/keylayout"
+
+// KeyLayout describes the statestore keys of the swap service and its
+// addressbook.
+var KeyLayout = []keylayout.Key{
+	{
+		Pattern:     peerPrefix + "<peer>",
+		Value:       "common.Address",
+		Version:     1,
+		Description: "chequebook of the peer",
+	},
+	{
+		Pattern:     peerChequebookPrefix + "<chequebook>",
+		Value:       "swarm.Address",
+		Version:     2,
+		Description: "peer owning the chequebook",
+	},
+	{
+		Pattern:     peerBeneficiaryPrefix + "<peer>",
+		Value:       "common.Address",
+		Version:     1,
+		Description: "beneficiary of the cheques issued to the peer",
+	},
+	{
+		Pattern:     beneficiaryPeerPrefix + "<beneficiary>",
+		Value:       "swarm.Address",
+		Version:     2,
+		Description: "peer of the beneficiary",
+	},
+	{
+		Pattern:     deductedForPeerPrefix + "<peer>",
+		Value:       "struct{}",
+		Version:     1,
+		Description: "marks that the peer was charged the deduction of its first cheque",
+	},
+	{
+		Pattern:     deductedByPeerPrefix + "<peer>",
+		Value:       "struct{}",
+		Version:     1,
+		Description: "marks that the peer charged the deduction of our first cheque",
+	},
+	{
+		Pattern:     payoutBeneficiaryPrefix + "<peer>",
+		Value:       "common.Address",
+		Version:     1,
+		Description: "payout beneficiary announced by the peer",
+	},
+	{
+		Pattern:     payoutBeneficiaryPeerPrefix + "<beneficiary>",
+		Value:       "swarm.Address",
+		Version:     1,
+		Description: "peer which announced the payout beneficiary",
+	},
+	{
+		Pattern:     receiptPrefix + "<beneficiary>",
+		Value:       "chequebook.Receipt",
+		Version:     1,
+		Description: "last receipt for a cheque sent to the beneficiary",
+	},
+	{
+		Pattern:     announcedChequebookPrefix + "<peer>",
+		Value:       "common.Address",
+		Version:     1,
+		Description: "chequebook last announced to the peer",
+	},
+	{
+		Pattern:     statementPrefix + "<beneficiary>",
+		Value:       "chequebook.Statement",
+		Version:     1,
+		Description: "last statement made for the beneficiary",
+	},
+	{
+		Pattern:     peerStatementPrefix + "<chequebook>",
+		Value:       "chequebook.Statement",
+		Version:     1,
+		Description: "last statement received for the chequebook",
+	},
+	{
+		Pattern:     disputePrefix + "<index>",
+		Value:       "swap.Dispute",
+		Version:     1,
+		Description: "settlement dispute with a peer",
+	},
+	{
+		Pattern:     disputeSeqKey,
+		Value:       "uint64",
+		Version:     1,
+		Description: "id of the last opened dispute",
+	},
+	{
+		Pattern:     withdrawWatchBlockKey,
+		Value:       "logcursor.Cursor",
+		Version:     1,
+		Description: "last block scanned for withdrawals from the chequebooks of peers",
+	},
+	{
+		Pattern:     lastSeenKeyPrefix + "<peer>",
+		Value:       "int64",
+		Version:     1,
+		Description: "unix time the peer was last seen",
+	},
+	{
+		Pattern:     archivePeerKeyPrefix + "<peer>",
+		Value:       "swap.PeerArchive",
+		Version:     1,
+		Description: "archived settlement records of a stale peer",
+	},
+	{
+		Pattern:     archiveBeneficiaryKeyPrefix + "<beneficiary>",
+		Value:       "swarm.Address",
+		Version:     1,
+		Description: "archived peer of the beneficiary",
+	},
+}
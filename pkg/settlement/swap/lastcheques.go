@@ -0,0 +1,190 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// ChequeOrder is the order in which the last cheques of peers are listed.
+type ChequeOrder string
+
+const (
+	// OrderPeer lists the cheques by the overlay address of the peer.
+	OrderPeer ChequeOrder = "peer"
+	// OrderPayout lists the cheques by the cumulative payout of the last
+	// sent cheque and then of the last received cheque, highest first.
+	OrderPayout ChequeOrder = "payout"
+)
+
+var (
+	// ErrInvalidChequeOrder is returned if the order of the cheques is unknown.
+	ErrInvalidChequeOrder = errors.New("invalid cheque order")
+	// ErrInvalidCursor is returned if the cursor was not returned for the same order.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// PeerCheques are the last cheques sent to and received from a peer.
+type PeerCheques struct {
+	Peer         string
+	LastSent     *chequebook.SignedCheque // nil if no cheque was sent
+	LastReceived *chequebook.SignedCheque // nil if no cheque was received
+}
+
+// PeerChequesPage is a page of the last cheques of peers in a stable order.
+type PeerChequesPage struct {
+	Cheques []PeerCheques
+	// Next is the cursor of the following page, empty on the last page.
+	Next string
+}
+
+// chequeCursor is the position after which a page starts. It holds the sort
+// key of the last listed peer so that pages stay stable while cheques are
+// sent or received between requests.
+type chequeCursor struct {
+	Order    ChequeOrder `json:"o"`
+	Peer     string      `json:"p"`
+	Sent     *big.Int    `json:"s,omitempty"`
+	Received *big.Int    `json:"r,omitempty"`
+}
+
+func newChequeCursor(order ChequeOrder, c PeerCheques) chequeCursor {
+	cursor := chequeCursor{Order: order, Peer: c.Peer}
+	if order == OrderPayout {
+		cursor.Sent = payout(c.LastSent)
+		cursor.Received = payout(c.LastReceived)
+	}
+	return cursor
+}
+
+func (c chequeCursor) encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeChequeCursor(order ChequeOrder, s string) (chequeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return chequeCursor{}, ErrInvalidCursor
+	}
+	var cursor chequeCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return chequeCursor{}, ErrInvalidCursor
+	}
+	if cursor.Order != order || (order == OrderPayout && (cursor.Sent == nil || cursor.Received == nil)) {
+		return chequeCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// before reports whether a cursor sorts before b.
+func (a chequeCursor) before(b chequeCursor) bool {
+	if a.Order == OrderPayout {
+		if c := a.Sent.Cmp(b.Sent); c != 0 {
+			return c > 0
+		}
+		if c := a.Received.Cmp(b.Received); c != 0 {
+			return c > 0
+		}
+	}
+	return a.Peer < b.Peer
+}
+
+// payout returns the cumulative payout of the cheque, zero if there is none.
+func payout(cheque *chequebook.SignedCheque) *big.Int {
+	if cheque == nil || cheque.CumulativePayout == nil {
+		return big.NewInt(0)
+	}
+	return cheque.CumulativePayout
+}
+
+// OrderCheques merges the last sent and received cheques by peer and returns
+// at most limit of them in the order following the cursor. A limit of zero
+// returns all of them and an empty cursor starts with the first page.
+func OrderCheques(sent, received map[string]*chequebook.SignedCheque, order ChequeOrder, cursor string, limit int) (*PeerChequesPage, error) {
+	if order == "" {
+		order = OrderPeer
+	}
+	if order != OrderPeer && order != OrderPayout {
+		return nil, ErrInvalidChequeOrder
+	}
+
+	byPeer := make(map[string]*PeerCheques, len(sent)+len(received))
+	for peer, cheque := range sent {
+		byPeer[peer] = &PeerCheques{Peer: peer, LastSent: cheque}
+	}
+	for peer, cheque := range received {
+		if c, ok := byPeer[peer]; ok {
+			c.LastReceived = cheque
+		} else {
+			byPeer[peer] = &PeerCheques{Peer: peer, LastReceived: cheque}
+		}
+	}
+
+	type keyed struct {
+		key     chequeCursor
+		cheques PeerCheques
+	}
+	all := make([]keyed, 0, len(byPeer))
+	for _, c := range byPeer {
+		all = append(all, keyed{key: newChequeCursor(order, *c), cheques: *c})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].key.before(all[j].key)
+	})
+
+	start := 0
+	if cursor != "" {
+		after, err := decodeChequeCursor(order, cursor)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(all), func(i int) bool {
+			return after.before(all[i].key)
+		})
+	}
+
+	end := len(all)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	page := &PeerChequesPage{Cheques: make([]PeerCheques, 0, end-start)}
+	for _, c := range all[start:end] {
+		page.Cheques = append(page.Cheques, c.cheques)
+	}
+	if end < len(all) {
+		next, err := all[end-1].key.encode()
+		if err != nil {
+			return nil, err
+		}
+		page.Next = next
+	}
+	return page, nil
+}
+
+// LastCheques returns the last cheques sent to and received from peers in the
+// order following the cursor, at most limit of them if limit is positive.
+func (s *Service) LastCheques(ctx context.Context, order ChequeOrder, cursor string, limit int) (*PeerChequesPage, error) {
+	sent, err := s.LastSentCheques(ctx)
+	if err != nil && !errors.Is(err, ErrNoChequebook) {
+		return nil, err
+	}
+	received, err := s.LastReceivedCheques()
+	if err != nil {
+		return nil, err
+	}
+	return OrderCheques(sent, received, order, cursor, limit)
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestOrderCheques(t *testing.T) {
+	t.Parallel()
+
+	cheque := func(payout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{Cheque: chequebook.Cheque{CumulativePayout: big.NewInt(payout)}}
+	}
+	peers := func(page *swap.PeerChequesPage) (peers []string) {
+		for _, c := range page.Cheques {
+			peers = append(peers, c.Peer)
+		}
+		return peers
+	}
+
+	sent := map[string]*chequebook.SignedCheque{"a": cheque(10), "b": cheque(30), "c": cheque(30)}
+	received := map[string]*chequebook.SignedCheque{"a": cheque(5), "d": cheque(50), "e": cheque(20)}
+
+	t.Run("peer", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := swap.OrderCheques(sent, received, "", "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(peers(page), want) {
+			t.Fatalf("got peers %v, want %v", peers(page), want)
+		}
+		if page.Next != "" {
+			t.Fatalf("got next cursor %q on the last page", page.Next)
+		}
+		if page.Cheques[0].LastSent != sent["a"] || page.Cheques[0].LastReceived != received["a"] {
+			t.Fatal("cheques of peer not merged")
+		}
+	})
+
+	t.Run("payout pages", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := swap.OrderCheques(sent, received, swap.OrderPayout, "", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"b", "c"}; !reflect.DeepEqual(peers(page), want) {
+			t.Fatalf("got peers %v, want %v", peers(page), want)
+		}
+
+		// a cheque sent between the pages does not repeat or skip peers
+		changed := map[string]*chequebook.SignedCheque{"a": cheque(10), "b": cheque(30), "c": cheque(30), "d": cheque(100)}
+		page, err = swap.OrderCheques(changed, received, swap.OrderPayout, page.Next, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a", "e"}; !reflect.DeepEqual(peers(page), want) {
+			t.Fatalf("got peers %v, want %v", peers(page), want)
+		}
+		if page.Next != "" {
+			t.Fatalf("got next cursor %q on the last page", page.Next)
+		}
+	})
+
+	t.Run("cursor of other order", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := swap.OrderCheques(sent, received, swap.OrderPeer, "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = swap.OrderCheques(sent, received, swap.OrderPayout, page.Next, 1)
+		if !errors.Is(err, swap.ErrInvalidCursor) {
+			t.Fatalf("got error %v, want %v", err, swap.ErrInvalidCursor)
+		}
+	})
+
+	t.Run("invalid order", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := swap.OrderCheques(sent, received, "age", "", 0)
+		if !errors.Is(err, swap.ErrInvalidChequeOrder) {
+			t.Fatalf("got error %v, want %v", err, swap.ErrInvalidChequeOrder)
+		}
+	})
+}
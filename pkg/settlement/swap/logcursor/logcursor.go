@@ -0,0 +1,243 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package logcursor scans the chain for logs in pages of blocks and persists
+// how far it got, so that a scan resumes after a restart where it stopped
+// and fills the gap of the blocks confirmed in the meantime. A reorg below
+// the cursor is detected from the hash of the last scanned block and the
+// blocks before it are scanned again, so log handlers have to be idempotent.
+// Consumers which keep state derived from the logs are told the rescanned
+// range first, so that they can roll back the state of orphaned logs.
+package logcursor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const (
+	// DefaultPage is the default number of blocks filtered for logs at once.
+	DefaultPage = 5000
+	// DefaultTail is the default number of blocks tailed from the tip of the chain.
+	DefaultTail = 4
+	// DefaultReorgDepth is the default number of blocks scanned again after a reorg.
+	DefaultReorgDepth = 64
+)
+
+// Cursor is the last block which was scanned.
+type Cursor struct {
+	Block uint64      `json:"block"`
+	Hash  common.Hash `json:"hash"` // zero for cursors stored before reorgs were detected
+}
+
+// UnmarshalJSON also accepts a bare block number as stored by the scanners
+// before they used cursors.
+func (c *Cursor) UnmarshalJSON(data []byte) error {
+	if block, err := strconv.ParseUint(string(data), 10, 64); err == nil {
+		*c = Cursor{Block: block}
+		return nil
+	}
+	type cursor Cursor
+	return json.Unmarshal(data, (*cursor)(c))
+}
+
+// StartFunc returns the first block to scan if no cursor is stored yet,
+// given the last confirmed block.
+type StartFunc func(ctx context.Context, confirmed uint64) (uint64, error)
+
+// FromGenesis starts scanning at the first block of the chain.
+func FromGenesis(context.Context, uint64) (uint64, error) {
+	return 0, nil
+}
+
+// FromHead starts scanning after the last confirmed block, for scanners not
+// interested in the history before they were started.
+func FromHead(_ context.Context, confirmed uint64) (uint64, error) {
+	return confirmed + 1, nil
+}
+
+// HandleFunc handles the logs found in a page of blocks, in the order they
+// happened. Logs removed by a reorg are not passed. The cursor is only
+// advanced past the page if the function succeeds.
+type HandleFunc func(ctx context.Context, logs []types.Log) error
+
+// RewindFunc is called with the range of already scanned blocks which are
+// scanned again after a reorg, before their logs are handled again. The
+// cursor is only rewound if the function succeeds.
+type RewindFunc func(ctx context.Context, from, to uint64) error
+
+// Options configures a Scanner. Zero values select the defaults.
+type Options struct {
+	Page       uint64     // number of blocks filtered for logs at once
+	Tail       uint64     // number of blocks tailed from the tip of the chain
+	ReorgDepth uint64     // number of blocks scanned again after a reorg
+	Start      StartFunc  // first block to scan without a stored cursor, FromGenesis by default
+	Rewind     RewindFunc // informed about the blocks scanned again after a reorg, optional
+}
+
+// Scanner scans the chain for logs and persists its cursor in the state store.
+type Scanner struct {
+	name    string
+	backend transaction.Backend
+	store   storage.StateStorer
+	key     string
+	o       Options
+}
+
+// New creates a Scanner named name for metrics which stores its cursor under
+// key.
+func New(name string, backend transaction.Backend, store storage.StateStorer, key string, o Options) *Scanner {
+	if o.Page == 0 {
+		o.Page = DefaultPage
+	}
+	if o.Tail == 0 {
+		o.Tail = DefaultTail
+	}
+	if o.ReorgDepth == 0 {
+		o.ReorgDepth = DefaultReorgDepth
+	}
+	if o.Start == nil {
+		o.Start = FromGenesis
+	}
+	return &Scanner{
+		name:    name,
+		backend: backend,
+		store:   store,
+		key:     key,
+		o:       o,
+	}
+}
+
+// Cursor returns the stored cursor, if any.
+func (s *Scanner) Cursor() (cursor Cursor, ok bool, err error) {
+	err = s.store.Get(s.key, &cursor)
+	if errors.Is(err, storage.ErrNotFound) {
+		return Cursor{}, false, nil
+	}
+	if err != nil {
+		return Cursor{}, false, err
+	}
+	return cursor, true, nil
+}
+
+// Scan filters the confirmed blocks after the cursor for logs matching the
+// query and passes them to handle page by page. The block range of the query
+// is set by the scanner. A query without addresses matches no logs, the
+// cursor is advanced without filtering.
+func (s *Scanner) Scan(ctx context.Context, query ethereum.FilterQuery, handle HandleFunc) error {
+	head, err := s.backend.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head < s.o.Tail {
+		return nil
+	}
+	to := head - s.o.Tail
+
+	from, rewound, err := s.start(ctx, to)
+	if err != nil {
+		return err
+	}
+	if rewound != nil && s.o.Rewind != nil {
+		if err := s.o.Rewind(ctx, from, rewound.Block); err != nil {
+			return fmt.Errorf("rewind blocks %d to %d: %w", from, rewound.Block, err)
+		}
+	}
+	if from > to {
+		metrics.Lag.WithLabelValues(s.name).Set(0)
+		// remember where the scan started for scanners starting from the head
+		if _, ok, err := s.Cursor(); err != nil || ok {
+			return err
+		}
+		return s.put(ctx, to)
+	}
+
+	for from <= to {
+		pageTo := to
+		if pageTo-from >= s.o.Page {
+			pageTo = from + s.o.Page - 1
+		}
+
+		if len(query.Addresses) > 0 {
+			query.FromBlock = new(big.Int).SetUint64(from)
+			query.ToBlock = new(big.Int).SetUint64(pageTo)
+			logs, err := s.backend.FilterLogs(ctx, query)
+			if err != nil {
+				return fmt.Errorf("filter logs: %w", err)
+			}
+
+			found := logs[:0]
+			for _, log := range logs {
+				if !log.Removed {
+					found = append(found, log)
+				}
+			}
+			metrics.Logs.WithLabelValues(s.name).Add(float64(len(found)))
+			if err := handle(ctx, found); err != nil {
+				return err
+			}
+		}
+
+		// progress is stored after every page so an interrupted scan can be resumed
+		if err := s.put(ctx, pageTo); err != nil {
+			return err
+		}
+		metrics.Blocks.WithLabelValues(s.name).Add(float64(pageTo - from + 1))
+		metrics.Lag.WithLabelValues(s.name).Set(float64(to - pageTo))
+
+		from = pageTo + 1
+	}
+
+	return nil
+}
+
+// start returns the first block to scan. If the last scanned block was
+// reorged, the scan starts ReorgDepth blocks before it and the reorged cursor
+// is returned.
+func (s *Scanner) start(ctx context.Context, confirmed uint64) (uint64, *Cursor, error) {
+	cursor, ok, err := s.Cursor()
+	if err != nil {
+		return 0, nil, err
+	}
+	if !ok {
+		from, err := s.o.Start(ctx, confirmed)
+		return from, nil, err
+	}
+	if cursor.Hash == (common.Hash{}) {
+		return cursor.Block + 1, nil, nil
+	}
+
+	header, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(cursor.Block))
+	if err != nil {
+		return 0, nil, fmt.Errorf("header of block %d: %w", cursor.Block, err)
+	}
+	if header.Hash() == cursor.Hash {
+		return cursor.Block + 1, nil, nil
+	}
+
+	metrics.Reorgs.WithLabelValues(s.name).Inc()
+	if cursor.Block < s.o.ReorgDepth {
+		return 0, &cursor, nil
+	}
+	return cursor.Block - s.o.ReorgDepth + 1, &cursor, nil
+}
+
+// put stores the cursor at the block together with the hash of the block.
+func (s *Scanner) put(ctx context.Context, block uint64) error {
+	header, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return fmt.Errorf("header of block %d: %w", block, err)
+	}
+	return s.store.Put(s.key, Cursor{Block: block, Hash: header.Hash()})
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logcursor_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/logcursor"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+)
+
+const cursorKey = "test_cursor"
+
+// chain is a backend whose blocks are identified by their number and a fork.
+type chain struct {
+	head    uint64
+	fork    uint64 // blocks from forkAt on are hashed with the fork
+	forkAt  uint64
+	queries []ethereum.FilterQuery
+	logs    []types.Log
+}
+
+func (c *chain) options() []backendmock.Option {
+	return []backendmock.Option{
+		backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+			return c.head, nil
+		}),
+		backendmock.WithHeaderbyNumberFunc(func(_ context.Context, number *big.Int) (*types.Header, error) {
+			header := &types.Header{Number: number}
+			if c.forkAt > 0 && number.Uint64() >= c.forkAt {
+				header.Extra = new(big.Int).SetUint64(c.fork).Bytes()
+			}
+			return header, nil
+		}),
+		backendmock.WithFilterLogsFunc(func(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+			c.queries = append(c.queries, query)
+			var logs []types.Log
+			for _, l := range c.logs {
+				if l.BlockNumber >= query.FromBlock.Uint64() && l.BlockNumber <= query.ToBlock.Uint64() {
+					logs = append(logs, l)
+				}
+			}
+			return logs, nil
+		}),
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	query := ethereum.FilterQuery{Addresses: []common.Address{common.HexToAddress("0xaa")}}
+
+	t.Run("pages", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{
+			head: 104,
+			logs: []types.Log{{BlockNumber: 30}, {BlockNumber: 70, Removed: true}, {BlockNumber: 90}},
+		}
+		store := storemock.NewStateStore()
+		scanner := logcursor.New("test", backendmock.New(c.options()...), store, cursorKey, logcursor.Options{Page: 40})
+
+		var found []uint64
+		err := scanner.Scan(ctx, query, func(_ context.Context, logs []types.Log) error {
+			for _, l := range logs {
+				found = append(found, l.BlockNumber)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(c.queries) != 3 {
+			t.Fatalf("got %d queries, want 3", len(c.queries))
+		}
+		if from, to := c.queries[2].FromBlock.Uint64(), c.queries[2].ToBlock.Uint64(); from != 80 || to != 100 {
+			t.Fatalf("got last page %d to %d, want 80 to 100", from, to)
+		}
+		if len(found) != 2 || found[0] != 30 || found[1] != 90 {
+			t.Fatalf("got logs of blocks %v, want [30 90]", found)
+		}
+
+		cursor, ok, err := scanner.Cursor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || cursor.Block != 100 {
+			t.Fatalf("got cursor %+v, want block 100", cursor)
+		}
+	})
+
+	t.Run("resume from legacy cursor", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{head: 104}
+		store := storemock.NewStateStore()
+		if err := store.Put(cursorKey, uint64(90)); err != nil {
+			t.Fatal(err)
+		}
+		scanner := logcursor.New("test", backendmock.New(c.options()...), store, cursorKey, logcursor.Options{})
+
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.queries) != 1 || c.queries[0].FromBlock.Uint64() != 91 {
+			t.Fatalf("got queries %v, want one from block 91", c.queries)
+		}
+	})
+
+	t.Run("from head", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{head: 104}
+		scanner := logcursor.New("test", backendmock.New(c.options()...), storemock.NewStateStore(), cursorKey, logcursor.Options{
+			Start: logcursor.FromHead,
+		})
+
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.queries) != 0 {
+			t.Fatalf("got %d queries, want none", len(c.queries))
+		}
+
+		c.head = 110
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.queries) != 1 || c.queries[0].FromBlock.Uint64() != 101 || c.queries[0].ToBlock.Uint64() != 106 {
+			t.Fatalf("got queries %v, want one from block 101 to 106", c.queries)
+		}
+	})
+
+	t.Run("reorg", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{head: 104}
+		var (
+			rewound   [][2]uint64
+			rewindErr error
+		)
+		scanner := logcursor.New("test", backendmock.New(c.options()...), storemock.NewStateStore(), cursorKey, logcursor.Options{
+			ReorgDepth: 10,
+			Rewind: func(_ context.Context, from, to uint64) error {
+				rewound = append(rewound, [2]uint64{from, to})
+				return rewindErr
+			},
+		})
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(rewound) != 0 {
+			t.Fatalf("got rewinds %v without a reorg", rewound)
+		}
+
+		// the last scanned block is replaced
+		c.fork, c.forkAt = 1, 98
+		c.head = 110
+
+		// the cursor is kept if the rewind fails
+		rewindErr = errors.New("rewind")
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); !errors.Is(err, rewindErr) {
+			t.Fatalf("got error %v, want %v", err, rewindErr)
+		}
+		if len(c.queries) != 1 {
+			t.Fatalf("got %d queries after a failed rewind, want 1", len(c.queries))
+		}
+
+		rewindErr = nil
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.queries) != 2 || c.queries[1].FromBlock.Uint64() != 91 || c.queries[1].ToBlock.Uint64() != 106 {
+			t.Fatalf("got queries %v, want the second from block 91 to 106", c.queries)
+		}
+		if len(rewound) != 2 || rewound[1] != [2]uint64{91, 100} {
+			t.Fatalf("got rewinds %v, want blocks 91 to 100", rewound)
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{head: 104}
+		scanner := logcursor.New("test", backendmock.New(c.options()...), storemock.NewStateStore(), cursorKey, logcursor.Options{})
+
+		errHandle := errors.New("handle")
+		if err := scanner.Scan(ctx, query, func(context.Context, []types.Log) error { return errHandle }); !errors.Is(err, errHandle) {
+			t.Fatalf("got error %v, want %v", err, errHandle)
+		}
+		if _, ok, err := scanner.Cursor(); err != nil || ok {
+			t.Fatalf("cursor advanced past a failed page: %v %v", ok, err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logcursor
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics are shared by all scanners and labeled by their name.
+var metrics = newMetrics()
+
+type scannerMetrics struct {
+	Blocks *prometheus.CounterVec
+	Logs   *prometheus.CounterVec
+	Reorgs *prometheus.CounterVec
+	Lag    *prometheus.GaugeVec
+}
+
+func newMetrics() scannerMetrics {
+	subsystem := "swap_log_scanner"
+
+	return scannerMetrics{
+		Blocks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "blocks",
+			Help:      "Number of blocks scanned for logs",
+		}, []string{"scanner"}),
+		Logs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "logs",
+			Help:      "Number of logs found",
+		}, []string{"scanner"}),
+		Reorgs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reorgs",
+			Help:      "Number of reorgs of the last scanned block",
+		}, []string{"scanner"}),
+		Lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "lag",
+			Help:      "Number of confirmed blocks not yet scanned",
+		}, []string{"scanner"}),
+	}
+}
+
+// Metrics returns the metrics of all scanners.
+func Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(metrics)
+}
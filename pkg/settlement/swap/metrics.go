@@ -15,7 +15,23 @@ type metrics struct {
 	ChequesReceived  prometheus.Counter
 	ChequesSent      prometheus.Counter
 	ChequesRejected  prometheus.Counter
+	ChequesBounced   prometheus.Counter
+	Cashouts         prometheus.Counter
 	AvailableBalance prometheus.Gauge
+
+	DisconnectNotifications prometheus.Counter
+	RemindersSent           prometheus.Counter
+	RemindersReceived       prometheus.Counter
+	PeersThrottled          prometheus.Counter
+
+	ChequesImported     prometheus.Counter
+	ChequeImportsFailed prometheus.Counter
+	ChequeImportTime    prometheus.Histogram
+	PeerWithdrawals     prometheus.Counter
+	RegistryMismatches  prometheus.Counter
+	PeersArchived       prometheus.Counter
+	PeersRestored       prometheus.Counter
+	AutoCashouts        prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -52,12 +68,97 @@ func newMetrics() metrics {
 			Name:      "cheques_rejected",
 			Help:      "Number of cheques rejected",
 		}),
+		ChequesBounced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cheques_bounced",
+			Help:      "Number of cashed out cheques which bounced",
+		}),
+		Cashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cashouts",
+			Help:      "Number of cashout transactions sent",
+		}),
 		AvailableBalance: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
 			Name:      "available_balance",
 			Help:      "Currently availeble chequebook balance.",
 		}),
+		DisconnectNotifications: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disconnect_notifications",
+			Help:      "Number of debt based peer disconnect notifications emitted",
+		}),
+		RemindersSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reminders_sent",
+			Help:      "Number of settlement reminders sent to peers whose debt exceeded the payment threshold",
+		}),
+		RemindersReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reminders_received",
+			Help:      "Number of settlement reminders received from peers",
+		}),
+		PeersThrottled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peers_throttled",
+			Help:      "Number of peers throttled because their debt exceeded the payment threshold for too long",
+		}),
+		ChequesImported: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cheques_imported",
+			Help:      "Number of received cheques imported from another node",
+		}),
+		ChequeImportsFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cheque_imports_failed",
+			Help:      "Number of received cheques which failed verification when imported",
+		}),
+		PeerWithdrawals: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_withdrawals",
+			Help:      "Number of withdrawals from chequebooks backing uncashed cheques",
+		}),
+		RegistryMismatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "registry_mismatches",
+			Help:      "Number of announcements rejected because they differ from the registry",
+		}),
+		PeersArchived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peers_archived",
+			Help:      "Number of stale peers whose settlement records were archived",
+		}),
+		PeersRestored: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peers_restored",
+			Help:      "Number of archived peers whose settlement records were restored on reconnect",
+		}),
+		AutoCashouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "auto_cashouts",
+			Help:      "Number of cheques cashed automatically after the uncashed amount of a peer exceeded the threshold",
+		}),
+		ChequeImportTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cheque_import_batch_seconds",
+			Help:      "Time taken to verify and import a batch of received cheques",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}),
 	}
 }
 
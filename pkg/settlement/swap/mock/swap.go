@@ -6,7 +6,9 @@ package mock
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -32,14 +34,48 @@ type Service struct {
 	receiveChequeFunc   func(context.Context, swarm.Address, *chequebook.SignedCheque, *big.Int, *big.Int) error
 	payFunc             func(context.Context, swarm.Address, *big.Int)
 	handshakeFunc       func(swarm.Address, common.Address) error
+	announceFunc        func(context.Context, swarm.Address) error
+	announcementFunc    func(context.Context, swarm.Address, common.Address) error
+	announceBeneficiary func(context.Context, swarm.Address) error
+	receiveBeneficiary  func(context.Context, swarm.Address, common.Address) error
+	rotateBeneficiary   func(context.Context, common.Address, time.Duration) (*chequebook.BeneficiaryRotation, []swap.BeneficiaryAnnouncement, error)
+	beneficiariesFunc   func() (*chequebook.BeneficiaryRotation, error)
 	lastSentChequeFunc  func(swarm.Address) (*chequebook.SignedCheque, error)
 	lastSentChequesFunc func() (map[string]*chequebook.SignedCheque, error)
 
 	lastReceivedChequeFunc  func(swarm.Address) (*chequebook.SignedCheque, error)
 	lastReceivedChequesFunc func() (map[string]*chequebook.SignedCheque, error)
+	lastChequesFunc         func(swap.ChequeOrder, string, int) (*swap.PeerChequesPage, error)
 
 	cashChequeFunc    func(ctx context.Context, peer swarm.Address) (common.Hash, error)
 	cashoutStatusFunc func(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error)
+	cashBatchFunc     func(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error)
+
+	receivedChequebooksFunc func(context.Context, swarm.Address) ([]swap.ReceivedChequebook, error)
+	cashChequebooksFunc     func(context.Context, swarm.Address) ([]chequebook.BatchCashoutResult, error)
+
+	receiveReceiptFunc func(swarm.Address, *chequebook.Receipt) error
+
+	recentBouncesFunc func() []swap.Bounce
+
+	chequeCashoutsFunc            func(swarm.Address) ([]chequebook.ChequeCashout, error)
+	cashoutTransactionChequesFunc func(common.Hash) ([]chequebook.SignedCheque, error)
+	reconcileCashoutsFunc         func(context.Context) (*chequebook.CashoutReconciliation, error)
+	cashoutAttemptsFunc           func(context.Context, swarm.Address) ([]chequebook.CashoutAttempt, error)
+	previewPayFunc                func(context.Context, swarm.Address, *big.Int) (*chequebook.IssuePreview, error)
+	retryCashoutFunc              func(context.Context, swarm.Address) (common.Hash, error)
+	assignChequeFunc              func(context.Context, swarm.Address, common.Address, common.Address, *big.Int) (*chequebook.ChequeAssignment, error)
+	chequeAssignmentFunc          func(context.Context, swarm.Address) (*chequebook.ChequeAssignmentStatus, error)
+	removeChequeAssignmentFunc    func(swarm.Address) error
+	importPeerFunc                func(context.Context, swarm.Address, swap.PeerImport) error
+	importPeersFunc               func(context.Context, []swap.PeerImportEntry) []error
+	peerStatementFunc             func(context.Context, swarm.Address) (*swap.StatementCheck, error)
+	statementFunc                 func(swarm.Address) (*chequebook.Statement, error)
+	receiveReminderFunc           func(context.Context, swarm.Address, *big.Int, *big.Int, time.Duration) error
+	openDisputeFunc               func(context.Context, swarm.Address, swap.DisputeDirection, string) (*swap.Dispute, error)
+	disputesFunc                  func() ([]swap.Dispute, error)
+	disputeFunc                   func(uint64) (*swap.Dispute, error)
+	resolveDisputeFunc            func(context.Context, uint64, swap.DisputeResolution, string) (*swap.Dispute, error)
 }
 
 // WithSettlementSentFunc sets the mock settlement function
@@ -86,6 +122,42 @@ func WithHandshakeFunc(f func(swarm.Address, common.Address) error) Option {
 	})
 }
 
+func WithAnnounceChequebookFunc(f func(context.Context, swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.announceFunc = f
+	})
+}
+
+func WithReceiveChequebookAnnouncementFunc(f func(context.Context, swarm.Address, common.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.announcementFunc = f
+	})
+}
+
+func WithAnnounceBeneficiaryFunc(f func(context.Context, swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.announceBeneficiary = f
+	})
+}
+
+func WithReceiveBeneficiaryAnnouncementFunc(f func(context.Context, swarm.Address, common.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.receiveBeneficiary = f
+	})
+}
+
+func WithRotateBeneficiaryFunc(f func(context.Context, common.Address, time.Duration) (*chequebook.BeneficiaryRotation, []swap.BeneficiaryAnnouncement, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.rotateBeneficiary = f
+	})
+}
+
+func WithBeneficiariesFunc(f func() (*chequebook.BeneficiaryRotation, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.beneficiariesFunc = f
+	})
+}
+
 func WithLastSentChequeFunc(f func(swarm.Address) (*chequebook.SignedCheque, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.lastSentChequeFunc = f
@@ -110,6 +182,12 @@ func WithLastReceivedChequesFunc(f func() (map[string]*chequebook.SignedCheque,
 	})
 }
 
+func WithLastChequesFunc(f func(order swap.ChequeOrder, cursor string, limit int) (*swap.PeerChequesPage, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastChequesFunc = f
+	})
+}
+
 func WithCashChequeFunc(f func(ctx context.Context, peer swarm.Address) (common.Hash, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.cashChequeFunc = f
@@ -122,6 +200,144 @@ func WithCashoutStatusFunc(f func(ctx context.Context, peer swarm.Address) (*che
 	})
 }
 
+func WithCashChequeBatchFunc(f func(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.cashBatchFunc = f
+	})
+}
+
+func WithReceivedChequebooksFunc(f func(context.Context, swarm.Address) ([]swap.ReceivedChequebook, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.receivedChequebooksFunc = f
+	})
+}
+
+func WithCashChequebooksFunc(f func(context.Context, swarm.Address) ([]chequebook.BatchCashoutResult, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.cashChequebooksFunc = f
+	})
+}
+
+func WithRecentBouncesFunc(f func() []swap.Bounce) Option {
+	return optionFunc(func(s *Service) {
+		s.recentBouncesFunc = f
+	})
+}
+
+func WithChequeCashoutsFunc(f func(swarm.Address) ([]chequebook.ChequeCashout, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.chequeCashoutsFunc = f
+	})
+}
+
+func WithCashoutTransactionChequesFunc(f func(common.Hash) ([]chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.cashoutTransactionChequesFunc = f
+	})
+}
+
+func WithReconcileCashoutsFunc(f func(context.Context) (*chequebook.CashoutReconciliation, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.reconcileCashoutsFunc = f
+	})
+}
+
+func WithPreviewPayFunc(f func(context.Context, swarm.Address, *big.Int) (*chequebook.IssuePreview, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.previewPayFunc = f
+	})
+}
+
+func WithCashoutAttemptsFunc(f func(context.Context, swarm.Address) ([]chequebook.CashoutAttempt, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.cashoutAttemptsFunc = f
+	})
+}
+
+func WithRetryCashoutFunc(f func(context.Context, swarm.Address) (common.Hash, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.retryCashoutFunc = f
+	})
+}
+
+func WithAssignChequeFunc(f func(context.Context, swarm.Address, common.Address, common.Address, *big.Int) (*chequebook.ChequeAssignment, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.assignChequeFunc = f
+	})
+}
+
+func WithChequeAssignmentFunc(f func(context.Context, swarm.Address) (*chequebook.ChequeAssignmentStatus, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.chequeAssignmentFunc = f
+	})
+}
+
+func WithRemoveChequeAssignmentFunc(f func(swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.removeChequeAssignmentFunc = f
+	})
+}
+
+func WithImportPeerFunc(f func(context.Context, swarm.Address, swap.PeerImport) error) Option {
+	return optionFunc(func(s *Service) {
+		s.importPeerFunc = f
+	})
+}
+
+func WithImportPeersFunc(f func(context.Context, []swap.PeerImportEntry) []error) Option {
+	return optionFunc(func(s *Service) {
+		s.importPeersFunc = f
+	})
+}
+
+func WithPeerStatementFunc(f func(context.Context, swarm.Address) (*swap.StatementCheck, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.peerStatementFunc = f
+	})
+}
+
+func WithStatementFunc(f func(swarm.Address) (*chequebook.Statement, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.statementFunc = f
+	})
+}
+
+func WithReceiveReminderFunc(f func(context.Context, swarm.Address, *big.Int, *big.Int, time.Duration) error) Option {
+	return optionFunc(func(s *Service) {
+		s.receiveReminderFunc = f
+	})
+}
+
+func WithOpenDisputeFunc(f func(context.Context, swarm.Address, swap.DisputeDirection, string) (*swap.Dispute, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.openDisputeFunc = f
+	})
+}
+
+func WithDisputesFunc(f func() ([]swap.Dispute, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.disputesFunc = f
+	})
+}
+
+func WithDisputeFunc(f func(uint64) (*swap.Dispute, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.disputeFunc = f
+	})
+}
+
+func WithResolveDisputeFunc(f func(context.Context, uint64, swap.DisputeResolution, string) (*swap.Dispute, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.resolveDisputeFunc = f
+	})
+}
+
+func WithReceiveReceiptFunc(f func(swarm.Address, *chequebook.Receipt) error) Option {
+	return optionFunc(func(s *Service) {
+		s.receiveReceiptFunc = f
+	})
+}
+
 // New creates the mock swap implementation
 func New(opts ...Option) swap.Interface {
 	mock := new(Service)
@@ -182,7 +398,7 @@ func (s *Service) TotalReceived(peer swarm.Address) (totalReceived *big.Int, err
 }
 
 // SettlementsSent is the mock SettlementsSent function of swap.
-func (s *Service) SettlementsSent() (map[string]*big.Int, error) {
+func (s *Service) SettlementsSent(context.Context) (map[string]*big.Int, error) {
 	if s.settlementsSentFunc != nil {
 		return s.settlementsSentFunc()
 	}
@@ -205,6 +421,48 @@ func (s *Service) Handshake(peer swarm.Address, beneficiary common.Address) erro
 	return nil
 }
 
+func (s *Service) AnnounceChequebook(ctx context.Context, peer swarm.Address) error {
+	if s.announceFunc != nil {
+		return s.announceFunc(ctx, peer)
+	}
+	return nil
+}
+
+func (s *Service) ReceiveChequebookAnnouncement(ctx context.Context, peer swarm.Address, chequebook common.Address) error {
+	if s.announcementFunc != nil {
+		return s.announcementFunc(ctx, peer, chequebook)
+	}
+	return nil
+}
+
+func (s *Service) AnnounceBeneficiary(ctx context.Context, peer swarm.Address) error {
+	if s.announceBeneficiary != nil {
+		return s.announceBeneficiary(ctx, peer)
+	}
+	return nil
+}
+
+func (s *Service) ReceiveBeneficiaryAnnouncement(ctx context.Context, peer swarm.Address, beneficiary common.Address) error {
+	if s.receiveBeneficiary != nil {
+		return s.receiveBeneficiary(ctx, peer, beneficiary)
+	}
+	return nil
+}
+
+func (s *Service) RotateBeneficiary(ctx context.Context, beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, []swap.BeneficiaryAnnouncement, error) {
+	if s.rotateBeneficiary != nil {
+		return s.rotateBeneficiary(ctx, beneficiary, grace)
+	}
+	return nil, nil, nil
+}
+
+func (s *Service) Beneficiaries() (*chequebook.BeneficiaryRotation, error) {
+	if s.beneficiariesFunc != nil {
+		return s.beneficiariesFunc()
+	}
+	return nil, nil
+}
+
 func (s *Service) LastSentCheque(address swarm.Address) (*chequebook.SignedCheque, error) {
 	if s.lastSentChequeFunc != nil {
 		return s.lastSentChequeFunc(address)
@@ -212,7 +470,7 @@ func (s *Service) LastSentCheque(address swarm.Address) (*chequebook.SignedChequ
 	return nil, nil
 }
 
-func (s *Service) LastSentCheques() (map[string]*chequebook.SignedCheque, error) {
+func (s *Service) LastSentCheques(context.Context) (map[string]*chequebook.SignedCheque, error) {
 	if s.lastSentChequesFunc != nil {
 		return s.lastSentChequesFunc()
 	}
@@ -233,6 +491,23 @@ func (s *Service) LastReceivedCheques() (map[string]*chequebook.SignedCheque, er
 	return nil, nil
 }
 
+// LastCheques orders the cheques of LastSentCheques and LastReceivedCheques
+// unless a function is configured.
+func (s *Service) LastCheques(ctx context.Context, order swap.ChequeOrder, cursor string, limit int) (*swap.PeerChequesPage, error) {
+	if s.lastChequesFunc != nil {
+		return s.lastChequesFunc(order, cursor, limit)
+	}
+	sent, err := s.LastSentCheques(ctx)
+	if err != nil && !errors.Is(err, swap.ErrNoChequebook) {
+		return nil, err
+	}
+	received, err := s.LastReceivedCheques()
+	if err != nil {
+		return nil, err
+	}
+	return swap.OrderCheques(sent, received, order, cursor, limit)
+}
+
 func (s *Service) CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error) {
 	if s.cashChequeFunc != nil {
 		return s.cashChequeFunc(ctx, peer)
@@ -247,6 +522,164 @@ func (s *Service) CashoutStatus(ctx context.Context, peer swarm.Address) (*chequ
 	return nil, nil
 }
 
+func (s *Service) CashChequeBatch(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	if s.cashBatchFunc != nil {
+		return s.cashBatchFunc(ctx, peers)
+	}
+	return nil, nil
+}
+
+func (s *Service) ReceivedChequebooks(ctx context.Context, peer swarm.Address) ([]swap.ReceivedChequebook, error) {
+	if s.receivedChequebooksFunc != nil {
+		return s.receivedChequebooksFunc(ctx, peer)
+	}
+	return nil, nil
+}
+
+func (s *Service) CashChequebooks(ctx context.Context, peer swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	if s.cashChequebooksFunc != nil {
+		return s.cashChequebooksFunc(ctx, peer)
+	}
+	return nil, nil
+}
+
+func (s *Service) RecentBounces() []swap.Bounce {
+	if s.recentBouncesFunc != nil {
+		return s.recentBouncesFunc()
+	}
+	return nil
+}
+
+func (s *Service) ChequeCashouts(peer swarm.Address) ([]chequebook.ChequeCashout, error) {
+	if s.chequeCashoutsFunc != nil {
+		return s.chequeCashoutsFunc(peer)
+	}
+	return nil, nil
+}
+
+func (s *Service) CashoutTransactionCheques(txHash common.Hash) ([]chequebook.SignedCheque, error) {
+	if s.cashoutTransactionChequesFunc != nil {
+		return s.cashoutTransactionChequesFunc(txHash)
+	}
+	return nil, nil
+}
+
+func (s *Service) ReconcileCashouts(ctx context.Context) (*chequebook.CashoutReconciliation, error) {
+	if s.reconcileCashoutsFunc != nil {
+		return s.reconcileCashoutsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (s *Service) PreviewPay(ctx context.Context, peer swarm.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+	if s.previewPayFunc != nil {
+		return s.previewPayFunc(ctx, peer, amount)
+	}
+	return nil, nil
+}
+
+func (s *Service) CashoutAttempts(ctx context.Context, peer swarm.Address) ([]chequebook.CashoutAttempt, error) {
+	if s.cashoutAttemptsFunc != nil {
+		return s.cashoutAttemptsFunc(ctx, peer)
+	}
+	return nil, nil
+}
+
+func (s *Service) RetryCashout(ctx context.Context, peer swarm.Address) (common.Hash, error) {
+	if s.retryCashoutFunc != nil {
+		return s.retryCashoutFunc(ctx, peer)
+	}
+	return common.Hash{}, nil
+}
+
+func (s *Service) AssignCheque(ctx context.Context, peer swarm.Address, cashier, recipient common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error) {
+	if s.assignChequeFunc != nil {
+		return s.assignChequeFunc(ctx, peer, cashier, recipient, callerPayout)
+	}
+	return nil, nil
+}
+
+func (s *Service) ChequeAssignment(ctx context.Context, peer swarm.Address) (*chequebook.ChequeAssignmentStatus, error) {
+	if s.chequeAssignmentFunc != nil {
+		return s.chequeAssignmentFunc(ctx, peer)
+	}
+	return nil, nil
+}
+
+func (s *Service) RemoveChequeAssignment(peer swarm.Address) error {
+	if s.removeChequeAssignmentFunc != nil {
+		return s.removeChequeAssignmentFunc(peer)
+	}
+	return nil
+}
+
+func (s *Service) ImportPeer(ctx context.Context, peer swarm.Address, state swap.PeerImport) error {
+	if s.importPeerFunc != nil {
+		return s.importPeerFunc(ctx, peer, state)
+	}
+	return nil
+}
+
+func (s *Service) ImportPeers(ctx context.Context, imports []swap.PeerImportEntry) []error {
+	if s.importPeersFunc != nil {
+		return s.importPeersFunc(ctx, imports)
+	}
+	errs := make([]error, len(imports))
+	for i, entry := range imports {
+		errs[i] = s.ImportPeer(ctx, entry.Peer, entry.State)
+	}
+	return errs
+}
+
+func (s *Service) PeerStatement(ctx context.Context, peer swarm.Address) (*swap.StatementCheck, error) {
+	if s.peerStatementFunc != nil {
+		return s.peerStatementFunc(ctx, peer)
+	}
+	return nil, swap.ErrNoStatement
+}
+
+func (s *Service) Statement(peer swarm.Address) (*chequebook.Statement, error) {
+	if s.statementFunc != nil {
+		return s.statementFunc(peer)
+	}
+	return nil, swap.ErrNoStatement
+}
+
+func (s *Service) ReceiveReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error {
+	if s.receiveReminderFunc != nil {
+		return s.receiveReminderFunc(ctx, peer, debt, threshold, overdue)
+	}
+	return nil
+}
+
+func (s *Service) OpenDispute(ctx context.Context, peer swarm.Address, direction swap.DisputeDirection, note string) (*swap.Dispute, error) {
+	if s.openDisputeFunc != nil {
+		return s.openDisputeFunc(ctx, peer, direction, note)
+	}
+	return &swap.Dispute{Peer: peer, Direction: direction, Status: swap.DisputeOpen}, nil
+}
+
+func (s *Service) Disputes() ([]swap.Dispute, error) {
+	if s.disputesFunc != nil {
+		return s.disputesFunc()
+	}
+	return nil, nil
+}
+
+func (s *Service) Dispute(id uint64) (*swap.Dispute, error) {
+	if s.disputeFunc != nil {
+		return s.disputeFunc(id)
+	}
+	return nil, swap.ErrDisputeNotFound
+}
+
+func (s *Service) ResolveDispute(ctx context.Context, id uint64, resolution swap.DisputeResolution, note string) (*swap.Dispute, error) {
+	if s.resolveDisputeFunc != nil {
+		return s.resolveDisputeFunc(ctx, id, resolution, note)
+	}
+	return nil, swap.ErrDisputeNotFound
+}
+
 func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) (err error) {
 	defer func() {
 		if err == nil {
@@ -260,6 +693,13 @@ func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque
 	return nil
 }
 
+func (s *Service) ReceiveReceipt(peer swarm.Address, receipt *chequebook.Receipt) error {
+	if s.receiveReceiptFunc != nil {
+		return s.receiveReceiptFunc(peer, receipt)
+	}
+	return nil
+}
+
 func (s *Service) GetDeductionForPeer(peer swarm.Address) (bool, error) {
 	if _, ok := s.deductionForPeers[peer.String()]; ok {
 		return true, nil
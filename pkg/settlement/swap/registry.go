@@ -0,0 +1,36 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// SetRegistry sets the on-chain registry announced chequebooks and
+// beneficiaries of peers are verified against. Without a registry
+// announcements are only verified on the chequebook contracts.
+func (s *Service) SetRegistry(r registry.Service) {
+	s.registry = r
+}
+
+// verifyRegistered checks the payment details announced by the peer against
+// the record the peer published in the registry, if any. The record must have
+// been published from the ethereum address the peer identified with in the
+// handshake.
+func (s *Service) verifyRegistered(ctx context.Context, peer swarm.Address, identity common.Address, announced registry.Record) error {
+	if s.registry == nil {
+		return nil
+	}
+	err := registry.Verify(ctx, s.registry, identity, peer, announced)
+	if errors.Is(err, registry.ErrMismatch) {
+		s.metrics.RegistryMismatches.Inc()
+	}
+	return err
+}
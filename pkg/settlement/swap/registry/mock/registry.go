@@ -0,0 +1,62 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type Service struct {
+	lookup  func(ctx context.Context, registrant common.Address, overlay swarm.Address) (registry.Record, bool, error)
+	publish func(ctx context.Context, overlay swarm.Address, record registry.Record) (common.Hash, error)
+}
+
+// Option is the option passed to the mock registry service.
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(r *Service) { f(r) }
+
+// New creates a mock registry in which nothing is published.
+func New(opts ...Option) registry.Service {
+	s := &Service{}
+	for _, o := range opts {
+		o.apply(s)
+	}
+	return s
+}
+
+func WithLookupFunc(f func(ctx context.Context, registrant common.Address, overlay swarm.Address) (registry.Record, bool, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lookup = f
+	})
+}
+
+func WithPublishFunc(f func(ctx context.Context, overlay swarm.Address, record registry.Record) (common.Hash, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.publish = f
+	})
+}
+
+func (s *Service) Lookup(ctx context.Context, registrant common.Address, overlay swarm.Address) (registry.Record, bool, error) {
+	if s.lookup != nil {
+		return s.lookup(ctx, registrant, overlay)
+	}
+	return registry.Record{}, false, nil
+}
+
+func (s *Service) Publish(ctx context.Context, overlay swarm.Address, record registry.Record) (common.Hash, error) {
+	if s.publish != nil {
+		return s.publish(ctx, overlay, record)
+	}
+	return common.Hash{}, nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package registry publishes and looks up the payment details of nodes in an
+// on-chain registry keyed by overlay address. Records are stored per
+// registrant, so the record of a peer can only be published by the ethereum
+// address its overlay address is derived from.
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+const registryABIJSON = `[{"inputs":[{"internalType":"bytes32","name":"overlay","type":"bytes32"},{"internalType":"address","name":"chequebook","type":"address"},{"internalType":"address","name":"beneficiary","type":"address"}],"name":"register","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"registrant","type":"address"},{"internalType":"bytes32","name":"overlay","type":"bytes32"}],"name":"records","outputs":[{"internalType":"address","name":"chequebook","type":"address"},{"internalType":"address","name":"beneficiary","type":"address"}],"stateMutability":"view","type":"function"}]`
+
+var registryABI = abiutil.MustParseABI(registryABIJSON)
+
+var (
+	// ErrMismatch is returned if announced payment details differ from the ones in the registry.
+	ErrMismatch = errors.New("payment details differ from the registry")
+
+	errDecodeABI = errors.New("could not decode abi data")
+)
+
+// registerGasLimit is the gas limit of publishing a record.
+const registerGasLimit = 80000
+
+// Record holds the payment details of a node.
+type Record struct {
+	Chequebook  common.Address `json:"chequebook"`
+	Beneficiary common.Address `json:"beneficiary"`
+}
+
+// Service publishes and looks up records in the registry.
+type Service interface {
+	// Lookup returns the record published by the registrant for the overlay
+	// address. The record is not known if nothing was published.
+	Lookup(ctx context.Context, registrant common.Address, overlay swarm.Address) (record Record, known bool, err error)
+	// Publish publishes the record for the overlay address from our ethereum
+	// address unless it is already published and returns the hash of the
+	// transaction, or the zero hash if none was sent.
+	Publish(ctx context.Context, overlay swarm.Address, record Record) (common.Hash, error)
+}
+
+type service struct {
+	transactionService transaction.Service
+	address            common.Address
+	owner              common.Address
+}
+
+// New creates a new registry Service for the registry contract at address.
+// Records are published from the owner address.
+func New(transactionService transaction.Service, address, owner common.Address) Service {
+	return &service{
+		transactionService: transactionService,
+		address:            address,
+		owner:              owner,
+	}
+}
+
+func (s *service) Lookup(ctx context.Context, registrant common.Address, overlay swarm.Address) (Record, bool, error) {
+	callData, err := registryABI.Pack("records", registrant, overlayKey(overlay))
+	if err != nil {
+		return Record{}, false, err
+	}
+	output, err := s.transactionService.Call(ctx, &transaction.TxRequest{
+		To:   &s.address,
+		Data: callData,
+	})
+	if err != nil {
+		return Record{}, false, fmt.Errorf("registry lookup: %w", err)
+	}
+
+	results, err := registryABI.Unpack("records", output)
+	if err != nil {
+		return Record{}, false, err
+	}
+	if len(results) != 2 {
+		return Record{}, false, errDecodeABI
+	}
+	chequebook, ok := results[0].(common.Address)
+	if !ok {
+		return Record{}, false, errDecodeABI
+	}
+	beneficiary, ok := results[1].(common.Address)
+	if !ok {
+		return Record{}, false, errDecodeABI
+	}
+
+	record := Record{Chequebook: chequebook, Beneficiary: beneficiary}
+	return record, record != (Record{}), nil
+}
+
+func (s *service) Publish(ctx context.Context, overlay swarm.Address, record Record) (common.Hash, error) {
+	current, known, err := s.Lookup(ctx, s.owner, overlay)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if known && current == record {
+		return common.Hash{}, nil
+	}
+
+	callData, err := registryABI.Pack("register", overlayKey(overlay), record.Chequebook, record.Beneficiary)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash, err := s.transactionService.Send(ctx, &transaction.TxRequest{
+		To:          &s.address,
+		Data:        callData,
+		GasLimit:    registerGasLimit,
+		Value:       big.NewInt(0),
+		Description: "payment details registration",
+	}, transaction.DefaultTipBoostPercent)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	receipt, err := s.transactionService.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt.Status != 1 {
+		return common.Hash{}, transaction.ErrTransactionReverted
+	}
+	return txHash, nil
+}
+
+// Verify checks the announced chequebook and beneficiary of the overlay
+// address against the record of the registrant. Details which are zero or
+// not published are not checked.
+func Verify(ctx context.Context, s Service, registrant common.Address, overlay swarm.Address, announced Record) error {
+	record, known, err := s.Lookup(ctx, registrant, overlay)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return nil
+	}
+	if announced.Chequebook != (common.Address{}) && record.Chequebook != (common.Address{}) && announced.Chequebook != record.Chequebook {
+		return fmt.Errorf("%w: announced chequebook %x, registered %x", ErrMismatch, announced.Chequebook, record.Chequebook)
+	}
+	if announced.Beneficiary != (common.Address{}) && record.Beneficiary != (common.Address{}) && announced.Beneficiary != record.Beneficiary {
+		return fmt.Errorf("%w: announced beneficiary %x, registered %x", ErrMismatch, announced.Beneficiary, record.Beneficiary)
+	}
+	return nil
+}
+
+func overlayKey(overlay swarm.Address) (key [32]byte) {
+	copy(key[:], overlay.Bytes())
+	return key
+}
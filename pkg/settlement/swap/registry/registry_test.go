@@ -0,0 +1,174 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func overlayKey(overlay swarm.Address) (key [32]byte) {
+	copy(key[:], overlay.Bytes())
+	return key
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	registryAddress := common.HexToAddress("0xabcd")
+	owner := common.HexToAddress("0xeeee")
+	overlay := swarm.MustParseHexAddress("aaaa000000000000000000000000000000000000000000000000000000000000")
+	record := registry.Record{
+		Chequebook:  common.HexToAddress("0xcccc"),
+		Beneficiary: common.HexToAddress("0xbbbb"),
+	}
+	txHash := common.HexToHash("0x1234")
+
+	published := registry.Record{}
+	var sent int
+	r := registry.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				if *request.To != registryAddress {
+					t.Fatalf("call to %x, want %x", *request.To, registryAddress)
+				}
+				args, err := registry.RegistryABI.Methods["records"].Inputs.Unpack(request.Data[4:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if args[0].(common.Address) != owner || args[1].([32]byte) != overlayKey(overlay) {
+					t.Fatalf("looked up %v, want record of %x for %s", args, owner, overlay)
+				}
+				return registry.RegistryABI.Methods["records"].Outputs.Pack(published.Chequebook, published.Beneficiary)
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				args, err := registry.RegistryABI.Methods["register"].Inputs.Unpack(request.Data[4:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if args[0].([32]byte) != overlayKey(overlay) || args[1].(common.Address) != record.Chequebook || args[2].(common.Address) != record.Beneficiary {
+					t.Fatalf("registered %v, want %v for %s", args, record, overlay)
+				}
+				sent++
+				published = record
+				return txHash, nil
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{Status: 1}, nil
+			}),
+		),
+		registryAddress,
+		owner,
+	)
+
+	ctx := context.Background()
+	if _, known, err := r.Lookup(ctx, owner, overlay); err != nil || known {
+		t.Fatalf("got record known %t and error %v, want none", known, err)
+	}
+
+	hash, err := r.Publish(ctx, overlay, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != txHash {
+		t.Fatalf("got tx %x, want %x", hash, txHash)
+	}
+
+	got, known, err := r.Lookup(ctx, owner, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !known || got != record {
+		t.Fatalf("got record %v, want %v", got, record)
+	}
+
+	// an unchanged record is not published again
+	hash, err = r.Publish(ctx, overlay, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != (common.Hash{}) || sent != 1 {
+		t.Fatalf("got tx %x after %d sends, want none after 1", hash, sent)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	registrant := common.HexToAddress("0xeeee")
+	overlay := swarm.MustParseHexAddress("aaaa")
+	registered := registry.Record{
+		Chequebook:  common.HexToAddress("0xcccc"),
+		Beneficiary: common.HexToAddress("0xbbbb"),
+	}
+
+	r := mock.New(mock.WithLookupFunc(func(ctx context.Context, a common.Address, o swarm.Address) (registry.Record, bool, error) {
+		if a != registrant || !o.Equal(overlay) {
+			return registry.Record{}, false, nil
+		}
+		return registered, true, nil
+	}))
+
+	for _, tc := range []struct {
+		name       string
+		registrant common.Address
+		announced  registry.Record
+		err        error
+	}{
+		{name: "registered chequebook", registrant: registrant, announced: registry.Record{Chequebook: registered.Chequebook}},
+		{name: "registered beneficiary", registrant: registrant, announced: registry.Record{Beneficiary: registered.Beneficiary}},
+		{name: "other chequebook", registrant: registrant, announced: registry.Record{Chequebook: common.HexToAddress("0xdddd")}, err: registry.ErrMismatch},
+		{name: "other beneficiary", registrant: registrant, announced: registry.Record{Beneficiary: common.HexToAddress("0xdddd")}, err: registry.ErrMismatch},
+		{name: "published by another address", registrant: common.HexToAddress("0xffff"), announced: registry.Record{Chequebook: common.HexToAddress("0xdddd")}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := registry.Verify(context.Background(), r, tc.registrant, overlay, tc.announced)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestPublishReverted(t *testing.T) {
+	t.Parallel()
+
+	r := registry.New(
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				return registry.RegistryABI.Methods["records"].Outputs.Pack(common.Address{}, common.Address{})
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				if request.Value.Cmp(big.NewInt(0)) != 0 {
+					t.Fatalf("got value %d, want 0", request.Value)
+				}
+				return common.HexToHash("0x1234"), nil
+			}),
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{Status: 0}, nil
+			}),
+		),
+		common.HexToAddress("0xabcd"),
+		common.HexToAddress("0xeeee"),
+	)
+
+	_, err := r.Publish(context.Background(), swarm.MustParseHexAddress("aaaa"), registry.Record{Chequebook: common.HexToAddress("0xcccc")})
+	if !errors.Is(err, transaction.ErrTransactionReverted) {
+		t.Fatalf("got error %v, want %v", err, transaction.ErrTransactionReverted)
+	}
+}
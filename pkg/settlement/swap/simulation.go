@@ -0,0 +1,124 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// cashoutGasLimit is the gas limit assumed for a single cashout transaction.
+// It matches the default gas limit used by the cashout service.
+const cashoutGasLimit = 300_000
+
+// ErrInvalidPaymentThreshold is the error if a simulation is requested for a non-positive payment threshold.
+var ErrInvalidPaymentThreshold = errors.New("invalid payment threshold")
+
+// PeerSimulation is the projected settlement outcome for a single peer.
+type PeerSimulation struct {
+	Peer         string
+	Debt         *big.Int // current debt towards the peer in accounting units
+	ChequeCount  int      // number of cheques which would be issued
+	ChequeAmount *big.Int // amount of the issued cheque in tokens, including the deduction
+	Remainder    *big.Int // part of the debt which stays unsettled in accounting units
+}
+
+// SimulationOptions are the settlement parameters of a simulation.
+type SimulationOptions struct {
+	// PaymentThreshold is the hypothetical debt in accounting units at which
+	// the debt is settled.
+	PaymentThreshold *big.Int
+	// MinimumPayment is the smallest debt in accounting units which is paid
+	// by a cheque. Nil means any debt is paid.
+	MinimumPayment *big.Int
+	// ExchangeRate is the current number of tokens per accounting unit. Nil
+	// means one token per unit.
+	ExchangeRate *big.Int
+	// Deduction is the current number of tokens added to the first cheque
+	// sent to a peer. Nil means no deduction.
+	Deduction *big.Int
+	// Deducted are the peers which already received a cheque including the
+	// deduction.
+	Deducted map[string]bool
+	// GasPrice is the gas price of the cashouts. Nil means no gas cost.
+	GasPrice *big.Int
+	// DataFee is the L1 data fee charged for every cashout on top of its gas
+	// on rollups, nil otherwise.
+	DataFee *big.Int
+}
+
+// Simulation is the projected outcome of settling the current debts with a hypothetical payment threshold.
+type Simulation struct {
+	PaymentThreshold *big.Int
+	Peers            []PeerSimulation // sorted by peer
+	ChequeCount      int              // number of cheques which would be issued in total
+	TotalAmount      *big.Int         // sum of all issued cheques in tokens
+	ProjectedGasCost *big.Int         // gas cost of cashing out every peer once, including L1 data fees
+}
+
+// Simulate reports which cheques would be issued if the given debts were
+// settled with the options. Like the accounting, a peer whose debt reached
+// the payment threshold is paid its whole debt with a single cheque, as long
+// as the debt is at least the minimum payment. The debt is converted into the
+// cheque amount at the exchange rate and the deduction is added unless the
+// peer received it before. The projected gas cost assumes that every peer
+// which received a cheque cashes out once at the gas price.
+func Simulate(debts map[string]*big.Int, o SimulationOptions) (*Simulation, error) {
+	if o.PaymentThreshold == nil || o.PaymentThreshold.Sign() <= 0 {
+		return nil, ErrInvalidPaymentThreshold
+	}
+	exchangeRate := o.ExchangeRate
+	if exchangeRate == nil {
+		exchangeRate = big.NewInt(1)
+	}
+
+	simulation := &Simulation{
+		PaymentThreshold: new(big.Int).Set(o.PaymentThreshold),
+		Peers:            make([]PeerSimulation, 0, len(debts)),
+		TotalAmount:      big.NewInt(0),
+		ProjectedGasCost: big.NewInt(0),
+	}
+
+	cashouts := int64(0)
+	for peer, debt := range debts {
+		if debt == nil || debt.Sign() <= 0 {
+			continue
+		}
+
+		p := PeerSimulation{
+			Peer:         peer,
+			Debt:         new(big.Int).Set(debt),
+			ChequeAmount: big.NewInt(0),
+			Remainder:    new(big.Int).Set(debt),
+		}
+		if debt.Cmp(o.PaymentThreshold) >= 0 && (o.MinimumPayment == nil || debt.Cmp(o.MinimumPayment) >= 0) {
+			p.ChequeCount = 1
+			p.ChequeAmount.Mul(debt, exchangeRate)
+			if o.Deduction != nil && !o.Deducted[peer] {
+				p.ChequeAmount.Add(p.ChequeAmount, o.Deduction)
+			}
+			p.Remainder.SetInt64(0)
+
+			cashouts++
+			simulation.ChequeCount++
+			simulation.TotalAmount.Add(simulation.TotalAmount, p.ChequeAmount)
+		}
+		simulation.Peers = append(simulation.Peers, p)
+	}
+
+	sort.Slice(simulation.Peers, func(i, j int) bool {
+		return simulation.Peers[i].Peer < simulation.Peers[j].Peer
+	})
+
+	if o.GasPrice != nil {
+		simulation.ProjectedGasCost.Mul(o.GasPrice, big.NewInt(cashouts*cashoutGasLimit))
+	}
+	if o.DataFee != nil {
+		simulation.ProjectedGasCost.Add(simulation.ProjectedGasCost, new(big.Int).Mul(o.DataFee, big.NewInt(cashouts)))
+	}
+
+	return simulation, nil
+}
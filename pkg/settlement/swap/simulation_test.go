@@ -0,0 +1,122 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+)
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	debts := map[string]*big.Int{
+		"b": big.NewInt(250),
+		"a": big.NewInt(90),
+		"c": big.NewInt(-50), // peer owes us, nothing to settle
+	}
+
+	simulation, err := swap.Simulate(debts, swap.SimulationOptions{
+		PaymentThreshold: big.NewInt(100),
+		GasPrice:         big.NewInt(2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(simulation.Peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(simulation.Peers))
+	}
+
+	// the whole debt is paid with one cheque once it reached the threshold
+	a, b := simulation.Peers[0], simulation.Peers[1]
+	if a.Peer != "a" || a.ChequeCount != 0 || a.ChequeAmount.Sign() != 0 || a.Remainder.Cmp(big.NewInt(90)) != 0 {
+		t.Fatalf("unexpected simulation for peer a: %+v", a)
+	}
+	if b.Peer != "b" || b.ChequeCount != 1 || b.ChequeAmount.Cmp(big.NewInt(250)) != 0 || b.Remainder.Sign() != 0 {
+		t.Fatalf("unexpected simulation for peer b: %+v", b)
+	}
+
+	if simulation.ChequeCount != 1 {
+		t.Fatalf("got %d cheques, want 1", simulation.ChequeCount)
+	}
+	if simulation.TotalAmount.Cmp(big.NewInt(250)) != 0 {
+		t.Fatalf("got total amount %d, want 250", simulation.TotalAmount)
+	}
+	if want := big.NewInt(2 * 300_000); simulation.ProjectedGasCost.Cmp(want) != 0 {
+		t.Fatalf("got projected gas cost %d, want %d", simulation.ProjectedGasCost, want)
+	}
+}
+
+func TestSimulateRates(t *testing.T) {
+	t.Parallel()
+
+	debts := map[string]*big.Int{
+		"a": big.NewInt(150),
+		"b": big.NewInt(200),
+		"c": big.NewInt(120),
+	}
+
+	simulation, err := swap.Simulate(debts, swap.SimulationOptions{
+		PaymentThreshold: big.NewInt(100),
+		MinimumPayment:   big.NewInt(130),
+		ExchangeRate:     big.NewInt(10),
+		Deduction:        big.NewInt(7),
+		Deducted:         map[string]bool{"b": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the debt of c reached the threshold but is below the minimum payment
+	for i, want := range []swap.PeerSimulation{
+		{Peer: "a", Debt: big.NewInt(150), ChequeCount: 1, ChequeAmount: big.NewInt(1507), Remainder: big.NewInt(0)},
+		{Peer: "b", Debt: big.NewInt(200), ChequeCount: 1, ChequeAmount: big.NewInt(2000), Remainder: big.NewInt(0)},
+		{Peer: "c", Debt: big.NewInt(120), ChequeCount: 0, ChequeAmount: big.NewInt(0), Remainder: big.NewInt(120)},
+	} {
+		got := simulation.Peers[i]
+		if got.Peer != want.Peer || got.Debt.Cmp(want.Debt) != 0 || got.ChequeCount != want.ChequeCount || got.ChequeAmount.Cmp(want.ChequeAmount) != 0 || got.Remainder.Cmp(want.Remainder) != 0 {
+			t.Fatalf("got simulation %+v, want %+v", got, want)
+		}
+	}
+	if simulation.TotalAmount.Cmp(big.NewInt(3507)) != 0 {
+		t.Fatalf("got total amount %d, want 3507", simulation.TotalAmount)
+	}
+}
+
+func TestSimulateDataFee(t *testing.T) {
+	t.Parallel()
+
+	debts := map[string]*big.Int{
+		"a": big.NewInt(150),
+		"b": big.NewInt(300),
+	}
+
+	simulation, err := swap.Simulate(debts, swap.SimulationOptions{
+		PaymentThreshold: big.NewInt(100),
+		GasPrice:         big.NewInt(2),
+		DataFee:          big.NewInt(1000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both peers cash out once and pay the data fee on top of the gas
+	if want := big.NewInt(2*2*300_000 + 2*1000); simulation.ProjectedGasCost.Cmp(want) != 0 {
+		t.Fatalf("got projected gas cost %d, want %d", simulation.ProjectedGasCost, want)
+	}
+}
+
+func TestSimulateInvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	_, err := swap.Simulate(nil, swap.SimulationOptions{PaymentThreshold: big.NewInt(0)})
+	if !errors.Is(err, swap.ErrInvalidPaymentThreshold) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrInvalidPaymentThreshold)
+	}
+}
@@ -0,0 +1,383 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// DefaultStaleCleanupInterval is the default interval in which peers are checked for staleness.
+	DefaultStaleCleanupInterval = time.Hour
+
+	// prefix for the persistence key of the time a peer was last seen
+	lastSeenKeyPrefix = "swap_peer_last_seen_"
+	// prefix for the persistence keys of archives
+	archiveKeyPrefix = "swap_archive_"
+	// prefix for the persistence key of the archive of a peer
+	archivePeerKeyPrefix = archiveKeyPrefix + "peer_"
+	// prefix for the persistence key of the archived peer of a beneficiary
+	archiveBeneficiaryKeyPrefix = archiveKeyPrefix + "beneficiary_"
+)
+
+// retainedPrefixes are the prefixes of records which are never archived. The
+// last cheques issued to a peer stay as the total issued counter and the
+// cumulative payout of the next cheque depend on them, the history of issued
+// cheques as it is appended to.
+var retainedPrefixes = []string{
+	"swap_chequebook_last_issued_cheque_",
+	"swap_chequebook_cheque_history_",
+}
+
+// StaleCleanupOptions configures the archiving of the settlement records of
+// stale peers.
+type StaleCleanupOptions struct {
+	// Period for which a peer has to be disconnected to be stale. Zero
+	// disables the cleanup.
+	Period time.Duration
+	// Interval in which the peers are checked.
+	Interval time.Duration
+	// Balance returns the accounting balance with the peer. Only peers with a
+	// zero balance are archived.
+	Balance func(peer swarm.Address) (*big.Int, error)
+	// Export records the archive, usually in the audit log, before the
+	// records are removed. The cleanup is disabled without it.
+	Export func(archive *PeerArchive) error
+}
+
+// PeerArchive holds the settlement records of a stale peer which were moved
+// out of the active dataset. The records are restored when the peer connects
+// again.
+type PeerArchive struct {
+	Peer        swarm.Address     `json:"peer"`
+	Beneficiary common.Address    `json:"beneficiary"`
+	Chequebooks []common.Address  `json:"chequebooks"`
+	LastSeen    int64             `json:"lastSeen"` // unix timestamp the peer was last seen
+	Archived    int64             `json:"archived"` // unix timestamp of the archiving
+	Records     map[string][]byte `json:"records"`  // archived records by their keys
+}
+
+// rawValue is stored as is, without being encoded again.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func lastSeenKey(peer swarm.Address) string {
+	return fmt.Sprintf("%s%s", lastSeenKeyPrefix, peer)
+}
+
+func archivePeerKey(peer swarm.Address) string {
+	return fmt.Sprintf("%s%s", archivePeerKeyPrefix, peer)
+}
+
+func archiveBeneficiaryKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", archiveBeneficiaryKeyPrefix, beneficiary)
+}
+
+// touchPeer records that the peer was seen now.
+func (s *Service) touchPeer(peer swarm.Address) error {
+	return s.store.Put(lastSeenKey(peer), s.clock.Now().Unix())
+}
+
+// lastSeen returns the unix timestamp the peer was last seen.
+func (s *Service) lastSeen(peer swarm.Address) (lastSeen int64, known bool, err error) {
+	err = s.store.Get(lastSeenKey(peer), &lastSeen)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return lastSeen, true, nil
+}
+
+type staleCleaner struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (c *staleCleaner) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// StartStaleCleanup starts archiving the settlement records of stale peers
+// once per interval. A peer is stale if it was not seen for the period, its
+// accounting balance is zero and all cheques received from it were cashed.
+// Its records are exported, stored in a single archive record and removed
+// from the active dataset. Peers known before the cleanup started are
+// considered seen at the first check.
+func (s *Service) StartStaleCleanup(o StaleCleanupOptions) io.Closer {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &staleCleaner{cancel: cancel}
+	if o.Period <= 0 || o.Interval <= 0 || o.Balance == nil || o.Export == nil {
+		return c
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(o.Interval):
+			}
+
+			if err := s.cleanupStalePeers(ctx, o); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to archive stale peers")
+			}
+		}
+	}()
+
+	return c
+}
+
+// cleanupStalePeers archives the records of all stale peers.
+func (s *Service) cleanupStalePeers(ctx context.Context, o StaleCleanupOptions) error {
+	s.peersMu.Lock()
+	lister := s.peers
+	s.peersMu.Unlock()
+
+	connected := make(map[string]struct{})
+	if lister != nil {
+		for _, p := range lister.Peers() {
+			connected[p.Address.ByteString()] = struct{}{}
+			if err := s.touchPeer(p.Address); err != nil {
+				return err
+			}
+		}
+	}
+
+	var candidates []swarm.Address
+	err := s.store.Iterate(peerBeneficiaryPrefix, func(key, _ []byte) (bool, error) {
+		peer, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), peerBeneficiaryPrefix))
+		if err != nil {
+			return false, fmt.Errorf("parse peer of %q: %w", key, err)
+		}
+		if _, ok := connected[peer.ByteString()]; !ok {
+			candidates = append(candidates, peer)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().Unix()
+	for _, peer := range candidates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lastSeen, known, err := s.lastSeen(peer)
+		if err != nil {
+			return err
+		}
+		if !known {
+			if err := s.touchPeer(peer); err != nil {
+				return err
+			}
+			continue
+		}
+		if now-lastSeen < int64(o.Period/time.Second) {
+			continue
+		}
+		settled, err := s.settled(peer, o.Balance)
+		if err != nil {
+			s.logger.Debug("checking settlement of stale peer failed", "peer_address", peer, "error", err)
+			continue
+		}
+		if !settled {
+			continue
+		}
+		if err := s.archivePeer(peer, lastSeen, o.Export); err != nil {
+			s.logger.Error(err, "failed to archive stale peer", "peer_address", peer)
+		}
+	}
+	return nil
+}
+
+// settled reports whether the balance with the peer is zero and all cheques
+// received from it were cashed.
+func (s *Service) settled(peer swarm.Address, balance func(swarm.Address) (*big.Int, error)) (bool, error) {
+	b, err := balance(peer)
+	if err != nil {
+		return false, err
+	}
+	if b.Sign() != 0 {
+		return false, nil
+	}
+
+	chequebooks, err := s.addressbook.Chequebooks(peer)
+	if err != nil {
+		return false, err
+	}
+	for _, chequebookAddress := range chequebooks {
+		cheques, err := s.chequeStore.LastBeneficiaryCheques(chequebookAddress)
+		if err != nil {
+			return false, err
+		}
+		uncashed, err := s.uncashed(chequebookAddress, cheques)
+		if err != nil {
+			return false, err
+		}
+		if uncashed.Sign() > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// archivePeer exports the records of the peer, stores them in its archive and
+// removes them from the active dataset.
+func (s *Service) archivePeer(peer swarm.Address, lastSeen int64, export func(*PeerArchive) error) error {
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	// the peer may have connected since it was found stale
+	seen, _, err := s.lastSeen(peer)
+	if err != nil {
+		return err
+	}
+	if seen != lastSeen {
+		return nil
+	}
+
+	beneficiary, known, err := s.addressbook.Beneficiary(peer)
+	if err != nil || !known {
+		return err
+	}
+
+	chequebooks, err := s.addressbook.Chequebooks(peer)
+	if err != nil {
+		return err
+	}
+	ids := []string{peer.String(), fmt.Sprintf("%x", beneficiary)}
+	for _, chequebookAddress := range chequebooks {
+		ids = append(ids, fmt.Sprintf("%x", chequebookAddress))
+	}
+
+	records := make(map[string][]byte)
+	err = s.store.Iterate("swap_", func(key, value []byte) (bool, error) {
+		k := string(key)
+		if strings.HasPrefix(k, archiveKeyPrefix) {
+			return false, nil
+		}
+		for _, prefix := range retainedPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				return false, nil
+			}
+		}
+		for _, id := range ids {
+			if strings.Contains(k, id) {
+				records[k] = append([]byte(nil), value...)
+				break
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	archive := &PeerArchive{
+		Peer:        peer,
+		Beneficiary: beneficiary,
+		Chequebooks: chequebooks,
+		LastSeen:    lastSeen,
+		Archived:    s.clock.Now().Unix(),
+		Records:     records,
+	}
+	if err := export(archive); err != nil {
+		return fmt.Errorf("export archive: %w", err)
+	}
+	if err := s.store.Put(archivePeerKey(peer), archive); err != nil {
+		return err
+	}
+	if err := s.store.Put(archiveBeneficiaryKey(beneficiary), peer); err != nil {
+		return err
+	}
+	for key := range records {
+		if err := s.store.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	s.metrics.PeersArchived.Inc()
+	s.logger.Info("archived settlement records of stale peer", "peer_address", peer, "beneficiary_address", beneficiary, "records", len(records), "last_seen", time.Unix(lastSeen, 0))
+	return nil
+}
+
+// archivedPeer returns the archive of the peer or, if it reconnects under a
+// new overlay, of the peer with the beneficiary.
+func (s *Service) archivedPeer(peer swarm.Address, beneficiary common.Address) (*PeerArchive, error) {
+	var archive PeerArchive
+	err := s.store.Get(archivePeerKey(peer), &archive)
+	if err == nil {
+		return &archive, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	var archived swarm.Address
+	err = s.store.Get(archiveBeneficiaryKey(beneficiary), &archived)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Get(archivePeerKey(archived), &archive)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// restoreArchive moves the archived records of a reconnecting peer back into
+// the active dataset.
+func (s *Service) restoreArchive(peer swarm.Address, beneficiary common.Address) error {
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	archive, err := s.archivedPeer(peer, beneficiary)
+	if err != nil || archive == nil {
+		return err
+	}
+
+	for key, value := range archive.Records {
+		if err := s.store.Put(key, rawValue(value)); err != nil {
+			return err
+		}
+	}
+	if err := s.store.Delete(archiveBeneficiaryKey(archive.Beneficiary)); err != nil {
+		return err
+	}
+	if err := s.store.Delete(archivePeerKey(archive.Peer)); err != nil {
+		return err
+	}
+
+	s.metrics.PeersRestored.Inc()
+	s.logger.Info("restored settlement records of archived peer", "peer_address", archive.Peer, "beneficiary_address", archive.Beneficiary, "records", len(archive.Records))
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	clockmock "github.com/ethersphere/bee/pkg/settlement/swap/clock/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestCleanupStalePeers(t *testing.T) {
+	t.Parallel()
+
+	settledPeer := swarm.MustParseHexAddress("aaaa")
+	settledBeneficiary := common.HexToAddress("0xaaaa")
+	settledChequebook := common.HexToAddress("0xaaab")
+	debtorPeer := swarm.MustParseHexAddress("bbbb")
+	debtorBeneficiary := common.HexToAddress("0xbbbb")
+	debtorChequebook := common.HexToAddress("0xbbbc")
+
+	cheques := map[common.Address]*chequebook.SignedCheque{
+		settledChequebook: {Cheque: chequebook.Cheque{Chequebook: settledChequebook, CumulativePayout: big.NewInt(50)}},
+		debtorChequebook:  {Cheque: chequebook.Cheque{Chequebook: debtorChequebook, CumulativePayout: big.NewInt(100)}},
+	}
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+			cheque, ok := cheques[c]
+			if !ok {
+				return nil, chequebook.ErrNoCheque
+			}
+			return cheque, nil
+		})),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			chequeCashouts: func(c common.Address) ([]chequebook.ChequeCashout, error) {
+				if c == settledChequebook {
+					return []chequebook.ChequeCashout{{Cheque: *cheques[settledChequebook]}}, nil
+				}
+				return nil, nil
+			},
+		},
+		nil,
+		common.Address{},
+	)
+	clock := clockmock.New(time.Unix(1000, 0))
+	swapService.SetClock(clock)
+
+	for _, p := range []struct {
+		peer        swarm.Address
+		beneficiary common.Address
+		chequebook  common.Address
+	}{
+		{settledPeer, settledBeneficiary, settledChequebook},
+		{debtorPeer, debtorBeneficiary, debtorChequebook},
+	} {
+		if err := swapService.Handshake(p.peer, p.beneficiary); err != nil {
+			t.Fatal(err)
+		}
+		if err := addressbook.PutChequebook(p.peer, p.chequebook); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receivedKey := fmt.Sprintf("swap_chequebook_last_received_cheque__%x", settledChequebook)
+	issuedKey := fmt.Sprintf("swap_chequebook_last_issued_cheque_%x", settledBeneficiary)
+	for _, key := range []string{receivedKey, issuedKey} {
+		if err := store.Put(key, cheques[settledChequebook]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var archives []*swap.PeerArchive
+	o := swap.StaleCleanupOptions{
+		Period: time.Hour,
+		Balance: func(peer swarm.Address) (*big.Int, error) {
+			return big.NewInt(0), nil
+		},
+		Export: func(archive *swap.PeerArchive) error {
+			archives = append(archives, archive)
+			return nil
+		},
+	}
+	ctx := context.Background()
+
+	// recently seen peers are kept
+	if err := swapService.CleanupStalePeers(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 0 {
+		t.Fatalf("got %d archives of recently seen peers, want none", len(archives))
+	}
+
+	clock.Advance(2 * time.Hour)
+	if err := swapService.CleanupStalePeers(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	// the peer with uncashed cheques is kept
+	if len(archives) != 1 || !archives[0].Peer.Equal(settledPeer) {
+		t.Fatalf("got archives %v, want only the settled peer", archives)
+	}
+	if _, ok := archives[0].Records[receivedKey]; !ok {
+		t.Fatal("received cheque not archived")
+	}
+	if _, ok := archives[0].Records[issuedKey]; ok {
+		t.Fatal("issued cheque archived")
+	}
+	if _, known, err := addressbook.Beneficiary(settledPeer); err != nil || known {
+		t.Fatalf("got beneficiary of archived peer known %t error %v, want pruned", known, err)
+	}
+	if _, known, err := addressbook.Beneficiary(debtorPeer); err != nil || !known {
+		t.Fatalf("got beneficiary of debtor known %t error %v, want kept", known, err)
+	}
+	var cheque chequebook.SignedCheque
+	if err := store.Get(receivedKey, &cheque); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v reading archived cheque, want %v", err, storage.ErrNotFound)
+	}
+	if err := store.Get(issuedKey, &cheque); err != nil {
+		t.Fatal(err)
+	}
+
+	// the records are restored when the peer connects again
+	if err := swapService.Handshake(settledPeer, settledBeneficiary); err != nil {
+		t.Fatal(err)
+	}
+	chequebookAddress, known, err := addressbook.Chequebook(settledPeer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !known || chequebookAddress != settledChequebook {
+		t.Fatalf("got chequebook %v known %t after restore, want %v", chequebookAddress, known, settledChequebook)
+	}
+	if err := store.Get(receivedKey, &cheque); err != nil {
+		t.Fatal(err)
+	}
+	if cheque.CumulativePayout.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got restored cumulative payout %d, want 50", cheque.CumulativePayout)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// statementPrefix is the prefix of the key under which our last statement for a beneficiary is stored.
+const statementPrefix = "swap_statement_"
+
+// peerStatementPrefix is the prefix of the key under which the last statement received for a chequebook is stored.
+const peerStatementPrefix = "swap_peer_statement_"
+
+// ErrNoStatement is the error returned if no statement was made for a peer yet.
+var ErrNoStatement = errors.New("no statement")
+
+func statementKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", statementPrefix, beneficiary)
+}
+
+func peerStatementKey(chequebookAddress common.Address) string {
+	return fmt.Sprintf("%s%x", peerStatementPrefix, chequebookAddress)
+}
+
+// StatementCheck is the result of comparing the statement of a peer about
+// the cheques it sent to us with our own view.
+type StatementCheck struct {
+	// Statement is the verified statement of the peer.
+	Statement *chequebook.Statement
+	// CumulativePayout of the last cheque we received from the peer.
+	CumulativePayout *big.Int
+	// Matches reports whether the statement agrees with the last cheque we received.
+	Matches bool
+}
+
+type statementProducer struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (p *statementProducer) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return nil
+}
+
+// StartStatements sets the signer of our statements and starts producing a
+// signed statement for every beneficiary we issued cheques to once per
+// interval. Peers request them to verify their view matches ours. A zero
+// interval disables producing statements, statements of peers can still be
+// checked.
+func (s *Service) StartStatements(signer crypto.Signer, chainID int64, interval time.Duration) io.Closer {
+	s.statementSigner = signer
+	s.chainID = chainID
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &statementProducer{cancel: cancel}
+	if interval <= 0 || s.chequebook == nil {
+		return p
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			if err := s.produceStatements(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to produce settlement statements")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(interval):
+			}
+		}
+	}()
+
+	return p
+}
+
+// produceStatements signs and stores a statement for every beneficiary we
+// issued cheques to.
+func (s *Service) produceStatements(ctx context.Context) error {
+	cheques, err := s.chequebook.LastCheques(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().Unix()
+	for beneficiary, cheque := range cheques {
+		statement := &chequebook.Statement{
+			Chequebook:       cheque.Chequebook,
+			Beneficiary:      beneficiary,
+			CumulativePayout: cheque.CumulativePayout,
+			Timestamp:        now,
+		}
+		if err := chequebook.SignStatement(s.statementSigner, statement, s.chainID); err != nil {
+			return err
+		}
+		if err := s.store.Put(statementKey(beneficiary), statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Statement returns our last statement about the cheques we sent to the peer.
+func (s *Service) Statement(peer swarm.Address) (*chequebook.Statement, error) {
+	beneficiary, known, err := s.beneficiary(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, ErrNoStatement
+	}
+
+	var statement chequebook.Statement
+	err = s.store.Get(statementKey(beneficiary), &statement)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNoStatement
+		}
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// PeerStatement requests the statement of the peer about the cheques it sent
+// to us and compares it with the last cheque we received. The statement must
+// be signed by the peer and is kept as evidence in case of a dispute.
+func (s *Service) PeerStatement(ctx context.Context, peer swarm.Address) (*StatementCheck, error) {
+	beneficiary, known, err := s.addressbook.Beneficiary(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, ErrUnknownBeneficary
+	}
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, settlement.ErrPeerNoSettlements
+	}
+
+	statement, err := s.proto.RequestStatement(ctx, peer)
+	if err != nil {
+		return nil, err
+	}
+	// peers sign with the node key they announced as beneficiary in the handshake
+	if err := chequebook.VerifyStatement(statement, beneficiary, s.chainID); err != nil {
+		return nil, err
+	}
+	if statement.Chequebook != chequebookAddress {
+		return nil, ErrWrongChequebook
+	}
+
+	cumulativePayout := big.NewInt(0)
+	cheque, err := s.chequeStore.LastCheque(chequebookAddress)
+	switch {
+	case errors.Is(err, chequebook.ErrNoCheque):
+	case err != nil:
+		return nil, err
+	case cheque.Beneficiary != statement.Beneficiary:
+		return nil, chequebook.ErrWrongBeneficiary
+	default:
+		cumulativePayout = cheque.CumulativePayout
+	}
+
+	if err := s.store.Put(peerStatementKey(chequebookAddress), statement); err != nil {
+		return nil, err
+	}
+
+	return &StatementCheck{
+		Statement:        statement,
+		CumulativePayout: cumulativePayout,
+		Matches:          statement.CumulativePayout.Cmp(cumulativePayout) == 0,
+	}, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func newStatementSigner(t *testing.T) (crypto.Signer, common.Address) {
+	t.Helper()
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, address
+}
+
+func TestStatements(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(1)
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xbe")
+	chequebookAddress := common.HexToAddress("0xcb")
+	signer, issuer := newStatementSigner(t)
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(mockchequebook.WithLastChequesFunc(func(context.Context) (map[common.Address]*chequebook.SignedCheque, error) {
+			return map[common.Address]*chequebook.SignedCheque{
+				beneficiary: {Cheque: chequebook.Cheque{
+					Chequebook:       chequebookAddress,
+					Beneficiary:      beneficiary,
+					CumulativePayout: big.NewInt(500),
+				}},
+			}, nil
+		})),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+
+	if _, err := swapService.Statement(peer); !errors.Is(err, swap.ErrNoStatement) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrNoStatement)
+	}
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	closer := swapService.StartStatements(signer, chainID, time.Hour)
+	defer closer.Close()
+
+	// the first statements are made right away
+	var statement *chequebook.Statement
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		var err error
+		statement, err = swapService.Statement(peer)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, swap.ErrNoStatement) {
+			t.Fatal(err)
+		}
+	}
+	if statement == nil {
+		t.Fatal("no statement made")
+	}
+
+	if statement.Chequebook != chequebookAddress || statement.Beneficiary != beneficiary || statement.CumulativePayout.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("wrong statement %+v", statement)
+	}
+	if err := chequebook.VerifyStatement(statement, issuer, chainID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPeerStatement(t *testing.T) {
+	t.Parallel()
+
+	chainID := int64(1)
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("abcd")
+	chequebookAddress := common.HexToAddress("0xcb")
+	ourBeneficiary := common.HexToAddress("0xff")
+	peerSigner, peerBeneficiary := newStatementSigner(t)
+	otherSigner, _ := newStatementSigner(t)
+
+	var statement *chequebook.Statement
+	swapService := swap.New(
+		&swapProtocolMock{
+			requestStatement: func(ctx context.Context, p swarm.Address) (*chequebook.Statement, error) {
+				if !p.Equal(peer) {
+					t.Fatalf("requested statement from %v, want %v", p, peer)
+				}
+				return statement, nil
+			},
+		},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+			return &chequebook.SignedCheque{Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      ourBeneficiary,
+				CumulativePayout: big.NewInt(300),
+			}}, nil
+		})),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+	closer := swapService.StartStatements(peerSigner, chainID, 0)
+	defer closer.Close()
+
+	if _, err := swapService.PeerStatement(context.Background(), peer); !errors.Is(err, swap.ErrUnknownBeneficary) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrUnknownBeneficary)
+	}
+	if err := addressbook.PutBeneficiary(peer, peerBeneficiary); err != nil {
+		t.Fatal(err)
+	}
+	if err := addressbook.PutChequebook(peer, chequebookAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(s crypto.Signer, cumulativePayout int64) *chequebook.Statement {
+		statement := &chequebook.Statement{
+			Chequebook:       chequebookAddress,
+			Beneficiary:      ourBeneficiary,
+			CumulativePayout: big.NewInt(cumulativePayout),
+			Timestamp:        1000,
+		}
+		if err := chequebook.SignStatement(s, statement, chainID); err != nil {
+			t.Fatal(err)
+		}
+		return statement
+	}
+
+	statement = sign(peerSigner, 300)
+	check, err := swapService.PeerStatement(context.Background(), peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Matches || check.CumulativePayout.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("got check %+v, want match", check)
+	}
+
+	// the peer claims to have issued a cheque we never received
+	statement = sign(peerSigner, 400)
+	check, err = swapService.PeerStatement(context.Background(), peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Matches {
+		t.Fatalf("got check %+v, want mismatch", check)
+	}
+
+	statement = sign(otherSigner, 300)
+	if _, err := swapService.PeerStatement(context.Background(), peer); !errors.Is(err, chequebook.ErrStatementInvalid) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrStatementInvalid)
+	}
+}
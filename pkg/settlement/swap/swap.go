@@ -9,13 +9,20 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/events"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/clock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
 	"github.com/ethersphere/bee/pkg/settlement/swap/swapprotocol"
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -31,22 +38,81 @@ var (
 	// ErrChequeValueTooLow is the error a peer issued a cheque not covering 1 accounting credit
 	ErrChequeValueTooLow = errors.New("cheque value too low")
 	ErrNoChequebook      = errors.New("no chequebook")
+	// ErrNoReceipt is the error returned if no receipt was received from a peer.
+	ErrNoReceipt = errors.New("no receipt")
 )
 
+// receiptPrefix is the prefix of the key under which the last receipt for a beneficiary is stored.
+const receiptPrefix = "swap_receipt_"
+
+// announcedChequebookPrefix is the prefix of the key under which the chequebook last announced to a peer is stored.
+const announcedChequebookPrefix = "swap_announced_chequebook_"
+
+// receiptKey computes the key where to store the last receipt received from a beneficiary.
+func receiptKey(beneficiary common.Address) string {
+	return fmt.Sprintf("%s%x", receiptPrefix, beneficiary)
+}
+
 type Interface interface {
 	settlement.Interface
 	// LastSentCheque returns the last sent cheque for the peer
 	LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, error)
 	// LastSentCheques returns the list of last sent cheques for all peers
-	LastSentCheques() (map[string]*chequebook.SignedCheque, error)
+	LastSentCheques(ctx context.Context) (map[string]*chequebook.SignedCheque, error)
 	// LastReceivedCheque returns the last received cheque for the peer
 	LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheque, error)
 	// LastReceivedCheques returns the list of last received cheques for all peers
 	LastReceivedCheques() (map[string]*chequebook.SignedCheque, error)
+	// LastCheques returns the last sent and received cheques of all peers in a stable order
+	LastCheques(ctx context.Context, order ChequeOrder, cursor string, limit int) (*PeerChequesPage, error)
 	// CashCheque sends a cashing transaction for the last cheque of the peer
 	CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error)
+	// PreviewPay evaluates paying amount to the peer without issuing a cheque
+	PreviewPay(ctx context.Context, peer swarm.Address, amount *big.Int) (*chequebook.IssuePreview, error)
 	// CashoutStatus gets the status of the latest cashout transaction for the peers chequebook
 	CashoutStatus(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error)
+	// CashChequeBatch sends cashing transactions for the last cheques of several peers, bundled into one transaction if possible
+	CashChequeBatch(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error)
+	// ReceivedChequebooks returns the last cheque and uncashed amount of every chequebook the peer sent cheques from
+	ReceivedChequebooks(ctx context.Context, peer swarm.Address) ([]ReceivedChequebook, error)
+	// CashChequebooks sends cashing transactions for every chequebook of the peer which still owes us
+	CashChequebooks(ctx context.Context, peer swarm.Address) ([]chequebook.BatchCashoutResult, error)
+	// RecentBounces returns the most recently detected bounced cashouts, newest first
+	RecentBounces() []Bounce
+	// ChequeCashouts returns the cashout transactions sent for cheques of the peer
+	ChequeCashouts(peer swarm.Address) ([]chequebook.ChequeCashout, error)
+	// CashoutTransactionCheques returns the cheques cashed by the cashout transaction
+	CashoutTransactionCheques(txHash common.Hash) ([]chequebook.SignedCheque, error)
+	// ReconcileCashouts finds received cheques which were never cashed or cashed more than once
+	ReconcileCashouts(ctx context.Context) (*chequebook.CashoutReconciliation, error)
+	// CashoutAttempts returns the recorded attempts to cash the cheques of the peer, oldest first
+	CashoutAttempts(ctx context.Context, peer swarm.Address) ([]chequebook.CashoutAttempt, error)
+	// RetryCashout cashes the last cheque of the peer again if the last attempt failed, reverted or was dropped
+	RetryCashout(ctx context.Context, peer swarm.Address) (common.Hash, error)
+	// AssignCheque authorizes the cashier to cash the last cheque of the peer to the recipient
+	AssignCheque(ctx context.Context, peer swarm.Address, cashier, recipient common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error)
+	// ChequeAssignment returns the last cheque assignment of the peer and whether it was cashed
+	ChequeAssignment(ctx context.Context, peer swarm.Address) (*chequebook.ChequeAssignmentStatus, error)
+	// RemoveChequeAssignment forgets the cheque assignment of the peer
+	RemoveChequeAssignment(peer swarm.Address) error
+	// ImportPeer imports the swap state of the peer carried over from another node
+	ImportPeer(ctx context.Context, peer swarm.Address, state PeerImport) error
+	// ImportPeers imports the swap state of many peers, verifying their received cheques in a batch
+	ImportPeers(ctx context.Context, imports []PeerImportEntry) []error
+	// PeerStatement requests the signed statement of the peer and compares it with the cheques we received
+	PeerStatement(ctx context.Context, peer swarm.Address) (*StatementCheck, error)
+	// RotateBeneficiary changes the beneficiary of cheques to us and announces it to the connected peers
+	RotateBeneficiary(ctx context.Context, beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, []BeneficiaryAnnouncement, error)
+	// Beneficiaries returns the beneficiary of cheques to us and the previous ones still accepted
+	Beneficiaries() (*chequebook.BeneficiaryRotation, error)
+	// OpenDispute opens a dispute about the cheques sent to or received from the peer
+	OpenDispute(ctx context.Context, peer swarm.Address, direction DisputeDirection, note string) (*Dispute, error)
+	// Disputes returns all disputes, the most recently opened first
+	Disputes() ([]Dispute, error)
+	// Dispute returns the dispute with the given id
+	Dispute(id uint64) (*Dispute, error)
+	// ResolveDispute resolves the open dispute by resending, adjusting or dismissing
+	ResolveDispute(ctx context.Context, id uint64, resolution DisputeResolution, note string) (*Dispute, error)
 }
 
 // Service is the implementation of the swap settlement layer.
@@ -62,23 +128,67 @@ type Service struct {
 	addressbook    Addressbook
 	networkID      uint64
 	cashoutAddress common.Address
+
+	disconnectMu       sync.Mutex
+	disconnectNotifier DisconnectNotifier
+	blocklistDuration  time.Duration
+	bouncedNotified    map[common.Hash]struct{}
+	bounces            []Bounce
+	pendingCashouts    map[common.Address]swarm.Address
+
+	bus          eventBus
+	eventsMu     sync.Mutex
+	eventsCancel func()
+
+	workers *workerpool.Pool
+
+	peersMu sync.Mutex
+	peers   PeerLister
+
+	grace graceTracker
+
+	disputesMu sync.Mutex
+
+	statementSigner crypto.Signer
+	chainID         int64
+
+	registry registry.Service
+
+	contractsMu sync.Mutex
+	contracts   chequebook.ContractInspector
+
+	archiveMu sync.Mutex
+
+	clock clock.Clock
 }
 
 // New creates a new swap Service.
 func New(proto swapprotocol.Interface, logger log.Logger, store storage.StateStorer, chequebook chequebook.Service, chequeStore chequebook.ChequeStore, addressbook Addressbook, networkID uint64, cashout chequebook.CashoutService, accounting settlement.Accounting, cashoutAddress common.Address) *Service {
-	return &Service{
-		proto:          proto,
-		logger:         logger.WithName(loggerName).Register(),
-		store:          store,
-		metrics:        newMetrics(),
-		chequebook:     chequebook,
-		chequeStore:    chequeStore,
-		addressbook:    addressbook,
-		networkID:      networkID,
-		cashout:        cashout,
-		accounting:     accounting,
-		cashoutAddress: cashoutAddress,
+	s := &Service{
+		proto:           proto,
+		logger:          logger.WithName(loggerName).Register(),
+		store:           store,
+		metrics:         newMetrics(),
+		chequebook:      chequebook,
+		chequeStore:     chequeStore,
+		addressbook:     addressbook,
+		networkID:       networkID,
+		cashout:         cashout,
+		accounting:      accounting,
+		cashoutAddress:  cashoutAddress,
+		bouncedNotified: make(map[common.Hash]struct{}),
+		pendingCashouts: make(map[common.Address]swarm.Address),
+		clock:           clock.System,
 	}
+	s.bus.subscribe(s.metrics.handleEvent)
+	s.bus.subscribe(s.trackCashout)
+	return s
+}
+
+// SetClock replaces the system clock the service tells the time with. It must
+// be called before the background tasks of the service are started.
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
 }
 
 // ReceiveCheque is called by the swap protocol if a cheque is received.
@@ -93,6 +203,12 @@ func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque
 	}
 
 	receivedAmount, err := s.chequeStore.ReceiveCheque(ctx, cheque, exchangeRate, deduction)
+	if errors.Is(err, chequebook.ErrDuplicateCheque) {
+		// the peer resent a cheque we already credited, it is acknowledged
+		// again so that the peer knows we hold it
+		s.logger.Debug("received duplicate cheque", "peer_address", peer, "chequebook", cheque.Chequebook)
+		return nil
+	}
 	if err != nil {
 		s.metrics.ChequesRejected.Inc()
 		return fmt.Errorf("rejecting cheque: %w", err)
@@ -115,13 +231,65 @@ func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque
 		}
 	}
 
-	tot, _ := big.NewFloat(0).SetInt(receivedAmount).Float64()
-	s.metrics.TotalReceived.Add(tot)
-	s.metrics.ChequesReceived.Inc()
+	s.publish(events.Event{
+		Type:       events.TypeChequeReceived,
+		Peer:       peer,
+		Chequebook: cheque.Chequebook,
+		Amount:     amount,
+		Payout:     receivedAmount,
+	})
 
 	return s.accounting.NotifyPaymentReceived(peer, amount)
 }
 
+// ReceiveReceipt is called by the swap protocol if a receipt for a sent cheque is received.
+// The receipt is kept as proof that the cheque was delivered.
+func (s *Service) ReceiveReceipt(peer swarm.Address, receipt *chequebook.Receipt) error {
+	beneficiary, known, err := s.beneficiary(peer)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return ErrUnknownBeneficary
+	}
+	if receipt.Beneficiary != beneficiary {
+		return chequebook.ErrReceiptInvalid
+	}
+
+	var lastReceipt chequebook.Receipt
+	err = s.store.Get(receiptKey(beneficiary), &lastReceipt)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	// receipts are cumulative, an older receipt does not replace a newer one
+	if err == nil && lastReceipt.CumulativePayout.Cmp(receipt.CumulativePayout) >= 0 {
+		return nil
+	}
+
+	return s.store.Put(receiptKey(beneficiary), receipt)
+}
+
+// LastReceipt returns the last receipt received from the peer.
+func (s *Service) LastReceipt(peer swarm.Address) (*chequebook.Receipt, error) {
+	beneficiary, known, err := s.beneficiary(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, ErrNoReceipt
+	}
+
+	var receipt chequebook.Receipt
+	err = s.store.Get(receiptKey(beneficiary), &receipt)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNoReceipt
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
+
 // Pay initiates a payment to the given peer
 func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int) {
 	var err error
@@ -134,7 +302,7 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 		err = ErrNoChequebook
 		return
 	}
-	beneficiary, known, err := s.addressbook.Beneficiary(peer)
+	beneficiary, known, err := s.beneficiary(peer)
 	if err != nil {
 		return
 	}
@@ -143,8 +311,24 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 		return
 	}
 
-	balance, err := s.proto.EmitCheque(ctx, peer, beneficiary, amount, s.chequebook.Issue)
+	// payments covered by a prepaid credit are made without sending a cheque,
+	// so no stream is opened to the peer
+	if payer, ok := s.chequebook.(chequebook.CreditPayer); ok {
+		var paid bool
+		if paid, err = s.payFromCredit(ctx, payer, peer, beneficiary, amount); err != nil || paid {
+			return
+		}
+	}
 
+	var balance, payout *big.Int
+	issue := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+		payout = amount.BigInt()
+		return s.chequebook.Issue(ctx, beneficiary, amount, sendChequeFunc)
+	}
+	err = s.run(ctx, workerpool.PriorityIssuance, func(ctx context.Context) (err error) {
+		balance, err = s.proto.EmitCheque(ctx, peer, beneficiary, amount, issue)
+		return err
+	})
 	if err != nil {
 		return
 	}
@@ -152,18 +336,80 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 	bal, _ := big.NewFloat(0).SetInt(balance).Float64()
 	s.metrics.AvailableBalance.Set(bal)
 	s.accounting.NotifyPaymentSent(peer, amount, nil)
-	amountFloat, _ := big.NewFloat(0).SetInt(amount).Float64()
-	s.metrics.TotalSent.Add(amountFloat)
-	s.metrics.ChequesSent.Inc()
+
+	s.publish(events.Event{
+		Type:       events.TypeChequeIssued,
+		Peer:       peer,
+		Chequebook: s.chequebook.Address(),
+		Amount:     amount,
+		Payout:     payout,
+	})
+}
+
+// payFromCredit pays amount to the peer from the prepaid credit of the
+// beneficiary and reports whether the credit covered it.
+func (s *Service) payFromCredit(ctx context.Context, payer chequebook.CreditPayer, peer swarm.Address, beneficiary common.Address, amount *big.Int) (bool, error) {
+	paymentAmount, err := s.proto.PaymentAmount(peer, amount)
+	if err != nil {
+		return false, err
+	}
+	balance, err := payer.PayFromCredit(ctx, beneficiary, paymentAmount)
+	if errors.Is(err, chequebook.ErrCreditInsufficient) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	bal, _ := big.NewFloat(0).SetInt(balance).Float64()
+	s.metrics.AvailableBalance.Set(bal)
+	s.accounting.NotifyPaymentSent(peer, amount, nil)
+	return true, nil
+}
+
+// PreviewPay evaluates paying amount to the peer at the current rates without
+// signing or sending a cheque.
+func (s *Service) PreviewPay(ctx context.Context, peer swarm.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+	if s.chequebook == nil {
+		return nil, ErrNoChequebook
+	}
+	beneficiary, known, err := s.beneficiary(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, ErrUnknownBeneficary
+	}
+
+	paymentAmount, err := s.proto.PaymentAmount(peer, amount)
+	if err != nil {
+		return nil, err
+	}
+	return s.chequebook.PreviewIssue(ctx, beneficiary, paymentAmount)
+}
+
+// SetWorkerPool sets the pool on which cheques are sent and cashouts and
+// cashout status checks are run. Without a pool they run inline.
+func (s *Service) SetWorkerPool(workers *workerpool.Pool) {
+	s.workers = workers
+}
+
+// run runs f on the worker pool with the given priority.
+func (s *Service) run(ctx context.Context, priority workerpool.Priority, f func(context.Context) error) error {
+	if s.workers == nil {
+		return f(ctx)
+	}
+	return s.workers.Do(ctx, priority, f)
 }
 
 func (s *Service) SetAccounting(accounting settlement.Accounting) {
 	s.accounting = accounting
 }
 
-// TotalSent returns the total amount sent to a peer
+// TotalSent returns the total amount sent to a peer, including the cheques
+// sent to the beneficiary of the handshake before the peer announced another.
 func (s *Service) TotalSent(peer swarm.Address) (totalSent *big.Int, err error) {
-	beneficiary, known, err := s.addressbook.Beneficiary(peer)
+	identity, known, err := s.addressbook.Beneficiary(peer)
 	if err != nil {
 		return nil, err
 	}
@@ -173,14 +419,32 @@ func (s *Service) TotalSent(peer swarm.Address) (totalSent *big.Int, err error)
 	if s.chequebook == nil {
 		return big.NewInt(0), nil
 	}
-	cheque, err := s.chequebook.LastCheque(beneficiary)
+	beneficiary, err := s.payoutBeneficiary(peer, identity)
 	if err != nil {
+		return nil, err
+	}
+
+	beneficiaries := []common.Address{identity}
+	if beneficiary != identity {
+		beneficiaries = append(beneficiaries, beneficiary)
+	}
+	for _, b := range beneficiaries {
+		cheque, err := s.chequebook.LastCheque(b)
 		if errors.Is(err, chequebook.ErrNoCheque) {
-			return nil, settlement.ErrPeerNoSettlements
+			continue
 		}
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+		if totalSent == nil {
+			totalSent = new(big.Int)
+		}
+		totalSent.Add(totalSent, cheque.CumulativePayout)
 	}
-	return cheque.CumulativePayout, nil
+	if totalSent == nil {
+		return nil, settlement.ErrPeerNoSettlements
+	}
+	return totalSent, nil
 }
 
 // TotalReceived returns the total amount received from a peer
@@ -204,24 +468,29 @@ func (s *Service) TotalReceived(peer swarm.Address) (totalReceived *big.Int, err
 }
 
 // SettlementsSent returns sent settlements for each individual known peer
-func (s *Service) SettlementsSent() (map[string]*big.Int, error) {
+func (s *Service) SettlementsSent(ctx context.Context) (map[string]*big.Int, error) {
 	result := make(map[string]*big.Int)
 	if s.chequebook == nil {
 		return result, nil
 	}
-	cheques, err := s.chequebook.LastCheques()
+	cheques, err := s.chequebook.LastCheques(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for beneficiary, cheque := range cheques {
-		peer, known, err := s.addressbook.BeneficiaryPeer(beneficiary)
+		peer, known, err := s.beneficiaryPeer(beneficiary)
 		if err != nil {
 			return nil, err
 		}
 		if !known {
 			continue
 		}
+		// a peer which announced another beneficiary received cheques to each of them
+		if sent, ok := result[peer.String()]; ok {
+			result[peer.String()] = new(big.Int).Add(sent, cheque.CumulativePayout)
+			continue
+		}
 		result[peer.String()] = cheque.CumulativePayout
 	}
 
@@ -249,10 +518,82 @@ func (s *Service) SettlementsReceived() (map[string]*big.Int, error) {
 	return result, err
 }
 
+// announcedChequebookKey computes the key where to store the chequebook last announced to a peer.
+func announcedChequebookKey(peer swarm.Address) string {
+	return fmt.Sprintf("%s%s", announcedChequebookPrefix, peer)
+}
+
+// AnnounceChequebook announces our chequebook to the peer unless it was
+// already announced to it. This way peers which stored the address of a
+// previous chequebook accept our cheques again after it was redeployed.
+func (s *Service) AnnounceChequebook(ctx context.Context, peer swarm.Address) error {
+	if s.chequebook == nil {
+		return nil
+	}
+	chequebookAddress := s.chequebook.Address()
+	if chequebookAddress == (common.Address{}) {
+		return nil
+	}
+
+	var announced common.Address
+	err := s.store.Get(announcedChequebookKey(peer), &announced)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	if err == nil && announced == chequebookAddress {
+		return nil
+	}
+
+	if err := s.proto.AnnounceChequebook(ctx, peer, chequebookAddress); err != nil {
+		return err
+	}
+	return s.store.Put(announcedChequebookKey(peer), chequebookAddress)
+}
+
+// ReceiveChequebookAnnouncement is called by the swap protocol if a peer
+// announces its chequebook. The chequebook replaces the one stored for the
+// peer if it was deployed by a trusted factory and is issued by the peer.
+func (s *Service) ReceiveChequebookAnnouncement(ctx context.Context, peer swarm.Address, chequebookAddress common.Address) error {
+	beneficiary, known, err := s.addressbook.Beneficiary(peer)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return ErrUnknownBeneficary
+	}
+
+	current, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return err
+	}
+	if known && current == chequebookAddress {
+		return nil
+	}
+
+	if err := s.chequeStore.VerifyChequebookIssuer(ctx, chequebookAddress, beneficiary); err != nil {
+		return fmt.Errorf("rejecting chequebook announcement: %w", err)
+	}
+	if err := s.verifyRegistered(ctx, peer, beneficiary, registry.Record{Chequebook: chequebookAddress}); err != nil {
+		return fmt.Errorf("rejecting chequebook announcement: %w", err)
+	}
+
+	if known {
+		s.logger.Info("peer announced a new chequebook", "peer_address", peer, "old_chequebook", current, "new_chequebook", chequebookAddress)
+	}
+	return s.addressbook.PutChequebook(peer, chequebookAddress)
+}
+
 // Handshake is called by the swap protocol when a handshake is received.
 func (s *Service) Handshake(peer swarm.Address, beneficiary common.Address) error {
 	loggerV1 := s.logger.V(1).Register()
 
+	if err := s.restoreArchive(peer, beneficiary); err != nil {
+		return fmt.Errorf("restore archive: %w", err)
+	}
+	if err := s.touchPeer(peer); err != nil {
+		return err
+	}
+
 	oldPeer, known, err := s.addressbook.BeneficiaryPeer(beneficiary)
 	if err != nil {
 		return err
@@ -277,7 +618,7 @@ func (s *Service) Handshake(peer swarm.Address, beneficiary common.Address) erro
 // LastSentCheque returns the last sent cheque for the peer
 func (s *Service) LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, error) {
 
-	common, known, err := s.addressbook.Beneficiary(peer)
+	common, known, err := s.beneficiary(peer)
 
 	if err != nil {
 		return nil, err
@@ -311,11 +652,11 @@ func (s *Service) LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheq
 }
 
 // LastSentCheques returns the list of last sent cheques for all peers
-func (s *Service) LastSentCheques() (map[string]*chequebook.SignedCheque, error) {
+func (s *Service) LastSentCheques(ctx context.Context) (map[string]*chequebook.SignedCheque, error) {
 	if s.chequebook == nil {
 		return nil, ErrNoChequebook
 	}
-	lastcheques, err := s.chequebook.LastCheques()
+	lastcheques, err := s.chequebook.LastCheques(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -323,7 +664,7 @@ func (s *Service) LastSentCheques() (map[string]*chequebook.SignedCheque, error)
 	resultmap := make(map[string]*chequebook.SignedCheque, len(lastcheques))
 
 	for i, j := range lastcheques {
-		addr, known, err := s.addressbook.BeneficiaryPeer(i)
+		addr, known, err := s.beneficiaryPeer(i)
 		if err == nil && known {
 			resultmap[addr.String()] = j
 		}
@@ -360,7 +701,179 @@ func (s *Service) CashCheque(ctx context.Context, peer swarm.Address) (common.Ha
 	if !known {
 		return common.Hash{}, chequebook.ErrNoCheque
 	}
-	return s.cashout.CashCheque(ctx, chequebookAddress, s.cashoutAddress)
+	var txHash common.Hash
+	err = s.run(ctx, workerpool.PriorityCashout, func(ctx context.Context) (err error) {
+		txHash, err = s.cashout.CashCheque(ctx, chequebookAddress, s.cashoutAddress)
+		return err
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	s.publish(events.Event{
+		Type:       events.TypeCashout,
+		Peer:       peer,
+		Chequebook: chequebookAddress,
+		TxHash:     txHash,
+	})
+
+	return txHash, nil
+}
+
+// CashChequeBatch sends cashing transactions for the last cheques of the peers,
+// including the cheques for previous beneficiaries not yet cashed. The results
+// are in the order of the peers, one per peer. If sending fails midway, the
+// results are returned with the error so that the cashouts sent are not lost.
+func (s *Service) CashChequeBatch(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	results := make([]chequebook.BatchCashoutResult, len(peers))
+
+	var (
+		chequebooks []common.Address
+		indices     []int // index into results of every chequebook
+	)
+	for i, peer := range peers {
+		chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if !known {
+			results[i].Err = chequebook.ErrNoCheque
+			continue
+		}
+		results[i].Chequebook = chequebookAddress
+		chequebooks = append(chequebooks, chequebookAddress)
+		indices = append(indices, i)
+	}
+
+	if len(chequebooks) == 0 {
+		return results, nil
+	}
+
+	var batchResults []chequebook.BatchCashoutResult
+	err := s.run(ctx, workerpool.PriorityCashout, func(ctx context.Context) (err error) {
+		batchResults, err = s.cashout.CashChequeBatch(ctx, chequebooks, s.cashoutAddress)
+		return err
+	})
+	if batchResults == nil {
+		return nil, err
+	}
+	// a chequebook has adjacent results for every beneficiary, the peer gets
+	// the last one, which is for the cheque of LastReceivedCheque
+	j := 0
+	for k, i := range indices {
+		for j < len(batchResults) && batchResults[j].Chequebook == chequebooks[k] {
+			result := batchResults[j]
+			j++
+			results[i] = result
+			if result.Err == nil && result.TxHash != (common.Hash{}) {
+				event := events.Event{
+					Type:       events.TypeCashout,
+					Peer:       peers[i],
+					Chequebook: result.Chequebook,
+					TxHash:     result.TxHash,
+				}
+				if result.Cheque != nil {
+					event.Amount = result.Cheque.CumulativePayout
+				}
+				s.publish(event)
+			}
+		}
+	}
+
+	return results, err
+}
+
+// ReceivedChequebook is a chequebook a peer sent cheques from.
+type ReceivedChequebook struct {
+	Chequebook common.Address
+	Current    bool // the chequebook the peer currently pays from
+	LastCheque *chequebook.SignedCheque
+	Uncashed   *big.Int // amount of the last cheque not yet cashed out
+}
+
+// ReceivedChequebooks returns the last cheque and uncashed amount of every
+// chequebook the peer sent cheques from, the current one first. A peer which
+// redeployed its chequebook may still owe us the cheques of its previous ones.
+func (s *Service) ReceivedChequebooks(ctx context.Context, peer swarm.Address) ([]ReceivedChequebook, error) {
+	chequebooks, err := s.addressbook.Chequebooks(peer)
+	if err != nil {
+		return nil, err
+	}
+	if len(chequebooks) == 0 {
+		return nil, chequebook.ErrNoCheque
+	}
+
+	received := make([]ReceivedChequebook, 0, len(chequebooks))
+	for i, chequebookAddress := range chequebooks {
+		cheque, err := s.chequeStore.LastCheque(chequebookAddress)
+		if errors.Is(err, chequebook.ErrNoCheque) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		status, err := s.cashoutStatus(ctx, peer, chequebookAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		received = append(received, ReceivedChequebook{
+			Chequebook: chequebookAddress,
+			Current:    i == 0,
+			LastCheque: cheque,
+			Uncashed:   status.UncashedAmount,
+		})
+	}
+	return received, nil
+}
+
+// CashChequebooks sends cashing transactions for the last cheques of every
+// chequebook of the peer which still owes us, bundled into one transaction if
+// possible. The results are in the order of ReceivedChequebooks. If sending
+// fails midway, the results are returned with the error.
+func (s *Service) CashChequebooks(ctx context.Context, peer swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	received, err := s.ReceivedChequebooks(ctx, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	var chequebooks []common.Address
+	for _, r := range received {
+		if r.Uncashed.Sign() > 0 {
+			chequebooks = append(chequebooks, r.Chequebook)
+		}
+	}
+	if len(chequebooks) == 0 {
+		return nil, chequebook.ErrNoCheque
+	}
+
+	var results []chequebook.BatchCashoutResult
+	err = s.run(ctx, workerpool.PriorityCashout, func(ctx context.Context) (err error) {
+		results, err = s.cashout.CashChequeBatch(ctx, chequebooks, s.cashoutAddress)
+		return err
+	})
+	if results == nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Err == nil && result.TxHash != (common.Hash{}) {
+			event := events.Event{
+				Type:       events.TypeCashout,
+				Peer:       peer,
+				Chequebook: result.Chequebook,
+				TxHash:     result.TxHash,
+			}
+			if result.Cheque != nil {
+				event.Amount = result.Cheque.CumulativePayout
+			}
+			s.publish(event)
+		}
+	}
+
+	return results, err
 }
 
 // CashoutStatus gets the status of the latest cashout transaction for the peers chequebook
@@ -372,7 +885,78 @@ func (s *Service) CashoutStatus(ctx context.Context, peer swarm.Address) (*chequ
 	if !known {
 		return nil, chequebook.ErrNoCheque
 	}
-	return s.cashout.CashoutStatus(ctx, chequebookAddress)
+	return s.cashoutStatus(ctx, peer, chequebookAddress)
+}
+
+// ChequeCashouts returns the cashout transactions sent for cheques of the peer, oldest first.
+func (s *Service) ChequeCashouts(peer swarm.Address) ([]chequebook.ChequeCashout, error) {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, chequebook.ErrNoCheque
+	}
+	return s.cashout.ChequeCashouts(chequebookAddress)
+}
+
+// CashoutTransactionCheques returns the cheques cashed by the cashout transaction.
+func (s *Service) CashoutTransactionCheques(txHash common.Hash) ([]chequebook.SignedCheque, error) {
+	return s.cashout.TransactionCheques(txHash)
+}
+
+// ReconcileCashouts finds received cheques which were never cashed or cashed more than once.
+func (s *Service) ReconcileCashouts(ctx context.Context) (*chequebook.CashoutReconciliation, error) {
+	var reconciliation *chequebook.CashoutReconciliation
+	err := s.run(ctx, workerpool.PriorityReconciliation, func(ctx context.Context) (err error) {
+		reconciliation, err = s.cashout.ReconcileCashouts(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// CashoutAttempts returns the recorded attempts to cash the cheques of the peer, oldest first.
+func (s *Service) CashoutAttempts(ctx context.Context, peer swarm.Address) ([]chequebook.CashoutAttempt, error) {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, chequebook.ErrNoCheque
+	}
+	return s.cashout.CashoutAttempts(ctx, chequebookAddress)
+}
+
+// RetryCashout cashes the last cheque of the peer again if the last attempt
+// failed, reverted or was dropped.
+func (s *Service) RetryCashout(ctx context.Context, peer swarm.Address) (common.Hash, error) {
+	chequebookAddress, known, err := s.addressbook.Chequebook(peer)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !known {
+		return common.Hash{}, chequebook.ErrNoCheque
+	}
+	var txHash common.Hash
+	err = s.run(ctx, workerpool.PriorityCashout, func(ctx context.Context) (err error) {
+		txHash, err = s.cashout.RetryCashout(ctx, chequebookAddress, s.cashoutAddress)
+		return err
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	s.publish(events.Event{
+		Type:       events.TypeCashout,
+		Peer:       peer,
+		Chequebook: chequebookAddress,
+		TxHash:     txHash,
+	})
+
+	return txHash, nil
 }
 
 func (s *Service) GetDeductionForPeer(peer swarm.Address) (bool, error) {
@@ -400,7 +984,7 @@ func (*NoOpSwap) TotalReceived(peer swarm.Address) (totalSent *big.Int, err erro
 }
 
 // SettlementsSent returns sent settlements for each individual known peer
-func (*NoOpSwap) SettlementsSent() (map[string]*big.Int, error) {
+func (*NoOpSwap) SettlementsSent(ctx context.Context) (map[string]*big.Int, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
 
@@ -414,7 +998,7 @@ func (*NoOpSwap) LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, e
 }
 
 // LastSentCheques returns the list of last sent cheques for all peers
-func (*NoOpSwap) LastSentCheques() (map[string]*chequebook.SignedCheque, error) {
+func (*NoOpSwap) LastSentCheques(ctx context.Context) (map[string]*chequebook.SignedCheque, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
 
@@ -428,6 +1012,11 @@ func (*NoOpSwap) LastReceivedCheques() (map[string]*chequebook.SignedCheque, err
 	return nil, postagecontract.ErrChainDisabled
 }
 
+// LastCheques returns the last sent and received cheques of all peers in a stable order
+func (*NoOpSwap) LastCheques(ctx context.Context, order ChequeOrder, cursor string, limit int) (*PeerChequesPage, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
 // CashCheque sends a cashing transaction for the last cheque of the peer
 func (*NoOpSwap) CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error) {
 	return common.Hash{}, postagecontract.ErrChainDisabled
@@ -437,3 +1026,80 @@ func (*NoOpSwap) CashCheque(ctx context.Context, peer swarm.Address) (common.Has
 func (*NoOpSwap) CashoutStatus(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error) {
 	return nil, postagecontract.ErrChainDisabled
 }
+
+// CashChequeBatch sends cashing transactions for the last cheques of several peers
+func (*NoOpSwap) CashChequeBatch(ctx context.Context, peers []swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) ReceivedChequebooks(ctx context.Context, peer swarm.Address) ([]ReceivedChequebook, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) CashChequebooks(ctx context.Context, peer swarm.Address) ([]chequebook.BatchCashoutResult, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) RecentBounces() []Bounce {
+	return nil
+}
+
+func (*NoOpSwap) ChequeCashouts(peer swarm.Address) ([]chequebook.ChequeCashout, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) CashoutTransactionCheques(txHash common.Hash) ([]chequebook.SignedCheque, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) ReconcileCashouts(ctx context.Context) (*chequebook.CashoutReconciliation, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) PreviewPay(ctx context.Context, peer swarm.Address, amount *big.Int) (*chequebook.IssuePreview, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) CashoutAttempts(ctx context.Context, peer swarm.Address) ([]chequebook.CashoutAttempt, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) RetryCashout(ctx context.Context, peer swarm.Address) (common.Hash, error) {
+	return common.Hash{}, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) AssignCheque(ctx context.Context, peer swarm.Address, cashier, recipient common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) ChequeAssignment(ctx context.Context, peer swarm.Address) (*chequebook.ChequeAssignmentStatus, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) RemoveChequeAssignment(peer swarm.Address) error {
+	return postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) ImportPeer(ctx context.Context, peer swarm.Address, state PeerImport) error {
+	return postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) ImportPeers(ctx context.Context, imports []PeerImportEntry) []error {
+	errs := make([]error, len(imports))
+	for i := range imports {
+		errs[i] = postagecontract.ErrChainDisabled
+	}
+	return errs
+}
+
+func (*NoOpSwap) PeerStatement(ctx context.Context, peer swarm.Address) (*StatementCheck, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) RotateBeneficiary(ctx context.Context, beneficiary common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, []BeneficiaryAnnouncement, error) {
+	return nil, nil, postagecontract.ErrChainDisabled
+}
+
+func (*NoOpSwap) Beneficiaries() (*chequebook.BeneficiaryRotation, error) {
+	return nil, postagecontract.ErrChainDisabled
+}
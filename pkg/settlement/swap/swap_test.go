@@ -14,17 +14,28 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement/events"
 	"github.com/ethersphere/bee/pkg/settlement/swap"
 	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
 	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/registry"
+	mockregistry "github.com/ethersphere/bee/pkg/settlement/swap/registry/mock"
 	"github.com/ethersphere/bee/pkg/settlement/swap/swapprotocol"
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
 	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 type swapProtocolMock struct {
-	emitCheque func(context.Context, swarm.Address, common.Address, *big.Int, swapprotocol.IssueFunc) (*big.Int, error)
+	emitCheque          func(context.Context, swarm.Address, common.Address, *big.Int, swapprotocol.IssueFunc) (*big.Int, error)
+	announceChequebook  func(context.Context, swarm.Address, common.Address) error
+	announceBeneficiary func(context.Context, swarm.Address, common.Address) error
+	requestStatement    func(context.Context, swarm.Address) (*chequebook.Statement, error)
+	sendReminder        func(context.Context, swarm.Address, *big.Int, *big.Int, time.Duration) error
+	requestReceipt      func(context.Context, swarm.Address) (*chequebook.Receipt, error)
+	paymentAmount       func(swarm.Address, *big.Int) (chequebook.Tokens, error)
 }
 
 func (m *swapProtocolMock) EmitCheque(ctx context.Context, peer swarm.Address, beneficiary common.Address, value *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
@@ -34,6 +45,48 @@ func (m *swapProtocolMock) EmitCheque(ctx context.Context, peer swarm.Address, b
 	return nil, errors.New("not implemented")
 }
 
+func (m *swapProtocolMock) AnnounceChequebook(ctx context.Context, peer swarm.Address, chequebook common.Address) error {
+	if m.announceChequebook != nil {
+		return m.announceChequebook(ctx, peer, chequebook)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *swapProtocolMock) AnnounceBeneficiary(ctx context.Context, peer swarm.Address, beneficiary common.Address) error {
+	if m.announceBeneficiary != nil {
+		return m.announceBeneficiary(ctx, peer, beneficiary)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *swapProtocolMock) RequestStatement(ctx context.Context, peer swarm.Address) (*chequebook.Statement, error) {
+	if m.requestStatement != nil {
+		return m.requestStatement(ctx, peer)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *swapProtocolMock) SendReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error {
+	if m.sendReminder != nil {
+		return m.sendReminder(ctx, peer, debt, threshold, overdue)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *swapProtocolMock) RequestReceipt(ctx context.Context, peer swarm.Address) (*chequebook.Receipt, error) {
+	if m.requestReceipt != nil {
+		return m.requestReceipt(ctx, peer)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *swapProtocolMock) PaymentAmount(peer swarm.Address, amount *big.Int) (chequebook.Tokens, error) {
+	if m.paymentAmount != nil {
+		return m.paymentAmount(peer, amount)
+	}
+	return chequebook.Tokens{}, errors.New("not implemented")
+}
+
 type testObserver struct {
 	receivedCalled chan notifyPaymentReceivedCall
 	sentCalled     chan notifyPaymentSentCall
@@ -98,6 +151,7 @@ type addressbookMock struct {
 	chequebook      func(peer swarm.Address) (chequebookAddress common.Address, known bool, err error)
 	beneficiaryPeer func(beneficiary common.Address) (peer swarm.Address, known bool, err error)
 	chequebookPeer  func(chequebook common.Address) (peer swarm.Address, known bool, err error)
+	chequebooks     func(peer swarm.Address) ([]common.Address, error)
 	putBeneficiary  func(peer swarm.Address, beneficiary common.Address) error
 	putChequebook   func(peer swarm.Address, chequebook common.Address) error
 	addDeductionFor func(peer swarm.Address) error
@@ -121,6 +175,9 @@ func (m *addressbookMock) BeneficiaryPeer(beneficiary common.Address) (peer swar
 func (m *addressbookMock) ChequebookPeer(chequebook common.Address) (peer swarm.Address, known bool, err error) {
 	return m.chequebookPeer(chequebook)
 }
+func (m *addressbookMock) Chequebooks(peer swarm.Address) ([]common.Address, error) {
+	return m.chequebooks(peer)
+}
 func (m *addressbookMock) PutBeneficiary(peer swarm.Address, beneficiary common.Address) error {
 	return m.putBeneficiary(peer, beneficiary)
 }
@@ -141,8 +198,12 @@ func (m *addressbookMock) GetDeductionBy(peer swarm.Address) (bool, error) {
 }
 
 type cashoutMock struct {
-	cashCheque    func(ctx context.Context, chequebook common.Address, recipient common.Address) (common.Hash, error)
-	cashoutStatus func(ctx context.Context, chequebookAddress common.Address) (*chequebook.CashoutStatus, error)
+	cashCheque      func(ctx context.Context, chequebook common.Address, recipient common.Address) (common.Hash, error)
+	cashoutStatus   func(ctx context.Context, chequebookAddress common.Address) (*chequebook.CashoutStatus, error)
+	cashBatch       func(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]chequebook.BatchCashoutResult, error)
+	cashoutAttempts func(ctx context.Context, chequebookAddress common.Address) ([]chequebook.CashoutAttempt, error)
+	retryCashout    func(ctx context.Context, chequebook common.Address, recipient common.Address) (common.Hash, error)
+	chequeCashouts  func(chequebook common.Address) ([]chequebook.ChequeCashout, error)
 }
 
 func (m *cashoutMock) CashCheque(ctx context.Context, chequebook, recipient common.Address) (common.Hash, error) {
@@ -151,6 +212,44 @@ func (m *cashoutMock) CashCheque(ctx context.Context, chequebook, recipient comm
 func (m *cashoutMock) CashoutStatus(ctx context.Context, chequebookAddress common.Address) (*chequebook.CashoutStatus, error) {
 	return m.cashoutStatus(ctx, chequebookAddress)
 }
+func (m *cashoutMock) CashChequeBatch(ctx context.Context, chequebooks []common.Address, recipient common.Address) ([]chequebook.BatchCashoutResult, error) {
+	return m.cashBatch(ctx, chequebooks, recipient)
+}
+func (m *cashoutMock) CashoutBatch(ctx context.Context, batch []chequebook.BeneficiaryCheques) ([]chequebook.BatchCashoutResult, error) {
+	var results []chequebook.BatchCashoutResult
+	for _, b := range batch {
+		r, err := m.cashBatch(ctx, b.Chequebooks, b.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+func (m *cashoutMock) ChequeCashouts(chequebookAddress common.Address) ([]chequebook.ChequeCashout, error) {
+	return m.chequeCashouts(chequebookAddress)
+}
+func (m *cashoutMock) TransactionCheques(txHash common.Hash) ([]chequebook.SignedCheque, error) {
+	return nil, nil
+}
+func (m *cashoutMock) ReconcileCashouts(ctx context.Context) (*chequebook.CashoutReconciliation, error) {
+	return nil, nil
+}
+func (m *cashoutMock) CashoutAttempts(ctx context.Context, chequebookAddress common.Address) ([]chequebook.CashoutAttempt, error) {
+	return m.cashoutAttempts(ctx, chequebookAddress)
+}
+func (m *cashoutMock) RetryCashout(ctx context.Context, chequebook, recipient common.Address) (common.Hash, error) {
+	return m.retryCashout(ctx, chequebook, recipient)
+}
+func (m *cashoutMock) AssignCheque(ctx context.Context, chequebook, cashier, recipient common.Address, callerPayout *big.Int) (*chequebook.ChequeAssignment, error) {
+	return nil, nil
+}
+func (m *cashoutMock) ChequeAssignment(ctx context.Context, chequebook common.Address) (*chequebook.ChequeAssignmentStatus, error) {
+	return nil, nil
+}
+func (m *cashoutMock) RemoveChequeAssignment(chequebook common.Address) error {
+	return nil
+}
 
 func TestReceiveCheque(t *testing.T) {
 	t.Parallel()
@@ -323,6 +422,63 @@ func TestReceiveChequeReject(t *testing.T) {
 
 }
 
+func TestReceiveChequeDuplicate(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	store := mockstore.NewStateStore()
+	chequebookService := mockchequebook.NewChequebook()
+	chequebookAddress := common.HexToAddress("0xcd")
+
+	peer := swarm.MustParseHexAddress("abcd")
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      common.HexToAddress("0xab"),
+			CumulativePayout: big.NewInt(10),
+			Chequebook:       chequebookAddress,
+		},
+		Signature: []byte{},
+	}
+
+	chequeStore := mockchequestore.NewChequeStore(
+		mockchequestore.WithReceiveChequeFunc(func(ctx context.Context, c *chequebook.SignedCheque, e *big.Int, d *big.Int) (*big.Int, error) {
+			return nil, chequebook.ErrDuplicateCheque
+		}),
+	)
+	networkID := uint64(1)
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			return chequebookAddress, true, nil
+		},
+	}
+
+	observer := newTestObserver()
+
+	swap := swap.New(
+		&swapProtocolMock{},
+		logger,
+		store,
+		chequebookService,
+		chequeStore,
+		addressbook,
+		networkID,
+		&cashoutMock{},
+		observer,
+		common.Address{},
+	)
+
+	err := swap.ReceiveCheque(context.Background(), peer, cheque, big.NewInt(10), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-observer.receivedCalled:
+		t.Fatal("duplicate cheque credited")
+	default:
+	}
+}
+
 func TestReceiveChequeWrongChequebook(t *testing.T) {
 	t.Parallel()
 
@@ -438,6 +594,150 @@ func TestPay(t *testing.T) {
 	}
 }
 
+// TestPayFromCredit checks that payments covered by the prepaid credit are
+// made without opening a stream to the peer.
+func TestPayFromCredit(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xcd")
+	peer := swarm.MustParseHexAddress("abcd")
+	observer := newTestObserver()
+
+	var issued []*big.Int
+	chequebookService := chequebook.NewGranularService(
+		mockchequebook.NewChequebook(
+			mockchequebook.WithChequebookIssueFunc(func(ctx context.Context, b common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+				issued = append(issued, amount)
+				return big.NewInt(1000), sendChequeFunc(&chequebook.SignedCheque{})
+			}),
+			mockchequebook.WithChequebookAvailableBalanceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(900), nil
+			}),
+		),
+		mockstore.NewStateStore(),
+		big.NewInt(100),
+	)
+
+	var emitted int
+	swapService := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				emitted++
+				return issueFunc(ctx, b, chequebook.MustNewTokens(a), func(*chequebook.SignedCheque) error { return nil })
+			},
+			paymentAmount: func(_ swarm.Address, amount *big.Int) (chequebook.Tokens, error) {
+				return chequebook.MustNewTokens(amount), nil
+			},
+		},
+		log.Noop,
+		mockstore.NewStateStore(),
+		chequebookService,
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{
+			beneficiary: func(swarm.Address) (common.Address, bool, error) {
+				return beneficiary, true, nil
+			},
+		},
+		1,
+		&cashoutMock{},
+		observer,
+		common.Address{},
+	)
+
+	for i, tc := range []struct {
+		amount  int64
+		emitted int
+		credit  int64
+	}{
+		// a rounded cheque is sent, the excess becomes credit
+		{amount: 30, emitted: 1, credit: 70},
+		// covered by the credit, no cheque is sent
+		{amount: 50, emitted: 1, credit: 20},
+		// not covered, the credit is used before rounding
+		{amount: 40, emitted: 2, credit: 80},
+	} {
+		swapService.Pay(context.Background(), peer, big.NewInt(tc.amount))
+
+		sent := <-observer.sentCalled
+		if sent.err != nil || sent.amount.Int64() != tc.amount {
+			t.Fatalf("payment %d: notified amount %d and error %v", i, sent.amount, sent.err)
+		}
+		if emitted != tc.emitted || len(issued) != tc.emitted {
+			t.Fatalf("payment %d: emitted %d and issued %d cheques, want %d", i, emitted, len(issued), tc.emitted)
+		}
+		credit, err := chequebookService.PrepaidCredit(beneficiary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credit.Int64() != tc.credit {
+			t.Fatalf("payment %d: got credit %d, want %d", i, credit, tc.credit)
+		}
+	}
+}
+
+func TestPayWorkerPoolFull(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("abcd")
+	amount := big.NewInt(50)
+	observer := newTestObserver()
+
+	var emitCalled bool
+	swapService := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				emitCalled = true
+				return amount, nil
+			},
+		},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{
+			beneficiary: func(p swarm.Address) (common.Address, bool, error) {
+				return common.HexToAddress("0xcd"), true, nil
+			},
+		},
+		1,
+		&cashoutMock{},
+		observer,
+		common.Address{},
+	)
+
+	// the only worker is busy and the queue of one is taken
+	workers := workerpool.New(1, 1)
+	t.Cleanup(func() { _ = workers.Close() })
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := workers.Submit(workerpool.PriorityIssuance, func() {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := workers.Submit(workerpool.PriorityIssuance, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	swapService.SetWorkerPool(workers)
+
+	swapService.Pay(context.Background(), peer, amount)
+
+	select {
+	case call := <-observer.sentCalled:
+		if !errors.Is(call.err, workerpool.ErrQueueFull) {
+			t.Fatalf("got error %v, want %v", call.err, workerpool.ErrQueueFull)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("payment failure not notified")
+	}
+	if emitCalled {
+		t.Fatal("cheque sent despite full queue")
+	}
+	close(release)
+}
+
 func TestPayIssueError(t *testing.T) {
 	t.Parallel()
 
@@ -693,6 +993,174 @@ func TestMigratePeer(t *testing.T) {
 	}
 }
 
+func TestAnnounceChequebook(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	peer := swarm.MustParseHexAddress("deff")
+	chequebookAddress := common.HexToAddress("0xcb")
+
+	var announced []common.Address
+	proto := &swapProtocolMock{
+		announceChequebook: func(ctx context.Context, p swarm.Address, chequebook common.Address) error {
+			if !p.Equal(peer) {
+				t.Fatalf("announced to %v, want %v", p, peer)
+			}
+			announced = append(announced, chequebook)
+			return nil
+		},
+	}
+	newService := func(chequebookAddress common.Address) *swap.Service {
+		return swap.New(
+			proto,
+			log.Noop,
+			store,
+			mockchequebook.NewChequebook(mockchequebook.WithChequebookAddressFunc(func() common.Address {
+				return chequebookAddress
+			})),
+			mockchequestore.NewChequeStore(),
+			swap.NewAddressbook(store),
+			1,
+			&cashoutMock{},
+			nil,
+			common.Address{},
+		)
+	}
+
+	swapService := newService(chequebookAddress)
+	for i := 0; i < 2; i++ {
+		if err := swapService.AnnounceChequebook(context.Background(), peer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(announced) != 1 || announced[0] != chequebookAddress {
+		t.Fatalf("got announcements %v, want %v", announced, []common.Address{chequebookAddress})
+	}
+
+	// the redeployed chequebook is announced again
+	redeployed := common.HexToAddress("0xcc")
+	if err := newService(redeployed).AnnounceChequebook(context.Background(), peer); err != nil {
+		t.Fatal(err)
+	}
+	if len(announced) != 2 || announced[1] != redeployed {
+		t.Fatalf("got announcements %v, want %v", announced, []common.Address{chequebookAddress, redeployed})
+	}
+}
+
+func TestReceiveChequebookAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("deff")
+	beneficiary := common.HexToAddress("0xbe")
+	oldChequebook := common.HexToAddress("0xcb")
+	newChequebook := common.HexToAddress("0xcc")
+	invalidChequebook := common.HexToAddress("0xcd")
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithVerifyChequebookIssuerFunc(func(ctx context.Context, chequebookAddress, issuer common.Address) error {
+			if issuer != beneficiary {
+				t.Fatalf("verified issuer %x, want %x", issuer, beneficiary)
+			}
+			if chequebookAddress == invalidChequebook {
+				return chequebook.ErrWrongIssuer
+			}
+			return nil
+		})),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+
+	err := swapService.ReceiveChequebookAnnouncement(context.Background(), peer, newChequebook)
+	if !errors.Is(err, swap.ErrUnknownBeneficary) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrUnknownBeneficary)
+	}
+
+	if err := addressbook.PutBeneficiary(peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+	if err := addressbook.PutChequebook(peer, oldChequebook); err != nil {
+		t.Fatal(err)
+	}
+
+	err = swapService.ReceiveChequebookAnnouncement(context.Background(), peer, invalidChequebook)
+	if !errors.Is(err, chequebook.ErrWrongIssuer) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrWrongIssuer)
+	}
+
+	if err := swapService.ReceiveChequebookAnnouncement(context.Background(), peer, newChequebook); err != nil {
+		t.Fatal(err)
+	}
+	got, known, err := addressbook.Chequebook(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !known || got != newChequebook {
+		t.Fatalf("got chequebook %x, want %x", got, newChequebook)
+	}
+}
+
+func TestReceiveAnnouncementRegistry(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+	peer := swarm.MustParseHexAddress("deff")
+	identity := common.HexToAddress("0xbe")
+	registeredChequebook := common.HexToAddress("0xcc")
+	registeredBeneficiary := common.HexToAddress("0xbf")
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithVerifyChequebookIssuerFunc(func(ctx context.Context, chequebookAddress, issuer common.Address) error {
+			return nil
+		})),
+		addressbook,
+		1,
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+	swapService.SetRegistry(mockregistry.New(mockregistry.WithLookupFunc(func(ctx context.Context, registrant common.Address, overlay swarm.Address) (registry.Record, bool, error) {
+		if registrant != identity || !overlay.Equal(peer) {
+			t.Fatalf("looked up record of %x for %s, want %x for %s", registrant, overlay, identity, peer)
+		}
+		return registry.Record{Chequebook: registeredChequebook, Beneficiary: registeredBeneficiary}, true, nil
+	})))
+
+	if err := addressbook.PutBeneficiary(peer, identity); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	err := swapService.ReceiveChequebookAnnouncement(ctx, peer, common.HexToAddress("0xcd"))
+	if !errors.Is(err, registry.ErrMismatch) {
+		t.Fatalf("got error %v, want %v", err, registry.ErrMismatch)
+	}
+	if err := swapService.ReceiveChequebookAnnouncement(ctx, peer, registeredChequebook); err != nil {
+		t.Fatal(err)
+	}
+
+	err = swapService.ReceiveBeneficiaryAnnouncement(ctx, peer, common.HexToAddress("0xba"))
+	if !errors.Is(err, registry.ErrMismatch) {
+		t.Fatalf("got error %v, want %v", err, registry.ErrMismatch)
+	}
+	if err := swapService.ReceiveBeneficiaryAnnouncement(ctx, peer, registeredBeneficiary); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestCashout(t *testing.T) {
 	t.Parallel()
 
@@ -735,6 +1203,12 @@ func TestCashout(t *testing.T) {
 		ourChequebookAddress,
 	)
 
+	feed := events.NewFeed()
+	defer feed.Close()
+	swapService.SetEventPublisher(feed)
+	c, cancel := feed.Subscribe()
+	defer cancel()
+
 	returnedHash, err := swapService.CashCheque(context.Background(), peer)
 	if err != nil {
 		t.Fatal(err)
@@ -743,22 +1217,138 @@ func TestCashout(t *testing.T) {
 	if returnedHash != txHash {
 		t.Fatalf("go wrong tx hash. wanted %v, got %v", txHash, returnedHash)
 	}
+
+	e := <-c
+	if e.Type != events.TypeCashout || !e.Peer.Equal(peer) || e.Chequebook != theirChequebookAddress || e.TxHash != txHash {
+		t.Fatalf("unexpected event %+v", e)
+	}
 }
 
-func TestCashoutStatus(t *testing.T) {
+func TestRetryCashout(t *testing.T) {
 	t.Parallel()
 
-	logger := log.Noop
-	store := mockstore.NewStateStore()
-
 	theirChequebookAddress := common.HexToAddress("ffff")
+	ourChequebookAddress := common.HexToAddress("fffa")
 	peer := swarm.MustParseHexAddress("abcd")
+	unknownPeer := swarm.MustParseHexAddress("abce")
+	txHash := common.HexToHash("eeee")
 	addressbook := &addressbookMock{
 		chequebook: func(p swarm.Address) (common.Address, bool, error) {
-			if !peer.Equal(p) {
-				t.Fatal("querying chequebook for wrong peer")
-			}
-			return theirChequebookAddress, true, nil
+			return theirChequebookAddress, peer.Equal(p), nil
+		},
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			retryCashout: func(ctx context.Context, c common.Address, r common.Address) (common.Hash, error) {
+				if c != theirChequebookAddress || r != ourChequebookAddress {
+					t.Fatalf("retrying cashout of %v to %v, want %v to %v", c, r, theirChequebookAddress, ourChequebookAddress)
+				}
+				return txHash, nil
+			},
+		},
+		nil,
+		ourChequebookAddress,
+	)
+
+	feed := events.NewFeed()
+	defer feed.Close()
+	swapService.SetEventPublisher(feed)
+	c, cancel := feed.Subscribe()
+	defer cancel()
+
+	returnedHash, err := swapService.RetryCashout(context.Background(), peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if returnedHash != txHash {
+		t.Fatalf("got tx hash %v, want %v", returnedHash, txHash)
+	}
+
+	e := <-c
+	if e.Type != events.TypeCashout || !e.Peer.Equal(peer) || e.Chequebook != theirChequebookAddress || e.TxHash != txHash {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	if _, err := swapService.RetryCashout(context.Background(), unknownPeer); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+}
+
+func TestCashChequeBatch(t *testing.T) {
+	t.Parallel()
+
+	theirChequebookAddress := common.HexToAddress("ffff")
+	ourChequebookAddress := common.HexToAddress("fffa")
+	knownPeer := swarm.MustParseHexAddress("abcd")
+	unknownPeer := swarm.MustParseHexAddress("abce")
+	txHash := common.HexToHash("eeee")
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			return theirChequebookAddress, knownPeer.Equal(p), nil
+		},
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			cashBatch: func(ctx context.Context, chequebooks []common.Address, r common.Address) ([]chequebook.BatchCashoutResult, error) {
+				if len(chequebooks) != 1 || chequebooks[0] != theirChequebookAddress {
+					t.Fatalf("not cashing the right chequebooks. wanted %v, got %v", theirChequebookAddress, chequebooks)
+				}
+				if r != ourChequebookAddress {
+					t.Fatalf("not cashing with the right recipient. wanted %v, got %v", ourChequebookAddress, r)
+				}
+				return []chequebook.BatchCashoutResult{{Chequebook: theirChequebookAddress, TxHash: txHash}}, nil
+			},
+		},
+		nil,
+		ourChequebookAddress,
+	)
+
+	results, err := swapService.CashChequeBatch(context.Background(), []swarm.Address{unknownPeer, knownPeer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want %d", len(results), 2)
+	}
+	if !errors.Is(results[0].Err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", results[0].Err, chequebook.ErrNoCheque)
+	}
+	if results[1].Err != nil || results[1].TxHash != txHash {
+		t.Fatalf("got result %+v, want cashout in %v", results[1], txHash)
+	}
+}
+
+func TestCashoutStatus(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	store := mockstore.NewStateStore()
+
+	theirChequebookAddress := common.HexToAddress("ffff")
+	peer := swarm.MustParseHexAddress("abcd")
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			if !peer.Equal(p) {
+				t.Fatal("querying chequebook for wrong peer")
+			}
+			return theirChequebookAddress, true, nil
 		},
 	}
 
@@ -794,6 +1384,308 @@ func TestCashoutStatus(t *testing.T) {
 	}
 }
 
+func TestCashoutStatusBounced(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	store := mockstore.NewStateStore()
+
+	theirChequebookAddress := common.HexToAddress("ffff")
+	peer := swarm.MustParseHexAddress("abcd")
+	txHash := common.HexToHash("eeee")
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			return theirChequebookAddress, true, nil
+		},
+	}
+
+	status := &chequebook.CashoutStatus{
+		Last: &chequebook.LastCashout{
+			TxHash: txHash,
+			Result: &chequebook.CashChequeResult{
+				Bounced: true,
+			},
+		},
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		logger,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			cashoutStatus: func(ctx context.Context, c common.Address) (*chequebook.CashoutStatus, error) {
+				return status, nil
+			},
+		},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	blocklistDuration := 10 * time.Minute
+	var notifications []swap.DisconnectNotification
+	swapService.SetDisconnectNotifier(swap.DisconnectNotifierFunc(func(n swap.DisconnectNotification) error {
+		notifications = append(notifications, n)
+		return nil
+	}), blocklistDuration)
+
+	var bounced []events.Event
+	swapService.SubscribeEvents(func(e events.Event) {
+		if e.Type == events.TypeChequeBounced {
+			bounced = append(bounced, e)
+		}
+	})
+
+	// the same bounced cashout must only be reported once
+	for i := 0; i < 2; i++ {
+		if _, err := swapService.CashoutStatus(context.Background(), peer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+
+	n := notifications[0]
+	if !n.Peer.Equal(peer) {
+		t.Fatalf("got peer %v, want %v", n.Peer, peer)
+	}
+	if n.Reason != swap.DisconnectChequeBounced {
+		t.Fatalf("got reason %v, want %v", n.Reason, swap.DisconnectChequeBounced)
+	}
+	if n.Chequebook != theirChequebookAddress {
+		t.Fatalf("got chequebook %v, want %v", n.Chequebook, theirChequebookAddress)
+	}
+	if n.TxHash != txHash {
+		t.Fatalf("got tx hash %v, want %v", n.TxHash, txHash)
+	}
+	if n.BlocklistDuration != blocklistDuration {
+		t.Fatalf("got blocklist duration %v, want %v", n.BlocklistDuration, blocklistDuration)
+	}
+
+	if len(bounced) != 1 || !bounced[0].Peer.Equal(peer) || bounced[0].TxHash != txHash {
+		t.Fatalf("unexpected bounced events %+v", bounced)
+	}
+
+	bounces := swapService.RecentBounces()
+	if len(bounces) != 1 {
+		t.Fatalf("got %d bounces, want 1", len(bounces))
+	}
+	if !bounces[0].Peer.Equal(peer) || bounces[0].TxHash != txHash || bounces[0].Chequebook != theirChequebookAddress {
+		t.Fatalf("unexpected bounce %+v", bounces[0])
+	}
+}
+
+func TestCheckCashoutsBounced(t *testing.T) {
+	t.Parallel()
+
+	theirChequebookAddress := common.HexToAddress("ffff")
+	peer := swarm.MustParseHexAddress("abcd")
+	txHash := common.HexToHash("eeee")
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			return theirChequebookAddress, true, nil
+		},
+	}
+
+	var (
+		status   = &chequebook.CashoutStatus{Last: &chequebook.LastCashout{TxHash: txHash}}
+		statuses int
+	)
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			cashCheque: func(ctx context.Context, c, recipient common.Address) (common.Hash, error) {
+				return txHash, nil
+			},
+			cashoutStatus: func(ctx context.Context, c common.Address) (*chequebook.CashoutStatus, error) {
+				if c != theirChequebookAddress {
+					t.Fatalf("got status of chequebook %v, want %v", c, theirChequebookAddress)
+				}
+				statuses++
+				return status, nil
+			},
+		},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	var notifications []swap.DisconnectNotification
+	swapService.SetDisconnectNotifier(swap.DisconnectNotifierFunc(func(n swap.DisconnectNotification) error {
+		notifications = append(notifications, n)
+		return nil
+	}), time.Minute)
+
+	// nothing is checked before a cashout was sent
+	if err := swapService.CheckCashouts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if statuses != 0 {
+		t.Fatalf("got %d status checks, want 0", statuses)
+	}
+
+	if _, err := swapService.CashCheque(context.Background(), peer); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cashout is pending
+	if err := swapService.CheckCashouts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 0 {
+		t.Fatalf("got %d notifications for a pending cashout, want 0", len(notifications))
+	}
+
+	// the cashout bounced once its transaction was confirmed
+	status.Last.Result = &chequebook.CashChequeResult{Bounced: true}
+	if err := swapService.CheckCashouts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || !notifications[0].Peer.Equal(peer) || notifications[0].Reason != swap.DisconnectChequeBounced || notifications[0].TxHash != txHash {
+		t.Fatalf("got notifications %+v", notifications)
+	}
+
+	// the cashout is no longer checked once its outcome is known
+	if err := swapService.CheckCashouts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if statuses != 2 {
+		t.Fatalf("got %d status checks, want 2", statuses)
+	}
+}
+func TestNotifyDisconnectThresholdExceeded(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	store := mockstore.NewStateStore()
+
+	peer := swarm.MustParseHexAddress("abcd")
+	addressbook := &addressbookMock{
+		chequebook: func(p swarm.Address) (common.Address, bool, error) {
+			return common.Address{}, false, nil
+		},
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		logger,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	// without a registered notifier this is a no-op
+	err := swapService.NotifyDisconnectThresholdExceeded(peer, big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifyErr := errors.New("notify error")
+	var got swap.DisconnectNotification
+	swapService.SetDisconnectNotifier(swap.DisconnectNotifierFunc(func(n swap.DisconnectNotification) error {
+		got = n
+		return notifyErr
+	}), 0)
+
+	err = swapService.NotifyDisconnectThresholdExceeded(peer, big.NewInt(100))
+	if !errors.Is(err, notifyErr) {
+		t.Fatalf("got error %v, want %v", err, notifyErr)
+	}
+
+	if got.Reason != swap.DisconnectThresholdExceeded {
+		t.Fatalf("got reason %v, want %v", got.Reason, swap.DisconnectThresholdExceeded)
+	}
+	if got.Debt.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got debt %v, want %v", got.Debt, 100)
+	}
+	if got.BlocklistDuration != 0 {
+		t.Fatalf("got blocklist duration %v, want 0", got.BlocklistDuration)
+	}
+}
+
+func TestReceiveReceipt(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	store := mockstore.NewStateStore()
+
+	peer := swarm.MustParseHexAddress("abcd")
+	beneficiary := common.HexToAddress("0xcd")
+	addressbook := &addressbookMock{
+		beneficiary: func(p swarm.Address) (common.Address, bool, error) {
+			return beneficiary, true, nil
+		},
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		logger,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		uint64(1),
+		&cashoutMock{},
+		nil,
+		common.Address{},
+	)
+
+	_, err := swapService.LastReceipt(peer)
+	if !errors.Is(err, swap.ErrNoReceipt) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrNoReceipt)
+	}
+
+	newReceipt := func(payout int64) *chequebook.Receipt {
+		return &chequebook.Receipt{
+			Cheque: chequebook.Cheque{
+				Chequebook:       common.HexToAddress("0xfff"),
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(payout),
+			},
+			Signature: []byte{1},
+		}
+	}
+
+	if err := swapService.ReceiveReceipt(peer, newReceipt(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	// an older receipt does not replace the newer one
+	if err := swapService.ReceiveReceipt(peer, newReceipt(50)); err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := swapService.LastReceipt(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.CumulativePayout.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got cumulative payout %v, want %v", receipt.CumulativePayout, 100)
+	}
+
+	wrongBeneficiary := newReceipt(200)
+	wrongBeneficiary.Beneficiary = common.HexToAddress("0xee")
+	err = swapService.ReceiveReceipt(peer, wrongBeneficiary)
+	if !errors.Is(err, chequebook.ErrReceiptInvalid) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrReceiptInvalid)
+	}
+}
+
 func TestStateStoreKeys(t *testing.T) {
 	t.Parallel()
 
@@ -829,4 +1721,276 @@ func TestStateStoreKeys(t *testing.T) {
 	if swap.PeerDeductedForKey(swarmAddress) != expected {
 		t.Fatalf("wrong peer deducted for key. wanted %s, got %s", expected, swap.PeerDeductedForKey(swarmAddress))
 	}
+
+	expected = "swap_receipt_000000000000000000000000000000000000abcd"
+	if swap.ReceiptKey(address) != expected {
+		t.Fatalf("wrong receipt key. wanted %s, got %s", expected, swap.ReceiptKey(address))
+	}
+
+	expected = "swap_announced_chequebook_deff"
+	if swap.AnnouncedChequebookKey(swarmAddress) != expected {
+		t.Fatalf("wrong announced chequebook key. wanted %s, got %s", expected, swap.AnnouncedChequebookKey(swarmAddress))
+	}
+}
+
+func TestReceivedChequebooks(t *testing.T) {
+	t.Parallel()
+
+	store := mockstore.NewStateStore()
+	addressbook := swap.NewAddressbook(store)
+
+	peer := swarm.MustParseHexAddress("abcd")
+	oldChequebook := common.HexToAddress("0xaaaa")
+	newChequebook := common.HexToAddress("0xbbbb")
+	ourChequebookAddress := common.HexToAddress("fffa")
+	txHash := common.HexToHash("eeee")
+
+	// the peer redeployed its chequebook after sending cheques from the old one
+	if err := addressbook.PutBeneficiary(peer, common.HexToAddress("0xcd")); err != nil {
+		t.Fatal(err)
+	}
+	if err := addressbook.PutChequebook(peer, oldChequebook); err != nil {
+		t.Fatal(err)
+	}
+	if err := addressbook.PutChequebook(peer, newChequebook); err != nil {
+		t.Fatal(err)
+	}
+
+	cheques := map[common.Address]*chequebook.SignedCheque{
+		oldChequebook: {Cheque: chequebook.Cheque{Chequebook: oldChequebook, CumulativePayout: big.NewInt(30)}},
+		newChequebook: {Cheque: chequebook.Cheque{Chequebook: newChequebook, CumulativePayout: big.NewInt(10)}},
+	}
+	uncashed := map[common.Address]*big.Int{
+		oldChequebook: big.NewInt(30),
+		newChequebook: big.NewInt(0),
+	}
+
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		store,
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(
+			mockchequestore.WithLastChequeFunc(func(c common.Address) (*chequebook.SignedCheque, error) {
+				return cheques[c], nil
+			}),
+		),
+		addressbook,
+		uint64(1),
+		&cashoutMock{
+			cashoutStatus: func(ctx context.Context, c common.Address) (*chequebook.CashoutStatus, error) {
+				return &chequebook.CashoutStatus{UncashedAmount: uncashed[c]}, nil
+			},
+			cashBatch: func(ctx context.Context, chequebooks []common.Address, r common.Address) ([]chequebook.BatchCashoutResult, error) {
+				if len(chequebooks) != 1 || chequebooks[0] != oldChequebook {
+					t.Fatalf("not cashing the chequebooks which owe us. wanted %v, got %v", oldChequebook, chequebooks)
+				}
+				return []chequebook.BatchCashoutResult{{Chequebook: oldChequebook, TxHash: txHash}}, nil
+			},
+		},
+		nil,
+		ourChequebookAddress,
+	)
+
+	received, err := swapService.ReceivedChequebooks(context.Background(), peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("got %d chequebooks, want 2", len(received))
+	}
+	if received[0].Chequebook != newChequebook || !received[0].Current || received[0].Uncashed.Sign() != 0 {
+		t.Fatalf("unexpected current chequebook %+v", received[0])
+	}
+	if received[1].Chequebook != oldChequebook || received[1].Current || received[1].Uncashed.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("unexpected previous chequebook %+v", received[1])
+	}
+
+	results, err := swapService.CashChequebooks(context.Background(), peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].TxHash != txHash {
+		t.Fatalf("got results %+v, want cashout in %v", results, txHash)
+	}
+
+	// a migrated peer keeps its previous chequebooks
+	newPeer := swarm.MustParseHexAddress("abce")
+	if err := addressbook.MigratePeer(peer, newPeer); err != nil {
+		t.Fatal(err)
+	}
+	chequebooks, err := addressbook.Chequebooks(newPeer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chequebooks) != 2 || chequebooks[0] != newChequebook || chequebooks[1] != oldChequebook {
+		t.Fatalf("got chequebooks %v after migration, want %v", chequebooks, []common.Address{newChequebook, oldChequebook})
+	}
+}
+
+type peerListerMock []p2p.Peer
+
+func (m peerListerMock) Peers() []p2p.Peer {
+	return m
+}
+
+func TestRotateBeneficiary(t *testing.T) {
+	t.Parallel()
+
+	beneficiary := common.HexToAddress("0xcd")
+	peers := peerListerMock{
+		{Address: swarm.MustParseHexAddress("abcd")},
+		{Address: swarm.MustParseHexAddress("abce")},
+	}
+
+	var rotatedTo common.Address
+	announced := make(chan swarm.Address, len(peers))
+	swapService := swap.New(
+		&swapProtocolMock{
+			announceBeneficiary: func(ctx context.Context, p swarm.Address, b common.Address) error {
+				if b != beneficiary {
+					t.Fatalf("announced beneficiary %x, want %x", b, beneficiary)
+				}
+				announced <- p
+				if p.Equal(peers[1].Address) {
+					return errors.New("peer unavailable")
+				}
+				return nil
+			},
+		},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithRotateBeneficiaryFunc(func(b common.Address, grace time.Duration) (*chequebook.BeneficiaryRotation, error) {
+			rotatedTo = b
+			return &chequebook.BeneficiaryRotation{Current: b}, nil
+		})),
+		&addressbookMock{},
+		1,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+	swapService.SetPeerLister(peers)
+
+	rotation, announcements, err := swapService.RotateBeneficiary(context.Background(), beneficiary, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotatedTo != beneficiary || rotation.Current != beneficiary {
+		t.Fatalf("rotated to %x, want %x", rotatedTo, beneficiary)
+	}
+	if len(announced) != len(peers) || len(announcements) != len(peers) {
+		t.Fatalf("announced to %d peers, want %d", len(announced), len(peers))
+	}
+	if announcements[0].Err != nil {
+		t.Fatal(announcements[0].Err)
+	}
+	if !announcements[1].Peer.Equal(peers[1].Address) || announcements[1].Err == nil {
+		t.Fatalf("expected failed announcement to %v, got %+v", peers[1].Address, announcements[1])
+	}
+}
+
+func TestReceiveBeneficiaryAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	amount := big.NewInt(50)
+	identity := common.HexToAddress("0xcd")
+	beneficiary := common.HexToAddress("0xce")
+	peer := swarm.MustParseHexAddress("abcd")
+
+	observer := newTestObserver()
+
+	var paidTo common.Address
+	swapService := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(ctx context.Context, p swarm.Address, b common.Address, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				paidTo = b
+				return amount, nil
+			},
+		},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{
+			beneficiary: func(p swarm.Address) (common.Address, bool, error) {
+				return identity, true, nil
+			},
+			beneficiaryPeer: func(b common.Address) (swarm.Address, bool, error) {
+				if b == identity {
+					return peer, true, nil
+				}
+				return swarm.ZeroAddress, false, nil
+			},
+		},
+		1,
+		&cashoutMock{},
+		observer,
+		common.Address{},
+	)
+
+	swapService.Pay(context.Background(), peer, amount)
+	<-observer.sentCalled
+	if paidTo != identity {
+		t.Fatalf("paid to %x, want %x", paidTo, identity)
+	}
+
+	if err := swapService.ReceiveBeneficiaryAnnouncement(context.Background(), peer, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	swapService.Pay(context.Background(), peer, amount)
+	<-observer.sentCalled
+	if paidTo != beneficiary {
+		t.Fatalf("paid to %x, want %x", paidTo, beneficiary)
+	}
+
+	// the peer rotates back to the beneficiary of the handshake
+	if err := swapService.ReceiveBeneficiaryAnnouncement(context.Background(), peer, identity); err != nil {
+		t.Fatal(err)
+	}
+
+	swapService.Pay(context.Background(), peer, amount)
+	<-observer.sentCalled
+	if paidTo != identity {
+		t.Fatalf("paid to %x, want %x", paidTo, identity)
+	}
+}
+
+func TestLastSentChequesContext(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+
+	var calls int
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(mockchequebook.WithLastChequesFunc(func(c context.Context) (map[common.Address]*chequebook.SignedCheque, error) {
+			if c.Value(ctxKey{}) == nil {
+				t.Fatal("request context not passed to the chequebook")
+			}
+			calls++
+			return nil, nil
+		})),
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{},
+		1,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+
+	if _, err := swapService.SettlementsSent(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := swapService.LastSentCheques(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("got %d calls, want 2", calls)
+	}
 }
@@ -16,3 +16,7 @@ func (s *Service) Init(ctx context.Context, p p2p.Peer) error {
 func (s *Service) Handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
 	return s.handler(ctx, p, stream)
 }
+
+func (s *Service) AnnouncementHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
+	return s.announcementHandler(ctx, p, stream)
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
@@ -30,6 +31,12 @@ const (
 	protocolName    = "swap"
 	protocolVersion = "1.0.0"
 	streamName      = "swap" // stream for cheques
+
+	announcementStreamName = "chequebook"  // stream for chequebook announcements
+	statementStreamName    = "statement"   // stream for settlement statements
+	beneficiaryStreamName  = "beneficiary" // stream for beneficiary announcements
+	reminderStreamName     = "reminder"    // stream for settlement reminders
+	receiptStreamName      = "receipt"     // stream for receipts of the last received cheque
 )
 
 var (
@@ -40,14 +47,26 @@ var (
 
 type SendChequeFunc chequebook.SendChequeFunc
 
-type IssueFunc func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)
+type IssueFunc func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error)
 
-// (context.Context, common.Address, *big.Int, chequebook.SendChequeFunc) (*big.Int, error)
+// (context.Context, common.Address, chequebook.Tokens, chequebook.SendChequeFunc) (*big.Int, error)
 
 // Interface is the main interface to send messages over swap protocol.
 type Interface interface {
 	// EmitCheque sends a signed cheque to a peer.
 	EmitCheque(ctx context.Context, peer swarm.Address, beneficiary common.Address, amount *big.Int, issue IssueFunc) (balance *big.Int, err error)
+	// AnnounceChequebook sends the address of our chequebook to a peer.
+	AnnounceChequebook(ctx context.Context, peer swarm.Address, chequebook common.Address) error
+	// AnnounceBeneficiary sends the beneficiary cheques to us have to be addressed to to a peer.
+	AnnounceBeneficiary(ctx context.Context, peer swarm.Address, beneficiary common.Address) error
+	// RequestStatement requests the signed settlement statement of a peer about the cheques it sent to us.
+	RequestStatement(ctx context.Context, peer swarm.Address) (*chequebook.Statement, error)
+	// SendReminder reminds a peer that its debt exceeded the payment threshold for the overdue duration.
+	SendReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error
+	// RequestReceipt requests a receipt of a peer for the last cheque it received from us.
+	RequestReceipt(ctx context.Context, peer swarm.Address) (*chequebook.Receipt, error)
+	// PaymentAmount returns the amount of the cheque paying amount to a peer at the current rates.
+	PaymentAmount(peer swarm.Address, amount *big.Int) (chequebook.Tokens, error)
 }
 
 // Swap is the interface the settlement layer should implement to receive cheques.
@@ -56,9 +75,27 @@ type Swap interface {
 	ReceiveCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) error
 	// Handshake is called by the swap protocol when a handshake is received.
 	Handshake(peer swarm.Address, beneficiary common.Address) error
+	// AnnounceChequebook is called by the swap protocol after the handshake
+	// to announce our chequebook to the peer if necessary.
+	AnnounceChequebook(ctx context.Context, peer swarm.Address) error
+	// ReceiveChequebookAnnouncement is called by the swap protocol if a peer announces its chequebook.
+	ReceiveChequebookAnnouncement(ctx context.Context, peer swarm.Address, chequebook common.Address) error
+	// AnnounceBeneficiary is called by the swap protocol after the handshake
+	// to announce our beneficiary to the peer if it was rotated.
+	AnnounceBeneficiary(ctx context.Context, peer swarm.Address) error
+	// ReceiveBeneficiaryAnnouncement is called by the swap protocol if a peer announces a new beneficiary.
+	ReceiveBeneficiaryAnnouncement(ctx context.Context, peer swarm.Address, beneficiary common.Address) error
 	GetDeductionForPeer(peer swarm.Address) (bool, error)
 	GetDeductionByPeer(peer swarm.Address) (bool, error)
 	AddDeductionByPeer(peer swarm.Address) error
+	// ReceiveReceipt is called by the swap protocol if a valid receipt for a sent cheque is received.
+	ReceiveReceipt(peer swarm.Address, receipt *chequebook.Receipt) error
+	// Statement is called by the swap protocol if a peer requests our settlement statement.
+	Statement(peer swarm.Address) (*chequebook.Statement, error)
+	// ReceiveReminder is called by the swap protocol if a peer reminds us to settle our debt.
+	ReceiveReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error
+	// LastReceivedCheque is called by the swap protocol if a peer requests a receipt for the last cheque it sent to us.
+	LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheque, error)
 }
 
 // Service is the main implementation of the swap protocol.
@@ -68,15 +105,19 @@ type Service struct {
 	swap        Swap
 	priceOracle priceoracle.Service
 	beneficiary common.Address
+	signer      crypto.Signer
+	chainID     int64
 }
 
 // New creates a new swap protocol Service.
-func New(streamer p2p.Streamer, logger log.Logger, beneficiary common.Address, priceOracle priceoracle.Service) *Service {
+func New(streamer p2p.Streamer, logger log.Logger, beneficiary common.Address, priceOracle priceoracle.Service, signer crypto.Signer, chainID int64) *Service {
 	return &Service{
 		streamer:    streamer,
 		logger:      logger.WithName(loggerName).Register(),
 		beneficiary: beneficiary,
 		priceOracle: priceOracle,
+		signer:      signer,
+		chainID:     chainID,
 	}
 }
 
@@ -95,6 +136,26 @@ func (s *Service) Protocol() p2p.ProtocolSpec {
 				Handler: s.handler,
 				Headler: s.headler,
 			},
+			{
+				Name:    announcementStreamName,
+				Handler: s.announcementHandler,
+			},
+			{
+				Name:    statementStreamName,
+				Handler: s.statementHandler,
+			},
+			{
+				Name:    beneficiaryStreamName,
+				Handler: s.beneficiaryHandler,
+			},
+			{
+				Name:    reminderStreamName,
+				Handler: s.reminderHandler,
+			},
+			{
+				Name:    receiptStreamName,
+				Handler: s.receiptHandler,
+			},
 		},
 		ConnectOut: s.init,
 		ConnectIn:  s.init,
@@ -104,7 +165,304 @@ func (s *Service) Protocol() p2p.ProtocolSpec {
 // init is called on outgoing connections and triggers handshake exchange
 func (s *Service) init(ctx context.Context, p p2p.Peer) error {
 	beneficiary := common.BytesToAddress(p.EthereumAddress)
-	return s.swap.Handshake(p.Address, beneficiary)
+	if err := s.swap.Handshake(p.Address, beneficiary); err != nil {
+		return err
+	}
+
+	// peers running an older version do not support announcements, which must
+	// not prevent the connection
+	if err := s.swap.AnnounceChequebook(ctx, p.Address); err != nil {
+		s.logger.Debug("chequebook announcement failed", "peer_address", p.Address, "error", err)
+	}
+	if err := s.swap.AnnounceBeneficiary(ctx, p.Address); err != nil {
+		s.logger.Debug("beneficiary announcement failed", "peer_address", p.Address, "error", err)
+	}
+	return nil
+}
+
+// announcementHandler handles chequebook announcements of peers.
+func (s *Service) announcementHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	r := protobuf.NewReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.ChequebookAnnouncement
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read chequebook announcement from peer %v: %w", p.Address, err)
+	}
+	if len(req.Chequebook) != common.AddressLength {
+		return fmt.Errorf("invalid chequebook announcement from peer %v", p.Address)
+	}
+
+	return s.swap.ReceiveChequebookAnnouncement(ctx, p.Address, common.BytesToAddress(req.Chequebook))
+}
+
+// AnnounceChequebook sends the address of our chequebook to a peer.
+func (s *Service) AnnounceChequebook(ctx context.Context, peer swarm.Address, chequebook common.Address) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, announcementStreamName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w := protobuf.NewWriter(stream)
+	return w.WriteMsgWithContext(ctx, &pb.ChequebookAnnouncement{
+		Chequebook: chequebook.Bytes(),
+	})
+}
+
+// beneficiaryHandler handles beneficiary announcements of peers.
+func (s *Service) beneficiaryHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	r := protobuf.NewReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.Handshake
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read beneficiary announcement from peer %v: %w", p.Address, err)
+	}
+	if len(req.Beneficiary) != common.AddressLength {
+		return fmt.Errorf("invalid beneficiary announcement from peer %v", p.Address)
+	}
+
+	return s.swap.ReceiveBeneficiaryAnnouncement(ctx, p.Address, common.BytesToAddress(req.Beneficiary))
+}
+
+// AnnounceBeneficiary sends the beneficiary cheques to us have to be
+// addressed to to a peer, repeating the handshake after a rotation.
+func (s *Service) AnnounceBeneficiary(ctx context.Context, peer swarm.Address, beneficiary common.Address) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, beneficiaryStreamName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w := protobuf.NewWriter(stream)
+	return w.WriteMsgWithContext(ctx, &pb.Handshake{
+		Beneficiary: beneficiary.Bytes(),
+	})
+}
+
+// reminderHandler handles settlement reminders of peers we owe more than the
+// payment threshold.
+func (s *Service) reminderHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	r := protobuf.NewReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.SettlementReminder
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read settlement reminder from peer %v: %w", p.Address, err)
+	}
+	if req.Overdue < 0 {
+		return fmt.Errorf("invalid settlement reminder from peer %v", p.Address)
+	}
+
+	debt := new(big.Int).SetBytes(req.Debt)
+	threshold := new(big.Int).SetBytes(req.Threshold)
+	return s.swap.ReceiveReminder(ctx, p.Address, debt, threshold, time.Duration(req.Overdue)*time.Second)
+}
+
+// SendReminder reminds a peer that its debt exceeded the payment threshold
+// for the overdue duration.
+func (s *Service) SendReminder(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, reminderStreamName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w := protobuf.NewWriter(stream)
+	return w.WriteMsgWithContext(ctx, &pb.SettlementReminder{
+		Debt:      debt.Bytes(),
+		Threshold: threshold.Bytes(),
+		Overdue:   int64(overdue / time.Second),
+	})
+}
+
+// receiptHandler answers receipt requests of peers with a receipt for the
+// last cheque they sent to us. An empty receipt is sent if there is none.
+func (s *Service) receiptHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	r := protobuf.NewReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.ReceiptRequest
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read receipt request from peer %v: %w", p.Address, err)
+	}
+
+	cheque, err := s.swap.LastReceivedCheque(p.Address)
+	if errors.Is(err, chequebook.ErrNoCheque) {
+		w := protobuf.NewWriter(stream)
+		return w.WriteMsgWithContext(ctx, &pb.Receipt{})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.sendReceipt(ctx, stream, &cheque.Cheque)
+}
+
+// RequestReceipt requests a receipt of a peer for the last cheque it received
+// from us. The receipt is verified to be signed by the beneficiary of the
+// cheque. A nil receipt is returned if the peer has not received any cheque.
+func (s *Service) RequestReceipt(ctx context.Context, peer swarm.Address) (receipt *chequebook.Receipt, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, receiptStreamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.ReceiptRequest{}); err != nil {
+		return nil, err
+	}
+
+	var msg pb.Receipt
+	if err := r.ReadMsgWithContext(ctx, &msg); err != nil {
+		return nil, fmt.Errorf("read receipt: %w", err)
+	}
+	if len(msg.Receipt) == 0 {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(msg.Receipt, &receipt); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, chequebook.ErrReceiptInvalid
+	}
+	if err := chequebook.VerifyReceipt(receipt, &receipt.Cheque, s.chainID); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// statementHandler answers statement requests of peers with our last
+// statement about the cheques we sent to them.
+func (s *Service) statementHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	r := protobuf.NewReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var req pb.StatementRequest
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read statement request from peer %v: %w", p.Address, err)
+	}
+
+	statement, err := s.swap.Statement(p.Address)
+	if err != nil {
+		return err
+	}
+
+	encodedStatement, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+
+	w := protobuf.NewWriter(stream)
+	return w.WriteMsgWithContext(ctx, &pb.Statement{
+		Statement: encodedStatement,
+	})
+}
+
+// RequestStatement requests the signed settlement statement of a peer about
+// the cheques it sent to us. The signature is not verified.
+func (s *Service) RequestStatement(ctx context.Context, peer swarm.Address) (statement *chequebook.Statement, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, statementStreamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.StatementRequest{}); err != nil {
+		return nil, err
+	}
+
+	var msg pb.Statement
+	if err := r.ReadMsgWithContext(ctx, &msg); err != nil {
+		return nil, fmt.Errorf("read statement: %w", err)
+	}
+
+	if err := json.Unmarshal(msg.Statement, &statement); err != nil {
+		return nil, err
+	}
+	if statement == nil {
+		return nil, chequebook.ErrStatementInvalid
+	}
+	return statement, nil
 }
 
 func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
@@ -138,7 +496,59 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 	}
 
 	// signature validation
-	return s.swap.ReceiveCheque(ctx, p.Address, signedCheque, exchangeRate, deduction)
+	err = s.swap.ReceiveCheque(ctx, p.Address, signedCheque, exchangeRate, deduction)
+	if err != nil {
+		return err
+	}
+
+	// acknowledge the stored cheque with a receipt, the cheque is accepted regardless of whether this succeeds
+	if err := s.sendReceipt(ctx, stream, &signedCheque.Cheque); err != nil {
+		s.logger.Debug("failed to send receipt", "peer_address", p.Address, "error", err)
+	}
+
+	return nil
+}
+
+// sendReceipt signs a receipt for the received cheque and writes it to the stream.
+func (s *Service) sendReceipt(ctx context.Context, stream p2p.Stream, cheque *chequebook.Cheque) error {
+	if s.signer == nil {
+		return nil
+	}
+
+	receipt, err := chequebook.SignReceipt(s.signer, cheque, s.chainID)
+	if err != nil {
+		return err
+	}
+
+	encodedReceipt, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+
+	w := protobuf.NewWriter(stream)
+	return w.WriteMsgWithContext(ctx, &pb.Receipt{
+		Receipt: encodedReceipt,
+	})
+}
+
+// receiveReceipt reads the receipt for the sent cheque from the stream and verifies it.
+func (s *Service) receiveReceipt(ctx context.Context, stream p2p.Stream, cheque *chequebook.Cheque) (*chequebook.Receipt, error) {
+	r := protobuf.NewReader(stream)
+	var msg pb.Receipt
+	if err := r.ReadMsgWithContext(ctx, &msg); err != nil {
+		return nil, fmt.Errorf("read receipt: %w", err)
+	}
+
+	var receipt *chequebook.Receipt
+	if err := json.Unmarshal(msg.Receipt, &receipt); err != nil {
+		return nil, err
+	}
+
+	if err := chequebook.VerifyReceipt(receipt, cheque, s.chainID); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
 }
 
 func (s *Service) headler(receivedHeaders p2p.Headers, peerAddress swarm.Address) (returnHeaders p2p.Headers) {
@@ -161,6 +571,40 @@ func (s *Service) headler(receivedHeaders p2p.Headers, peerAddress swarm.Address
 	return
 }
 
+// PaymentAmount returns the amount of the cheque EmitCheque would issue to
+// pay amount to the peer at the current exchange rate. The deduction is
+// included as long as the peer has not deducted from our cheques before.
+func (s *Service) PaymentAmount(peer swarm.Address, amount *big.Int) (chequebook.Tokens, error) {
+	exchangeRate, deduction, err := s.priceOracle.CurrentRates()
+	if err != nil {
+		return chequebook.Tokens{}, err
+	}
+
+	deducted, err := s.swap.GetDeductionByPeer(peer)
+	if err != nil {
+		return chequebook.Tokens{}, err
+	}
+
+	if deducted {
+		deduction = big.NewInt(0)
+	}
+	return paymentTokens(amount, exchangeRate, deduction)
+}
+
+// paymentTokens converts the amount in accounting units into the tokens of
+// the cheque paying it, including the deduction.
+func paymentTokens(amount, exchangeRate, deduction *big.Int) (chequebook.Tokens, error) {
+	paymentAmount, err := chequebook.AccountingToTokens(amount, exchangeRate)
+	if err != nil {
+		return chequebook.Tokens{}, err
+	}
+	deductionTokens, err := chequebook.NewTokens(deduction)
+	if err != nil {
+		return chequebook.Tokens{}, err
+	}
+	return paymentAmount.Add(deductionTokens), nil
+}
+
 // InitiateCheque attempts to send a cheque to a peer.
 func (s *Service) EmitCheque(ctx context.Context, peer swarm.Address, beneficiary common.Address, amount *big.Int, issue IssueFunc) (balance *big.Int, err error) {
 	loggerV1 := s.logger.V(1).Register()
@@ -219,11 +663,14 @@ func (s *Service) EmitCheque(ctx context.Context, peer swarm.Address, beneficiar
 		return nil, ErrNegotiateDeduction
 	}
 
-	paymentAmount := new(big.Int).Mul(amount, exchangeRate)
-	sentAmount := new(big.Int).Add(paymentAmount, deduction)
+	sentAmount, err := paymentTokens(amount, exchangeRate, deduction)
+	if err != nil {
+		return nil, err
+	}
 
 	// issue cheque call with provided callback for sending cheque to finish transaction
 
+	var sentCheque *chequebook.SignedCheque
 	balance, err = issue(ctx, beneficiary, sentAmount, func(cheque *chequebook.SignedCheque) error {
 		// for simplicity we use json marshaller. can be replaced by a binary encoding in the future.
 		encodedCheque, err := json.Marshal(cheque)
@@ -235,15 +682,30 @@ func (s *Service) EmitCheque(ctx context.Context, peer swarm.Address, beneficiar
 		loggerV1.Debug("sending cheque message to peer", "peer_address", peer, "cheque", cheque)
 
 		w := protobuf.NewWriter(stream)
-		return w.WriteMsgWithContext(ctx, &pb.EmitCheque{
+		err = w.WriteMsgWithContext(ctx, &pb.EmitCheque{
 			Cheque: encodedCheque,
 		})
+		if err != nil {
+			return err
+		}
 
+		sentCheque = cheque
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// peers running an older version do not send receipts, so a missing receipt does not fail the payment
+	if sentCheque != nil {
+		receipt, receiptErr := s.receiveReceipt(ctx, stream, &sentCheque.Cheque)
+		if receiptErr != nil {
+			loggerV1.Debug("no valid receipt received from peer", "peer_address", peer, "error", receiptErr)
+		} else if receiptErr = s.swap.ReceiveReceipt(peer, receipt); receiptErr != nil {
+			s.logger.Error(receiptErr, "failed to store receipt", "peer_address", peer)
+		}
+	}
+
 	if deduction.Cmp(big.NewInt(0)) != 0 {
 		err = s.swap.AddDeductionByPeer(peer)
 		if err != nil {
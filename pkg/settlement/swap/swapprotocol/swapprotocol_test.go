@@ -11,9 +11,11 @@ import (
 	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
@@ -40,26 +42,26 @@ func TestEmitCheques(t *testing.T) {
 	// mocked exchange rate and deduction
 	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
 
-	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle)
+	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle, nil, 0)
 	swappReceiver.SetSwap(swapReceiver)
 	recorder := streamtest.New(
 		streamtest.WithProtocols(swappReceiver.Protocol()),
 		streamtest.WithBaseAddr(peerID),
 	)
 	commonAddr2 := common.HexToAddress("0xdc")
-	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle)
+	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle, nil, 0)
 	swappInitiator.SetSwap(swapInitiator)
 	peer := p2p.Peer{Address: peerID}
 
 	// amount in accounting credits cheque should cover
 	chequeAmount := big.NewInt(1250)
 
-	issueFunc := func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+	issueFunc := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
 		cheque := &chequebook.SignedCheque{
 			Cheque: chequebook.Cheque{
 				Beneficiary: commonAddr,
 				// CumulativePayout only contains value of last cheque
-				CumulativePayout: amount,
+				CumulativePayout: amount.BigInt(),
 				Chequebook:       common.Address{},
 			},
 			Signature: []byte{},
@@ -145,6 +147,74 @@ func TestEmitCheques(t *testing.T) {
 	}
 }
 
+func TestEmitChequeReceipt(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	chainID := int64(1)
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	beneficiary, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipts := make(chan *chequebook.Receipt, 1)
+	swapReceiver := swapmock.NewSwap()
+	swapInitiator := swapmock.NewSwap(swapmock.WithReceiveReceiptFunc(func(peer swarm.Address, receipt *chequebook.Receipt) error {
+		if !peer.Equal(peerID) {
+			t.Fatalf("receipt for wrong peer. wanted %v, got %v", peerID, peer)
+		}
+		receipts <- receipt
+		return nil
+	}))
+
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(0))
+
+	swappReceiver := swapprotocol.New(nil, logger, beneficiary, priceOracle, signer, chainID)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, chainID)
+	swappInitiator.SetSwap(swapInitiator)
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       common.HexToAddress("0xfff"),
+		},
+		Signature: []byte{},
+	}
+
+	issueFunc := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+		return big.NewInt(0), sendChequeFunc(cheque)
+	}
+
+	if _, err := swappInitiator.EmitCheque(context.Background(), peerID, beneficiary, big.NewInt(10), issueFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case receipt := <-receipts:
+		if !receipt.Cheque.Equal(&cheque.Cheque) {
+			t.Fatalf("receipt for wrong cheque. wanted %v, got %v", cheque.Cheque, receipt.Cheque)
+		}
+		if err := chequebook.VerifyReceipt(receipt, &cheque.Cheque, chainID); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("no receipt received")
+	}
+}
+
 func TestCantEmitChequeRateMismatch(t *testing.T) {
 	t.Parallel()
 
@@ -158,24 +228,24 @@ func TestCantEmitChequeRateMismatch(t *testing.T) {
 
 	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
 	priceOracle2 := priceoraclemock.New(big.NewInt(52), big.NewInt(560))
-	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle)
+	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle, nil, 0)
 	swappReceiver.SetSwap(swapReceiver)
 	recorder := streamtest.New(
 		streamtest.WithProtocols(swappReceiver.Protocol()),
 		streamtest.WithBaseAddr(peerID),
 	)
 	commonAddr2 := common.HexToAddress("0xdc")
-	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2)
+	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2, nil, 0)
 	swappInitiator.SetSwap(swapInitiator)
 	peer := p2p.Peer{Address: peerID}
 
 	chequeAmount := big.NewInt(1250)
 
-	issueFunc := func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+	issueFunc := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
 		cheque := &chequebook.SignedCheque{
 			Cheque: chequebook.Cheque{
 				Beneficiary:      commonAddr,
-				CumulativePayout: amount,
+				CumulativePayout: amount.BigInt(),
 				Chequebook:       common.Address{},
 			},
 			Signature: []byte{},
@@ -223,24 +293,24 @@ func TestCantEmitChequeDeductionMismatch(t *testing.T) {
 
 	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
 	priceOracle2 := priceoraclemock.New(big.NewInt(50), big.NewInt(560))
-	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle)
+	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle, nil, 0)
 	swappReceiver.SetSwap(swapReceiver)
 	recorder := streamtest.New(
 		streamtest.WithProtocols(swappReceiver.Protocol()),
 		streamtest.WithBaseAddr(peerID),
 	)
 	commonAddr2 := common.HexToAddress("0xdc")
-	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2)
+	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2, nil, 0)
 	swappInitiator.SetSwap(swapInitiator)
 	peer := p2p.Peer{Address: peerID}
 
 	chequeAmount := big.NewInt(1250)
 
-	issueFunc := func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+	issueFunc := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
 		cheque := &chequebook.SignedCheque{
 			Cheque: chequebook.Cheque{
 				Beneficiary:      commonAddr,
-				CumulativePayout: amount,
+				CumulativePayout: amount.BigInt(),
 				Chequebook:       common.Address{},
 			},
 			Signature: []byte{},
@@ -288,24 +358,24 @@ func TestCantEmitChequeIneligibleDeduction(t *testing.T) {
 
 	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
 	priceOracle2 := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
-	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle)
+	swappReceiver := swapprotocol.New(nil, logger, commonAddr, priceOracle, nil, 0)
 	swappReceiver.SetSwap(swapReceiver)
 	recorder := streamtest.New(
 		streamtest.WithProtocols(swappReceiver.Protocol()),
 		streamtest.WithBaseAddr(peerID),
 	)
 	commonAddr2 := common.HexToAddress("0xdc")
-	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2)
+	swappInitiator := swapprotocol.New(recorder, logger, commonAddr2, priceOracle2, nil, 0)
 	swappInitiator.SetSwap(swapInitiator)
 	peer := p2p.Peer{Address: peerID}
 
 	chequeAmount := big.NewInt(1250)
 
-	issueFunc := func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+	issueFunc := func(ctx context.Context, beneficiary common.Address, amount chequebook.Tokens, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
 		cheque := &chequebook.SignedCheque{
 			Cheque: chequebook.Cheque{
 				Beneficiary:      commonAddr,
-				CumulativePayout: amount,
+				CumulativePayout: amount.BigInt(),
 				Chequebook:       common.Address{},
 			},
 			Signature: []byte{},
@@ -346,3 +416,330 @@ func TestCantEmitChequeIneligibleDeduction(t *testing.T) {
 		t.Fatalf("got %v messages, want %v", len(messages), 0)
 	}
 }
+
+func TestAnnounceChequebook(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	chequebookAddress := common.HexToAddress("0xcb")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	announcedC := make(chan common.Address, 1)
+	swapReceiver := swapmock.NewSwap(swapmock.WithReceiveChequebookAnnouncementFunc(func(ctx context.Context, peer swarm.Address, chequebook common.Address) error {
+		announcedC <- chequebook
+		return nil
+	}))
+	swappReceiver := swapprotocol.New(nil, logger, common.HexToAddress("0xab"), priceOracle, nil, 0)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	var announcedTo swarm.Address
+	swapInitiator := swapmock.NewSwap(swapmock.WithAnnounceChequebookFunc(func(ctx context.Context, peer swarm.Address) error {
+		announcedTo = peer
+		return errors.New("announcement not supported")
+	}))
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, 0)
+	swappInitiator.SetSwap(swapInitiator)
+
+	// a failing announcement does not fail the connection
+	if err := swappInitiator.Init(context.Background(), p2p.Peer{Address: peerID}); err != nil {
+		t.Fatal(err)
+	}
+	if !announcedTo.Equal(peerID) {
+		t.Fatalf("announced to %v, want %v", announcedTo, peerID)
+	}
+
+	if err := swappInitiator.AnnounceChequebook(context.Background(), peerID, chequebookAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-announcedC:
+		if got != chequebookAddress {
+			t.Fatalf("got chequebook %x, want %x", got, chequebookAddress)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("announcement not received")
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "chequebook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 1 {
+		t.Fatalf("got %v records, want %v", l, 1)
+	}
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(records[0].In()),
+		func() protobuf.Message { return new(pb.ChequebookAnnouncement) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || !bytes.Equal(messages[0].(*pb.ChequebookAnnouncement).Chequebook, chequebookAddress.Bytes()) {
+		t.Fatalf("unexpected announcement messages %v", messages)
+	}
+}
+
+func TestAnnounceBeneficiary(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	beneficiary := common.HexToAddress("0xbb")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	announcedC := make(chan common.Address, 1)
+	swapReceiver := swapmock.NewSwap(swapmock.WithReceiveBeneficiaryAnnouncementFunc(func(ctx context.Context, peer swarm.Address, beneficiary common.Address) error {
+		announcedC <- beneficiary
+		return nil
+	}))
+	swappReceiver := swapprotocol.New(nil, logger, common.HexToAddress("0xab"), priceOracle, nil, 0)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	var announcedTo swarm.Address
+	swapInitiator := swapmock.NewSwap(swapmock.WithAnnounceBeneficiaryFunc(func(ctx context.Context, peer swarm.Address) error {
+		announcedTo = peer
+		return errors.New("beneficiary not rotated")
+	}))
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, 0)
+	swappInitiator.SetSwap(swapInitiator)
+
+	// a failing announcement does not fail the connection
+	if err := swappInitiator.Init(context.Background(), p2p.Peer{Address: peerID}); err != nil {
+		t.Fatal(err)
+	}
+	if !announcedTo.Equal(peerID) {
+		t.Fatalf("announced to %v, want %v", announcedTo, peerID)
+	}
+
+	if err := swappInitiator.AnnounceBeneficiary(context.Background(), peerID, beneficiary); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-announcedC:
+		if got != beneficiary {
+			t.Fatalf("got beneficiary %x, want %x", got, beneficiary)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("announcement not received")
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "beneficiary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 1 {
+		t.Fatalf("got %v records, want %v", l, 1)
+	}
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(records[0].In()),
+		func() protobuf.Message { return new(pb.Handshake) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || !bytes.Equal(messages[0].(*pb.Handshake).Beneficiary, beneficiary.Bytes()) {
+		t.Fatalf("unexpected announcement messages %v", messages)
+	}
+}
+
+func TestSendReminder(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	type reminder struct {
+		peer            swarm.Address
+		debt, threshold *big.Int
+		overdue         time.Duration
+	}
+	remindedC := make(chan reminder, 1)
+	swapReceiver := swapmock.NewSwap(swapmock.WithReceiveReminderFunc(func(ctx context.Context, peer swarm.Address, debt, threshold *big.Int, overdue time.Duration) error {
+		remindedC <- reminder{peer: peer, debt: debt, threshold: threshold, overdue: overdue}
+		return nil
+	}))
+	swappReceiver := swapprotocol.New(nil, logger, common.HexToAddress("0xab"), priceOracle, nil, 0)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, 0)
+	swappInitiator.SetSwap(swapmock.NewSwap())
+
+	if err := swappInitiator.SendReminder(context.Background(), peerID, big.NewInt(1500), big.NewInt(1000), 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-remindedC:
+		if !got.peer.Equal(peerID) || got.debt.Cmp(big.NewInt(1500)) != 0 || got.threshold.Cmp(big.NewInt(1000)) != 0 || got.overdue != 5*time.Minute {
+			t.Fatalf("got reminder %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reminder not received")
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "reminder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 1 {
+		t.Fatalf("got %v records, want %v", l, 1)
+	}
+}
+
+func TestRequestReceipt(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	chainID := int64(1)
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	beneficiary, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheque := &chequebook.SignedCheque{
+		Cheque: chequebook.Cheque{
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(500),
+			Chequebook:       common.HexToAddress("0xfff"),
+		},
+	}
+
+	var hasCheque bool
+	swapReceiver := swapmock.NewSwap(swapmock.WithLastReceivedChequeFunc(func(peer swarm.Address) (*chequebook.SignedCheque, error) {
+		if !hasCheque {
+			return nil, chequebook.ErrNoCheque
+		}
+		return cheque, nil
+	}))
+	swappReceiver := swapprotocol.New(nil, logger, beneficiary, priceOracle, signer, chainID)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, chainID)
+	swappInitiator.SetSwap(swapmock.NewSwap())
+
+	receipt, err := swappInitiator.RequestReceipt(context.Background(), peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt != nil {
+		t.Fatalf("got receipt %v, want none", receipt)
+	}
+
+	hasCheque = true
+	receipt, err = swappInitiator.RequestReceipt(context.Background(), peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt == nil || !receipt.Cheque.Equal(&cheque.Cheque) {
+		t.Fatalf("got receipt %v, want receipt for %v", receipt, cheque.Cheque)
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "receipt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 2 {
+		t.Fatalf("got %v records, want %v", l, 2)
+	}
+}
+
+func TestRequestStatement(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	statement := &chequebook.Statement{
+		Chequebook:       common.HexToAddress("0xcb"),
+		Beneficiary:      common.HexToAddress("0xdc"),
+		CumulativePayout: big.NewInt(500),
+		Timestamp:        1000,
+		Signature:        []byte{1, 2, 3},
+	}
+
+	var requestedBy swarm.Address
+	swapReceiver := swapmock.NewSwap(swapmock.WithStatementFunc(func(peer swarm.Address) (*chequebook.Statement, error) {
+		requestedBy = peer
+		return statement, nil
+	}))
+	swappReceiver := swapprotocol.New(nil, logger, common.HexToAddress("0xab"), priceOracle, nil, 0)
+	swappReceiver.SetSwap(swapReceiver)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, 0)
+	swappInitiator.SetSwap(swapmock.NewSwap())
+
+	got, err := swappInitiator.RequestStatement(context.Background(), peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Chequebook != statement.Chequebook || got.Beneficiary != statement.Beneficiary || got.CumulativePayout.Cmp(statement.CumulativePayout) != 0 ||
+		got.Timestamp != statement.Timestamp || !bytes.Equal(got.Signature, statement.Signature) {
+		t.Fatalf("got statement %+v, want %+v", got, statement)
+	}
+	if !requestedBy.Equal(peerID) {
+		t.Fatalf("statement requested by %v, want %v", requestedBy, peerID)
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "statement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 1 {
+		t.Fatalf("got %v records, want %v", l, 1)
+	}
+}
+
+func TestRequestStatementNone(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	priceOracle := priceoraclemock.New(big.NewInt(50), big.NewInt(500))
+
+	swappReceiver := swapprotocol.New(nil, logger, common.HexToAddress("0xab"), priceOracle, nil, 0)
+	swappReceiver.SetSwap(swapmock.NewSwap())
+	recorder := streamtest.New(
+		streamtest.WithProtocols(swappReceiver.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	swappInitiator := swapprotocol.New(recorder, logger, common.HexToAddress("0xdc"), priceOracle, nil, 0)
+	swappInitiator.SetSwap(swapmock.NewSwap())
+
+	if _, err := swappInitiator.RequestStatement(context.Background(), peerID); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/logcursor"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const (
+	// DefaultWithdrawWatchPeers is the default number of debtor peers whose chequebooks are watched for withdrawals.
+	DefaultWithdrawWatchPeers = 10
+
+	withdrawWatchBlockKey = "swap_withdraw_watch_last_block"
+)
+
+// WithdrawWatchOptions configures the watching of peer chequebooks for withdrawals.
+type WithdrawWatchOptions struct {
+	// Peers is the number of peers with the highest uncashed amounts whose
+	// chequebooks are watched. Zero disables watching.
+	Peers int
+	// Interval in which new blocks are scanned for withdrawals.
+	Interval time.Duration
+	// CashOut makes the last cheque of a peer be cashed as soon as the peer
+	// withdraws from its chequebook.
+	CashOut bool
+}
+
+// debtor is a peer whose cheques we did not fully cash.
+type debtor struct {
+	peer       swarm.Address
+	chequebook common.Address
+	uncashed   *big.Int
+}
+
+type withdrawWatcher struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (w *withdrawWatcher) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	return nil
+}
+
+// StartWithdrawWatch starts watching the chequebooks of the peers with the
+// highest uncashed amounts for withdrawals once per interval. A withdrawal
+// from a chequebook backing our uncashed cheques is logged and published as
+// a peer withdrawn event so that the cheques can be cashed before they
+// bounce. If configured, the last cheque of the peer is cashed right away.
+func (s *Service) StartWithdrawWatch(backend transaction.Backend, o WithdrawWatchOptions) io.Closer {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &withdrawWatcher{cancel: cancel}
+	if o.Peers <= 0 || o.Interval <= 0 || backend == nil {
+		return w
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(o.Interval):
+			}
+
+			if err := s.checkWithdrawals(ctx, backend, o); err != nil && ctx.Err() == nil {
+				s.logger.Error(err, "failed to check peer chequebooks for withdrawals")
+			}
+		}
+	}()
+
+	return w
+}
+
+// checkWithdrawals scans the blocks confirmed since the last check for
+// withdrawals from the chequebooks of the top debtors.
+func (s *Service) checkWithdrawals(ctx context.Context, backend transaction.Backend, o WithdrawWatchOptions) error {
+	debtors, err := s.topDebtors(o.Peers)
+	if err != nil {
+		return err
+	}
+	watched := make(map[common.Address]debtor, len(debtors))
+	chequebooks := make([]common.Address, 0, len(debtors))
+	for _, d := range debtors {
+		watched[d.chequebook] = d
+		chequebooks = append(chequebooks, d.chequebook)
+	}
+
+	// the history before the watch started is not of interest
+	scanner := logcursor.New("withdrawals", backend, s.store, withdrawWatchBlockKey, logcursor.Options{
+		Start: logcursor.FromHead,
+		// alerts cannot be taken back, but the withdrawals they announced
+		// may be orphaned and are alerted again if they are mined again
+		Rewind: func(_ context.Context, from, to uint64) error {
+			s.logger.Warning("chain reorg, withdrawal alerts may be orphaned", "from_block", from, "to_block", to)
+			return nil
+		},
+	})
+	return scanner.Scan(ctx, chequebook.WithdrawalsQuery(chequebooks), func(ctx context.Context, logs []types.Log) error {
+		withdrawals, err := chequebook.ParseWithdrawals(logs)
+		if err != nil {
+			return err
+		}
+		for _, withdrawal := range withdrawals {
+			s.peerWithdrew(ctx, watched[withdrawal.Chequebook], withdrawal, o.CashOut)
+		}
+		return nil
+	})
+}
+
+// peerWithdrew alerts about the withdrawal from the chequebook of the debtor
+// and cashes its last cheque if requested.
+func (s *Service) peerWithdrew(ctx context.Context, d debtor, withdrawal chequebook.Withdrawal, cashOut bool) {
+	s.metrics.PeerWithdrawals.Inc()
+	s.logger.Warning("peer withdraws from chequebook backing uncashed cheques", "peer_address", d.peer, "chequebook", d.chequebook, "amount", withdrawal.Amount, "uncashed", d.uncashed, "tx", withdrawal.TxHash)
+	s.publish(events.Event{
+		Type:       events.TypePeerWithdrawn,
+		Peer:       d.peer,
+		Chequebook: d.chequebook,
+		Amount:     withdrawal.Amount,
+		Payout:     withdrawal.Amount,
+		TxHash:     withdrawal.TxHash,
+	})
+
+	if !cashOut {
+		return
+	}
+	txHash, err := s.CashCheque(ctx, d.peer)
+	if err != nil {
+		s.logger.Error(err, "cashout after peer withdrawal failed", "peer_address", d.peer)
+		return
+	}
+	s.logger.Info("cashed out cheque after peer withdrawal", "peer_address", d.peer, "tx", txHash)
+}
+
+// topDebtors returns at most n peers with the highest amounts of received
+// but not yet cashed cheques, highest first. The amounts are computed from
+// the cashouts sent by this node without querying the chain.
+func (s *Service) topDebtors(n int) ([]debtor, error) {
+	debtors, err := s.debtors()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(debtors, func(i, j int) bool {
+		if c := debtors[i].uncashed.Cmp(debtors[j].uncashed); c != 0 {
+			return c > 0
+		}
+		return debtors[i].chequebook.Hex() < debtors[j].chequebook.Hex()
+	})
+	if len(debtors) > n {
+		debtors = debtors[:n]
+	}
+	return debtors, nil
+}
+
+// debtors returns every chequebook of a known peer with received but not yet
+// cashed cheques, summing up the cheques for all our beneficiaries.
+func (s *Service) debtors() ([]debtor, error) {
+	lastCheques, err := s.chequeStore.LastCheques()
+	if err != nil {
+		return nil, err
+	}
+
+	var debtors []debtor
+	for chequebookAddress := range lastCheques {
+		peer, known, err := s.addressbook.ChequebookPeer(chequebookAddress)
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			continue
+		}
+
+		cheques, err := s.chequeStore.LastBeneficiaryCheques(chequebookAddress)
+		if err != nil {
+			return nil, err
+		}
+		uncashed, err := s.uncashed(chequebookAddress, cheques)
+		if err != nil {
+			return nil, err
+		}
+		if uncashed.Sign() <= 0 {
+			continue
+		}
+
+		debtors = append(debtors, debtor{peer: peer, chequebook: chequebookAddress, uncashed: uncashed})
+	}
+	return debtors, nil
+}
+
+// uncashed returns the part of the cheques for every beneficiary which was not
+// cashed by the cashouts sent by this node.
+func (s *Service) uncashed(chequebookAddress common.Address, cheques map[common.Address]*chequebook.SignedCheque) (*big.Int, error) {
+	cashouts, err := s.cashout.ChequeCashouts(chequebookAddress)
+	if err != nil {
+		return nil, err
+	}
+	uncashed := new(big.Int)
+	for beneficiary, cheque := range cheques {
+		uncashed.Add(uncashed, cheque.CumulativePayout)
+		// the cashouts are ordered by time
+		for i := len(cashouts) - 1; i >= 0; i-- {
+			if cashouts[i].Cheque.Beneficiary == beneficiary {
+				uncashed.Sub(uncashed, cashouts[i].Cheque.CumulativePayout)
+				break
+			}
+		}
+	}
+	return uncashed, nil
+}
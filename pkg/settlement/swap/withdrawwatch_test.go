@@ -0,0 +1,169 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/settlement/events"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	mockchequebook "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	mockchequestore "github.com/ethersphere/bee/pkg/settlement/swap/chequestore/mock"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+	"github.com/ethersphere/go-sw3-abi/sw3abi"
+)
+
+func TestCheckWithdrawals(t *testing.T) {
+	t.Parallel()
+
+	chequebookABI := abiutil.MustParseABI(sw3abi.ERC20SimpleSwapABIv0_3_1)
+	withdrawEvent := chequebookABI.Events["Withdraw"]
+
+	debtorPeer := swarm.MustParseHexAddress("aaaa")
+	debtorChequebook := common.HexToAddress("0xaaaa")
+	cashedPeer := swarm.MustParseHexAddress("bbbb")
+	cashedChequebook := common.HexToAddress("0xbbbb")
+	ourChequebook := common.HexToAddress("0xfffa")
+	withdrawHash := common.HexToHash("0xeeee")
+	cashoutHash := common.HexToHash("0xcccc")
+
+	peers := map[common.Address]swarm.Address{
+		debtorChequebook: debtorPeer,
+		cashedChequebook: cashedPeer,
+	}
+
+	var cashed []common.Address
+	swapService := swap.New(
+		&swapProtocolMock{},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(mockchequestore.WithLastChequesFunc(func() (map[common.Address]*chequebook.SignedCheque, error) {
+			return map[common.Address]*chequebook.SignedCheque{
+				debtorChequebook: {Cheque: chequebook.Cheque{Chequebook: debtorChequebook, CumulativePayout: big.NewInt(100)}},
+				cashedChequebook: {Cheque: chequebook.Cheque{Chequebook: cashedChequebook, CumulativePayout: big.NewInt(50)}},
+			}, nil
+		})),
+		&addressbookMock{
+			chequebookPeer: func(c common.Address) (swarm.Address, bool, error) {
+				peer, ok := peers[c]
+				return peer, ok, nil
+			},
+			chequebook: func(p swarm.Address) (common.Address, bool, error) {
+				for c, peer := range peers {
+					if peer.Equal(p) {
+						return c, true, nil
+					}
+				}
+				return common.Address{}, false, nil
+			},
+		},
+		uint64(1),
+		&cashoutMock{
+			chequeCashouts: func(c common.Address) ([]chequebook.ChequeCashout, error) {
+				if c == cashedChequebook {
+					return []chequebook.ChequeCashout{{Cheque: chequebook.SignedCheque{Cheque: chequebook.Cheque{CumulativePayout: big.NewInt(50)}}}}, nil
+				}
+				return nil, nil
+			},
+			cashCheque: func(ctx context.Context, c common.Address, r common.Address) (common.Hash, error) {
+				cashed = append(cashed, c)
+				return cashoutHash, nil
+			},
+		},
+		nil,
+		ourChequebook,
+	)
+
+	feed := events.NewFeed()
+	defer feed.Close()
+	swapService.SetEventPublisher(feed)
+	c, cancel := feed.Subscribe()
+	defer cancel()
+
+	amount, err := withdrawEvent.Inputs.NonIndexed().Pack(big.NewInt(70))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head := uint64(100)
+	var queries []ethereum.FilterQuery
+	backend := backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			return head, nil
+		}),
+		backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+			return &types.Header{Number: number}, nil
+		}),
+		backendmock.WithFilterLogsFunc(func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+			queries = append(queries, query)
+			return []types.Log{{
+				Address:     debtorChequebook,
+				Topics:      []common.Hash{withdrawEvent.ID},
+				Data:        amount,
+				BlockNumber: 103,
+				TxHash:      withdrawHash,
+			}}, nil
+		}),
+	)
+
+	o := swap.WithdrawWatchOptions{Peers: 10, CashOut: true}
+	ctx := context.Background()
+
+	// the first check only records where to start watching
+	if err := swapService.CheckWithdrawals(ctx, backend, o); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 0 {
+		t.Fatalf("got %d log queries on the first check, want none", len(queries))
+	}
+
+	head = 110
+	if err := swapService.CheckWithdrawals(ctx, backend, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("got %d log queries, want 1", len(queries))
+	}
+	query := queries[0]
+	if query.FromBlock.Uint64() != 97 || query.ToBlock.Uint64() != 106 {
+		t.Fatalf("got blocks %d to %d, want %d to %d", query.FromBlock, query.ToBlock, 97, 106)
+	}
+	// the fully cashed chequebook is not watched
+	if len(query.Addresses) != 1 || query.Addresses[0] != debtorChequebook {
+		t.Fatalf("got watched chequebooks %v, want %v", query.Addresses, []common.Address{debtorChequebook})
+	}
+
+	e := <-c
+	if e.Type != events.TypePeerWithdrawn || !e.Peer.Equal(debtorPeer) || e.Chequebook != debtorChequebook || e.Amount.Cmp(big.NewInt(70)) != 0 || e.TxHash != withdrawHash {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	e = <-c
+	if e.Type != events.TypeCashout || e.Chequebook != debtorChequebook || e.TxHash != cashoutHash {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if len(cashed) != 1 || cashed[0] != debtorChequebook {
+		t.Fatalf("got cashed chequebooks %v, want %v", cashed, []common.Address{debtorChequebook})
+	}
+
+	// blocks already scanned are not scanned again
+	if err := swapService.CheckWithdrawals(ctx, backend, o); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("got %d log queries, want 1", len(queries))
+	}
+}
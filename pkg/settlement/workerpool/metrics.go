@@ -0,0 +1,53 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workerpool
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	QueueDepth     *prometheus.GaugeVec
+	RejectedTasks  *prometheus.CounterVec
+	CompletedTasks *prometheus.CounterVec
+	WaitTime       *prometheus.HistogramVec
+}
+
+func newMetrics() metrics {
+	subsystem := "settlement_workers"
+
+	return metrics{
+		QueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "queue_depth",
+			Help:      "Number of settlement tasks waiting for a worker",
+		}, []string{"priority"}),
+		RejectedTasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rejected_tasks",
+			Help:      "Number of settlement tasks rejected because the queue was full",
+		}, []string{"priority"}),
+		CompletedTasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "completed_tasks",
+			Help:      "Number of settlement tasks run by the workers",
+		}, []string{"priority"}),
+		WaitTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "wait_time",
+			Help:      "Time settlement tasks spent in the queue in seconds",
+			Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60},
+		}, []string{"priority"}),
+	}
+}
+
+func (p *Pool) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(p.metrics)
+}
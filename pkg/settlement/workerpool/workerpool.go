@@ -0,0 +1,276 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workerpool runs settlement tasks on a bounded number of workers so
+// that settlement storms do not saturate the blockchain endpoint and the peer
+// bandwidth. Queued tasks of a higher priority are always run first.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority is the priority of a task.
+type Priority int
+
+// Priorities of settlement tasks, lowest first.
+const (
+	PriorityReconciliation Priority = iota
+	PriorityCashout
+	PriorityIssuance
+
+	priorities = int(PriorityIssuance) + 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityReconciliation:
+		return "reconciliation"
+	case PriorityCashout:
+		return "cashout"
+	case PriorityIssuance:
+		return "issuance"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// DefaultWorkers is the default number of tasks run concurrently.
+	DefaultWorkers = 16
+	// DefaultQueueSize is the default maximum number of queued tasks per priority.
+	DefaultQueueSize = 1000
+)
+
+var (
+	// ErrQueueFull is the error returned if the queue of the priority is full.
+	ErrQueueFull = errors.New("settlement queue full")
+	// ErrClosed is the error returned for tasks submitted to or still queued in a closed pool.
+	ErrClosed = errors.New("settlement worker pool closed")
+)
+
+type task struct {
+	run    func()
+	reject func(error) // called instead of run if the task is dropped
+	queued time.Time
+}
+
+// Pool is a bounded pool of workers running tasks by priority.
+type Pool struct {
+	queueSize int
+	metrics   metrics
+	wg        sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	idle    *sync.Cond // signalled once no task is queued or running
+	queues  [priorities][]task
+	running int
+	closed  bool
+}
+
+// New starts a pool with the given number of workers. Every priority queues
+// at most queueSize tasks.
+func New(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	p := &Pool{
+		queueSize: queueSize,
+		metrics:   newMetrics(),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.idle = sync.NewCond(&p.mu)
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues f to be run by a worker. It returns ErrQueueFull without
+// queuing f if too many tasks of the priority are waiting.
+func (p *Pool) Submit(priority Priority, f func()) error {
+	return p.enqueue(priority, task{run: f, reject: func(error) {}})
+}
+
+// Do runs f on a worker and waits for its result. If ctx is done while the
+// task is queued, Do returns and f is skipped.
+func (p *Pool) Do(ctx context.Context, priority Priority, f func(context.Context) error) error {
+	done := make(chan error, 1)
+	err := p.enqueue(priority, task{
+		run: func() {
+			if err := ctx.Err(); err != nil {
+				done <- err
+				return
+			}
+			done <- f(ctx)
+		},
+		reject: func(err error) {
+			done <- err
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) enqueue(priority Priority, t task) error {
+	if priority < 0 || int(priority) >= priorities {
+		priority = PriorityReconciliation
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	if len(p.queues[priority]) >= p.queueSize {
+		p.metrics.RejectedTasks.WithLabelValues(priority.String()).Inc()
+		return ErrQueueFull
+	}
+
+	t.queued = time.Now()
+	p.queues[priority] = append(p.queues[priority], t)
+	p.metrics.QueueDepth.WithLabelValues(priority.String()).Set(float64(len(p.queues[priority])))
+	p.cond.Signal()
+	return nil
+}
+
+// next removes the oldest task of the highest priority from the queues.
+// It must be called with the lock held.
+func (p *Pool) next() (task, Priority, bool) {
+	for i := priorities - 1; i >= 0; i-- {
+		if len(p.queues[i]) == 0 {
+			continue
+		}
+		t := p.queues[i][0]
+		p.queues[i][0] = task{}
+		p.queues[i] = p.queues[i][1:]
+		priority := Priority(i)
+		p.metrics.QueueDepth.WithLabelValues(priority.String()).Set(float64(len(p.queues[i])))
+		return t, priority, true
+	}
+	return task{}, 0, false
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+		t, priority, ok := p.next()
+		for !ok && !p.closed {
+			p.cond.Wait()
+			t, priority, ok = p.next()
+		}
+		if ok {
+			p.running++
+		}
+		p.mu.Unlock()
+		if !ok {
+			return
+		}
+
+		p.metrics.WaitTime.WithLabelValues(priority.String()).Observe(time.Since(t.queued).Seconds())
+		t.run()
+		p.metrics.CompletedTasks.WithLabelValues(priority.String()).Inc()
+
+		p.mu.Lock()
+		p.running--
+		if p.isIdle() {
+			p.idle.Broadcast()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// isIdle reports whether no task is queued or running. It must be called with
+// the lock held.
+func (p *Pool) isIdle() bool {
+	if p.running > 0 {
+		return false
+	}
+	for i := range p.queues {
+		if len(p.queues[i]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Drain waits until the queued tasks and the tasks being run are done, so that
+// settlements are not dropped on shutdown. Tasks submitted while draining are
+// waited for as well. It returns the error of ctx if it is done before.
+func (p *Pool) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.mu.Lock()
+		for !p.isIdle() && !p.closed {
+			p.idle.Wait()
+		}
+		p.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QueueDepth returns the number of tasks of the priority waiting for a worker.
+func (p *Pool) QueueDepth(priority Priority) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if priority < 0 || int(priority) >= priorities {
+		return 0
+	}
+	return len(p.queues[priority])
+}
+
+// Close stops the workers after their current tasks. Queued tasks are
+// dropped and their callers waiting in Do get ErrClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	var dropped []task
+	for i := range p.queues {
+		dropped = append(dropped, p.queues[i]...)
+		p.queues[i] = nil
+		p.metrics.QueueDepth.WithLabelValues(Priority(i).String()).Set(0)
+	}
+	p.cond.Broadcast()
+	p.idle.Broadcast()
+	p.mu.Unlock()
+
+	for _, t := range dropped {
+		t.reject(ErrClosed)
+	}
+	p.wg.Wait()
+	return nil
+}
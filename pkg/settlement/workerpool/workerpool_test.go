@@ -0,0 +1,206 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/settlement/workerpool"
+)
+
+// block occupies the only worker of the pool until the returned function is called.
+func block(t *testing.T, p *workerpool.Pool) func() {
+	t.Helper()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := p.Submit(workerpool.PriorityIssuance, func() {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	return func() { close(release) }
+}
+
+func TestPriorities(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(1, 10)
+	t.Cleanup(func() { _ = p.Close() })
+
+	release := block(t, p)
+
+	var (
+		mu    sync.Mutex
+		order []workerpool.Priority
+		wg    sync.WaitGroup
+	)
+	for _, priority := range []workerpool.Priority{
+		workerpool.PriorityReconciliation,
+		workerpool.PriorityCashout,
+		workerpool.PriorityIssuance,
+		workerpool.PriorityCashout,
+	} {
+		priority := priority
+		wg.Add(1)
+		if err := p.Submit(priority, func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := p.QueueDepth(workerpool.PriorityCashout); got != 2 {
+		t.Fatalf("got queue depth %d, want 2", got)
+	}
+
+	release()
+	wg.Wait()
+
+	want := []workerpool.Priority{
+		workerpool.PriorityIssuance,
+		workerpool.PriorityCashout,
+		workerpool.PriorityCashout,
+		workerpool.PriorityReconciliation,
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("got order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(1, 1)
+	t.Cleanup(func() { _ = p.Close() })
+
+	release := block(t, p)
+	defer release()
+
+	if err := p.Submit(workerpool.PriorityCashout, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Submit(workerpool.PriorityCashout, func() {}); !errors.Is(err, workerpool.ErrQueueFull) {
+		t.Fatalf("got error %v, want %v", err, workerpool.ErrQueueFull)
+	}
+	// other priorities have their own queue
+	if err := p.Submit(workerpool.PriorityReconciliation, func() {}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(2, 10)
+	t.Cleanup(func() { _ = p.Close() })
+
+	errTest := errors.New("test")
+	err := p.Do(context.Background(), workerpool.PriorityCashout, func(ctx context.Context) error {
+		return errTest
+	})
+	if !errors.Is(err, errTest) {
+		t.Fatalf("got error %v, want %v", err, errTest)
+	}
+}
+
+func TestDoContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(1, 10)
+	t.Cleanup(func() { _ = p.Close() })
+
+	release := block(t, p)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var ran bool
+	err := p.Do(ctx, workerpool.PriorityCashout, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if ran {
+		t.Fatal("task of canceled context ran")
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(1, 10)
+	release := block(t, p)
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- p.Do(context.Background(), workerpool.PriorityCashout, func(ctx context.Context) error {
+			return nil
+		})
+	}()
+	for p.QueueDepth(workerpool.PriorityCashout) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = p.Close()
+		close(closed)
+	}()
+
+	if err := <-errC; !errors.Is(err, workerpool.ErrClosed) {
+		t.Fatalf("got error %v, want %v", err, workerpool.ErrClosed)
+	}
+
+	release()
+	<-closed
+
+	if err := p.Submit(workerpool.PriorityCashout, func() {}); !errors.Is(err, workerpool.ErrClosed) {
+		t.Fatalf("got error %v, want %v", err, workerpool.ErrClosed)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+
+	p := workerpool.New(1, 10)
+	t.Cleanup(func() { _ = p.Close() })
+
+	release := block(t, p)
+
+	ran := make(chan struct{})
+	if err := p.Submit(workerpool.PriorityCashout, func() { close(ran) }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+	default:
+		t.Fatal("queued task not run before the pool was drained")
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package encrypted provides a state store which transparently encrypts the
+// values of selected keys before they are written to an underlying store.
+package encrypted
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// KeySize is the size of the encryption key in bytes.
+const KeySize = 32
+
+// keyDerivationMessage is signed by the node key to derive the encryption key.
+const keyDerivationMessage = "bee settlement statestore encryption key"
+
+var (
+	// ErrInvalidKey is the error returned if the encryption key has the wrong size.
+	ErrInvalidKey = errors.New("invalid encryption key")
+	// ErrDecrypt is the error returned if a value could not be decrypted.
+	ErrDecrypt = errors.New("could not decrypt value")
+)
+
+// header marks encrypted values. Values without it were written before
+// encryption was enabled and are returned unchanged until they are encrypted
+// by Migrate.
+var header = []byte("bee-enc\x01")
+
+var _ storage.StateStorer = (*Store)(nil)
+
+// Store encrypts the values of all keys matching one of its prefixes with
+// AES-GCM before passing them on to the underlying store.
+type Store struct {
+	storage.StateStorer
+	aead     cipher.AEAD
+	prefixes []string
+}
+
+// New returns a store encrypting values of keys with one of the given prefixes
+// in store with the key. The key must be KeySize bytes long.
+func New(store storage.StateStorer, key []byte, prefixes ...string) (*Store, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		StateStorer: store,
+		aead:        aead,
+		prefixes:    prefixes,
+	}, nil
+}
+
+// KeyFromSecret derives an encryption key from a configured secret.
+func KeyFromSecret(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, ErrInvalidKey
+	}
+	return crypto.LegacyKeccak256([]byte(secret))
+}
+
+// KeyFromSigner derives an encryption key from the node key. Signatures are
+// deterministic, so the same key is derived on every start.
+func KeyFromSigner(signer crypto.Signer) ([]byte, error) {
+	signature, err := signer.Sign([]byte(keyDerivationMessage))
+	if err != nil {
+		return nil, err
+	}
+	return crypto.LegacyKeccak256(signature)
+}
+
+// encrypts reports whether values of the key are encrypted.
+func (s *Store) encrypts(key string) bool {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get implements storage.StateStorer.Get method.
+func (s *Store) Get(key string, i interface{}) error {
+	if !s.encrypts(key) {
+		return s.StateStorer.Get(key, i)
+	}
+
+	var value rawValue
+	if err := s.StateStorer.Get(key, &value); err != nil {
+		return err
+	}
+	data, err := s.decrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	if unmarshaler, ok := i.(encoding.BinaryUnmarshaler); ok {
+		return unmarshaler.UnmarshalBinary(data)
+	}
+	return json.Unmarshal(data, i)
+}
+
+// Put implements storage.StateStorer.Put method.
+func (s *Store) Put(key string, i interface{}) (err error) {
+	if !s.encrypts(key) {
+		return s.StateStorer.Put(key, i)
+	}
+
+	var data []byte
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		if data, err = marshaler.MarshalBinary(); err != nil {
+			return err
+		}
+	} else if data, err = json.Marshal(i); err != nil {
+		return err
+	}
+
+	value, err := s.encrypt(key, data)
+	if err != nil {
+		return err
+	}
+	return s.StateStorer.Put(key, value)
+}
+
+// Iterate implements storage.StateStorer.Iterate method. Values of encrypted
+// keys are passed to iterFunc decrypted.
+func (s *Store) Iterate(prefix string, iterFunc storage.StateIterFunc) error {
+	return s.StateStorer.Iterate(prefix, func(key, value []byte) (bool, error) {
+		if s.encrypts(string(key)) {
+			data, err := s.decrypt(string(key), value)
+			if err != nil {
+				return true, err
+			}
+			value = data
+		}
+		return iterFunc(key, value)
+	})
+}
+
+// DB implements storage.StateStorer.DB method.
+func (s *Store) DB() *leveldb.DB {
+	return s.StateStorer.DB()
+}
+
+// Migrate encrypts the values written before encryption was enabled. Values
+// already encrypted are left as they are and the migration can be run on
+// every start. It returns the number of encrypted values.
+func (s *Store) Migrate() (int, error) {
+	var (
+		legacy []string
+		seen   = make(map[string]struct{})
+	)
+	for _, prefix := range s.prefixes {
+		err := s.StateStorer.Iterate(prefix, func(key, value []byte) (bool, error) {
+			k := string(key)
+			// keys of overlapping prefixes are visited more than once
+			if _, ok := seen[k]; ok {
+				return false, nil
+			}
+			seen[k] = struct{}{}
+			if !bytes.HasPrefix(value, header) {
+				legacy = append(legacy, k)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, key := range legacy {
+		var value rawValue
+		if err := s.StateStorer.Get(key, &value); err != nil {
+			return 0, err
+		}
+		encrypted, err := s.encrypt(key, value)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.StateStorer.Put(key, encrypted); err != nil {
+			return 0, err
+		}
+	}
+	return len(legacy), nil
+}
+
+// encrypt seals data. The key is used as additional data so that encrypted
+// values cannot be moved to another key.
+func (s *Store) encrypt(key string, data []byte) (rawValue, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	value := make([]byte, 0, len(header)+len(nonce)+len(data)+s.aead.Overhead())
+	value = append(append(value, header...), nonce...)
+	return s.aead.Seal(value, nonce, data, []byte(key)), nil
+}
+
+// decrypt opens value. Values written before encryption was enabled are
+// returned as they are.
+func (s *Store) decrypt(key string, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, header) {
+		return value, nil
+	}
+	value = value[len(header):]
+	if len(value) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("key %q: %w", key, ErrDecrypt)
+	}
+	nonce, ciphertext := value[:s.aead.NonceSize()], value[s.aead.NonceSize():]
+	data, err := s.aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", key, ErrDecrypt)
+	}
+	return data, nil
+}
+
+// rawValue is stored in the underlying store as it is.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *rawValue) UnmarshalBinary(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encrypted_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/statestore/encrypted"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/test"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func newKey(t *testing.T, secret string) []byte {
+	t.Helper()
+	key, err := encrypted.KeyFromSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEncryptedStateStore(t *testing.T) {
+	t.Parallel()
+
+	test.Run(t, func(t *testing.T) storage.StateStorer {
+		t.Helper()
+		store, err := encrypted.New(mock.NewStateStore(), newKey(t, "secret"), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestEncryptedValues(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	store, err := encrypted.New(underlying, newKey(t, "secret"), "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("swap_cheque", "sensitive"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("other", "public"); err != nil {
+		t.Fatal(err)
+	}
+
+	// values of matching keys are not stored in plain text
+	err = underlying.Iterate("", func(key, value []byte) (bool, error) {
+		if bytes.Contains(value, []byte("sensitive")) {
+			t.Fatalf("value of key %s stored in plain text", key)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var other string
+	if err := underlying.Get("other", &other); err != nil {
+		t.Fatal(err)
+	}
+	if other != "public" {
+		t.Fatalf("got %q, want %q", other, "public")
+	}
+
+	var value string
+	if err := store.Get("swap_cheque", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "sensitive" {
+		t.Fatalf("got %q, want %q", value, "sensitive")
+	}
+
+	err = store.Iterate("swap_", func(key, value []byte) (bool, error) {
+		if string(value) != `"sensitive"` {
+			t.Fatalf("got iterated value %s, want decrypted value", value)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// encrypted values cannot be read with another key
+	other2, err := encrypted.New(underlying, newKey(t, "other secret"), "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other2.Get("swap_cheque", &value); !errors.Is(err, encrypted.ErrDecrypt) {
+		t.Fatalf("got error %v, want %v", err, encrypted.ErrDecrypt)
+	}
+}
+
+func TestPlaintextValues(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	if err := underlying.Put("swap_cheque", "legacy"); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := encrypted.New(underlying, newKey(t, "secret"), "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values stored before encryption was enabled remain readable
+	var value string
+	if err := store.Get("swap_cheque", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "legacy" {
+		t.Fatalf("got %q, want %q", value, "legacy")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	// the store is populated before encryption is enabled
+	underlying := mock.NewStateStore()
+	for _, key := range []string{"swap_cheque", "swap_chequebook", "other"} {
+		if err := underlying.Put(key, "legacy "+key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := encrypted.New(underlying, newKey(t, "secret"), "swap_", "swap_cheque")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("swap_new", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 2 {
+		t.Fatalf("got %d migrated values, want 2", migrated)
+	}
+
+	// the legacy values are encrypted in the underlying store
+	for _, key := range []string{"swap_cheque", "swap_chequebook"} {
+		var raw string
+		if err := underlying.Get(key, &raw); err == nil {
+			t.Fatalf("value of %q is stored as plaintext %q", key, raw)
+		}
+
+		var value string
+		if err := store.Get(key, &value); err != nil {
+			t.Fatal(err)
+		}
+		if value != "legacy "+key {
+			t.Fatalf("got %q, want %q", value, "legacy "+key)
+		}
+	}
+	var value string
+	if err := underlying.Get("other", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "legacy other" {
+		t.Fatalf("got %q, want %q", value, "legacy other")
+	}
+
+	// values are only encrypted once
+	migrated, err = store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 0 {
+		t.Fatalf("got %d migrated values, want 0", migrated)
+	}
+}
+
+func TestKeyFromSigner(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privateKey)
+
+	key1, err := encrypted.KeyFromSigner(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := encrypted.KeyFromSigner(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key1, key2) {
+		t.Fatal("derived keys differ")
+	}
+	if len(key1) != encrypted.KeySize {
+		t.Fatalf("got key size %d, want %d", len(key1), encrypted.KeySize)
+	}
+
+	if _, err := encrypted.New(mock.NewStateStore(), key1[1:]); !errors.Is(err, encrypted.ErrInvalidKey) {
+		t.Fatalf("got error %v, want %v", err, encrypted.ErrInvalidKey)
+	}
+}
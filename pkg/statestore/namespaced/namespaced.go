@@ -0,0 +1,184 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package namespaced provides a state store which keeps the keys of selected
+// prefixes in a namespace, so that a single underlying store can hold the
+// state of several networks or accounts side by side.
+package namespaced
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Separator terminates a namespace in the stored keys. Keys passed to the
+// store must not contain it.
+const Separator = ":"
+
+var _ storage.StateStorer = (*Store)(nil)
+
+// Store keeps all keys matching one of its prefixes in a namespace of the
+// underlying store. The namespace is inserted right after the matching
+// prefix, so that the stored keys still start with it and prefix based
+// wrappers like the encrypted store keep working below a Store. Other keys are
+// passed on unchanged.
+type Store struct {
+	storage.StateStorer
+	namespace string
+	prefixes  []string
+}
+
+// New returns a store keeping the keys with one of the given prefixes in the
+// namespace of store.
+func New(store storage.StateStorer, namespace string, prefixes ...string) *Store {
+	return &Store{
+		StateStorer: store,
+		namespace:   namespace + Separator,
+		prefixes:    prefixes,
+	}
+}
+
+// match returns the first prefix the key starts with.
+func (s *Store) match(key string) (string, bool) {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// wrap returns the key in the underlying store.
+func (s *Store) wrap(key string) string {
+	prefix, ok := s.match(key)
+	if !ok {
+		return key
+	}
+	return prefix + s.namespace + key[len(prefix):]
+}
+
+// unwrap returns the key as seen through the store. It reports false for keys
+// of other namespaces and keys which were not migrated yet.
+func (s *Store) unwrap(key string) (string, bool) {
+	prefix, ok := s.match(key)
+	if !ok {
+		return key, true
+	}
+	rest, ok := strings.CutPrefix(key[len(prefix):], s.namespace)
+	if !ok {
+		return "", false
+	}
+	return prefix + rest, true
+}
+
+// Strip returns the key without the namespace inserted after the first of the
+// prefixes it starts with, and the namespace itself. Wrappers below a Store see
+// the stored keys and use it to match keys of any namespace against patterns
+// of the keys passed to the Store. Keys without a namespace are returned
+// unchanged.
+func Strip(key string, prefixes ...string) (stripped, namespace string) {
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		namespace, rest, ok := strings.Cut(key[len(prefix):], Separator)
+		if !ok {
+			return key, ""
+		}
+		return prefix + rest, namespace
+	}
+	return key, ""
+}
+
+// Get implements storage.StateStorer.Get method.
+func (s *Store) Get(key string, i interface{}) error {
+	return s.StateStorer.Get(s.wrap(key), i)
+}
+
+// Put implements storage.StateStorer.Put method.
+func (s *Store) Put(key string, i interface{}) error {
+	return s.StateStorer.Put(s.wrap(key), i)
+}
+
+// Delete implements storage.StateStorer.Delete method.
+func (s *Store) Delete(key string) error {
+	return s.StateStorer.Delete(s.wrap(key))
+}
+
+// Iterate implements storage.StateStorer.Iterate method. The keys are passed
+// to iterFunc without the namespace.
+func (s *Store) Iterate(prefix string, iterFunc storage.StateIterFunc) error {
+	return s.StateStorer.Iterate(s.wrap(prefix), func(key, value []byte) (bool, error) {
+		k, ok := s.unwrap(string(key))
+		if !ok {
+			return false, nil
+		}
+		return iterFunc([]byte(k), value)
+	})
+}
+
+// DB implements storage.StateStorer.DB method.
+func (s *Store) DB() *leveldb.DB {
+	return s.StateStorer.DB()
+}
+
+// Migrate moves the keys written before the namespace was introduced into
+// it. Keys already present in the namespace are not overwritten and the
+// migration can be run on every start. It returns the number of moved keys.
+func (s *Store) Migrate() (int, error) {
+	var legacy []string
+	for _, prefix := range s.prefixes {
+		err := s.StateStorer.Iterate(prefix, func(key, _ []byte) (bool, error) {
+			k := string(key)
+			// keys of overlapping prefixes belong to the first matching one
+			if p, _ := s.match(k); p != prefix {
+				return false, nil
+			}
+			if !strings.Contains(k[len(prefix):], Separator) {
+				legacy = append(legacy, k)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, key := range legacy {
+		var value rawValue
+		if err := s.StateStorer.Get(key, &value); err != nil {
+			return 0, err
+		}
+
+		err := s.StateStorer.Get(s.wrap(key), new(rawValue))
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			if err := s.StateStorer.Put(s.wrap(key), value); err != nil {
+				return 0, err
+			}
+		case err != nil:
+			return 0, err
+		}
+
+		if err := s.StateStorer.Delete(key); err != nil {
+			return 0, err
+		}
+	}
+	return len(legacy), nil
+}
+
+// rawValue is copied between keys as it is.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *rawValue) UnmarshalBinary(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
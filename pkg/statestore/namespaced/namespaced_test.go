@@ -0,0 +1,207 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package namespaced_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/statestore/encrypted"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/namespaced"
+	"github.com/ethersphere/bee/pkg/statestore/test"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestNamespacedStateStore(t *testing.T) {
+	t.Parallel()
+
+	test.Run(t, func(t *testing.T) storage.StateStorer {
+		t.Helper()
+		return namespaced.New(mock.NewStateStore(), "1", "key", "some_")
+	})
+}
+
+// keys returns the string values of the store without the schema name of the
+// mock store.
+func keys(t *testing.T, store storage.StateStorer, prefix string) map[string]string {
+	t.Helper()
+	result := make(map[string]string)
+	err := store.Iterate(prefix, func(key, _ []byte) (bool, error) {
+		if string(key) == "schema_name" {
+			return false, nil
+		}
+		var value string
+		if err := store.Get(string(key), &value); err != nil {
+			return true, err
+		}
+		result[string(key)] = value
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestNamespaces(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	mainnet := namespaced.New(underlying, "100", "swap_")
+	testnet := namespaced.New(underlying, "5", "swap_")
+
+	if err := mainnet.Put("swap_cheque", "mainnet"); err != nil {
+		t.Fatal(err)
+	}
+	if err := testnet.Put("swap_cheque", "testnet"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mainnet.Put("other", "shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	for store, want := range map[storage.StateStorer]string{mainnet: "mainnet", testnet: "testnet"} {
+		var value string
+		if err := store.Get("swap_cheque", &value); err != nil {
+			t.Fatal(err)
+		}
+		if value != want {
+			t.Fatalf("got %q, want %q", value, want)
+		}
+		got := keys(t, store, "")
+		if len(got) != 2 || got["swap_cheque"] != want || got["other"] != "shared" {
+			t.Fatalf("got keys %v", got)
+		}
+		if got := keys(t, store, "swap_"); len(got) != 1 {
+			t.Fatalf("got keys %v", got)
+		}
+	}
+
+	if got := keys(t, underlying, ""); len(got) != 3 || got["swap_100:cheque"] != "mainnet" || got["swap_5:cheque"] != "testnet" {
+		t.Fatalf("got underlying keys %v", got)
+	}
+
+	if err := testnet.Delete("swap_cheque"); err != nil {
+		t.Fatal(err)
+	}
+	var value string
+	if err := mainnet.Get("swap_cheque", &value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNested(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	chain := namespaced.New(underlying, "100", "swap_")
+	chequebook := namespaced.New(chain, "cafe", "swap_chequebook_")
+
+	if err := chequebook.Put("swap_chequebook_total", "issued"); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, underlying, ""); got["swap_100:chequebook_cafe:total"] != "issued" {
+		t.Fatalf("got underlying keys %v", got)
+	}
+	if got := keys(t, chequebook, "swap_chequebook_"); len(got) != 1 || got["swap_chequebook_total"] != "issued" {
+		t.Fatalf("got keys %v", got)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	chain := namespaced.New(underlying, "100", "swap_", "accounting_")
+	chequebook := namespaced.New(chain, "cafe", "swap_chequebook_")
+
+	if err := chequebook.Put("swap_chequebook_total", "issued"); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Put("accounting_balance", "balance"); err != nil {
+		t.Fatal(err)
+	}
+	if err := underlying.Put("other_key", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"swap_chequebook_cafe:total": "100",
+		"accounting_balance":         "100",
+		"other_key":                  "",
+	}
+	for key := range keys(t, underlying, "") {
+		stripped, namespace := namespaced.Strip(key, "swap_", "accounting_")
+		if ns, ok := want[stripped]; !ok || ns != namespace {
+			t.Fatalf("stripped %q to %q in namespace %q", key, stripped, namespace)
+		}
+		delete(want, stripped)
+	}
+	if len(want) > 0 {
+		t.Fatalf("keys not found %v", want)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	key, err := encrypted.KeyFromSecret("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlying, err := encrypted.New(mock.NewStateStore(), key, "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		"swap_cheque":   "legacy",
+		"swap_existing": "legacy",
+		"swap_5:cheque": "testnet",
+		"other":         "shared",
+	} {
+		if err := underlying.Put(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := namespaced.New(underlying, "100", "swap_")
+	if err := store.Put("swap_existing", "mainnet"); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 2 {
+		t.Fatalf("migrated %d keys, want 2", migrated)
+	}
+
+	got := keys(t, underlying, "")
+	want := map[string]string{
+		"swap_100:cheque":   "legacy",
+		"swap_100:existing": "mainnet",
+		"swap_5:cheque":     "testnet",
+		"other":             "shared",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got underlying keys %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("got underlying keys %v, want %v", got, want)
+		}
+	}
+
+	// the migration is idempotent
+	migrated, err = store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 0 {
+		t.Fatalf("migrated %d keys, want 0", migrated)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replicated
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+type storeReplica struct {
+	store storage.StateStorer
+}
+
+// NewStoreReplica returns a replica applying the changes to a secondary store,
+// which can be used as the statestore of the standby node.
+func NewStoreReplica(store storage.StateStorer) Replica {
+	return &storeReplica{store: store}
+}
+
+// Replicate implements the Replica interface.
+func (r *storeReplica) Replicate(change Change) error {
+	return apply(r.store, change)
+}
+
+// apply applies the change to the store.
+func apply(store storage.StateStorer, change Change) error {
+	if change.Deleted {
+		err := store.Delete(change.Key)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return store.Put(change.Key, rawValue(change.Value))
+}
+
+// Journal is a replica appending the changes to a file, one JSON encoded
+// change per line. The file can be shipped to the standby location and
+// replayed into its statestore with Replay.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJournal opens the journal file at path, appending to it if it exists.
+func NewJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file}, nil
+}
+
+// Replicate implements the Replica interface. The change is synced to disk
+// before it returns.
+func (j *Journal) Replicate(change Change) error {
+	line, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Replay applies the changes of a journal to store in the order they were
+// written and returns their number.
+func Replay(r io.Reader, store storage.StateStorer) (int, error) {
+	scanner := bufio.NewScanner(r)
+	// values of single records are small but lines must fit in the buffer
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var count int
+	for scanner.Scan() {
+		var change Change
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return count, fmt.Errorf("change %d: %w", count+1, err)
+		}
+		if err := apply(store, change); err != nil {
+			return count, fmt.Errorf("change %d: %w", count+1, err)
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package replicated provides a state store which streams the changes of
+// selected keys to a replica, so that a warm standby node can take over the
+// settlement state without issuing cheques below an already sent cumulative
+// payout.
+package replicated
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ErrReplication is the error returned if a change could not be replicated.
+var ErrReplication = errors.New("replication failed")
+
+var _ storage.StateStorer = (*Store)(nil)
+
+// Change is a single write of a replicated key. Value is the value as stored
+// and nil for deleted keys.
+type Change struct {
+	Seq     uint64 `json:"seq"`
+	Key     string `json:"key"`
+	Value   []byte `json:"value,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Replica receives the changes of the replicated keys.
+type Replica interface {
+	// Replicate is called with one change at a time in the order of the
+	// sequence numbers. It must only return once the change is durable.
+	Replicate(change Change) error
+}
+
+// Store replicates every write of the keys matching one of its prefixes
+// before applying it to the underlying store. The replica is thus never
+// behind the store, and a standby started from it never issues a cheque
+// with a lower cumulative payout than one already sent. Writes which fail to
+// replicate are not applied. Other keys are passed on unchanged.
+type Store struct {
+	storage.StateStorer
+	prefixes []string
+	replica  Replica
+
+	mu  sync.Mutex // serializes replicated writes
+	seq uint64
+}
+
+// New returns a store replicating the keys with one of the given prefixes in
+// store to replica. The existing records are replicated first, so that the
+// replica holds the full state.
+func New(store storage.StateStorer, replica Replica, prefixes ...string) (*Store, error) {
+	s := &Store{
+		StateStorer: store,
+		prefixes:    prefixes,
+		replica:     replica,
+	}
+
+	for _, prefix := range prefixes {
+		err := store.Iterate(prefix, func(key, value []byte) (bool, error) {
+			// keys of overlapping prefixes are replicated with the first one
+			if p, _ := s.match(string(key)); p != prefix {
+				return false, nil
+			}
+			return false, s.replicate(string(key), value, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Seq returns the sequence number of the last replicated change.
+func (s *Store) Seq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// match returns the first prefix the key starts with.
+func (s *Store) match(key string) (string, bool) {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// replicate passes the change to the replica. It must be called with the lock
+// held or before the store is used.
+func (s *Store) replicate(key string, value []byte, deleted bool) error {
+	change := Change{
+		Seq:     s.seq + 1,
+		Key:     key,
+		Value:   value,
+		Deleted: deleted,
+	}
+	if err := s.replica.Replicate(change); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrReplication, key, err)
+	}
+	s.seq = change.Seq
+	return nil
+}
+
+// Put implements storage.StateStorer.Put method.
+func (s *Store) Put(key string, i interface{}) (err error) {
+	if _, ok := s.match(key); !ok {
+		return s.StateStorer.Put(key, i)
+	}
+
+	// the value is marshaled here to replicate it as it is stored
+	var data rawValue
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		if data, err = marshaler.MarshalBinary(); err != nil {
+			return err
+		}
+	} else if data, err = json.Marshal(i); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.replicate(key, data, false); err != nil {
+		return err
+	}
+	return s.StateStorer.Put(key, data)
+}
+
+// Delete implements storage.StateStorer.Delete method.
+func (s *Store) Delete(key string) error {
+	if _, ok := s.match(key); !ok {
+		return s.StateStorer.Delete(key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.replicate(key, nil, true); err != nil {
+		return err
+	}
+	return s.StateStorer.Delete(key)
+}
+
+// DB implements storage.StateStorer.DB method.
+func (s *Store) DB() *leveldb.DB {
+	return s.StateStorer.DB()
+}
+
+// rawValue is stored as it is.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *rawValue) UnmarshalBinary(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replicated_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/replicated"
+	"github.com/ethersphere/bee/pkg/statestore/test"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+type replicaMock struct {
+	changes []replicated.Change
+	err     error
+}
+
+func (r *replicaMock) Replicate(change replicated.Change) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func TestReplicatedStateStore(t *testing.T) {
+	t.Parallel()
+
+	test.Run(t, func(t *testing.T) storage.StateStorer {
+		t.Helper()
+		store, err := replicated.New(mock.NewStateStore(), &replicaMock{}, "key", "some_")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestReplication(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	if err := underlying.Put("swap_existing", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := underlying.Put("other_existing", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	replica := &replicaMock{}
+	store, err := replicated.New(underlying, replica, "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("swap_cheque", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("other_key", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("swap_existing"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []replicated.Change{
+		{Seq: 1, Key: "swap_existing", Value: []byte(`"a"`)},
+		{Seq: 2, Key: "swap_cheque", Value: []byte(`"b"`)},
+		{Seq: 3, Key: "swap_existing", Deleted: true},
+	}
+	if !reflect.DeepEqual(replica.changes, want) {
+		t.Fatalf("got changes %+v, want %+v", replica.changes, want)
+	}
+	if seq := store.Seq(); seq != 3 {
+		t.Fatalf("got seq %d, want 3", seq)
+	}
+
+	// writes which are not replicated are not applied
+	replica.err = errors.New("replica unavailable")
+	if err := store.Put("swap_cheque", "d"); !errors.Is(err, replicated.ErrReplication) {
+		t.Fatalf("got error %v, want %v", err, replicated.ErrReplication)
+	}
+	var value string
+	if err := store.Get("swap_cheque", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "b" {
+		t.Fatalf("got value %q, want %q", value, "b")
+	}
+	if err := store.Put("other_key", "d"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+	journal, err := replicated.NewJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := replicated.New(mock.NewStateStore(), journal, "swap_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("swap_a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("swap_b", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("swap_a", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("swap_b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	standby := mock.NewStateStore()
+	n, err := replicated.Replay(f, standby)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Fatalf("got %d replayed changes, want 4", n)
+	}
+
+	var value string
+	if err := standby.Get("swap_a", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "c" {
+		t.Fatalf("got value %q, want %q", value, "c")
+	}
+	if err := standby.Get("swap_b", &value); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
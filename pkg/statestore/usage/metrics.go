@@ -0,0 +1,38 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Records *prometheus.GaugeVec
+	Bytes   *prometheus.GaugeVec
+}
+
+func newMetrics() metrics {
+	subsystem := "statestore_usage"
+
+	return metrics{
+		Records: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "records",
+			Help:      "Number of records in the statestore by category",
+		}, []string{"category"}),
+		Bytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "bytes",
+			Help:      "Size of the keys and values of the records in the statestore by category",
+		}, []string{"category"}),
+	}
+}
+
+func (s *Store) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,238 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package usage provides a state store which keeps track of the number and
+// size of the records in categories of keys, so that abnormal growth of the
+// state can be noticed without scanning the store.
+package usage
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var _ storage.StateStorer = (*Store)(nil)
+
+// Category groups the keys starting with one of its prefixes. A key belongs to
+// the category with the longest matching prefix.
+type Category struct {
+	Name     string
+	Prefixes []string
+}
+
+// Usage is the number of records in a category and their size in bytes,
+// including the size of the keys.
+type Usage struct {
+	Category string `json:"category"`
+	Records  uint64 `json:"records"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// Store counts the records written to the categories of keys. The usage is
+// computed once when the store is created and then updated on every write.
+// Writes bypassing the store, like restored snapshots, are only counted once
+// the store is created again.
+type Store struct {
+	storage.StateStorer
+	categories []Category
+	key        func(string) string
+
+	mu      sync.Mutex // serializes writes to tracked keys
+	usage   map[string]*Usage
+	metrics metrics
+}
+
+// Options are the options of a Store.
+type Options struct {
+	// Key returns the key matched against the prefixes of the categories for
+	// a stored key, for example without the namespace added by a wrapper above
+	// the store. It defaults to the stored key.
+	Key func(key string) string
+	// Prefixes are the prefixes of the stored keys counted when the store is
+	// created. They default to the prefixes of the categories.
+	Prefixes []string
+}
+
+// New returns a store tracking the usage of the given categories in store.
+// The existing records are counted once.
+func New(store storage.StateStorer, categories ...Category) (*Store, error) {
+	return NewWithOptions(store, Options{}, categories...)
+}
+
+// NewWithOptions returns a store tracking the usage of the given categories in
+// store with the options o. The existing records are counted once.
+func NewWithOptions(store storage.StateStorer, o Options, categories ...Category) (*Store, error) {
+	s := &Store{
+		StateStorer: store,
+		categories:  categories,
+		key:         o.Key,
+		usage:       make(map[string]*Usage, len(categories)),
+		metrics:     newMetrics(),
+	}
+	if s.key == nil {
+		s.key = func(key string) string { return key }
+	}
+	for _, c := range categories {
+		s.usage[c.Name] = &Usage{Category: c.Name}
+	}
+
+	prefixes := o.Prefixes
+	if prefixes == nil {
+		for _, c := range categories {
+			prefixes = append(prefixes, c.Prefixes...)
+		}
+	}
+	for i, prefix := range prefixes {
+		err := store.Iterate(prefix, func(key, value []byte) (bool, error) {
+			// keys of overlapping prefixes are counted once
+			for _, p := range prefixes[:i] {
+				if strings.HasPrefix(string(key), p) {
+					return false, nil
+				}
+			}
+			if category, ok := s.category(string(key)); ok {
+				s.add(category, 1, len(key)+len(value))
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Usage returns the usage of all categories in the order they were given.
+func (s *Store) Usage() []Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := make([]Usage, 0, len(s.categories))
+	for _, c := range s.categories {
+		usage = append(usage, *s.usage[c.Name])
+	}
+	return usage
+}
+
+// category returns the category of the stored key.
+func (s *Store) category(key string) (string, bool) {
+	key = s.key(key)
+	var (
+		name    string
+		longest = -1
+	)
+	for _, c := range s.categories {
+		for _, prefix := range c.Prefixes {
+			if len(prefix) > longest && strings.HasPrefix(key, prefix) {
+				name, longest = c.Name, len(prefix)
+			}
+		}
+	}
+	return name, longest >= 0
+}
+
+// add changes the usage of the category by the number of records and bytes.
+func (s *Store) add(category string, records, bytes int) {
+	u := s.usage[category]
+	u.Records = uint64(int64(u.Records) + int64(records))
+	u.Bytes = uint64(int64(u.Bytes) + int64(bytes))
+	s.metrics.Records.WithLabelValues(category).Set(float64(u.Records))
+	s.metrics.Bytes.WithLabelValues(category).Set(float64(u.Bytes))
+}
+
+// size returns the size of the stored value of the key and whether it exists.
+func (s *Store) size(key string) (int, bool, error) {
+	var value rawValue
+	err := s.StateStorer.Get(key, &value)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return len(value), true, nil
+}
+
+// Put implements storage.StateStorer.Put method.
+func (s *Store) Put(key string, i interface{}) (err error) {
+	category, ok := s.category(key)
+	if !ok {
+		return s.StateStorer.Put(key, i)
+	}
+
+	// the value is marshaled here to know its size and stored as it is
+	var data rawValue
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		if data, err = marshaler.MarshalBinary(); err != nil {
+			return err
+		}
+	} else if data, err = json.Marshal(i); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, exists, err := s.size(key)
+	if err != nil {
+		return err
+	}
+	if err := s.StateStorer.Put(key, data); err != nil {
+		return err
+	}
+
+	if exists {
+		s.add(category, 0, len(data)-previous)
+	} else {
+		s.add(category, 1, len(key)+len(data))
+	}
+	return nil
+}
+
+// Delete implements storage.StateStorer.Delete method.
+func (s *Store) Delete(key string) error {
+	category, ok := s.category(key)
+	if !ok {
+		return s.StateStorer.Delete(key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, exists, err := s.size(key)
+	if err != nil {
+		return err
+	}
+	if err := s.StateStorer.Delete(key); err != nil {
+		return err
+	}
+
+	if exists {
+		s.add(category, -1, -(len(key) + previous))
+	}
+	return nil
+}
+
+// DB implements storage.StateStorer.DB method.
+func (s *Store) DB() *leveldb.DB {
+	return s.StateStorer.DB()
+}
+
+// rawValue is stored as it is.
+type rawValue []byte
+
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *rawValue) UnmarshalBinary(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
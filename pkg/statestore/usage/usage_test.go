@@ -0,0 +1,98 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/statestore/test"
+	"github.com/ethersphere/bee/pkg/statestore/usage"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestUsageStateStore(t *testing.T) {
+	t.Parallel()
+
+	test.Run(t, func(t *testing.T) storage.StateStorer {
+		t.Helper()
+		store, err := usage.New(mock.NewStateStore(), usage.Category{Name: "keys", Prefixes: []string{"key", "some_"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	underlying := mock.NewStateStore()
+	// existing records are counted when the store is created
+	if err := underlying.Put("swap_cheque_a", "aa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := underlying.Put("swap_history_a", "aa"); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := usage.New(underlying,
+		usage.Category{Name: "cheques", Prefixes: []string{"swap_cheque_"}},
+		usage.Category{Name: "other", Prefixes: []string{"swap_"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectUsage := func(t *testing.T, want ...usage.Usage) {
+		t.Helper()
+		if got := store.Usage(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got usage %+v, want %+v", got, want)
+		}
+	}
+
+	// the keys are 13 and 14 bytes, the json encoded values 4 bytes
+	expectUsage(t,
+		usage.Usage{Category: "cheques", Records: 1, Bytes: 17},
+		usage.Usage{Category: "other", Records: 1, Bytes: 18},
+	)
+
+	if err := store.Put("swap_cheque_b", "bbbb"); err != nil {
+		t.Fatal(err)
+	}
+	// overwriting a record only changes its size
+	if err := store.Put("swap_cheque_a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	// untracked keys are not counted
+	if err := store.Put("accounting_a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	expectUsage(t,
+		usage.Usage{Category: "cheques", Records: 2, Bytes: 35},
+		usage.Usage{Category: "other", Records: 1, Bytes: 18},
+	)
+
+	var value string
+	if err := store.Get("swap_cheque_b", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "bbbb" {
+		t.Fatalf("got value %q, want %q", value, "bbbb")
+	}
+
+	if err := store.Delete("swap_history_a"); err != nil {
+		t.Fatal(err)
+	}
+	// deleting a missing record changes nothing
+	if err := store.Delete("swap_history_b"); err != nil {
+		t.Fatal(err)
+	}
+	expectUsage(t,
+		usage.Usage{Category: "cheques", Records: 2, Bytes: 35},
+		usage.Usage{Category: "other", Records: 0, Bytes: 0},
+	)
+}
@@ -0,0 +1,783 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package backendfake provides an in-memory transaction.Backend which keeps
+// balances, nonces, blocks and receipts like a chain would. Unlike the mocks
+// it can be scripted step by step: transactions stay pending until blocks are
+// mined, can be made to revert, can be reorged out of the chain again and
+// every call can be delayed. This allows higher layers to test bounce
+// handling, reorg recovery and retry logic deterministically.
+package backendfake
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+const (
+	// DefaultChainID is the chain id of the fake chain unless set otherwise.
+	DefaultChainID = 1337
+	// DefaultGasEstimate is the gas estimated for every call unless set otherwise.
+	DefaultGasEstimate = 21000
+	// DefaultBlockTime is the time between two blocks unless set otherwise.
+	DefaultBlockTime = 5 * time.Second
+)
+
+var (
+	// ErrNonceTooLow is the error returned when sending a transaction with a
+	// nonce already used by a transaction in the chain.
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrInsufficientFunds is the error returned when sending a transaction
+	// the sender cannot pay the maximum cost of.
+	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+	// ErrAlreadyKnown is the error returned when sending a transaction again.
+	ErrAlreadyKnown = errors.New("already known")
+	// ErrFeeCapTooLow is the error returned when sending a transaction with a
+	// fee cap below the base fee.
+	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+	// ErrReorgTooDeep is the error returned when reorging beyond the genesis block.
+	ErrReorgTooDeep = errors.New("reorg too deep")
+)
+
+// state are the balances and nonces of the accounts.
+type state struct {
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+}
+
+func newState() state {
+	return state{
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address]uint64),
+	}
+}
+
+func (s state) copy() state {
+	c := newState()
+	for a, b := range s.balances {
+		c.balances[a] = new(big.Int).Set(b)
+	}
+	for a, n := range s.nonces {
+		c.nonces[a] = n
+	}
+	return c
+}
+
+func (s state) balance(a common.Address) *big.Int {
+	if b, ok := s.balances[a]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+type block struct {
+	header   *types.Header
+	txs      []*types.Transaction
+	receipts []*types.Receipt
+	state    state // state after the block
+}
+
+// Backend is a scriptable in-memory transaction.Backend.
+type Backend struct {
+	mu sync.Mutex
+
+	chainID     *big.Int
+	signer      types.Signer
+	genesisTime uint64
+	blockTime   time.Duration
+	baseFee     *big.Int
+	gasTipCap   *big.Int
+	gasEstimate uint64
+	latency     time.Duration
+	autoMine    bool
+
+	blocks  []*block
+	state   state // state of the pending block
+	pending []*types.Transaction
+	reorgs  uint64 // number of reorgs, makes the hashes of re-mined blocks differ
+
+	code       map[common.Address][]byte
+	reverts    map[common.Hash]bool
+	revertFunc func(tx *types.Transaction) bool
+	callFunc   func(call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	logsFunc   func(tx *types.Transaction) []*types.Log
+}
+
+var _ transaction.Backend = (*Backend)(nil)
+
+type Option interface {
+	apply(*Backend)
+}
+
+type optionFunc func(*Backend)
+
+func (f optionFunc) apply(b *Backend) { f(b) }
+
+// WithChainID sets the chain id.
+func WithChainID(chainID int64) Option {
+	return optionFunc(func(b *Backend) {
+		b.chainID = big.NewInt(chainID)
+	})
+}
+
+// WithBalance sets the balance of the account in the genesis block.
+func WithBalance(account common.Address, balance *big.Int) Option {
+	return optionFunc(func(b *Backend) {
+		b.state.balances[account] = new(big.Int).Set(balance)
+	})
+}
+
+// WithCode sets the code of the contract.
+func WithCode(contract common.Address, code []byte) Option {
+	return optionFunc(func(b *Backend) {
+		b.code[contract] = code
+	})
+}
+
+// WithBaseFee sets the base fee of all blocks.
+func WithBaseFee(baseFee *big.Int) Option {
+	return optionFunc(func(b *Backend) {
+		b.baseFee = baseFee
+	})
+}
+
+// WithGasTipCap sets the suggested gas tip cap.
+func WithGasTipCap(gasTipCap *big.Int) Option {
+	return optionFunc(func(b *Backend) {
+		b.gasTipCap = gasTipCap
+	})
+}
+
+// WithGasEstimate sets the gas estimated for every call.
+func WithGasEstimate(gas uint64) Option {
+	return optionFunc(func(b *Backend) {
+		b.gasEstimate = gas
+	})
+}
+
+// WithBlockTime sets the time between two blocks.
+func WithBlockTime(blockTime time.Duration) Option {
+	return optionFunc(func(b *Backend) {
+		b.blockTime = blockTime
+	})
+}
+
+// WithGenesisTime sets the time of the genesis block.
+func WithGenesisTime(t time.Time) Option {
+	return optionFunc(func(b *Backend) {
+		b.genesisTime = uint64(t.Unix())
+	})
+}
+
+// WithLatency delays every call by the given duration.
+func WithLatency(latency time.Duration) Option {
+	return optionFunc(func(b *Backend) {
+		b.latency = latency
+	})
+}
+
+// WithAutoMine mines a block for every sent transaction.
+func WithAutoMine() Option {
+	return optionFunc(func(b *Backend) {
+		b.autoMine = true
+	})
+}
+
+// WithCallFunc sets the function answering contract calls.
+func WithCallFunc(f func(call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)) Option {
+	return optionFunc(func(b *Backend) {
+		b.callFunc = f
+	})
+}
+
+// WithRevertFunc reverts every mined transaction for which f returns true.
+func WithRevertFunc(f func(tx *types.Transaction) bool) Option {
+	return optionFunc(func(b *Backend) {
+		b.revertFunc = f
+	})
+}
+
+// WithLogsFunc sets the function returning the logs emitted by a successful
+// transaction. The block and transaction fields of the logs are filled in.
+func WithLogsFunc(f func(tx *types.Transaction) []*types.Log) Option {
+	return optionFunc(func(b *Backend) {
+		b.logsFunc = f
+	})
+}
+
+// New creates a fake backend with only the genesis block.
+func New(opts ...Option) *Backend {
+	b := &Backend{
+		chainID:     big.NewInt(DefaultChainID),
+		blockTime:   DefaultBlockTime,
+		baseFee:     big.NewInt(1),
+		gasTipCap:   big.NewInt(1),
+		gasEstimate: DefaultGasEstimate,
+		state:       newState(),
+		code:        make(map[common.Address][]byte),
+		reverts:     make(map[common.Hash]bool),
+	}
+	for _, opt := range opts {
+		opt.apply(b)
+	}
+	b.signer = types.LatestSignerForChainID(b.chainID)
+
+	genesis := &block{state: b.state.copy()}
+	genesis.header = b.newHeader(0, common.Hash{})
+	b.blocks = []*block{genesis}
+
+	return b
+}
+
+func (b *Backend) newHeader(number uint64, parent common.Hash) *types.Header {
+	extra := make([]byte, 8)
+	binary.BigEndian.PutUint64(extra, b.reorgs)
+	return &types.Header{
+		ParentHash: parent,
+		Number:     new(big.Int).SetUint64(number),
+		Time:       b.genesisTime + number*uint64(b.blockTime/time.Second),
+		BaseFee:    new(big.Int).Set(b.baseFee),
+		Extra:      extra,
+	}
+}
+
+func (b *Backend) head() *block {
+	return b.blocks[len(b.blocks)-1]
+}
+
+// delay waits for the configured latency.
+func (b *Backend) delay(ctx context.Context) error {
+	b.mu.Lock()
+	latency := b.latency
+	b.mu.Unlock()
+	if latency <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(latency):
+		return nil
+	}
+}
+
+// blockAt returns the block with the given number, the head for nil.
+func (b *Backend) blockAt(number *big.Int) (*block, error) {
+	if number == nil {
+		return b.head(), nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(b.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return b.blocks[number.Uint64()], nil
+}
+
+// SetLatency delays every following call by the given duration.
+func (b *Backend) SetLatency(latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latency = latency
+}
+
+// SetBalance sets the balance of the account from the next block on.
+func (b *Backend) SetBalance(account common.Address, balance *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.balances[account] = new(big.Int).Set(balance)
+	b.head().state.balances[account] = new(big.Int).Set(balance)
+}
+
+// SetBaseFee sets the base fee of the following blocks.
+func (b *Backend) SetBaseFee(baseFee *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.baseFee = baseFee
+}
+
+// Revert makes the transaction revert once it is mined.
+func (b *Backend) Revert(txHash common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reverts[txHash] = true
+}
+
+// Pending returns the hashes of the transactions not yet mined.
+func (b *Backend) Pending() []common.Hash {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	hashes := make([]common.Hash, 0, len(b.pending))
+	for _, tx := range b.pending {
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes
+}
+
+// Drop removes the transaction from the pending transactions as if it was
+// evicted from the pool. It reports whether the transaction was pending.
+func (b *Backend) Drop(txHash common.Hash) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, tx := range b.pending {
+		if tx.Hash() == txHash {
+			b.pending = append(b.pending[:i], b.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Head returns the number of the latest block.
+func (b *Backend) Head() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head().header.Number.Uint64()
+}
+
+// Mine mines n blocks. The pending transactions which can be executed are
+// included in the first one.
+func (b *Backend) Mine(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := 0; i < n; i++ {
+		b.mine()
+	}
+}
+
+// Reorg removes the latest depth blocks from the chain. Their transactions
+// become pending again and their receipts disappear until they are mined
+// again. Balances set after the new head are lost.
+func (b *Backend) Reorg(depth int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if depth >= len(b.blocks) {
+		return ErrReorgTooDeep
+	}
+	var txs []*types.Transaction
+	for _, blk := range b.blocks[len(b.blocks)-depth:] {
+		txs = append(txs, blk.txs...)
+	}
+	b.blocks = b.blocks[:len(b.blocks)-depth]
+	b.pending = append(txs, b.pending...)
+	b.state = b.head().state.copy()
+	b.reorgs++
+	return nil
+}
+
+func (b *Backend) mine() {
+	parent := b.head()
+	header := b.newHeader(parent.header.Number.Uint64()+1, parent.header.Hash())
+	blk := &block{header: header}
+
+	// include pending transactions in nonce order as long as progress is made
+	sort.SliceStable(b.pending, func(i, j int) bool {
+		return b.pending[i].Nonce() < b.pending[j].Nonce()
+	})
+	for progress := true; progress; {
+		progress = false
+		remaining := b.pending[:0:0]
+		for _, tx := range b.pending {
+			receipt, ok := b.execute(tx, header, uint(len(blk.txs)))
+			if !ok {
+				remaining = append(remaining, tx)
+				continue
+			}
+			blk.txs = append(blk.txs, tx)
+			blk.receipts = append(blk.receipts, receipt)
+			progress = true
+		}
+		b.pending = remaining
+	}
+
+	var gasUsed uint64
+	for _, r := range blk.receipts {
+		gasUsed += r.GasUsed
+	}
+	header.GasUsed = gasUsed
+	hash := header.Hash()
+	for _, r := range blk.receipts {
+		r.BlockHash = hash
+		for _, l := range r.Logs {
+			l.BlockHash = hash
+		}
+	}
+
+	blk.state = b.state.copy()
+	b.blocks = append(b.blocks, blk)
+}
+
+// execute applies the transaction to the pending state. It reports false if
+// the transaction cannot be included in the block.
+func (b *Backend) execute(tx *types.Transaction, header *types.Header, index uint) (*types.Receipt, bool) {
+	sender, err := types.Sender(b.signer, tx)
+	if err != nil {
+		return nil, false
+	}
+	if tx.Nonce() != b.state.nonces[sender] || tx.GasFeeCap().Cmp(header.BaseFee) < 0 {
+		return nil, false
+	}
+
+	price := new(big.Int).Add(header.BaseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price = tx.GasFeeCap()
+	}
+	fee := new(big.Int).Mul(price, new(big.Int).SetUint64(tx.Gas()))
+	balance := b.state.balance(sender)
+	if balance.Cmp(fee) < 0 {
+		return nil, false
+	}
+
+	receipt := &types.Receipt{
+		Type:             tx.Type(),
+		Status:           types.ReceiptStatusSuccessful,
+		TxHash:           tx.Hash(),
+		GasUsed:          tx.Gas(),
+		BlockNumber:      new(big.Int).Set(header.Number),
+		TransactionIndex: index,
+	}
+	if tx.To() == nil {
+		receipt.ContractAddress = crypto.CreateAddress(sender, tx.Nonce())
+	}
+
+	balance = new(big.Int).Sub(balance, fee)
+	reverted := b.reverts[tx.Hash()] || (b.revertFunc != nil && b.revertFunc(tx)) || balance.Cmp(tx.Value()) < 0
+	if reverted {
+		receipt.Status = types.ReceiptStatusFailed
+	} else {
+		balance.Sub(balance, tx.Value())
+		if to := tx.To(); to != nil {
+			b.state.balances[*to] = new(big.Int).Add(b.state.balance(*to), tx.Value())
+		}
+		if b.logsFunc != nil {
+			for _, l := range b.logsFunc(tx) {
+				l.BlockNumber = header.Number.Uint64()
+				l.TxHash = tx.Hash()
+				l.TxIndex = index
+				receipt.Logs = append(receipt.Logs, l)
+			}
+		}
+	}
+	b.state.balances[sender] = balance
+	b.state.nonces[sender]++
+	receipt.CumulativeGasUsed = receipt.GasUsed
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	return receipt, true
+}
+
+func (b *Backend) findTransaction(hash common.Hash) (*types.Transaction, *types.Receipt, bool) {
+	for _, blk := range b.blocks {
+		for i, tx := range blk.txs {
+			if tx.Hash() == hash {
+				return tx, blk.receipts[i], false
+			}
+		}
+	}
+	for _, tx := range b.pending {
+		if tx.Hash() == hash {
+			return tx, nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.code[contract], nil
+}
+
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	callFunc := b.callFunc
+	b.mu.Unlock()
+	if callFunc == nil {
+		return nil, nil
+	}
+	return callFunc(call, blockNumber)
+}
+
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	blk, err := b.blockAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return types.CopyHeader(blk.header), nil
+}
+
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := b.delay(ctx); err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nonce := b.state.nonces[account]
+	for _, tx := range b.pending {
+		sender, err := types.Sender(b.signer, tx)
+		if err == nil && sender == account && tx.Nonce() >= nonce {
+			nonce = tx.Nonce() + 1
+		}
+	}
+	return nonce, nil
+}
+
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return new(big.Int).Add(b.baseFee, b.gasTipCap), nil
+}
+
+func (b *Backend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return new(big.Int).Set(b.gasTipCap), nil
+}
+
+func (b *Backend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last, err := b.blockAt(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	lastNumber := last.header.Number.Uint64()
+	if blockCount > lastNumber+1 {
+		blockCount = lastNumber + 1
+	}
+	history := &ethereum.FeeHistory{
+		OldestBlock: new(big.Int).SetUint64(lastNumber + 1 - blockCount),
+	}
+	for n := lastNumber + 1 - blockCount; n <= lastNumber; n++ {
+		history.BaseFee = append(history.BaseFee, new(big.Int).Set(b.blocks[n].header.BaseFee))
+		history.GasUsedRatio = append(history.GasUsedRatio, 0)
+		rewards := make([]*big.Int, len(rewardPercentiles))
+		for i := range rewards {
+			rewards[i] = new(big.Int).Set(b.gasTipCap)
+		}
+		history.Reward = append(history.Reward, rewards)
+	}
+	history.BaseFee = append(history.BaseFee, new(big.Int).Set(b.baseFee))
+	return history, nil
+}
+
+func (b *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := b.delay(ctx); err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gasEstimate, nil
+}
+
+// SendTransaction adds the transaction to the pending transactions. Like a
+// node it rejects transactions with a used nonce or whose maximum cost
+// exceeds the balance of the sender.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.delay(ctx); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sender, err := types.Sender(b.signer, tx)
+	if err != nil {
+		return err
+	}
+	if _, _, pending := b.findTransaction(tx.Hash()); pending {
+		return ErrAlreadyKnown
+	}
+	if tx.Nonce() < b.state.nonces[sender] {
+		return ErrNonceTooLow
+	}
+	if tx.GasFeeCap().Cmp(b.baseFee) < 0 {
+		return ErrFeeCapTooLow
+	}
+	if b.state.balance(sender).Cmp(tx.Cost()) < 0 {
+		return fmt.Errorf("%w: address %s have %d want %d", ErrInsufficientFunds, sender, b.state.balance(sender), tx.Cost())
+	}
+
+	// a transaction with the same nonce replaces the pending one
+	for i, p := range b.pending {
+		if s, err := types.Sender(b.signer, p); err == nil && s == sender && p.Nonce() == tx.Nonce() {
+			b.pending = append(b.pending[:i], b.pending[i+1:]...)
+			break
+		}
+	}
+	b.pending = append(b.pending, tx)
+
+	if b.autoMine {
+		b.mine()
+	}
+	return nil
+}
+
+func (b *Backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, receipt, _ := b.findTransaction(txHash)
+	if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (b *Backend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, false, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tx, _, pending := b.findTransaction(hash)
+	if tx == nil {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, pending, nil
+}
+
+func (b *Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := b.delay(ctx); err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head().header.Number.Uint64(), nil
+}
+
+func (b *Backend) BalanceAt(ctx context.Context, address common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	blk, err := b.blockAt(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(blk.state.balance(address)), nil
+}
+
+func (b *Backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if err := b.delay(ctx); err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	blk, err := b.blockAt(blockNumber)
+	if err != nil {
+		return 0, err
+	}
+	return blk.state.nonces[account], nil
+}
+
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from, to := uint64(0), b.head().header.Number.Uint64()
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil && query.ToBlock.Uint64() < to {
+		to = query.ToBlock.Uint64()
+	}
+
+	var logs []types.Log
+	for n := from; n <= to && n < uint64(len(b.blocks)); n++ {
+		blk := b.blocks[n]
+		if query.BlockHash != nil && *query.BlockHash != blk.header.Hash() {
+			continue
+		}
+		for _, r := range blk.receipts {
+			for _, l := range r.Logs {
+				if matches(query, l) {
+					logs = append(logs, *l)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+// matches reports whether the log matches the addresses and topics of the query.
+func matches(query ethereum.FilterQuery, l *types.Log) bool {
+	if len(query.Addresses) > 0 {
+		found := false
+		for _, a := range query.Addresses {
+			if a == l.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(query.Topics) > len(l.Topics) {
+		return false
+	}
+	for i, topics := range query.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		found := false
+		for _, t := range topics {
+			if t == l.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := b.delay(ctx); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.chainID), nil
+}
+
+func (b *Backend) Close() {}
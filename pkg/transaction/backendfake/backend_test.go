@@ -0,0 +1,311 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backendfake_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendfake"
+)
+
+func newSigner(t testing.TB) (crypto.Signer, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, address
+}
+
+func signTx(t testing.TB, signer crypto.Signer, nonce uint64, to common.Address, value int64) *types.Transaction {
+	t.Helper()
+
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(backendfake.DefaultChainID),
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(value),
+		Gas:       backendfake.DefaultGasEstimate,
+		GasFeeCap: big.NewInt(2),
+		GasTipCap: big.NewInt(1),
+	}), big.NewInt(backendfake.DefaultChainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func newTransactionService(t *testing.T, backend transaction.Backend, signer crypto.Signer, sender common.Address) transaction.Service {
+	t.Helper()
+
+	heads := transaction.NewHeadListener(log.Noop, backend, 10*time.Millisecond)
+	monitor := transaction.NewMonitor(log.Noop, backend, sender, heads, 5)
+	service, err := transaction.NewService(log.Noop, backend, signer, mockstore.NewStateStore(), big.NewInt(backendfake.DefaultChainID), monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := service.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := monitor.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := heads.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return service
+}
+
+func TestSendAndMine(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	recipient := common.HexToAddress("0xabcd")
+	backend := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+	service := newTransactionService(t, backend, signer, sender)
+
+	ctx := context.Background()
+	txHash, err := service.Send(ctx, &transaction.TxRequest{To: &recipient, Value: big.NewInt(100)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pending := backend.Pending(); len(pending) != 1 || pending[0] != txHash {
+		t.Fatalf("got pending %v, want %v", pending, txHash)
+	}
+	if _, err := backend.TransactionReceipt(ctx, txHash); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+	}
+
+	backend.Mine(1)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	receipt, err := service.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.BlockNumber.Uint64() != 1 {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+
+	balance, err := backend.BalanceAt(ctx, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got recipient balance %d, want 100", balance)
+	}
+	nonce, err := backend.NonceAt(ctx, sender, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce != 1 {
+		t.Fatalf("got nonce %d, want 1", nonce)
+	}
+	balance, err = backend.BalanceAt(ctx, sender, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(1000000)) != 0 {
+		t.Fatalf("got sender balance at genesis %d, want 1000000", balance)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	recipient := common.HexToAddress("0xabcd")
+	backend := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+	ctx := context.Background()
+
+	tx := signTx(t, signer, 0, recipient, 100)
+	backend.Revert(tx.Hash())
+	if err := backend.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	backend.Mine(1)
+
+	receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusFailed {
+		t.Fatal("expected the transaction to revert")
+	}
+
+	balance, err := backend.BalanceAt(ctx, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Sign() != 0 {
+		t.Fatalf("got recipient balance %d, want 0", balance)
+	}
+	// only the gas was paid
+	balance, err = backend.BalanceAt(ctx, sender, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(1000000 - 2*backendfake.DefaultGasEstimate); balance.Cmp(want) != 0 {
+		t.Fatalf("got sender balance %d, want %d", balance, want)
+	}
+}
+
+func TestReorg(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	backend := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+	ctx := context.Background()
+
+	tx := signTx(t, signer, 0, common.HexToAddress("0xabcd"), 100)
+	if err := backend.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	backend.Mine(2)
+
+	receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Reorg(3); !errors.Is(err, backendfake.ErrReorgTooDeep) {
+		t.Fatalf("got error %v, want %v", err, backendfake.ErrReorgTooDeep)
+	}
+	if err := backend.Reorg(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if head := backend.Head(); head != 0 {
+		t.Fatalf("got head %d, want 0", head)
+	}
+	if _, err := backend.TransactionReceipt(ctx, tx.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+	}
+	if _, pending, err := backend.TransactionByHash(ctx, tx.Hash()); err != nil || !pending {
+		t.Fatalf("got pending %t and error %v, want the transaction to be pending again", pending, err)
+	}
+	nonce, err := backend.NonceAt(ctx, sender, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce != 0 {
+		t.Fatalf("got nonce %d, want 0", nonce)
+	}
+
+	backend.Mine(1)
+
+	reorged, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reorged.BlockNumber.Cmp(receipt.BlockNumber) != 0 || reorged.BlockHash == receipt.BlockHash {
+		t.Fatalf("got receipt in block %d %x, want a different block with number %d", reorged.BlockNumber, reorged.BlockHash, receipt.BlockNumber)
+	}
+}
+
+func TestSendTransactionRejected(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	backend := backendfake.New(backendfake.WithBalance(sender, big.NewInt(50000)), backendfake.WithAutoMine())
+	ctx := context.Background()
+
+	if err := backend.SendTransaction(ctx, signTx(t, signer, 0, common.HexToAddress("0xabcd"), 10000)); !errors.Is(err, backendfake.ErrInsufficientFunds) {
+		t.Fatalf("got error %v, want %v", err, backendfake.ErrInsufficientFunds)
+	}
+	if err := backend.SendTransaction(ctx, signTx(t, signer, 0, common.HexToAddress("0xabcd"), 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.SendTransaction(ctx, signTx(t, signer, 0, common.HexToAddress("0xabcd"), 2)); !errors.Is(err, backendfake.ErrNonceTooLow) {
+		t.Fatalf("got error %v, want %v", err, backendfake.ErrNonceTooLow)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	backend := backendfake.New()
+	backend.SetLatency(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := backend.BlockNumber(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// FuzzChain applies a sequence of sends, mined blocks and reorgs and checks
+// that the chain stays consistent.
+func FuzzChain(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 0, 1, 2, 1})
+	f.Add([]byte{0, 0, 0, 1, 2, 2, 1, 0, 1})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		signer, sender := newSigner(t)
+		recipient := common.HexToAddress("0xabcd")
+		backend := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+		ctx := context.Background()
+
+		var nonce uint64
+		for _, op := range ops {
+			switch op % 3 {
+			case 0:
+				pending, err := backend.PendingNonceAt(ctx, sender)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := backend.SendTransaction(ctx, signTx(t, signer, pending, recipient, 1)); err != nil && !errors.Is(err, backendfake.ErrInsufficientFunds) {
+					t.Fatal(err)
+				}
+			case 1:
+				backend.Mine(1)
+			case 2:
+				_ = backend.Reorg(1)
+			}
+
+			mined, err := backend.NonceAt(ctx, sender, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mined < nonce && op%3 != 2 {
+				t.Fatalf("nonce decreased from %d to %d without a reorg", nonce, mined)
+			}
+			nonce = mined
+
+			sent, err := backend.BalanceAt(ctx, sender, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			received, err := backend.BalanceAt(ctx, recipient, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent.Sign() < 0 || received.Uint64() != mined {
+				t.Fatalf("got balances %d and %d after %d transactions", sent, received, mined)
+			}
+		}
+	})
+}
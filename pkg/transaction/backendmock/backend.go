@@ -20,6 +20,7 @@ type backendMock struct {
 	sendTransaction    func(ctx context.Context, tx *types.Transaction) error
 	suggestGasPrice    func(ctx context.Context) (*big.Int, error)
 	suggestGasTipCap   func(ctx context.Context) (*big.Int, error)
+	feeHistory         func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	estimateGas        func(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error)
 	transactionReceipt func(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
 	pendingNonceAt     func(ctx context.Context, account common.Address) (uint64, error)
@@ -29,6 +30,7 @@ type backendMock struct {
 	headerByNumber     func(ctx context.Context, number *big.Int) (*types.Header, error)
 	balanceAt          func(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error)
 	nonceAt            func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	filterLogs         func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
 }
 
 func (m *backendMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
@@ -74,7 +76,10 @@ func (m *backendMock) SendTransaction(ctx context.Context, tx *types.Transaction
 	return errors.New("not implemented")
 }
 
-func (*backendMock) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+func (m *backendMock) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if m.filterLogs != nil {
+		return m.filterLogs(ctx, query)
+	}
 	return nil, errors.New("not implemented")
 }
 
@@ -137,6 +142,13 @@ func (m *backendMock) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *backendMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if m.feeHistory != nil {
+		return m.feeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *backendMock) ChainID(ctx context.Context) (*big.Int, error) {
 	return nil, errors.New("not implemented")
 }
@@ -191,6 +203,12 @@ func WithSuggestGasTipCapFunc(f func(ctx context.Context) (*big.Int, error)) Opt
 	})
 }
 
+func WithFeeHistoryFunc(f func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)) Option {
+	return optionFunc(func(s *backendMock) {
+		s.feeHistory = f
+	})
+}
+
 func WithEstimateGasFunc(f func(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error)) Option {
 	return optionFunc(func(s *backendMock) {
 		s.estimateGas = f
@@ -227,6 +245,12 @@ func WithBlockNumberFunc(f func(context.Context) (uint64, error)) Option {
 	})
 }
 
+func WithFilterLogsFunc(f func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)) Option {
+	return optionFunc(func(s *backendMock) {
+		s.filterLogs = f
+	})
+}
+
 func WithHeaderbyNumberFunc(f func(ctx context.Context, number *big.Int) (*types.Header, error)) Option {
 	return optionFunc(func(s *backendMock) {
 		s.headerByNumber = f
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,6 +22,7 @@ type AccountAtKey struct {
 }
 
 type simulatedBackend struct {
+	lock        sync.Mutex
 	blockNumber uint64
 
 	receipts map[common.Hash]*types.Receipt
@@ -127,6 +129,9 @@ func (*simulatedBackend) SubscribeFilterLogs(ctx context.Context, query ethereum
 }
 
 func (m *simulatedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	receipt, ok := m.receipts[txHash]
 	if ok {
 		return receipt, nil
@@ -140,6 +145,9 @@ func (m *simulatedBackend) TransactionByHash(ctx context.Context, hash common.Ha
 }
 
 func (m *simulatedBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	m.advanceBlock()
 	return m.blockNumber, nil
 }
@@ -152,6 +160,9 @@ func (m *simulatedBackend) BalanceAt(ctx context.Context, address common.Address
 	return nil, errors.New("not implemented")
 }
 func (m *simulatedBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	nonce, ok := m.noncesAt[AccountAtKey{Account: account, BlockNumber: blockNumber.Uint64()}]
 	if ok {
 		return nonce, nil
@@ -164,6 +175,10 @@ func (m *simulatedBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, erro
 	return nil, errors.New("not implemented")
 }
 
+func (m *simulatedBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *simulatedBackend) ChainID(ctx context.Context) (*big.Int, error) {
 	return nil, errors.New("not implemented")
 }
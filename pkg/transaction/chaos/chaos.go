@@ -0,0 +1,300 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chaos injects faults into blockchain backend calls: transactions
+// are dropped, receipts show up late, stale pending nonces lead to duplicate
+// nonces and calls fail with transient errors. It is used to check that the
+// recovery paths of the transaction service and the settlement pipeline built
+// on top of it work end to end.
+//
+// Nodes only inject faults if they are built with the chaos build tag and
+// the faults are configured in the BEE_CHAOS environment variable, see Wrap.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "chaos"
+
+// EnvName is the environment variable the faults are configured with in
+// builds with the chaos build tag.
+const EnvName = "BEE_CHAOS"
+
+// ErrInjected is wrapped by every error injected into a call.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Options configures the injected faults. Rates are probabilities between 0
+// and 1 applied to every call of the affected methods.
+type Options struct {
+	DropRate           float64       // transactions reported as sent but never forwarded
+	ReceiptDelay       time.Duration // receipts are hidden for this long after they first show up
+	DuplicateNonceRate float64       // pending nonces reported one too low
+	ErrorRate          float64       // calls failing with a transient error
+	Seed               int64         // seed of the random source, 0 uses the current time
+}
+
+// Active reports whether any fault is injected.
+func (o Options) Active() bool {
+	return o.DropRate > 0 || o.ReceiptDelay > 0 || o.DuplicateNonceRate > 0 || o.ErrorRate > 0
+}
+
+// ParseOptions parses comma separated key=value pairs, e.g.
+// "drop=0.1,receipt-delay=30s,duplicate-nonce=0.05,error=0.01,seed=1".
+func ParseOptions(s string) (Options, error) {
+	var o Options
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Options{}, fmt.Errorf("invalid chaos option %q, expected key=value", pair)
+		}
+		var err error
+		switch strings.TrimSpace(key) {
+		case "drop":
+			o.DropRate, err = parseRate(value)
+		case "receipt-delay":
+			o.ReceiptDelay, err = time.ParseDuration(strings.TrimSpace(value))
+		case "duplicate-nonce":
+			o.DuplicateNonceRate, err = parseRate(value)
+		case "error":
+			o.ErrorRate, err = parseRate(value)
+		case "seed":
+			o.Seed, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		default:
+			return Options{}, fmt.Errorf("unknown chaos option %q", key)
+		}
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid chaos option %q: %w", pair, err)
+		}
+	}
+	return o, nil
+}
+
+func parseRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %v not between 0 and 1", rate)
+	}
+	return rate, nil
+}
+
+var _ transaction.Backend = (*backend)(nil)
+
+type backend struct {
+	transaction.Backend
+	logger  log.Logger
+	options Options
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	receipts map[common.Hash]time.Time // when the receipt was first seen
+	now      func() time.Time
+}
+
+// NewBackend wraps the backend so that the configured faults are injected
+// into its calls.
+func NewBackend(logger log.Logger, b transaction.Backend, o Options) transaction.Backend {
+	seed := o.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &backend{
+		Backend:  b,
+		logger:   logger.WithName(loggerName).Register(),
+		options:  o,
+		rand:     rand.New(rand.NewSource(seed)),
+		receipts: make(map[common.Hash]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (b *backend) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rand.Float64() < rate
+}
+
+// fail returns a transient error with the configured error rate.
+func (b *backend) fail(method string) error {
+	if !b.roll(b.options.ErrorRate) {
+		return nil
+	}
+	b.logger.Debug("injecting error", "method", method)
+	return fmt.Errorf("%w: %w", ErrInjected, rpc.HTTPError{
+		StatusCode: http.StatusServiceUnavailable,
+		Status:     http.StatusText(http.StatusServiceUnavailable),
+	})
+}
+
+func (b *backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := b.fail("CodeAt"); err != nil {
+		return nil, err
+	}
+	return b.Backend.CodeAt(ctx, contract, blockNumber)
+}
+
+func (b *backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := b.fail("CallContract"); err != nil {
+		return nil, err
+	}
+	return b.Backend.CallContract(ctx, call, blockNumber)
+}
+
+func (b *backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := b.fail("HeaderByNumber"); err != nil {
+		return nil, err
+	}
+	return b.Backend.HeaderByNumber(ctx, number)
+}
+
+// PendingNonceAt reports a nonce one too low with the configured rate as a
+// node lagging behind would, so that a nonce is used twice.
+func (b *backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := b.fail("PendingNonceAt"); err != nil {
+		return 0, err
+	}
+	nonce, err := b.Backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	if nonce > 0 && b.roll(b.options.DuplicateNonceRate) {
+		b.logger.Debug("injecting duplicate nonce", "account", account, "nonce", nonce-1)
+		return nonce - 1, nil
+	}
+	return nonce, nil
+}
+
+func (b *backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := b.fail("SuggestGasPrice"); err != nil {
+		return nil, err
+	}
+	return b.Backend.SuggestGasPrice(ctx)
+}
+
+func (b *backend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := b.fail("SuggestGasTipCap"); err != nil {
+		return nil, err
+	}
+	return b.Backend.SuggestGasTipCap(ctx)
+}
+
+func (b *backend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if err := b.fail("FeeHistory"); err != nil {
+		return nil, err
+	}
+	return b.Backend.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
+func (b *backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := b.fail("EstimateGas"); err != nil {
+		return 0, err
+	}
+	return b.Backend.EstimateGas(ctx, call)
+}
+
+// SendTransaction drops the transaction with the configured rate while
+// reporting success, as if it got lost on its way to the network.
+func (b *backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.fail("SendTransaction"); err != nil {
+		return err
+	}
+	if b.roll(b.options.DropRate) {
+		b.logger.Debug("dropping transaction", "tx", tx.Hash(), "nonce", tx.Nonce())
+		return nil
+	}
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+// TransactionReceipt hides receipts for the configured delay after they were
+// first seen.
+func (b *backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := b.fail("TransactionReceipt"); err != nil {
+		return nil, err
+	}
+	receipt, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if err != nil || b.options.ReceiptDelay <= 0 {
+		return receipt, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seen, ok := b.receipts[txHash]
+	if !ok {
+		seen = b.now()
+		b.receipts[txHash] = seen
+	}
+	if b.now().Sub(seen) < b.options.ReceiptDelay {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (b *backend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if err := b.fail("TransactionByHash"); err != nil {
+		return nil, false, err
+	}
+	return b.Backend.TransactionByHash(ctx, hash)
+}
+
+func (b *backend) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := b.fail("BlockNumber"); err != nil {
+		return 0, err
+	}
+	return b.Backend.BlockNumber(ctx)
+}
+
+func (b *backend) BalanceAt(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error) {
+	if err := b.fail("BalanceAt"); err != nil {
+		return nil, err
+	}
+	return b.Backend.BalanceAt(ctx, address, block)
+}
+
+func (b *backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if err := b.fail("NonceAt"); err != nil {
+		return 0, err
+	}
+	return b.Backend.NonceAt(ctx, account, blockNumber)
+}
+
+func (b *backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := b.fail("FilterLogs"); err != nil {
+		return nil, err
+	}
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+func (b *backend) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := b.fail("ChainID"); err != nil {
+		return nil, err
+	}
+	return b.Backend.ChainID(ctx)
+}
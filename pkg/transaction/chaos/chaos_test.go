@@ -0,0 +1,226 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendfake"
+	"github.com/ethersphere/bee/pkg/transaction/chaos"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
+)
+
+func newSigner(t *testing.T) (crypto.Signer, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, address
+}
+
+func signTx(t *testing.T, signer crypto.Signer, nonce uint64) *types.Transaction {
+	t.Helper()
+
+	to := common.HexToAddress("0xabcd")
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(backendfake.DefaultChainID),
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Gas:       backendfake.DefaultGasEstimate,
+		GasFeeCap: big.NewInt(2),
+		GasTipCap: big.NewInt(1),
+	}), big.NewInt(backendfake.DefaultChainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	o, err := chaos.ParseOptions("drop=0.1, receipt-delay=30s,duplicate-nonce=0.05,error=1,seed=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := chaos.Options{DropRate: 0.1, ReceiptDelay: 30 * time.Second, DuplicateNonceRate: 0.05, ErrorRate: 1, Seed: 7}
+	if o != want {
+		t.Fatalf("got options %+v, want %+v", o, want)
+	}
+	if !o.Active() {
+		t.Fatal("expected options to be active")
+	}
+
+	o, err = chaos.ParseOptions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Active() {
+		t.Fatal("expected empty options to be inactive")
+	}
+
+	for _, s := range []string{"drop", "drop=2", "error=x", "unknown=1", "receipt-delay=soon"} {
+		if _, err := chaos.ParseOptions(s); err == nil {
+			t.Fatalf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	ctx := context.Background()
+
+	t.Run("drop", func(t *testing.T) {
+		t.Parallel()
+
+		fake := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+		backend := chaos.NewBackend(log.Noop, fake, chaos.Options{DropRate: 1})
+
+		if err := backend.SendTransaction(ctx, signTx(t, signer, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if pending := fake.Pending(); len(pending) != 0 {
+			t.Fatalf("got pending transactions %v, want none", pending)
+		}
+	})
+
+	t.Run("receipt delay", func(t *testing.T) {
+		t.Parallel()
+
+		fake := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)), backendfake.WithAutoMine())
+		backend := chaos.NewBackend(log.Noop, fake, chaos.Options{ReceiptDelay: time.Minute})
+		now := time.Unix(1000, 0)
+		chaos.SetNow(backend, func() time.Time { return now })
+
+		tx := signTx(t, signer, 0)
+		if err := backend.SendTransaction(ctx, tx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backend.TransactionReceipt(ctx, tx.Hash()); !errors.Is(err, ethereum.NotFound) {
+			t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+		}
+		now = now.Add(time.Minute)
+		if _, err := backend.TransactionReceipt(ctx, tx.Hash()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("duplicate nonce", func(t *testing.T) {
+		t.Parallel()
+
+		fake := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)))
+		backend := chaos.NewBackend(log.Noop, fake, chaos.Options{DuplicateNonceRate: 1})
+
+		if err := backend.SendTransaction(ctx, signTx(t, signer, 0)); err != nil {
+			t.Fatal(err)
+		}
+		nonce, err := backend.PendingNonceAt(ctx, sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce != 0 {
+			t.Fatalf("got pending nonce %d, want 0", nonce)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		backend := chaos.NewBackend(log.Noop, backendfake.New(), chaos.Options{ErrorRate: 1})
+
+		_, err := backend.BlockNumber(ctx)
+		if !errors.Is(err, chaos.ErrInjected) {
+			t.Fatalf("got error %v, want %v", err, chaos.ErrInjected)
+		}
+		if !retry.IsTransient(err) {
+			t.Fatal("expected injected error to be transient")
+		}
+	})
+}
+
+// TestRecovery checks that the transaction service gets a transaction mined
+// although every second backend call fails and receipts show up late, as
+// long as they do before the transaction is considered cancelled.
+func TestRecovery(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	fake := backendfake.New(backendfake.WithBalance(sender, big.NewInt(1000000)), backendfake.WithAutoMine())
+	policy := retry.Policy{MaxAttempts: 50, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	backend := retry.NewBackend(
+		chaos.NewBackend(log.Noop, fake, chaos.Options{ErrorRate: 0.5, ReceiptDelay: 50 * time.Millisecond}),
+		retry.Options{Default: policy, Budget: 1000, BudgetRefill: 1},
+	)
+
+	heads := transaction.NewHeadListener(log.Noop, backend, 10*time.Millisecond)
+	monitor := transaction.NewMonitor(log.Noop, backend, sender, heads, 50)
+	service, err := transaction.NewService(log.Noop, backend, signer, mockstore.NewStateStore(), big.NewInt(backendfake.DefaultChainID), monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := service.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := monitor.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := heads.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+	// keep the chain going so that the monitor checks again
+	done := make(chan struct{})
+	defer func() { <-done }()
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+				fake.Mine(1)
+			}
+		}
+	}()
+	defer cancel()
+
+	to := common.HexToAddress("0xabcd")
+	txHash, err := service.Send(ctx, &transaction.TxRequest{To: &to, Value: big.NewInt(100)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := service.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("expected the transaction to succeed")
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !chaos
+
+package chaos
+
+import (
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// Enabled reports whether the node is built with fault injection.
+const Enabled = false
+
+// Wrap returns the backend unchanged as the node is built without the chaos
+// build tag.
+func Wrap(_ log.Logger, b transaction.Backend) (transaction.Backend, error) {
+	return b, nil
+}
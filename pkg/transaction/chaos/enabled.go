@@ -0,0 +1,32 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build chaos
+
+package chaos
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// Enabled reports whether the node is built with fault injection.
+const Enabled = true
+
+// Wrap wraps the backend so that the faults configured in the BEE_CHAOS
+// environment variable are injected.
+func Wrap(logger log.Logger, b transaction.Backend) (transaction.Backend, error) {
+	o, err := ParseOptions(os.Getenv(EnvName))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvName, err)
+	}
+	if !o.Active() {
+		return b, nil
+	}
+	logger.Warning("injecting faults into blockchain backend calls", "drop_rate", o.DropRate, "receipt_delay", o.ReceiptDelay, "duplicate_nonce_rate", o.DuplicateNonceRate, "error_rate", o.ErrorRate)
+	return NewBackend(logger, b, o), nil
+}
@@ -0,0 +1,15 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chaos
+
+import (
+	"time"
+
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+func SetNow(b transaction.Backend, now func() time.Time) {
+	b.(*backend).now = now
+}
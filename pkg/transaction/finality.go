@@ -0,0 +1,208 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// finalityBackend is a Backend which only reports blocks, receipts and mined
+// transactions once they are final.
+type finalityBackend struct {
+	Backend
+	confirmations uint64
+}
+
+// NewFinalityBackend wraps backend so that it presents the chain as it is
+// final after confirmations blocks: the block number is the newest final block,
+// receipts of transactions in later blocks are not found and such transactions
+// are reported as pending. On chains with instant finality confirmations is 0
+// and backend is returned as is.
+func NewFinalityBackend(backend Backend, confirmations uint64) Backend {
+	if confirmations == 0 {
+		return backend
+	}
+	return &finalityBackend{
+		Backend:       backend,
+		confirmations: confirmations,
+	}
+}
+
+// BlockNumber returns the number of the newest final block.
+func (b *finalityBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	head, err := b.Backend.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if head < b.confirmations {
+		return 0, nil
+	}
+	return head - b.confirmations, nil
+}
+
+// TransactionReceipt returns the receipt of the transaction if its block is final.
+func (b *finalityBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	final, err := b.final(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	if !final {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// TransactionByHash reports mined transactions as pending until their block is final.
+func (b *finalityBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, isPending, err := b.Backend.TransactionByHash(ctx, hash)
+	if err != nil || isPending {
+		return tx, isPending, err
+	}
+	receipt, err := b.Backend.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return tx, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	final, err := b.final(ctx, receipt)
+	if err != nil {
+		return nil, false, err
+	}
+	return tx, !final, nil
+}
+
+func (b *finalityBackend) final(ctx context.Context, receipt *types.Receipt) (bool, error) {
+	head, err := b.Backend.BlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+	return head >= blockNumber(receipt)+b.confirmations, nil
+}
+
+// Confirmations is the progress of a mined transaction towards finality.
+type Confirmations struct {
+	BlockNumber   uint64 // block the transaction was included in
+	Confirmations uint64 // blocks on top of the block of the transaction
+	Required      uint64 // confirmations after which the transaction is final
+}
+
+// ConfirmationsFunc is called whenever the confirmations of a transaction
+// waited for change.
+type ConfirmationsFunc func(Confirmations)
+
+type confirmationsFuncKey struct{}
+
+// WithConfirmationsFunc returns a context with which WaitForReceipt of a
+// finality service reports the confirmations of the transaction to f while
+// waiting for it to become final.
+func WithConfirmationsFunc(ctx context.Context, f ConfirmationsFunc) context.Context {
+	return context.WithValue(ctx, confirmationsFuncKey{}, f)
+}
+
+func confirmationsFuncFrom(ctx context.Context) ConfirmationsFunc {
+	f, _ := ctx.Value(confirmationsFuncKey{}).(ConfirmationsFunc)
+	return f
+}
+
+// finalityService is a Service whose WaitForReceipt waits until the
+// transaction is final.
+type finalityService struct {
+	Service
+	backend         Backend
+	confirmations   uint64
+	pollingInterval time.Duration
+}
+
+// NewFinalityService wraps service so that WaitForReceipt only returns once
+// the block of the transaction has confirmations blocks on top of it. If the
+// transaction is moved to another block by a reorg meanwhile, the new block is
+// waited for. On chains with instant finality confirmations is 0 and service
+// is returned as is.
+func NewFinalityService(service Service, backend Backend, confirmations uint64, pollingInterval time.Duration) Service {
+	if confirmations == 0 {
+		return service
+	}
+	return &finalityService{
+		Service:         service,
+		backend:         backend,
+		confirmations:   confirmations,
+		pollingInterval: pollingInterval,
+	}
+}
+
+// WaitForReceipt waits for the receipt of the transaction and then until the
+// transaction is final.
+func (s *finalityService) WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := s.Service.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := confirmationsFuncFrom(ctx)
+	var reported *Confirmations
+	for {
+		head, err := s.backend.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil && head >= blockNumber(receipt) {
+			c := Confirmations{
+				BlockNumber:   blockNumber(receipt),
+				Confirmations: head - blockNumber(receipt),
+				Required:      s.confirmations,
+			}
+			if c.Confirmations > c.Required {
+				c.Confirmations = c.Required
+			}
+			if reported == nil || *reported != c {
+				reported = &c
+				progress(c)
+			}
+		}
+		if head >= blockNumber(receipt)+s.confirmations {
+			// the receipt is looked up again in case a reorg moved the transaction
+			current, err := s.backend.TransactionReceipt(ctx, txHash)
+			switch {
+			case errors.Is(err, ethereum.NotFound):
+				if receipt, err = s.Service.WaitForReceipt(ctx, txHash); err != nil {
+					return nil, err
+				}
+				continue
+			case err != nil:
+				return nil, err
+			case current.BlockHash == receipt.BlockHash:
+				return current, nil
+			default:
+				receipt = current
+				continue
+			}
+		}
+
+		select {
+		case <-time.After(s.pollingInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// blockNumber returns the block number of the receipt or 0 if it is unknown.
+func blockNumber(receipt *types.Receipt) uint64 {
+	if receipt.BlockNumber == nil {
+		return 0
+	}
+	return receipt.BlockNumber.Uint64()
+}
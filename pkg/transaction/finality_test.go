@@ -0,0 +1,168 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+func TestFinalityBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	finalHash := common.HexToHash("0x01")
+	recentHash := common.HexToHash("0x02")
+	receipts := map[common.Hash]*types.Receipt{
+		finalHash:  {BlockNumber: big.NewInt(90)},
+		recentHash: {BlockNumber: big.NewInt(95)},
+	}
+
+	backend := transaction.NewFinalityBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+			return 100, nil
+		}),
+		backendmock.WithTransactionReceiptFunc(func(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+			return receipts[txHash], nil
+		}),
+		backendmock.WithTransactionByHashFunc(func(context.Context, common.Hash) (*types.Transaction, bool, error) {
+			return &types.Transaction{}, false, nil
+		}),
+	), 10)
+
+	head, err := backend.BlockNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 90 {
+		t.Fatalf("got block number %d, want %d", head, 90)
+	}
+
+	if _, err := backend.TransactionReceipt(ctx, finalHash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.TransactionReceipt(ctx, recentHash); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+	}
+
+	for txHash, want := range map[common.Hash]bool{finalHash: false, recentHash: true} {
+		_, pending, err := backend.TransactionByHash(ctx, txHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pending != want {
+			t.Fatalf("transaction %s: got pending %v, want %v", txHash, pending, want)
+		}
+	}
+}
+
+func TestFinalityService(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0xabcd")
+	mined := &types.Receipt{BlockNumber: big.NewInt(95), BlockHash: common.HexToHash("0x95")}
+	// a reorg moves the transaction to a later block before it is final
+	moved := &types.Receipt{BlockNumber: big.NewInt(97), BlockHash: common.HexToHash("0x97")}
+
+	var head atomic.Uint64
+	head.Store(95)
+	service := transaction.NewFinalityService(
+		transactionmock.New(
+			transactionmock.WithWaitForReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return mined, nil
+			}),
+		),
+		backendmock.New(
+			backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+				// every check sees a new block
+				return head.Add(1), nil
+			}),
+			backendmock.WithTransactionReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return moved, nil
+			}),
+		),
+		10,
+		time.Millisecond,
+	)
+
+	receipt, err := service.WaitForReceipt(context.Background(), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.BlockHash != moved.BlockHash {
+		t.Fatalf("got receipt of block %s, want %s", receipt.BlockHash, moved.BlockHash)
+	}
+	if h := head.Load(); h < 107 {
+		t.Fatalf("receipt returned at block %d, before it was final", h)
+	}
+}
+
+func TestFinalityServiceConfirmations(t *testing.T) {
+	t.Parallel()
+
+	mined := &types.Receipt{BlockNumber: big.NewInt(95), BlockHash: common.HexToHash("0x95")}
+
+	var head atomic.Uint64
+	head.Store(94)
+	service := transaction.NewFinalityService(
+		transactionmock.New(
+			transactionmock.WithWaitForReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return mined, nil
+			}),
+		),
+		backendmock.New(
+			backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+				return head.Add(1), nil
+			}),
+			backendmock.WithTransactionReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return mined, nil
+			}),
+		),
+		2,
+		time.Millisecond,
+	)
+
+	var got []transaction.Confirmations
+	ctx := transaction.WithConfirmationsFunc(context.Background(), func(c transaction.Confirmations) {
+		got = append(got, c)
+	})
+	if _, err := service.WaitForReceipt(ctx, common.HexToHash("0xabcd")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []transaction.Confirmations{
+		{BlockNumber: 95, Confirmations: 0, Required: 2},
+		{BlockNumber: 95, Confirmations: 1, Required: 2},
+		{BlockNumber: 95, Confirmations: 2, Required: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got confirmations %+v, want %+v", got, want)
+	}
+}
+
+func TestFinalityInstant(t *testing.T) {
+	t.Parallel()
+
+	backend := backendmock.New()
+	if transaction.NewFinalityBackend(backend, 0) != backend {
+		t.Fatal("backend wrapped on chain with instant finality")
+	}
+	service := transactionmock.New()
+	if transaction.NewFinalityService(service, backend, 0, time.Second) != service {
+		t.Fatal("service wrapped on chain with instant finality")
+	}
+}
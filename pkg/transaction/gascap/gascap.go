@@ -0,0 +1,276 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gascap holds back transactions while the network gas price is above
+// the maximum configured for their type of operation.
+package gascap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "gascap"
+
+const (
+	// DefaultExpiry is the default time an operation waits for the gas price to fall.
+	DefaultExpiry = time.Hour
+	// DefaultRetryInterval is the default interval in which the gas price is checked again.
+	DefaultRetryInterval = time.Minute
+)
+
+var (
+	// ErrGasPriceTooHigh is the error returned if the gas price stayed above
+	// the cap of the operation until it expired.
+	ErrGasPriceTooHigh = errors.New("gas price above cap")
+	// ErrClosed is the error returned for queued operations when the service is closed.
+	ErrClosed = errors.New("gas price cap service closed")
+)
+
+// ParseCaps parses caps given as operation=maximum gas price in wei.
+func ParseCaps(caps []string) (map[string]*big.Int, error) {
+	result := make(map[string]*big.Int, len(caps))
+	for _, c := range caps {
+		operation, value, ok := strings.Cut(c, "=")
+		if !ok || operation == "" {
+			return nil, fmt.Errorf("invalid gas price cap %q, expected operation=wei", c)
+		}
+		price, ok := new(big.Int).SetString(value, 10)
+		if !ok || price.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid gas price cap %q, expected operation=wei", c)
+		}
+		result[operation] = price
+	}
+	return result, nil
+}
+
+// FormatCaps formats caps as operation=maximum gas price in wei, ordered by
+// operation, so that ParseCaps parses them again.
+func FormatCaps(caps map[string]*big.Int) []string {
+	result := make([]string, 0, len(caps))
+	for operation, price := range caps {
+		result = append(result, operation+"="+price.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Options configures the Service.
+type Options struct {
+	Caps          map[string]*big.Int                 // maximum gas price per operation
+	Operation     func(*transaction.TxRequest) string // determines the operation of a request
+	Expiry        time.Duration                       // maximum time an operation is queued
+	RetryInterval time.Duration                       // interval in which the gas price is checked
+}
+
+// QueuedOperation is an operation waiting for the gas price to fall.
+type QueuedOperation struct {
+	Operation   string
+	Description string
+	MaxGasPrice *big.Int
+	GasPrice    *big.Int // gas price at the last check
+	Queued      time.Time
+	Expires     time.Time
+}
+
+var _ transaction.Service = (*Service)(nil)
+
+// Service is a transaction.Service which queues the requests of operations
+// with a gas price cap until the suggested gas price is at most the cap.
+// Requests with an explicit gas price and of operations without a cap are
+// sent immediately.
+type Service struct {
+	transaction.Service
+
+	logger  log.Logger
+	backend transaction.Backend
+	options Options
+	metrics metrics
+	timeNow func() time.Time
+
+	mu     sync.Mutex
+	queue  map[uint64]*QueuedOperation
+	nextID uint64
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a new gas price cap service wrapping transactionService.
+func New(logger log.Logger, transactionService transaction.Service, backend transaction.Backend, o Options) *Service {
+	if o.Expiry <= 0 {
+		o.Expiry = DefaultExpiry
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+	return &Service{
+		Service: transactionService,
+		logger:  logger.WithName(loggerName).Register(),
+		backend: backend,
+		options: o,
+		metrics: newMetrics(),
+		timeNow: time.Now,
+		queue:   make(map[uint64]*QueuedOperation),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Send sends the request once the gas price is at most the cap of its
+// operation. It returns ErrGasPriceTooHigh if that did not happen before the
+// operation expired.
+func (s *Service) Send(ctx context.Context, request *transaction.TxRequest, boostPercent int) (common.Hash, error) {
+	if request.GasPrice != nil || s.options.Operation == nil {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+	operation := s.options.Operation(request)
+	s.mu.Lock()
+	maxGasPrice, ok := s.options.Caps[operation]
+	s.mu.Unlock()
+	if !ok {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+
+	gasPrice, err := s.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if gasPrice.Cmp(maxGasPrice) <= 0 {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+
+	id, queued := s.enqueue(operation, request.Description, maxGasPrice, gasPrice)
+	defer s.dequeue(id)
+
+	s.logger.Info("gas price above cap, operation queued", "operation", operation, "description", request.Description, "gas_price", gasPrice, "max_gas_price", maxGasPrice, "expires", queued.Expires)
+
+	expiry := time.NewTimer(queued.Expires.Sub(queued.Queued))
+	defer expiry.Stop()
+	ticker := time.NewTicker(s.options.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-expiry.C:
+			s.metrics.ExpiredOperations.Inc()
+			s.logger.Warning("gas price stayed above cap, operation expired", "operation", operation, "description", request.Description, "max_gas_price", maxGasPrice)
+			return common.Hash{}, fmt.Errorf("%s: %w", operation, ErrGasPriceTooHigh)
+		case <-ctx.Done():
+			return common.Hash{}, ctx.Err()
+		case <-s.quit:
+			return common.Hash{}, ErrClosed
+		}
+
+		gasPrice, err := s.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			s.logger.Debug("gas price check failed", "operation", operation, "error", err)
+			continue
+		}
+		s.updateGasPrice(id, gasPrice)
+		if gasPrice.Cmp(maxGasPrice) > 0 {
+			continue
+		}
+
+		s.metrics.DelayedOperations.Inc()
+		s.logger.Info("gas price below cap, sending queued operation", "operation", operation, "description", request.Description, "gas_price", gasPrice)
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+}
+
+func (s *Service) enqueue(operation, description string, maxGasPrice, gasPrice *big.Int) (uint64, QueuedOperation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNow()
+	q := &QueuedOperation{
+		Operation:   operation,
+		Description: description,
+		MaxGasPrice: maxGasPrice,
+		GasPrice:    gasPrice,
+		Queued:      now,
+		Expires:     now.Add(s.options.Expiry),
+	}
+	id := s.nextID
+	s.nextID++
+	s.queue[id] = q
+	s.metrics.QueuedOperations.Set(float64(len(s.queue)))
+
+	return id, *q
+}
+
+func (s *Service) updateGasPrice(id uint64, gasPrice *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if q, ok := s.queue[id]; ok {
+		q.GasPrice = gasPrice
+	}
+}
+
+func (s *Service) dequeue(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.queue, id)
+	s.metrics.QueuedOperations.Set(float64(len(s.queue)))
+}
+
+// Caps returns the maximum gas price per operation.
+func (s *Service) Caps() map[string]*big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	caps := make(map[string]*big.Int, len(s.options.Caps))
+	for operation, price := range s.options.Caps {
+		caps[operation] = new(big.Int).Set(price)
+	}
+	return caps
+}
+
+// SetCaps replaces the maximum gas price per operation. Operations already
+// queued keep waiting for the cap they were queued with.
+func (s *Service) SetCaps(caps map[string]*big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.options.Caps = make(map[string]*big.Int, len(caps))
+	for operation, price := range caps {
+		s.options.Caps[operation] = new(big.Int).Set(price)
+	}
+}
+
+// Queued returns the operations waiting for the gas price to fall, oldest first.
+func (s *Service) Queued() []QueuedOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := make([]QueuedOperation, 0, len(s.queue))
+	for _, q := range s.queue {
+		queued = append(queued, *q)
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].Queued.Before(queued[j].Queued)
+	})
+	return queued
+}
+
+// Close fails all queued operations. It does not close the wrapped service.
+func (s *Service) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gascap_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/transaction/gascap"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+)
+
+var txHash = common.HexToHash("0xabcd")
+
+func newService(t *testing.T, gasPrices func() int64, o gascap.Options) (*gascap.Service, *atomic.Int32) {
+	t.Helper()
+
+	var sent atomic.Int32
+	s := gascap.New(
+		log.Noop,
+		transactionmock.New(
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sent.Add(1)
+				return txHash, nil
+			}),
+		),
+		backendmock.New(
+			backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(gasPrices()), nil
+			}),
+		),
+		o,
+	)
+	t.Cleanup(func() { _ = s.Close() })
+	return s, &sent
+}
+
+func operation(request *transaction.TxRequest) string {
+	return request.Description
+}
+
+func TestSendBelowCap(t *testing.T) {
+	t.Parallel()
+
+	s, sent := newService(t, func() int64 { return 10 }, gascap.Options{
+		Caps:      map[string]*big.Int{"cashout": big.NewInt(10)},
+		Operation: operation,
+	})
+
+	hash, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != txHash || sent.Load() != 1 {
+		t.Fatal("transaction not sent")
+	}
+}
+
+func TestSendUncapped(t *testing.T) {
+	t.Parallel()
+
+	s, sent := newService(t, func() int64 { return 1000 }, gascap.Options{
+		Caps:      map[string]*big.Int{"cashout": big.NewInt(10)},
+		Operation: operation,
+	})
+
+	// other operations are not capped
+	if _, err := s.Send(context.Background(), &transaction.TxRequest{Description: "deposit"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// explicitly set gas prices are not capped
+	if _, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout", GasPrice: big.NewInt(1000)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Load() != 2 {
+		t.Fatalf("sent %d transactions, want 2", sent.Load())
+	}
+}
+
+func TestSendQueued(t *testing.T) {
+	t.Parallel()
+
+	var gasPrice atomic.Int64
+	gasPrice.Store(100)
+
+	s, sent := newService(t, gasPrice.Load, gascap.Options{
+		Caps:          map[string]*big.Int{"cashout": big.NewInt(10)},
+		Operation:     operation,
+		RetryInterval: time.Millisecond,
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+		errC <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.Queued()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("operation not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	queued := s.Queued()[0]
+	if queued.Operation != "cashout" || queued.MaxGasPrice.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("unexpected queued operation %+v", queued)
+	}
+	if sent.Load() != 0 {
+		t.Fatal("sent above cap")
+	}
+
+	gasPrice.Store(5)
+
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued operation not sent")
+	}
+	if sent.Load() != 1 {
+		t.Fatal("transaction not sent")
+	}
+	if len(s.Queued()) != 0 {
+		t.Fatal("operation still queued")
+	}
+}
+
+func TestSendExpired(t *testing.T) {
+	t.Parallel()
+
+	s, sent := newService(t, func() int64 { return 100 }, gascap.Options{
+		Caps:          map[string]*big.Int{"cashout": big.NewInt(10)},
+		Operation:     operation,
+		Expiry:        20 * time.Millisecond,
+		RetryInterval: time.Millisecond,
+	})
+
+	_, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+	if !errors.Is(err, gascap.ErrGasPriceTooHigh) {
+		t.Fatalf("got error %v, want %v", err, gascap.ErrGasPriceTooHigh)
+	}
+	if sent.Load() != 0 {
+		t.Fatal("sent above cap")
+	}
+}
+
+func TestSendClosed(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t, func() int64 { return 100 }, gascap.Options{
+		Caps:      map[string]*big.Int{"cashout": big.NewInt(10)},
+		Operation: operation,
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+		errC <- err
+	}()
+
+	for len(s.Queued()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errC; !errors.Is(err, gascap.ErrClosed) {
+		t.Fatalf("got error %v, want %v", err, gascap.ErrClosed)
+	}
+}
+
+func TestParseCaps(t *testing.T) {
+	t.Parallel()
+
+	caps, err := gascap.ParseCaps([]string{"cashout=10", "deposit=20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caps) != 2 || caps["cashout"].Int64() != 10 || caps["deposit"].Int64() != 20 {
+		t.Fatalf("unexpected caps %v", caps)
+	}
+
+	for _, invalid := range []string{"cashout", "=10", "cashout=", "cashout=-1", "cashout=x"} {
+		if _, err := gascap.ParseCaps([]string{invalid}); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
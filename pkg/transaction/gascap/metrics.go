@@ -0,0 +1,45 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gascap
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	QueuedOperations  prometheus.Gauge
+	DelayedOperations prometheus.Counter
+	ExpiredOperations prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "gas_price_cap"
+
+	return metrics{
+		QueuedOperations: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "queued_operations",
+			Help:      "Number of operations waiting for the gas price to fall below their cap",
+		}),
+		DelayedOperations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "delayed_operations",
+			Help:      "Number of operations which were sent after waiting for the gas price to fall",
+		}),
+		ExpiredOperations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "expired_operations",
+			Help:      "Number of operations given up because the gas price stayed above their cap",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+)
+
+// Head is a chain head observed by the HeadListener.
+type Head struct {
+	Number uint64
+	// Reorg is set if the head is lower than a previously observed head,
+	// meaning that previously seen blocks were reorged out of the chain.
+	Reorg bool
+}
+
+// HeadListener follows the head of the chain and notifies its subscribers
+// about new heads. It allows components which need to act on new blocks to
+// share a single source instead of each polling the backend independently.
+type HeadListener interface {
+	io.Closer
+	// Subscribe returns a channel on which new heads are delivered and a
+	// function to cancel the subscription. Only the latest head is kept for
+	// slow subscribers; the reorg flag is preserved if an intermediate head is
+	// skipped.
+	Subscribe() (<-chan Head, func())
+	// Latest queries the backend for the current head immediately and
+	// notifies the subscribers if it changed.
+	Latest(ctx context.Context) (Head, error)
+}
+
+type headListener struct {
+	ctx        context.Context    // context which is used for all backend calls
+	cancelFunc context.CancelFunc // function to cancel the above context
+	wg         sync.WaitGroup
+
+	logger          log.Logger
+	backend         Backend
+	pollingInterval time.Duration // time between checking for new heads
+
+	lock        sync.Mutex
+	head        Head
+	hasHead     bool
+	subscribers map[chan Head]struct{}
+}
+
+// NewHeadListener creates a new HeadListener which polls the backend for new
+// heads every pollingInterval while there is at least one subscriber.
+func NewHeadListener(logger log.Logger, backend Backend, pollingInterval time.Duration) HeadListener {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	l := &headListener{
+		ctx:             ctx,
+		cancelFunc:      cancelFunc,
+		logger:          logger.WithName(loggerName).Register(),
+		backend:         backend,
+		pollingInterval: pollingInterval,
+		subscribers:     make(map[chan Head]struct{}),
+	}
+
+	l.wg.Add(1)
+	go l.poll()
+
+	return l
+}
+
+func (l *headListener) Subscribe() (<-chan Head, func()) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	c := make(chan Head, 1)
+	l.subscribers[c] = struct{}{}
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			delete(l.subscribers, c)
+		})
+	}
+}
+
+func (l *headListener) Latest(ctx context.Context) (Head, error) {
+	// switch to new head subscriptions once websockets are the norm
+	number, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		return Head{}, err
+	}
+	return l.update(number), nil
+}
+
+// update records the given block number as the current head and notifies the
+// subscribers if it differs from the previous one.
+func (l *headListener) update(number uint64) Head {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.hasHead && l.head.Number == number {
+		return l.head
+	}
+
+	head := Head{
+		Number: number,
+		Reorg:  l.hasHead && number < l.head.Number,
+	}
+	l.head = head
+	l.hasHead = true
+
+	for c := range l.subscribers {
+		select {
+		case c <- head:
+		default:
+			// the subscriber did not yet consume the previous head, replace it
+			next := head
+			select {
+			case prev := <-c:
+				next.Reorg = next.Reorg || prev.Reorg
+			default:
+			}
+			c <- next
+		}
+	}
+
+	return head
+}
+
+func (l *headListener) hasSubscribers() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.subscribers) > 0
+}
+
+// main polling loop
+func (l *headListener) poll() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-time.After(l.pollingInterval):
+		case <-l.ctx.Done():
+			return
+		}
+
+		// if nobody is listening there is no point in querying the backend
+		if !l.hasSubscribers() {
+			continue
+		}
+
+		if _, err := l.Latest(l.ctx); err != nil && l.ctx.Err() == nil {
+			l.logger.Error(err, "could not get block number")
+		}
+	}
+}
+
+func (l *headListener) Close() error {
+	l.cancelFunc()
+	l.wg.Wait()
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+)
+
+func TestHeadListener(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		number uint64 = 10
+	)
+	setNumber := func(n uint64) {
+		mu.Lock()
+		defer mu.Unlock()
+		number = n
+	}
+
+	heads := transaction.NewHeadListener(log.Noop, backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return number, nil
+		}),
+	), time.Millisecond)
+	defer heads.Close()
+
+	c1, unsubscribe1 := heads.Subscribe()
+	defer unsubscribe1()
+	c2, unsubscribe2 := heads.Subscribe()
+	defer unsubscribe2()
+
+	expectHead := func(t *testing.T, c <-chan transaction.Head, want transaction.Head) {
+		t.Helper()
+		select {
+		case head := <-c:
+			if head != want {
+				t.Fatalf("got head %+v, want %+v", head, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for head")
+		}
+	}
+
+	// both subscribers are served by the same listener
+	expectHead(t, c1, transaction.Head{Number: 10})
+	expectHead(t, c2, transaction.Head{Number: 10})
+
+	setNumber(12)
+	expectHead(t, c1, transaction.Head{Number: 12})
+
+	// a lower head is a reorg, the flag is kept even if the head is replaced before it is consumed
+	setNumber(11)
+	expectHead(t, c1, transaction.Head{Number: 11, Reorg: true})
+	setNumber(13)
+	expectHead(t, c1, transaction.Head{Number: 13})
+	expectHead(t, c2, transaction.Head{Number: 13, Reorg: true})
+
+	head, err := heads.Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Number != 13 {
+		t.Fatalf("got latest head %d, want %d", head.Number, 13)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+	"golang.org/x/net/context"
+)
+
+const intentPrefix = "transaction_intent_"
+
+// IntentRetention is the time after the transaction of an operation sent
+// before a restart was mined during which a request for the same operation is
+// considered a retry of it. The caller of Send may have stopped before it
+// recorded the transaction, so that it retries the operation after the
+// restart.
+const IntentRetention = 10 * time.Minute
+
+// intent is stored before a transaction is broadcast, so that the transaction
+// of an operation is known even if the node stops before it was recorded.
+// Requests with the same description, recipient, value and data are the same
+// operation. While its transaction is pending, a request for the operation
+// returns it instead of sending another one.
+type intent struct {
+	Nonce       uint64
+	Description string
+	DataHash    common.Hash
+	TxHash      common.Hash
+	RawTx       []byte // signed transaction to broadcast again if it was dropped
+	Created     int64
+	Restarted   bool  // whether the node restarted while the transaction was pending
+	Mined       int64 // unix timestamp the transaction was seen mined, zero while pending
+}
+
+func intentKey(id common.Hash) string {
+	return fmt.Sprintf("%s%x", intentPrefix, id)
+}
+
+// intentID identifies the operation of the request.
+func intentID(request *TxRequest) (common.Hash, error) {
+	var to []byte
+	if request.To != nil {
+		to = request.To.Bytes()
+	}
+	var value []byte
+	if request.Value != nil {
+		value = request.Value.Bytes()
+	}
+	var data []byte
+	for _, field := range [][]byte{[]byte(request.Description), to, value, request.Data} {
+		data = binary.BigEndian.AppendUint64(data, uint64(len(field)))
+		data = append(data, field...)
+	}
+	h, err := crypto.LegacyKeccak256(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(h), nil
+}
+
+// newIntent creates the intent of the signed transaction.
+func newIntent(request *TxRequest, signedTx *types.Transaction) (intent, error) {
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return intent{}, err
+	}
+	dataHash, err := crypto.LegacyKeccak256(request.Data)
+	if err != nil {
+		return intent{}, err
+	}
+	return intent{
+		Nonce:       signedTx.Nonce(),
+		Description: request.Description,
+		DataHash:    common.BytesToHash(dataHash),
+		TxHash:      signedTx.Hash(),
+		RawTx:       raw,
+		Created:     time.Now().Unix(),
+	}, nil
+}
+
+// resumeIntent returns the transaction already sent for the operation, if
+// any. A transaction which was dropped is broadcast again unless its nonce was
+// used by another transaction in the meantime. Must be called with the lock
+// held.
+func (t *transactionService) resumeIntent(ctx context.Context, id common.Hash) (common.Hash, bool, error) {
+	var in intent
+	err := t.store.Get(intentKey(id), &in)
+	if errors.Is(err, storage.ErrNotFound) {
+		return common.Hash{}, false, nil
+	}
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	if in.Mined != 0 {
+		if time.Since(time.Unix(in.Mined, 0)) < IntentRetention {
+			return in.TxHash, true, nil
+		}
+		return common.Hash{}, false, t.store.Delete(intentKey(id))
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(in.RawTx); err != nil {
+		return common.Hash{}, false, fmt.Errorf("decode transaction of intent %x: %w", id, err)
+	}
+
+	_, _, err = t.backend.TransactionByHash(ctx, in.TxHash)
+	if err == nil {
+		return in.TxHash, true, t.recoverIntent(id, in, tx, true)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return common.Hash{}, false, err
+	}
+
+	confirmedNonce, err := t.backend.NonceAt(ctx, t.sender, nil)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	if in.Nonce < confirmedNonce {
+		// the transaction can never be mined anymore
+		t.logger.Warning("transaction of intent replaced by another transaction", "tx", in.TxHash, "nonce", in.Nonce)
+		return common.Hash{}, false, t.store.Delete(intentKey(id))
+	}
+
+	t.logger.Info("broadcasting dropped transaction again", "tx", in.TxHash, "nonce", in.Nonce)
+	if err := t.backend.SendTransaction(ctx, tx); err != nil && !strings.Contains(err.Error(), "already known") {
+		return common.Hash{}, false, t.gasFundsError(err)
+	}
+	return in.TxHash, true, t.recoverIntent(id, in, tx, true)
+}
+
+// recoverIntent records the transaction of the intent if the node stopped
+// after it was broadcast but before it was recorded, and waits for it if
+// requested. Must be called with the lock held.
+func (t *transactionService) recoverIntent(id common.Hash, in intent, tx *types.Transaction, wait bool) error {
+	_, err := t.StoredTransaction(in.TxHash)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrUnknownTransaction) {
+		return err
+	}
+
+	var nonce uint64
+	err = t.store.Get(t.nonceKey(), &nonce)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	if nonce <= in.Nonce {
+		if err := t.putNonce(in.Nonce + 1); err != nil {
+			return err
+		}
+	}
+
+	t.logger.Info("recovered transaction broadcast before a restart", "tx", in.TxHash, "description", in.Description)
+	if err := t.recordTransaction(tx, 0, in.Description, id, in.Created); err != nil {
+		return err
+	}
+	if wait {
+		t.waitForPendingTx(in.TxHash)
+	}
+	return nil
+}
+
+// recoverIntents records the transactions broadcast but not recorded before
+// the node stopped, and removes the intents which expired. Dropped
+// transactions are only broadcast again if their operation is retried. It
+// must be called before the pending transactions are waited for.
+func (t *transactionService) recoverIntents() error {
+	intents := make(map[common.Hash]intent)
+	err := t.store.Iterate(intentPrefix, func(key, value []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), intentPrefix) {
+			return true, nil
+		}
+		var in intent
+		if err := json.Unmarshal(value, &in); err != nil {
+			return true, err
+		}
+		intents[common.HexToHash(strings.TrimPrefix(string(key), intentPrefix))] = in
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for id, in := range intents {
+		if in.Mined != 0 {
+			if time.Since(time.Unix(in.Mined, 0)) >= IntentRetention {
+				if err := t.store.Delete(intentKey(id)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !in.Restarted {
+			in.Restarted = true
+			if err := t.store.Put(intentKey(id), in); err != nil {
+				return err
+			}
+		}
+
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(in.RawTx); err != nil {
+			return fmt.Errorf("decode transaction of intent %x: %w", id, err)
+		}
+		_, _, err := t.backend.TransactionByHash(t.ctx, in.TxHash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := t.recoverIntent(id, in, tx, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeIntent removes the intent of the mined or cancelled transaction, so
+// that the operation can be sent again. The intent of a transaction sent
+// before a restart is kept for IntentRetention after it was mined.
+func (t *transactionService) closeIntent(txHash common.Hash, cancelled bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stored, err := t.StoredTransaction(txHash)
+	if err != nil || stored.Intent == (common.Hash{}) {
+		return
+	}
+
+	var in intent
+	if err := t.store.Get(intentKey(stored.Intent), &in); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.logger.Error(err, "error while loading transaction intent", "tx", txHash)
+		}
+		return
+	}
+	if in.TxHash != txHash {
+		return
+	}
+
+	if cancelled || !in.Restarted {
+		err = t.store.Delete(intentKey(stored.Intent))
+	} else {
+		in.Mined = time.Now().Unix()
+		err = t.store.Put(intentKey(stored.Intent), in)
+	}
+	if err != nil {
+		t.logger.Error(err, "error while closing transaction intent", "tx", txHash)
+	}
+}
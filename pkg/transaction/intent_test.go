@@ -0,0 +1,203 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	signermock "github.com/ethersphere/bee/pkg/crypto/mock"
+	"github.com/ethersphere/bee/pkg/log"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/transaction/monitormock"
+)
+
+var errCrash = errors.New("crash")
+
+// crashingStore fails to record sent transactions, like a node stopping
+// right after a transaction was broadcast.
+type crashingStore struct {
+	storage.StateStorer
+	crash bool
+}
+
+func (s *crashingStore) Put(key string, i interface{}) error {
+	if s.crash && strings.HasPrefix(key, "transaction_stored_") {
+		return errCrash
+	}
+	return s.StateStorer.Put(key, i)
+}
+
+func TestTransactionSendAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xabcd")
+	chainID := big.NewInt(5)
+	request := &transaction.TxRequest{
+		To:          &recipient,
+		Data:        common.Hex2Bytes("abcdee"),
+		Value:       big.NewInt(1),
+		GasLimit:    21000,
+		Description: "deposit",
+	}
+
+	// chain is the view of the backend on the broadcast transactions
+	type chain struct {
+		known          map[common.Hash]bool
+		sent           []common.Hash
+		confirmedNonce uint64
+		pendingNonce   uint64
+	}
+
+	newService := func(t *testing.T, store storage.StateStorer, c *chain) transaction.Service {
+		t.Helper()
+
+		service, err := transaction.NewService(log.Noop,
+			backendmock.New(
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					c.known[tx.Hash()] = true
+					c.sent = append(c.sent, tx.Hash())
+					c.pendingNonce = tx.Nonce() + 1
+					return nil
+				}),
+				backendmock.WithTransactionByHashFunc(func(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+					if !c.known[hash] {
+						return nil, false, ethereum.NotFound
+					}
+					return nil, true, nil
+				}),
+				backendmock.WithNonceAtFunc(func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+					return c.confirmedNonce, nil
+				}),
+				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+					return c.pendingNonce, nil
+				}),
+				backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+					return big.NewInt(1000), nil
+				}),
+				backendmock.WithSuggestGasTipCapFunc(func(ctx context.Context) (*big.Int, error) {
+					return big.NewInt(100), nil
+				}),
+			),
+			signermock.New(
+				signermock.WithSignTxFunc(func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+					return tx, nil
+				}),
+				signermock.WithEthereumAddressFunc(func() (common.Address, error) {
+					return sender, nil
+				}),
+			),
+			store,
+			chainID,
+			monitormock.New(
+				monitormock.WithWatchTransactionFunc(func(txHash common.Hash, nonce uint64) (<-chan types.Receipt, <-chan error, error) {
+					return nil, nil, nil
+				}),
+			),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return service
+	}
+
+	// sendAndCrash sends the request with a node stopping before the
+	// transaction was recorded.
+	sendAndCrash := func(t *testing.T, c *chain) storage.StateStorer {
+		t.Helper()
+
+		store := &crashingStore{StateStorer: storemock.NewStateStore(), crash: true}
+		service := newService(t, store, c)
+		if _, err := service.Send(context.Background(), request, 0); !errors.Is(err, errCrash) {
+			t.Fatalf("got error %v, want %v", err, errCrash)
+		}
+		if err := service.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.sent) != 1 {
+			t.Fatalf("got %d transactions broadcast, want 1", len(c.sent))
+		}
+		return store.StateStorer
+	}
+
+	t.Run("broadcast transaction is recovered", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{known: make(map[common.Hash]bool)}
+		store := sendAndCrash(t, c)
+		txHash := c.sent[0]
+
+		service := newService(t, store, c)
+		defer service.Close()
+
+		stored, err := service.StoredTransaction(txHash)
+		if err != nil {
+			t.Fatalf("transaction not recovered: %v", err)
+		}
+		if stored.Description != request.Description {
+			t.Fatalf("got description %q, want %q", stored.Description, request.Description)
+		}
+
+		got, err := service.Send(context.Background(), request, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != txHash || len(c.sent) != 1 {
+			t.Fatalf("retry sent %d transactions and returned %s, want the recovered %s", len(c.sent)-1, got, txHash)
+		}
+	})
+
+	t.Run("dropped transaction is broadcast again", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{known: make(map[common.Hash]bool)}
+		store := sendAndCrash(t, c)
+		txHash := c.sent[0]
+		c.known = make(map[common.Hash]bool)
+		c.pendingNonce = 0
+
+		service := newService(t, store, c)
+		defer service.Close()
+
+		got, err := service.Send(context.Background(), request, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != txHash || len(c.sent) != 2 || c.sent[1] != txHash {
+			t.Fatalf("got %s and broadcasts %v, want %s broadcast again", got, c.sent, txHash)
+		}
+	})
+
+	t.Run("replaced transaction is sent again", func(t *testing.T) {
+		t.Parallel()
+
+		c := &chain{known: make(map[common.Hash]bool)}
+		store := sendAndCrash(t, c)
+		txHash := c.sent[0]
+		c.known = make(map[common.Hash]bool)
+		c.confirmedNonce = 1
+
+		service := newService(t, store, c)
+		defer service.Close()
+
+		got, err := service.Send(context.Background(), request, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == txHash || len(c.sent) != 2 {
+			t.Fatalf("got %s and broadcasts %v, want a new transaction", got, c.sent)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	InsufficientGasFunds prometheus.Counter
+}
+
+// newMetrics creates the metrics of the transaction service labeled with the
+// symbol of the token gas is paid in.
+func newMetrics(nativeToken string) metrics {
+	subsystem := "transaction"
+
+	return metrics{
+		InsufficientGasFunds: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   m.Namespace,
+			Subsystem:   subsystem,
+			Name:        "insufficient_gas_funds",
+			Help:        "Number of transactions not sent because the wallet balance of the native token was too low to pay for the gas.",
+			ConstLabels: prometheus.Labels{"native_token": nativeToken},
+		}),
+	}
+}
+
+func (t *transactionService) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(t.metrics)
+}
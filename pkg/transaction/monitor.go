@@ -10,7 +10,6 @@ import (
 	"io"
 	"math/big"
 	"sync"
-	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -39,9 +38,9 @@ type transactionMonitor struct {
 	logger  log.Logger
 	backend Backend
 	sender  common.Address // sender of transactions which this instance can monitor
+	heads   HeadListener   // source of new blocks, shared with other components
 
-	pollingInterval   time.Duration // time between checking for new blocks
-	cancellationDepth uint64        // number of blocks until considering a tx cancellation final
+	cancellationDepth uint64 // number of blocks until considering a tx cancellation final
 
 	watchesByNonce map[uint64]map[common.Hash][]transactionWatch // active watches grouped by nonce and tx hash
 	watchAdded     chan struct{}                                 // channel to trigger instant pending check
@@ -52,7 +51,9 @@ type transactionWatch struct {
 	errC     chan error         // error channel (primarily for cancelled transactions)
 }
 
-func NewMonitor(logger log.Logger, backend Backend, sender common.Address, pollingInterval time.Duration, cancellationDepth uint64) Monitor {
+// NewMonitor creates a new Monitor which checks the watched transactions
+// whenever heads reports a new block. The head listener is not closed by the monitor.
+func NewMonitor(logger log.Logger, backend Backend, sender common.Address, heads HeadListener, cancellationDepth uint64) Monitor {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
 	t := &transactionMonitor{
@@ -61,8 +62,8 @@ func NewMonitor(logger log.Logger, backend Backend, sender common.Address, polli
 		logger:     logger.WithName(loggerName).Register(),
 		backend:    backend,
 		sender:     sender,
+		heads:      heads,
 
-		pollingInterval:   pollingInterval,
 		cancellationDepth: cancellationDepth,
 
 		watchesByNonce: make(map[uint64]map[common.Hash][]transactionWatch),
@@ -127,18 +128,26 @@ func (tm *transactionMonitor) watchPending() {
 	}()
 
 	var (
-		lastBlock uint64 = 0
-		added     bool   // flag if this iteration was triggered by the watchAdded channel
+		lastBlock   uint64      = 0
+		added       bool        // flag if this iteration was triggered by the watchAdded channel
+		heads       <-chan Head // new heads, only subscribed to while there are watches
+		unsubscribe func()
 	)
+	defer func() {
+		if unsubscribe != nil {
+			unsubscribe()
+		}
+	}()
 
 	for {
+		var head Head
 		added = false
 		select {
 		// if a new watch has been added check again without waiting
 		case <-tm.watchAdded:
 			added = true
-		// otherwise wait
-		case <-time.After(tm.pollingInterval):
+		// otherwise wait for the next block
+		case head = <-heads:
 		// if the main context is cancelled terminate
 		case <-tm.ctx.Done():
 			return
@@ -146,17 +155,29 @@ func (tm *transactionMonitor) watchPending() {
 
 		// if there are no watched transactions there is nothing to do
 		if !tm.hasWatches() {
+			if unsubscribe != nil {
+				unsubscribe()
+				heads, unsubscribe = nil, nil
+			}
 			continue
 		}
+		if heads == nil {
+			heads, unsubscribe = tm.heads.Subscribe()
+		}
 
-		// switch to new head subscriptions once websockets are the norm
-		block, err := tm.backend.BlockNumber(tm.ctx)
-		if err != nil {
-			tm.logger.Error(err, "could not get block number")
-			continue
-		} else if block <= lastBlock && !added {
+		if added {
+			var err error
+			head, err = tm.heads.Latest(tm.ctx)
+			if err != nil {
+				tm.logger.Error(err, "could not get block number")
+				continue
+			}
+		}
+
+		block := head.Number
+		if block <= lastBlock && !added && !head.Reorg {
 			// if the block number is not higher than before there is nothing todo
-			// unless a watch was added in which case we will do the check anyway
+			// unless a watch was added or the chain was reorged in which case we will do the check anyway
 			// in the rare case where a block was reorged and the new one is the first to contain our tx we wait an extra block
 			continue
 		}
@@ -31,7 +31,8 @@ func TestMonitorWatchTransaction(t *testing.T) {
 	t.Run("single transaction confirmed", func(t *testing.T) {
 		t.Parallel()
 
-		monitor := transaction.NewMonitor(
+		monitor := newTestMonitor(
+			t,
 			logger,
 			backendsimulation.New(
 				backendsimulation.WithBlocks(
@@ -82,7 +83,8 @@ func TestMonitorWatchTransaction(t *testing.T) {
 	t.Run("single transaction cancelled", func(t *testing.T) {
 		t.Parallel()
 
-		monitor := transaction.NewMonitor(
+		monitor := newTestMonitor(
+			t,
 			logger,
 			backendsimulation.New(
 				backendsimulation.WithBlocks(
@@ -142,7 +144,8 @@ func TestMonitorWatchTransaction(t *testing.T) {
 		txHash2 := common.HexToHash("bbbb")
 		txHash3 := common.HexToHash("cccc")
 
-		monitor := transaction.NewMonitor(
+		monitor := newTestMonitor(
+			t,
 			logger,
 			backendsimulation.New(
 				backendsimulation.WithBlocks(
@@ -264,7 +267,8 @@ func TestMonitorWatchTransaction(t *testing.T) {
 		t.Parallel()
 
 		txHash2 := common.HexToHash("bbbb")
-		monitor := transaction.NewMonitor(
+		monitor := newTestMonitor(
+			t,
 			logger,
 			backendsimulation.New(
 				backendsimulation.WithBlocks(
@@ -343,7 +347,8 @@ func TestMonitorWatchTransaction(t *testing.T) {
 	t.Run("shutdown while waiting", func(t *testing.T) {
 		t.Parallel()
 
-		monitor := transaction.NewMonitor(
+		monitor := newTestMonitor(
+			t,
 			logger,
 			backendsimulation.New(
 				backendsimulation.WithBlocks(
@@ -389,3 +394,17 @@ func TestMonitorWatchTransaction(t *testing.T) {
 	})
 
 }
+
+// newTestMonitor creates a monitor with its own head listener which is closed when the test finishes.
+func newTestMonitor(t *testing.T, logger log.Logger, backend transaction.Backend, sender common.Address, pollingInterval time.Duration, cancellationDepth uint64) transaction.Monitor {
+	t.Helper()
+
+	heads := transaction.NewHeadListener(logger, backend, pollingInterval)
+	t.Cleanup(func() {
+		if err := heads.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	return transaction.NewMonitor(logger, backend, sender, heads, cancellationDepth)
+}
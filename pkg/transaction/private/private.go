@@ -0,0 +1,198 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package private submits transactions through a private relay, like the
+// Flashbots Protect RPC, instead of the public mempool, so that high-value
+// operations cannot be front-run or griefed. Only transactions sent with a
+// context marked with sctx.SetPrivateSubmission are relayed. A relayed
+// transaction which was not mined before the deadline is broadcast publicly.
+package private
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "private"
+
+// DefaultDeadline is the time given to the relay to get a transaction mined
+// before it is broadcast publicly.
+const DefaultDeadline = 2 * time.Minute
+
+// fallbackTimeout bounds the calls made when a relayed transaction is
+// broadcast publicly.
+const fallbackTimeout = 30 * time.Second
+
+// Options configures the private submission of transactions.
+type Options struct {
+	Endpoint string        // rpc endpoint of the relay, empty disables private submission
+	Deadline time.Duration // time after which relayed transactions which were not mined are broadcast publicly
+}
+
+// Relay submits signed transactions without broadcasting them publicly.
+type Relay interface {
+	// SendPrivateTransaction submits the transaction for inclusion up to the
+	// max block number, 0 leaves the choice to the relay.
+	SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error
+	Close()
+}
+
+type rpcRelay struct {
+	client *rpc.Client
+}
+
+// DialRelay connects to the relay at the endpoint, which has to serve the
+// eth_sendPrivateTransaction method.
+func DialRelay(ctx context.Context, endpoint string) (Relay, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcRelay{client: client}, nil
+}
+
+func (r *rpcRelay) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{
+		"tx": hexutil.Encode(raw),
+	}
+	if maxBlockNumber > 0 {
+		params["maxBlockNumber"] = hexutil.EncodeUint64(maxBlockNumber)
+	}
+	var result interface{}
+	return r.client.CallContext(ctx, &result, "eth_sendPrivateTransaction", params)
+}
+
+func (r *rpcRelay) Close() {
+	r.client.Close()
+}
+
+var _ transaction.Backend = (*backend)(nil)
+
+type backend struct {
+	transaction.Backend
+	logger    log.Logger
+	relay     Relay
+	deadline  time.Duration
+	blockTime time.Duration
+
+	quit      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewBackend wraps the backend so that transactions requested to be
+// submitted privately are sent through the relay. They are broadcast
+// publicly if the relay rejects them or if they were not mined before the
+// deadline. The block time is used to limit the relay to the blocks before
+// the deadline.
+func NewBackend(logger log.Logger, b transaction.Backend, relay Relay, deadline, blockTime time.Duration) transaction.Backend {
+	return &backend{
+		Backend:   b,
+		logger:    logger.WithName(loggerName).Register(),
+		relay:     relay,
+		deadline:  deadline,
+		blockTime: blockTime,
+		quit:      make(chan struct{}),
+	}
+}
+
+// SendTransaction sends the transaction through the relay if the context
+// requests a private submission, otherwise through the wrapped backend.
+func (b *backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !sctx.GetPrivateSubmission(ctx) {
+		return b.Backend.SendTransaction(ctx, tx)
+	}
+
+	var maxBlockNumber uint64
+	if b.blockTime > 0 {
+		if head, err := b.Backend.BlockNumber(ctx); err == nil {
+			maxBlockNumber = head + uint64(b.deadline/b.blockTime) + 1
+		}
+	}
+
+	if err := b.relay.SendPrivateTransaction(ctx, tx, maxBlockNumber); err != nil {
+		b.logger.Warning("private submission failed, sending transaction publicly", "tx", tx.Hash(), "error", err)
+		return b.Backend.SendTransaction(ctx, tx)
+	}
+	b.logger.Debug("transaction submitted privately", "tx", tx.Hash(), "nonce", tx.Nonce(), "max_block_number", maxBlockNumber)
+
+	b.wg.Add(1)
+	go b.fallback(tx)
+	return nil
+}
+
+// fallback broadcasts the relayed transaction publicly if it was not mined
+// before the deadline.
+func (b *backend) fallback(tx *types.Transaction) {
+	defer b.wg.Done()
+
+	timer := time.NewTimer(b.deadline)
+	defer timer.Stop()
+	select {
+	case <-b.quit:
+		return
+	case <-timer.C:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fallbackTimeout)
+	defer cancel()
+
+	if _, err := b.Backend.TransactionReceipt(ctx, tx.Hash()); err == nil {
+		return
+	}
+	mined, err := b.nonceUsed(ctx, tx)
+	if err != nil {
+		b.logger.Debug("checking nonce of privately submitted transaction failed", "tx", tx.Hash(), "error", err)
+	}
+	if mined {
+		// a replacement of the transaction was mined
+		return
+	}
+
+	err = b.Backend.SendTransaction(ctx, tx)
+	if err != nil && !strings.Contains(err.Error(), "already known") {
+		b.logger.Error(err, "sending privately submitted transaction publicly failed", "tx", tx.Hash())
+		return
+	}
+	b.logger.Info("privately submitted transaction not mined before the deadline, sent publicly", "tx", tx.Hash(), "deadline", b.deadline)
+}
+
+// nonceUsed reports whether a transaction with the nonce of tx was mined.
+func (b *backend) nonceUsed(ctx context.Context, tx *types.Transaction) (bool, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return false, fmt.Errorf("transaction sender: %w", err)
+	}
+	nonce, err := b.Backend.NonceAt(ctx, from, nil)
+	if err != nil {
+		return false, err
+	}
+	return nonce > tx.Nonce(), nil
+}
+
+// Close stops waiting for the deadlines of relayed transactions and closes
+// the relay and the wrapped backend.
+func (b *backend) Close() {
+	b.closeOnce.Do(func() {
+		close(b.quit)
+		b.wg.Wait()
+		b.relay.Close()
+		b.Backend.Close()
+	})
+}
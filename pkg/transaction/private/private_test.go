@@ -0,0 +1,163 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package private_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/transaction/private"
+)
+
+const chainID = 1
+
+type relayMock struct {
+	err  error
+	sent chan uint64 // max block numbers of the submitted transactions
+}
+
+func (r *relayMock) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.sent <- maxBlockNumber
+	return nil
+}
+
+func (r *relayMock) Close() {}
+
+func signedTx(t *testing.T) *types.Transaction {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0xabcd")
+	tx, err := crypto.NewDefaultSigner(key).SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(chainID),
+		Nonce:     3,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2),
+		GasTipCap: big.NewInt(1),
+	}), big.NewInt(chainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestPrivateSubmission(t *testing.T) {
+	t.Parallel()
+
+	tx := signedTx(t)
+
+	newBackend := func(relay private.Relay, mined bool, public chan<- common.Hash) func() {
+		b := private.NewBackend(log.Noop, backendmock.New(
+			backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+				return 100, nil
+			}),
+			backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+				public <- tx.Hash()
+				return nil
+			}),
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				if mined {
+					return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+				}
+				return nil, ethereum.NotFound
+			}),
+			backendmock.WithNonceAtFunc(func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+				return tx.Nonce(), nil
+			}),
+		), relay, 50*time.Millisecond, 10*time.Millisecond)
+
+		if err := b.SendTransaction(sctx.SetPrivateSubmission(context.Background(), true), tx); err != nil {
+			t.Fatal(err)
+		}
+		return b.Close
+	}
+
+	t.Run("public fallback after deadline", func(t *testing.T) {
+		t.Parallel()
+
+		relay := &relayMock{sent: make(chan uint64, 1)}
+		public := make(chan common.Hash, 1)
+		closeBackend := newBackend(relay, false, public)
+		defer closeBackend()
+
+		if maxBlockNumber := <-relay.sent; maxBlockNumber != 106 {
+			t.Fatalf("got max block number %d, want %d", maxBlockNumber, 106)
+		}
+		select {
+		case txHash := <-public:
+			if txHash != tx.Hash() {
+				t.Fatalf("got public transaction %v, want %v", txHash, tx.Hash())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("transaction not sent publicly after the deadline")
+		}
+	})
+
+	t.Run("mined before deadline", func(t *testing.T) {
+		t.Parallel()
+
+		relay := &relayMock{sent: make(chan uint64, 1)}
+		public := make(chan common.Hash, 1)
+		closeBackend := newBackend(relay, true, public)
+
+		<-relay.sent
+		time.Sleep(100 * time.Millisecond)
+		closeBackend()
+		if len(public) != 0 {
+			t.Fatal("mined transaction sent publicly")
+		}
+	})
+
+	t.Run("relay failure", func(t *testing.T) {
+		t.Parallel()
+
+		public := make(chan common.Hash, 1)
+		closeBackend := newBackend(&relayMock{err: errors.New("relay down")}, false, public)
+		defer closeBackend()
+
+		if len(public) != 1 {
+			t.Fatal("transaction rejected by the relay not sent publicly")
+		}
+	})
+
+	t.Run("public submission", func(t *testing.T) {
+		t.Parallel()
+
+		relay := &relayMock{sent: make(chan uint64, 1)}
+		public := make(chan common.Hash, 1)
+		b := private.NewBackend(log.Noop, backendmock.New(
+			backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+				public <- tx.Hash()
+				return nil
+			}),
+		), relay, time.Minute, time.Second)
+		defer b.Close()
+
+		if err := b.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+		if len(public) != 1 || len(relay.sent) != 0 {
+			t.Fatal("transaction without private submission not sent publicly")
+		}
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retry
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Retries         *prometheus.CounterVec
+	BudgetExhausted prometheus.Counter
+	Timeouts        *prometheus.CounterVec
+	Latency         *prometheus.HistogramVec
+	Timeout         *prometheus.GaugeVec
+}
+
+func newMetrics() metrics {
+	subsystem := "eth_backend"
+
+	return metrics{
+		Retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rpc_retries",
+			Help:      "Count of retried rpc calls by method",
+		}, []string{"method"}),
+		BudgetExhausted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rpc_retry_budget_exhausted",
+			Help:      "Count of rpc calls not retried because the retry budget was used up",
+		}),
+		Timeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rpc_timeouts",
+			Help:      "Count of rpc call attempts cancelled for exceeding the adaptive timeout by method",
+		}, []string{"method"}),
+		Latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rpc_latency_seconds",
+			Help:      "Latency of completed rpc call attempts by method",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"method"}),
+		Timeout: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rpc_timeout_seconds",
+			Help:      "Current adaptive timeout of rpc calls by method",
+		}, []string{"method"}),
+	}
+}
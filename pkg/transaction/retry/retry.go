@@ -0,0 +1,396 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package retry retries blockchain backend calls failing with transient
+// errors such as timeouts, rate limiting and temporary server errors. Calls
+// hanging much longer than usual for their method are cancelled and retried.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts of a call.
+	DefaultMaxAttempts = 3
+	// DefaultInitialBackoff is the default upper bound of the wait before the first retry.
+	DefaultInitialBackoff = 250 * time.Millisecond
+	// DefaultMaxBackoff is the default upper bound of the wait between two attempts.
+	DefaultMaxBackoff = 10 * time.Second
+	// DefaultBudget is the default number of retries which can be made in a row
+	// before retrying is suspended until calls succeed again.
+	DefaultBudget = 100
+	// DefaultBudgetRefill is the default share of a retry regained by every successful call.
+	DefaultBudgetRefill = 0.1
+)
+
+// Policy configures the retries of a call type.
+type Policy struct {
+	MaxAttempts    int           // attempts including the first one, 1 disables retries
+	InitialBackoff time.Duration // upper bound of the wait before the first retry
+	MaxBackoff     time.Duration // upper bound of the wait between two attempts
+	// AdaptiveTimeout cancels attempts running longer than the usual latency
+	// of the method, see Options.Timeouts.
+	AdaptiveTimeout bool
+}
+
+// Options configures the backend.
+type Options struct {
+	Default Policy            // policy of calls without an own policy
+	Calls   map[string]Policy // policies by backend method name, e.g. CallContract
+	// Budget is the number of retries which can be made in a row. Every retry
+	// uses up one unit of the budget and every successful call regains
+	// BudgetRefill units. Without budget calls are not retried so that a
+	// failing endpoint is not flooded with retries.
+	Budget       float64
+	BudgetRefill float64
+	Timeouts     TimeoutOptions
+}
+
+// DefaultOptions returns the default options. Transactions are neither
+// resent nor cancelled as a failed send might still have reached the network.
+func DefaultOptions() Options {
+	policy := Policy{
+		MaxAttempts:     DefaultMaxAttempts,
+		InitialBackoff:  DefaultInitialBackoff,
+		MaxBackoff:      DefaultMaxBackoff,
+		AdaptiveTimeout: true,
+	}
+	send := policy
+	send.MaxAttempts = 1
+	send.AdaptiveTimeout = false
+	return Options{
+		Default: policy,
+		Calls: map[string]Policy{
+			"SendTransaction": send,
+		},
+		Budget:       DefaultBudget,
+		BudgetRefill: DefaultBudgetRefill,
+		Timeouts:     DefaultTimeoutOptions(),
+	}
+}
+
+// ParseCallAttempts parses per call attempts given as method=attempts and
+// sets them in the policy of the method, which is based on the default
+// policy if the method has none yet.
+func (o *Options) ParseCallAttempts(calls []string) error {
+	for _, c := range calls {
+		method, value, ok := strings.Cut(c, "=")
+		attempts, err := strconv.Atoi(value)
+		if !ok || method == "" || err != nil || attempts < 1 {
+			return fmt.Errorf("invalid call retry %q, expected method=attempts", c)
+		}
+		policy, ok := o.Calls[method]
+		if !ok {
+			policy = o.Default
+		}
+		policy.MaxAttempts = attempts
+		if o.Calls == nil {
+			o.Calls = make(map[string]Policy)
+		}
+		o.Calls[method] = policy
+	}
+	return nil
+}
+
+// IsTransient reports whether the error of a backend call is worth retrying.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusRequestTimeout, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the limit exceeded code used by most providers for rate limiting
+		if rpcErr.ErrorCode() == -32005 {
+			return true
+		}
+		message := strings.ToLower(rpcErr.Error())
+		return strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests")
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+var _ transaction.Backend = (*backend)(nil)
+
+type backend struct {
+	backend transaction.Backend
+	options Options
+	metrics metrics
+
+	mu        sync.Mutex
+	budget    float64
+	rand      *rand.Rand
+	latencies map[string]*latencies
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewBackend wraps the backend so that calls failing with transient errors
+// are retried with exponential backoff and full jitter.
+func NewBackend(b transaction.Backend, o Options) transaction.Backend {
+	return &backend{
+		backend:   b,
+		options:   o,
+		metrics:   newMetrics(),
+		budget:    o.Budget,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		latencies: make(map[string]*latencies),
+		sleep:     sleep,
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *backend) policy(method string) Policy {
+	if p, ok := b.options.Calls[method]; ok {
+		return p
+	}
+	return b.options.Default
+}
+
+// takeBudget uses up one retry of the budget if there is one left.
+func (b *backend) takeBudget() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.budget < 1 {
+		return false
+	}
+	b.budget--
+	return true
+}
+
+func (b *backend) refillBudget() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.budget += b.options.BudgetRefill
+	if b.budget > b.options.Budget {
+		b.budget = b.options.Budget
+	}
+}
+
+// backoff returns a random wait of at most the exponential backoff of the retry.
+func (b *backend) backoff(p Policy, retry int) time.Duration {
+	limit := p.InitialBackoff << retry
+	if limit <= 0 || limit > p.MaxBackoff {
+		limit = p.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(b.rand.Int63n(int64(limit) + 1))
+}
+
+// do calls f until it succeeds, fails with a permanent error, the attempts of
+// the method are used up or there is no budget left.
+func (b *backend) do(ctx context.Context, method string, f func(ctx context.Context) error) error {
+	p := b.policy(method)
+	for attempt := 1; ; attempt++ {
+		timedOut, err := b.attempt(ctx, method, p, attempt, f)
+		if err == nil {
+			b.refillBudget()
+			return nil
+		}
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !(timedOut || IsTransient(err)) {
+			return err
+		}
+		if !b.takeBudget() {
+			b.metrics.BudgetExhausted.Inc()
+			return err
+		}
+		b.metrics.Retries.WithLabelValues(method).Inc()
+		if serr := b.sleep(ctx, b.backoff(p, attempt-1)); serr != nil {
+			return err
+		}
+	}
+}
+
+func (b *backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = b.do(ctx, "CodeAt", func(ctx context.Context) error {
+		code, err = b.backend.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (b *backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = b.do(ctx, "CallContract", func(ctx context.Context) error {
+		result, err = b.backend.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (b *backend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = b.do(ctx, "HeaderByNumber", func(ctx context.Context) error {
+		header, err = b.backend.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (b *backend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = b.do(ctx, "PendingNonceAt", func(ctx context.Context) error {
+		nonce, err = b.backend.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (b *backend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = b.do(ctx, "SuggestGasPrice", func(ctx context.Context) error {
+		price, err = b.backend.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (b *backend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = b.do(ctx, "SuggestGasTipCap", func(ctx context.Context) error {
+		tip, err = b.backend.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (b *backend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (history *ethereum.FeeHistory, err error) {
+	err = b.do(ctx, "FeeHistory", func(ctx context.Context) error {
+		history, err = b.backend.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return history, err
+}
+
+func (b *backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = b.do(ctx, "EstimateGas", func(ctx context.Context) error {
+		gas, err = b.backend.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+func (b *backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.do(ctx, "SendTransaction", func(ctx context.Context) error {
+		return b.backend.SendTransaction(ctx, tx)
+	})
+}
+
+func (b *backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = b.do(ctx, "TransactionReceipt", func(ctx context.Context) error {
+		receipt, err = b.backend.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (b *backend) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = b.do(ctx, "TransactionByHash", func(ctx context.Context) error {
+		tx, isPending, err = b.backend.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (b *backend) BlockNumber(ctx context.Context) (number uint64, err error) {
+	err = b.do(ctx, "BlockNumber", func(ctx context.Context) error {
+		number, err = b.backend.BlockNumber(ctx)
+		return err
+	})
+	return number, err
+}
+
+func (b *backend) BalanceAt(ctx context.Context, address common.Address, block *big.Int) (balance *big.Int, err error) {
+	err = b.do(ctx, "BalanceAt", func(ctx context.Context) error {
+		balance, err = b.backend.BalanceAt(ctx, address, block)
+		return err
+	})
+	return balance, err
+}
+
+func (b *backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = b.do(ctx, "NonceAt", func(ctx context.Context) error {
+		nonce, err = b.backend.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return nonce, err
+}
+
+func (b *backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = b.do(ctx, "FilterLogs", func(ctx context.Context) error {
+		logs, err = b.backend.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+func (b *backend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = b.do(ctx, "ChainID", func(ctx context.Context) error {
+		chainID, err = b.backend.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (b *backend) Close() {
+	b.backend.Close()
+}
+
+// Metrics returns the metrics of the retries together with the ones of the wrapped backend.
+func (b *backend) Metrics() []prometheus.Collector {
+	collectors := m.PrometheusCollectorsFromFields(b.metrics)
+	if c, ok := b.backend.(m.Collector); ok {
+		collectors = append(collectors, c.Metrics()...)
+	}
+	return collectors
+}
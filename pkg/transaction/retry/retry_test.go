@@ -0,0 +1,302 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/transaction/retry"
+)
+
+var errUnavailable = rpc.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+
+// statusCode returns the status code of an http error or zero.
+func statusCode(err error) int {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
+func testOptions() retry.Options {
+	o := retry.DefaultOptions()
+	o.Default.InitialBackoff = time.Millisecond
+	o.Default.MaxBackoff = time.Millisecond
+	return o
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("abcd")
+	calls := 0
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBalanceAt(func(ctx context.Context, a common.Address, block *big.Int) (*big.Int, error) {
+			calls++
+			if calls < 3 {
+				return nil, fmt.Errorf("balance: %w", errUnavailable)
+			}
+			return big.NewInt(10), nil
+		}),
+	), testOptions())
+
+	balance, err := backend.BalanceAt(context.Background(), address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("got balance %v, want 10", balance)
+	}
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+}
+
+func TestRetryAttempts(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	if err := o.ParseCallAttempts([]string{"BlockNumber=5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	blockNumberCalls, sendCalls := 0, 0
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			blockNumberCalls++
+			return 0, errUnavailable
+		}),
+		backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+			sendCalls++
+			return errUnavailable
+		}),
+	), o)
+
+	if _, err := backend.BlockNumber(context.Background()); statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want %v", err, errUnavailable)
+	}
+	if blockNumberCalls != 5 {
+		t.Fatalf("got %d calls, want 5", blockNumberCalls)
+	}
+
+	// transactions are not resent by default
+	if err := backend.SendTransaction(context.Background(), nil); statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want %v", err, errUnavailable)
+	}
+	if sendCalls != 1 {
+		t.Fatalf("got %d calls, want 1", sendCalls)
+	}
+}
+
+func TestRetryPermanentError(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{name: "not found", err: ethereum.NotFound},
+		{name: "reverted", err: errors.New("execution reverted")},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			backend := retry.NewBackend(backendmock.New(
+				backendmock.WithTransactionReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+					calls++
+					return nil, tc.err
+				}),
+			), testOptions())
+
+			if _, err := backend.TransactionReceipt(context.Background(), common.Hash{}); !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if calls != 1 {
+				t.Fatalf("got %d calls, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	o.Budget = 2
+	o.BudgetRefill = 1
+
+	failing := true
+	calls := 0
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			calls++
+			if failing {
+				return 0, errUnavailable
+			}
+			return 1, nil
+		}),
+	), o)
+
+	// the first call uses up the budget with two retries
+	_, _ = backend.BlockNumber(context.Background())
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+
+	// without budget there are no retries
+	_, _ = backend.BlockNumber(context.Background())
+	if calls != 4 {
+		t.Fatalf("got %d calls, want 4", calls)
+	}
+
+	// a successful call regains budget for a retry
+	failing = false
+	if _, err := backend.BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	failing = true
+	calls = 0
+	_, _ = backend.BlockNumber(context.Background())
+	if calls != 2 {
+		t.Fatalf("got %d calls, want 2", calls)
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			calls++
+			cancel()
+			return 0, errUnavailable
+		}),
+	), testOptions())
+
+	if _, err := backend.BlockNumber(ctx); statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want %v", err, errUnavailable)
+	}
+	if calls != 1 {
+		t.Fatalf("got %d calls, want 1", calls)
+	}
+}
+
+func TestParseCallAttempts(t *testing.T) {
+	t.Parallel()
+
+	for _, calls := range [][]string{{"CallContract"}, {"=3"}, {"CallContract=0"}, {"CallContract=x"}} {
+		o := retry.DefaultOptions()
+		if err := o.ParseCallAttempts(calls); err == nil {
+			t.Fatalf("parsing %v: expected error", calls)
+		}
+	}
+
+	o := retry.DefaultOptions()
+	if err := o.ParseCallAttempts([]string{"SendTransaction=2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Calls["SendTransaction"]; got.MaxAttempts != 2 || got.MaxBackoff != retry.DefaultMaxBackoff {
+		t.Fatalf("got policy %+v", got)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{err: rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{err: fmt.Errorf("call: %w", errUnavailable), want: true},
+		{err: rpc.HTTPError{StatusCode: http.StatusBadRequest}, want: false},
+		{err: context.DeadlineExceeded, want: true},
+		{err: ethereum.NotFound, want: false},
+		{err: errors.New("execution reverted"), want: false},
+	} {
+		if got := retry.IsTransient(tc.err); got != tc.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRetryHangingCall(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	o.Timeouts = retry.TimeoutOptions{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	calls := 0
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			calls++
+			if calls == 1 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 1, nil
+		}),
+	), o)
+
+	number, err := backend.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number != 1 || calls != 2 {
+		t.Fatalf("got block %d after %d calls, want block 1 after 2 calls", number, calls)
+	}
+}
+
+func TestRetryAdaptiveTimeout(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	o.Timeouts = retry.TimeoutOptions{Min: 10 * time.Millisecond, Max: time.Minute}
+
+	hang := false
+	backend := retry.NewBackend(backendmock.New(
+		backendmock.WithBlockNumberFunc(func(ctx context.Context) (uint64, error) {
+			if hang {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 1, nil
+		}),
+	), o)
+
+	// fast calls lower the timeout from the upper bound to the lower bound
+	for i := 0; i < 32; i++ {
+		if _, err := backend.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hang = true
+	start := time.Now()
+	_, err := backend.BlockNumber(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	// three attempts with timeouts of 10ms, 20ms and 40ms
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("hanging call took %s", elapsed)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeoutMargin is the default time added to the p99 latency of a method to get its timeout.
+	DefaultTimeoutMargin = 2 * time.Second
+	// DefaultMinTimeout is the default lower bound of adaptive timeouts.
+	DefaultMinTimeout = 5 * time.Second
+	// DefaultMaxTimeout is the default upper bound of adaptive timeouts.
+	DefaultMaxTimeout = time.Minute
+
+	latencyWindow     = 512 // number of latest latencies kept per method
+	minLatencySamples = 20  // number of latencies needed before timeouts adapt
+	timeoutUpdate     = 16  // number of latencies after which the timeout is updated
+)
+
+// TimeoutOptions configures the adaptive timeouts. The timeout of a method
+// is its p99 latency over the latest calls plus the margin, kept within the
+// bounds. Until enough calls of a method completed the upper bound applies.
+// Every retry of a timed out attempt doubles the timeout up to the upper bound
+// so that calls still complete if the endpoint became slower as a whole.
+type TimeoutOptions struct {
+	Margin time.Duration
+	Min    time.Duration
+	Max    time.Duration // zero disables adaptive timeouts
+}
+
+// DefaultTimeoutOptions returns the default timeout options.
+func DefaultTimeoutOptions() TimeoutOptions {
+	return TimeoutOptions{
+		Margin: DefaultTimeoutMargin,
+		Min:    DefaultMinTimeout,
+		Max:    DefaultMaxTimeout,
+	}
+}
+
+// latencies tracks the latest latencies of a method and the timeout derived from them.
+type latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration // ring buffer of the latest latencies
+	next    int             // position of the next latency in samples
+	added   int             // latencies added since the last timeout update
+	timeout time.Duration
+}
+
+func newLatencies(o TimeoutOptions) *latencies {
+	return &latencies{
+		samples: make([]time.Duration, 0, latencyWindow),
+		timeout: o.Max,
+	}
+}
+
+// add records the latency of a completed call and updates the timeout from time to time.
+func (l *latencies) add(d time.Duration, o TimeoutOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < latencyWindow {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+	}
+	l.next = (l.next + 1) % latencyWindow
+	l.added++
+
+	if len(l.samples) < minLatencySamples || l.added < timeoutUpdate {
+		return
+	}
+	l.added = 0
+
+	timeout := l.percentile(0.99) + o.Margin
+	if timeout < o.Min {
+		timeout = o.Min
+	}
+	if timeout > o.Max {
+		timeout = o.Max
+	}
+	l.timeout = timeout
+}
+
+// percentile returns the latency below which the share q of the samples lie.
+// It must be called with the lock held.
+func (l *latencies) percentile(q float64) time.Duration {
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func (l *latencies) currentTimeout() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.timeout
+}
+
+func (b *backend) methodLatencies(method string) *latencies {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l, ok := b.latencies[method]
+	if !ok {
+		l = newLatencies(b.options.Timeouts)
+		b.latencies[method] = l
+	}
+	return l
+}
+
+// attempt makes a single attempt of a call, cancelling it if it exceeds the
+// adaptive timeout of the method. It reports whether the attempt timed out.
+func (b *backend) attempt(ctx context.Context, method string, p Policy, attempt int, f func(ctx context.Context) error) (bool, error) {
+	o := b.options.Timeouts
+	l := b.methodLatencies(method)
+
+	var timeout time.Duration
+	if p.AdaptiveTimeout && o.Max > 0 {
+		timeout = l.currentTimeout() << (attempt - 1)
+		if timeout <= 0 || timeout > o.Max {
+			timeout = o.Max
+		}
+	}
+
+	attemptCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := f(attemptCtx)
+	elapsed := time.Since(start)
+
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		b.metrics.Timeouts.WithLabelValues(method).Inc()
+		return true, fmt.Errorf("%s timed out after %s: %w", method, timeout, err)
+	}
+	if ctx.Err() == nil {
+		l.add(elapsed, o)
+		b.metrics.Latency.WithLabelValues(method).Observe(elapsed.Seconds())
+		b.metrics.Timeout.WithLabelValues(method).Set(l.currentTimeout().Seconds())
+	}
+	return false, err
+}
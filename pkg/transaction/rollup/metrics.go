@@ -0,0 +1,52 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rollup
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	SequencerDown        prometheus.Gauge
+	WaitingTransactions  prometheus.Gauge
+	DeferredTransactions prometheus.Counter
+	ExpiredTransactions  prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "rollup"
+
+	return metrics{
+		SequencerDown: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sequencer_down",
+			Help:      "Whether the sequencer was reported down at the last check of its uptime feed",
+		}),
+		WaitingTransactions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "waiting_transactions",
+			Help:      "Number of transactions waiting for the sequencer",
+		}),
+		DeferredTransactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "deferred_transactions",
+			Help:      "Number of transactions which were sent after waiting for the sequencer",
+		}),
+		ExpiredTransactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "expired_transactions",
+			Help:      "Number of transactions given up because the sequencer stayed down",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
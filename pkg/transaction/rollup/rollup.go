@@ -0,0 +1,261 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rollup adapts sending transactions to L2 rollups. Transactions are
+// held back while the sequencer is down according to its uptime feed and the
+// L1 data fee charged for publishing transactions is estimated.
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "rollup"
+
+const (
+	// DefaultGracePeriod is the default time transactions are held back after
+	// the sequencer came back up, so that the queue built up during the
+	// outage is processed first.
+	DefaultGracePeriod = time.Hour
+	// DefaultExpiry is the default time a transaction waits for the sequencer.
+	DefaultExpiry = 2 * time.Hour
+	// DefaultRetryInterval is the default interval in which the uptime feed is checked again.
+	DefaultRetryInterval = time.Minute
+)
+
+// uptimeFeedABI is the part of the Chainlink aggregator interface of sequencer uptime feeds.
+const uptimeFeedABIJSON = `[{"inputs":[],"name":"latestRoundData","outputs":[{"internalType":"uint80","name":"roundId","type":"uint80"},{"internalType":"int256","name":"answer","type":"int256"},{"internalType":"uint256","name":"startedAt","type":"uint256"},{"internalType":"uint256","name":"updatedAt","type":"uint256"},{"internalType":"uint80","name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}]`
+
+// gasPriceOracleABI is the part of the OP Stack GasPriceOracle predeploy used to estimate L1 data fees.
+const gasPriceOracleABIJSON = `[{"inputs":[{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	uptimeFeedABI     = abiutil.MustParseABI(uptimeFeedABIJSON)
+	gasPriceOracleABI = abiutil.MustParseABI(gasPriceOracleABIJSON)
+
+	// GasPriceOracleAddress is the address of the GasPriceOracle predeploy of OP Stack chains.
+	GasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+	// opStackChains are the known chains with the GasPriceOracle predeploy.
+	opStackChains = map[int64]bool{
+		10:       true, // Optimism
+		420:      true, // Optimism Goerli
+		11155420: true, // Optimism Sepolia
+		8453:     true, // Base
+		84531:    true, // Base Goerli
+		84532:    true, // Base Sepolia
+	}
+)
+
+var (
+	// ErrSequencerDown is the error returned for transactions which waited
+	// for the sequencer to come back up until they expired.
+	ErrSequencerDown = errors.New("sequencer down")
+	// ErrNoL1FeeOracle is the error returned if L1 data fees are estimated without an oracle.
+	ErrNoL1FeeOracle = errors.New("no L1 fee oracle")
+	// ErrClosed is the error returned for waiting transactions when the service is closed.
+	ErrClosed = errors.New("rollup service closed")
+)
+
+// L1FeeOracle returns the address of the L1 data fee oracle of the chain and
+// whether the chain is known to have one.
+func L1FeeOracle(chainID int64) (common.Address, bool) {
+	if opStackChains[chainID] {
+		return GasPriceOracleAddress, true
+	}
+	return common.Address{}, false
+}
+
+// Options configures the Service.
+type Options struct {
+	UptimeFeed    common.Address // sequencer uptime feed, the zero address disables waiting for the sequencer
+	L1FeeOracle   common.Address // L1 data fee oracle, the zero address disables fee estimation
+	GracePeriod   time.Duration  // time after the sequencer came back up during which transactions still wait
+	Expiry        time.Duration  // maximum time a transaction waits for the sequencer
+	RetryInterval time.Duration  // interval in which the uptime feed is checked
+}
+
+// SequencerStatus is the status of the sequencer reported by its uptime feed.
+type SequencerStatus struct {
+	Up    bool
+	Since time.Time // time of the last status change
+}
+
+var _ transaction.Service = (*Service)(nil)
+
+// Service is a transaction.Service which holds back sent transactions while
+// the sequencer of the rollup is down or within the grace period after it
+// came back up.
+type Service struct {
+	transaction.Service
+
+	logger  log.Logger
+	options Options
+	metrics metrics
+	timeNow func() time.Time
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a new rollup service wrapping transactionService.
+func New(logger log.Logger, transactionService transaction.Service, o Options) *Service {
+	if o.GracePeriod < 0 {
+		o.GracePeriod = 0
+	}
+	if o.Expiry <= 0 {
+		o.Expiry = DefaultExpiry
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+	return &Service{
+		Service: transactionService,
+		logger:  logger.WithName(loggerName).Register(),
+		options: o,
+		metrics: newMetrics(),
+		timeNow: time.Now,
+		quit:    make(chan struct{}),
+	}
+}
+
+// SequencerStatus reads the status of the sequencer from the uptime feed.
+func (s *Service) SequencerStatus(ctx context.Context) (*SequencerStatus, error) {
+	callData, err := uptimeFeedABI.Pack("latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Service.Call(ctx, &transaction.TxRequest{
+		To:   &s.options.UptimeFeed,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, err := uptimeFeedABI.Unpack("latestRoundData", output)
+	if err != nil {
+		return nil, fmt.Errorf("uptime feed %s: %w", s.options.UptimeFeed, err)
+	}
+	answer, ok1 := results[1].(*big.Int)
+	startedAt, ok2 := results[2].(*big.Int)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("uptime feed %s: unexpected round data", s.options.UptimeFeed)
+	}
+
+	// an answer of 0 means the sequencer is up, 1 that it is down
+	status := &SequencerStatus{
+		Up:    answer.Sign() == 0,
+		Since: time.Unix(startedAt.Int64(), 0),
+	}
+	if status.Up {
+		s.metrics.SequencerDown.Set(0)
+	} else {
+		s.metrics.SequencerDown.Set(1)
+	}
+	return status, nil
+}
+
+// ready reports whether the sequencer is up for longer than the grace period.
+// Transactions are not held back if the uptime feed cannot be read.
+func (s *Service) ready(ctx context.Context) (bool, *SequencerStatus) {
+	status, err := s.SequencerStatus(ctx)
+	if err != nil {
+		s.logger.Warning("sequencer uptime feed unavailable, not waiting for sequencer", "feed", s.options.UptimeFeed, "error", err)
+		return true, nil
+	}
+	return status.Up && s.timeNow().Sub(status.Since) >= s.options.GracePeriod, status
+}
+
+// Send sends the request once the sequencer is up for longer than the grace
+// period. It returns ErrSequencerDown if that did not happen before the
+// request expired.
+func (s *Service) Send(ctx context.Context, request *transaction.TxRequest, boostPercent int) (common.Hash, error) {
+	if s.options.UptimeFeed == (common.Address{}) {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+
+	ready, status := s.ready(ctx)
+	if ready {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+
+	s.metrics.WaitingTransactions.Inc()
+	defer s.metrics.WaitingTransactions.Dec()
+	s.logger.Info("sequencer down or recovering, transaction deferred", "description", request.Description, "sequencer_up", status.Up, "since", status.Since)
+
+	expiry := time.NewTimer(s.options.Expiry)
+	defer expiry.Stop()
+	ticker := time.NewTicker(s.options.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-expiry.C:
+			s.metrics.ExpiredTransactions.Inc()
+			s.logger.Warning("sequencer stayed down, transaction expired", "description", request.Description)
+			return common.Hash{}, fmt.Errorf("%s: %w", request.Description, ErrSequencerDown)
+		case <-ctx.Done():
+			return common.Hash{}, ctx.Err()
+		case <-s.quit:
+			return common.Hash{}, ErrClosed
+		}
+
+		if ready, _ := s.ready(ctx); !ready {
+			continue
+		}
+
+		s.metrics.DeferredTransactions.Inc()
+		s.logger.Info("sequencer up, sending deferred transaction", "description", request.Description)
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+}
+
+// L1Fee estimates the fee charged for publishing a transaction with the given
+// data on L1, in addition to its gas cost on the rollup.
+func (s *Service) L1Fee(ctx context.Context, data []byte) (*big.Int, error) {
+	if s.options.L1FeeOracle == (common.Address{}) {
+		return nil, ErrNoL1FeeOracle
+	}
+
+	callData, err := gasPriceOracleABI.Pack("getL1Fee", data)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Service.Call(ctx, &transaction.TxRequest{
+		To:   &s.options.L1FeeOracle,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, err := gasPriceOracleABI.Unpack("getL1Fee", output)
+	if err != nil {
+		return nil, fmt.Errorf("L1 fee oracle %s: %w", s.options.L1FeeOracle, err)
+	}
+	fee, ok := results[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("L1 fee oracle %s: unexpected result", s.options.L1FeeOracle)
+	}
+	return fee, nil
+}
+
+// Close fails all deferred transactions. It does not close the wrapped service.
+func (s *Service) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rollup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/transaction/rollup"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+var (
+	txHash     = common.HexToHash("0xabcd")
+	uptimeFeed = common.HexToAddress("0xfeed")
+
+	uptimeFeedABI     = abiutil.MustParseABI(`[{"inputs":[],"name":"latestRoundData","outputs":[{"internalType":"uint80","name":"roundId","type":"uint80"},{"internalType":"int256","name":"answer","type":"int256"},{"internalType":"uint256","name":"startedAt","type":"uint256"},{"internalType":"uint256","name":"updatedAt","type":"uint256"},{"internalType":"uint80","name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}]`)
+	gasPriceOracleABI = abiutil.MustParseABI(`[{"inputs":[{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`)
+)
+
+// sequencer is the state reported by the mocked uptime feed.
+type sequencer struct {
+	down  atomic.Bool
+	since atomic.Int64
+}
+
+func newService(t *testing.T, seq *sequencer, o rollup.Options) (*rollup.Service, *atomic.Int32) {
+	t.Helper()
+
+	var sent atomic.Int32
+	s := rollup.New(
+		log.Noop,
+		transactionmock.New(
+			transactionmock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) ([]byte, error) {
+				switch *request.To {
+				case uptimeFeed:
+					answer := big.NewInt(0)
+					if seq.down.Load() {
+						answer = big.NewInt(1)
+					}
+					since := big.NewInt(seq.since.Load())
+					return uptimeFeedABI.Methods["latestRoundData"].Outputs.Pack(big.NewInt(1), answer, since, since, big.NewInt(1))
+				case rollup.GasPriceOracleAddress:
+					data, err := gasPriceOracleABI.Methods["getL1Fee"].Inputs.Unpack(request.Data[4:])
+					if err != nil {
+						return nil, err
+					}
+					// the fee is proportional to the data size
+					return gasPriceOracleABI.Methods["getL1Fee"].Outputs.Pack(big.NewInt(int64(10 * len(data[0].([]byte)))))
+				}
+				return nil, errors.New("unexpected call")
+			}),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sent.Add(1)
+				return txHash, nil
+			}),
+		),
+		o,
+	)
+	t.Cleanup(func() { _ = s.Close() })
+	return s, &sent
+}
+
+func TestSendSequencerUp(t *testing.T) {
+	t.Parallel()
+
+	seq := new(sequencer)
+	seq.since.Store(time.Now().Add(-2 * time.Hour).Unix())
+	s, sent := newService(t, seq, rollup.Options{UptimeFeed: uptimeFeed, GracePeriod: time.Hour})
+
+	hash, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != txHash || sent.Load() != 1 {
+		t.Fatal("transaction not sent")
+	}
+}
+
+func TestSendSequencerDown(t *testing.T) {
+	t.Parallel()
+
+	seq := new(sequencer)
+	seq.down.Store(true)
+	seq.since.Store(time.Now().Unix())
+	s, sent := newService(t, seq, rollup.Options{
+		UptimeFeed:    uptimeFeed,
+		GracePeriod:   time.Hour,
+		RetryInterval: time.Millisecond,
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+		errC <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	// the sequencer came back up but is still in its grace period
+	seq.down.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	if sent.Load() != 0 {
+		t.Fatal("sent while the sequencer was down")
+	}
+
+	seq.since.Store(time.Now().Add(-2 * time.Hour).Unix())
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deferred transaction not sent")
+	}
+	if sent.Load() != 1 {
+		t.Fatal("transaction not sent")
+	}
+}
+
+func TestSendSequencerExpired(t *testing.T) {
+	t.Parallel()
+
+	seq := new(sequencer)
+	seq.down.Store(true)
+	s, sent := newService(t, seq, rollup.Options{
+		UptimeFeed:    uptimeFeed,
+		Expiry:        20 * time.Millisecond,
+		RetryInterval: time.Millisecond,
+	})
+
+	_, err := s.Send(context.Background(), &transaction.TxRequest{Description: "cashout"}, 0)
+	if !errors.Is(err, rollup.ErrSequencerDown) {
+		t.Fatalf("got error %v, want %v", err, rollup.ErrSequencerDown)
+	}
+	if sent.Load() != 0 {
+		t.Fatal("sent while the sequencer was down")
+	}
+}
+
+func TestL1Fee(t *testing.T) {
+	t.Parallel()
+
+	oracle, ok := rollup.L1FeeOracle(10)
+	if !ok {
+		t.Fatal("no L1 fee oracle for Optimism")
+	}
+	s, _ := newService(t, new(sequencer), rollup.Options{L1FeeOracle: oracle})
+
+	fee, err := s.L1Fee(context.Background(), bytes.Repeat([]byte{1}, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("got L1 fee %v, want %v", fee, 1000)
+	}
+
+	if _, ok := rollup.L1FeeOracle(100); ok {
+		t.Fatal("L1 fee oracle for Gnosis Chain")
+	}
+	s, _ = newService(t, new(sequencer), rollup.Options{})
+	if _, err := s.L1Fee(context.Background(), nil); !errors.Is(err, rollup.ErrNoL1FeeOracle) {
+		t.Fatalf("got error %v, want %v", err, rollup.ErrNoL1FeeOracle)
+	}
+}
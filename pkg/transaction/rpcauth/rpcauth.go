@@ -0,0 +1,160 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rpcauth dials blockchain RPC endpoints which require custom headers,
+// basic authentication or JWT bearer tokens, as used by hosted providers and
+// the Engine API.
+package rpcauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// JWTSecretSize is the size of the JWT secret in bytes.
+const JWTSecretSize = 32
+
+var (
+	// ErrInvalidJWTSecret is the error returned if the JWT secret is not
+	// JWTSecretSize hex encoded bytes.
+	ErrInvalidJWTSecret = errors.New("invalid jwt secret")
+	// ErrUnsupportedEndpoint is the error returned if the configured
+	// authentication is not supported for the kind of endpoint.
+	ErrUnsupportedEndpoint = errors.New("authentication not supported for the endpoint")
+)
+
+// Options configures the authentication of an endpoint.
+type Options struct {
+	Headers   http.Header // sent with every request
+	Username  string      // basic authentication, used if not empty
+	Password  string
+	JWTSecret []byte // signs a fresh HS256 token for every request if set
+}
+
+// Empty reports whether no authentication is configured.
+func (o Options) Empty() bool {
+	return len(o.Headers) == 0 && o.Username == "" && len(o.JWTSecret) == 0
+}
+
+// ParseHeaders parses headers given as "Name: value".
+func ParseHeaders(headers []string) (http.Header, error) {
+	result := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q, expected name: value", h)
+		}
+		result.Add(name, strings.TrimSpace(value))
+	}
+	return result, nil
+}
+
+// ParseBasicAuth parses credentials given as "username:password".
+func ParseBasicAuth(credentials string) (username, password string, err error) {
+	username, password, ok := strings.Cut(credentials, ":")
+	if !ok || username == "" {
+		return "", "", errors.New("invalid basic auth credentials, expected username:password")
+	}
+	return username, password, nil
+}
+
+// LoadJWTSecret reads a hex encoded JWT secret from the file at path.
+func LoadJWTSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil || len(secret) != JWTSecretSize {
+		return nil, ErrInvalidJWTSecret
+	}
+	return secret, nil
+}
+
+// Token returns a HS256 signed JWT token issued at the given time.
+func Token(secret []byte, issuedAt time.Time) string {
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		encoding.EncodeToString([]byte(`{"iat":`+strconv.FormatInt(issuedAt.Unix(), 10)+`}`))
+
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(unsigned))
+	return unsigned + "." + encoding.EncodeToString(mac.Sum(nil))
+}
+
+// transport adds the configured authentication to every request.
+type transport struct {
+	base    http.RoundTripper
+	options Options
+	timeNow func() time.Time
+}
+
+// NewTransport returns a http.RoundTripper adding the authentication
+// configured in o to the requests passed on to base.
+func NewTransport(base http.RoundTripper, o Options) http.RoundTripper {
+	return &transport{
+		base:    base,
+		options: o,
+		timeNow: time.Now,
+	}
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// a round tripper must not modify the original request
+	r = r.Clone(r.Context())
+	for name, values := range t.options.Headers {
+		r.Header[name] = append([]string(nil), values...)
+	}
+	if t.options.Username != "" {
+		r.SetBasicAuth(t.options.Username, t.options.Password)
+	}
+	if len(t.options.JWTSecret) > 0 {
+		// tokens are only accepted shortly after they were issued
+		r.Header.Set("Authorization", "Bearer "+Token(t.options.JWTSecret, t.timeNow()))
+	}
+	return t.base.RoundTrip(r)
+}
+
+// Dial connects to the RPC endpoint with the authentication configured in o.
+// Basic authentication is supported for all endpoints, headers and JWT tokens
+// only for http endpoints.
+func Dial(ctx context.Context, endpoint string, o Options) (*rpc.Client, error) {
+	if o.Empty() {
+		return rpc.DialContext(ctx, endpoint)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return rpc.DialHTTPWithClient(endpoint, &http.Client{
+			Transport: NewTransport(http.DefaultTransport, o),
+		})
+	case "ws", "wss":
+		if len(o.Headers) > 0 || len(o.JWTSecret) > 0 {
+			return nil, ErrUnsupportedEndpoint
+		}
+		// websocket endpoints take the basic authentication from the url
+		u.User = url.UserPassword(o.Username, o.Password)
+		return rpc.DialContext(ctx, u.String())
+	default:
+		return nil, ErrUnsupportedEndpoint
+	}
+}
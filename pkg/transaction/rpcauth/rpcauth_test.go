@@ -0,0 +1,175 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpcauth_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/transaction/rpcauth"
+)
+
+type web3API struct{}
+
+func (web3API) ClientVersion() string {
+	return "test"
+}
+
+func newServer(t *testing.T, check func(r *http.Request)) string {
+	t.Helper()
+
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	if err := server.RegisterName("web3", web3API{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func call(t *testing.T, endpoint string, o rpcauth.Options) {
+	t.Helper()
+
+	client, err := rpcauth.Dial(context.Background(), endpoint, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var version string
+	if err := client.CallContext(context.Background(), &version, "web3_clientVersion"); err != nil {
+		t.Fatal(err)
+	}
+	if version != "test" {
+		t.Fatalf("got version %q, want %q", version, "test")
+	}
+}
+
+func TestDialHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers, err := rpcauth.ParseHeaders([]string{"X-Api-Key: secret", "X-Other:value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint := newServer(t, func(r *http.Request) {
+		if got := r.Header.Get("X-Api-Key"); got != "secret" {
+			t.Errorf("got api key %q, want %q", got, "secret")
+		}
+		if got := r.Header.Get("X-Other"); got != "value" {
+			t.Errorf("got header %q, want %q", got, "value")
+		}
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			t.Errorf("got basic auth %q:%q", username, password)
+		}
+	})
+
+	call(t, endpoint, rpcauth.Options{Headers: headers, Username: "user", Password: "pass"})
+}
+
+func TestDialJWT(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte(strings.Repeat("s", rpcauth.JWTSecretSize))
+
+	endpoint := newServer(t, func(r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			t.Errorf("no bearer token in %q", r.Header.Get("Authorization"))
+			return
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			t.Errorf("malformed token %q", token)
+			return
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write([]byte(parts[0] + "." + parts[1]))
+		if base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) != parts[2] {
+			t.Error("invalid token signature")
+		}
+
+		data, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var claims struct {
+			IssuedAt int64 `json:"iat"`
+		}
+		if err := json.Unmarshal(data, &claims); err != nil {
+			t.Error(err)
+			return
+		}
+		if d := time.Since(time.Unix(claims.IssuedAt, 0)); d > time.Minute || d < -time.Minute {
+			t.Errorf("token issued at %d", claims.IssuedAt)
+		}
+	})
+
+	call(t, endpoint, rpcauth.Options{JWTSecret: secret})
+}
+
+func TestDialUnsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := rpcauth.Dial(context.Background(), "ws://localhost:8546", rpcauth.Options{JWTSecret: make([]byte, rpcauth.JWTSecretSize)})
+	if !errors.Is(err, rpcauth.ErrUnsupportedEndpoint) {
+		t.Fatalf("got error %v, want %v", err, rpcauth.ErrUnsupportedEndpoint)
+	}
+}
+
+func TestLoadJWTSecret(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid")
+	if err := os.WriteFile(valid, []byte("0x"+strings.Repeat("ab", rpcauth.JWTSecretSize)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := rpcauth.LoadJWTSecret(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != rpcauth.JWTSecretSize || secret[0] != 0xab {
+		t.Fatalf("got secret %x", secret)
+	}
+
+	invalid := filepath.Join(dir, "invalid")
+	if err := os.WriteFile(invalid, []byte("abcd"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rpcauth.LoadJWTSecret(invalid); !errors.Is(err, rpcauth.ErrInvalidJWTSecret) {
+		t.Fatalf("got error %v, want %v", err, rpcauth.ErrInvalidJWTSecret)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	t.Parallel()
+
+	for _, invalid := range []string{"X-Api-Key", ": value"} {
+		if _, err := rpcauth.ParseHeaders([]string{invalid}); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
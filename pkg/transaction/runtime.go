@@ -0,0 +1,124 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// ErrRuntimeClosed is the error returned if a service is requested from a
+// closed runtime.
+var ErrRuntimeClosed = errors.New("runtime closed")
+
+// Runtime holds the components which must exist only once per backend: the
+// head listener following the chain and, for every sender, the transaction
+// monitor and the transaction service managing its nonces. Several services
+// built on one backend, like the chequebooks of an operator, take these from
+// a shared runtime instead of creating their own. Otherwise each of them
+// polls the backend on its own and transactions of the same sender are sent
+// with colliding nonces.
+type Runtime struct {
+	logger            log.Logger
+	backend           Backend
+	store             storage.StateStorer
+	chainID           *big.Int
+	cancellationDepth uint64
+	heads             HeadListener
+
+	mu      sync.Mutex
+	senders map[common.Address]*runtimeSender
+	closed  bool
+}
+
+// runtimeSender holds the components of a single sender.
+type runtimeSender struct {
+	monitor Monitor
+	service Service
+}
+
+// NewRuntime creates a runtime on the backend whose head listener polls for
+// new blocks every pollingInterval. The nonces of the senders are kept in store.
+func NewRuntime(logger log.Logger, backend Backend, store storage.StateStorer, chainID *big.Int, pollingInterval time.Duration, cancellationDepth uint64) *Runtime {
+	return &Runtime{
+		logger:            logger,
+		backend:           backend,
+		store:             store,
+		chainID:           chainID,
+		cancellationDepth: cancellationDepth,
+		heads:             NewHeadListener(logger, backend, pollingInterval),
+		senders:           make(map[common.Address]*runtimeSender),
+	}
+}
+
+// Backend returns the backend of the runtime.
+func (r *Runtime) Backend() Backend {
+	return r.backend
+}
+
+// ChainID returns the id of the chain of the backend.
+func (r *Runtime) ChainID() *big.Int {
+	return new(big.Int).Set(r.chainID)
+}
+
+// HeadListener returns the head listener shared by all senders.
+func (r *Runtime) HeadListener() HeadListener {
+	return r.heads
+}
+
+// Service returns the transaction service and monitor of the signer. They are
+// created on the first request and shared by all later requests for the same
+// sender, also if requested concurrently.
+func (r *Runtime) Service(signer crypto.Signer) (Service, Monitor, error) {
+	sender, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, nil, ErrRuntimeClosed
+	}
+	if s, ok := r.senders[sender]; ok {
+		return s.service, s.monitor, nil
+	}
+
+	monitor := NewMonitor(r.logger, r.backend, sender, r.heads, r.cancellationDepth)
+	service, err := NewService(r.logger, r.backend, signer, r.store, r.chainID, monitor)
+	if err != nil {
+		_ = monitor.Close()
+		return nil, nil, err
+	}
+	r.senders[sender] = &runtimeSender{monitor: monitor, service: service}
+	return service, monitor, nil
+}
+
+// Close closes the services and monitors of all senders and the head listener.
+// The backend is not closed.
+func (r *Runtime) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	var errs []error
+	for _, s := range r.senders {
+		errs = append(errs, s.service.Close(), s.monitor.Close())
+	}
+	errs = append(errs, r.heads.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/crypto"
+	signermock "github.com/ethersphere/bee/pkg/crypto/mock"
+	"github.com/ethersphere/bee/pkg/log"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+)
+
+func TestRuntime(t *testing.T) {
+	t.Parallel()
+
+	signer := func(address common.Address) crypto.Signer {
+		return signermock.New(signermock.WithEthereumAddressFunc(func() (common.Address, error) {
+			return address, nil
+		}))
+	}
+	first := signer(common.HexToAddress("0xabcd"))
+	second := signer(common.HexToAddress("0xbcde"))
+
+	runtime := transaction.NewRuntime(log.Noop, backendmock.New(), storemock.NewStateStore(), big.NewInt(1), time.Minute, 12)
+
+	// services of the same sender requested concurrently are shared
+	services := make([]transaction.Service, 8)
+	monitors := make([]transaction.Monitor, 8)
+	var wg sync.WaitGroup
+	for i := range services {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			services[i], monitors[i], err = runtime.Service(first)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range services {
+		if services[i] != services[0] || monitors[i] != monitors[0] {
+			t.Fatalf("got different services for the same sender")
+		}
+	}
+
+	other, _, err := runtime.Service(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == services[0] {
+		t.Fatal("got the same service for different senders")
+	}
+
+	if runtime.ChainID().Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("got chain id %d, want 1", runtime.ChainID())
+	}
+
+	if err := runtime.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runtime.Service(first); !errors.Is(err, transaction.ErrRuntimeClosed) {
+		t.Fatalf("got error %v, want %v", err, transaction.ErrRuntimeClosed)
+	}
+}
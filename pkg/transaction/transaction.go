@@ -16,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/sctx"
@@ -38,6 +39,9 @@ var (
 	ErrTransactionReverted = errors.New("transaction reverted")
 	ErrUnknownTransaction  = errors.New("unknown transaction")
 	ErrAlreadyImported     = errors.New("already imported")
+	// ErrInsufficientGasFunds denotes that the wallet of the node cannot pay
+	// for the gas of the transaction.
+	ErrInsufficientGasFunds = errors.New("insufficient funds for gas")
 )
 
 const DefaultTipBoostPercent = 20
@@ -66,6 +70,7 @@ type StoredTransaction struct {
 	Nonce       uint64          // used nonce
 	Created     int64           // creation timestamp
 	Description string          // description
+	Intent      common.Hash     // operation the transaction was sent for, zero if unknown
 }
 
 // Service is the service to send transactions. It takes care of gas price, gas
@@ -109,6 +114,9 @@ type transactionService struct {
 	store   storage.StateStorer
 	chainID *big.Int
 	monitor Monitor
+	metrics metrics
+
+	nativeToken string // symbol of the token gas is paid in
 }
 
 // NewService creates a new transaction service.
@@ -130,6 +138,14 @@ func NewService(logger log.Logger, backend Backend, signer crypto.Signer, store
 		store:   store,
 		chainID: chainID,
 		monitor: monitor,
+
+		nativeToken: config.NativeTokenSymbol(chainID.Int64()),
+	}
+	t.metrics = newMetrics(t.nativeToken)
+
+	err = t.recoverIntents()
+	if err != nil {
+		return nil, err
 	}
 
 	err = t.waitForAllPendingTx()
@@ -162,6 +178,21 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	// a request for an operation whose transaction was already broadcast,
+	// possibly before a restart, is not sent a second time
+	id, err := intentID(request)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	txHash, sent, err := t.resumeIntent(ctx, id)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if sent {
+		loggerV1.Debug("transaction already sent for the operation", "tx", txHash, "description", request.Description)
+		return txHash, nil
+	}
+
 	nonce, err := t.nextNonce(ctx)
 	if err != nil {
 		return common.Hash{}, err
@@ -169,7 +200,7 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 
 	tx, err := t.prepareTransaction(ctx, request, nonce, boostPercent)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, t.gasFundsError(err)
 	}
 
 	signedTx, err := t.signer.SignTx(tx, t.chainID)
@@ -177,11 +208,26 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 		return common.Hash{}, err
 	}
 
+	in, err := newIntent(request, signedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	err = t.store.Put(intentKey(id), in)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
 	loggerV1.Debug("sending transaction", "tx", signedTx.Hash(), "nonce", nonce)
 
 	err = t.backend.SendTransaction(ctx, signedTx)
 	if err != nil {
-		return common.Hash{}, err
+		// the transaction may have been broadcast if the request was interrupted
+		if ctx.Err() == nil {
+			if err := t.store.Delete(intentKey(id)); err != nil {
+				t.logger.Error(err, "error while removing transaction intent", "tx", signedTx.Hash())
+			}
+		}
+		return common.Hash{}, t.gasFundsError(err)
 	}
 
 	err = t.putNonce(nonce + 1)
@@ -191,7 +237,21 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 
 	txHash = signedTx.Hash()
 
-	err = t.store.Put(storedTransactionKey(txHash), StoredTransaction{
+	err = t.recordTransaction(signedTx, boostPercent, request.Description, id, time.Now().Unix())
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	t.waitForPendingTx(txHash)
+
+	return signedTx.Hash(), nil
+}
+
+// recordTransaction stores the sent transaction and registers it as pending.
+func (t *transactionService) recordTransaction(signedTx *types.Transaction, boostPercent int, description string, intent common.Hash, created int64) error {
+	txHash := signedTx.Hash()
+
+	err := t.store.Put(storedTransactionKey(txHash), StoredTransaction{
 		To:          signedTx.To(),
 		Data:        signedTx.Data(),
 		GasPrice:    signedTx.GasPrice(),
@@ -201,21 +261,15 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 		GasFeeCap:   signedTx.GasFeeCap(),
 		Value:       signedTx.Value(),
 		Nonce:       signedTx.Nonce(),
-		Created:     time.Now().Unix(),
-		Description: request.Description,
+		Created:     created,
+		Description: description,
+		Intent:      intent,
 	})
 	if err != nil {
-		return common.Hash{}, err
-	}
-
-	err = t.store.Put(pendingTransactionKey(txHash), struct{}{})
-	if err != nil {
-		return common.Hash{}, err
+		return err
 	}
 
-	t.waitForPendingTx(txHash)
-
-	return signedTx.Hash(), nil
+	return t.store.Put(pendingTransactionKey(txHash), struct{}{})
 }
 
 func (t *transactionService) waitForPendingTx(txHash common.Hash) {
@@ -235,6 +289,7 @@ func (t *transactionService) waitForPendingTx(txHash common.Hash) {
 		} else {
 			loggerV1.Debug("pending transaction confirmed", "tx", txHash)
 		}
+		t.closeIntent(txHash, err != nil)
 
 		err = t.store.Delete(pendingTransactionKey(txHash))
 		if err != nil {
@@ -455,6 +510,9 @@ func (t *transactionService) filterPendingTransactions(ctx context.Context, txHa
 		if isPending {
 			result = append(result, txHash)
 		} else {
+			if err == nil {
+				t.closeIntent(txHash, false)
+			}
 			err := t.store.Delete(pendingTransactionKey(txHash))
 			if err != nil {
 				t.logger.Error(err, "error while unregistering transaction as pending", "tx", txHash)
@@ -544,7 +602,7 @@ func (t *transactionService) CancelTransaction(ctx context.Context, originalTxHa
 
 	err = t.backend.SendTransaction(t.ctx, signedTx)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, t.gasFundsError(err)
 	}
 
 	txHash := signedTx.Hash()
@@ -575,6 +633,17 @@ func (t *transactionService) CancelTransaction(ctx context.Context, originalTxHa
 	return txHash, err
 }
 
+// gasFundsError wraps errors of the backend caused by a wallet balance too low
+// to pay for the gas with ErrInsufficientGasFunds, naming the native token of
+// the chain instead of the one of the backend message.
+func (t *transactionService) gasFundsError(err error) error {
+	if !strings.Contains(err.Error(), "insufficient funds") {
+		return err
+	}
+	t.metrics.InsufficientGasFunds.Inc()
+	return fmt.Errorf("%w: not enough %s on %s: %v", ErrInsufficientGasFunds, t.nativeToken, t.sender, err)
+}
+
 func (t *transactionService) Close() error {
 	t.cancel()
 	t.wg.Wait()
@@ -7,8 +7,10 @@ package transaction_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
@@ -777,3 +779,53 @@ func TestTransactionCancel(t *testing.T) {
 		}
 	})
 }
+
+func TestTransactionSendInsufficientGasFunds(t *testing.T) {
+	t.Parallel()
+
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xabcd")
+
+	for _, tc := range []struct {
+		chainID int64
+		symbol  string
+	}{
+		{chainID: 100, symbol: "xDAI"},
+		{chainID: 137, symbol: "MATIC"},
+		{chainID: 1, symbol: "ETH"},
+	} {
+		tc := tc
+		t.Run(tc.symbol, func(t *testing.T) {
+			t.Parallel()
+
+			transactionService, err := transaction.NewService(log.Noop,
+				backendmock.New(
+					backendmock.WithEstimateGasFunc(func(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+						return 0, errors.New("insufficient funds for gas * price + value")
+					}),
+					backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+						return 0, nil
+					}),
+				),
+				signermock.New(signermock.WithEthereumAddressFunc(func() (common.Address, error) {
+					return sender, nil
+				})),
+				storemock.NewStateStore(),
+				big.NewInt(tc.chainID),
+				monitormock.New(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testutil.CleanupCloser(t, transactionService)
+
+			_, err = transactionService.Send(context.Background(), &transaction.TxRequest{To: &recipient}, 0)
+			if !errors.Is(err, transaction.ErrInsufficientGasFunds) {
+				t.Fatalf("got error %v, want %v", err, transaction.ErrInsufficientGasFunds)
+			}
+			if !strings.Contains(err.Error(), "not enough "+tc.symbol+" ") {
+				t.Fatalf("error %q does not name %s", err, tc.symbol)
+			}
+		})
+	}
+}
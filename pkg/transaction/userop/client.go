@@ -0,0 +1,97 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package userop
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Bundler submits user operations to the network.
+type Bundler interface {
+	// EstimateUserOperationGas estimates the gas limits of the user operation.
+	EstimateUserOperationGas(ctx context.Context, op *UserOperation, entryPoint common.Address) (*GasEstimate, error)
+	// SendUserOperation submits the user operation and returns its hash.
+	SendUserOperation(ctx context.Context, op *UserOperation, entryPoint common.Address) (common.Hash, error)
+	// UserOperationReceipt returns the receipt of the user operation or nil if it was not included yet.
+	UserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*Receipt, error)
+}
+
+// Paymaster sponsors the gas costs of user operations.
+type Paymaster interface {
+	// SponsorUserOperation returns the paymasterAndData field for the user operation.
+	SponsorUserOperation(ctx context.Context, op *UserOperation, entryPoint common.Address) ([]byte, error)
+}
+
+var (
+	_ Bundler   = (*Client)(nil)
+	_ Paymaster = (*Client)(nil)
+)
+
+// Client talks to a bundler and paymaster through the standard JSON-RPC API.
+type Client struct {
+	rpc *rpc.Client
+}
+
+// NewClient creates a new bundler and paymaster client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{rpc: c}
+}
+
+// Dial connects to the bundler at endpoint.
+func Dial(ctx context.Context, endpoint string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// EstimateUserOperationGas implements the Bundler interface.
+func (c *Client) EstimateUserOperationGas(ctx context.Context, op *UserOperation, entryPoint common.Address) (*GasEstimate, error) {
+	var estimate GasEstimate
+	if err := c.rpc.CallContext(ctx, &estimate, "eth_estimateUserOperationGas", op, entryPoint); err != nil {
+		return nil, err
+	}
+	return &estimate, nil
+}
+
+// SendUserOperation implements the Bundler interface.
+func (c *Client) SendUserOperation(ctx context.Context, op *UserOperation, entryPoint common.Address) (common.Hash, error) {
+	var hash common.Hash
+	if err := c.rpc.CallContext(ctx, &hash, "eth_sendUserOperation", op, entryPoint); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// UserOperationReceipt implements the Bundler interface.
+func (c *Client) UserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	if err := c.rpc.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", userOpHash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// SponsorUserOperation implements the Paymaster interface.
+func (c *Client) SponsorUserOperation(ctx context.Context, op *UserOperation, entryPoint common.Address) ([]byte, error) {
+	var result struct {
+		PaymasterAndData hexutil.Bytes `json:"paymasterAndData"`
+	}
+	if err := c.rpc.CallContext(ctx, &result, "pm_sponsorUserOperation", op, entryPoint); err != nil {
+		return nil, err
+	}
+	return result.PaymasterAndData, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.rpc.Close()
+	return nil
+}
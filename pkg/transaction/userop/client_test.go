@@ -0,0 +1,162 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package userop_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/transaction/userop"
+)
+
+type bundlerAPI struct {
+	t      *testing.T
+	sender common.Address
+}
+
+func (a *bundlerAPI) EstimateUserOperationGas(op userop.UserOperation, entryPoint common.Address) userop.GasEstimate {
+	return userop.GasEstimate{
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(1)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(2)),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(3)),
+	}
+}
+
+func (a *bundlerAPI) SendUserOperation(op userop.UserOperation, entryPoint common.Address) common.Hash {
+	if op.Sender != a.sender {
+		a.t.Errorf("got sender %x, want %x", op.Sender, a.sender)
+	}
+	return common.HexToHash("0x01")
+}
+
+func (a *bundlerAPI) GetUserOperationReceipt(hash common.Hash) *userop.Receipt {
+	if hash != common.HexToHash("0x01") {
+		return nil
+	}
+	return &userop.Receipt{UserOpHash: hash, Success: true}
+}
+
+type paymasterAPI struct{}
+
+func (paymasterAPI) SponsorUserOperation(op userop.UserOperation, entryPoint common.Address) map[string]hexutil.Bytes {
+	return map[string]hexutil.Bytes{"paymasterAndData": {0xaa}}
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	sender := common.HexToAddress("0xacc0")
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", &bundlerAPI{t: t, sender: sender}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("pm", paymasterAPI{}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := userop.NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	ctx := context.Background()
+	op := &userop.UserOperation{Sender: sender, Nonce: big.NewInt(1), CallData: []byte{1}}
+
+	estimate, err := client.EstimateUserOperationGas(ctx, op, userop.DefaultEntryPoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.CallGasLimit.ToInt().Int64() != 3 {
+		t.Fatalf("got call gas limit %d, want 3", estimate.CallGasLimit.ToInt())
+	}
+
+	paymasterAndData, err := client.SponsorUserOperation(ctx, op, userop.DefaultEntryPoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(paymasterAndData, []byte{0xaa}) {
+		t.Fatalf("got paymaster data %x", paymasterAndData)
+	}
+
+	hash, err := client.SendUserOperation(ctx, op, userop.DefaultEntryPoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := client.UserOperationReceipt(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt == nil || !receipt.Success {
+		t.Fatalf("got receipt %v", receipt)
+	}
+
+	receipt, err = client.UserOperationReceipt(ctx, common.HexToHash("0x02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt != nil {
+		t.Fatal("expected no receipt for pending user operation")
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	keccak := func(data []byte) []byte {
+		h, err := crypto.LegacyKeccak256(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	word := func(i int64) []byte {
+		return common.LeftPadBytes(big.NewInt(i).Bytes(), 32)
+	}
+
+	op := &userop.UserOperation{
+		Sender:               common.HexToAddress("0xacc0"),
+		Nonce:                big.NewInt(1),
+		InitCode:             []byte{2},
+		CallData:             []byte{3},
+		CallGasLimit:         big.NewInt(4),
+		VerificationGasLimit: big.NewInt(5),
+		PreVerificationGas:   big.NewInt(6),
+		MaxFeePerGas:         big.NewInt(7),
+		MaxPriorityFeePerGas: big.NewInt(8),
+		PaymasterAndData:     []byte{9},
+		Signature:            []byte{10}, // not part of the hash
+	}
+
+	var packed []byte
+	packed = append(packed, common.LeftPadBytes(op.Sender.Bytes(), 32)...)
+	packed = append(packed, word(1)...)
+	packed = append(packed, keccak([]byte{2})...)
+	packed = append(packed, keccak([]byte{3})...)
+	for i := int64(4); i <= 8; i++ {
+		packed = append(packed, word(i)...)
+	}
+	packed = append(packed, keccak([]byte{9})...)
+
+	var encoded []byte
+	encoded = append(encoded, keccak(packed)...)
+	encoded = append(encoded, common.LeftPadBytes(userop.DefaultEntryPoint.Bytes(), 32)...)
+	encoded = append(encoded, word(100)...)
+	want := common.BytesToHash(keccak(encoded))
+
+	got, err := op.Hash(userop.DefaultEntryPoint, big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("got hash %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package userop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "userop"
+
+// DefaultPollInterval is the default interval in which the bundler is asked
+// whether a user operation was included.
+const DefaultPollInterval = 2 * time.Second
+
+const (
+	entryPointABIJSON = `[{"inputs":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"uint192","name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"internalType":"uint256","name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	accountABIJSON    = `[{"inputs":[{"internalType":"address","name":"dest","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"bytes","name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+)
+
+var (
+	entryPointABI = abiutil.MustParseABI(entryPointABIJSON)
+	accountABI    = abiutil.MustParseABI(accountABIJSON)
+
+	// dummySignature is a well-formed signature used during gas estimation
+	// so that the signature validation of the account does not revert.
+	dummySignature = append(common.FromHex("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"), 0x1c)
+)
+
+// ErrUserOperationFailed is the error returned if a user operation was
+// included but its execution reverted.
+var ErrUserOperationFailed = errors.New("user operation failed")
+
+// Options configures the Service.
+type Options struct {
+	EntryPoint   common.Address                    // the EntryPoint contract the bundler submits to
+	Account      common.Address                    // the smart contract account sending the transactions
+	Paymaster    Paymaster                         // sponsors the gas costs if set
+	Route        func(*transaction.TxRequest) bool // selects the requests sent as user operations, all if nil
+	PollInterval time.Duration                     // interval of receipt polling
+}
+
+// RouteDescriptions routes the requests with one of the given descriptions.
+func RouteDescriptions(descriptions ...string) func(*transaction.TxRequest) bool {
+	routed := make(map[string]struct{}, len(descriptions))
+	for _, d := range descriptions {
+		routed[d] = struct{}{}
+	}
+	return func(request *transaction.TxRequest) bool {
+		_, ok := routed[request.Description]
+		return ok
+	}
+}
+
+var _ transaction.Service = (*Service)(nil)
+
+// Service is a transaction.Service which sends the routed transactions as
+// user operations of a smart contract account owned by the signer. All other
+// requests and calls are handled by the wrapped transaction service.
+type Service struct {
+	transaction.Service
+
+	logger  log.Logger
+	backend transaction.Backend
+	bundler Bundler
+	signer  crypto.Signer
+	chainID *big.Int
+	options Options
+
+	sendMu sync.Mutex // serializes user operations as the account nonce is only known after inclusion
+}
+
+// NewService creates a new user operation transaction service.
+func NewService(logger log.Logger, transactionService transaction.Service, backend transaction.Backend, bundler Bundler, signer crypto.Signer, chainID *big.Int, o Options) *Service {
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	return &Service{
+		Service: transactionService,
+		logger:  logger.WithName(loggerName).Register(),
+		backend: backend,
+		bundler: bundler,
+		signer:  signer,
+		chainID: chainID,
+		options: o,
+	}
+}
+
+// Account returns the smart contract account sending the user operations.
+func (s *Service) Account() common.Address {
+	return s.options.Account
+}
+
+// Send sends the request as a user operation if it is routed, otherwise it is
+// sent by the wrapped service. User operations are only returned once they
+// were included, the returned hash is the hash of the bundle transaction.
+func (s *Service) Send(ctx context.Context, request *transaction.TxRequest, boostPercent int) (common.Hash, error) {
+	if s.options.Route != nil && !s.options.Route(request) {
+		return s.Service.Send(ctx, request, boostPercent)
+	}
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	op, err := s.prepareUserOperation(ctx, request, boostPercent)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	userOpHash, err := s.bundler.SendUserOperation(ctx, op, s.options.EntryPoint)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("send user operation: %w", err)
+	}
+	s.logger.Debug("sent user operation", "user_op_hash", userOpHash, "description", request.Description)
+
+	receipt, err := s.waitForUserOperation(ctx, userOpHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt.Receipt == nil {
+		return common.Hash{}, fmt.Errorf("user operation %x: missing transaction receipt", userOpHash)
+	}
+	txHash := receipt.Receipt.TxHash
+	if !receipt.Success {
+		return txHash, fmt.Errorf("user operation %x: %s: %w", userOpHash, receipt.Reason, ErrUserOperationFailed)
+	}
+
+	s.logger.Debug("user operation included", "user_op_hash", userOpHash, "tx", txHash)
+
+	return txHash, nil
+}
+
+// prepareUserOperation creates a signed user operation executing the request through the account.
+func (s *Service) prepareUserOperation(ctx context.Context, request *transaction.TxRequest, boostPercent int) (*UserOperation, error) {
+	if request.To == nil {
+		return nil, errors.New("user operations cannot deploy contracts")
+	}
+
+	value := request.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	callData, err := accountABI.Pack("execute", *request.To, value, request.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.accountNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxFee, maxPriorityFee, err := s.fees(ctx, request.GasPrice, boostPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &UserOperation{
+		Sender:               s.options.Account,
+		Nonce:                nonce,
+		CallData:             callData,
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: maxPriorityFee,
+		Signature:            dummySignature,
+	}
+
+	if s.options.Paymaster != nil {
+		// a preliminary sponsorship is needed as the paymaster affects the verification gas
+		op.PaymasterAndData, err = s.options.Paymaster.SponsorUserOperation(ctx, op, s.options.EntryPoint)
+		if err != nil {
+			return nil, fmt.Errorf("sponsor user operation: %w", err)
+		}
+	}
+
+	estimate, err := s.bundler.EstimateUserOperationGas(ctx, op, s.options.EntryPoint)
+	if err != nil {
+		return nil, fmt.Errorf("estimate user operation gas: %w", err)
+	}
+	if estimate.CallGasLimit == nil || estimate.VerificationGasLimit == nil || estimate.PreVerificationGas == nil {
+		return nil, errors.New("incomplete user operation gas estimate")
+	}
+	op.CallGasLimit = estimate.CallGasLimit.ToInt()
+	if request.GasLimit != 0 {
+		op.CallGasLimit = new(big.Int).SetUint64(request.GasLimit)
+	}
+	op.VerificationGasLimit = estimate.VerificationGasLimit.ToInt()
+	op.PreVerificationGas = estimate.PreVerificationGas.ToInt()
+
+	if s.options.Paymaster != nil {
+		// the paymaster signature covers the gas limits
+		op.PaymasterAndData, err = s.options.Paymaster.SponsorUserOperation(ctx, op, s.options.EntryPoint)
+		if err != nil {
+			return nil, fmt.Errorf("sponsor user operation: %w", err)
+		}
+	}
+
+	hash, err := op.Hash(s.options.EntryPoint, s.chainID)
+	if err != nil {
+		return nil, err
+	}
+	op.Signature, err = s.signer.Sign(hash.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// accountNonce returns the next nonce of the account for the default nonce key.
+func (s *Service) accountNonce(ctx context.Context) (*big.Int, error) {
+	callData, err := entryPointABI.Pack("getNonce", s.options.Account, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Service.Call(ctx, &transaction.TxRequest{
+		To:   &s.options.EntryPoint,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, err := entryPointABI.Unpack("getNonce", output)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, errors.New("unexpected getNonce result")
+	}
+	return abi.ConvertType(results[0], new(big.Int)).(*big.Int), nil
+}
+
+// fees computes the fee caps the same way the transaction service does.
+func (s *Service) fees(ctx context.Context, gasPrice *big.Int, boostPercent int) (maxFee, maxPriorityFee *big.Int, err error) {
+	if gasPrice == nil {
+		gasPrice, err = s.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		gasPrice = new(big.Int).Div(new(big.Int).Mul(big.NewInt(int64(boostPercent)+100), gasPrice), big.NewInt(100))
+	}
+
+	gasTipCap, err := s.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	gasTipCap = new(big.Int).Div(new(big.Int).Mul(big.NewInt(int64(boostPercent)+100), gasTipCap), big.NewInt(100))
+
+	return new(big.Int).Add(gasTipCap, gasPrice), gasTipCap, nil
+}
+
+// waitForUserOperation polls the bundler until the user operation was included.
+func (s *Service) waitForUserOperation(ctx context.Context, userOpHash common.Hash) (*Receipt, error) {
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := s.bundler.UserOperationReceipt(ctx, userOpHash)
+		if err != nil {
+			s.logger.Debug("user operation receipt failed", "user_op_hash", userOpHash, "error", err)
+		} else if receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("user operation %x: %w", userOpHash, ctx.Err())
+		}
+	}
+}
+
+// WaitForReceipt waits for the receipt of a transaction. Bundle transactions
+// of user operations are already included once Send returns, their receipts
+// are looked up directly.
+func (s *Service) WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := s.Service.WaitForReceipt(ctx, txHash)
+	if errors.Is(err, transaction.ErrUnknownTransaction) {
+		return s.backend.TransactionReceipt(ctx, txHash)
+	}
+	return receipt, err
+}
@@ -0,0 +1,281 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package userop_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/transaction/userop"
+	"github.com/ethersphere/bee/pkg/util/abiutil"
+)
+
+var (
+	entryPointABI = abiutil.MustParseABI(`[{"inputs":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"uint192","name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"internalType":"uint256","name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"}]`)
+	accountABI    = abiutil.MustParseABI(`[{"inputs":[{"internalType":"address","name":"dest","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"bytes","name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"}]`)
+)
+
+type bundlerMock struct {
+	sent     []*userop.UserOperation
+	receipts []*userop.Receipt // returned one after another, nil means pending
+}
+
+func (b *bundlerMock) EstimateUserOperationGas(ctx context.Context, op *userop.UserOperation, entryPoint common.Address) (*userop.GasEstimate, error) {
+	return &userop.GasEstimate{
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50_000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(100_000)),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(200_000)),
+	}, nil
+}
+
+func (b *bundlerMock) SendUserOperation(ctx context.Context, op *userop.UserOperation, entryPoint common.Address) (common.Hash, error) {
+	b.sent = append(b.sent, op)
+	return common.HexToHash("0x0a"), nil
+}
+
+func (b *bundlerMock) UserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*userop.Receipt, error) {
+	if len(b.receipts) == 0 {
+		return nil, errors.New("no receipt")
+	}
+	receipt := b.receipts[0]
+	b.receipts = b.receipts[1:]
+	return receipt, nil
+}
+
+type paymasterMock struct {
+	calls int
+}
+
+func (p *paymasterMock) SponsorUserOperation(ctx context.Context, op *userop.UserOperation, entryPoint common.Address) ([]byte, error) {
+	p.calls++
+	return []byte{0x01, byte(p.calls)}, nil
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := common.HexToAddress("0xacc0")
+	entryPoint := userop.DefaultEntryPoint
+	chainID := big.NewInt(100)
+	to := common.HexToAddress("0xc0de")
+	data := []byte{1, 2, 3}
+	txHash := common.HexToHash("0xbb")
+	nonce := big.NewInt(7)
+
+	bundler := &bundlerMock{
+		receipts: []*userop.Receipt{
+			nil,
+			{Success: true, Receipt: &types.Receipt{TxHash: txHash}},
+		},
+	}
+	paymaster := &paymasterMock{}
+
+	var sentByWallet bool
+	service := userop.NewService(
+		log.Noop,
+		transactionmock.New(
+			transactionmock.WithABICall(&entryPointABI, entryPoint, common.BigToHash(nonce).Bytes(), "getNonce", account, big.NewInt(0)),
+			transactionmock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (common.Hash, error) {
+				sentByWallet = true
+				return common.HexToHash("0xcc"), nil
+			}),
+		),
+		backendmock.New(
+			backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(100), nil
+			}),
+			backendmock.WithSuggestGasTipCapFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+		),
+		bundler,
+		signer,
+		chainID,
+		userop.Options{
+			EntryPoint:   entryPoint,
+			Account:      account,
+			Paymaster:    paymaster,
+			Route:        userop.RouteDescriptions("routed"),
+			PollInterval: time.Millisecond,
+		},
+	)
+
+	if service.Account() != account {
+		t.Fatalf("got account %x, want %x", service.Account(), account)
+	}
+
+	hash, err := service.Send(context.Background(), &transaction.TxRequest{
+		To:          &to,
+		Data:        data,
+		Value:       big.NewInt(0),
+		Description: "routed",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != txHash {
+		t.Fatalf("got hash %x, want %x", hash, txHash)
+	}
+	if sentByWallet {
+		t.Fatal("routed request sent by the wallet")
+	}
+
+	if len(bundler.sent) != 1 {
+		t.Fatalf("got %d user operations, want 1", len(bundler.sent))
+	}
+	op := bundler.sent[0]
+
+	if op.Sender != account {
+		t.Fatalf("got sender %x, want %x", op.Sender, account)
+	}
+	if op.Nonce.Cmp(nonce) != 0 {
+		t.Fatalf("got nonce %d, want %d", op.Nonce, nonce)
+	}
+	expectedCallData, err := accountABI.Pack("execute", to, big.NewInt(0), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(op.CallData, expectedCallData) {
+		t.Fatalf("got call data %x, want %x", op.CallData, expectedCallData)
+	}
+	if op.MaxFeePerGas.Cmp(big.NewInt(110)) != 0 || op.MaxPriorityFeePerGas.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("got fees %d/%d, want 110/10", op.MaxFeePerGas, op.MaxPriorityFeePerGas)
+	}
+	if op.CallGasLimit.Cmp(big.NewInt(200_000)) != 0 || op.VerificationGasLimit.Cmp(big.NewInt(100_000)) != 0 || op.PreVerificationGas.Cmp(big.NewInt(50_000)) != 0 {
+		t.Fatal("gas estimate not applied")
+	}
+	// the final sponsorship covers the estimated gas limits
+	if paymaster.calls != 2 || !bytes.Equal(op.PaymasterAndData, []byte{0x01, 0x02}) {
+		t.Fatalf("got paymaster data %x after %d calls", op.PaymasterAndData, paymaster.calls)
+	}
+
+	hashToSign, err := op.Hash(entryPoint, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := crypto.Recover(op.Signature, hashToSign.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerAddress, err := crypto.NewEthereumAddress(*pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signerAddress, owner.Bytes()) {
+		t.Fatalf("signed by %x, want %x", signerAddress, owner)
+	}
+
+	hash, err = service.Send(context.Background(), &transaction.TxRequest{
+		To:          &to,
+		Description: "not routed",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sentByWallet || hash != common.HexToHash("0xcc") {
+		t.Fatal("request not sent by the wallet")
+	}
+}
+
+func TestSendFailed(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := common.HexToAddress("0xacc0")
+	to := common.HexToAddress("0xc0de")
+	txHash := common.HexToHash("0xbb")
+
+	service := userop.NewService(
+		log.Noop,
+		transactionmock.New(
+			transactionmock.WithABICall(&entryPointABI, userop.DefaultEntryPoint, common.BigToHash(big.NewInt(0)).Bytes(), "getNonce", account, big.NewInt(0)),
+		),
+		backendmock.New(
+			backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(100), nil
+			}),
+			backendmock.WithSuggestGasTipCapFunc(func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+		),
+		&bundlerMock{
+			receipts: []*userop.Receipt{{Success: false, Reason: "reverted", Receipt: &types.Receipt{TxHash: txHash}}},
+		},
+		crypto.NewDefaultSigner(key),
+		big.NewInt(100),
+		userop.Options{
+			EntryPoint:   userop.DefaultEntryPoint,
+			Account:      account,
+			PollInterval: time.Millisecond,
+		},
+	)
+
+	_, err = service.Send(context.Background(), &transaction.TxRequest{To: &to}, 0)
+	if !errors.Is(err, userop.ErrUserOperationFailed) {
+		t.Fatalf("got error %v, want %v", err, userop.ErrUserOperationFailed)
+	}
+}
+
+func TestWaitForReceipt(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0xbb")
+	receipt := &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}
+
+	service := userop.NewService(
+		log.Noop,
+		transactionmock.New(
+			transactionmock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				return nil, transaction.ErrUnknownTransaction
+			}),
+		),
+		backendmock.New(
+			backendmock.WithTransactionReceiptFunc(func(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+				if hash != txHash {
+					t.Fatalf("got hash %x, want %x", hash, txHash)
+				}
+				return receipt, nil
+			}),
+		),
+		&bundlerMock{},
+		nil,
+		big.NewInt(100),
+		userop.Options{},
+	)
+
+	got, err := service.WaitForReceipt(context.Background(), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != receipt {
+		t.Fatal("wrong receipt")
+	}
+}
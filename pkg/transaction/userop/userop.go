@@ -0,0 +1,191 @@
+// Copyright 2023 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package userop sends transactions as ERC-4337 user operations of a smart
+// contract account through a bundler, optionally sponsored by a paymaster.
+package userop
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+)
+
+// DefaultEntryPoint is the address of the canonical v0.6 EntryPoint contract.
+var DefaultEntryPoint = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+// UserOperation is an ERC-4337 (EntryPoint v0.6) user operation.
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+type userOperationJSON struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// bigOrZero converts nil values into zero as the bundler API requires all fields.
+func bigOrZero(i *big.Int) *hexutil.Big {
+	if i == nil {
+		return (*hexutil.Big)(big.NewInt(0))
+	}
+	return (*hexutil.Big)(i)
+}
+
+// MarshalJSON encodes the user operation as expected by the bundler API.
+func (op *UserOperation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(userOperationJSON{
+		Sender:               op.Sender,
+		Nonce:                bigOrZero(op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         bigOrZero(op.CallGasLimit),
+		VerificationGasLimit: bigOrZero(op.VerificationGasLimit),
+		PreVerificationGas:   bigOrZero(op.PreVerificationGas),
+		MaxFeePerGas:         bigOrZero(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigOrZero(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	})
+}
+
+// UnmarshalJSON decodes a user operation from the bundler API encoding.
+func (op *UserOperation) UnmarshalJSON(b []byte) error {
+	var v userOperationJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*op = UserOperation{
+		Sender:               v.Sender,
+		Nonce:                (*big.Int)(v.Nonce),
+		InitCode:             v.InitCode,
+		CallData:             v.CallData,
+		CallGasLimit:         (*big.Int)(v.CallGasLimit),
+		VerificationGasLimit: (*big.Int)(v.VerificationGasLimit),
+		PreVerificationGas:   (*big.Int)(v.PreVerificationGas),
+		MaxFeePerGas:         (*big.Int)(v.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*big.Int)(v.MaxPriorityFeePerGas),
+		PaymasterAndData:     v.PaymasterAndData,
+		Signature:            v.Signature,
+	}
+	return nil
+}
+
+var (
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	addressType, _ = abi.NewType("address", "", nil)
+	bytes32Type, _ = abi.NewType("bytes32", "", nil)
+
+	packedUserOperationArguments = abi.Arguments{
+		{Type: addressType}, // sender
+		{Type: uint256Type}, // nonce
+		{Type: bytes32Type}, // keccak256(initCode)
+		{Type: bytes32Type}, // keccak256(callData)
+		{Type: uint256Type}, // callGasLimit
+		{Type: uint256Type}, // verificationGasLimit
+		{Type: uint256Type}, // preVerificationGas
+		{Type: uint256Type}, // maxFeePerGas
+		{Type: uint256Type}, // maxPriorityFeePerGas
+		{Type: bytes32Type}, // keccak256(paymasterAndData)
+	}
+
+	userOperationHashArguments = abi.Arguments{
+		{Type: bytes32Type}, // keccak256(packed user operation)
+		{Type: addressType}, // entry point
+		{Type: uint256Type}, // chain id
+	}
+)
+
+func keccak256Hash(data []byte) (common.Hash, error) {
+	h, err := crypto.LegacyKeccak256(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(h), nil
+}
+
+// Hash computes the hash of the user operation which is signed by the
+// account owner, as defined by the EntryPoint getUserOpHash function.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	initCodeHash, err := keccak256Hash(op.InitCode)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	callDataHash, err := keccak256Hash(op.CallData)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	paymasterAndDataHash, err := keccak256Hash(op.PaymasterAndData)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	packed, err := packedUserOperationArguments.Pack(
+		op.Sender,
+		(*big.Int)(bigOrZero(op.Nonce)),
+		initCodeHash,
+		callDataHash,
+		(*big.Int)(bigOrZero(op.CallGasLimit)),
+		(*big.Int)(bigOrZero(op.VerificationGasLimit)),
+		(*big.Int)(bigOrZero(op.PreVerificationGas)),
+		(*big.Int)(bigOrZero(op.MaxFeePerGas)),
+		(*big.Int)(bigOrZero(op.MaxPriorityFeePerGas)),
+		paymasterAndDataHash,
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	packedHash, err := keccak256Hash(packed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	encoded, err := userOperationHashArguments.Pack(packedHash, entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return keccak256Hash(encoded)
+}
+
+// GasEstimate is the gas estimation of a user operation by the bundler.
+type GasEstimate struct {
+	PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+}
+
+// Receipt is the receipt of an included user operation.
+type Receipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	Sender        common.Address `json:"sender"`
+	Success       bool           `json:"success"`
+	Reason        string         `json:"reason"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Receipt       *types.Receipt `json:"receipt"` // receipt of the bundle transaction
+}
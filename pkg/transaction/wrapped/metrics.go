@@ -23,6 +23,7 @@ type metrics struct {
 	PendingNonceCalls       prometheus.Counter
 	CallContractCalls       prometheus.Counter
 	SuggestGasPriceCalls    prometheus.Counter
+	FeeHistoryCalls         prometheus.Counter
 	EstimateGasCalls        prometheus.Counter
 	SendTransactionCalls    prometheus.Counter
 	FilterLogsCalls         prometheus.Counter
@@ -105,6 +106,12 @@ func newMetrics() metrics {
 			Name:      "calls_suggest_gasprice",
 			Help:      "Count of eth_suggestGasPrice rpc calls",
 		}),
+		FeeHistoryCalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "calls_fee_history",
+			Help:      "Count of eth_feeHistory rpc calls",
+		}),
 		EstimateGasCalls: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -158,6 +158,17 @@ func (b *wrappedBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	return gasTipCap, nil
 }
 
+func (b *wrappedBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	b.metrics.TotalRPCCalls.Inc()
+	b.metrics.FeeHistoryCalls.Inc()
+	history, err := b.backend.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	if err != nil {
+		b.metrics.TotalRPCErrors.Inc()
+		return nil, err
+	}
+	return history, nil
+}
+
 func (b *wrappedBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
 	b.metrics.TotalRPCCalls.Inc()
 	b.metrics.EstimateGasCalls.Inc()